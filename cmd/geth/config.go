@@ -33,6 +33,7 @@ import (
 	"github.com/scroll-tech/go-ethereum/accounts/scwallet"
 	"github.com/scroll-tech/go-ethereum/accounts/usbwallet"
 	"github.com/scroll-tech/go-ethereum/cmd/utils"
+	"github.com/scroll-tech/go-ethereum/eth"
 	"github.com/scroll-tech/go-ethereum/eth/catalyst"
 	"github.com/scroll-tech/go-ethereum/eth/ethconfig"
 	"github.com/scroll-tech/go-ethereum/internal/debug"
@@ -41,6 +42,7 @@ import (
 	"github.com/scroll-tech/go-ethereum/metrics"
 	"github.com/scroll-tech/go-ethereum/node"
 	"github.com/scroll-tech/go-ethereum/params"
+	"github.com/scroll-tech/go-ethereum/rollup/rollup_sync_service"
 )
 
 var (
@@ -91,6 +93,7 @@ type gethConfig struct {
 	Node     node.Config
 	Ethstats ethstatsConfig
 	Metrics  metrics.Config
+	Rollup   rollup_sync_service.Config
 }
 
 func loadConfig(file string, cfg *gethConfig) error {
@@ -125,6 +128,7 @@ func makeConfigNode(ctx *cli.Context) (*node.Node, gethConfig) {
 		Eth:     ethconfig.Defaults,
 		Node:    defaultNodeConfig(),
 		Metrics: metrics.DefaultConfig,
+		Rollup:  ethconfig.Defaults.RollupSync,
 	}
 
 	// Load config file.
@@ -133,6 +137,8 @@ func makeConfigNode(ctx *cli.Context) (*node.Node, gethConfig) {
 			utils.Fatalf("%v", err)
 		}
 	}
+	// The rollup section is applied before flags, so that flags take precedence.
+	cfg.Eth.RollupSync = cfg.Rollup
 
 	// Apply flags.
 	utils.SetNodeConfig(ctx, &cfg.Node)
@@ -151,12 +157,14 @@ func makeConfigNode(ctx *cli.Context) (*node.Node, gethConfig) {
 	}
 	applyTraceConfig(ctx, &cfg.Eth)
 	applyMetricConfig(ctx, &cfg)
+	cfg.Rollup = cfg.Eth.RollupSync
 
 	return stack, cfg
 }
 
-// makeFullNode loads geth configuration and creates the Ethereum backend.
-func makeFullNode(ctx *cli.Context) (*node.Node, ethapi.Backend) {
+// makeFullNode loads geth configuration and creates the Ethereum backend. The full
+// Ethereum service is nil in light client mode.
+func makeFullNode(ctx *cli.Context) (*node.Node, ethapi.Backend, *eth.Ethereum) {
 	stack, cfg := makeConfigNode(ctx)
 	if ctx.GlobalIsSet(utils.OverrideArrowGlacierFlag.Name) {
 		cfg.Eth.OverrideArrowGlacier = new(big.Int).SetUint64(ctx.GlobalUint64(utils.OverrideArrowGlacierFlag.Name))
@@ -181,7 +189,7 @@ func makeFullNode(ctx *cli.Context) (*node.Node, ethapi.Backend) {
 	if cfg.Ethstats.URL != "" {
 		utils.RegisterEthStatsService(stack, backend, cfg.Ethstats.URL)
 	}
-	return stack, backend
+	return stack, backend, eth
 }
 
 // dumpConfig is the dumpconfig command.
@@ -77,7 +77,7 @@ JavaScript API. See https://geth.ethereum.org/docs/interface/javascript-console`
 func localConsole(ctx *cli.Context) error {
 	// Create and start the node based on the CLI flags
 	prepare(ctx)
-	stack, backend := makeFullNode(ctx)
+	stack, backend, _ := makeFullNode(ctx)
 	startNode(ctx, stack, backend)
 	defer stack.Close()
 
@@ -195,7 +195,7 @@ func dialRPC(endpoint string) (*rpc.Client, error) {
 // everything down.
 func ephemeralConsole(ctx *cli.Context) error {
 	// Create and start the node based on the CLI flags
-	stack, backend := makeFullNode(ctx)
+	stack, backend, _ := makeFullNode(ctx)
 	startNode(ctx, stack, backend)
 	defer stack.Close()
 
@@ -29,6 +29,7 @@ import (
 	"syscall"
 	"time"
 
+	"github.com/olekukonko/tablewriter"
 	"gopkg.in/urfave/cli.v1"
 
 	"github.com/scroll-tech/go-ethereum/cmd/utils"
@@ -38,6 +39,7 @@ import (
 	"github.com/scroll-tech/go-ethereum/core/rawdb"
 	"github.com/scroll-tech/go-ethereum/ethdb"
 	"github.com/scroll-tech/go-ethereum/log"
+	"github.com/scroll-tech/go-ethereum/node"
 	"github.com/scroll-tech/go-ethereum/trie"
 )
 
@@ -70,6 +72,8 @@ Remove blockchain and state databases`,
 			dbDumpFreezerIndex,
 			dbImportCmd,
 			dbExportCmd,
+			dbRollupFreezeCmd,
+			dbRollupInspectCmd,
 		},
 	}
 	dbInspectCmd = cli.Command{
@@ -264,6 +268,57 @@ WARNING: This is a low-level operation which may cause database corruption!`,
 		},
 		Description: "Exports the specified chain data to an RLP encoded stream, optionally gzip-compressed.",
 	}
+	dbRollupInspectCmd = cli.Command{
+		Action: utils.MigrateFlags(rollupInspect),
+		Name:   "rollup-inspect",
+		Usage:  "Inspect the rollup data in the database and check it for inconsistencies",
+		Flags: []cli.Flag{
+			utils.DataDirFlag,
+			utils.AncientFlag,
+			utils.SyncModeFlag,
+			utils.MainnetFlag,
+			utils.RopstenFlag,
+			utils.SepoliaFlag,
+			utils.RinkebyFlag,
+			utils.GoerliFlag,
+			utils.ScrollAlphaFlag,
+			utils.ScrollSepoliaFlag,
+			utils.ScrollFlag,
+		},
+		Description: `This command iterates the batch chunk ranges, finalized batch metadata, batch L1
+metadata and batch end block index, including batches moved into the rollup ancient
+store, and prints their counts, sizes and highest indices along with the synced L1
+block and finalized L2 block. It fails if the tables are inconsistent, e.g. if a
+finalized batch has no chunk ranges.`,
+	}
+	rollupFreezeKeepFlag = cli.Uint64Flag{
+		Name:  "keep",
+		Usage: "Number of most recent finalized batches kept in the key-value store",
+		Value: 100000,
+	}
+	dbRollupFreezeCmd = cli.Command{
+		Action: utils.MigrateFlags(rollupFreeze),
+		Name:   "rollup-freeze",
+		Usage:  "Move the metadata of old finalized batches into the rollup ancient store",
+		Flags: []cli.Flag{
+			utils.DataDirFlag,
+			utils.AncientFlag,
+			utils.SyncModeFlag,
+			utils.MainnetFlag,
+			utils.RopstenFlag,
+			utils.SepoliaFlag,
+			utils.RinkebyFlag,
+			utils.GoerliFlag,
+			utils.ScrollAlphaFlag,
+			utils.ScrollSepoliaFlag,
+			utils.ScrollFlag,
+			rollupFreezeKeepFlag,
+		},
+		Description: `This command moves the finalized batch metadata and chunk ranges of all but the
+most recent --keep finalized batches from the key-value store into an append-only
+freezer in the 'rollup' folder of the ancient directory. The node reads frozen
+batches from the freezer transparently. The node must not be running.`,
+	}
 )
 
 func removeDB(ctx *cli.Context) error {
@@ -716,3 +771,90 @@ func exportChaindata(ctx *cli.Context) error {
 	db := utils.MakeChainDatabase(ctx, stack, true)
 	return utils.ExportChaindata(ctx.Args().Get(1), kind, exporter(db), stop)
 }
+
+func rollupFreeze(ctx *cli.Context) error {
+	stack, config := makeConfigNode(ctx)
+	defer stack.Close()
+
+	db, err := rawdb.NewBatchFreezerDatabase(utils.MakeChainDatabase(ctx, stack, false), rawdb.BatchFreezerDir(stack.ResolveAncient("chaindata", config.Eth.DatabaseFreezer)), "", false)
+	if err != nil {
+		return fmt.Errorf("failed to open rollup ancient store: %w", err)
+	}
+	defer db.Close()
+
+	finalizedL2BlockNumber := rawdb.ReadFinalizedL2BlockNumber(db)
+	if finalizedL2BlockNumber == nil {
+		return errors.New("no finalized batches in database")
+	}
+	lastFinalizedBatchIndex := rawdb.ReadBatchIndexByL2BlockNumber(db, *finalizedL2BlockNumber)
+	if lastFinalizedBatchIndex == nil {
+		return fmt.Errorf("no batch found for finalized L2 block %d", *finalizedL2BlockNumber)
+	}
+	keep := ctx.Uint64(rollupFreezeKeepFlag.Name)
+	if *lastFinalizedBatchIndex+1 <= keep {
+		log.Info("Not enough finalized batches to freeze", "finalized", *lastFinalizedBatchIndex+1, "keep", keep)
+		return nil
+	}
+
+	start := time.Now()
+	frozen, err := db.FreezeBatches(*lastFinalizedBatchIndex + 1 - keep)
+	if err != nil {
+		return err
+	}
+	log.Info("Froze finalized batch metadata", "frozen", frozen, "last finalized batch", *lastFinalizedBatchIndex, "elapsed", common.PrettyDuration(time.Since(start)))
+	return nil
+}
+
+func rollupInspect(ctx *cli.Context) error {
+	stack, config := makeConfigNode(ctx)
+	defer stack.Close()
+
+	chainDb := utils.MakeChainDatabase(ctx, stack, true)
+	db, err := withBatchFreezer(stack, &config, chainDb, true)
+	if err != nil {
+		chainDb.Close()
+		return err
+	}
+	defer db.Close()
+
+	report, err := rawdb.InspectRollupData(db)
+	if err != nil {
+		return err
+	}
+	formatNumber := func(number *uint64) string {
+		if number == nil {
+			return "-"
+		}
+		return strconv.FormatUint(*number, 10)
+	}
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetHeader([]string{"Database", "Category", "Size", "Items", "Highest"})
+	for _, stats := range report.Tables {
+		table.Append([]string{stats.Database, stats.Name, stats.Size.String(), strconv.FormatUint(stats.Count, 10), formatNumber(stats.Highest)})
+	}
+	table.Render()
+	fmt.Printf("Synced L1 block:    %s\n", formatNumber(report.SyncedL1BlockNumber))
+	fmt.Printf("Finalized L2 block: %s\n", formatNumber(report.FinalizedL2BlockNumber))
+
+	for _, inconsistency := range report.Inconsistencies {
+		log.Error("Inconsistent rollup data", "err", inconsistency)
+	}
+	if len(report.Inconsistencies) > 0 {
+		return fmt.Errorf("found %d inconsistencies in rollup data", len(report.Inconsistencies))
+	}
+	return nil
+}
+
+// withBatchFreezer wraps db to read frozen batches from the rollup ancient store
+// if it exists. Closing the returned database also closes db.
+func withBatchFreezer(stack *node.Node, config *gethConfig, db ethdb.Database, readonly bool) (ethdb.Database, error) {
+	dir := rawdb.BatchFreezerDir(stack.ResolveAncient("chaindata", config.Eth.DatabaseFreezer))
+	if !common.FileExist(dir) {
+		return db, nil
+	}
+	frdb, err := rawdb.NewBatchFreezerDatabase(db, dir, "", readonly)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open rollup ancient store: %w", err)
+	}
+	return frdb, nil
+}
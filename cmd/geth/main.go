@@ -95,6 +95,7 @@ var (
 		utils.ExitWhenSyncedFlag,
 		utils.GCModeFlag,
 		utils.SnapshotFlag,
+		utils.SnapshotZktrieFlag,
 		utils.TxLookupLimitFlag,
 		utils.LightServeFlag,
 		utils.LightIngressFlag,
@@ -131,6 +132,9 @@ var (
 		utils.MinerRecommitIntervalFlag,
 		utils.MinerNoVerifyFlag,
 		utils.MinerStoreSkippedTxTracesFlag,
+		utils.MinerDeterministicL1MessagesFlag,
+		utils.MinerL1MessageGasBudgetFlag,
+		utils.MinerMaxSkippedL1MessagesFlag,
 		utils.NATFlag,
 		utils.NoDiscoverFlag,
 		utils.DiscoveryV5Flag,
@@ -158,14 +162,50 @@ var (
 		utils.GpoPercentileFlag,
 		utils.GpoMaxGasPriceFlag,
 		utils.GpoIgnoreGasPriceFlag,
+		utils.GpoL1BaseFeeBlocksFlag,
+		utils.GpoL1BaseFeeWeightFlag,
 		utils.MinerNotifyFullFlag,
 		configFileFlag,
 		utils.CatalystFlag,
 		utils.L1EndpointFlag,
 		utils.L1ConfirmationsFlag,
 		utils.L1DeploymentBlockFlag,
+		utils.L1GetLogsRateLimitFlag,
+		utils.L1GetLogsDailyBudgetFlag,
+		utils.L1VerifiedEndpointFlag,
+		utils.L1ModeFlag,
+		utils.L1SlowQueryThresholdFlag,
+		utils.L1ReceiptsEndpointsFlag,
 		utils.CircuitCapacityCheckEnabledFlag,
+		utils.DualTrieFlag,
 		utils.RollupVerifyEnabledFlag,
+		utils.RollupFollowFlag,
+		utils.RollupL1FollowerFlag,
+		utils.RollupProverTaskQueueFlag,
+		utils.RollupVerifyReexecFlag,
+		utils.RollupUnknownEventPolicyFlag,
+		utils.RollupIgnoredEventsFlag,
+		utils.RollupVerifyModeFlag,
+		utils.RollupSyncConfirmationsFlag,
+		utils.RollupVerifyWorkersFlag,
+		utils.RollupVerifyRateLimitFlag,
+		utils.RollupPayloadCacheFlag,
+		utils.RollupBlobBeaconFlag,
+		utils.RollupBlobArchiveFlag,
+		utils.RollupBlobMirrorFlag,
+		utils.RollupBlobDiskCacheFlag,
+		utils.RollupVerifyStrictFlag,
+		utils.RollupSyncL1TimeoutFlag,
+		utils.RollupSyncCrossCheckFlag,
+		utils.RollupSyncMaxL1StalenessFlag,
+		utils.RollupSyncMetadataSinkFlag,
+		utils.RollupLogMaxBytesFlag,
+		utils.RollupLogCompressFlag,
+		utils.RollupLogSampleFlag,
+		utils.RollupWithdrawRootBackfillFlag,
+		utils.RollupWithdrawRootBackfillRateLimitFlag,
+		utils.KeccakBackendFlag,
+		utils.KZGTrustedSetupFlag,
 	}
 
 	rpcFlags = []cli.Flag{
@@ -192,6 +232,8 @@ var (
 		utils.RPCGlobalEVMTimeoutFlag,
 		utils.RPCGlobalTxFeeCapFlag,
 		utils.AllowUnprotectedTxs,
+		utils.RPCMethodAllowFlag,
+		utils.RPCMethodDenyFlag,
 		utils.MaxBlockRangeFlag,
 	}
 
@@ -249,6 +291,8 @@ func init() {
 		utils.ShowDeprecated,
 		// See snapshot.go
 		snapshotCommand,
+		// See rollupcmd.go
+		rollupCommand,
 	}
 	sort.Sort(cli.CommandsByName(app.Commands))
 
@@ -332,7 +376,7 @@ func geth(ctx *cli.Context) error {
 	}
 
 	prepare(ctx)
-	stack, backend := makeFullNode(ctx)
+	stack, backend, _ := makeFullNode(ctx)
 	defer stack.Close()
 
 	startNode(ctx, stack, backend)
@@ -0,0 +1,626 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"time"
+
+	"gopkg.in/urfave/cli.v1"
+
+	"github.com/scroll-tech/go-ethereum/accounts/abi/bind"
+	"github.com/scroll-tech/go-ethereum/cmd/utils"
+	"github.com/scroll-tech/go-ethereum/common"
+	"github.com/scroll-tech/go-ethereum/common/hexutil"
+	"github.com/scroll-tech/go-ethereum/core"
+	"github.com/scroll-tech/go-ethereum/core/rawdb"
+	"github.com/scroll-tech/go-ethereum/core/types"
+	"github.com/scroll-tech/go-ethereum/crypto"
+	"github.com/scroll-tech/go-ethereum/ethclient"
+	"github.com/scroll-tech/go-ethereum/log"
+	"github.com/scroll-tech/go-ethereum/params"
+	"github.com/scroll-tech/go-ethereum/rollup/devnet"
+	"github.com/scroll-tech/go-ethereum/rollup/rollup_sync_service"
+	"github.com/scroll-tech/go-ethereum/rpc"
+)
+
+var (
+	benchFixturesFlag = cli.StringFlag{
+		Name:  "l1-fixtures",
+		Usage: "Directory of recorded commitBatch calldata and block trace fixtures",
+	}
+	benchIterationsFlag = cli.IntFlag{
+		Name:  "iterations",
+		Usage: "Number of times every fixture is processed",
+		Value: 10,
+	}
+	benchJSONFlag = cli.BoolFlag{
+		Name:  "json",
+		Usage: "Print the report as JSON",
+	}
+	verifyFromFlag = cli.Uint64Flag{
+		Name:  "from",
+		Usage: "Index of the first finalized batch to re-validate",
+	}
+	verifyToFlag = cli.Uint64Flag{
+		Name:  "to",
+		Usage: "Index of the last finalized batch to re-validate (default = last finalized batch)",
+	}
+	devnetKeyFlag = cli.StringFlag{
+		Name:  "key",
+		Usage: "Private key file of the L1 account deploying the ScrollChain contract",
+	}
+	migrateResyncFlag = cli.BoolFlag{
+		Name:  "resync",
+		Usage: "Delete the stored batches, so that the batches of the new deployment are synced from L1",
+	}
+	checkBeaconFlag = cli.StringFlag{
+		Name:  "beacon-endpoint",
+		Usage: "Beacon node API endpoint serving the blobs of the L1 chain",
+	}
+
+	rollupCommand = cli.Command{
+		Name:        "rollup",
+		Usage:       "A set of commands for inspecting rollup data",
+		Category:    "MISCELLANEOUS COMMANDS",
+		Description: "",
+		Subcommands: []cli.Command{
+			{
+				Name:      "decode-calldata",
+				Usage:     "Decode the calldata of a commitBatch transaction",
+				ArgsUsage: "<hexfile>",
+				Action:    decodeCalldata,
+				Category:  "MISCELLANEOUS COMMANDS",
+				Description: `
+geth rollup decode-calldata <hexfile>
+decodes the hex encoded calldata of a commitBatch transaction stored in
+<hexfile> and prints the chunk ranges, block contexts and sizes of the
+batch. It does not require a database.`,
+			},
+			{
+				Name:     "bench",
+				Usage:    "Benchmark decoding and validation of recorded rollup data",
+				Action:   benchRollup,
+				Category: "MISCELLANEOUS COMMANDS",
+				Flags: []cli.Flag{
+					benchFixturesFlag,
+					benchIterationsFlag,
+					benchJSONFlag,
+				},
+				Description: `
+geth rollup bench --l1-fixtures <dir>
+measures the throughput of the rollup sync pipeline on the fixtures in <dir>.
+Files with a "calldata" field hold the hex encoded calldata of a commitBatch
+transaction and are decoded into block contexts, all other .json files hold
+a block trace and are validated together as one batch. It does not require
+a database, so reports are comparable between releases.`,
+			},
+			{
+				Name:      "check-vectors",
+				Usage:     "Check the batch hash computation against protocol test vectors",
+				ArgsUsage: "<vectorfile>",
+				Action:    checkVectors,
+				Category:  "MISCELLANEOUS COMMANDS",
+				Description: `
+geth rollup check-vectors <vectorfile>
+computes the batch hash of every test vector in <vectorfile> with the codec
+of its version and compares it with the hash expected by the ScrollChain
+contract. The file holds a JSON list of vectors with the fields name,
+codecVersion, batchIndex, totalL1MessagePoppedBefore, parentBatchHash,
+chunks and batchHash, where every chunk is a list of wrapped blocks or of
+paths of block trace files relative to <vectorfile>. It fails if any vector
+does not match and warns about codec versions without vectors. It does not
+require a database.`,
+			},
+			{
+				Name:     "verify",
+				Usage:    "Re-validate finalized batches against the local chain",
+				Action:   utils.MigrateFlags(verifyRollup),
+				Category: "MISCELLANEOUS COMMANDS",
+				Flags: []cli.Flag{
+					utils.DataDirFlag,
+					utils.AncientFlag,
+					utils.SyncModeFlag,
+					utils.MainnetFlag,
+					utils.RopstenFlag,
+					utils.SepoliaFlag,
+					utils.RinkebyFlag,
+					utils.GoerliFlag,
+					utils.ScrollAlphaFlag,
+					utils.ScrollSepoliaFlag,
+					utils.ScrollFlag,
+					utils.RollupVerifyWorkersFlag,
+					utils.RollupVerifyReexecFlag,
+					verifyFromFlag,
+					verifyToFlag,
+				},
+				Description: `
+geth rollup verify --from N [--to M]
+recomputes the batch hash, state root and withdraw root of the finalized
+batches N to M from the local chain and compares them with the stored
+batch metadata, without connecting to L1. It is useful after restoring a
+database or on suspected corruption. The node must not be running. It fails
+if any batch does not match.`,
+			},
+			{
+				Name:     "migrate-scrollchain",
+				Usage:    "Adopt a changed ScrollChain address for the stored batches",
+				Action:   utils.MigrateFlags(migrateScrollChain),
+				Category: "MISCELLANEOUS COMMANDS",
+				Flags: []cli.Flag{
+					utils.DataDirFlag,
+					utils.AncientFlag,
+					utils.MainnetFlag,
+					utils.RopstenFlag,
+					utils.SepoliaFlag,
+					utils.RinkebyFlag,
+					utils.GoerliFlag,
+					utils.ScrollAlphaFlag,
+					utils.ScrollSepoliaFlag,
+					utils.ScrollFlag,
+					migrateResyncFlag,
+				},
+				Description: `
+geth rollup migrate-scrollchain [--resync]
+records the ScrollChain address of the genesis as the deployment of the
+stored batches. The rollup sync refuses to start if the configured address
+differs from the address the stored batches were synced from, as the events
+of two deployments do not form one batch chain. Without --resync the stored
+batches are kept, for a new deployment that continues the batch chain of the
+previous one. With --resync the rollup event store and the rollup ancient
+store are deleted and the batches are synced again from the L1 deployment
+block. The node must not be running.`,
+			},
+			{
+				Name:      "check-l1",
+				Usage:     "Check the L1 endpoint before starting the rollup sync",
+				ArgsUsage: "[<genesisPath>]",
+				Action:    utils.MigrateFlags(checkL1),
+				Category:  "MISCELLANEOUS COMMANDS",
+				Flags: []cli.Flag{
+					utils.L1EndpointFlag,
+					utils.L1DeploymentBlockFlag,
+					utils.ScrollAlphaFlag,
+					utils.ScrollSepoliaFlag,
+					utils.ScrollFlag,
+					checkBeaconFlag,
+				},
+				Description: `
+geth rollup check-l1 --l1.endpoint <url> [--beacon-endpoint <url>] [<genesisPath>]
+checks that the L1 endpoint serves what the rollup sync relies on: the L1
+chain ID of the genesis, the code of the ScrollChain contract, its events
+from the deployment block on, the finalized block tag and, with a beacon
+node endpoint, the availability of blobs. The L1 config is taken from the
+selected Scroll network or from the genesis in <genesisPath>, the deployment
+block from --l1.sync.startblock or the network default. It prints a
+pass/fail report and fails if any check fails. It does not require a
+database.`,
+			},
+			{
+				Name:      "devnet-init",
+				Usage:     "Bootstrap a local rollup against a development L1 chain",
+				ArgsUsage: "<genesisPath>",
+				Action:    utils.MigrateFlags(devnetInit),
+				Category:  "MISCELLANEOUS COMMANDS",
+				Flags: []cli.Flag{
+					utils.DataDirFlag,
+					utils.L1EndpointFlag,
+					devnetKeyFlag,
+				},
+				Description: `
+geth rollup devnet-init --l1.endpoint <url> --key <keyfile> <genesisPath>
+deploys a minimal ScrollChain-compatible contract to the L1 chain at <url>,
+e.g. anvil or geth --dev, from the account of <keyfile>. The contract emits
+the events of ScrollChain, but does not verify the submitted batches.
+
+The L1 config of the genesis in <genesisPath> is pointed at the contract,
+the resulting genesis is written to devnet-genesis.json in the data
+directory and used to initialise the database. The node is then started
+with the L1 message and rollup sync services following the contract from
+its deployment block. Batches, including the genesis batch, are submitted
+by the operator through the usual ScrollChain functions, or built from the
+local chain and submitted through the devnet RPC namespace, which is served
+on IPC and on HTTP or WebSocket if enabled with --http.api or --ws.api:
+devnet_importGenesisBatch, devnet_commitBatch, devnet_revertBatches,
+devnet_finalizeBatch and devnet_simulateRevert, which reverts batches,
+re-commits them with the given chunks and finalizes them. Each call returns
+once the rollup sync service has processed its L1 transaction.`,
+			},
+		},
+	}
+)
+
+// decodeCalldata decodes the commitBatch calldata stored in a file.
+func decodeCalldata(ctx *cli.Context) error {
+	if ctx.NArg() != 1 {
+		return fmt.Errorf("required arguments: %v", ctx.Command.ArgsUsage)
+	}
+	data, err := os.ReadFile(ctx.Args().Get(0))
+	if err != nil {
+		return err
+	}
+	input := strings.TrimSpace(string(data))
+	if !strings.HasPrefix(input, "0x") {
+		input = "0x" + input
+	}
+	txData, err := hexutil.Decode(input)
+	if err != nil {
+		return fmt.Errorf("failed to hex-decode calldata: %v", err)
+	}
+	return printCommitBatchCalldata(os.Stdout, txData)
+}
+
+// printCommitBatchCalldata writes a human readable description of commitBatch calldata to w.
+func printCommitBatchCalldata(w io.Writer, txData []byte) error {
+	calldata, err := rollup_sync_service.DecodeCommitBatchCalldata(txData)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(w, "Calldata size:              %d bytes\n", len(txData))
+	fmt.Fprintf(w, "Batch version:              %d\n", calldata.Version)
+	fmt.Fprintf(w, "Parent batch header:        %d bytes\n", len(calldata.ParentBatchHeader))
+	fmt.Fprintf(w, "Skipped L1 message bitmap:  %d bytes\n", len(calldata.SkippedL1MessageBitmap))
+	fmt.Fprintf(w, "Chunks:                     %d\n", len(calldata.Chunks))
+
+	codec, err := rollup_sync_service.CodecForVersion(calldata.Version)
+	if err != nil {
+		return err
+	}
+	chunkBlockContexts, err := codec.DecodeChunkBlockContexts(calldata.Chunks)
+	if err != nil {
+		return fmt.Errorf("failed to decode block contexts: %v", err)
+	}
+	for i, blockContexts := range chunkBlockContexts {
+		fmt.Fprintf(w, "\nChunk %d: %d bytes, blocks %d-%d\n", i, len(calldata.Chunks[i]), blockContexts[0].BlockNumber, blockContexts[len(blockContexts)-1].BlockNumber)
+		for _, block := range blockContexts {
+			fmt.Fprintf(w, "  block %d: timestamp=%d baseFee=%v gasLimit=%d txs=%d l1Messages=%d\n",
+				block.BlockNumber, block.Timestamp, block.BaseFee, block.GasLimit, block.NumTransactions, block.NumL1Messages)
+		}
+	}
+	return nil
+}
+
+// benchRollup runs the rollup pipeline benchmark and prints its report.
+func benchRollup(ctx *cli.Context) error {
+	dir := ctx.String(benchFixturesFlag.Name)
+	if dir == "" {
+		return fmt.Errorf("missing --%v", benchFixturesFlag.Name)
+	}
+	report, err := rollup_sync_service.RunBench(dir, ctx.Int(benchIterationsFlag.Name))
+	if err != nil {
+		return err
+	}
+	if ctx.Bool(benchJSONFlag.Name) {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(report)
+	}
+	printBenchReport(os.Stdout, report)
+	return nil
+}
+
+// printBenchReport writes a human readable benchmark report to w.
+func printBenchReport(w io.Writer, report *rollup_sync_service.BenchReport) {
+	fmt.Fprintf(w, "Iterations:                 %d\n", report.Iterations)
+	fmt.Fprintf(w, "\nDecode\n")
+	fmt.Fprintf(w, "  calldata fixtures:        %d (%d bytes)\n", report.CalldataFixtures, report.CalldataBytes)
+	fmt.Fprintf(w, "  chunks per iteration:     %d\n", report.DecodedChunks)
+	fmt.Fprintf(w, "  blocks per iteration:     %d\n", report.DecodedBlocks)
+	fmt.Fprintf(w, "  total time:               %v\n", report.DecodeTime)
+	fmt.Fprintf(w, "  throughput:               %.0f blocks/s\n", report.DecodeBlockRate)
+	fmt.Fprintf(w, "\nValidate\n")
+	fmt.Fprintf(w, "  block fixtures:           %d\n", report.BlockFixtures)
+	fmt.Fprintf(w, "  total time:               %v\n", report.ValidateTime)
+	fmt.Fprintf(w, "  throughput:               %.0f blocks/s\n", report.ValidateBlockRate)
+}
+
+// checkVectors checks the batch hash computation against the test vectors of a file.
+func checkVectors(ctx *cli.Context) error {
+	if ctx.NArg() != 1 {
+		return fmt.Errorf("required arguments: %v", ctx.Command.ArgsUsage)
+	}
+	results, err := rollup_sync_service.CheckBatchVectors(ctx.Args().Get(0))
+	if err != nil {
+		return err
+	}
+	failed := printVectorResults(os.Stdout, results)
+	for _, version := range rollup_sync_service.UncoveredCodecVersions(results) {
+		log.Warn("No test vectors for codec version", "version", version)
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d test vectors failed", failed, len(results))
+	}
+	return nil
+}
+
+// printVectorResults writes one line per checked test vector to w and returns the
+// number of failed vectors.
+func printVectorResults(w io.Writer, results []*rollup_sync_service.BatchVectorResult) int {
+	var failed int
+	for _, result := range results {
+		switch {
+		case result.Err != nil:
+			failed++
+			fmt.Fprintf(w, "FAIL  codec v%d  %s: %v\n", result.CodecVersion, result.Name, result.Err)
+		case !result.Passed():
+			failed++
+			fmt.Fprintf(w, "FAIL  codec v%d  %s: expected %s, computed %s\n", result.CodecVersion, result.Name, result.Expected.Hex(), result.Computed.Hex())
+		default:
+			fmt.Fprintf(w, "ok    codec v%d  %s: %s\n", result.CodecVersion, result.Name, result.Computed.Hex())
+		}
+	}
+	return failed
+}
+
+// verifyRollup re-validates a range of finalized batches against the local chain.
+func verifyRollup(ctx *cli.Context) error {
+	if !ctx.IsSet(verifyFromFlag.Name) {
+		return fmt.Errorf("missing --%v", verifyFromFlag.Name)
+	}
+	stack, config := makeConfigNode(ctx)
+	defer stack.Close()
+
+	// note: the chain is opened read-write, as the state may be regenerated by re-execution.
+	chain, chainDb := utils.MakeChain(ctx, stack)
+	db, err := withBatchFreezer(stack, &config, chainDb, true)
+	if err != nil {
+		chain.Stop()
+		chainDb.Close()
+		return err
+	}
+	defer db.Close()
+	defer chain.Stop()
+
+	from, to := ctx.Uint64(verifyFromFlag.Name), ctx.Uint64(verifyToFlag.Name)
+	if !ctx.IsSet(verifyToFlag.Name) {
+		finalizedL2BlockNumber := rawdb.ReadFinalizedL2BlockNumber(db)
+		if finalizedL2BlockNumber == nil {
+			return errors.New("no finalized batches in database")
+		}
+		lastFinalizedBatchIndex := rawdb.ReadBatchIndexByL2BlockNumber(db, *finalizedL2BlockNumber)
+		if lastFinalizedBatchIndex == nil {
+			return fmt.Errorf("no batch found for finalized L2 block %d", *finalizedL2BlockNumber)
+		}
+		to = *lastFinalizedBatchIndex
+	}
+
+	interrupt, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer cancel()
+
+	var valid, invalid int
+	start := time.Now()
+	err = rollup_sync_service.RevalidateBatches(interrupt, db, chain, &config.Eth.RollupSync, from, to, func(result *rollup_sync_service.RevalidationResult) error {
+		if result.Valid {
+			valid++
+		} else {
+			invalid++
+			for _, mismatch := range result.Mismatches {
+				fmt.Printf("batch %d: %s mismatch, stored: %s, computed: %s\n", result.BatchIndex, mismatch.Field, mismatch.Stored, mismatch.Computed)
+			}
+		}
+		return nil
+	})
+	log.Info("Re-validated finalized batches", "from", from, "to", to, "valid", valid, "invalid", invalid, "elapsed", common.PrettyDuration(time.Since(start)))
+	if err != nil {
+		return err
+	}
+	if invalid > 0 {
+		return fmt.Errorf("%d of %d batches do not match the local chain", invalid, valid+invalid)
+	}
+	return nil
+}
+
+// migrateScrollChain records the configured ScrollChain address for the stored batches.
+func migrateScrollChain(ctx *cli.Context) error {
+	stack, config := makeConfigNode(ctx)
+	defer stack.Close()
+
+	db := utils.MakeChainDatabase(ctx, stack, false)
+	defer db.Close()
+	chainConfig, _, err := core.SetupGenesisBlock(db, utils.MakeGenesis(ctx))
+	if err != nil {
+		return err
+	}
+	if chainConfig.Scroll.L1Config == nil {
+		return errors.New("missing L1 config in genesis")
+	}
+	address := chainConfig.Scroll.L1Config.ScrollChainAddress
+	if stored := rawdb.ReadRollupScrollChainAddress(db); stored != nil && *stored == address && !ctx.Bool(migrateResyncFlag.Name) {
+		fmt.Printf("Stored batches were synced from ScrollChain %v already\n", address.Hex())
+		return nil
+	}
+	if ctx.Bool(migrateResyncFlag.Name) {
+		dir := rawdb.BatchFreezerDir(stack.ResolveAncient("chaindata", config.Eth.DatabaseFreezer))
+		if err := os.RemoveAll(dir); err != nil {
+			return fmt.Errorf("failed to delete rollup ancient store: %v", err)
+		}
+	}
+	rollup_sync_service.MigrateScrollChain(db, address, ctx.Bool(migrateResyncFlag.Name))
+	return nil
+}
+
+// checkL1 runs the L1 self-test and prints its report.
+func checkL1(ctx *cli.Context) error {
+	endpoint := strings.Split(ctx.GlobalString(utils.L1EndpointFlag.Name), ",")[0]
+	if endpoint == "" {
+		return fmt.Errorf("missing --%v", utils.L1EndpointFlag.Name)
+	}
+	genesis := utils.MakeGenesis(ctx)
+	if genesis == nil {
+		genesisPath := ctx.Args().First()
+		if len(genesisPath) == 0 {
+			return errors.New("select a Scroll network or pass <genesisPath>")
+		}
+		data, err := os.ReadFile(genesisPath)
+		if err != nil {
+			return err
+		}
+		genesis = new(core.Genesis)
+		if err := json.Unmarshal(data, genesis); err != nil {
+			return fmt.Errorf("invalid genesis file: %v", err)
+		}
+	}
+	if genesis.Config == nil || genesis.Config.Scroll.L1Config == nil {
+		return errors.New("genesis has no L1 config")
+	}
+	deploymentBlock := utils.DefaultL1DeploymentBlock(ctx)
+	if ctx.GlobalIsSet(utils.L1DeploymentBlockFlag.Name) {
+		deploymentBlock = ctx.GlobalUint64(utils.L1DeploymentBlockFlag.Name)
+	}
+
+	checkCtx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	client, err := ethclient.DialContext(checkCtx, endpoint)
+	if err != nil {
+		return fmt.Errorf("failed to connect to L1: %v", err)
+	}
+	defer client.Close()
+
+	results := rollup_sync_service.CheckL1(checkCtx, client, genesis.Config.Scroll.L1Config, deploymentBlock, ctx.String(checkBeaconFlag.Name))
+	if failed := printL1CheckResults(os.Stdout, results); failed > 0 {
+		return fmt.Errorf("%d of %d L1 checks failed", failed, len(results))
+	}
+	return nil
+}
+
+// printL1CheckResults writes one line per L1 check to w and returns the number of
+// failed checks.
+func printL1CheckResults(w io.Writer, results []*rollup_sync_service.L1CheckResult) int {
+	var failed int
+	for _, result := range results {
+		switch {
+		case result.Err != nil:
+			failed++
+			fmt.Fprintf(w, "FAIL  %s: %v\n", result.Name, result.Err)
+		case result.Skipped:
+			fmt.Fprintf(w, "skip  %s: %s\n", result.Name, result.Detail)
+		default:
+			fmt.Fprintf(w, "ok    %s: %s\n", result.Name, result.Detail)
+		}
+	}
+	return failed
+}
+
+// devnetInit deploys the mock ScrollChain contract, initialises the database with a
+// genesis pointing at it and starts the node with the rollup sync services enabled.
+func devnetInit(ctx *cli.Context) error {
+	genesisPath := ctx.Args().First()
+	if len(genesisPath) == 0 {
+		return fmt.Errorf("required arguments: %v", ctx.Command.ArgsUsage)
+	}
+	endpoint := strings.Split(ctx.GlobalString(utils.L1EndpointFlag.Name), ",")[0]
+	if endpoint == "" {
+		return fmt.Errorf("missing --%v", utils.L1EndpointFlag.Name)
+	}
+	if !ctx.IsSet(devnetKeyFlag.Name) {
+		return fmt.Errorf("missing --%v", devnetKeyFlag.Name)
+	}
+	key, err := crypto.LoadECDSA(ctx.String(devnetKeyFlag.Name))
+	if err != nil {
+		return fmt.Errorf("failed to load key: %v", err)
+	}
+	data, err := os.ReadFile(genesisPath)
+	if err != nil {
+		return err
+	}
+	genesis := new(core.Genesis)
+	if err := json.Unmarshal(data, genesis); err != nil {
+		return fmt.Errorf("invalid genesis file: %v", err)
+	}
+	if genesis.Config == nil || genesis.Config.ChainID == nil {
+		return errors.New("genesis has no chain ID")
+	}
+
+	client, err := ethclient.Dial(endpoint)
+	if err != nil {
+		return fmt.Errorf("failed to connect to L1: %v", err)
+	}
+	defer client.Close()
+	deployCtx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	l1ChainID, err := client.ChainID(deployCtx)
+	if err != nil {
+		return fmt.Errorf("failed to query L1 chain ID: %v", err)
+	}
+	auth, err := bind.NewKeyedTransactorWithChainID(key, l1ChainID)
+	if err != nil {
+		return err
+	}
+	address, tx, err := devnet.DeployScrollChain(auth, client, genesis.Config.ChainID.Uint64())
+	if err != nil {
+		return fmt.Errorf("failed to deploy ScrollChain contract: %v", err)
+	}
+	receipt, err := bind.WaitMined(deployCtx, client, tx)
+	if err != nil {
+		return fmt.Errorf("failed to wait for deployment: %v", err)
+	}
+	if receipt.Status != types.ReceiptStatusSuccessful {
+		return fmt.Errorf("deployment transaction %v failed", tx.Hash().Hex())
+	}
+	log.Info("Deployed ScrollChain contract", "address", address, "l1 chain id", l1ChainID, "l1 block", receipt.BlockNumber)
+
+	// note: the contract does not emit L1 messages, it only serves as an empty message queue.
+	l1Config := &params.L1Config{
+		L1ChainId:             l1ChainID.Uint64(),
+		L1MessageQueueAddress: address,
+		ScrollChainAddress:    address,
+	}
+	if genesis.Config.Scroll.L1Config != nil {
+		l1Config.NumL1MessagesPerBlock = genesis.Config.Scroll.L1Config.NumL1MessagesPerBlock
+	}
+	genesis.Config.Scroll.L1Config = l1Config
+
+	stack, _ := makeConfigNode(ctx)
+	out, err := json.MarshalIndent(genesis, "", "  ")
+	if err != nil {
+		stack.Close()
+		return err
+	}
+	outPath := filepath.Join(stack.DataDir(), "devnet-genesis.json")
+	if err := os.WriteFile(outPath, out, 0644); err != nil {
+		stack.Close()
+		return err
+	}
+	log.Info("Wrote devnet genesis", "path", outPath)
+	err = writeGenesis(stack, genesis)
+	stack.Close()
+	if err != nil {
+		return err
+	}
+
+	for name, value := range map[string]string{
+		utils.L1DeploymentBlockFlag.Name:   receipt.BlockNumber.String(),
+		utils.RollupVerifyEnabledFlag.Name: "true",
+	} {
+		if err := ctx.GlobalSet(name, value); err != nil {
+			return err
+		}
+	}
+	prepare(ctx)
+	stack, backend, eth := makeFullNode(ctx)
+	defer stack.Close()
+	if eth == nil {
+		return errors.New("devnet-init does not work in light client mode")
+	}
+	simulator, err := devnet.NewSimulator(eth.RollupSyncService(), address, auth, client)
+	if err != nil {
+		return err
+	}
+	stack.RegisterAPIs([]rpc.API{{
+		Namespace: "devnet",
+		Version:   "1.0",
+		Service:   devnet.NewAPI(simulator),
+		Public:    false,
+	}})
+
+	startNode(ctx, stack, backend)
+	stack.Wait()
+	return nil
+}
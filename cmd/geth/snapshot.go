@@ -17,10 +17,18 @@
 package main
 
 import (
+	"bufio"
 	"bytes"
 	"encoding/json"
 	"errors"
+	"fmt"
+	"io"
+	"math/big"
 	"os"
+	"os/signal"
+	"path/filepath"
+	"strconv"
+	"syscall"
 	"time"
 
 	cli "gopkg.in/urfave/cli.v1"
@@ -29,10 +37,12 @@ import (
 	"github.com/scroll-tech/go-ethereum/common"
 	"github.com/scroll-tech/go-ethereum/core/rawdb"
 	"github.com/scroll-tech/go-ethereum/core/state"
+	"github.com/scroll-tech/go-ethereum/core/state/migrator"
 	"github.com/scroll-tech/go-ethereum/core/state/pruner"
 	"github.com/scroll-tech/go-ethereum/core/state/snapshot"
 	"github.com/scroll-tech/go-ethereum/core/types"
 	"github.com/scroll-tech/go-ethereum/crypto/codehash"
+	"github.com/scroll-tech/go-ethereum/ethdb"
 	"github.com/scroll-tech/go-ethereum/log"
 	"github.com/scroll-tech/go-ethereum/rlp"
 	"github.com/scroll-tech/go-ethereum/trie"
@@ -191,12 +201,100 @@ as the backend data source, making this command a lot faster.
 
 The argument is interpreted as block number or hash. If none is provided, the latest
 block is used.
+`,
+			},
+			{
+				Name:     "export",
+				Usage:    "Export the state at the last block of a finalized batch",
+				Action:   utils.MigrateFlags(exportState),
+				Category: "MISCELLANEOUS COMMANDS",
+				Flags: []cli.Flag{
+					utils.DataDirFlag,
+					utils.AncientFlag,
+					utils.RopstenFlag,
+					utils.SepoliaFlag,
+					utils.RinkebyFlag,
+					utils.GoerliFlag,
+					utils.ScrollAlphaFlag,
+					utils.ScrollSepoliaFlag,
+					utils.ScrollFlag,
+					exportFinalizedBatchFlag,
+					exportOutputFlag,
+				},
+				Description: `
+geth snapshot export --at-finalized-batch <batchIndex> [--output <dir>]
+
+This command exports the state at the last block of the given finalized batch from
+the snapshot. The output directory contains the state in the format of 'geth snapshot
+dump' and a manifest with the batch hash, block and state root the export was taken
+at, so that consumers can verify the export against the batch finalized on L1.
+
+The batch must be finalized by the rollup verifier, and the state root of its last
+block must match the finalized state root. The snapshot must still contain the state
+of that block, i.e. the block must be among the most recent 128 blocks or the
+snapshot disk layer.
+`,
+			},
+			{
+				Name:      "migrate-trie",
+				Usage:     "Migrate the state into the other trie ahead of the state scheme switch",
+				ArgsUsage: "[? <blockHash> | <blockNum>]",
+				Action:    utils.MigrateFlags(migrateTrie),
+				Category:  "MISCELLANEOUS COMMANDS",
+				Flags: []cli.Flag{
+					utils.DataDirFlag,
+					utils.AncientFlag,
+					utils.RopstenFlag,
+					utils.SepoliaFlag,
+					utils.RinkebyFlag,
+					utils.GoerliFlag,
+					utils.ScrollAlphaFlag,
+					utils.ScrollSepoliaFlag,
+					utils.ScrollFlag,
+				},
+				Description: `
+geth snapshot migrate-trie [? <blockHash> | <blockNum>]
+will copy the state after the given block from the zktrie into the MPT, or from the
+MPT into the zktrie, depending on the trie the state of the block is kept in. The
+migrated state is written next to the existing one, and its root is recorded for the
+block. The default migration target is the HEAD state.
+
+The progress of the migration is checkpointed, an interrupted migration of the same
+block resumes where it was left, also in the node. Once the state of a block before
+the state scheme switch block (scroll.trieSwitchBlock of the chain config) is
+migrated, the node only replays the blocks since then on the migrated state, and
+continues on the migrated state from the switch block on.
+
+The keys of the state are resolved through their preimages, so the state must have
+been built with the recording of preimages enabled (--cache.preimages).
 `,
 			},
 		},
 	}
+
+	exportFinalizedBatchFlag = cli.Uint64Flag{
+		Name:  "at-finalized-batch",
+		Usage: "Index of the finalized batch whose last block the state is exported at",
+	}
+	exportOutputFlag = cli.StringFlag{
+		Name:  "output",
+		Usage: "Directory the state export and manifest are written to",
+		Value: "state-export",
+	}
 )
 
+// exportManifest describes a state export taken at the last block of a finalized batch.
+type exportManifest struct {
+	BatchIndex   uint64      `json:"batchIndex"`
+	BatchHash    common.Hash `json:"batchHash"`
+	BlockNumber  uint64      `json:"blockNumber"`
+	BlockHash    common.Hash `json:"blockHash"`
+	StateRoot    common.Hash `json:"stateRoot"`
+	WithdrawRoot common.Hash `json:"withdrawRoot"`
+	Accounts     uint64      `json:"accounts"`
+	StateFile    string      `json:"stateFile"`
+}
+
 func pruneState(ctx *cli.Context) error {
 	stack, config := makeConfigNode(ctx)
 	defer stack.Close()
@@ -236,7 +334,12 @@ func verifyState(ctx *cli.Context) error {
 		log.Error("Failed to load head block")
 		return errors.New("no head block")
 	}
-	snaptree, err := snapshot.New(chaindb, trie.NewDatabase(chaindb), 256, headBlock.Root(), false, false, false)
+	triedb, err := stateTrieDatabase(chaindb, headBlock.Number())
+	if err != nil {
+		log.Error("Failed to open trie database", "err", err)
+		return err
+	}
+	snaptree, err := snapshot.New(chaindb, triedb, 256, headBlock.Root(), false, false, false)
 	if err != nil {
 		log.Error("Failed to open snapshot tree", "err", err)
 		return err
@@ -293,8 +396,13 @@ func traverseState(ctx *cli.Context) error {
 		root = headBlock.Root()
 		log.Info("Start traversing the state", "root", root, "number", headBlock.NumberU64())
 	}
-	triedb := trie.NewDatabase(chaindb)
-	t, err := trie.NewSecure(root, triedb)
+	// the state is traversed in the trie scheme of the head state
+	triedb, err := stateTrieDatabase(chaindb, headBlock.Number())
+	if err != nil {
+		log.Error("Failed to open trie database", "err", err)
+		return err
+	}
+	t, err := openStateTrie(triedb, root)
 	if err != nil {
 		log.Error("Failed to open trie", "root", root, "err", err)
 		return err
@@ -309,13 +417,13 @@ func traverseState(ctx *cli.Context) error {
 	accIter := trie.NewIterator(t.NodeIterator(nil))
 	for accIter.Next() {
 		accounts += 1
-		var acc types.StateAccount
-		if err := rlp.DecodeBytes(accIter.Value, &acc); err != nil {
+		acc, err := decodeStateAccount(triedb, accIter.Value)
+		if err != nil {
 			log.Error("Invalid account encountered during traversal", "err", err)
 			return err
 		}
-		if acc.Root != emptyRoot {
-			storageTrie, err := trie.NewSecure(acc.Root, triedb)
+		if acc.Root != emptyRoot && acc.Root != (common.Hash{}) {
+			storageTrie, err := openStateTrie(triedb, acc.Root)
 			if err != nil {
 				log.Error("Failed to open storage trie", "root", acc.Root, "err", err)
 				return err
@@ -383,7 +491,15 @@ func traverseRawState(ctx *cli.Context) error {
 		root = headBlock.Root()
 		log.Info("Start traversing the state", "root", root, "number", headBlock.NumberU64())
 	}
-	triedb := trie.NewDatabase(chaindb)
+	triedb, err := stateTrieDatabase(chaindb, headBlock.Number())
+	if err != nil {
+		log.Error("Failed to open trie database", "err", err)
+		return err
+	}
+	if triedb.Zktrie {
+		log.Error("Raw traversal of zktrie states is not supported, use traverse-state")
+		return errors.New("zktrie state")
+	}
 	t, err := trie.NewSecure(root, triedb)
 	if err != nil {
 		log.Error("Failed to open trie", "root", root, "err", err)
@@ -472,6 +588,42 @@ func traverseRawState(ctx *cli.Context) error {
 	return nil
 }
 
+// stateTrieDatabase opens the trie database of the state after the block with the given
+// number, in the trie scheme of the state.
+func stateTrieDatabase(chaindb ethdb.Database, number *big.Int) (*trie.Database, error) {
+	config := rawdb.ReadChainConfig(chaindb, rawdb.ReadCanonicalHash(chaindb, 0))
+	if config == nil {
+		return nil, errors.New("no chain config")
+	}
+	return trie.NewDatabaseWithConfig(chaindb, &trie.Config{Zktrie: config.Scroll.ZktrieEnabledAt(number)}), nil
+}
+
+// iterableTrie is a trie whose nodes can be iterated, either a Merkle Patricia trie or a
+// zktrie.
+type iterableTrie interface {
+	NodeIterator(start []byte) trie.NodeIterator
+}
+
+// openStateTrie opens an account or storage trie in the trie scheme of triedb.
+func openStateTrie(triedb *trie.Database, root common.Hash) (iterableTrie, error) {
+	if triedb.Zktrie {
+		return trie.NewZkTrie(root, trie.NewZktrieDatabaseFromTriedb(triedb))
+	}
+	return trie.NewSecure(root, triedb)
+}
+
+// decodeStateAccount decodes a leaf of an account trie in the trie scheme of triedb.
+func decodeStateAccount(triedb *trie.Database, blob []byte) (*types.StateAccount, error) {
+	if triedb.Zktrie {
+		return types.UnmarshalStateAccount(blob)
+	}
+	acc := new(types.StateAccount)
+	if err := rlp.DecodeBytes(blob, acc); err != nil {
+		return nil, err
+	}
+	return acc, nil
+}
+
 func parseRoot(input string) (common.Hash, error) {
 	var h common.Hash
 	if err := h.UnmarshalText([]byte(input)); err != nil {
@@ -484,18 +636,29 @@ func dumpState(ctx *cli.Context) error {
 	stack, _ := makeConfigNode(ctx)
 	defer stack.Close()
 
-	conf, db, root, err := parseDumpConfig(ctx, stack)
+	conf, db, header, err := parseDumpConfig(ctx, stack)
 	if err != nil {
 		return err
 	}
-	snaptree, err := snapshot.New(db, trie.NewDatabase(db), 256, root, false, false, false)
+	triedb, err := stateTrieDatabase(db, header.Number)
 	if err != nil {
 		return err
 	}
-	accIt, err := snaptree.AccountIterator(root, common.BytesToHash(conf.Start))
+	snaptree, err := snapshot.New(db, triedb, 256, header.Root, false, false, false)
 	if err != nil {
 		return err
 	}
+	_, err = dumpSnapshot(snaptree, db, header.Root, conf, os.Stdout)
+	return err
+}
+
+// dumpSnapshot writes the state with the given root from the snapshot tree to w
+// as newline-separated JSON, and returns the number of accounts written.
+func dumpSnapshot(snaptree *snapshot.Tree, db ethdb.Database, root common.Hash, conf *state.DumpConfig, w io.Writer) (uint64, error) {
+	accIt, err := snaptree.AccountIterator(root, common.BytesToHash(conf.Start))
+	if err != nil {
+		return 0, err
+	}
 	defer accIt.Release()
 
 	log.Info("Snapshot dumping started", "root", root)
@@ -504,14 +667,16 @@ func dumpState(ctx *cli.Context) error {
 		logged   = time.Now()
 		accounts uint64
 	)
-	enc := json.NewEncoder(os.Stdout)
-	enc.Encode(struct {
+	enc := json.NewEncoder(w)
+	if err := enc.Encode(struct {
 		Root common.Hash `json:"root"`
-	}{root})
+	}{root}); err != nil {
+		return 0, err
+	}
 	for accIt.Next() {
 		account, err := snapshot.FullAccount(accIt.Account())
 		if err != nil {
-			return err
+			return accounts, err
 		}
 		da := &state.DumpAccount{
 			Balance:          account.Balance.String(),
@@ -530,13 +695,16 @@ func dumpState(ctx *cli.Context) error {
 
 			stIt, err := snaptree.StorageIterator(root, accIt.Hash(), common.Hash{})
 			if err != nil {
-				return err
+				return accounts, err
 			}
 			for stIt.Next() {
 				da.Storage[stIt.Hash()] = common.Bytes2Hex(stIt.Slot())
 			}
+			stIt.Release()
+		}
+		if err := enc.Encode(da); err != nil {
+			return accounts, err
 		}
-		enc.Encode(da)
 		accounts++
 		if time.Since(logged) > 8*time.Second {
 			log.Info("Snapshot dumping in progress", "at", accIt.Hash(), "accounts", accounts,
@@ -547,7 +715,182 @@ func dumpState(ctx *cli.Context) error {
 			break
 		}
 	}
+	if err := accIt.Error(); err != nil {
+		return accounts, err
+	}
 	log.Info("Snapshot dumping complete", "accounts", accounts,
 		"elapsed", common.PrettyDuration(time.Since(start)))
+	return accounts, nil
+}
+
+func exportState(ctx *cli.Context) error {
+	if !ctx.IsSet(exportFinalizedBatchFlag.Name) {
+		return fmt.Errorf("--%s is required", exportFinalizedBatchFlag.Name)
+	}
+	batchIndex := ctx.Uint64(exportFinalizedBatchFlag.Name)
+
+	stack, config := makeConfigNode(ctx)
+	defer stack.Close()
+
+	chainDb := utils.MakeChainDatabase(ctx, stack, true)
+	db, err := withBatchFreezer(stack, &config, chainDb, true)
+	if err != nil {
+		chainDb.Close()
+		return err
+	}
+	defer db.Close()
+
+	manifest, err := finalizedBatchManifest(db, batchIndex)
+	if err != nil {
+		return err
+	}
+	headBlock := rawdb.ReadHeadBlock(db)
+	if headBlock == nil {
+		return errors.New("failed to load head block")
+	}
+	triedb, err := stateTrieDatabase(db, headBlock.Number())
+	if err != nil {
+		return err
+	}
+	snaptree, err := snapshot.New(db, triedb, 256, headBlock.Root(), false, false, false)
+	if err != nil {
+		return err
+	}
+	// note: the snapshot only keeps diff layers for the most recent blocks.
+	if snaptree.Snapshot(manifest.StateRoot) == nil {
+		return fmt.Errorf("state of block %d is not available in the snapshot", manifest.BlockNumber)
+	}
+
+	dir := ctx.String(exportOutputFlag.Name)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	manifest.StateFile = "state.jsonl"
+	out, err := os.Create(filepath.Join(dir, manifest.StateFile))
+	if err != nil {
+		return err
+	}
+	defer out.Close()
+
+	buf := bufio.NewWriter(out)
+	if manifest.Accounts, err = dumpSnapshot(snaptree, db, manifest.StateRoot, &state.DumpConfig{}, buf); err != nil {
+		return err
+	}
+	if err := buf.Flush(); err != nil {
+		return err
+	}
+	if err := out.Close(); err != nil {
+		return err
+	}
+
+	// note: the manifest is written last, so an interrupted export has no manifest.
+	blob, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(filepath.Join(dir, "manifest.json"), blob, 0644); err != nil {
+		return err
+	}
+	log.Info("Exported state at finalized batch", "batch", batchIndex, "hash", manifest.BatchHash, "block", manifest.BlockNumber,
+		"root", manifest.StateRoot, "accounts", manifest.Accounts, "dir", dir)
+	return nil
+}
+
+// finalizedBatchManifest resolves the last block of a finalized batch and checks
+// that its state root matches the finalized state root.
+// migrateTrie copies the state after a block into the other trie.
+func migrateTrie(ctx *cli.Context) error {
+	stack, _ := makeConfigNode(ctx)
+	defer stack.Close()
+
+	chaindb := utils.MakeChainDatabase(ctx, stack, false)
+	defer chaindb.Close()
+
+	if ctx.NArg() > 1 {
+		log.Error("Too many arguments given")
+		return errors.New("too many arguments")
+	}
+	var header *types.Header
+	if ctx.NArg() == 1 {
+		arg := ctx.Args().First()
+		if hashish(arg) {
+			hash := common.HexToHash(arg)
+			if number := rawdb.ReadHeaderNumber(chaindb, hash); number != nil {
+				header = rawdb.ReadHeader(chaindb, hash, *number)
+			}
+		} else {
+			number, err := strconv.ParseUint(arg, 10, 64)
+			if err != nil {
+				return err
+			}
+			header = rawdb.ReadHeader(chaindb, rawdb.ReadCanonicalHash(chaindb, number), number)
+		}
+	} else if headBlock := rawdb.ReadHeadBlock(chaindb); headBlock != nil {
+		header = headBlock.Header()
+	}
+	if header == nil {
+		log.Error("Failed to load block")
+		return errors.New("block not found")
+	}
+	config := rawdb.ReadChainConfig(chaindb, rawdb.ReadCanonicalHash(chaindb, 0))
+	if config == nil {
+		log.Error("Failed to load chain config")
+		return errors.New("no chain config")
+	}
+	var (
+		zktrie = config.Scroll.ZktrieEnabledAt(header.Number)
+		source = state.NewDatabaseWithConfig(chaindb, &trie.Config{Preimages: true, Zktrie: zktrie})
+		target = state.NewDatabaseWithConfig(chaindb, &trie.Config{Preimages: true, Zktrie: !zktrie})
+
+		interrupt = make(chan os.Signal, 1)
+		stop      = make(chan struct{})
+	)
+	signal.Notify(interrupt, syscall.SIGINT, syscall.SIGTERM)
+	defer signal.Stop(interrupt)
+	defer close(interrupt)
+	go func() {
+		if _, ok := <-interrupt; ok {
+			log.Info("Interrupted during state migration, checkpointing the progress")
+		}
+		close(stop)
+	}()
+	log.Info("Migrating state", "number", header.Number, "hash", header.Hash(), "root", header.Root, "zktrie", zktrie)
+	root, err := migrator.NewMigrator(chaindb, source, target).Migrate(header, stop)
+	if err != nil {
+		log.Error("Failed to migrate state", "number", header.Number, "err", err)
+		return err
+	}
+	log.Info("Migrated the state", "number", header.Number, "hash", header.Hash(), "root", header.Root, "migrated", root)
 	return nil
 }
+
+func finalizedBatchManifest(db ethdb.Reader, batchIndex uint64) (*exportManifest, error) {
+	meta := rawdb.ReadFinalizedBatchMeta(db, batchIndex)
+	if meta == nil {
+		return nil, fmt.Errorf("batch %d is not finalized", batchIndex)
+	}
+	chunkBlockRanges := rawdb.ReadBatchChunkRanges(db, batchIndex)
+	if len(chunkBlockRanges) == 0 {
+		return nil, fmt.Errorf("batch %d has no chunk ranges", batchIndex)
+	}
+	number := chunkBlockRanges[len(chunkBlockRanges)-1].EndBlockNumber
+	hash := rawdb.ReadCanonicalHash(db, number)
+	if hash == (common.Hash{}) {
+		return nil, fmt.Errorf("last block %d of batch %d not found", number, batchIndex)
+	}
+	header := rawdb.ReadHeader(db, hash, number)
+	if header == nil {
+		return nil, fmt.Errorf("header of block %d not found", number)
+	}
+	if header.Root != meta.StateRoot {
+		return nil, fmt.Errorf("state root %v of block %d does not match finalized state root %v of batch %d", header.Root.Hex(), number, meta.StateRoot.Hex(), batchIndex)
+	}
+	return &exportManifest{
+		BatchIndex:   batchIndex,
+		BatchHash:    meta.BatchHash,
+		BlockNumber:  number,
+		BlockHash:    hash,
+		StateRoot:    meta.StateRoot,
+		WithdrawRoot: meta.WithdrawRoot,
+	}, nil
+}
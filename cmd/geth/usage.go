@@ -159,6 +159,8 @@ var AppHelpFlagGroups = []flags.FlagGroup{
 			utils.RPCGlobalEVMTimeoutFlag,
 			utils.RPCGlobalTxFeeCapFlag,
 			utils.AllowUnprotectedTxs,
+			utils.RPCMethodAllowFlag,
+			utils.RPCMethodDenyFlag,
 			utils.JSpathFlag,
 			utils.ExecFlag,
 			utils.PreloadJSFlag,
@@ -194,6 +196,9 @@ var AppHelpFlagGroups = []flags.FlagGroup{
 			utils.MinerRecommitIntervalFlag,
 			utils.MinerNoVerifyFlag,
 			utils.MinerStoreSkippedTxTracesFlag,
+			utils.MinerDeterministicL1MessagesFlag,
+			utils.MinerL1MessageGasBudgetFlag,
+			utils.MinerMaxSkippedL1MessagesFlag,
 		},
 	},
 	{
@@ -203,6 +208,8 @@ var AppHelpFlagGroups = []flags.FlagGroup{
 			utils.GpoPercentileFlag,
 			utils.GpoMaxGasPriceFlag,
 			utils.GpoIgnoreGasPriceFlag,
+			utils.GpoL1BaseFeeBlocksFlag,
+			utils.GpoL1BaseFeeWeightFlag,
 		},
 	},
 	{
@@ -232,6 +239,7 @@ var AppHelpFlagGroups = []flags.FlagGroup{
 		Name: "MISC",
 		Flags: []cli.Flag{
 			utils.SnapshotFlag,
+			utils.SnapshotZktrieFlag,
 			utils.BloomFilterSizeFlag,
 			cli.HelpFlag,
 			utils.CatalystFlag,
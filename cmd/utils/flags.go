@@ -48,8 +48,10 @@ import (
 	"github.com/scroll-tech/go-ethereum/consensus/ethash"
 	"github.com/scroll-tech/go-ethereum/core"
 	"github.com/scroll-tech/go-ethereum/core/rawdb"
+	"github.com/scroll-tech/go-ethereum/core/state"
 	"github.com/scroll-tech/go-ethereum/core/vm"
 	"github.com/scroll-tech/go-ethereum/crypto"
+	"github.com/scroll-tech/go-ethereum/crypto/kzg4844"
 	"github.com/scroll-tech/go-ethereum/eth"
 	"github.com/scroll-tech/go-ethereum/eth/downloader"
 	"github.com/scroll-tech/go-ethereum/eth/ethconfig"
@@ -73,7 +75,12 @@ import (
 	"github.com/scroll-tech/go-ethereum/p2p/nat"
 	"github.com/scroll-tech/go-ethereum/p2p/netutil"
 	"github.com/scroll-tech/go-ethereum/params"
+	"github.com/scroll-tech/go-ethereum/rollup/da"
+	"github.com/scroll-tech/go-ethereum/rollup/diaglog"
+	"github.com/scroll-tech/go-ethereum/rollup/rollup_sync_service"
+	"github.com/scroll-tech/go-ethereum/rollup/sync_service"
 	"github.com/scroll-tech/go-ethereum/rollup/tracing"
+	"github.com/scroll-tech/go-ethereum/rollup/withdrawtrie"
 	"github.com/scroll-tech/go-ethereum/rpc"
 )
 
@@ -108,6 +115,10 @@ func printHelp(out io.Writer, templ string, data interface{}) {
 const (
 	GCModeFull    = "full"
 	GCModeArchive = "archive"
+
+	// blockTraceDumpDir is the directory in the data directory of the block traces dumped by
+	// debug_dumpBlockTrace.
+	blockTraceDumpDir = "blocktraces"
 )
 
 // These are all the command line flags we support.
@@ -249,6 +260,10 @@ var (
 		Name:  "snapshot",
 		Usage: `Enables snapshot-database mode (default = enable)`,
 	}
+	SnapshotZktrieFlag = cli.BoolFlag{
+		Name:  "snapshot.zktrie",
+		Usage: "Enables snapshot-database mode for zktrie states (experimental)",
+	}
 	TxLookupLimitFlag = cli.Uint64Flag{
 		Name:  "txlookuplimit",
 		Usage: "Number of recent blocks to maintain transactions index for (default = about one year, 0 = entire chain)",
@@ -500,6 +515,19 @@ var (
 		Name:  "miner.storeskippedtxtraces",
 		Usage: "Store the wrapped traces when storing a skipped tx",
 	}
+	MinerDeterministicL1MessagesFlag = cli.BoolFlag{
+		Name:  "miner.l1messages.deterministic",
+		Usage: "Include pending L1 messages in queue order before any L2 transaction, blocks that cannot include all pending L1 messages contain no L2 transactions",
+	}
+	MinerL1MessageGasBudgetFlag = cli.Uint64Flag{
+		Name:  "miner.l1messages.gasbudget",
+		Usage: "Maximum total gas limit of the L1 messages of a block with deterministic L1 message inclusion (0 = block gas limit)",
+	}
+	MinerMaxSkippedL1MessagesFlag = cli.IntFlag{
+		Name:  "miner.l1messages.maxskips",
+		Usage: "Maximum number of L1 messages skipped per block with deterministic L1 message inclusion, further messages are deferred to the next block (at least 1, -1 = unlimited)",
+		Value: ethconfig.Defaults.Miner.MaxSkippedL1Messages,
+	}
 	// Account settings
 	UnlockedAccountFlag = cli.StringFlag{
 		Name:  "unlock",
@@ -650,6 +678,14 @@ var (
 		Name:  "rpc.allow-unprotected-txs",
 		Usage: "Allow for unprotected (non EIP155 signed) transactions to be submitted via RPC",
 	}
+	RPCMethodAllowFlag = cli.StringFlag{
+		Name:  "rpc.methods.allow",
+		Usage: "Comma separated RPC methods offered over HTTP and WS even if their API is not, wildcards allowed (e.g. admin_rollupSyncStatus,admin_l1Mode)",
+	}
+	RPCMethodDenyFlag = cli.StringFlag{
+		Name:  "rpc.methods.deny",
+		Usage: "Comma separated RPC methods not offered over HTTP and WS even if their API is, wildcards allowed (e.g. admin_rollupSync*)",
+	}
 
 	// Network Settings
 	MaxPeersFlag = cli.IntFlag{
@@ -730,6 +766,16 @@ var (
 		Usage: "Gas price below which gpo will ignore transactions",
 		Value: ethconfig.Defaults.GPO.IgnorePrice.Int64(),
 	}
+	GpoL1BaseFeeBlocksFlag = cli.IntFlag{
+		Name:  "gpo.l1basefeeblocks",
+		Usage: "Number of recent blocks to check for L1 base fees, enables the rollup-aware gas price suggestion (0 = disabled)",
+		Value: ethconfig.Defaults.GPO.L1BaseFeeBlocks,
+	}
+	GpoL1BaseFeeWeightFlag = cli.Float64Flag{
+		Name:  "gpo.l1basefeeweight",
+		Usage: "Expected L1 data gas per L2 gas, converts the recent L1 data gas price into the share of the suggested gas price in the rollup-aware mode",
+		Value: ethconfig.Defaults.GPO.L1BaseFeeWeight,
+	}
 
 	// Metrics flags
 	MetricsEnabledFlag = cli.BoolFlag{
@@ -820,7 +866,7 @@ var (
 	// L1Settings
 	L1EndpointFlag = cli.StringFlag{
 		Name:  "l1.endpoint",
-		Usage: "Endpoint of L1 HTTP-RPC server",
+		Usage: "Endpoint of L1 HTTP-RPC server, comma separated list of endpoints to fail over between",
 	}
 	L1ConfirmationsFlag = cli.StringFlag{
 		Name:  "l1.confirmations",
@@ -830,6 +876,31 @@ var (
 		Name:  "l1.sync.startblock",
 		Usage: "L1 block height to start syncing from. Should be set to the L1 message queue deployment block number.",
 	}
+	L1GetLogsRateLimitFlag = cli.Float64Flag{
+		Name:  "l1.getlogs.rate",
+		Usage: "Maximum number of eth_getLogs queries per second sent to L1 (0 = no limit)",
+	}
+	L1GetLogsDailyBudgetFlag = cli.Uint64Flag{
+		Name:  "l1.getlogs.daily",
+		Usage: "Maximum number of eth_getLogs queries per day sent to L1 (0 = no limit)",
+	}
+	L1VerifiedEndpointFlag = cli.StringFlag{
+		Name:  "l1.verified.endpoint",
+		Usage: "Endpoint of an L1 light client HTTP-RPC server serving verified L1 data",
+	}
+	L1ModeFlag = cli.StringFlag{
+		Name:  "l1.mode",
+		Usage: "Source of L1 data on startup, \"trusted\" (l1.endpoint) or \"verified\" (l1.verified.endpoint), can be switched at runtime with admin_setL1Mode (default = mode last selected with admin_setL1Mode)",
+		Value: string(sync_service.L1ModeTrusted),
+	}
+	L1SlowQueryThresholdFlag = cli.DurationFlag{
+		Name:  "l1.slowquery",
+		Usage: "Log and count the L1 requests that take longer than this duration, per endpoint and method (0 = disabled)",
+	}
+	L1ReceiptsEndpointsFlag = cli.StringFlag{
+		Name:  "l1.receipts.endpoints",
+		Usage: "Comma separated endpoints of --l1.endpoint on which the ScrollChain events are collected from block receipts instead of eth_getLogs",
+	}
 
 	// Circuit capacity check settings
 	CircuitCapacityCheckEnabledFlag = cli.BoolFlag{
@@ -837,11 +908,134 @@ var (
 		Usage: "Enable circuit capacity check during block validation",
 	}
 
+	// Dual-trie settings
+	DualTrieFlag = cli.BoolFlag{
+		Name:  "dualtrie",
+		Usage: "Maintain a shadow MPT state alongside the zktrie state and cross-check it after every block (implies --cache.preimages)",
+	}
+
 	// Rollup verify service settings
 	RollupVerifyEnabledFlag = cli.BoolFlag{
 		Name:  "rollup.verify",
 		Usage: "Enable verification of batch consistency between L1 and L2 in rollup",
 	}
+	RollupFollowFlag = cli.StringFlag{
+		Name:  "rollup.follow",
+		Usage: "RPC endpoint of a node running the rollup verifier with the rollupdb and scroll APIs enabled, whose rollup metadata is served instead of syncing it from L1",
+	}
+	RollupL1FollowerFlag = cli.BoolFlag{
+		Name:  "rollup.l1follower",
+		Usage: "Derive the L2 chain from the batch data committed to L1 instead of syncing blocks from peers (disables p2p networking, implies --rollup.verify)",
+	}
+	RollupProverTaskQueueFlag = cli.StringFlag{
+		Name:  "rollup.prover.taskqueue",
+		Usage: "URL of the queue that prover tasks are pushed to on each batch commit (redis://host:port/key or http(s)://...)",
+	}
+	RollupVerifyReexecFlag = cli.Uint64Flag{
+		Name:  "rollup.verify.reexec",
+		Usage: "Maximum number of blocks to re-execute when the state needed for batch verification is missing (0 = disabled)",
+	}
+	RollupUnknownEventPolicyFlag = cli.StringFlag{
+		Name:  "rollup.sync.unknownevents",
+		Usage: "Policy for unknown ScrollChain events (halt, skip, skip-with-alert)",
+		Value: string(rollup_sync_service.UnknownEventHalt),
+	}
+	RollupIgnoredEventsFlag = cli.StringFlag{
+		Name:  "rollup.sync.ignoredevents",
+		Usage: "Comma separated list of additional ScrollChain event topics to ignore",
+	}
+	RollupSyncConfirmationsFlag = cli.Uint64Flag{
+		Name:  "rollup.sync.confirmations",
+		Usage: "Number of L1 confirmations before rollup events are processed, L1 reorgs are rolled back (0 = finalized L1 blocks only)",
+	}
+	RollupVerifyModeFlag = cli.StringFlag{
+		Name:  "rollup.sync.verify.mode",
+		Usage: "Action on batch validation failure (crash, halt-sync, halt-import, log-and-continue)",
+		Value: string(rollup_sync_service.VerifyModeCrash),
+	}
+	RollupVerifyWorkersFlag = cli.IntFlag{
+		Name:  "rollup.verify.workers",
+		Usage: "Number of goroutines loading local blocks for batch verification",
+		Value: 1,
+	}
+	RollupVerifyRateLimitFlag = cli.Uint64Flag{
+		Name:  "rollup.verify.ratelimit",
+		Usage: "Maximum number of blocks loaded per second for batch verification (0 = unlimited)",
+	}
+	RollupPayloadCacheFlag = cli.Uint64Flag{
+		Name:  "rollup.payloadcache",
+		Usage: "Disk space in MB for the commit transactions fetched from L1, kept in the data directory across restarts (0 = disabled)",
+	}
+	RollupBlobBeaconFlag = cli.StringFlag{
+		Name:  "rollup.blob.beacon",
+		Usage: "Comma separated beacon node API endpoints that the blobs of committed batches are retrieved from",
+	}
+	RollupBlobArchiveFlag = cli.StringFlag{
+		Name:  "rollup.blob.archive",
+		Usage: "Comma separated blob archive API endpoints, queried for the blobs pruned by the beacon nodes",
+	}
+	RollupBlobMirrorFlag = cli.StringFlag{
+		Name:  "rollup.blob.mirror",
+		Usage: "Comma separated blob mirror URLs (file://, s3:// or http(s)://), queried after the archives",
+	}
+	RollupBlobDiskCacheFlag = cli.Uint64Flag{
+		Name:  "rollup.blob.diskcache",
+		Usage: "Disk space in MB for the retrieved blobs, kept in the data directory across restarts (0 = disabled)",
+	}
+	RollupVerifyStrictFlag = cli.BoolFlag{
+		Name:  "rollup.verify.strict",
+		Usage: "Reject finalize events with non-monotonic batch indices or beyond the contract's last finalized batch",
+	}
+	RollupSyncL1TimeoutFlag = cli.DurationFlag{
+		Name:  "rollup.sync.l1timeout",
+		Usage: "Timeout of every L1 request of the rollup verifier",
+		Value: 30 * time.Second,
+	}
+	RollupSyncCrossCheckFlag = cli.StringFlag{
+		Name:  "rollup.sync.crosscheck",
+		Usage: "Secondary L1 endpoint that the rollup events are cross-checked against before they are processed",
+	}
+	RollupSyncMaxL1StalenessFlag = cli.DurationFlag{
+		Name:  "rollup.sync.maxl1staleness",
+		Usage: "Maximum time since the latest finalized L1 block was first observed before finalized L2 blocks are no longer advertised (0 = disabled)",
+	}
+	RollupSyncMetadataSinkFlag = cli.StringFlag{
+		Name:  "rollup.sync.metasink",
+		Usage: "URL of an external store that the metadata of each committed, finalized and reverted batch is written to (redis://host:port/prefix or http(s)://...)",
+	}
+	RollupLogMaxBytesFlag = cli.Uint64Flag{
+		Name:  "rollup.log.maxbytes",
+		Usage: "Maximum bytes of diagnostic payloads, e.g. chunks of mismatching batches, logged per hour by the rollup services (0 = no limit)",
+		Value: diaglog.DefaultConfig.MaxBytesPerHour,
+	}
+	RollupLogCompressFlag = cli.IntFlag{
+		Name:  "rollup.log.compress",
+		Usage: "Size in bytes from which rollup diagnostic payloads are logged gzip compressed and base64 encoded (0 = never)",
+		Value: diaglog.DefaultConfig.CompressThreshold,
+	}
+	RollupLogSampleFlag = cli.Uint64Flag{
+		Name:  "rollup.log.sample",
+		Usage: "Log only every n-th rollup diagnostic payload of a kind",
+		Value: diaglog.DefaultConfig.SampleRate,
+	}
+	RollupWithdrawRootBackfillFlag = cli.BoolFlag{
+		Name:  "rollup.withdrawroot.backfill",
+		Usage: "Backfill the withdraw roots of the blocks imported before they were stored (requires an archive node)",
+	}
+	RollupWithdrawRootBackfillRateLimitFlag = cli.Uint64Flag{
+		Name:  "rollup.withdrawroot.backfill.ratelimit",
+		Usage: "Maximum number of block states read per second by the withdraw root backfill (0 = unlimited)",
+		Value: 100,
+	}
+	KeccakBackendFlag = cli.StringFlag{
+		Name:  "crypto.keccak",
+		Usage: "Expected implementation of Keccak-256 hashing, \"native\" (assembly where available) or the portable \"go\" of binaries built with the purego tag",
+		Value: crypto.KeccakBackendNative,
+	}
+	KZGTrustedSetupFlag = cli.StringFlag{
+		Name:  "kzg.trustedsetup",
+		Usage: "Path of a JSON trusted setup for KZG verification overriding the embedded one",
+	}
 
 	// Max block range for `eth_getLogs` method
 	MaxBlockRangeFlag = cli.Int64Flag{
@@ -1042,6 +1236,12 @@ func setHTTP(ctx *cli.Context, cfg *node.Config) {
 	if ctx.GlobalIsSet(AllowUnprotectedTxs.Name) {
 		cfg.AllowUnprotectedTxs = ctx.GlobalBool(AllowUnprotectedTxs.Name)
 	}
+	if ctx.GlobalIsSet(RPCMethodAllowFlag.Name) {
+		cfg.RPCMethodAllow = SplitAndTrim(ctx.GlobalString(RPCMethodAllowFlag.Name))
+	}
+	if ctx.GlobalIsSet(RPCMethodDenyFlag.Name) {
+		cfg.RPCMethodDeny = SplitAndTrim(ctx.GlobalString(RPCMethodDenyFlag.Name))
+	}
 }
 
 // setGraphQL creates the GraphQL listener interface string from the set
@@ -1270,6 +1470,15 @@ func SetP2PConfig(ctx *cli.Context, cfg *p2p.Config) {
 		cfg.NetRestrict = list
 	}
 
+	if ctx.GlobalBool(RollupL1FollowerFlag.Name) {
+		// the blocks of an L1 follower are derived from L1, they are not synced from peers
+		log.Info("Disabling p2p networking of L1 follower node")
+		cfg.MaxPeers = 0
+		cfg.ListenAddr = ""
+		cfg.NoDial = true
+		cfg.NoDiscovery = true
+		cfg.DiscoveryV5 = false
+	}
 	if ctx.GlobalBool(DeveloperFlag.Name) || ctx.GlobalBool(CatalystFlag.Name) {
 		// --dev mode can't use p2p networking.
 		cfg.MaxPeers = 0
@@ -1350,6 +1559,48 @@ func setL1(ctx *cli.Context, cfg *node.Config) {
 	if ctx.GlobalIsSet(L1DeploymentBlockFlag.Name) {
 		cfg.L1DeploymentBlock = ctx.GlobalUint64(L1DeploymentBlockFlag.Name)
 	}
+	if ctx.GlobalIsSet(L1GetLogsRateLimitFlag.Name) {
+		if cfg.L1GetLogsRateLimit = ctx.GlobalFloat64(L1GetLogsRateLimitFlag.Name); cfg.L1GetLogsRateLimit < 0 {
+			Fatalf("Invalid %s value: %v", L1GetLogsRateLimitFlag.Name, cfg.L1GetLogsRateLimit)
+		}
+	}
+	if ctx.GlobalIsSet(L1GetLogsDailyBudgetFlag.Name) {
+		cfg.L1GetLogsDailyBudget = ctx.GlobalUint64(L1GetLogsDailyBudgetFlag.Name)
+	}
+	if ctx.GlobalIsSet(L1VerifiedEndpointFlag.Name) {
+		cfg.L1VerifiedEndpoint = ctx.GlobalString(L1VerifiedEndpointFlag.Name)
+	}
+	if ctx.GlobalIsSet(L1ModeFlag.Name) {
+		mode, err := sync_service.ParseL1Mode(ctx.GlobalString(L1ModeFlag.Name))
+		if err != nil {
+			Fatalf("Invalid %s: %v", L1ModeFlag.Name, err)
+		}
+		if mode == sync_service.L1ModeVerified && cfg.L1VerifiedEndpoint == "" {
+			Fatalf("Invalid %s: %v mode requires --%s", L1ModeFlag.Name, mode, L1VerifiedEndpointFlag.Name)
+		}
+		cfg.L1Mode = string(mode)
+	}
+	if ctx.GlobalIsSet(L1SlowQueryThresholdFlag.Name) {
+		cfg.L1SlowQueryThreshold = ctx.GlobalDuration(L1SlowQueryThresholdFlag.Name)
+	}
+	if ctx.GlobalIsSet(L1ReceiptsEndpointsFlag.Name) {
+		cfg.L1ReceiptsEndpoints = SplitAndTrim(ctx.GlobalString(L1ReceiptsEndpointsFlag.Name))
+		for _, endpoint := range cfg.L1ReceiptsEndpoints {
+			if !includesString(SplitAndTrim(cfg.L1Endpoint), endpoint) {
+				Fatalf("Invalid %s: %v is not an endpoint of --%s", L1ReceiptsEndpointsFlag.Name, endpoint, L1EndpointFlag.Name)
+			}
+		}
+	}
+}
+
+// includesString reports whether list contains s.
+func includesString(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
 }
 
 func setSmartCard(ctx *cli.Context, cfg *node.Config) {
@@ -1424,6 +1675,12 @@ func setGPO(ctx *cli.Context, cfg *gasprice.Config, light bool) {
 	if ctx.GlobalIsSet(GpoIgnoreGasPriceFlag.Name) {
 		cfg.IgnorePrice = big.NewInt(ctx.GlobalInt64(GpoIgnoreGasPriceFlag.Name))
 	}
+	if ctx.GlobalIsSet(GpoL1BaseFeeBlocksFlag.Name) {
+		cfg.L1BaseFeeBlocks = ctx.GlobalInt(GpoL1BaseFeeBlocksFlag.Name)
+	}
+	if ctx.GlobalIsSet(GpoL1BaseFeeWeightFlag.Name) {
+		cfg.L1BaseFeeWeight = ctx.GlobalFloat64(GpoL1BaseFeeWeightFlag.Name)
+	}
 }
 
 func setTxPool(ctx *cli.Context, cfg *core.TxPoolConfig) {
@@ -1519,6 +1776,17 @@ func setMiner(ctx *cli.Context, cfg *miner.Config) {
 	if ctx.GlobalIsSet(MinerStoreSkippedTxTracesFlag.Name) {
 		cfg.StoreSkippedTxTraces = ctx.GlobalBool(MinerStoreSkippedTxTracesFlag.Name)
 	}
+	if ctx.GlobalIsSet(MinerDeterministicL1MessagesFlag.Name) {
+		cfg.DeterministicL1Messages = ctx.GlobalBool(MinerDeterministicL1MessagesFlag.Name)
+	}
+	if ctx.GlobalIsSet(MinerL1MessageGasBudgetFlag.Name) {
+		cfg.L1MessageGasBudget = ctx.GlobalUint64(MinerL1MessageGasBudgetFlag.Name)
+	}
+	if ctx.GlobalIsSet(MinerMaxSkippedL1MessagesFlag.Name) {
+		if cfg.MaxSkippedL1Messages = ctx.GlobalInt(MinerMaxSkippedL1MessagesFlag.Name); cfg.MaxSkippedL1Messages < -1 || cfg.MaxSkippedL1Messages == 0 {
+			Fatalf("Invalid %s value: %v", MinerMaxSkippedL1MessagesFlag.Name, cfg.MaxSkippedL1Messages)
+		}
+	}
 	if ctx.GlobalIsSet(LegacyMinerGasTargetFlag.Name) {
 		log.Warn("The generic --miner.gastarget flag is deprecated and will be removed in the future!")
 	}
@@ -1557,6 +1825,163 @@ func setEnableRollupVerify(ctx *cli.Context, cfg *ethconfig.Config) {
 	if ctx.GlobalIsSet(RollupVerifyEnabledFlag.Name) {
 		cfg.EnableRollupVerify = ctx.GlobalBool(RollupVerifyEnabledFlag.Name)
 	}
+	if ctx.GlobalIsSet(RollupFollowFlag.Name) {
+		cfg.RollupFollow = ctx.GlobalString(RollupFollowFlag.Name)
+	}
+	if cfg.EnableRollupVerify && cfg.RollupFollow != "" {
+		Fatalf("Options %q and %q are mutually exclusive", RollupVerifyEnabledFlag.Name, RollupFollowFlag.Name)
+	}
+	if ctx.GlobalBool(RollupL1FollowerFlag.Name) {
+		if cfg.RollupFollow != "" {
+			Fatalf("Options %q and %q are mutually exclusive", RollupL1FollowerFlag.Name, RollupFollowFlag.Name)
+		}
+		if ctx.GlobalBool(MiningEnabledFlag.Name) {
+			Fatalf("Options %q and %q are mutually exclusive", RollupL1FollowerFlag.Name, MiningEnabledFlag.Name)
+		}
+		cfg.EnableRollupVerify = true
+		cfg.RollupSync.L1Follower = true
+	}
+	if ctx.GlobalIsSet(RollupProverTaskQueueFlag.Name) {
+		cfg.RollupSync.ProverTaskQueue = ctx.GlobalString(RollupProverTaskQueueFlag.Name)
+	}
+	if ctx.GlobalIsSet(RollupVerifyReexecFlag.Name) {
+		cfg.RollupSync.StateReexec = ctx.GlobalUint64(RollupVerifyReexecFlag.Name)
+	}
+	if ctx.GlobalIsSet(RollupUnknownEventPolicyFlag.Name) {
+		policy, err := rollup_sync_service.ParseUnknownEventPolicy(ctx.GlobalString(RollupUnknownEventPolicyFlag.Name))
+		if err != nil {
+			Fatalf("Invalid %s: %v", RollupUnknownEventPolicyFlag.Name, err)
+		}
+		cfg.RollupSync.UnknownEventPolicy = policy
+	}
+	if ctx.GlobalIsSet(RollupIgnoredEventsFlag.Name) {
+		for _, topic := range SplitAndTrim(ctx.GlobalString(RollupIgnoredEventsFlag.Name)) {
+			var hash common.Hash
+			if err := hash.UnmarshalText([]byte(topic)); err != nil {
+				Fatalf("Invalid event topic %s in %s: %v", topic, RollupIgnoredEventsFlag.Name, err)
+			}
+			cfg.RollupSync.IgnoredEventTopics = append(cfg.RollupSync.IgnoredEventTopics, hash)
+		}
+	}
+	if ctx.GlobalIsSet(RollupVerifyModeFlag.Name) {
+		mode, err := rollup_sync_service.ParseVerifyMode(ctx.GlobalString(RollupVerifyModeFlag.Name))
+		if err != nil {
+			Fatalf("Invalid %s: %v", RollupVerifyModeFlag.Name, err)
+		}
+		cfg.RollupSync.VerifyMode = mode
+	}
+	if ctx.GlobalIsSet(RollupSyncConfirmationsFlag.Name) {
+		cfg.RollupSync.Confirmations = ctx.GlobalUint64(RollupSyncConfirmationsFlag.Name)
+	}
+	if ctx.GlobalIsSet(RollupVerifyWorkersFlag.Name) {
+		cfg.RollupSync.ValidationWorkers = ctx.GlobalInt(RollupVerifyWorkersFlag.Name)
+	}
+	if ctx.GlobalIsSet(RollupVerifyRateLimitFlag.Name) {
+		cfg.RollupSync.ValidationRateLimit = ctx.GlobalUint64(RollupVerifyRateLimitFlag.Name)
+	}
+	if ctx.GlobalIsSet(RollupPayloadCacheFlag.Name) {
+		cfg.RollupSync.PayloadCacheSize = ctx.GlobalUint64(RollupPayloadCacheFlag.Name) * 1024 * 1024
+		if cfg.RollupSync.PayloadCacheDir == "" {
+			cfg.RollupSync.PayloadCacheDir = "rollup-payloads"
+		}
+	}
+	if ctx.GlobalIsSet(RollupBlobBeaconFlag.Name) || ctx.GlobalIsSet(RollupBlobArchiveFlag.Name) || ctx.GlobalIsSet(RollupBlobMirrorFlag.Name) {
+		if cfg.RollupSync.BlobSources == nil {
+			cfg.RollupSync.BlobSources = &da.Config{}
+		}
+		sources := cfg.RollupSync.BlobSources
+		if ctx.GlobalIsSet(RollupBlobBeaconFlag.Name) {
+			sources.BeaconNodeEndpoints = SplitAndTrim(ctx.GlobalString(RollupBlobBeaconFlag.Name))
+		}
+		if ctx.GlobalIsSet(RollupBlobArchiveFlag.Name) {
+			sources.ArchiveEndpoints = SplitAndTrim(ctx.GlobalString(RollupBlobArchiveFlag.Name))
+		}
+		if ctx.GlobalIsSet(RollupBlobMirrorFlag.Name) {
+			sources.MirrorURLs = SplitAndTrim(ctx.GlobalString(RollupBlobMirrorFlag.Name))
+		}
+	}
+	if ctx.GlobalIsSet(RollupBlobDiskCacheFlag.Name) && cfg.RollupSync.BlobSources != nil {
+		cfg.RollupSync.BlobSources.DiskCacheSize = ctx.GlobalUint64(RollupBlobDiskCacheFlag.Name) * 1024 * 1024
+		if cfg.RollupSync.BlobSources.DiskCacheSize == 0 {
+			cfg.RollupSync.BlobSources.DiskCacheDir = ""
+		} else if cfg.RollupSync.BlobSources.DiskCacheDir == "" {
+			cfg.RollupSync.BlobSources.DiskCacheDir = "rollup-blobs"
+		}
+	}
+	if ctx.GlobalIsSet(RollupVerifyStrictFlag.Name) {
+		cfg.RollupSync.StrictFinalizeOrder = ctx.GlobalBool(RollupVerifyStrictFlag.Name)
+	}
+	if ctx.GlobalIsSet(RollupSyncL1TimeoutFlag.Name) {
+		cfg.RollupSync.L1RequestTimeout = ctx.GlobalDuration(RollupSyncL1TimeoutFlag.Name)
+	}
+	if ctx.GlobalIsSet(RollupSyncCrossCheckFlag.Name) {
+		cfg.RollupSync.CrossCheckL1Endpoint = ctx.GlobalString(RollupSyncCrossCheckFlag.Name)
+	}
+	if ctx.GlobalIsSet(RollupSyncMaxL1StalenessFlag.Name) {
+		cfg.RollupSync.MaxL1FinalizedStaleness = ctx.GlobalDuration(RollupSyncMaxL1StalenessFlag.Name)
+	}
+	if ctx.GlobalIsSet(RollupSyncMetadataSinkFlag.Name) {
+		cfg.RollupSync.MetadataSink = ctx.GlobalString(RollupSyncMetadataSinkFlag.Name)
+	}
+	if ctx.GlobalIsSet(L1SlowQueryThresholdFlag.Name) {
+		cfg.RollupSync.L1SlowQueryThreshold = ctx.GlobalDuration(L1SlowQueryThresholdFlag.Name)
+	}
+}
+
+// setKeccakBackend checks that the binary was built with the requested implementation of
+// Keccak-256 hashing, which is fixed at build time.
+func setKeccakBackend(ctx *cli.Context) {
+	if ctx.GlobalIsSet(KeccakBackendFlag.Name) {
+		name := ctx.GlobalString(KeccakBackendFlag.Name)
+		if name != crypto.KeccakBackendNative && name != crypto.KeccakBackendGo {
+			Fatalf("Invalid %s: unknown keccak backend %q", KeccakBackendFlag.Name, name)
+		}
+		if backend := crypto.KeccakBackend(); name != backend {
+			Fatalf("Invalid %s: binary built with the %q keccak backend, the %q backend is selected at build time by the purego tag", KeccakBackendFlag.Name, backend, name)
+		}
+	}
+	log.Info("Using Keccak-256 implementation", "backend", crypto.KeccakBackend())
+}
+
+// setRollupDiagLog configures the logging of diagnostic payloads of the rollup services.
+func setRollupDiagLog(ctx *cli.Context) {
+	config := diaglog.DefaultConfig
+	if ctx.GlobalIsSet(RollupLogMaxBytesFlag.Name) {
+		config.MaxBytesPerHour = ctx.GlobalUint64(RollupLogMaxBytesFlag.Name)
+	}
+	if ctx.GlobalIsSet(RollupLogCompressFlag.Name) {
+		if config.CompressThreshold = ctx.GlobalInt(RollupLogCompressFlag.Name); config.CompressThreshold < 0 {
+			Fatalf("Invalid %s: %d", RollupLogCompressFlag.Name, config.CompressThreshold)
+		}
+	}
+	if ctx.GlobalIsSet(RollupLogSampleFlag.Name) {
+		config.SampleRate = ctx.GlobalUint64(RollupLogSampleFlag.Name)
+	}
+	diaglog.SetConfig(config)
+}
+
+// setKZGTrustedSetup applies the trusted setup override and verifies the setup
+// at startup if it is required for rollup verification.
+func setKZGTrustedSetup(ctx *cli.Context, cfg *ethconfig.Config) {
+	if ctx.GlobalIsSet(KZGTrustedSetupFlag.Name) {
+		path := ctx.GlobalString(KZGTrustedSetupFlag.Name)
+		if err := kzg4844.UseTrustedSetup(path); err != nil {
+			Fatalf("Failed to load %s: %v", KZGTrustedSetupFlag.Name, err)
+		}
+		log.Info("Using custom KZG trusted setup", "path", path)
+	}
+	if cfg.EnableRollupVerify || ctx.GlobalIsSet(KZGTrustedSetupFlag.Name) {
+		if err := kzg4844.VerifyTrustedSetup(); err != nil {
+			Fatalf("Failed to verify KZG trusted setup: %v", err)
+		}
+	}
+}
+
+func setWithdrawRootBackfill(ctx *cli.Context, cfg *ethconfig.Config) {
+	if ctx.GlobalIsSet(RollupWithdrawRootBackfillFlag.Name) {
+		cfg.WithdrawRootBackfill = ctx.GlobalBool(RollupWithdrawRootBackfillFlag.Name)
+	}
+	cfg.WithdrawRootBackfillRateLimit = ctx.GlobalUint64(RollupWithdrawRootBackfillRateLimitFlag.Name)
 }
 
 func setMaxBlockRange(ctx *cli.Context, cfg *ethconfig.Config) {
@@ -1634,6 +2059,10 @@ func SetEthConfig(ctx *cli.Context, stack *node.Node, cfg *ethconfig.Config) {
 	setLes(ctx, cfg)
 	setCircuitCapacityCheck(ctx, cfg)
 	setEnableRollupVerify(ctx, cfg)
+	setKeccakBackend(ctx)
+	setRollupDiagLog(ctx)
+	setKZGTrustedSetup(ctx, cfg)
+	setWithdrawRootBackfill(ctx, cfg)
 	setMaxBlockRange(ctx, cfg)
 
 	// Cap the cache allowance and tune the garbage collector
@@ -1685,6 +2114,11 @@ func SetEthConfig(ctx *cli.Context, stack *node.Node, cfg *ethconfig.Config) {
 		cfg.Preimages = true
 		log.Info("Enabling recording of key preimages since archive mode is used")
 	}
+	cfg.DualTrie = ctx.GlobalBool(DualTrieFlag.Name)
+	if cfg.DualTrie && !cfg.Preimages {
+		cfg.Preimages = true
+		log.Info("Enabling recording of key preimages since dual-trie mode is used")
+	}
 	if ctx.GlobalIsSet(TxLookupLimitFlag.Name) {
 		cfg.TxLookupLimit = ctx.GlobalUint64(TxLookupLimitFlag.Name)
 	}
@@ -1712,6 +2146,7 @@ func SetEthConfig(ctx *cli.Context, stack *node.Node, cfg *ethconfig.Config) {
 			cfg.SnapshotCache = 0 // Disabled
 		}
 	}
+	cfg.SnapshotZktrie = ctx.GlobalBool(SnapshotZktrieFlag.Name)
 	if ctx.GlobalIsSet(DocRootFlag.Name) {
 		cfg.DocRoot = ctx.GlobalString(DocRootFlag.Name)
 	}
@@ -1790,8 +2225,8 @@ func SetEthConfig(ctx *cli.Context, stack *node.Node, cfg *ethconfig.Config) {
 		// forced for sepolia
 		log.Info("Setting flag", "--l1.confirmations", "finalized")
 		stack.Config().L1Confirmations = rpc.FinalizedBlockNumber
-		log.Info("Setting flag", "--l1.sync.startblock", "4038000")
-		stack.Config().L1DeploymentBlock = 4038000
+		log.Info("Setting flag", "--l1.sync.startblock", DefaultL1DeploymentBlock(ctx))
+		stack.Config().L1DeploymentBlock = DefaultL1DeploymentBlock(ctx)
 		// disable pruning
 		if ctx.GlobalString(GCModeFlag.Name) != GCModeArchive {
 			log.Crit("Must use --gcmode=archive")
@@ -1809,8 +2244,8 @@ func SetEthConfig(ctx *cli.Context, stack *node.Node, cfg *ethconfig.Config) {
 		// forced for mainnet
 		log.Info("Setting flag", "--l1.confirmations", "finalized")
 		stack.Config().L1Confirmations = rpc.FinalizedBlockNumber
-		log.Info("Setting flag", "--l1.sync.startblock", "18306000")
-		stack.Config().L1DeploymentBlock = 18306000
+		log.Info("Setting flag", "--l1.sync.startblock", DefaultL1DeploymentBlock(ctx))
+		stack.Config().L1DeploymentBlock = DefaultL1DeploymentBlock(ctx)
 		// disable pruning
 		if ctx.GlobalString(GCModeFlag.Name) != GCModeArchive {
 			log.Crit("Must use --gcmode=archive")
@@ -1906,23 +2341,67 @@ func RegisterEthService(stack *node.Node, cfg *ethconfig.Config) (ethapi.Backend
 			Fatalf("Failed to register the Ethereum service: %v", err)
 		}
 		scrollTracerWrapper := tracing.NewTracerWrapper()
-		stack.RegisterAPIs(tracers.APIs(backend.ApiBackend, scrollTracerWrapper))
+		stack.RegisterAPIs(tracers.APIs(backend.ApiBackend, scrollTracerWrapper, stack.ResolvePath(blockTraceDumpDir)))
 		return backend.ApiBackend, nil
 	}
 
 	// initialize L1 client for sync service
 	// note: we need to do this here to avoid circular dependency
-	l1EndpointUrl := stack.Config().L1Endpoint
-	var l1Client *ethclient.Client
+	var l1Client sync_service.EthClient
 
-	if l1EndpointUrl != "" {
-		var err error
-		l1Client, err = ethclient.Dial(l1EndpointUrl)
-		if err != nil {
-			Fatalf("Unable to connect to L1 endpoint at %v: %v", l1EndpointUrl, err)
+	if l1EndpointUrls := SplitAndTrim(stack.Config().L1Endpoint); len(l1EndpointUrls) > 0 {
+		var clients []sync_service.EthClient
+		for _, l1EndpointUrl := range l1EndpointUrls {
+			client, err := ethclient.Dial(l1EndpointUrl)
+			if err != nil {
+				Fatalf("Unable to connect to L1 endpoint at %v: %v", l1EndpointUrl, err)
+			}
+			var endpointClient sync_service.EthClient = client
+			if includesString(stack.Config().L1ReceiptsEndpoints, l1EndpointUrl) {
+				endpointClient = withReceiptsLogs(client, cfg)
+				log.Info("Collecting ScrollChain events from L1 receipts", "endpoint", l1EndpointUrl)
+			}
+			clients = append(clients, withSlowQueryLog(endpointClient, l1EndpointUrl, stack.Config().L1SlowQueryThreshold))
+		}
+		if len(clients) == 1 {
+			l1Client = clients[0]
+		} else {
+			var err error
+			if l1Client, err = sync_service.NewFailoverClient(clients, l1EndpointUrls); err != nil {
+				Fatalf("Unable to create L1 client: %v", err)
+			}
+		}
+
+		if rate, budget := stack.Config().L1GetLogsRateLimit, stack.Config().L1GetLogsDailyBudget; rate > 0 || budget > 0 {
+			// note: the client is shared by the L1 message and rollup sync services, so are the limits
+			l1Client = sync_service.NewRateLimitedClient(l1Client, rate, budget)
+			log.Info("Limiting L1 eth_getLogs queries", "rate", rate, "daily budget", budget)
+		}
+
+		if verifiedUrl := stack.Config().L1VerifiedEndpoint; verifiedUrl != "" {
+			verified, err := ethclient.Dial(verifiedUrl)
+			if err != nil {
+				Fatalf("Unable to connect to verified L1 endpoint at %v: %v", verifiedUrl, err)
+			}
+			mode := sync_service.L1ModeTrusted
+			if stack.Config().L1Mode != "" {
+				mode = sync_service.L1Mode(stack.Config().L1Mode)
+			}
+			if l1Client, err = sync_service.NewModeSwitchClient(l1Client, withSlowQueryLog(verified, verifiedUrl, stack.Config().L1SlowQueryThreshold), mode); err != nil {
+				Fatalf("Unable to create L1 client: %v", err)
+			}
+			log.Info("Initialized verified L1 client", "endpoint", verifiedUrl, "mode", mode)
 		}
 
-		log.Info("Initialized L1 client", "endpoint", l1EndpointUrl)
+		log.Info("Initialized L1 client", "endpoints", l1EndpointUrls)
+	}
+
+	if crossCheckUrl := cfg.RollupSync.CrossCheckL1Endpoint; crossCheckUrl != "" {
+		client, err := ethclient.Dial(crossCheckUrl)
+		if err != nil {
+			Fatalf("Unable to connect to cross-check L1 endpoint at %v: %v", crossCheckUrl, err)
+		}
+		cfg.RollupSync.CrossCheckL1Client = withSlowQueryLog(client, crossCheckUrl, cfg.RollupSync.L1SlowQueryThreshold)
 	}
 
 	backend, err := eth.New(stack, cfg, l1Client)
@@ -1936,10 +2415,29 @@ func RegisterEthService(stack *node.Node, cfg *ethconfig.Config) (ethapi.Backend
 		}
 	}
 	scrollTracerWrapper := tracing.NewTracerWrapper()
-	stack.RegisterAPIs(tracers.APIs(backend.APIBackend, scrollTracerWrapper))
+	stack.RegisterAPIs(tracers.APIs(backend.APIBackend, scrollTracerWrapper, stack.ResolvePath(blockTraceDumpDir)))
 	return backend.APIBackend, backend
 }
 
+// withReceiptsLogs wraps the client of an L1 endpoint to collect the events of the
+// ScrollChain contract of the configured network from block receipts.
+func withReceiptsLogs(client *ethclient.Client, cfg *ethconfig.Config) sync_service.EthClient {
+	if cfg.Genesis == nil || cfg.Genesis.Config == nil || cfg.Genesis.Config.Scroll.L1Config == nil {
+		Fatalf("Unable to collect ScrollChain events from receipts, the ScrollChain address of the network is unknown")
+	}
+	scrollChain := cfg.Genesis.Config.Scroll.L1Config.ScrollChainAddress
+	return sync_service.NewReceiptsLogClient(client, []common.Address{scrollChain})
+}
+
+// withSlowQueryLog wraps the client of an L1 endpoint to report its slow requests,
+// unless the threshold is zero.
+func withSlowQueryLog(client sync_service.EthClient, endpoint string, threshold time.Duration) sync_service.EthClient {
+	if threshold == 0 {
+		return client
+	}
+	return sync_service.NewSlowQueryClient(client, endpoint, threshold)
+}
+
 // RegisterEthStatsService configures the Ethereum Stats daemon and adds it to
 // the given node.
 func RegisterEthStatsService(stack *node.Node, backend ethapi.Backend, url string) {
@@ -2078,6 +2576,18 @@ func MakeGenesis(ctx *cli.Context) *core.Genesis {
 	return genesis
 }
 
+// DefaultL1DeploymentBlock returns the L1 block the ScrollChain contract of the selected
+// Scroll network was deployed at, or zero for other networks.
+func DefaultL1DeploymentBlock(ctx *cli.Context) uint64 {
+	switch {
+	case ctx.GlobalBool(ScrollSepoliaFlag.Name):
+		return 4038000
+	case ctx.GlobalBool(ScrollFlag.Name):
+		return 18306000
+	}
+	return 0
+}
+
 // MakeChain creates a chain manager from set command line flags.
 func MakeChain(ctx *cli.Context, stack *node.Node) (chain *core.BlockChain, chainDb ethdb.Database) {
 	var err error
@@ -2114,6 +2624,7 @@ func MakeChain(ctx *cli.Context, stack *node.Node) (chain *core.BlockChain, chai
 		TrieDirtyDisabled:   ctx.GlobalString(GCModeFlag.Name) == GCModeArchive,
 		TrieTimeLimit:       ethconfig.Defaults.TrieTimeout,
 		SnapshotLimit:       ethconfig.Defaults.SnapshotCache,
+		ZktrieSnapshot:      ctx.GlobalBool(SnapshotZktrieFlag.Name),
 		Preimages:           ctx.GlobalBool(CachePreimagesFlag.Name),
 	}
 	if cache.TrieDirtyDisabled && !cache.Preimages {
@@ -2137,6 +2648,8 @@ func MakeChain(ctx *cli.Context, stack *node.Node) (chain *core.BlockChain, chai
 	if err != nil {
 		Fatalf("Can't create BlockChain: %v", err)
 	}
+	chain.SetWithdrawRootFn(func(statedb *state.StateDB) common.Hash { return withdrawtrie.WithdrawRoot(statedb) })
+	chain.SetWithdrawMessageIndexer(withdrawtrie.MessageIndexer{})
 	return chain, chainDb
 }
 
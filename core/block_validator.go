@@ -273,7 +273,7 @@ func (v *BlockValidator) createTraceEnvAndGetBlockTrace(block *types.Block) (*ty
 		return nil, errors.New("validateCircuitRowConsumption: no parent block found")
 	}
 
-	statedb, err := v.bc.StateAt(parent.Root())
+	statedb, err := v.bc.StateForChild(parent.Header())
 	if err != nil {
 		return nil, err
 	}
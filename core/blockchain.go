@@ -50,9 +50,11 @@ import (
 )
 
 var (
-	headBlockGauge     = metrics.NewRegisteredGauge("chain/head/block", nil)
-	headHeaderGauge    = metrics.NewRegisteredGauge("chain/head/header", nil)
-	headFastBlockGauge = metrics.NewRegisteredGauge("chain/head/receipt", nil)
+	headBlockGauge          = metrics.NewRegisteredGauge("chain/head/block", nil)
+	headHeaderGauge         = metrics.NewRegisteredGauge("chain/head/header", nil)
+	headFastBlockGauge      = metrics.NewRegisteredGauge("chain/head/receipt", nil)
+	headFinalizedBlockGauge = metrics.NewRegisteredGauge("chain/head/finalized", nil)
+	headSafeBlockGauge      = metrics.NewRegisteredGauge("chain/head/safe", nil)
 
 	accountReadTimer   = metrics.NewRegisteredTimer("chain/account/reads", nil)
 	accountHashTimer   = metrics.NewRegisteredTimer("chain/account/hashes", nil)
@@ -131,8 +133,10 @@ type CacheConfig struct {
 	TrieDirtyDisabled   bool          // Whether to disable trie write caching and GC altogether (archive node)
 	TrieTimeLimit       time.Duration // Time limit after which to flush the current in-memory trie to disk
 	SnapshotLimit       int           // Memory allowance (MB) to use for caching snapshot entries in memory
+	ZktrieSnapshot      bool          // Whether to maintain the state snapshot of zktrie states
 	Preimages           bool          // Whether to store preimage of trie key to the disk
 	MPTWitness          int           // How to generate witness data for mpt circuit, 0: nothing, 1: natural
+	DualTrie            bool          // Whether to maintain a shadow MPT state alongside the zktrie state and cross-check it after every block
 
 	SnapshotWait bool // Wait for snapshot construction on startup. TODO(karalabe): This is a dirty hack for testing, nuke it
 }
@@ -194,27 +198,44 @@ type BlockChain struct {
 
 	currentBlock     atomic.Value // Current head of the block chain
 	currentFastBlock atomic.Value // Current head of the fast-sync chain (may be above the block chain!)
+	currentFinalized atomic.Value // Current finalized header, the end of the last batch finalized on L1
+	currentSafe      atomic.Value // Current safe header, the end of the last batch committed to L1
 
-	stateCache    state.Database // State database to reuse between imports (contains state cache)
-	bodyCache     *lru.Cache     // Cache for the most recent block bodies
-	bodyRLPCache  *lru.Cache     // Cache for the most recent block bodies in RLP encoded format
-	receiptsCache *lru.Cache     // Cache for the most recent receipts per block
-	blockCache    *lru.Cache     // Cache for the most recent entire blocks
-	txLookupCache *lru.Cache     // Cache for the most recent transaction lookup data.
-	futureBlocks  *lru.Cache     // future blocks are blocks added for later processing
+	stateCache    *switchableDatabase // State database to reuse between imports (contains state cache)
+	bodyCache     *lru.Cache          // Cache for the most recent block bodies
+	bodyRLPCache  *lru.Cache          // Cache for the most recent block bodies in RLP encoded format
+	receiptsCache *lru.Cache          // Cache for the most recent receipts per block
+	blockCache    *lru.Cache          // Cache for the most recent entire blocks
+	txLookupCache *lru.Cache          // Cache for the most recent transaction lookup data.
+	futureBlocks  *lru.Cache          // future blocks are blocks added for later processing
 
 	wg            sync.WaitGroup //
 	quit          chan struct{}  // shutdown signal, closed in Stop.
 	running       int32          // 0 if chain is running, 1 when stopped
 	procInterrupt int32          // interrupt signaler for block processing
+	importPaused  int32          // 1 if the import of new blocks is paused, see PauseImport
 
 	engine     consensus.Engine
 	validator  Validator // Block and state validator interface
 	prefetcher Prefetcher
 	processor  Processor // Block transaction processor interface
 	vmConfig   vm.Config
+	shadow     *shadowState    // Shadow MPT state of the dual-trie mode, nil if disabled
+	migration  *stateMigration // State migration ahead of the state scheme switch, nil if not needed
 
-	shouldPreserve func(*types.Block) bool // Function used to determine whether should preserve the given block.
+	shouldPreserve func(*types.Block) bool          // Function used to determine whether should preserve the given block.
+	withdrawRootFn func(*state.StateDB) common.Hash // Function reading the withdraw trie root from the state after a block, nil if not persisted
+	messageIndexer WithdrawMessageIndexer           // Index of the withdraw trie messages of the canonical blocks, nil if not indexed
+}
+
+// WithdrawMessageIndexer maintains the index of the messages appended to the withdraw trie by
+// the canonical blocks. It is provided by the rollup packages, as core does not depend on them.
+type WithdrawMessageIndexer interface {
+	// IndexBlockMessages stores the messages appended by a canonical block with the given receipts.
+	IndexBlockMessages(db ethdb.KeyValueWriter, blockHash common.Hash, blockNumber uint64, receipts types.Receipts)
+
+	// UnindexBlockMessages removes the messages appended by the given logs of a reorged block.
+	UnindexBlockMessages(reader ethdb.Reader, db ethdb.KeyValueWriter, logs []*types.Log)
 }
 
 // NewBlockChain returns a fully initialised block chain using information
@@ -230,9 +251,17 @@ func NewBlockChain(db ethdb.Database, cacheConfig *CacheConfig, chainConfig *par
 	blockCache, _ := lru.New(blockCacheLimit)
 	txLookupCache, _ := lru.New(txLookupCacheLimit)
 	futureBlocks, _ := lru.New(maxFutureBlocks)
+	// the trie of the state is selected by the number of the head block, the state scheme
+	// might have been switched since the genesis block
+	headNumber := common.Big0
+	if number := rawdb.ReadHeaderNumber(db, rawdb.ReadHeadBlockHash(db)); number != nil {
+		headNumber = new(big.Int).SetUint64(*number)
+	}
+	zktrie := chainConfig.Scroll.ZktrieEnabledAt(headNumber)
+
 	// override snapshot setting
-	if chainConfig.Scroll.ZktrieEnabled() && cacheConfig.SnapshotLimit > 0 {
-		log.Warn("Snapshot has been disabled by zktrie")
+	if zktrie && cacheConfig.SnapshotLimit > 0 && !cacheConfig.ZktrieSnapshot {
+		log.Warn("Snapshot has been disabled by zktrie, use --snapshot.zktrie to enable it")
 		cacheConfig.SnapshotLimit = 0
 	}
 
@@ -245,12 +274,12 @@ func NewBlockChain(db ethdb.Database, cacheConfig *CacheConfig, chainConfig *par
 		cacheConfig: cacheConfig,
 		db:          db,
 		triegc:      prque.New(nil),
-		stateCache: state.NewDatabaseWithConfig(db, &trie.Config{
+		stateCache: newSwitchableDatabase(state.NewDatabaseWithConfig(db, &trie.Config{
 			Cache:     cacheConfig.TrieCleanLimit,
 			Journal:   cacheConfig.TrieCleanJournal,
 			Preimages: cacheConfig.Preimages,
-			Zktrie:    chainConfig.Scroll.ZktrieEnabled(),
-		}),
+			Zktrie:    zktrie,
+		}), nil),
 		quit:           make(chan struct{}),
 		chainmu:        syncx.NewClosableMutex(),
 		shouldPreserve: shouldPreserve,
@@ -263,6 +292,14 @@ func NewBlockChain(db ethdb.Database, cacheConfig *CacheConfig, chainConfig *par
 		engine:         engine,
 		vmConfig:       vmConfig,
 	}
+	if zktrie != chainConfig.Scroll.ZktrieEnabled() {
+		// keep serving the states before the state scheme switch
+		bc.stateCache = newSwitchableDatabase(bc.stateCache.load().current, state.NewDatabaseWithConfig(db, &trie.Config{
+			Cache:     16,
+			Preimages: cacheConfig.Preimages,
+			Zktrie:    !zktrie,
+		}))
+	}
 	bc.validator = NewBlockValidator(chainConfig, bc, engine)
 	bc.prefetcher = newStatePrefetcher(chainConfig, bc, engine)
 	bc.processor = NewStateProcessor(chainConfig, bc, engine)
@@ -283,6 +320,9 @@ func NewBlockChain(db ethdb.Database, cacheConfig *CacheConfig, chainConfig *par
 	var nilBlock *types.Block
 	bc.currentBlock.Store(nilBlock)
 	bc.currentFastBlock.Store(nilBlock)
+	var nilHeader *types.Header
+	bc.currentFinalized.Store(nilHeader)
+	bc.currentSafe.Store(nilHeader)
 
 	// Initialize the chain with ancient data if it isn't empty.
 	var txIndexBlock uint64
@@ -397,6 +437,19 @@ func NewBlockChain(db ethdb.Database, cacheConfig *CacheConfig, chainConfig *par
 		bc.snaps, _ = snapshot.New(bc.db, bc.stateCache.TrieDB(), bc.cacheConfig.SnapshotLimit, head.Root(), !bc.cacheConfig.SnapshotWait, true, recover)
 	}
 
+	// Open the shadow state of the dual-trie mode, bootstrapping it in the background if needed
+	if bc.cacheConfig.DualTrie {
+		if bc.shadow, err = newShadowState(bc); err != nil {
+			return nil, err
+		}
+	}
+	// Migrate the state into the other trie ahead of the state scheme switch
+	if switchBlock := chainConfig.Scroll.TrieSwitchBlock; switchBlock != nil && bc.migration == nil && bc.CurrentBlock().Number().Cmp(switchBlock) < 0 {
+		if bc.migration, err = newStateMigration(bc); err != nil {
+			return nil, err
+		}
+	}
+
 	// Start future block processor.
 	bc.wg.Add(1)
 	go bc.futureBlocksLoop()
@@ -479,6 +532,15 @@ func (bc *BlockChain) loadLastState() error {
 			headFastBlockGauge.Update(int64(block.NumberU64()))
 		}
 	}
+	// Restore the finalized header from the rollup verifier, the safe header is
+	// refined by the verifier once it is running.
+	var finalized *types.Header
+	if number := rawdb.ReadFinalizedL2BlockNumber(bc.db); number != nil && *number <= currentBlock.NumberU64() {
+		finalized = bc.GetHeaderByNumber(*number)
+	}
+	bc.currentFinalized.Store(finalized)
+	bc.currentSafe.Store(finalized)
+
 	// Issue a status log for the user
 	currentFastBlock := bc.CurrentFastBlock()
 
@@ -545,7 +607,7 @@ func (bc *BlockChain) setHeadBeyondRoot(head uint64, root common.Hash, repair bo
 					if root != (common.Hash{}) && !beyondRoot && newHeadBlock.Root() == root {
 						beyondRoot, rootNumber = true, newHeadBlock.NumberU64()
 					}
-					if _, err := state.New(newHeadBlock.Root(), bc.stateCache, bc.snaps); err != nil {
+					if _, err := bc.StateAt(newHeadBlock.Root()); err != nil {
 						log.Trace("Block state missing, rewinding further", "number", newHeadBlock.NumberU64(), "hash", newHeadBlock.Hash())
 						if pivot == nil || newHeadBlock.NumberU64() > *pivot {
 							parent := bc.GetBlock(newHeadBlock.ParentHash(), newHeadBlock.NumberU64()-1)
@@ -623,6 +685,7 @@ func (bc *BlockChain) setHeadBeyondRoot(head uint64, root common.Hash, repair bo
 			rawdb.DeleteBody(db, hash, num)
 			rawdb.DeleteReceipts(db, hash, num)
 		}
+		rawdb.DeleteBlockWithdrawRoot(db, hash)
 		// Todo(rjl493456442) txlookup, bloombits, etc
 	}
 	// If SetHead was only called as a chain reparation method, try to skip
@@ -645,6 +708,9 @@ func (bc *BlockChain) setHeadBeyondRoot(head uint64, root common.Hash, repair bo
 	bc.txLookupCache.Purge()
 	bc.futureBlocks.Purge()
 
+	if err := bc.revertStateScheme(); err != nil {
+		return rootNumber, err
+	}
 	return rootNumber, bc.loadLastState()
 }
 
@@ -763,6 +829,10 @@ func (bc *BlockChain) writeHeadBlock(block *types.Block) {
 	batch := bc.db.NewBatch()
 	rawdb.WriteCanonicalHash(batch, block.Hash(), block.NumberU64())
 	rawdb.WriteTxLookupEntriesByBlock(batch, block)
+	rawdb.WriteL1MessageInclusionsByBlock(batch, block)
+	if bc.messageIndexer != nil {
+		bc.messageIndexer.IndexBlockMessages(batch, block.Hash(), block.NumberU64(), rawdb.ReadRawReceipts(bc.db, block.Hash(), block.NumberU64()))
+	}
 	rawdb.WriteHeadBlockHash(batch, block.Hash())
 
 	// If the block is better than our head or is on a different chain, force update heads
@@ -807,6 +877,13 @@ func (bc *BlockChain) Stop() {
 	bc.chainmu.Close()
 	bc.wg.Wait()
 
+	if bc.migration != nil {
+		bc.migration.stop()
+	}
+	if bc.shadow != nil {
+		bc.shadow.stop()
+	}
+
 	// Ensure that the entirety of the state snapshot is journalled to disk.
 	var snapBase common.Hash
 	if bc.snaps != nil {
@@ -868,6 +945,64 @@ func (bc *BlockChain) insertStopped() bool {
 	return atomic.LoadInt32(&bc.procInterrupt) == 1
 }
 
+// PauseImport rejects the import of new blocks with ErrImportPaused until
+// ResumeImport is called, while the existing chain can still be read. It is
+// used to stop a node whose chain diverged from L1 from building on top of it.
+func (bc *BlockChain) PauseImport() {
+	if atomic.CompareAndSwapInt32(&bc.importPaused, 0, 1) {
+		log.Warn("Paused block import")
+	}
+}
+
+// ResumeImport resumes the import of new blocks after PauseImport.
+func (bc *BlockChain) ResumeImport() {
+	if atomic.CompareAndSwapInt32(&bc.importPaused, 1, 0) {
+		log.Info("Resumed block import")
+	}
+}
+
+// ImportPaused returns true if the import of new blocks is paused.
+func (bc *BlockChain) ImportPaused() bool {
+	return atomic.LoadInt32(&bc.importPaused) == 1
+}
+
+// SetFinalized sets the header returned for the "finalized" block tag, nil if
+// no block is finalized.
+func (bc *BlockChain) SetFinalized(header *types.Header) {
+	bc.currentFinalized.Store(header)
+	if header != nil {
+		headFinalizedBlockGauge.Update(header.Number.Int64())
+	}
+}
+
+// SetSafe sets the header returned for the "safe" block tag, nil if no block is safe.
+func (bc *BlockChain) SetSafe(header *types.Header) {
+	bc.currentSafe.Store(header)
+	if header != nil {
+		headSafeBlockGauge.Update(header.Number.Int64())
+	}
+}
+
+// SetWithdrawRootFn sets the function reading the withdraw trie root from the state after a
+// block, so that the root is persisted along with the block for the rollup verifier. It is
+// provided by the rollup packages and must be set before blocks are imported.
+func (bc *BlockChain) SetWithdrawRootFn(fn func(*state.StateDB) common.Hash) {
+	bc.withdrawRootFn = fn
+}
+
+// SetWithdrawMessageIndexer sets the index of the withdraw trie messages that is updated as
+// blocks become or stop being canonical. It must be set before blocks are imported.
+func (bc *BlockChain) SetWithdrawMessageIndexer(indexer WithdrawMessageIndexer) {
+	bc.messageIndexer = indexer
+}
+
+// indexBlockMessages stores the withdraw trie messages of a canonical block, if indexed.
+func (bc *BlockChain) indexBlockMessages(db ethdb.KeyValueWriter, block *types.Block, receipts types.Receipts) {
+	if bc.messageIndexer != nil {
+		bc.messageIndexer.IndexBlockMessages(db, block.Hash(), block.NumberU64(), receipts)
+	}
+}
+
 func (bc *BlockChain) procFutureBlocks() {
 	blocks := make([]*types.Block, 0, bc.futureBlocks.Len())
 	for _, hash := range bc.futureBlocks.Keys() {
@@ -1007,7 +1142,9 @@ func (bc *BlockChain) InsertReceiptChain(blockChain types.Blocks, receiptChain [
 		// range. In this case, all tx indices of newly imported blocks should be
 		// generated.
 		var batch = bc.db.NewBatch()
-		for _, block := range blockChain {
+		for i, block := range blockChain {
+			rawdb.WriteL1MessageInclusionsByBlock(batch, block)
+			bc.indexBlockMessages(batch, block, receiptChain[i])
 			if bc.txLookupLimit == 0 || ancientLimit <= bc.txLookupLimit || block.NumberU64() >= ancientLimit-bc.txLookupLimit {
 				rawdb.WriteTxLookupEntriesByBlock(batch, block)
 			} else if rawdb.ReadTxIndexTail(bc.db) != nil {
@@ -1096,6 +1233,8 @@ func (bc *BlockChain) InsertReceiptChain(blockChain types.Blocks, receiptChain [
 			rawdb.WriteBody(batch, block.Hash(), block.NumberU64(), block.Body())
 			rawdb.WriteReceipts(batch, block.Hash(), block.NumberU64(), receiptChain[i])
 			rawdb.WriteTxLookupEntriesByBlock(batch, block) // Always write tx indices for live blocks, we assume they are needed
+			rawdb.WriteL1MessageInclusionsByBlock(batch, block)
+			bc.indexBlockMessages(batch, block, receiptChain[i])
 
 			// Write everything belongs to the blocks into the database. So that
 			// we can ensure all components of body is completed(body, receipts,
@@ -1245,6 +1384,9 @@ func (bc *BlockChain) writeBlockWithState(block *types.Block, receipts []*types.
 	if bc.insertStopped() {
 		return NonStatTy, errInsertionInterrupted
 	}
+	if bc.ImportPaused() {
+		return NonStatTy, ErrImportPaused
+	}
 
 	// Calculate the total difficulty of the block
 	ptd := bc.GetTd(block.ParentHash(), block.NumberU64()-1)
@@ -1265,6 +1407,10 @@ func (bc *BlockChain) writeBlockWithState(block *types.Block, receipts []*types.
 	rawdb.WriteBlock(blockBatch, block)
 	rawdb.WriteReceipts(blockBatch, block.Hash(), block.NumberU64(), receipts)
 	rawdb.WritePreimages(blockBatch, state.Preimages())
+	// persist the withdraw trie root so that the rollup verifier does not need the block state
+	if bc.withdrawRootFn != nil {
+		rawdb.WriteBlockWithdrawRoot(blockBatch, block.Hash(), bc.withdrawRootFn(state))
+	}
 
 	queueIndex := rawdb.ReadFirstQueueIndexNotInL2Block(bc.db, block.ParentHash())
 	if queueIndex == nil {
@@ -1304,6 +1450,18 @@ func (bc *BlockChain) writeBlockWithState(block *types.Block, receipts []*types.
 	if err != nil {
 		return NonStatTy, err
 	}
+	if bc.shadow != nil {
+		bc.shadow.process(block, state)
+	}
+	if bc.migration != nil {
+		if bc.chainConfig.Scroll.IsTrieSwitchBlock(block.Number()) {
+			if err := bc.switchStateScheme(block); err != nil {
+				return NonStatTy, err
+			}
+		} else if bc.stateCache.legacy() == nil {
+			bc.migration.process(block)
+		}
+	}
 	triedb := bc.stateCache.TrieDB()
 
 	// If we're running an archive node, always flush
@@ -1489,6 +1647,9 @@ func (bc *BlockChain) insertChain(chain types.Blocks, verifySeals bool) (int, er
 	if bc.insertStopped() {
 		return 0, nil
 	}
+	if bc.ImportPaused() {
+		return 0, ErrImportPaused
+	}
 
 	// Start a parallel signature recovery (signer will fluke on fork transition, minimal perf loss)
 	senderCacher.recoverFromBlocks(types.MakeSigner(bc.chainConfig, chain[0].Number()), chain)
@@ -1658,7 +1819,7 @@ func (bc *BlockChain) insertChain(chain types.Blocks, verifySeals bool) (int, er
 		if parent == nil {
 			parent = bc.GetHeader(block.ParentHash(), block.NumberU64()-1)
 		}
-		statedb, err := state.New(parent.Root, bc.stateCache, bc.snaps)
+		statedb, err := bc.StateForChild(parent)
 		if err != nil {
 			return it.index, err
 		}
@@ -2049,6 +2210,15 @@ func (bc *BlockChain) reorg(oldBlock, newBlock *types.Block) error {
 	indexesBatch := bc.db.NewBatch()
 	for _, tx := range types.TxDifference(deletedTxs, addedTxs) {
 		rawdb.DeleteTxLookupEntry(indexesBatch, tx.Hash())
+		if tx.IsL1MessageTx() {
+			// note: messages included in the new head block are indexed again when it is written
+			rawdb.DeleteL1MessageInclusion(indexesBatch, tx.AsL1MessageTx().QueueIndex)
+		}
+	}
+	if bc.messageIndexer != nil {
+		for _, logs := range deletedLogs {
+			bc.messageIndexer.UnindexBlockMessages(bc.db, indexesBatch, logs)
+		}
 	}
 	// Delete any canonical number assignments above the new head
 	number := bc.CurrentBlock().NumberU64()
@@ -49,6 +49,20 @@ func (bc *BlockChain) CurrentFastBlock() *types.Block {
 	return bc.currentFastBlock.Load().(*types.Block)
 }
 
+// CurrentFinalizedBlock retrieves the header of the current finalized block,
+// the last block of the last batch finalized on L1. It is nil if no batch is
+// finalized or the rollup verifier is not enabled.
+func (bc *BlockChain) CurrentFinalizedBlock() *types.Header {
+	return bc.currentFinalized.Load().(*types.Header)
+}
+
+// CurrentSafeBlock retrieves the header of the current safe block, the last
+// local block of the last batch committed to L1. It is nil if no batch is
+// committed or the rollup verifier is not enabled.
+func (bc *BlockChain) CurrentSafeBlock() *types.Header {
+	return bc.currentSafe.Load().(*types.Header)
+}
+
 // HasHeader checks if a block header is present in the database or not, caching
 // it if present.
 func (bc *BlockChain) HasHeader(hash common.Hash, number uint64) bool {
@@ -253,8 +267,14 @@ func (bc *BlockChain) GetTd(hash common.Hash, number uint64) *big.Int {
 
 // HasState checks if state trie is fully present in the database or not.
 func (bc *BlockChain) HasState(hash common.Hash) bool {
-	_, err := bc.stateCache.OpenTrie(hash)
-	return err == nil
+	if _, err := bc.stateCache.OpenTrie(hash); err == nil {
+		return true
+	}
+	if legacy := bc.stateCache.legacy(); legacy != nil {
+		_, err := legacy.OpenTrie(hash)
+		return err == nil
+	}
+	return false
 }
 
 // HasBlockAndState checks if a block and associated state trie is fully present
@@ -286,10 +306,7 @@ func (bc *BlockChain) ContractCode(hash common.Hash) ([]byte, error) {
 // If the code doesn't exist in the in-memory cache, check the storage with
 // new code scheme.
 func (bc *BlockChain) ContractCodeWithPrefix(hash common.Hash) ([]byte, error) {
-	type codeReader interface {
-		ContractCodeWithPrefix(addrHash, codeHash common.Hash) ([]byte, error)
-	}
-	return bc.stateCache.(codeReader).ContractCodeWithPrefix(common.Hash{}, hash)
+	return bc.stateCache.ContractCodeWithPrefix(common.Hash{}, hash)
 }
 
 // State returns a new mutable state based on the current HEAD block.
@@ -299,7 +316,16 @@ func (bc *BlockChain) State() (*state.StateDB, error) {
 
 // StateAt returns a new mutable state based on a particular point in time.
 func (bc *BlockChain) StateAt(root common.Hash) (*state.StateDB, error) {
-	return state.New(root, bc.stateCache, bc.snaps)
+	statedb, err := state.New(root, bc.stateCache, bc.snaps)
+	if err != nil {
+		// the states before the state scheme switch are kept in the other trie
+		if legacy := bc.stateCache.legacy(); legacy != nil {
+			if legacyState, legacyErr := state.New(root, legacy, nil); legacyErr == nil {
+				return legacyState, nil
+			}
+		}
+	}
+	return statedb, err
 }
 
 // Config retrieves the chain's fork configuration.
@@ -29,13 +29,16 @@ import (
 	"testing"
 	"time"
 
+	"github.com/scroll-tech/go-ethereum/common"
 	"github.com/scroll-tech/go-ethereum/consensus"
 	"github.com/scroll-tech/go-ethereum/consensus/ethash"
 	"github.com/scroll-tech/go-ethereum/core/rawdb"
 	"github.com/scroll-tech/go-ethereum/core/types"
 	"github.com/scroll-tech/go-ethereum/core/vm"
+	"github.com/scroll-tech/go-ethereum/crypto"
 	"github.com/scroll-tech/go-ethereum/ethdb"
 	"github.com/scroll-tech/go-ethereum/params"
+	"github.com/scroll-tech/go-ethereum/trie"
 )
 
 // snapshotTestBasic wraps the common testing fields in the snapshot tests.
@@ -716,3 +719,100 @@ func TestRecoverSnapshotFromWipingCrash(t *testing.T) {
 	test.test(t)
 	test.teardown()
 }
+
+// Tests that the snapshot of a zktrie state is maintained while importing blocks, and
+// reloaded after a restart.
+func TestZktrieSnapshot(t *testing.T) {
+	var (
+		key, _   = crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+		sender   = crypto.PubkeyToAddress(key.PublicKey)
+		contract = common.HexToAddress("0xc0de")
+		// SSTORE(NUMBER, NUMBER), SSTORE(1, 0)
+		code   = common.FromHex("0x4343556000600155")
+		config = *params.TestChainConfig
+	)
+	// other tests modify the limits of the shared test config
+	config.Scroll.UseZktrie = true
+	config.Scroll.MaxTxPerBlock = nil
+	config.Scroll.MaxTxPayloadBytesPerBlock = nil
+	gspec := &Genesis{
+		Config: &config,
+		Alloc: GenesisAlloc{
+			sender:   {Balance: big.NewInt(params.Ether)},
+			contract: {Balance: common.Big0, Code: code, Storage: map[common.Hash]common.Hash{common.BigToHash(common.Big1): common.BigToHash(common.Big1)}},
+		},
+	}
+	gendb := rawdb.NewMemoryDatabase()
+	genesis := gspec.MustCommit(gendb)
+	blocks, _ := GenerateChain(&config, genesis, ethash.NewFaker(), gendb, 4, func(i int, b *BlockGen) {
+		signer := types.HomesteadSigner{}
+		tx, _ := types.SignTx(types.NewTransaction(b.TxNonce(sender), common.Address{byte(i + 1)}, common.Big1, params.TxGas, b.BaseFee(), nil), signer, key)
+		b.AddTx(tx)
+		tx, _ = types.SignTx(types.NewTransaction(b.TxNonce(sender), contract, common.Big0, 100000, b.BaseFee(), nil), signer, key)
+		b.AddTx(tx)
+	})
+
+	db := rawdb.NewMemoryDatabase()
+	gspec.MustCommit(db)
+	// zktrie snapshots are opt-in
+	chain, err := NewBlockChain(db, &CacheConfig{TrieCleanLimit: 256, TrieDirtyLimit: 256, TrieTimeLimit: 5 * time.Minute, SnapshotLimit: 256, SnapshotWait: true}, &config, ethash.NewFaker(), vm.Config{}, nil, nil)
+	if err != nil {
+		t.Fatalf("failed to create chain: %v", err)
+	}
+	if chain.Snapshots() != nil {
+		t.Fatal("zktrie snapshot enabled without opting in")
+	}
+	chain.Stop()
+
+	cacheConfig := &CacheConfig{TrieCleanLimit: 256, TrieDirtyLimit: 256, TrieTimeLimit: 5 * time.Minute, SnapshotLimit: 256, ZktrieSnapshot: true, SnapshotWait: true}
+	chain, err = NewBlockChain(db, cacheConfig, &config, ethash.NewFaker(), vm.Config{}, nil, nil)
+	if err != nil {
+		t.Fatalf("failed to create chain: %v", err)
+	}
+	if _, err := chain.InsertChain(blocks); err != nil {
+		t.Fatalf("failed to insert chain: %v", err)
+	}
+	check := func(chain *BlockChain) {
+		t.Helper()
+
+		head := chain.CurrentBlock()
+		if head.Hash() != blocks[3].Hash() {
+			t.Fatalf("head mismatch, expected %d, got %d", blocks[3].NumberU64(), head.NumberU64())
+		}
+		if chain.Snapshots() == nil {
+			t.Fatal("snapshot disabled")
+		}
+		snap := chain.Snapshots().Snapshot(head.Root())
+		if snap == nil {
+			t.Fatal("missing head snapshot")
+		}
+		accountHash, _ := trie.ZkLeafKey(contract.Bytes())
+		slotHash, _ := trie.ZkLeafKey(common.BigToHash(head.Number()).Bytes())
+		if value, err := snap.Storage(accountHash, slotHash); err != nil || !bytes.Equal(value, common.BigToHash(head.Number()).Bytes()) {
+			t.Fatalf("snapshot storage mismatch, got %x, err %v", value, err)
+		}
+		if err := chain.Snapshots().Verify(head.Root()); err != nil {
+			t.Fatalf("failed to verify snapshot: %v", err)
+		}
+		statedb, err := chain.State()
+		if err != nil {
+			t.Fatalf("failed to open head state: %v", err)
+		}
+		if value := statedb.GetState(contract, common.BigToHash(head.Number())); value != common.BigToHash(head.Number()) {
+			t.Fatalf("storage mismatch, got %v", value)
+		}
+		if value := statedb.GetState(contract, common.BigToHash(common.Big1)); value != (common.Hash{}) {
+			t.Fatalf("cleared slot not empty, got %v", value)
+		}
+	}
+	check(chain)
+	chain.Stop()
+
+	// the journalled snapshot is loaded after a restart
+	chain, err = NewBlockChain(db, cacheConfig, &config, ethash.NewFaker(), vm.Config{}, nil, nil)
+	if err != nil {
+		t.Fatalf("failed to reopen chain: %v", err)
+	}
+	defer chain.Stop()
+	check(chain)
+}
@@ -39,6 +39,8 @@ import (
 	"github.com/scroll-tech/go-ethereum/crypto"
 	"github.com/scroll-tech/go-ethereum/ethdb"
 	"github.com/scroll-tech/go-ethereum/params"
+	"github.com/scroll-tech/go-ethereum/rollup/rcfg"
+	"github.com/scroll-tech/go-ethereum/rollup/withdrawtrie"
 	"github.com/scroll-tech/go-ethereum/trie"
 )
 
@@ -3340,6 +3342,138 @@ func TestInsertBlocksWithL1Messages(t *testing.T) {
 	assert.Equal(t, uint64(len(msgs)), *queueIndex)
 }
 
+// TestL1MessageInclusionIndex tests that the inclusion of L1 messages follows the canonical chain.
+func TestL1MessageInclusionIndex(t *testing.T) {
+	var (
+		db     = rawdb.NewMemoryDatabase()
+		engine = ethash.NewFaker()
+	)
+
+	config := params.AllEthashProtocolChanges
+	config.Scroll.L1Config.NumL1MessagesPerBlock = 1
+
+	genspec := &Genesis{
+		Config:  config,
+		BaseFee: big.NewInt(params.InitialBaseFee),
+	}
+	genesis := genspec.MustCommit(db)
+
+	msgs := []types.L1MessageTx{
+		{QueueIndex: 0, Gas: 21016, To: &common.Address{1}, Data: []byte{0x01}, Sender: common.Address{2}},
+		{QueueIndex: 1, Gas: 21016, To: &common.Address{1}, Data: []byte{0x01}, Sender: common.Address{2}},
+	}
+	rawdb.WriteL1Messages(db, msgs)
+
+	blockchain, _ := NewBlockChain(db, nil, config, engine, vm.Config{}, nil, nil)
+	defer blockchain.Stop()
+
+	// one L1 message in each block
+	blocks, _ := GenerateChain(config, genesis, engine, db, len(msgs), func(i int, b *BlockGen) {
+		b.AddTxWithChain(blockchain, types.NewTx(&msgs[i]))
+	})
+	_, err := blockchain.InsertChain(blocks)
+	assert.Nil(t, err)
+	for i, block := range blocks {
+		inclusion := rawdb.ReadL1MessageInclusion(db, uint64(i))
+		assert.NotNil(t, inclusion)
+		assert.Equal(t, block.Hash(), inclusion.L2BlockHash)
+		assert.Equal(t, block.NumberU64(), inclusion.L2BlockNumber)
+		assert.Equal(t, block.Transactions()[0].Hash(), inclusion.TxHash)
+	}
+
+	// a longer fork only includes the first message, in its last block
+	fork, _ := GenerateChain(config, genesis, engine, db, 3, func(i int, b *BlockGen) {
+		b.SetCoinbase(common.Address{0x01})
+		if i == 2 {
+			b.AddTxWithChain(blockchain, types.NewTx(&msgs[0]))
+		}
+	})
+	_, err = blockchain.InsertChain(fork)
+	assert.Nil(t, err)
+	assert.Equal(t, fork[2].Hash(), blockchain.CurrentBlock().Hash())
+
+	inclusion := rawdb.ReadL1MessageInclusion(db, 0)
+	assert.NotNil(t, inclusion)
+	assert.Equal(t, fork[2].Hash(), inclusion.L2BlockHash)
+	assert.Nil(t, rawdb.ReadL1MessageInclusion(db, 1))
+}
+
+func TestWithdrawMessageIndex(t *testing.T) {
+	var (
+		db     = rawdb.NewMemoryDatabase()
+		engine = ethash.NewFaker()
+		key, _ = crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+		addr   = crypto.PubkeyToAddress(key.PublicKey)
+		signer = new(types.HomesteadSigner)
+	)
+	// the message queue emits an AppendMessage event with the calldata
+	code := []byte{byte(vm.PUSH1), 0x40, byte(vm.PUSH1), 0x00, byte(vm.PUSH1), 0x00, byte(vm.CALLDATACOPY), byte(vm.PUSH32)}
+	code = append(code, withdrawtrie.AppendMessageEventTopic.Bytes()...)
+	code = append(code, byte(vm.PUSH1), 0x40, byte(vm.PUSH1), 0x00, byte(vm.LOG1), byte(vm.STOP))
+
+	config := params.AllEthashProtocolChanges
+	genspec := &Genesis{
+		Config: config,
+		Alloc: map[common.Address]GenesisAccount{
+			addr:                       {Balance: big.NewInt(10000000000000000)},
+			rcfg.L2MessageQueueAddress: {Balance: big.NewInt(0), Code: code},
+		},
+		BaseFee: big.NewInt(params.InitialBaseFee),
+	}
+	genesis := genspec.MustCommit(db)
+
+	blockchain, _ := NewBlockChain(db, nil, config, engine, vm.Config{}, nil, nil)
+	defer blockchain.Stop()
+	blockchain.SetWithdrawMessageIndexer(withdrawtrie.MessageIndexer{})
+
+	appendMessage := func(b *BlockGen, nonce uint64, messageHash common.Hash) {
+		data := append(common.BigToHash(new(big.Int).SetUint64(nonce)).Bytes(), messageHash.Bytes()...)
+		tx, err := types.SignTx(types.NewTransaction(b.TxNonce(addr), rcfg.L2MessageQueueAddress, big.NewInt(0), 100000, b.header.BaseFee, data), signer, key)
+		assert.Nil(t, err)
+		b.AddTx(tx)
+	}
+
+	// one message in each block
+	hashes := []common.Hash{{0x01}, {0x02}}
+	blocks, _ := GenerateChain(config, genesis, engine, db, len(hashes), func(i int, b *BlockGen) {
+		appendMessage(b, uint64(i), hashes[i])
+	})
+	_, err := blockchain.InsertChain(blocks)
+	assert.Nil(t, err)
+	for i, block := range blocks {
+		message := rawdb.ReadWithdrawMessage(db, uint64(i))
+		assert.NotNil(t, message)
+		assert.Equal(t, hashes[i], message.MessageHash)
+		assert.Equal(t, block.Hash(), message.BlockHash)
+		assert.Equal(t, block.NumberU64(), message.BlockNumber)
+	}
+	leaves, ok := withdrawtrie.MessageLeaves(db, 1)
+	assert.True(t, ok)
+	assert.Equal(t, hashes[:1], leaves)
+
+	// a longer fork only appends another first message, in its last block
+	fork, _ := GenerateChain(config, genesis, engine, db, 3, func(i int, b *BlockGen) {
+		b.SetCoinbase(common.Address{0x01})
+		if i == 2 {
+			appendMessage(b, 0, common.Hash{0x03})
+		}
+	})
+	_, err = blockchain.InsertChain(fork)
+	assert.Nil(t, err)
+	assert.Equal(t, fork[2].Hash(), blockchain.CurrentBlock().Hash())
+
+	message := rawdb.ReadWithdrawMessage(db, 0)
+	assert.NotNil(t, message)
+	assert.Equal(t, common.Hash{0x03}, message.MessageHash)
+	assert.Equal(t, fork[2].Hash(), message.BlockHash)
+	assert.Nil(t, rawdb.ReadWithdrawMessage(db, 1))
+	assert.Nil(t, rawdb.ReadWithdrawMessageNonce(db, hashes[0]))
+	assert.Nil(t, rawdb.ReadWithdrawMessageNonce(db, hashes[1]))
+	leaves, ok = withdrawtrie.MessageLeaves(db, 2)
+	assert.True(t, ok)
+	assert.Empty(t, leaves)
+}
+
 // TestL1MessageValidationFailure tests that the chain rejects blocks with incorrect L1MessageTx transactions.
 func TestL1MessageValidationFailure(t *testing.T) {
 	var (
@@ -3603,3 +3737,121 @@ func TestEIP3651(t *testing.T) {
 		t.Fatalf("sender balance incorrect: expected %d, got %d", expected, actual)
 	}
 }
+
+// Tests that no blocks are imported while block import is paused.
+func TestPauseImport(t *testing.T) {
+	db, chain, err := newCanonical(ethash.NewFaker(), 0, true)
+	if err != nil {
+		t.Fatalf("failed to create pristine chain: %v", err)
+	}
+	defer chain.Stop()
+
+	blocks := makeBlockChain(chain.CurrentBlock(), 2, ethash.NewFaker(), db, canonicalSeed)
+	chain.PauseImport()
+	if _, err := chain.InsertChain(blocks); !errors.Is(err, ErrImportPaused) {
+		t.Fatalf("unexpected error while import is paused: have %v, want %v", err, ErrImportPaused)
+	}
+	if head := chain.CurrentBlock().NumberU64(); head != 0 {
+		t.Fatalf("chain head mismatch: have %d, want 0", head)
+	}
+
+	chain.ResumeImport()
+	if _, err := chain.InsertChain(blocks); err != nil {
+		t.Fatalf("failed to insert chain after resuming import: %v", err)
+	}
+	if head := chain.CurrentBlock().NumberU64(); head != 2 {
+		t.Fatalf("chain head mismatch: have %d, want 2", head)
+	}
+}
+
+func TestFinalizedBlockRestored(t *testing.T) {
+	db, chain, err := newCanonical(ethash.NewFaker(), 4, true)
+	if err != nil {
+		t.Fatalf("failed to create pristine chain: %v", err)
+	}
+	if chain.CurrentFinalizedBlock() != nil || chain.CurrentSafeBlock() != nil {
+		t.Fatalf("unexpected finalized or safe block before finalization")
+	}
+	chain.Stop()
+
+	rawdb.WriteFinalizedL2BlockNumber(db, 2)
+	chain, err = NewBlockChain(db, nil, params.AllEthashProtocolChanges, ethash.NewFaker(), vm.Config{}, nil, nil)
+	if err != nil {
+		t.Fatalf("failed to reopen chain: %v", err)
+	}
+	defer chain.Stop()
+
+	want := chain.GetHeaderByNumber(2).Hash()
+	if finalized := chain.CurrentFinalizedBlock(); finalized == nil || finalized.Hash() != want {
+		t.Fatalf("finalized block mismatch: have %v, want %x", finalized, want)
+	}
+	if safe := chain.CurrentSafeBlock(); safe == nil || safe.Hash() != want {
+		t.Fatalf("safe block mismatch: have %v, want %x", safe, want)
+	}
+
+	chain.SetSafe(chain.GetHeaderByNumber(3))
+	if safe := chain.CurrentSafeBlock(); safe.Number.Uint64() != 3 {
+		t.Fatalf("safe block number mismatch: have %d, want 3", safe.Number)
+	}
+}
+
+// Tests that the withdraw roots of the blocks that leave the canonical chain are deleted.
+func TestWithdrawRootsOfRemovedBlocks(t *testing.T) {
+	db, chain, err := newCanonical(ethash.NewFaker(), 0, true)
+	if err != nil {
+		t.Fatalf("failed to create pristine chain: %v", err)
+	}
+	defer chain.Stop()
+	withdrawRoot := common.Hash{0x01}
+	chain.SetWithdrawRootFn(func(*state.StateDB) common.Hash { return withdrawRoot })
+
+	blocks, _ := GenerateChain(params.TestChainConfig, chain.CurrentBlock(), ethash.NewFaker(), db, 4, nil)
+	if _, err := chain.InsertChain(blocks); err != nil {
+		t.Fatalf("failed to insert chain: %v", err)
+	}
+	for _, block := range blocks {
+		if root := rawdb.ReadBlockWithdrawRoot(db, block.Hash()); root == nil || *root != withdrawRoot {
+			t.Fatalf("block %d: withdraw root mismatch: have %v, want %v", block.NumberU64(), root, withdrawRoot)
+		}
+	}
+
+	// a reorg keeps the withdraw roots of the old chain, its blocks stay as side chain blocks
+	forks, _ := GenerateChain(params.TestChainConfig, blocks[1], ethash.NewFaker(), db, 3, func(i int, b *BlockGen) {
+		b.SetCoinbase(common.Address{0x02})
+	})
+	if _, err := chain.InsertChain(forks); err != nil {
+		t.Fatalf("failed to insert fork: %v", err)
+	}
+	for _, block := range blocks[2:] {
+		if root := rawdb.ReadBlockWithdrawRoot(db, block.Hash()); root == nil || *root != withdrawRoot {
+			t.Fatalf("block %d: withdraw root of reorged block mismatch: have %v, want %v", block.NumberU64(), root, withdrawRoot)
+		}
+	}
+
+	// the old chain is still verifiable once it becomes canonical again
+	extension, _ := GenerateChain(params.TestChainConfig, blocks[len(blocks)-1], ethash.NewFaker(), db, 2, nil)
+	if _, err := chain.InsertChain(append(blocks[2:], extension...)); err != nil {
+		t.Fatalf("failed to reinsert old chain: %v", err)
+	}
+	if head := chain.CurrentBlock().Hash(); head != extension[len(extension)-1].Hash() {
+		t.Fatalf("head mismatch: have %v, want %v", head, extension[len(extension)-1].Hash())
+	}
+	for _, block := range append(blocks, extension...) {
+		if root := rawdb.ReadBlockWithdrawRoot(db, block.Hash()); root == nil || *root != withdrawRoot {
+			t.Fatalf("block %d: withdraw root mismatch: have %v, want %v", block.NumberU64(), root, withdrawRoot)
+		}
+	}
+
+	// rewinding removes the withdraw roots of the blocks above the new head
+	if err := chain.SetHead(2); err != nil {
+		t.Fatalf("failed to rewind chain: %v", err)
+	}
+	for _, block := range append(blocks[2:], extension...) {
+		if root := rawdb.ReadBlockWithdrawRoot(db, block.Hash()); root != nil {
+			t.Fatalf("block %d: withdraw root of rewound block not deleted", block.NumberU64())
+		}
+	}
+	if root := rawdb.ReadBlockWithdrawRoot(db, blocks[1].Hash()); root == nil {
+		t.Fatal("withdraw root of the new head deleted")
+	}
+}
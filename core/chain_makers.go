@@ -24,10 +24,12 @@ import (
 	"github.com/scroll-tech/go-ethereum/consensus"
 	"github.com/scroll-tech/go-ethereum/consensus/misc"
 	"github.com/scroll-tech/go-ethereum/core/state"
+	"github.com/scroll-tech/go-ethereum/core/state/migrator"
 	"github.com/scroll-tech/go-ethereum/core/types"
 	"github.com/scroll-tech/go-ethereum/core/vm"
 	"github.com/scroll-tech/go-ethereum/ethdb"
 	"github.com/scroll-tech/go-ethereum/params"
+	"github.com/scroll-tech/go-ethereum/trie"
 )
 
 // BlockGen creates blocks for testing.
@@ -242,7 +244,18 @@ func GenerateChain(config *params.ChainConfig, parent *types.Block, engine conse
 		return nil, nil
 	}
 	for i := 0; i < n; i++ {
-		statedb, err := state.New(parent.Root(), state.NewDatabase(db), nil)
+		// the migration of the state at the state scheme switch requires the preimages
+		trieConfig := &trie.Config{Zktrie: config.Scroll.ZktrieEnabledAt(parent.Number()), Preimages: config.Scroll.TrieSwitchBlock != nil}
+		root, stateCache := parent.Root(), state.NewDatabaseWithConfig(db, trieConfig)
+		if number := new(big.Int).Add(parent.Number(), common.Big1); config.Scroll.IsTrieSwitchBlock(number) {
+			target := state.NewDatabaseWithConfig(db, &trie.Config{Zktrie: config.Scroll.ZktrieEnabledAt(number), Preimages: true})
+			migrated, err := migrator.NewMigrator(db, stateCache, target).Migrate(parent.Header(), nil)
+			if err != nil {
+				panic(fmt.Sprintf("state migration error: %v", err))
+			}
+			root, stateCache = migrated, target
+		}
+		statedb, err := state.New(root, stateCache, nil)
 		if err != nil {
 			panic(err)
 		}
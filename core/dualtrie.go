@@ -0,0 +1,398 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/scroll-tech/go-ethereum/common"
+	"github.com/scroll-tech/go-ethereum/common/prque"
+	"github.com/scroll-tech/go-ethereum/core/rawdb"
+	"github.com/scroll-tech/go-ethereum/core/state"
+	"github.com/scroll-tech/go-ethereum/core/types"
+	"github.com/scroll-tech/go-ethereum/core/vm"
+	"github.com/scroll-tech/go-ethereum/crypto"
+	"github.com/scroll-tech/go-ethereum/crypto/codehash"
+	"github.com/scroll-tech/go-ethereum/ethdb"
+	"github.com/scroll-tech/go-ethereum/log"
+	"github.com/scroll-tech/go-ethereum/metrics"
+	"github.com/scroll-tech/go-ethereum/trie"
+)
+
+var (
+	shadowStateBlockTimer       = metrics.NewRegisteredTimer("chain/dualtrie/block", nil)
+	shadowStateDivergenceMeter  = metrics.NewRegisteredMeter("chain/dualtrie/divergence", nil)
+	shadowStateMissingRootMeter = metrics.NewRegisteredMeter("chain/dualtrie/missing", nil)
+)
+
+var (
+	errShadowStateMissing         = errors.New("shadow MPT state missing")
+	errShadowBootstrapInterrupted = errors.New("shadow MPT state bootstrap interrupted")
+)
+
+// shadowBootstrapBatchAccounts is the number of accounts copied into the shadow state
+// between two commits of its bootstrap.
+const shadowBootstrapBatchAccounts = 10000
+
+// shadowState is the MPT state maintained alongside the zktrie state of the chain in the
+// dual-trie mode. Every block is executed a second time on the shadow state, and after both
+// executions the accounts and storage slots loaded by either of them are compared between
+// the zktrie and the MPT state. The root of the shadow state after every block is recorded,
+// a divergence is reported once, the shadow state is not advanced past it.
+//
+// Like the state of the chain, the shadow states of the recent blocks are kept in memory,
+// and the shadow state is written to the disk every TriesInMemory blocks and on shutdown.
+type shadowState struct {
+	bc        *BlockChain
+	state     state.Database // backed by a table of the chain database
+	processor Processor
+	start     *types.Header // block the shadow state was loaded or bootstrapped at
+
+	mu         sync.Mutex   // serializes the executions of blocks on the shadow state
+	synced     bool         // whether the imported blocks are executed as they are written
+	diverged   bool         // whether the shadow state diverged from the zktrie state
+	triegc     *prque.Prque // roots of the shadow states in memory, by block number
+	lastCommit uint64       // number of the last block whose shadow state was written to the disk
+
+	quit chan struct{}
+	done chan struct{}
+}
+
+// newShadowState opens the shadow state of the chain. The shadow state of the newest block
+// it was written to the disk for is loaded, if it is missing the shadow state is bootstrapped
+// from the zktrie state of the head block in the background.
+func newShadowState(bc *BlockChain) (*shadowState, error) {
+	if !bc.chainConfig.Scroll.ZktrieEnabled() {
+		return nil, errors.New("dual-trie mode requires a zktrie state")
+	}
+	if !bc.cacheConfig.Preimages {
+		return nil, errors.New("dual-trie mode requires the recording of preimages")
+	}
+	s := &shadowState{
+		bc:        bc,
+		state:     state.NewDatabaseWithConfig(rawdb.NewTable(bc.db, string(rawdb.ShadowStatePrefix)), &trie.Config{Cache: 16}),
+		processor: NewStateProcessor(bc.chainConfig, bc, bc.engine),
+		triegc:    prque.New(nil),
+		quit:      make(chan struct{}),
+		done:      make(chan struct{}),
+	}
+	head := bc.CurrentBlock().Header()
+	// The shadow states of the blocks before the shutdown might not have been written
+	for header := head; header != nil; header = bc.GetHeader(header.ParentHash, header.Number.Uint64()-1) {
+		root := rawdb.ReadShadowStateRoot(bc.db, header.Hash())
+		if root == nil {
+			break
+		}
+		if s.hasState(*root) {
+			s.start, s.synced, s.lastCommit = header, true, header.Number.Uint64()
+			close(s.done)
+			log.Info("Loaded shadow MPT state", "number", header.Number, "hash", header.Hash(), "root", *root)
+			return s, nil
+		}
+		if header.Number.Sign() == 0 {
+			break
+		}
+	}
+	s.start, s.lastCommit = head, head.Number.Uint64()
+	log.Info("Bootstrapping shadow MPT state", "number", head.Number, "hash", head.Hash())
+	go s.loop()
+	return s, nil
+}
+
+// loop bootstraps the shadow state at the start block, the blocks imported since then are
+// executed on the shadow state along with the next imported block.
+func (s *shadowState) loop() {
+	defer close(s.done)
+
+	block := s.bc.GetBlock(s.start.Hash(), s.start.Number.Uint64())
+	if block == nil {
+		log.Error("Failed to bootstrap the shadow MPT state", "number", s.start.Number, "err", "missing block")
+		return
+	}
+	if err := s.bootstrap(block); err != nil {
+		if !errors.Is(err, errShadowBootstrapInterrupted) {
+			log.Error("Failed to bootstrap the shadow MPT state", "number", block.NumberU64(), "err", err)
+		}
+		return
+	}
+	s.mu.Lock()
+	s.synced = true
+	s.mu.Unlock()
+}
+
+// bootstrap copies the zktrie state after a block into the shadow state. The accounts and
+// storage slots are read from the leaves of the zktrie, which requires the preimages of
+// their keys.
+func (s *shadowState) bootstrap(block *types.Block) error {
+	accountTrie, err := s.bc.stateCache.OpenTrie(block.Root())
+	if err != nil {
+		return err
+	}
+	zkTrie, ok := accountTrie.(*trie.ZkTrie)
+	if !ok {
+		return fmt.Errorf("unexpected trie type %T", accountTrie)
+	}
+	statedb, err := state.New(common.Hash{}, s.state, nil)
+	if err != nil {
+		return err
+	}
+	var (
+		root     common.Hash
+		accounts int
+		start    = time.Now()
+		logged   = time.Now()
+	)
+	err = zkTrie.IterateLeaves(func(key, value []byte) error {
+		select {
+		case <-s.quit:
+			return errShadowBootstrapInterrupted
+		default:
+		}
+		addr := common.BytesToAddress(key)
+		account, err := types.UnmarshalStateAccount(value)
+		if err != nil {
+			return fmt.Errorf("account %v: %w", addr, err)
+		}
+		statedb.SetNonce(addr, account.Nonce)
+		statedb.SetBalance(addr, account.Balance)
+		if codeHash := common.BytesToHash(account.KeccakCodeHash); codeHash != codehash.EmptyKeccakCodeHash {
+			code, err := s.bc.stateCache.ContractCode(crypto.Keccak256Hash(addr.Bytes()), codeHash)
+			if err != nil {
+				return fmt.Errorf("code of account %v: %w", addr, err)
+			}
+			statedb.SetCode(addr, code)
+		}
+		if account.Root != (common.Hash{}) {
+			storageTrie, err := s.bc.stateCache.OpenStorageTrie(crypto.Keccak256Hash(addr.Bytes()), account.Root)
+			if err != nil {
+				return fmt.Errorf("storage of account %v: %w", addr, err)
+			}
+			zkStorageTrie, ok := storageTrie.(*trie.ZkTrie)
+			if !ok {
+				return fmt.Errorf("unexpected trie type %T", storageTrie)
+			}
+			err = zkStorageTrie.IterateLeaves(func(key, value []byte) error {
+				statedb.SetState(addr, common.BytesToHash(key), common.BytesToHash(value))
+				return nil
+			})
+			if err != nil {
+				return fmt.Errorf("storage of account %v: %w", addr, err)
+			}
+		}
+		accounts++
+		if accounts%shadowBootstrapBatchAccounts == 0 {
+			// the empty accounts of the zktrie state are kept
+			if root, err = s.commit(statedb, false); err != nil {
+				return err
+			}
+			if statedb, err = state.New(root, s.state, nil); err != nil {
+				return err
+			}
+		}
+		if time.Since(logged) > 8*time.Second {
+			log.Info("Bootstrapping shadow MPT state", "number", block.NumberU64(), "accounts", accounts, "elapsed", common.PrettyDuration(time.Since(start)))
+			logged = time.Now()
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	if root, err = s.commit(statedb, false); err != nil {
+		return err
+	}
+	rawdb.WriteShadowStateRoot(s.bc.db, block.Hash(), root)
+	log.Info("Bootstrapped shadow MPT state", "number", block.NumberU64(), "hash", block.Hash(), "root", root, "accounts", accounts, "elapsed", common.PrettyDuration(time.Since(start)))
+	return nil
+}
+
+// process executes a block on the shadow state after its parent and compares the state
+// with zkState, the committed zktrie state after the block, once the shadow state is
+// bootstrapped. Blocks without an ancestor with a shadow state since the start block, like
+// the blocks of side chains forked off before the dual-trie mode was enabled, are skipped.
+func (s *shadowState) process(block *types.Block, zkState *state.StateDB) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !s.synced || s.diverged {
+		return
+	}
+	start := time.Now()
+	root, err := s.rootOf(block, zkState)
+	if errors.Is(err, errShadowStateMissing) {
+		shadowStateMissingRootMeter.Mark(1)
+		log.Warn("Shadow MPT state of parent block missing", "number", block.NumberU64(), "hash", block.Hash(), "parent", block.ParentHash(), "err", err)
+		return
+	}
+	if err != nil {
+		s.diverged = true
+		shadowStateDivergenceMeter.Mark(1)
+		log.Error("Shadow MPT state diverged from the zktrie state, not maintaining it any further", "number", block.NumberU64(), "hash", block.Hash(), "err", err)
+		return
+	}
+	shadowStateBlockTimer.UpdateSince(start)
+	log.Debug("Cross-checked shadow MPT state", "number", block.NumberU64(), "hash", block.Hash(), "root", block.Root(), "shadowRoot", root)
+}
+
+// rootOf executes a block on the shadow state, first executing the ancestors since its last
+// ancestor with a shadow state, and returns the root of the shadow state after the block.
+// It must be called with s.mu held.
+func (s *shadowState) rootOf(block *types.Block, zkState *state.StateDB) (common.Hash, error) {
+	var (
+		blocks = []*types.Block{block}
+		root   common.Hash
+	)
+	for {
+		oldest := blocks[len(blocks)-1]
+		if stored := rawdb.ReadShadowStateRoot(s.bc.db, oldest.ParentHash()); stored != nil && s.hasState(*stored) {
+			root = *stored
+			break
+		}
+		if oldest.NumberU64() <= s.start.Number.Uint64()+1 {
+			return common.Hash{}, fmt.Errorf("%w: block %d (%v)", errShadowStateMissing, oldest.NumberU64()-1, oldest.ParentHash())
+		}
+		parent := s.bc.GetBlock(oldest.ParentHash(), oldest.NumberU64()-1)
+		if parent == nil {
+			return common.Hash{}, fmt.Errorf("%w: missing block %d (%v)", errShadowStateMissing, oldest.NumberU64()-1, oldest.ParentHash())
+		}
+		blocks = append(blocks, parent)
+	}
+	for i := len(blocks) - 1; i >= 0; i-- {
+		blockZkState := zkState
+		if i > 0 {
+			var err error
+			if blockZkState, err = s.bc.StateAt(blocks[i].Root()); err != nil {
+				return common.Hash{}, fmt.Errorf("%w: zktrie state of block %d: %v", errShadowStateMissing, blocks[i].NumberU64(), err)
+			}
+		}
+		var err error
+		if root, err = s.execute(blocks[i], root, blockZkState); err != nil {
+			return common.Hash{}, fmt.Errorf("block %d (%v), parent root %v: %w", blocks[i].NumberU64(), blocks[i].Hash(), root, err)
+		}
+		if err := s.track(blocks[i], root); err != nil {
+			return common.Hash{}, err
+		}
+	}
+	return root, nil
+}
+
+// execute executes a block on the shadow state with the given root, and returns the root of
+// the shadow state after the block, which is kept in memory.
+func (s *shadowState) execute(block *types.Block, parentRoot common.Hash, zkState *state.StateDB) (common.Hash, error) {
+	statedb, err := state.New(parentRoot, s.state, nil)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	if err := replayBlock(s.processor, block, statedb); err != nil {
+		return common.Hash{}, err
+	}
+	root, err := statedb.Commit(s.bc.chainConfig.IsEIP158(block.Number()))
+	if err != nil {
+		return common.Hash{}, err
+	}
+	if err := state.DiffLoadedState(zkState, statedb); err != nil {
+		return common.Hash{}, fmt.Errorf("zktrie and shadow MPT state differ: %w", err)
+	}
+	return root, nil
+}
+
+// track records the shadow state after a block, and garbage collects the shadow states in
+// memory like the chain does with its state: the shadow states of the last TriesInMemory
+// blocks are kept, the dirty nodes are flushed past the dirty limit of the chain and the
+// shadow state is written to the disk every TriesInMemory blocks.
+func (s *shadowState) track(block *types.Block, root common.Hash) error {
+	rawdb.WriteShadowStateRoot(s.bc.db, block.Hash(), root)
+
+	triedb := s.state.TrieDB()
+	triedb.Reference(root, common.Hash{})
+	s.triegc.Push(root, -int64(block.NumberU64()))
+
+	var (
+		nodes, imgs = triedb.Size()
+		limit       = common.StorageSize(s.bc.cacheConfig.TrieDirtyLimit) * 1024 * 1024
+	)
+	if nodes > limit || imgs > 4*1024*1024 {
+		if err := triedb.Cap(limit - ethdb.IdealBatchSize); err != nil {
+			return err
+		}
+	}
+	current := block.NumberU64()
+	if current >= s.lastCommit+TriesInMemory {
+		if err := triedb.Commit(root, false, nil); err != nil {
+			return err
+		}
+		s.lastCommit = current
+	}
+	if current <= TriesInMemory {
+		return nil
+	}
+	for !s.triegc.Empty() {
+		root, number := s.triegc.Pop()
+		if uint64(-number) > current-TriesInMemory {
+			s.triegc.Push(root, number)
+			break
+		}
+		triedb.Dereference(root.(common.Hash))
+	}
+	return nil
+}
+
+// hasState reports whether the shadow state with the given root is available.
+func (s *shadowState) hasState(root common.Hash) bool {
+	_, err := s.state.OpenTrie(root)
+	return err == nil
+}
+
+// stop interrupts the bootstrap, writes the shadow state of the head block to the disk and
+// releases the shadow states in memory.
+func (s *shadowState) stop() {
+	close(s.quit)
+	<-s.done
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	triedb := s.state.TrieDB()
+	head := s.bc.CurrentBlock()
+	if root := rawdb.ReadShadowStateRoot(s.bc.db, head.Hash()); root != nil && s.hasState(*root) {
+		log.Info("Writing shadow MPT state to disk", "number", head.Number(), "hash", head.Hash(), "root", *root)
+		if err := triedb.Commit(*root, false, nil); err != nil {
+			log.Error("Failed to commit shadow MPT state", "err", err)
+		}
+	}
+	for !s.triegc.Empty() {
+		triedb.Dereference(s.triegc.PopItem().(common.Hash))
+	}
+}
+
+// commit writes the shadow state to the disk.
+func (s *shadowState) commit(statedb *state.StateDB, deleteEmptyObjects bool) (common.Hash, error) {
+	return commitState(s.state, statedb, deleteEmptyObjects)
+}
+
+// replayBlock executes a block on a state kept in another trie than the state of the chain,
+// and checks the result against the header. The state root is left unchecked, as it differs
+// between the tries.
+func replayBlock(processor Processor, block *types.Block, statedb *state.StateDB) error {
+	receipts, _, usedGas, err := processor.Process(block, statedb, vm.Config{})
+	if err != nil {
+		return fmt.Errorf("failed to execute block: %w", err)
+	}
+	if usedGas != block.GasUsed() {
+		return fmt.Errorf("gas used: %d, header: %d", usedGas, block.GasUsed())
+	}
+	if receiptSha := types.DeriveSha(receipts, trie.NewStackTrie(nil)); receiptSha != block.ReceiptHash() {
+		return fmt.Errorf("receipt root: %x, header: %x", receiptSha, block.ReceiptHash())
+	}
+	return nil
+}
+
+// commitState writes a state to the disk of its state database.
+func commitState(db state.Database, statedb *state.StateDB, deleteEmptyObjects bool) (common.Hash, error) {
+	root, err := statedb.Commit(deleteEmptyObjects)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	return root, db.TrieDB().Commit(root, false, nil)
+}
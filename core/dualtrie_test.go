@@ -0,0 +1,122 @@
+package core
+
+import (
+	"math/big"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/scroll-tech/go-ethereum/common"
+	"github.com/scroll-tech/go-ethereum/consensus/ethash"
+	"github.com/scroll-tech/go-ethereum/core/rawdb"
+	"github.com/scroll-tech/go-ethereum/core/state"
+	"github.com/scroll-tech/go-ethereum/core/types"
+	"github.com/scroll-tech/go-ethereum/core/vm"
+	"github.com/scroll-tech/go-ethereum/crypto"
+	"github.com/scroll-tech/go-ethereum/params"
+)
+
+func TestShadowState(t *testing.T) {
+	var (
+		key, _   = crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+		sender   = crypto.PubkeyToAddress(key.PublicKey)
+		contract = common.HexToAddress("0xc0de")
+		// SSTORE(NUMBER, NUMBER), SSTORE(1, 0)
+		code      = common.FromHex("0x4343556000600155")
+		mptConfig = *params.TestChainConfig
+	)
+	// other tests modify the limits of the shared test config
+	mptConfig.Scroll.UseZktrie = false
+	mptConfig.Scroll.MaxTxPerBlock = nil
+	mptConfig.Scroll.MaxTxPayloadBytesPerBlock = nil
+	zkConfig := mptConfig
+	zkConfig.Scroll.UseZktrie = true
+	gspec := func(config *params.ChainConfig) *Genesis {
+		return &Genesis{
+			Config: config,
+			Alloc: GenesisAlloc{
+				sender:   {Balance: big.NewInt(params.Ether)},
+				contract: {Balance: common.Big0, Code: code, Storage: map[common.Hash]common.Hash{common.BigToHash(common.Big1): common.BigToHash(common.Big1)}},
+			},
+		}
+	}
+	generate := func(i int, b *BlockGen) {
+		signer := types.HomesteadSigner{}
+		tx, _ := types.SignTx(types.NewTransaction(b.TxNonce(sender), common.Address{byte(i + 1)}, common.Big1, params.TxGas, b.BaseFee(), nil), signer, key)
+		b.AddTx(tx)
+		tx, _ = types.SignTx(types.NewTransaction(b.TxNonce(sender), contract, common.Big0, 100000, b.BaseFee(), nil), signer, key)
+		b.AddTx(tx)
+	}
+
+	// the same chain on the MPT, whose state roots the shadow state must reproduce
+	mptDb := rawdb.NewMemoryDatabase()
+	mptGenesis := gspec(&mptConfig).MustCommit(mptDb)
+	mptBlocks, _ := GenerateChain(&mptConfig, mptGenesis, ethash.NewFaker(), mptDb, 4, generate)
+
+	gendb := rawdb.NewMemoryDatabase()
+	genesis := gspec(&zkConfig).MustCommit(gendb)
+	blocks, _ := GenerateChain(&zkConfig, genesis, ethash.NewFaker(), gendb, 4, generate)
+
+	db := rawdb.NewMemoryDatabase()
+	gspec(&zkConfig).MustCommit(db)
+	cacheConfig := &CacheConfig{TrieCleanLimit: 256, TrieDirtyLimit: 256, TrieTimeLimit: 5 * time.Minute, Preimages: true, DualTrie: true}
+	chain, err := NewBlockChain(db, cacheConfig, &zkConfig, ethash.NewFaker(), vm.Config{}, nil, nil)
+	if err != nil {
+		t.Fatalf("failed to create chain: %v", err)
+	}
+
+	// the shadow state is bootstrapped from the genesis zktrie state in the background
+	defer func() {
+		if chain != nil {
+			chain.Stop()
+		}
+	}()
+	<-chain.shadow.done
+	if root := rawdb.ReadShadowStateRoot(db, genesis.Hash()); root == nil || *root != mptGenesis.Root() {
+		t.Fatalf("genesis shadow root mismatch, expected %v, got %v", mptGenesis.Root(), root)
+	}
+	if _, err := chain.InsertChain(blocks); err != nil {
+		t.Fatalf("failed to insert chain: %v", err)
+	}
+	if chain.shadow.diverged {
+		t.Fatal("shadow state diverged")
+	}
+	for i, block := range blocks {
+		if root := rawdb.ReadShadowStateRoot(db, block.Hash()); root == nil || *root != mptBlocks[i].Root() {
+			t.Fatalf("block %d: shadow root mismatch, expected %v, got %v", block.NumberU64(), mptBlocks[i].Root(), root)
+		}
+	}
+
+	// a shadow state diverging from the zktrie state is detected
+	parent, block := blocks[2], blocks[3]
+	shadowParent, err := state.New(mptBlocks[2].Root(), chain.shadow.state, nil)
+	if err != nil {
+		t.Fatalf("failed to open shadow state: %v", err)
+	}
+	shadowParent.AddBalance(sender, common.Big1)
+	corruptRoot, err := chain.shadow.commit(shadowParent, false)
+	if err != nil {
+		t.Fatalf("failed to commit shadow state: %v", err)
+	}
+	zkState, err := chain.StateAt(block.Root())
+	if err != nil {
+		t.Fatalf("failed to open zktrie state: %v", err)
+	}
+	if _, err := chain.shadow.execute(block, corruptRoot, zkState); err == nil || !strings.Contains(err.Error(), "balance") {
+		t.Fatalf("expected balance divergence, got %v", err)
+	}
+	rawdb.WriteShadowStateRoot(db, parent.Hash(), corruptRoot)
+	chain.shadow.process(block, zkState)
+	if !chain.shadow.diverged {
+		t.Fatal("divergence not detected")
+	}
+
+	// the shadow state of the head block is written on shutdown and loaded on restart
+	chain.Stop()
+	if chain, err = NewBlockChain(db, cacheConfig, &zkConfig, ethash.NewFaker(), vm.Config{}, nil, nil); err != nil {
+		t.Fatalf("failed to reopen chain: %v", err)
+	}
+	if !chain.shadow.synced || chain.shadow.start.Hash() != blocks[3].Hash() {
+		t.Fatalf("shadow state not loaded at the head block, synced %v, start %d", chain.shadow.synced, chain.shadow.start.Number)
+	}
+}
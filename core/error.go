@@ -35,6 +35,9 @@ var (
 	// ErrNoGenesis is returned when there is no Genesis Block.
 	ErrNoGenesis = errors.New("genesis not found in chain")
 
+	// ErrImportPaused is returned when a block is imported while block import is paused.
+	ErrImportPaused = errors.New("block import paused")
+
 	errSideChainReceipts = errors.New("side blocks can't be accepted as ancient chain data")
 )
 
@@ -74,6 +77,10 @@ var (
 	// is higher than the balance of the user's account.
 	ErrInsufficientFunds = errors.New("insufficient funds for gas * price + value")
 
+	// ErrInsufficientFundsForL1DataFee is returned if the total cost of executing a
+	// transaction including the L1 data fee is higher than the balance of the user's account.
+	ErrInsufficientFundsForL1DataFee = errors.New("invalid transaction: insufficient funds for l1fee + gas * price + value")
+
 	// ErrGasUintOverflow is returned when calculating gas usage.
 	ErrGasUintOverflow = errors.New("gas uint64 overflow")
 
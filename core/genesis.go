@@ -277,7 +277,11 @@ func (g *Genesis) ToBlock(db ethdb.Database) *types.Block {
 	}
 	var trieCfg *trie.Config
 	if g.Config != nil {
-		trieCfg = &trie.Config{Zktrie: g.Config.Scroll.ZktrieEnabled()}
+		// the preimages of the keys are recorded if the genesis state might be iterated, to
+		// bootstrap the shadow state of the dual-trie mode or to migrate the state ahead of
+		// the state scheme switch
+		zktrie := g.Config.Scroll.ZktrieEnabled()
+		trieCfg = &trie.Config{Zktrie: zktrie, Preimages: zktrie || g.Config.Scroll.TrieSwitchBlock != nil}
 	}
 	statedb, err := state.New(common.Hash{}, state.NewDatabaseWithConfig(db, trieCfg), nil)
 	if err != nil {
@@ -624,6 +624,10 @@ type storedReceiptRLP struct {
 	CumulativeGasUsed uint64
 	Logs              []*types.LogForStorage
 	L1Fee             *big.Int
+	L1GasUsed         *big.Int `rlp:"optional"`
+	L1BaseFee         *big.Int `rlp:"optional"`
+	L1FeeOverhead     *big.Int `rlp:"optional"`
+	L1FeeScalar       *big.Int `rlp:"optional"`
 }
 
 // ReceiptLogs is a barebone version of ReceiptForStorage which only keeps
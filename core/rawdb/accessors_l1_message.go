@@ -85,6 +85,25 @@ func ReadHighestSyncedQueueIndex(db ethdb.Reader) uint64 {
 	return number.Uint64()
 }
 
+// WriteL1Mode writes the source of L1 data selected at runtime to the database.
+func WriteL1Mode(db ethdb.KeyValueWriter, mode string) {
+	if err := db.Put(l1ModeKey, []byte(mode)); err != nil {
+		log.Crit("Failed to update L1 mode", "err", err)
+	}
+}
+
+// ReadL1Mode retrieves the source of L1 data selected at runtime, or "" if none was selected.
+func ReadL1Mode(db ethdb.Reader) string {
+	data, err := db.Get(l1ModeKey)
+	if err != nil && isNotFoundErr(err) {
+		return ""
+	}
+	if err != nil {
+		log.Crit("Failed to read L1 mode from database", "err", err)
+	}
+	return string(data)
+}
+
 // WriteL1Message writes an L1 message to the database.
 // We assume that L1 messages are written to DB following their queue index order.
 func WriteL1Message(db ethdb.KeyValueWriter, l1Msg types.L1MessageTx) {
@@ -132,6 +151,125 @@ func ReadL1Message(db ethdb.Reader, queueIndex uint64) *types.L1MessageTx {
 	return l1Msg
 }
 
+// L1MessageFee contains the fee fields of an L1 message that was enqueued in the
+// L1MessageQueueV2 contract. They are not part of the L1 message transaction.
+type L1MessageFee struct {
+	L2BaseFee *big.Int // L2 base fee used for the message by the queue contract
+	Fee       *big.Int // fee paid on L1 for the message
+}
+
+// WriteL1MessageFee writes the fee fields of an L1 message to the database.
+func WriteL1MessageFee(db ethdb.KeyValueWriter, queueIndex uint64, fee *L1MessageFee) {
+	bytes, err := rlp.EncodeToBytes(fee)
+	if err != nil {
+		log.Crit("Failed to RLP encode L1 message fee", "err", err)
+	}
+	if err := db.Put(l1MessageFeeKey(queueIndex), bytes); err != nil {
+		log.Crit("Failed to store L1 message fee", "queueIndex", queueIndex, "err", err)
+	}
+}
+
+// ReadL1MessageFee retrieves the fee fields of an L1 message, or nil if the message
+// was not enqueued in the L1MessageQueueV2 contract.
+func ReadL1MessageFee(db ethdb.Reader, queueIndex uint64) *L1MessageFee {
+	data, err := db.Get(l1MessageFeeKey(queueIndex))
+	if err != nil && isNotFoundErr(err) {
+		return nil
+	}
+	if err != nil {
+		log.Crit("Failed to load L1 message fee", "queueIndex", queueIndex, "err", err)
+	}
+	fee := new(L1MessageFee)
+	if err := rlp.DecodeBytes(data, fee); err != nil {
+		log.Crit("Invalid L1 message fee RLP", "queueIndex", queueIndex, "data", data, "err", err)
+	}
+	return fee
+}
+
+// L1MessageOrigin identifies the L1 transaction that enqueued an L1 message.
+type L1MessageOrigin struct {
+	L1BlockNumber uint64
+	L1TxHash      common.Hash
+}
+
+// WriteL1MessageOrigin writes the origin of an L1 message to the database.
+func WriteL1MessageOrigin(db ethdb.KeyValueWriter, queueIndex uint64, origin *L1MessageOrigin) {
+	bytes, err := rlp.EncodeToBytes(origin)
+	if err != nil {
+		log.Crit("Failed to RLP encode L1 message origin", "err", err)
+	}
+	if err := db.Put(l1MessageOriginKey(queueIndex), bytes); err != nil {
+		log.Crit("Failed to store L1 message origin", "queueIndex", queueIndex, "err", err)
+	}
+}
+
+// ReadL1MessageOrigin retrieves the origin of an L1 message, or nil if it was not
+// recorded, e.g. for messages synced by an older version.
+func ReadL1MessageOrigin(db ethdb.Reader, queueIndex uint64) *L1MessageOrigin {
+	data, err := db.Get(l1MessageOriginKey(queueIndex))
+	if err != nil && isNotFoundErr(err) {
+		return nil
+	}
+	if err != nil {
+		log.Crit("Failed to load L1 message origin", "queueIndex", queueIndex, "err", err)
+	}
+	origin := new(L1MessageOrigin)
+	if err := rlp.DecodeBytes(data, origin); err != nil {
+		log.Crit("Invalid L1 message origin RLP", "queueIndex", queueIndex, "data", data, "err", err)
+	}
+	return origin
+}
+
+// L1MessageInclusion identifies the canonical L2 transaction that executed an L1 message.
+type L1MessageInclusion struct {
+	L2BlockNumber uint64
+	L2BlockHash   common.Hash
+	TxHash        common.Hash
+}
+
+// WriteL1MessageInclusionsByBlock stores the inclusion of all L1 messages of a canonical block.
+func WriteL1MessageInclusionsByBlock(db ethdb.KeyValueWriter, block *types.Block) {
+	for _, tx := range block.Transactions() {
+		if !tx.IsL1MessageTx() {
+			continue
+		}
+		inclusion := &L1MessageInclusion{L2BlockNumber: block.NumberU64(), L2BlockHash: block.Hash(), TxHash: tx.Hash()}
+		bytes, err := rlp.EncodeToBytes(inclusion)
+		if err != nil {
+			log.Crit("Failed to RLP encode L1 message inclusion", "err", err)
+		}
+		queueIndex := tx.AsL1MessageTx().QueueIndex
+		if err := db.Put(l1MessageInclusionKey(queueIndex), bytes); err != nil {
+			log.Crit("Failed to store L1 message inclusion", "queueIndex", queueIndex, "err", err)
+		}
+	}
+}
+
+// ReadL1MessageInclusion retrieves the inclusion of an L1 message, or nil if it is not
+// included in the canonical chain or was included in a block imported by an older version.
+func ReadL1MessageInclusion(db ethdb.Reader, queueIndex uint64) *L1MessageInclusion {
+	data, err := db.Get(l1MessageInclusionKey(queueIndex))
+	if err != nil && isNotFoundErr(err) {
+		return nil
+	}
+	if err != nil {
+		log.Crit("Failed to load L1 message inclusion", "queueIndex", queueIndex, "err", err)
+	}
+	inclusion := new(L1MessageInclusion)
+	if err := rlp.DecodeBytes(data, inclusion); err != nil {
+		log.Crit("Invalid L1 message inclusion RLP", "queueIndex", queueIndex, "data", data, "err", err)
+	}
+	return inclusion
+}
+
+// DeleteL1MessageInclusion removes the inclusion of an L1 message, e.g. after the block
+// that included it was reorged out.
+func DeleteL1MessageInclusion(db ethdb.KeyValueWriter, queueIndex uint64) {
+	if err := db.Delete(l1MessageInclusionKey(queueIndex)); err != nil {
+		log.Crit("Failed to delete L1 message inclusion", "queueIndex", queueIndex, "err", err)
+	}
+}
+
 // L1MessageIterator is a wrapper around ethdb.Iterator that
 // allows us to iterate over L1 messages in the database. It
 // implements an interface similar to ethdb.Iterator.
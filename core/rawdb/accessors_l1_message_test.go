@@ -55,6 +55,49 @@ func TestReadWriteL1Message(t *testing.T) {
 	}
 }
 
+func TestReadWriteL1MessageFee(t *testing.T) {
+	db := NewMemoryDatabase()
+	if got := ReadL1MessageFee(db, 1); got != nil {
+		t.Fatal("unexpected L1 message fee", "got", got)
+	}
+	WriteL1MessageFee(db, 1, &L1MessageFee{L2BaseFee: big.NewInt(10), Fee: big.NewInt(2000)})
+	got := ReadL1MessageFee(db, 1)
+	if got == nil || got.L2BaseFee.Uint64() != 10 || got.Fee.Uint64() != 2000 {
+		t.Fatal("L1 message fee mismatch", "got", got)
+	}
+}
+
+func TestReadWriteL1MessageOrigin(t *testing.T) {
+	db := NewMemoryDatabase()
+	if got := ReadL1MessageOrigin(db, 1); got != nil {
+		t.Fatal("unexpected L1 message origin", "got", got)
+	}
+	origin := &L1MessageOrigin{L1BlockNumber: 100, L1TxHash: common.Hash{0x01}}
+	WriteL1MessageOrigin(db, 1, origin)
+	if got := ReadL1MessageOrigin(db, 1); got == nil || *got != *origin {
+		t.Fatal("L1 message origin mismatch", "expected", origin, "got", got)
+	}
+}
+
+func TestReadWriteL1MessageInclusion(t *testing.T) {
+	db := NewMemoryDatabase()
+	msg := newL1MessageTx(7)
+	block := types.NewBlockWithHeader(&types.Header{Number: big.NewInt(5)}).WithBody([]*types.Transaction{types.NewTx(&msg)}, nil)
+
+	if got := ReadL1MessageInclusion(db, 7); got != nil {
+		t.Fatal("unexpected L1 message inclusion", "got", got)
+	}
+	WriteL1MessageInclusionsByBlock(db, block)
+	expected := &L1MessageInclusion{L2BlockNumber: 5, L2BlockHash: block.Hash(), TxHash: block.Transactions()[0].Hash()}
+	if got := ReadL1MessageInclusion(db, 7); got == nil || *got != *expected {
+		t.Fatal("L1 message inclusion mismatch", "expected", expected, "got", got)
+	}
+	DeleteL1MessageInclusion(db, 7)
+	if got := ReadL1MessageInclusion(db, 7); got != nil {
+		t.Fatal("L1 message inclusion was not deleted", "got", got)
+	}
+}
+
 func TestIterateL1Message(t *testing.T) {
 	msgs := []types.L1MessageTx{
 		newL1MessageTx(100),
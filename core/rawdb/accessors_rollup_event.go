@@ -2,7 +2,10 @@ package rawdb
 
 import (
 	"bytes"
+	"encoding/binary"
+	"math"
 	"math/big"
+	"time"
 
 	"github.com/scroll-tech/go-ethereum/common"
 	"github.com/scroll-tech/go-ethereum/ethdb"
@@ -24,6 +27,119 @@ type FinalizedBatchMeta struct {
 	WithdrawRoot         common.Hash
 }
 
+// BatchL1Meta records the L1 transactions that committed and finalized a batch.
+// The finalize fields are zero until the batch is finalized. The L1 block hashes
+// allow checking whether the transactions are still canonical after an L1 reorg,
+// they are zero for batches stored before the hashes were recorded. The finalized
+// roots are the ones of the finalize event, they are zero for the batches of a
+// bundle but its last one, whose roots are not recorded on L1.
+type BatchL1Meta struct {
+	CommitTxHash          common.Hash
+	CommitL1BlockNumber   uint64
+	FinalizeTxHash        common.Hash
+	FinalizeL1BlockNumber uint64
+	CodecVersion          uint8       `rlp:"optional"` // batch header version of the commitBatch calldata
+	CommitL1BlockHash     common.Hash `rlp:"optional"`
+	FinalizeL1BlockHash   common.Hash `rlp:"optional"`
+	Enforced              bool        `rlp:"optional"` // committed while the enforced batch mode was enabled
+	FinalizedStateRoot    common.Hash `rlp:"optional"`
+	FinalizedWithdrawRoot common.Hash `rlp:"optional"`
+	BlobVersionedHash     common.Hash `rlp:"optional"` // versioned hash of the blob of batches from version 1
+	BlobDataProof         []byte      `rlp:"optional"` // evaluation point and claim of the blob, from version 3
+}
+
+// PoisonedBatch marks a finalized batch that failed validation against the local chain.
+type PoisonedBatch struct {
+	Reason        string
+	L1BlockNumber uint64 // L1 block of the finalize event
+}
+
+// RevertedBatch records the revert of a committed batch. It is kept after the batch
+// has been committed again, so that the last revert of every batch index can be inspected.
+type RevertedBatch struct {
+	BatchHash     common.Hash // hash of the reverted batch, zero if it was reverted with its parent
+	Reason        string
+	L1BlockNumber uint64 // L1 block of the RevertBatch event
+	L1TxHash      common.Hash
+	CommitTxHash  common.Hash // L1 transaction of the reverted commit, zero if unknown
+}
+
+// EnforcedBatchMode records the last UpdateEnforcedBatchMode event of the ScrollChain contract.
+// While the mode is enabled, batches are committed and finalized at once with commitAndFinalizeBatch.
+type EnforcedBatchMode struct {
+	Enabled                 bool
+	LastCommittedBatchIndex uint64 // last committed batch index after the mode was updated
+	L1BlockNumber           uint64 // L1 block of the UpdateEnforcedBatchMode event
+}
+
+// RollupSyncCheckpoint records a processed range of L1 blocks, allowing the rollup
+// sync service to detect L1 reorgs and to undo the batch updates of reorged ranges.
+type RollupSyncCheckpoint struct {
+	L1BlockNumber          uint64 // last L1 block of the processed range
+	L1BlockHash            common.Hash
+	CommittedBatches       []uint64 // batches committed in the range
+	FinalizedBatches       []uint64 // batches finalized in the range
+	FinalizedL2BlockNumber uint64   // highest finalized L2 block number before the range was processed
+}
+
+// L1EndpointRange records the L1 endpoints that served the rollup events of a processed
+// range of L1 blocks, so that data found to be bad later can be attributed to a provider.
+type L1EndpointRange struct {
+	FromL1BlockNumber uint64
+	ToL1BlockNumber   uint64
+	Endpoints         []string
+}
+
+// RollupSyncRecovery records the progress of a catch-up of the rollup sync service over a
+// long range of L1 blocks, e.g. a recovery of all batches from L1, so that its rates and
+// remaining time can be reported across restarts.
+type RollupSyncRecovery struct {
+	StartL1BlockNumber  uint64 // last processed L1 block when the catch-up started
+	TargetL1BlockNumber uint64 // latest confirmed L1 block of the last fetch round
+	BatchesDerived      uint64 // batches finalized since the catch-up started
+	Elapsed             uint64 // nanoseconds spent processing, excluding the time the node was down
+}
+
+// RollupSyncBatchPointers records the newest committed and finalized batches reported by L1
+// while the rollup sync service catches up, before their events are validated. An L1 block
+// number of 0 marks a pointer that was not found yet.
+type RollupSyncBatchPointers struct {
+	ScannedL1BlockNumber   uint64 // L1 block up to which the events were scanned
+	CommittedBatchIndex    uint64
+	CommittedL1BlockNumber uint64
+	FinalizedBatchIndex    uint64
+	FinalizedBatchHash     common.Hash
+	FinalizedStateRoot     common.Hash
+	FinalizedL1BlockNumber uint64
+	FinalizedL2BlockNumber uint64 // last L2 block of the finalized batch, 0 if its commit was not found
+	CommittedL2BlockNumber uint64 `rlp:"optional"` // last L2 block of the committed batch, 0 if unknown
+}
+
+// RollupBatchGap records a range of batches whose CommitBatch events were not returned by L1,
+// and how far the L1 blocks in which the events must have been emitted were re-queried, so
+// that the recovery resumes across restarts.
+type RollupBatchGap struct {
+	FromBatchIndex    uint64
+	ToBatchIndex      uint64
+	FromL1BlockNumber uint64
+	ToL1BlockNumber   uint64
+	NextL1BlockNumber uint64 // next L1 block to re-query
+}
+
+// MetadataSinkRecord is a batch metadata record that was not delivered to the metadata sink yet.
+type MetadataSinkRecord struct {
+	Kind       string
+	BatchIndex uint64
+	Value      []byte // JSON encoding of the record value
+}
+
+// L1FinalizedBlock records the latest finalized L1 block observed by the rollup sync service
+// and when it was first observed, so that a stalled L1 feed is detected across restarts.
+type L1FinalizedBlock struct {
+	Number     uint64
+	ObservedAt uint64 // unix time in seconds
+}
+
 // WriteRollupEventSyncedL1BlockNumber stores the latest synced L1 block number related to rollup events in the database.
 func WriteRollupEventSyncedL1BlockNumber(db ethdb.KeyValueWriter, l1BlockNumber uint64) {
 	value := big.NewInt(0).SetUint64(l1BlockNumber).Bytes()
@@ -118,6 +234,13 @@ func ReadFinalizedBatchMeta(db ethdb.Reader, batchIndex uint64) *FinalizedBatchM
 	return fbm
 }
 
+// DeleteFinalizedBatchMeta removes the metadata of a finalized batch from the database.
+func DeleteFinalizedBatchMeta(db ethdb.KeyValueWriter, batchIndex uint64) {
+	if err := db.Delete(batchMetaKey(batchIndex)); err != nil {
+		log.Crit("failed to delete finalized batch metadata", "batch index", batchIndex, "err", err)
+	}
+}
+
 // WriteFinalizedL2BlockNumber stores the highest finalized L2 block number in the database.
 func WriteFinalizedL2BlockNumber(db ethdb.KeyValueWriter, l2BlockNumber uint64) {
 	value := big.NewInt(0).SetUint64(l2BlockNumber).Bytes()
@@ -144,3 +267,778 @@ func ReadFinalizedL2BlockNumber(db ethdb.Reader) *uint64 {
 	finalizedL2BlockNumber := number.Uint64()
 	return &finalizedL2BlockNumber
 }
+
+// WriteBatchL1Meta stores the L1 commit and finalize transaction info of a batch in the database.
+func WriteBatchL1Meta(db ethdb.KeyValueWriter, batchIndex uint64, batchL1Meta *BatchL1Meta) {
+	value, err := rlp.EncodeToBytes(batchL1Meta)
+	if err != nil {
+		log.Crit("failed to RLP encode batch L1 metadata", "batch index", batchIndex, "batch L1 meta", batchL1Meta, "err", err)
+	}
+	if err := db.Put(batchL1MetaKey(batchIndex), value); err != nil {
+		log.Crit("failed to store batch L1 metadata", "batch index", batchIndex, "value", value, "err", err)
+	}
+}
+
+// ReadBatchL1Meta fetches the L1 commit and finalize transaction info of a batch from the database.
+func ReadBatchL1Meta(db ethdb.Reader, batchIndex uint64) *BatchL1Meta {
+	data, err := db.Get(batchL1MetaKey(batchIndex))
+	if err != nil && isNotFoundErr(err) {
+		return nil
+	}
+	if err != nil {
+		log.Crit("failed to read batch L1 metadata from database", "batch index", batchIndex, "err", err)
+	}
+
+	meta := new(BatchL1Meta)
+	if err := rlp.Decode(bytes.NewReader(data), meta); err != nil {
+		log.Crit("Invalid BatchL1Meta RLP", "batch index", batchIndex, "data", data, "err", err)
+	}
+	return meta
+}
+
+// DeleteBatchL1Meta removes the L1 commit and finalize transaction info of a batch from the database.
+func DeleteBatchL1Meta(db ethdb.KeyValueWriter, batchIndex uint64) {
+	if err := db.Delete(batchL1MetaKey(batchIndex)); err != nil {
+		log.Crit("failed to delete batch L1 metadata", "batch index", batchIndex, "err", err)
+	}
+}
+
+// WriteBatchEndBlock indexes a committed batch by the number of its last L2 block.
+// Together with the chunk ranges of the batch this allows mapping any L2 block to its containing batch.
+func WriteBatchEndBlock(db ethdb.KeyValueWriter, endBlockNumber uint64, batchIndex uint64) {
+	if err := db.Put(batchEndBlockKey(endBlockNumber), encodeBigEndian(batchIndex)); err != nil {
+		log.Crit("failed to store batch end block index", "end block number", endBlockNumber, "batch index", batchIndex, "err", err)
+	}
+}
+
+// DeleteBatchEndBlock removes the batch index entry for the given last L2 block number of a batch.
+func DeleteBatchEndBlock(db ethdb.KeyValueWriter, endBlockNumber uint64) {
+	if err := db.Delete(batchEndBlockKey(endBlockNumber)); err != nil {
+		log.Crit("failed to delete batch end block index", "end block number", endBlockNumber, "err", err)
+	}
+}
+
+// Kinds of the L1 events indexed by WriteBatchL1Block.
+const (
+	BatchCommittedL1Event byte = 'c'
+	BatchFinalizedL1Event byte = 'f'
+)
+
+// WriteBatchL1Block indexes the commit or finalize event of a batch by the number of its L1 block.
+func WriteBatchL1Block(db ethdb.KeyValueWriter, l1BlockNumber uint64, kind byte, batchIndex uint64) {
+	if err := db.Put(batchL1BlockKey(l1BlockNumber, kind, batchIndex), nil); err != nil {
+		log.Crit("failed to store batch L1 block index", "L1 block number", l1BlockNumber, "kind", string(kind), "batch index", batchIndex, "err", err)
+	}
+}
+
+// DeleteBatchL1Block removes the index entry of the commit or finalize event of a batch.
+func DeleteBatchL1Block(db ethdb.KeyValueWriter, l1BlockNumber uint64, kind byte, batchIndex uint64) {
+	if err := db.Delete(batchL1BlockKey(l1BlockNumber, kind, batchIndex)); err != nil {
+		log.Crit("failed to delete batch L1 block index", "L1 block number", l1BlockNumber, "kind", string(kind), "batch index", batchIndex, "err", err)
+	}
+}
+
+// ReadBatchesByL1Block returns the first L1 block at or after fromL1BlockNumber that committed or
+// finalized a batch, together with the indices of the batches committed and finalized in it.
+// ok is false if no such L1 block is indexed.
+func ReadBatchesByL1Block(db ethdb.Iteratee, fromL1BlockNumber uint64) (l1BlockNumber uint64, committed, finalized []uint64, ok bool) {
+	it := db.NewIterator(batchL1BlockPrefix, encodeBigEndian(fromL1BlockNumber))
+	defer it.Release()
+
+	keyLength := len(batchL1BlockPrefix) + 8 + 1 + 8
+	for it.Next() {
+		key := it.Key()
+		if len(key) != keyLength {
+			continue
+		}
+		number := binary.BigEndian.Uint64(key[len(batchL1BlockPrefix):])
+		if ok && number != l1BlockNumber {
+			break
+		}
+		l1BlockNumber, ok = number, true
+		batchIndex := binary.BigEndian.Uint64(key[len(batchL1BlockPrefix)+9:])
+		switch key[len(batchL1BlockPrefix)+8] {
+		case BatchCommittedL1Event:
+			committed = append(committed, batchIndex)
+		case BatchFinalizedL1Event:
+			finalized = append(finalized, batchIndex)
+		}
+	}
+	return l1BlockNumber, committed, finalized, ok
+}
+
+// ReadBatchIndexByL2BlockNumber returns the index of the committed batch with the lowest last L2 block
+// number greater than or equal to the given block number, or nil if there is no such batch.
+// Callers must check the chunk ranges of the returned batch to confirm that it contains the block.
+func ReadBatchIndexByL2BlockNumber(db ethdb.Iteratee, l2BlockNumber uint64) *uint64 {
+	it := db.NewIterator(batchEndBlockPrefix, encodeBigEndian(l2BlockNumber))
+	defer it.Release()
+
+	keyLength := len(batchEndBlockPrefix) + 8
+	for it.Next() {
+		if len(it.Key()) != keyLength {
+			continue
+		}
+		if len(it.Value()) != 8 {
+			log.Crit("Invalid batch end block index entry", "key", it.Key(), "value", it.Value())
+		}
+		batchIndex := binary.BigEndian.Uint64(it.Value())
+		return &batchIndex
+	}
+	return nil
+}
+
+// batchRangeLogThreshold is the number of batches from which the deletion of a batch range is
+// logged at info level.
+const batchRangeLogThreshold = 1024
+
+// DeleteBatchRange removes all metadata of the batches in the range [fromBatchIndex, toBatchIndex]:
+// chunk ranges, end block index entries, finalized batch metadata, L1 metadata and its L1 block index
+// entries, poisoned batch markers and skipped L1 messages.
+// All per-batch keys are laid out as prefix + big endian batch index, so the range is
+// visited with one iterator per prefix and deleted in batches of ethdb.IdealBatchSize.
+// It returns the number of batches whose chunk ranges were deleted.
+func DeleteBatchRange(db ethdb.KeyValueStore, fromBatchIndex, toBatchIndex uint64) uint64 {
+	if fromBatchIndex > toBatchIndex {
+		return 0
+	}
+	var (
+		batch   = db.NewBatch()
+		start   = time.Now()
+		logged  = start
+		deleted uint64
+		total   = toBatchIndex - fromBatchIndex + 1
+	)
+	flush := func(force bool) {
+		if batch.ValueSize() > ethdb.IdealBatchSize || force {
+			if err := batch.Write(); err != nil {
+				log.Crit("Failed to delete batch range", "from", fromBatchIndex, "to", toBatchIndex, "err", err)
+			}
+			batch.Reset()
+		}
+		if time.Since(logged) > 8*time.Second {
+			log.Info("Deleting batch range", "from", fromBatchIndex, "to", toBatchIndex, "deleted", deleted, "total", total, "elapsed", common.PrettyDuration(time.Since(start)))
+			logged = time.Now()
+		}
+	}
+
+	// chunk ranges also give us the end block index entries
+	iterateBatchRange(db, batchChunkRangesPrefix, fromBatchIndex, toBatchIndex, func(key, value []byte, batchIndex uint64) {
+		var cr []*ChunkBlockRange
+		if err := rlp.DecodeBytes(value, &cr); err != nil {
+			log.Warn("Invalid ChunkBlockRange RLP", "batch index", batchIndex, "err", err)
+		} else if len(cr) > 0 {
+			endBlockKey := batchEndBlockKey(cr[len(cr)-1].EndBlockNumber)
+			// only drop the index entry if it still points to this batch
+			if enc, err := db.Get(endBlockKey); err == nil && len(enc) == 8 && binary.BigEndian.Uint64(enc) == batchIndex {
+				if err := batch.Delete(endBlockKey); err != nil {
+					log.Crit("Failed to delete batch end block index", "batch index", batchIndex, "err", err)
+				}
+			}
+		}
+		if err := batch.Delete(key); err != nil {
+			log.Crit("Failed to delete batch chunk ranges", "batch index", batchIndex, "err", err)
+		}
+		deleted++
+		flush(false)
+	})
+	// skipped message lists also give us the skipped message index entries
+	iterateBatchRange(db, batchSkippedL1MessagesPrefix, fromBatchIndex, toBatchIndex, func(key, value []byte, batchIndex uint64) {
+		var queueIndices []uint64
+		if err := rlp.DecodeBytes(value, &queueIndices); err != nil {
+			log.Warn("Invalid skipped L1 messages RLP", "batch index", batchIndex, "err", err)
+		}
+		for _, queueIndex := range queueIndices {
+			// only drop the index entry if it still points to this batch
+			if enc, err := db.Get(skippedL1MessageKey(queueIndex)); err == nil && len(enc) == 8 && binary.BigEndian.Uint64(enc) == batchIndex {
+				if err := batch.Delete(skippedL1MessageKey(queueIndex)); err != nil {
+					log.Crit("Failed to delete skipped L1 message", "batch index", batchIndex, "queue index", queueIndex, "err", err)
+				}
+			}
+		}
+		if err := batch.Delete(key); err != nil {
+			log.Crit("Failed to delete batch skipped L1 messages", "batch index", batchIndex, "err", err)
+		}
+		flush(false)
+	})
+	// L1 metadata also gives us the L1 block index entries
+	iterateBatchRange(db, batchL1MetaPrefix, fromBatchIndex, toBatchIndex, func(key, value []byte, batchIndex uint64) {
+		var meta BatchL1Meta
+		if err := rlp.DecodeBytes(value, &meta); err != nil {
+			log.Warn("Invalid BatchL1Meta RLP", "batch index", batchIndex, "err", err)
+		}
+		if meta.CommitTxHash != (common.Hash{}) {
+			if err := batch.Delete(batchL1BlockKey(meta.CommitL1BlockNumber, BatchCommittedL1Event, batchIndex)); err != nil {
+				log.Crit("Failed to delete batch L1 block index", "batch index", batchIndex, "err", err)
+			}
+		}
+		if meta.FinalizeTxHash != (common.Hash{}) {
+			if err := batch.Delete(batchL1BlockKey(meta.FinalizeL1BlockNumber, BatchFinalizedL1Event, batchIndex)); err != nil {
+				log.Crit("Failed to delete batch L1 block index", "batch index", batchIndex, "err", err)
+			}
+		}
+		if err := batch.Delete(key); err != nil {
+			log.Crit("Failed to delete batch L1 metadata", "batch index", batchIndex, "err", err)
+		}
+		flush(false)
+	})
+	for _, prefix := range [][]byte{batchMetaPrefix, poisonedBatchPrefix} {
+		iterateBatchRange(db, prefix, fromBatchIndex, toBatchIndex, func(key, _ []byte, batchIndex uint64) {
+			if err := batch.Delete(key); err != nil {
+				log.Crit("Failed to delete batch metadata", "batch index", batchIndex, "err", err)
+			}
+			flush(false)
+		})
+	}
+	flush(true)
+
+	logFn := log.Debug
+	if total >= batchRangeLogThreshold {
+		logFn = log.Info
+	}
+	logFn("Deleted batch range", "from", fromBatchIndex, "to", toBatchIndex, "deleted", deleted, "elapsed", common.PrettyDuration(time.Since(start)))
+	return deleted
+}
+
+// iterateBatchRange calls fn for every key of the form prefix + big endian batch index
+// with a batch index in the range [fromBatchIndex, toBatchIndex].
+func iterateBatchRange(db ethdb.Iteratee, prefix []byte, fromBatchIndex, toBatchIndex uint64, fn func(key, value []byte, batchIndex uint64)) {
+	it := db.NewIterator(prefix, encodeBigEndian(fromBatchIndex))
+	defer it.Release()
+
+	keyLength := len(prefix) + 8
+	for it.Next() {
+		if len(it.Key()) != keyLength {
+			continue
+		}
+		batchIndex := binary.BigEndian.Uint64(it.Key()[len(prefix):])
+		if batchIndex > toBatchIndex {
+			break
+		}
+		// the iterator owns the returned slices, copy the key before handing it out
+		fn(common.CopyBytes(it.Key()), it.Value(), batchIndex)
+	}
+}
+
+// WritePoisonedBatch marks a batch as failed validation.
+func WritePoisonedBatch(db ethdb.KeyValueWriter, batchIndex uint64, poisonedBatch *PoisonedBatch) {
+	value, err := rlp.EncodeToBytes(poisonedBatch)
+	if err != nil {
+		log.Crit("failed to RLP encode poisoned batch", "batch index", batchIndex, "poisoned batch", poisonedBatch, "err", err)
+	}
+	if err := db.Put(poisonedBatchKey(batchIndex), value); err != nil {
+		log.Crit("failed to store poisoned batch", "batch index", batchIndex, "value", value, "err", err)
+	}
+}
+
+// ReadPoisonedBatch fetches the poisoned batch marker of a batch, or nil if the batch is not marked.
+func ReadPoisonedBatch(db ethdb.Reader, batchIndex uint64) *PoisonedBatch {
+	data, err := db.Get(poisonedBatchKey(batchIndex))
+	if err != nil && isNotFoundErr(err) {
+		return nil
+	}
+	if err != nil {
+		log.Crit("failed to read poisoned batch from database", "batch index", batchIndex, "err", err)
+	}
+
+	pb := new(PoisonedBatch)
+	if err := rlp.Decode(bytes.NewReader(data), pb); err != nil {
+		log.Crit("Invalid PoisonedBatch RLP", "batch index", batchIndex, "data", data, "err", err)
+	}
+	return pb
+}
+
+// DeletePoisonedBatch removes the poisoned batch marker of a batch.
+func DeletePoisonedBatch(db ethdb.KeyValueWriter, batchIndex uint64) {
+	if err := db.Delete(poisonedBatchKey(batchIndex)); err != nil {
+		log.Crit("failed to delete poisoned batch", "batch index", batchIndex, "err", err)
+	}
+}
+
+// ReadPoisonedBatchIndices returns the indices of all batches marked as poisoned in ascending order.
+func ReadPoisonedBatchIndices(db ethdb.Iteratee) []uint64 {
+	var indices []uint64
+	iterateBatchRange(db, poisonedBatchPrefix, 0, math.MaxUint64, func(_, _ []byte, batchIndex uint64) {
+		indices = append(indices, batchIndex)
+	})
+	return indices
+}
+
+// WriteRevertedBatch stores the revert record of a batch.
+func WriteRevertedBatch(db ethdb.KeyValueWriter, batchIndex uint64, revertedBatch *RevertedBatch) {
+	value, err := rlp.EncodeToBytes(revertedBatch)
+	if err != nil {
+		log.Crit("failed to RLP encode reverted batch", "batch index", batchIndex, "reverted batch", revertedBatch, "err", err)
+	}
+	if err := db.Put(revertedBatchKey(batchIndex), value); err != nil {
+		log.Crit("failed to store reverted batch", "batch index", batchIndex, "value", value, "err", err)
+	}
+}
+
+// ReadRevertedBatch fetches the last revert record of a batch, or nil if the batch has never been reverted.
+func ReadRevertedBatch(db ethdb.Reader, batchIndex uint64) *RevertedBatch {
+	data, err := db.Get(revertedBatchKey(batchIndex))
+	if err != nil && isNotFoundErr(err) {
+		return nil
+	}
+	if err != nil {
+		log.Crit("failed to read reverted batch from database", "batch index", batchIndex, "err", err)
+	}
+
+	rb := new(RevertedBatch)
+	if err := rlp.Decode(bytes.NewReader(data), rb); err != nil {
+		log.Crit("Invalid RevertedBatch RLP", "batch index", batchIndex, "data", data, "err", err)
+	}
+	return rb
+}
+
+// WriteEnforcedBatchMode stores the enforced batch mode of the ScrollChain contract.
+func WriteEnforcedBatchMode(db ethdb.KeyValueWriter, mode *EnforcedBatchMode) {
+	value, err := rlp.EncodeToBytes(mode)
+	if err != nil {
+		log.Crit("failed to RLP encode enforced batch mode", "mode", mode, "err", err)
+	}
+	if err := db.Put(enforcedBatchModeKey, value); err != nil {
+		log.Crit("failed to store enforced batch mode", "value", value, "err", err)
+	}
+}
+
+// ReadEnforcedBatchMode fetches the enforced batch mode of the ScrollChain contract,
+// or nil if the mode has never been updated.
+func ReadEnforcedBatchMode(db ethdb.Reader) *EnforcedBatchMode {
+	data, err := db.Get(enforcedBatchModeKey)
+	if err != nil && isNotFoundErr(err) {
+		return nil
+	}
+	if err != nil {
+		log.Crit("failed to read enforced batch mode from database", "err", err)
+	}
+
+	mode := new(EnforcedBatchMode)
+	if err := rlp.Decode(bytes.NewReader(data), mode); err != nil {
+		log.Crit("Invalid EnforcedBatchMode RLP", "data", data, "err", err)
+	}
+	return mode
+}
+
+// DeleteEnforcedBatchMode removes the enforced batch mode of the ScrollChain contract.
+func DeleteEnforcedBatchMode(db ethdb.KeyValueWriter) {
+	if err := db.Delete(enforcedBatchModeKey); err != nil {
+		log.Crit("failed to delete enforced batch mode", "err", err)
+	}
+}
+
+// ReadCommittedBatchIndices returns the indices of all batches with chunk ranges at or above
+// fromBatchIndex in ascending order.
+func ReadCommittedBatchIndices(db ethdb.Iteratee, fromBatchIndex uint64) []uint64 {
+	var indices []uint64
+	iterateBatchRange(db, batchChunkRangesPrefix, fromBatchIndex, math.MaxUint64, func(_, _ []byte, batchIndex uint64) {
+		indices = append(indices, batchIndex)
+	})
+	return indices
+}
+
+// WriteRollupSyncCheckpoint stores the checkpoint of a processed L1 block range.
+func WriteRollupSyncCheckpoint(db ethdb.KeyValueWriter, checkpoint *RollupSyncCheckpoint) {
+	value, err := rlp.EncodeToBytes(checkpoint)
+	if err != nil {
+		log.Crit("failed to RLP encode rollup sync checkpoint", "L1 block number", checkpoint.L1BlockNumber, "err", err)
+	}
+	if err := db.Put(rollupSyncCheckpointKey(checkpoint.L1BlockNumber), value); err != nil {
+		log.Crit("failed to store rollup sync checkpoint", "L1 block number", checkpoint.L1BlockNumber, "value", value, "err", err)
+	}
+}
+
+// DeleteRollupSyncCheckpoint removes the checkpoint at the given L1 block number.
+func DeleteRollupSyncCheckpoint(db ethdb.KeyValueWriter, l1BlockNumber uint64) {
+	if err := db.Delete(rollupSyncCheckpointKey(l1BlockNumber)); err != nil {
+		log.Crit("failed to delete rollup sync checkpoint", "L1 block number", l1BlockNumber, "err", err)
+	}
+}
+
+// ReadRollupSyncCheckpoints returns all stored rollup sync checkpoints ordered by ascending L1 block number.
+func ReadRollupSyncCheckpoints(db ethdb.Iteratee) []*RollupSyncCheckpoint {
+	var checkpoints []*RollupSyncCheckpoint
+	iterateBatchRange(db, rollupSyncCheckpointPrefix, 0, math.MaxUint64, func(_, value []byte, l1BlockNumber uint64) {
+		checkpoint := new(RollupSyncCheckpoint)
+		if err := rlp.DecodeBytes(value, checkpoint); err != nil {
+			log.Crit("Invalid RollupSyncCheckpoint RLP", "L1 block number", l1BlockNumber, "data", value, "err", err)
+		}
+		checkpoints = append(checkpoints, checkpoint)
+	})
+	return checkpoints
+}
+
+// WriteL1EndpointRange stores the L1 endpoints that served a processed L1 block range.
+// A range ending at the same L1 block, e.g. after a reset, is overwritten.
+func WriteL1EndpointRange(db ethdb.KeyValueWriter, endpointRange *L1EndpointRange) {
+	value, err := rlp.EncodeToBytes(endpointRange)
+	if err != nil {
+		log.Crit("failed to RLP encode L1 endpoint range", "L1 block number", endpointRange.ToL1BlockNumber, "err", err)
+	}
+	if err := db.Put(l1EndpointRangeKey(endpointRange.ToL1BlockNumber), value); err != nil {
+		log.Crit("failed to store L1 endpoint range", "L1 block number", endpointRange.ToL1BlockNumber, "value", value, "err", err)
+	}
+}
+
+// ReadL1EndpointRanges returns the stored L1 endpoint ranges overlapping the L1 blocks
+// [fromL1BlockNumber, toL1BlockNumber], ordered by ascending last L1 block number.
+func ReadL1EndpointRanges(db ethdb.Iteratee, fromL1BlockNumber, toL1BlockNumber uint64) []*L1EndpointRange {
+	var ranges []*L1EndpointRange
+	iterateBatchRange(db, l1EndpointRangePrefix, fromL1BlockNumber, math.MaxUint64, func(_, value []byte, l1BlockNumber uint64) {
+		endpointRange := new(L1EndpointRange)
+		if err := rlp.DecodeBytes(value, endpointRange); err != nil {
+			log.Crit("Invalid L1EndpointRange RLP", "L1 block number", l1BlockNumber, "data", value, "err", err)
+		}
+		// note: ranges are keyed by their last block and may overlap after a reset,
+		// so all ranges ending after the queried range are checked as well.
+		if endpointRange.FromL1BlockNumber <= toL1BlockNumber {
+			ranges = append(ranges, endpointRange)
+		}
+	})
+	return ranges
+}
+
+// WriteRollupSyncRecovery stores the progress of a catch-up of the rollup sync service.
+func WriteRollupSyncRecovery(db ethdb.KeyValueWriter, recovery *RollupSyncRecovery) {
+	value, err := rlp.EncodeToBytes(recovery)
+	if err != nil {
+		log.Crit("failed to RLP encode rollup sync recovery", "recovery", recovery, "err", err)
+	}
+	if err := db.Put(rollupSyncRecoveryKey, value); err != nil {
+		log.Crit("failed to store rollup sync recovery", "value", value, "err", err)
+	}
+}
+
+// ReadRollupSyncRecovery fetches the progress of a catch-up of the rollup sync service,
+// or nil if no catch-up is running.
+func ReadRollupSyncRecovery(db ethdb.Reader) *RollupSyncRecovery {
+	data, err := db.Get(rollupSyncRecoveryKey)
+	if err != nil && isNotFoundErr(err) {
+		return nil
+	}
+	if err != nil {
+		log.Crit("failed to read rollup sync recovery from database", "err", err)
+	}
+
+	recovery := new(RollupSyncRecovery)
+	if err := rlp.Decode(bytes.NewReader(data), recovery); err != nil {
+		log.Crit("Invalid RollupSyncRecovery RLP", "data", data, "err", err)
+	}
+	return recovery
+}
+
+// DeleteRollupSyncRecovery removes the progress of a finished catch-up of the rollup sync service.
+func DeleteRollupSyncRecovery(db ethdb.KeyValueWriter) {
+	if err := db.Delete(rollupSyncRecoveryKey); err != nil {
+		log.Crit("failed to delete rollup sync recovery", "err", err)
+	}
+}
+
+// WriteRollupSyncBatchPointers stores the batches reported by L1 during a catch-up of the rollup sync service.
+func WriteRollupSyncBatchPointers(db ethdb.KeyValueWriter, pointers *RollupSyncBatchPointers) {
+	value, err := rlp.EncodeToBytes(pointers)
+	if err != nil {
+		log.Crit("failed to RLP encode rollup sync batch pointers", "pointers", pointers, "err", err)
+	}
+	if err := db.Put(rollupSyncBatchPointersKey, value); err != nil {
+		log.Crit("failed to store rollup sync batch pointers", "value", value, "err", err)
+	}
+}
+
+// ReadRollupSyncBatchPointers fetches the batches reported by L1 during a catch-up of the
+// rollup sync service, or nil if none are stored.
+func ReadRollupSyncBatchPointers(db ethdb.Reader) *RollupSyncBatchPointers {
+	data, err := db.Get(rollupSyncBatchPointersKey)
+	if err != nil && isNotFoundErr(err) {
+		return nil
+	}
+	if err != nil {
+		log.Crit("failed to read rollup sync batch pointers from database", "err", err)
+	}
+
+	pointers := new(RollupSyncBatchPointers)
+	if err := rlp.Decode(bytes.NewReader(data), pointers); err != nil {
+		log.Crit("Invalid RollupSyncBatchPointers RLP", "data", data, "err", err)
+	}
+	return pointers
+}
+
+// DeleteRollupSyncBatchPointers removes the batches reported by L1 once the rollup sync service validated them.
+func DeleteRollupSyncBatchPointers(db ethdb.KeyValueWriter) {
+	if err := db.Delete(rollupSyncBatchPointersKey); err != nil {
+		log.Crit("failed to delete rollup sync batch pointers", "err", err)
+	}
+}
+
+// WriteRollupBatchGaps stores the batch gaps that the rollup sync service has not recovered yet.
+func WriteRollupBatchGaps(db ethdb.KeyValueWriter, gaps []*RollupBatchGap) {
+	if len(gaps) == 0 {
+		if err := db.Delete(rollupBatchGapsKey); err != nil {
+			log.Crit("failed to delete rollup batch gaps", "err", err)
+		}
+		return
+	}
+	value, err := rlp.EncodeToBytes(gaps)
+	if err != nil {
+		log.Crit("failed to RLP encode rollup batch gaps", "gaps", len(gaps), "err", err)
+	}
+	if err := db.Put(rollupBatchGapsKey, value); err != nil {
+		log.Crit("failed to store rollup batch gaps", "value", value, "err", err)
+	}
+}
+
+// ReadRollupBatchGaps fetches the batch gaps that the rollup sync service has not recovered yet.
+func ReadRollupBatchGaps(db ethdb.Reader) []*RollupBatchGap {
+	data, err := db.Get(rollupBatchGapsKey)
+	if err != nil && isNotFoundErr(err) {
+		return nil
+	}
+	if err != nil {
+		log.Crit("failed to read rollup batch gaps from database", "err", err)
+	}
+
+	var gaps []*RollupBatchGap
+	if err := rlp.Decode(bytes.NewReader(data), &gaps); err != nil {
+		log.Crit("Invalid RollupBatchGaps RLP", "data", data, "err", err)
+	}
+	return gaps
+}
+
+// WriteL1FinalizedBlock stores the latest finalized L1 block observed by the rollup sync service.
+func WriteL1FinalizedBlock(db ethdb.KeyValueWriter, finalized *L1FinalizedBlock) {
+	value, err := rlp.EncodeToBytes(finalized)
+	if err != nil {
+		log.Crit("failed to RLP encode L1 finalized block", "finalized", finalized, "err", err)
+	}
+	if err := db.Put(l1FinalizedBlockKey, value); err != nil {
+		log.Crit("failed to store L1 finalized block", "value", value, "err", err)
+	}
+}
+
+// ReadL1FinalizedBlock fetches the latest finalized L1 block observed by the rollup sync
+// service, or nil if none was observed yet.
+func ReadL1FinalizedBlock(db ethdb.Reader) *L1FinalizedBlock {
+	data, err := db.Get(l1FinalizedBlockKey)
+	if err != nil && isNotFoundErr(err) {
+		return nil
+	}
+	if err != nil {
+		log.Crit("failed to read L1 finalized block from database", "err", err)
+	}
+
+	finalized := new(L1FinalizedBlock)
+	if err := rlp.Decode(bytes.NewReader(data), finalized); err != nil {
+		log.Crit("Invalid L1FinalizedBlock RLP", "data", data, "err", err)
+	}
+	return finalized
+}
+
+// WriteRollupScrollChainAddress stores the address of the ScrollChain contract whose events the rollup event store was built from.
+func WriteRollupScrollChainAddress(db ethdb.KeyValueWriter, address common.Address) {
+	if err := db.Put(rollupScrollChainAddressKey, address.Bytes()); err != nil {
+		log.Crit("failed to store rollup ScrollChain address", "address", address, "err", err)
+	}
+}
+
+// ReadRollupScrollChainAddress fetches the address of the ScrollChain contract whose events the
+// rollup event store was built from, or nil if it was not recorded yet.
+func ReadRollupScrollChainAddress(db ethdb.Reader) *common.Address {
+	data, err := db.Get(rollupScrollChainAddressKey)
+	if err != nil && isNotFoundErr(err) {
+		return nil
+	}
+	if err != nil {
+		log.Crit("failed to read rollup ScrollChain address from database", "err", err)
+	}
+	if len(data) != common.AddressLength {
+		log.Crit("Invalid rollup ScrollChain address in database", "data", data)
+	}
+	address := common.BytesToAddress(data)
+	return &address
+}
+
+// WriteMetadataSinkRecord stores a batch metadata record to be delivered to the metadata sink.
+func WriteMetadataSinkRecord(db ethdb.KeyValueWriter, sequence uint64, record *MetadataSinkRecord) {
+	value, err := rlp.EncodeToBytes(record)
+	if err != nil {
+		log.Crit("failed to RLP encode metadata sink record", "sequence", sequence, "err", err)
+	}
+	if err := db.Put(metadataSinkRecordKey(sequence), value); err != nil {
+		log.Crit("failed to store metadata sink record", "sequence", sequence, "value", value, "err", err)
+	}
+}
+
+// ReadMetadataSinkRecord fetches the batch metadata record with the given sequence number,
+// or nil if it was delivered or never stored.
+func ReadMetadataSinkRecord(db ethdb.Reader, sequence uint64) *MetadataSinkRecord {
+	data, err := db.Get(metadataSinkRecordKey(sequence))
+	if err != nil && isNotFoundErr(err) {
+		return nil
+	}
+	if err != nil {
+		log.Crit("failed to read metadata sink record from database", "sequence", sequence, "err", err)
+	}
+
+	record := new(MetadataSinkRecord)
+	if err := rlp.Decode(bytes.NewReader(data), record); err != nil {
+		log.Crit("Invalid MetadataSinkRecord RLP", "sequence", sequence, "data", data, "err", err)
+	}
+	return record
+}
+
+// DeleteMetadataSinkRecord removes a batch metadata record once it was delivered to the metadata sink.
+func DeleteMetadataSinkRecord(db ethdb.KeyValueWriter, sequence uint64) {
+	if err := db.Delete(metadataSinkRecordKey(sequence)); err != nil {
+		log.Crit("failed to delete metadata sink record", "sequence", sequence, "err", err)
+	}
+}
+
+// WriteMetadataSinkCursor stores the sequence number of the next batch metadata record to
+// deliver to the metadata sink.
+func WriteMetadataSinkCursor(db ethdb.KeyValueWriter, sequence uint64) {
+	value := big.NewInt(0).SetUint64(sequence).Bytes()
+	if err := db.Put(metadataSinkCursorKey, value); err != nil {
+		log.Crit("failed to store metadata sink cursor", "sequence", sequence, "value", value, "err", err)
+	}
+}
+
+// ReadMetadataSinkCursor fetches the sequence number of the next batch metadata record to
+// deliver to the metadata sink, 0 if no record was delivered yet.
+func ReadMetadataSinkCursor(db ethdb.Reader) uint64 {
+	data, err := db.Get(metadataSinkCursorKey)
+	if err != nil && isNotFoundErr(err) {
+		return 0
+	}
+	if err != nil {
+		log.Crit("failed to read metadata sink cursor from database", "err", err)
+	}
+
+	number := new(big.Int).SetBytes(data)
+	if !number.IsUint64() {
+		log.Crit("unexpected metadata sink cursor in database", "number", number)
+	}
+	return number.Uint64()
+}
+
+// DeleteRollupEventStore removes all data of the rollup event store from the key-value store:
+// the batch metadata, the synced L1 block and the recorded ScrollChain address, so that the
+// rollup events are synced again from the L1 deployment block. It returns the number of
+// deleted keys. Batches moved into the rollup ancient store are not affected.
+//
+// The keys are deleted by their known prefixes instead of the common "R-" prefix, which
+// the 32 byte hashes of trie nodes may share.
+func DeleteRollupEventStore(db ethdb.KeyValueStore) int {
+	var (
+		batch   = db.NewBatch()
+		deleted int
+	)
+	flush := func() {
+		if batch.ValueSize() > ethdb.IdealBatchSize {
+			if err := batch.Write(); err != nil {
+				log.Crit("Failed to delete rollup event store", "err", err)
+			}
+			batch.Reset()
+		}
+	}
+	for _, key := range rollupEventStoreKeys {
+		has, err := db.Has(key)
+		if err != nil {
+			log.Crit("Failed to read rollup event store", "err", err)
+		}
+		if !has {
+			continue
+		}
+		if err := batch.Delete(key); err != nil {
+			log.Crit("Failed to delete rollup event store", "err", err)
+		}
+		deleted++
+	}
+	for _, prefix := range rollupEventStorePrefixes {
+		it := db.NewIterator(prefix, nil)
+		for it.Next() {
+			if len(it.Key()) == common.HashLength {
+				continue
+			}
+			if err := batch.Delete(it.Key()); err != nil {
+				log.Crit("Failed to delete rollup event store", "err", err)
+			}
+			deleted++
+			flush()
+		}
+		err := it.Error()
+		it.Release()
+		if err != nil {
+			log.Crit("Failed to iterate rollup event store", "err", err)
+		}
+	}
+	if err := batch.Write(); err != nil {
+		log.Crit("Failed to delete rollup event store", "err", err)
+	}
+	return deleted
+}
+
+// SkippedL1Message is an L1 message that a batch skipped instead of including it.
+type SkippedL1Message struct {
+	QueueIndex uint64
+	BatchIndex uint64
+}
+
+// WriteBatchSkippedL1Messages stores the queue indices of the L1 messages that a batch skipped,
+// and indexes them by queue index.
+func WriteBatchSkippedL1Messages(db ethdb.KeyValueWriter, batchIndex uint64, queueIndices []uint64) {
+	value, err := rlp.EncodeToBytes(queueIndices)
+	if err != nil {
+		log.Crit("failed to RLP encode skipped L1 messages", "batch index", batchIndex, "err", err)
+	}
+	if err := db.Put(batchSkippedL1MessagesKey(batchIndex), value); err != nil {
+		log.Crit("failed to store batch skipped L1 messages", "batch index", batchIndex, "err", err)
+	}
+	for _, queueIndex := range queueIndices {
+		if err := db.Put(skippedL1MessageKey(queueIndex), encodeBigEndian(batchIndex)); err != nil {
+			log.Crit("failed to store skipped L1 message", "batch index", batchIndex, "queue index", queueIndex, "err", err)
+		}
+	}
+}
+
+// ReadBatchSkippedL1Messages fetches the queue indices of the L1 messages that a batch skipped,
+// nil if the batch skipped none.
+func ReadBatchSkippedL1Messages(db ethdb.Reader, batchIndex uint64) []uint64 {
+	data, err := db.Get(batchSkippedL1MessagesKey(batchIndex))
+	if err != nil && isNotFoundErr(err) {
+		return nil
+	}
+	if err != nil {
+		log.Crit("failed to read batch skipped L1 messages from database", "batch index", batchIndex, "err", err)
+	}
+
+	var queueIndices []uint64
+	if err := rlp.DecodeBytes(data, &queueIndices); err != nil {
+		log.Crit("Invalid skipped L1 messages RLP", "batch index", batchIndex, "data", data, "err", err)
+	}
+	return queueIndices
+}
+
+// ReadSkippedL1Messages fetches up to maxCount skipped L1 messages with queue indices in the
+// range [fromQueueIndex, toQueueIndex], in ascending order.
+func ReadSkippedL1Messages(db ethdb.Iteratee, fromQueueIndex, toQueueIndex uint64, maxCount int) []SkippedL1Message {
+	it := db.NewIterator(skippedL1MessagePrefix, encodeBigEndian(fromQueueIndex))
+	defer it.Release()
+
+	var messages []SkippedL1Message
+	keyLength := len(skippedL1MessagePrefix) + 8
+	for len(messages) < maxCount && it.Next() {
+		if len(it.Key()) != keyLength {
+			continue
+		}
+		queueIndex := binary.BigEndian.Uint64(it.Key()[len(skippedL1MessagePrefix):])
+		if queueIndex > toQueueIndex {
+			break
+		}
+		if len(it.Value()) != 8 {
+			log.Crit("Invalid skipped L1 message entry", "key", it.Key(), "value", it.Value())
+		}
+		messages = append(messages, SkippedL1Message{QueueIndex: queueIndex, BatchIndex: binary.BigEndian.Uint64(it.Value())})
+	}
+	return messages
+}
@@ -1,9 +1,12 @@
 package rawdb
 
 import (
+	"math"
+	"reflect"
 	"testing"
 
 	"github.com/scroll-tech/go-ethereum/common"
+	"github.com/scroll-tech/go-ethereum/rlp"
 )
 
 func TestWriteRollupEventSyncedL1BlockNumber(t *testing.T) {
@@ -184,3 +187,475 @@ func TestBatchChunkRanges(t *testing.T) {
 	// delete non-existing value: ensure the delete operation handles non-existing values without errors.
 	DeleteBatchChunkRanges(db, uint64(len(chunks)+1))
 }
+
+func TestBatchL1Meta(t *testing.T) {
+	db := NewMemoryDatabase()
+
+	// read non-existing value
+	if got := ReadBatchL1Meta(db, 1); got != nil {
+		t.Fatal("Expected nil for non-existing value", "got", got)
+	}
+
+	meta := &BatchL1Meta{
+		CommitTxHash:        common.BytesToHash([]byte("commitTx")),
+		CommitL1BlockNumber: 100,
+		CommitL1BlockHash:   common.BytesToHash([]byte("commitBlock")),
+	}
+	WriteBatchL1Meta(db, 1, meta)
+	if got := ReadBatchL1Meta(db, 1); got == nil || !reflect.DeepEqual(got, meta) {
+		t.Fatal("Mismatch in batch L1 meta", "expected", meta, "got", got)
+	}
+
+	// over-write with finalize info
+	meta.FinalizeTxHash = common.BytesToHash([]byte("finalizeTx"))
+	meta.FinalizeL1BlockNumber = 200
+	meta.FinalizeL1BlockHash = common.BytesToHash([]byte("finalizeBlock"))
+	meta.FinalizedStateRoot = common.BytesToHash([]byte("stateRoot"))
+	meta.FinalizedWithdrawRoot = common.BytesToHash([]byte("withdrawRoot"))
+	meta.BlobVersionedHash = common.BytesToHash([]byte("blob"))
+	meta.BlobDataProof = []byte("blob data proof")
+	WriteBatchL1Meta(db, 1, meta)
+	if got := ReadBatchL1Meta(db, 1); got == nil || !reflect.DeepEqual(got, meta) {
+		t.Fatal("Mismatch in batch L1 meta after over-write", "expected", meta, "got", got)
+	}
+
+	// entries stored before the block hashes were recorded are still readable
+	legacy, err := rlp.EncodeToBytes([]interface{}{meta.CommitTxHash, meta.CommitL1BlockNumber, meta.FinalizeTxHash, meta.FinalizeL1BlockNumber})
+	if err != nil {
+		t.Fatal("Failed to encode legacy batch L1 meta", "err", err)
+	}
+	if err := db.Put(batchL1MetaKey(2), legacy); err != nil {
+		t.Fatal("Failed to store legacy batch L1 meta", "err", err)
+	}
+	if got := ReadBatchL1Meta(db, 2); got == nil || got.FinalizeL1BlockNumber != 200 || got.CommitL1BlockHash != (common.Hash{}) {
+		t.Fatal("Mismatch in legacy batch L1 meta", "got", got)
+	}
+
+	DeleteBatchL1Meta(db, 1)
+	if got := ReadBatchL1Meta(db, 1); got != nil {
+		t.Fatal("Batch L1 meta was not deleted", "got", got)
+	}
+}
+
+func TestBatchEndBlock(t *testing.T) {
+	db := NewMemoryDatabase()
+
+	// read non-existing value
+	if got := ReadBatchIndexByL2BlockNumber(db, 1); got != nil {
+		t.Fatal("Expected nil for non-existing value", "got", *got)
+	}
+
+	WriteBatchEndBlock(db, 0, 0)
+	WriteBatchEndBlock(db, 10, 1)
+	WriteBatchEndBlock(db, 25, 2)
+
+	tests := []struct {
+		block uint64
+		batch uint64
+	}{{0, 0}, {1, 1}, {10, 1}, {11, 2}, {25, 2}}
+	for _, tt := range tests {
+		got := ReadBatchIndexByL2BlockNumber(db, tt.block)
+		if got == nil || *got != tt.batch {
+			t.Fatal("Batch index mismatch", "block", tt.block, "expected", tt.batch, "got", got)
+		}
+	}
+	if got := ReadBatchIndexByL2BlockNumber(db, 26); got != nil {
+		t.Fatal("Expected nil for block beyond last batch", "got", *got)
+	}
+
+	// delete: revert batch
+	DeleteBatchEndBlock(db, 25)
+	if got := ReadBatchIndexByL2BlockNumber(db, 11); got != nil {
+		t.Fatal("Expected nil after deleting batch", "got", *got)
+	}
+}
+
+func TestBatchL1Block(t *testing.T) {
+	db := NewMemoryDatabase()
+	if _, _, _, ok := ReadBatchesByL1Block(db, 0); ok {
+		t.Fatal("Unexpected batches in empty database")
+	}
+
+	WriteBatchL1Block(db, 100, BatchCommittedL1Event, 1)
+	WriteBatchL1Block(db, 100, BatchCommittedL1Event, 2)
+	WriteBatchL1Block(db, 105, BatchFinalizedL1Event, 1)
+	WriteBatchL1Block(db, 105, BatchCommittedL1Event, 3)
+	WriteBatchL1Block(db, 256, BatchFinalizedL1Event, 2)
+
+	number, committed, finalized, ok := ReadBatchesByL1Block(db, 100)
+	if !ok || number != 100 || len(committed) != 2 || committed[1] != 2 || len(finalized) != 0 {
+		t.Fatal("Batches mismatch", "number", number, "committed", committed, "finalized", finalized)
+	}
+	number, committed, finalized, ok = ReadBatchesByL1Block(db, 101)
+	if !ok || number != 105 || len(committed) != 1 || committed[0] != 3 || len(finalized) != 1 || finalized[0] != 1 {
+		t.Fatal("Batches mismatch", "number", number, "committed", committed, "finalized", finalized)
+	}
+	number, _, finalized, ok = ReadBatchesByL1Block(db, 106)
+	if !ok || number != 256 || len(finalized) != 1 || finalized[0] != 2 {
+		t.Fatal("Batches mismatch", "number", number, "finalized", finalized)
+	}
+
+	DeleteBatchL1Block(db, 256, BatchFinalizedL1Event, 2)
+	if number, _, _, ok := ReadBatchesByL1Block(db, 106); ok {
+		t.Fatal("Unexpected batches after deletion", "number", number)
+	}
+}
+
+func TestDeleteBatchRange(t *testing.T) {
+	db := NewMemoryDatabase()
+
+	for i := uint64(0); i < 10; i++ {
+		WriteBatchChunkRanges(db, i, []*ChunkBlockRange{{StartBlockNumber: i*10 + 1, EndBlockNumber: i*10 + 10}})
+		WriteBatchEndBlock(db, i*10+10, i)
+		WriteBatchL1Meta(db, i, &BatchL1Meta{CommitTxHash: common.Hash{1}, CommitL1BlockNumber: i})
+		WriteBatchL1Block(db, i, BatchCommittedL1Event, i)
+		WriteFinalizedBatchMeta(db, i, &FinalizedBatchMeta{TotalL1MessagePopped: i})
+	}
+
+	if deleted := DeleteBatchRange(db, 3, 6); deleted != 4 {
+		t.Fatal("Unexpected number of deleted batches", "expected", 4, "got", deleted)
+	}
+	for i := uint64(0); i < 10; i++ {
+		removed := i >= 3 && i <= 6
+		if got := ReadBatchChunkRanges(db, i); (got == nil) != removed {
+			t.Fatal("Unexpected batch chunk ranges", "batch index", i, "got", got)
+		}
+		if got := ReadBatchL1Meta(db, i); (got == nil) != removed {
+			t.Fatal("Unexpected batch L1 meta", "batch index", i, "got", got)
+		}
+		if got := ReadFinalizedBatchMeta(db, i); (got == nil) != removed {
+			t.Fatal("Unexpected finalized batch meta", "batch index", i, "got", got)
+		}
+	}
+	if number, committed, _, ok := ReadBatchesByL1Block(db, 3); !ok || number != 7 || committed[0] != 7 {
+		t.Fatal("Batch L1 block index not deleted", "number", number, "committed", committed)
+	}
+	// blocks of deleted batches now map to the next remaining batch
+	if got := ReadBatchIndexByL2BlockNumber(db, 35); got == nil || *got != 7 {
+		t.Fatal("Batch index mismatch after range deletion", "expected", 7, "got", got)
+	}
+
+	// empty and inverted ranges are no-ops
+	if deleted := DeleteBatchRange(db, 3, 6); deleted != 0 {
+		t.Fatal("Expected no deletions for already deleted range", "got", deleted)
+	}
+	if deleted := DeleteBatchRange(db, 9, 8); deleted != 0 {
+		t.Fatal("Expected no deletions for inverted range", "got", deleted)
+	}
+}
+
+func TestSkippedL1Messages(t *testing.T) {
+	db := NewMemoryDatabase()
+
+	if got := ReadBatchSkippedL1Messages(db, 1); got != nil {
+		t.Fatal("Expected nil for non-existing value", "got", got)
+	}
+	WriteBatchSkippedL1Messages(db, 1, []uint64{3, 5})
+	WriteBatchSkippedL1Messages(db, 2, []uint64{8})
+	if got := ReadBatchSkippedL1Messages(db, 1); !reflect.DeepEqual(got, []uint64{3, 5}) {
+		t.Fatal("Mismatch in skipped L1 messages", "got", got)
+	}
+
+	expected := []SkippedL1Message{{QueueIndex: 3, BatchIndex: 1}, {QueueIndex: 5, BatchIndex: 1}, {QueueIndex: 8, BatchIndex: 2}}
+	if got := ReadSkippedL1Messages(db, 0, 100, 10); !reflect.DeepEqual(got, expected) {
+		t.Fatal("Mismatch in skipped L1 messages", "expected", expected, "got", got)
+	}
+	if got := ReadSkippedL1Messages(db, 4, 7, 10); !reflect.DeepEqual(got, expected[1:2]) {
+		t.Fatal("Mismatch in skipped L1 messages in range", "got", got)
+	}
+	if got := ReadSkippedL1Messages(db, 0, 100, 1); !reflect.DeepEqual(got, expected[:1]) {
+		t.Fatal("Mismatch in limited skipped L1 messages", "got", got)
+	}
+
+	// deleting a batch removes its skipped messages
+	DeleteBatchRange(db, 2, 2)
+	if got := ReadSkippedL1Messages(db, 0, 100, 10); !reflect.DeepEqual(got, expected[:2]) {
+		t.Fatal("Mismatch in skipped L1 messages after deletion", "got", got)
+	}
+	if got := ReadBatchSkippedL1Messages(db, 2); got != nil {
+		t.Fatal("Batch skipped L1 messages were not deleted", "got", got)
+	}
+}
+
+func TestPoisonedBatch(t *testing.T) {
+	db := NewMemoryDatabase()
+
+	if got := ReadPoisonedBatch(db, 1); got != nil {
+		t.Fatal("Expected nil for non-existing value", "got", got)
+	}
+	if got := ReadPoisonedBatchIndices(db); len(got) != 0 {
+		t.Fatal("Expected no poisoned batches", "got", got)
+	}
+
+	pb := &PoisonedBatch{Reason: "state root mismatch", L1BlockNumber: 100}
+	WritePoisonedBatch(db, 3, pb)
+	WritePoisonedBatch(db, 1, pb)
+	if got := ReadPoisonedBatch(db, 3); got == nil || *got != *pb {
+		t.Fatal("Mismatch in poisoned batch", "expected", pb, "got", got)
+	}
+	if got := ReadPoisonedBatchIndices(db); len(got) != 2 || got[0] != 1 || got[1] != 3 {
+		t.Fatal("Mismatch in poisoned batch indices", "got", got)
+	}
+
+	DeletePoisonedBatch(db, 1)
+	if got := ReadPoisonedBatch(db, 1); got != nil {
+		t.Fatal("Poisoned batch was not deleted", "got", got)
+	}
+}
+
+func TestRevertedBatch(t *testing.T) {
+	db := NewMemoryDatabase()
+
+	if got := ReadRevertedBatch(db, 1); got != nil {
+		t.Fatal("Expected nil for non-existing value", "got", got)
+	}
+
+	rb := &RevertedBatch{BatchHash: common.HexToHash("0x01"), Reason: "RevertBatch event", L1BlockNumber: 100, L1TxHash: common.HexToHash("0x02"), CommitTxHash: common.HexToHash("0x03")}
+	WriteRevertedBatch(db, 1, rb)
+	if got := ReadRevertedBatch(db, 1); got == nil || *got != *rb {
+		t.Fatal("Mismatch in reverted batch", "expected", rb, "got", got)
+	}
+
+	// revert records are not batch metadata
+	WriteBatchChunkRanges(db, 1, []*ChunkBlockRange{{StartBlockNumber: 1, EndBlockNumber: 2}})
+	DeleteBatchRange(db, 0, 10)
+	if got := ReadRevertedBatch(db, 1); got == nil {
+		t.Fatal("Reverted batch was deleted with the batch range")
+	}
+}
+
+func TestReadCommittedBatchIndices(t *testing.T) {
+	db := NewMemoryDatabase()
+
+	for _, batchIndex := range []uint64{5, 2, 7} {
+		WriteBatchChunkRanges(db, batchIndex, []*ChunkBlockRange{{StartBlockNumber: batchIndex, EndBlockNumber: batchIndex}})
+	}
+	if got := ReadCommittedBatchIndices(db, 0); !reflect.DeepEqual(got, []uint64{2, 5, 7}) {
+		t.Fatal("Mismatch in committed batch indices", "got", got)
+	}
+	if got := ReadCommittedBatchIndices(db, 5); !reflect.DeepEqual(got, []uint64{5, 7}) {
+		t.Fatal("Mismatch in committed batch indices", "got", got)
+	}
+	if got := ReadCommittedBatchIndices(db, 8); len(got) != 0 {
+		t.Fatal("Expected no committed batches", "got", got)
+	}
+}
+
+func TestEnforcedBatchMode(t *testing.T) {
+	db := NewMemoryDatabase()
+
+	if got := ReadEnforcedBatchMode(db); got != nil {
+		t.Fatal("Expected nil for non-existing value", "got", got)
+	}
+
+	mode := &EnforcedBatchMode{Enabled: true, LastCommittedBatchIndex: 10, L1BlockNumber: 100}
+	WriteEnforcedBatchMode(db, mode)
+	if got := ReadEnforcedBatchMode(db); got == nil || *got != *mode {
+		t.Fatal("Mismatch in enforced batch mode", "expected", mode, "got", got)
+	}
+
+	DeleteEnforcedBatchMode(db)
+	if got := ReadEnforcedBatchMode(db); got != nil {
+		t.Fatal("Enforced batch mode was not deleted", "got", got)
+	}
+}
+
+func TestRollupSyncRecovery(t *testing.T) {
+	db := NewMemoryDatabase()
+
+	if got := ReadRollupSyncRecovery(db); got != nil {
+		t.Fatal("Expected nil for non-existing value", "got", got)
+	}
+
+	recovery := &RollupSyncRecovery{StartL1BlockNumber: 100, TargetL1BlockNumber: 5000, BatchesDerived: 42, Elapsed: 7e9}
+	WriteRollupSyncRecovery(db, recovery)
+	if got := ReadRollupSyncRecovery(db); got == nil || *got != *recovery {
+		t.Fatal("Mismatch in rollup sync recovery", "expected", recovery, "got", got)
+	}
+
+	DeleteRollupSyncRecovery(db)
+	if got := ReadRollupSyncRecovery(db); got != nil {
+		t.Fatal("Rollup sync recovery was not deleted", "got", got)
+	}
+}
+
+func TestRollupSyncBatchPointers(t *testing.T) {
+	db := NewMemoryDatabase()
+
+	if got := ReadRollupSyncBatchPointers(db); got != nil {
+		t.Fatal("Expected nil for non-existing value", "got", got)
+	}
+
+	pointers := &RollupSyncBatchPointers{
+		ScannedL1BlockNumber:   5000,
+		CommittedBatchIndex:    12,
+		CommittedL1BlockNumber: 4990,
+		FinalizedBatchIndex:    10,
+		FinalizedBatchHash:     common.HexToHash("0x01"),
+		FinalizedStateRoot:     common.HexToHash("0x02"),
+		FinalizedL1BlockNumber: 4980,
+		FinalizedL2BlockNumber: 300,
+	}
+	WriteRollupSyncBatchPointers(db, pointers)
+	if got := ReadRollupSyncBatchPointers(db); got == nil || *got != *pointers {
+		t.Fatal("Mismatch in rollup sync batch pointers", "expected", pointers, "got", got)
+	}
+
+	DeleteRollupSyncBatchPointers(db)
+	if got := ReadRollupSyncBatchPointers(db); got != nil {
+		t.Fatal("Rollup sync batch pointers were not deleted", "got", got)
+	}
+}
+
+func TestRollupBatchGaps(t *testing.T) {
+	db := NewMemoryDatabase()
+
+	if got := ReadRollupBatchGaps(db); got != nil {
+		t.Fatal("Expected nil for non-existing value", "got", got)
+	}
+
+	gaps := []*RollupBatchGap{
+		{FromBatchIndex: 3, ToBatchIndex: 4, FromL1BlockNumber: 10, ToL1BlockNumber: 300, NextL1BlockNumber: 10},
+		{FromBatchIndex: 8, ToBatchIndex: 8, FromL1BlockNumber: 400, ToL1BlockNumber: 5000, NextL1BlockNumber: 1400},
+	}
+	WriteRollupBatchGaps(db, gaps)
+	got := ReadRollupBatchGaps(db)
+	if len(got) != len(gaps) {
+		t.Fatal("Mismatch in rollup batch gaps", "expected", len(gaps), "got", len(got))
+	}
+	for i := range gaps {
+		if *got[i] != *gaps[i] {
+			t.Fatal("Mismatch in rollup batch gap", "index", i, "expected", gaps[i], "got", got[i])
+		}
+	}
+
+	WriteRollupBatchGaps(db, nil)
+	if got := ReadRollupBatchGaps(db); got != nil {
+		t.Fatal("Rollup batch gaps were not deleted", "got", got)
+	}
+}
+
+func TestRollupScrollChainAddress(t *testing.T) {
+	db := NewMemoryDatabase()
+
+	if got := ReadRollupScrollChainAddress(db); got != nil {
+		t.Fatal("Expected nil for non-existing value", "got", got)
+	}
+
+	address := common.HexToAddress("0x2D567EcE699Eabe5afCd141eDB7A4f2D0D6ce8a0")
+	WriteRollupScrollChainAddress(db, address)
+	if got := ReadRollupScrollChainAddress(db); got == nil || *got != address {
+		t.Fatal("Mismatch in rollup ScrollChain address", "expected", address, "got", got)
+	}
+}
+
+func TestDeleteRollupEventStore(t *testing.T) {
+	db := NewMemoryDatabase()
+
+	WriteRollupScrollChainAddress(db, common.HexToAddress("0x01"))
+	WriteRollupEventSyncedL1BlockNumber(db, 100)
+	WriteFinalizedL2BlockNumber(db, 10)
+	WriteBatchChunkRanges(db, 1, []*ChunkBlockRange{{StartBlockNumber: 1, EndBlockNumber: 10}})
+	WriteSyncedL1BlockNumber(db, 200)
+
+	// trie nodes whose hashes share the prefixes of the rollup event store
+	nodes := [][]byte{
+		append([]byte("R-"), make([]byte, common.HashLength-2)...),
+		append(append([]byte{}, batchMetaPrefix...), make([]byte, common.HashLength-len(batchMetaPrefix))...),
+	}
+	for _, node := range nodes {
+		if err := db.Put(node, []byte{0x01}); err != nil {
+			t.Fatal("Failed to write trie node", "err", err)
+		}
+	}
+
+	if deleted := DeleteRollupEventStore(db); deleted != 4 {
+		t.Fatal("Unexpected number of deleted keys", "expected", 4, "got", deleted)
+	}
+	if ReadRollupScrollChainAddress(db) != nil || ReadRollupEventSyncedL1BlockNumber(db) != nil || ReadFinalizedL2BlockNumber(db) != nil || ReadBatchChunkRanges(db, 1) != nil {
+		t.Fatal("Rollup event store was not deleted")
+	}
+	// the L1 message store is kept
+	if got := ReadSyncedL1BlockNumber(db); got == nil || *got != 200 {
+		t.Fatal("L1 message store was deleted", "got", got)
+	}
+	for _, node := range nodes {
+		if has, _ := db.Has(node); !has {
+			t.Fatal("Trie node was deleted", "key", node)
+		}
+	}
+}
+
+func TestL1FinalizedBlock(t *testing.T) {
+	db := NewMemoryDatabase()
+
+	if got := ReadL1FinalizedBlock(db); got != nil {
+		t.Fatal("Expected nil for non-existing value", "got", got)
+	}
+
+	finalized := &L1FinalizedBlock{Number: 1000, ObservedAt: 1700000000}
+	WriteL1FinalizedBlock(db, finalized)
+	if got := ReadL1FinalizedBlock(db); got == nil || *got != *finalized {
+		t.Fatal("Mismatch in L1 finalized block", "expected", finalized, "got", got)
+	}
+}
+
+func TestRollupSyncCheckpoints(t *testing.T) {
+	db := NewMemoryDatabase()
+
+	if got := ReadRollupSyncCheckpoints(db); len(got) != 0 {
+		t.Fatal("Expected no checkpoints", "got", got)
+	}
+
+	cp1 := &RollupSyncCheckpoint{L1BlockNumber: 200, L1BlockHash: common.HexToHash("0x02"), CommittedBatches: []uint64{3, 4}, FinalizedBatches: []uint64{}}
+	cp0 := &RollupSyncCheckpoint{L1BlockNumber: 100, L1BlockHash: common.HexToHash("0x01"), CommittedBatches: []uint64{}, FinalizedBatches: []uint64{1}, FinalizedL2BlockNumber: 10}
+	WriteRollupSyncCheckpoint(db, cp1)
+	WriteRollupSyncCheckpoint(db, cp0)
+
+	got := ReadRollupSyncCheckpoints(db)
+	if len(got) != 2 || !reflect.DeepEqual(got[0], cp0) || !reflect.DeepEqual(got[1], cp1) {
+		t.Fatal("Mismatch in checkpoints", "got", got)
+	}
+
+	DeleteRollupSyncCheckpoint(db, 100)
+	if got := ReadRollupSyncCheckpoints(db); len(got) != 1 || got[0].L1BlockNumber != 200 {
+		t.Fatal("Checkpoint was not deleted", "got", got)
+	}
+}
+
+func TestL1EndpointRanges(t *testing.T) {
+	db := NewMemoryDatabase()
+
+	if got := ReadL1EndpointRanges(db, 0, math.MaxUint64); len(got) != 0 {
+		t.Fatal("Expected no L1 endpoint ranges", "got", got)
+	}
+
+	r0 := &L1EndpointRange{FromL1BlockNumber: 1, ToL1BlockNumber: 100, Endpoints: []string{"a"}}
+	r1 := &L1EndpointRange{FromL1BlockNumber: 101, ToL1BlockNumber: 200, Endpoints: []string{"a", "b"}}
+	r2 := &L1EndpointRange{FromL1BlockNumber: 201, ToL1BlockNumber: 300, Endpoints: []string{"b"}}
+	WriteL1EndpointRange(db, r2)
+	WriteL1EndpointRange(db, r0)
+	WriteL1EndpointRange(db, r1)
+
+	tests := []struct {
+		from, to uint64
+		want     []*L1EndpointRange
+	}{
+		{0, math.MaxUint64, []*L1EndpointRange{r0, r1, r2}},
+		{100, 101, []*L1EndpointRange{r0, r1}},
+		{150, 150, []*L1EndpointRange{r1}},
+		{301, 400, nil},
+	}
+	for _, tt := range tests {
+		if got := ReadL1EndpointRanges(db, tt.from, tt.to); !reflect.DeepEqual(got, tt.want) {
+			t.Fatal("Mismatch in L1 endpoint ranges", "from", tt.from, "to", tt.to, "got", got, "want", tt.want)
+		}
+	}
+
+	// ranges overlapping after a reset are all returned
+	r3 := &L1EndpointRange{FromL1BlockNumber: 151, ToL1BlockNumber: 250, Endpoints: []string{"c"}}
+	WriteL1EndpointRange(db, r3)
+	if got := ReadL1EndpointRanges(db, 160, 160); !reflect.DeepEqual(got, []*L1EndpointRange{r1, r3}) {
+		t.Fatal("Mismatch in overlapping L1 endpoint ranges", "got", got)
+	}
+}
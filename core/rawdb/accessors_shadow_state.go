@@ -0,0 +1,31 @@
+package rawdb
+
+import (
+	"github.com/scroll-tech/go-ethereum/common"
+	"github.com/scroll-tech/go-ethereum/ethdb"
+	"github.com/scroll-tech/go-ethereum/log"
+)
+
+// WriteShadowStateRoot writes the root of the shadow MPT state after executing the block to the database.
+func WriteShadowStateRoot(db ethdb.KeyValueWriter, l2BlockHash common.Hash, root common.Hash) {
+	if err := db.Put(shadowStateRootKey(l2BlockHash), root.Bytes()); err != nil {
+		log.Crit("Failed to store shadow state root", "l2BlockHash", l2BlockHash.String(), "err", err)
+	}
+}
+
+// ReadShadowStateRoot retrieves the root of the shadow MPT state after executing the block.
+// It returns nil for blocks the shadow state was not maintained for.
+func ReadShadowStateRoot(db ethdb.Reader, l2BlockHash common.Hash) *common.Hash {
+	data, err := db.Get(shadowStateRootKey(l2BlockHash))
+	if err != nil && isNotFoundErr(err) {
+		return nil
+	}
+	if err != nil {
+		log.Crit("Failed to load shadow state root", "l2BlockHash", l2BlockHash.String(), "err", err)
+	}
+	if len(data) != common.HashLength {
+		log.Crit("Invalid shadow state root", "l2BlockHash", l2BlockHash.String(), "data", data)
+	}
+	root := common.BytesToHash(data)
+	return &root
+}
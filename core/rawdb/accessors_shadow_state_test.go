@@ -0,0 +1,21 @@
+package rawdb
+
+import (
+	"testing"
+
+	"github.com/scroll-tech/go-ethereum/common"
+)
+
+func TestReadShadowStateRoot(t *testing.T) {
+	db := NewMemoryDatabase()
+	l2BlockHash := common.HexToHash("0x0a")
+	if got := ReadShadowStateRoot(db, l2BlockHash); got != nil {
+		t.Fatalf("unexpected shadow state root for unknown block: %v", got)
+	}
+
+	root := common.HexToHash("0x01")
+	WriteShadowStateRoot(db, l2BlockHash, root)
+	if got := ReadShadowStateRoot(db, l2BlockHash); got == nil || *got != root {
+		t.Fatalf("shadow state root mismatch, expected %v, got %v", root, got)
+	}
+}
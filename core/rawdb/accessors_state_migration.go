@@ -0,0 +1,51 @@
+package rawdb
+
+import (
+	"github.com/scroll-tech/go-ethereum/common"
+	"github.com/scroll-tech/go-ethereum/ethdb"
+	"github.com/scroll-tech/go-ethereum/log"
+)
+
+// ReadStateMigrationProgress retrieves the serialized progress of the state migration.
+func ReadStateMigrationProgress(db ethdb.KeyValueReader) []byte {
+	data, _ := db.Get(stateMigrationKey)
+	return data
+}
+
+// WriteStateMigrationProgress stores the serialized progress of the state migration.
+func WriteStateMigrationProgress(db ethdb.KeyValueWriter, progress []byte) {
+	if err := db.Put(stateMigrationKey, progress); err != nil {
+		log.Crit("Failed to store state migration progress", "err", err)
+	}
+}
+
+// DeleteStateMigrationProgress deletes the serialized progress of the state migration.
+func DeleteStateMigrationProgress(db ethdb.KeyValueWriter) {
+	if err := db.Delete(stateMigrationKey); err != nil {
+		log.Crit("Failed to remove state migration progress", "err", err)
+	}
+}
+
+// WriteMigratedStateRoot writes the root of the migrated state after executing the block to the database.
+func WriteMigratedStateRoot(db ethdb.KeyValueWriter, l2BlockHash common.Hash, root common.Hash) {
+	if err := db.Put(migratedStateRootKey(l2BlockHash), root.Bytes()); err != nil {
+		log.Crit("Failed to store migrated state root", "l2BlockHash", l2BlockHash.String(), "err", err)
+	}
+}
+
+// ReadMigratedStateRoot retrieves the root of the migrated state after executing the block.
+// It returns nil for blocks whose state was not migrated.
+func ReadMigratedStateRoot(db ethdb.Reader, l2BlockHash common.Hash) *common.Hash {
+	data, err := db.Get(migratedStateRootKey(l2BlockHash))
+	if err != nil && isNotFoundErr(err) {
+		return nil
+	}
+	if err != nil {
+		log.Crit("Failed to load migrated state root", "l2BlockHash", l2BlockHash.String(), "err", err)
+	}
+	if len(data) != common.HashLength {
+		log.Crit("Invalid migrated state root", "l2BlockHash", l2BlockHash.String(), "data", data)
+	}
+	root := common.BytesToHash(data)
+	return &root
+}
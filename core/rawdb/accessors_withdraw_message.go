@@ -0,0 +1,104 @@
+package rawdb
+
+import (
+	"encoding/binary"
+
+	"github.com/scroll-tech/go-ethereum/common"
+	"github.com/scroll-tech/go-ethereum/ethdb"
+	"github.com/scroll-tech/go-ethereum/log"
+	"github.com/scroll-tech/go-ethereum/rlp"
+)
+
+// WithdrawMessage is a leaf of the withdraw trie, i.e. the hash of an L2->L1 message
+// appended to the L2MessageQueue, and the canonical block that appended it.
+type WithdrawMessage struct {
+	MessageHash common.Hash
+	BlockNumber uint64
+	BlockHash   common.Hash
+}
+
+// WriteWithdrawMessage stores the withdraw message with the given nonce and indexes
+// its nonce by message hash.
+func WriteWithdrawMessage(db ethdb.KeyValueWriter, nonce uint64, message *WithdrawMessage) {
+	data, err := rlp.EncodeToBytes(message)
+	if err != nil {
+		log.Crit("Failed to RLP encode withdraw message", "nonce", nonce, "err", err)
+	}
+	if err := db.Put(withdrawMessageKey(nonce), data); err != nil {
+		log.Crit("Failed to store withdraw message", "nonce", nonce, "err", err)
+	}
+	if err := db.Put(withdrawMessageNonceKey(message.MessageHash), encodeBigEndian(nonce)); err != nil {
+		log.Crit("Failed to store withdraw message nonce", "messageHash", message.MessageHash.String(), "err", err)
+	}
+}
+
+// ReadWithdrawMessage retrieves the withdraw message with the given nonce.
+func ReadWithdrawMessage(db ethdb.Reader, nonce uint64) *WithdrawMessage {
+	data, err := db.Get(withdrawMessageKey(nonce))
+	if err != nil && isNotFoundErr(err) {
+		return nil
+	}
+	if err != nil {
+		log.Crit("Failed to load withdraw message", "nonce", nonce, "err", err)
+	}
+	message := new(WithdrawMessage)
+	if err := rlp.DecodeBytes(data, message); err != nil {
+		log.Crit("Invalid withdraw message RLP", "nonce", nonce, "data", data, "err", err)
+	}
+	return message
+}
+
+// ReadWithdrawMessageNonce retrieves the nonce of the withdraw message with the given hash.
+func ReadWithdrawMessageNonce(db ethdb.Reader, messageHash common.Hash) *uint64 {
+	data, err := db.Get(withdrawMessageNonceKey(messageHash))
+	if err != nil && isNotFoundErr(err) {
+		return nil
+	}
+	if err != nil {
+		log.Crit("Failed to load withdraw message nonce", "messageHash", messageHash.String(), "err", err)
+	}
+	if len(data) != 8 {
+		log.Crit("Invalid withdraw message nonce", "messageHash", messageHash.String(), "data", data)
+	}
+	nonce := binary.BigEndian.Uint64(data)
+	return &nonce
+}
+
+// DeleteWithdrawMessage removes the withdraw message with the given nonce.
+func DeleteWithdrawMessage(db ethdb.KeyValueWriter, nonce uint64) {
+	if err := db.Delete(withdrawMessageKey(nonce)); err != nil {
+		log.Crit("Failed to delete withdraw message", "nonce", nonce, "err", err)
+	}
+}
+
+// DeleteWithdrawMessageNonce removes the nonce index of the withdraw message with the given hash.
+func DeleteWithdrawMessageNonce(db ethdb.KeyValueWriter, messageHash common.Hash) {
+	if err := db.Delete(withdrawMessageNonceKey(messageHash)); err != nil {
+		log.Crit("Failed to delete withdraw message nonce", "messageHash", messageHash.String(), "err", err)
+	}
+}
+
+// ReadWithdrawMessageHashes retrieves the hashes of the withdraw messages with nonces in
+// [0, count). It stops at the first missing nonce, so fewer hashes are returned if the
+// index is incomplete.
+func ReadWithdrawMessageHashes(db ethdb.Iteratee, count uint64) []common.Hash {
+	it := db.NewIterator(withdrawMessagePrefix, nil)
+	defer it.Release()
+
+	var hashes []common.Hash
+	keyLength := len(withdrawMessagePrefix) + 8
+	for uint64(len(hashes)) < count && it.Next() {
+		if len(it.Key()) != keyLength {
+			continue
+		}
+		if nonce := binary.BigEndian.Uint64(it.Key()[len(withdrawMessagePrefix):]); nonce != uint64(len(hashes)) {
+			break
+		}
+		var message WithdrawMessage
+		if err := rlp.DecodeBytes(it.Value(), &message); err != nil {
+			log.Crit("Invalid withdraw message RLP", "key", it.Key(), "data", it.Value(), "err", err)
+		}
+		hashes = append(hashes, message.MessageHash)
+	}
+	return hashes
+}
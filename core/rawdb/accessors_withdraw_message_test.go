@@ -0,0 +1,45 @@
+package rawdb
+
+import (
+	"testing"
+
+	"github.com/scroll-tech/go-ethereum/common"
+)
+
+func TestReadWriteWithdrawMessage(t *testing.T) {
+	db := NewMemoryDatabase()
+	if got := ReadWithdrawMessage(db, 0); got != nil {
+		t.Fatal("unexpected withdraw message", "got", got)
+	}
+
+	for nonce := uint64(0); nonce < 5; nonce++ {
+		if nonce == 3 {
+			continue
+		}
+		WriteWithdrawMessage(db, nonce, &WithdrawMessage{MessageHash: common.Hash{byte(nonce + 1)}, BlockNumber: nonce * 10, BlockHash: common.Hash{0xff}})
+	}
+	got := ReadWithdrawMessage(db, 2)
+	if got == nil || got.MessageHash != (common.Hash{3}) || got.BlockNumber != 20 || got.BlockHash != (common.Hash{0xff}) {
+		t.Fatal("withdraw message mismatch", "got", got)
+	}
+	if nonce := ReadWithdrawMessageNonce(db, common.Hash{3}); nonce == nil || *nonce != 2 {
+		t.Fatal("withdraw message nonce mismatch", "expected", 2, "got", nonce)
+	}
+
+	// the hashes stop at the missing nonce 3
+	if hashes := ReadWithdrawMessageHashes(db, 2); len(hashes) != 2 || hashes[1] != (common.Hash{2}) {
+		t.Fatal("withdraw message hashes mismatch", "got", hashes)
+	}
+	if hashes := ReadWithdrawMessageHashes(db, 5); len(hashes) != 3 {
+		t.Fatal("withdraw message hashes mismatch", "expected", 3, "got", len(hashes))
+	}
+
+	DeleteWithdrawMessage(db, 2)
+	DeleteWithdrawMessageNonce(db, common.Hash{3})
+	if got := ReadWithdrawMessage(db, 2); got != nil {
+		t.Fatal("withdraw message not deleted", "got", got)
+	}
+	if nonce := ReadWithdrawMessageNonce(db, common.Hash{3}); nonce != nil {
+		t.Fatal("withdraw message nonce not deleted", "got", *nonce)
+	}
+}
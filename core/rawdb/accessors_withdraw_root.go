@@ -0,0 +1,76 @@
+package rawdb
+
+import (
+	"bytes"
+
+	"github.com/scroll-tech/go-ethereum/common"
+	"github.com/scroll-tech/go-ethereum/ethdb"
+	"github.com/scroll-tech/go-ethereum/log"
+	"github.com/scroll-tech/go-ethereum/rlp"
+)
+
+// WithdrawRootBackfill is the progress of the backfill of the withdraw roots of the blocks
+// imported before withdraw roots were stored.
+type WithdrawRootBackfill struct {
+	NextBlock uint64 // number of the next block to backfill
+	Done      bool   // set once all blocks up to the head at the start of the backfill are covered
+}
+
+// WriteBlockWithdrawRoot writes the withdraw trie root after executing the block to the database.
+func WriteBlockWithdrawRoot(db ethdb.KeyValueWriter, l2BlockHash common.Hash, withdrawRoot common.Hash) {
+	if err := db.Put(withdrawRootKey(l2BlockHash), withdrawRoot.Bytes()); err != nil {
+		log.Crit("Failed to store withdraw root", "l2BlockHash", l2BlockHash.String(), "err", err)
+	}
+}
+
+// ReadBlockWithdrawRoot retrieves the withdraw trie root after executing the block.
+// It returns nil for blocks imported before withdraw roots were stored.
+func ReadBlockWithdrawRoot(db ethdb.Reader, l2BlockHash common.Hash) *common.Hash {
+	data, err := db.Get(withdrawRootKey(l2BlockHash))
+	if err != nil && isNotFoundErr(err) {
+		return nil
+	}
+	if err != nil {
+		log.Crit("Failed to load withdraw root", "l2BlockHash", l2BlockHash.String(), "err", err)
+	}
+	if len(data) != common.HashLength {
+		log.Crit("Invalid withdraw root", "l2BlockHash", l2BlockHash.String(), "data", data)
+	}
+	withdrawRoot := common.BytesToHash(data)
+	return &withdrawRoot
+}
+
+// DeleteBlockWithdrawRoot removes the withdraw trie root of the block.
+func DeleteBlockWithdrawRoot(db ethdb.KeyValueWriter, l2BlockHash common.Hash) {
+	if err := db.Delete(withdrawRootKey(l2BlockHash)); err != nil {
+		log.Crit("Failed to delete withdraw root", "l2BlockHash", l2BlockHash.String(), "err", err)
+	}
+}
+
+// WriteWithdrawRootBackfill stores the progress of the withdraw root backfill.
+func WriteWithdrawRootBackfill(db ethdb.KeyValueWriter, progress *WithdrawRootBackfill) {
+	value, err := rlp.EncodeToBytes(progress)
+	if err != nil {
+		log.Crit("Failed to RLP encode withdraw root backfill progress", "progress", progress, "err", err)
+	}
+	if err := db.Put(withdrawRootBackfillKey, value); err != nil {
+		log.Crit("Failed to store withdraw root backfill progress", "err", err)
+	}
+}
+
+// ReadWithdrawRootBackfill retrieves the progress of the withdraw root backfill, or nil
+// if it never ran.
+func ReadWithdrawRootBackfill(db ethdb.Reader) *WithdrawRootBackfill {
+	data, err := db.Get(withdrawRootBackfillKey)
+	if err != nil && isNotFoundErr(err) {
+		return nil
+	}
+	if err != nil {
+		log.Crit("Failed to load withdraw root backfill progress", "err", err)
+	}
+	progress := new(WithdrawRootBackfill)
+	if err := rlp.Decode(bytes.NewReader(data), progress); err != nil {
+		log.Crit("Invalid withdraw root backfill progress RLP", "data", data, "err", err)
+	}
+	return progress
+}
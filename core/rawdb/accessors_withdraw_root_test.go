@@ -0,0 +1,44 @@
+package rawdb
+
+import (
+	"testing"
+
+	"github.com/scroll-tech/go-ethereum/common"
+)
+
+func TestReadBlockWithdrawRoot(t *testing.T) {
+	db := NewMemoryDatabase()
+	l2BlockHash := common.HexToHash("0x0a")
+	if got := ReadBlockWithdrawRoot(db, l2BlockHash); got != nil {
+		t.Fatalf("unexpected withdraw root for unknown block: %v", got)
+	}
+
+	// the zero root is stored as well
+	for _, withdrawRoot := range []common.Hash{{}, common.HexToHash("0x01")} {
+		WriteBlockWithdrawRoot(db, l2BlockHash, withdrawRoot)
+		got := ReadBlockWithdrawRoot(db, l2BlockHash)
+		if got == nil || *got != withdrawRoot {
+			t.Fatalf("withdraw root mismatch, expected %v, got %v", withdrawRoot, got)
+		}
+	}
+
+	DeleteBlockWithdrawRoot(db, l2BlockHash)
+	if got := ReadBlockWithdrawRoot(db, l2BlockHash); got != nil {
+		t.Fatalf("withdraw root not deleted: %v", got)
+	}
+}
+
+func TestReadWithdrawRootBackfill(t *testing.T) {
+	db := NewMemoryDatabase()
+	if got := ReadWithdrawRootBackfill(db); got != nil {
+		t.Fatalf("unexpected backfill progress before the backfill ran: %v", got)
+	}
+
+	for _, progress := range []WithdrawRootBackfill{{NextBlock: 1024}, {NextBlock: 2048, Done: true}} {
+		WriteWithdrawRootBackfill(db, &progress)
+		got := ReadWithdrawRootBackfill(db)
+		if got == nil || *got != progress {
+			t.Fatalf("backfill progress mismatch, expected %v, got %v", progress, got)
+		}
+	}
+}
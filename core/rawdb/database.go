@@ -405,7 +405,7 @@ func InspectDatabase(db ethdb.Database, keyPrefix, keyStart []byte) error {
 				databaseVersionKey, headHeaderKey, headBlockKey, headFastBlockKey, lastPivotKey,
 				fastTrieProgressKey, snapshotDisabledKey, SnapshotRootKey, snapshotJournalKey,
 				snapshotGeneratorKey, snapshotRecoveryKey, txIndexTailKey, fastTxLookupLimitKey,
-				uncleanShutdownKey, badBlockKey, syncedL1BlockNumberKey,
+				uncleanShutdownKey, badBlockKey, syncedL1BlockNumberKey, l1ModeKey,
 			} {
 				if bytes.Equal(key, meta) {
 					metadata.Add(size)
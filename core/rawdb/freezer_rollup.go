@@ -0,0 +1,181 @@
+package rawdb
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"path/filepath"
+
+	"github.com/scroll-tech/go-ethereum/ethdb"
+	"github.com/scroll-tech/go-ethereum/log"
+)
+
+const (
+	// freezerBatchMetaTable indicates the name of the freezer finalized batch metadata table.
+	freezerBatchMetaTable = "batchmeta"
+
+	// freezerBatchChunkRangesTable indicates the name of the freezer batch chunk ranges table.
+	freezerBatchChunkRangesTable = "chunkranges"
+
+	// batchFreezeBatchLimit is the maximum number of batches moved into the
+	// batch freezer in a single write.
+	batchFreezeBatchLimit = 10000
+)
+
+// batchFreezerTables are the tables of the batch freezer, snappy compression is enabled for all of them.
+var batchFreezerTables = map[string]bool{
+	freezerBatchMetaTable:        false,
+	freezerBatchChunkRangesTable: false,
+}
+
+// BatchFreezerDir returns the directory of the batch freezer within the ancient directory of the chain database.
+func BatchFreezerDir(ancient string) string {
+	return filepath.Join(ancient, "rollup")
+}
+
+// BatchFreezerDatabase is a database wrapper that serves the metadata and chunk ranges
+// of old finalized batches from an append-only freezer once they have been moved out
+// of the key-value store with FreezeBatches. Frozen batches are indexed by batch index
+// starting at 0, so only a contiguous range of batches can be frozen.
+type BatchFreezerDatabase struct {
+	ethdb.Database
+	freezer *freezer
+}
+
+// NewBatchFreezerDatabase opens the batch freezer in the given directory and wraps db,
+// transparently falling back to the freezer when reading batch metadata or chunk ranges
+// that are not in the key-value store.
+func NewBatchFreezerDatabase(db ethdb.Database, dir string, namespace string, readonly bool) (*BatchFreezerDatabase, error) {
+	frdb, err := newFreezer(dir, namespace, readonly, freezerTableSize, batchFreezerTables)
+	if err != nil {
+		return nil, err
+	}
+	return &BatchFreezerDatabase{Database: db, freezer: frdb}, nil
+}
+
+// frozenBatchItem maps a key of the key-value store to the freezer table and item
+// that may hold its value.
+func frozenBatchItem(key []byte) (string, uint64, bool) {
+	switch {
+	case len(key) == len(batchMetaPrefix)+8 && bytes.HasPrefix(key, batchMetaPrefix):
+		return freezerBatchMetaTable, binary.BigEndian.Uint64(key[len(batchMetaPrefix):]), true
+	case len(key) == len(batchChunkRangesPrefix)+8 && bytes.HasPrefix(key, batchChunkRangesPrefix):
+		return freezerBatchChunkRangesTable, binary.BigEndian.Uint64(key[len(batchChunkRangesPrefix):]), true
+	}
+	return "", 0, false
+}
+
+// Get retrieves the given key from the key-value store, or from the batch freezer
+// if the key belongs to a frozen batch.
+func (db *BatchFreezerDatabase) Get(key []byte) ([]byte, error) {
+	value, err := db.Database.Get(key)
+	if err == nil || !isNotFoundErr(err) {
+		return value, err
+	}
+	if kind, number, ok := frozenBatchItem(key); ok {
+		if data, ferr := db.freezer.Ancient(kind, number); ferr == nil {
+			return data, nil
+		}
+	}
+	return nil, err
+}
+
+// Has retrieves if the given key is present in the key-value store or the batch freezer.
+func (db *BatchFreezerDatabase) Has(key []byte) (bool, error) {
+	if has, err := db.Database.Has(key); err != nil || has {
+		return has, err
+	}
+	if kind, number, ok := frozenBatchItem(key); ok {
+		return db.freezer.HasAncient(kind, number)
+	}
+	return false, nil
+}
+
+// Close closes the batch freezer and the wrapped database.
+func (db *BatchFreezerDatabase) Close() error {
+	var errs []error
+	if err := db.freezer.Close(); err != nil {
+		errs = append(errs, err)
+	}
+	if err := db.Database.Close(); err != nil {
+		errs = append(errs, err)
+	}
+	if len(errs) != 0 {
+		return fmt.Errorf("%v", errs)
+	}
+	return nil
+}
+
+// FrozenBatches returns the number of batches in the batch freezer.
+func (db *BatchFreezerDatabase) FrozenBatches() (uint64, error) {
+	return db.freezer.Ancients()
+}
+
+// FreezeBatches moves the metadata and chunk ranges of all finalized batches with
+// an index below limit from the key-value store into the batch freezer. It stops
+// at the first batch without finalized metadata and returns the number of frozen
+// batches afterwards.
+func (db *BatchFreezerDatabase) FreezeBatches(limit uint64) (uint64, error) {
+	frozen, err := db.freezer.Ancients()
+	if err != nil {
+		return 0, err
+	}
+	for frozen < limit {
+		end := limit
+		if end-frozen > batchFreezeBatchLimit {
+			end = frozen + batchFreezeBatchLimit
+		}
+		var metas, ranges [][]byte
+		for batchIndex := frozen; batchIndex < end; batchIndex++ {
+			meta, err := db.Database.Get(batchMetaKey(batchIndex))
+			if err != nil && !isNotFoundErr(err) {
+				return frozen, err
+			}
+			chunkRanges, err := db.Database.Get(batchChunkRangesKey(batchIndex))
+			if err != nil && !isNotFoundErr(err) {
+				return frozen, err
+			}
+			if meta == nil || chunkRanges == nil {
+				log.Warn("Stopped freezing batches at batch without finalized metadata", "batch index", batchIndex)
+				limit = batchIndex
+				break
+			}
+			metas, ranges = append(metas, meta), append(ranges, chunkRanges)
+		}
+		if len(metas) == 0 {
+			break
+		}
+
+		_, err := db.freezer.ModifyAncients(func(op ethdb.AncientWriteOp) error {
+			for i := range metas {
+				batchIndex := frozen + uint64(i)
+				if err := op.AppendRaw(freezerBatchMetaTable, batchIndex, metas[i]); err != nil {
+					return fmt.Errorf("can't write batch metadata to freezer, batch index: %v, err: %w", batchIndex, err)
+				}
+				if err := op.AppendRaw(freezerBatchChunkRangesTable, batchIndex, ranges[i]); err != nil {
+					return fmt.Errorf("can't write batch chunk ranges to freezer, batch index: %v, err: %w", batchIndex, err)
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			return frozen, err
+		}
+		if err := db.freezer.Sync(); err != nil {
+			return frozen, err
+		}
+
+		// Only remove the key-value entries once the freezer has been synced to disk.
+		batch := db.Database.NewBatch()
+		for i := range metas {
+			DeleteFinalizedBatchMeta(batch, frozen+uint64(i))
+			DeleteBatchChunkRanges(batch, frozen+uint64(i))
+		}
+		if err := batch.Write(); err != nil {
+			return frozen, err
+		}
+		frozen += uint64(len(metas))
+		log.Info("Froze finalized batches", "frozen", frozen)
+	}
+	return frozen, nil
+}
@@ -0,0 +1,81 @@
+package rawdb
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/scroll-tech/go-ethereum/common"
+)
+
+func TestBatchFreezer(t *testing.T) {
+	dir := t.TempDir()
+	db, err := NewBatchFreezerDatabase(NewMemoryDatabase(), dir, "", false)
+	if err != nil {
+		t.Fatalf("failed to open batch freezer: %v", err)
+	}
+
+	metas := make([]*FinalizedBatchMeta, 5)
+	ranges := make([][]*ChunkBlockRange, 5)
+	for i := range metas {
+		metas[i] = &FinalizedBatchMeta{BatchHash: common.BigToHash(common.Big1), TotalL1MessagePopped: uint64(i)}
+		ranges[i] = []*ChunkBlockRange{{StartBlockNumber: uint64(i * 10), EndBlockNumber: uint64(i*10 + 9)}}
+		WriteFinalizedBatchMeta(db, uint64(i), metas[i])
+		WriteBatchChunkRanges(db, uint64(i), ranges[i])
+	}
+	// batch 6 is committed but not finalized
+	WriteBatchChunkRanges(db, 6, []*ChunkBlockRange{{StartBlockNumber: 60, EndBlockNumber: 69}})
+
+	frozen, err := db.FreezeBatches(3)
+	if err != nil {
+		t.Fatalf("failed to freeze batches: %v", err)
+	}
+	if frozen != 3 {
+		t.Fatalf("unexpected number of frozen batches, got %d, want 3", frozen)
+	}
+	for i := uint64(0); i < 3; i++ {
+		if has, _ := db.Database.Has(batchMetaKey(i)); has {
+			t.Errorf("batch %d metadata still in key-value store", i)
+		}
+		if has, _ := db.Has(batchChunkRangesKey(i)); !has {
+			t.Errorf("batch %d chunk ranges not found", i)
+		}
+	}
+	for i := range metas {
+		if got := ReadFinalizedBatchMeta(db, uint64(i)); !reflect.DeepEqual(got, metas[i]) {
+			t.Errorf("batch %d metadata mismatch, got %v, want %v", i, got, metas[i])
+		}
+		if got := ReadBatchChunkRanges(db, uint64(i)); !reflect.DeepEqual(got, ranges[i]) {
+			t.Errorf("batch %d chunk ranges mismatch, got %v, want %v", i, got, ranges[i])
+		}
+	}
+
+	// freezing stops at the first batch without finalized metadata
+	if frozen, err = db.FreezeBatches(10); err != nil {
+		t.Fatalf("failed to freeze batches: %v", err)
+	}
+	if frozen != 5 {
+		t.Fatalf("unexpected number of frozen batches, got %d, want 5", frozen)
+	}
+	if ReadFinalizedBatchMeta(db, 5) != nil {
+		t.Fatal("unexpected metadata of batch 5")
+	}
+	if ReadBatchChunkRanges(db, 6) == nil {
+		t.Fatal("chunk ranges of unfinalized batch 6 not found")
+	}
+	if err := db.Close(); err != nil {
+		t.Fatalf("failed to close database: %v", err)
+	}
+
+	// frozen batches are served after reopening the freezer
+	reopened, err := NewBatchFreezerDatabase(NewMemoryDatabase(), dir, "", true)
+	if err != nil {
+		t.Fatalf("failed to reopen batch freezer: %v", err)
+	}
+	defer reopened.Close()
+	if frozen, _ := reopened.FrozenBatches(); frozen != 5 {
+		t.Fatalf("unexpected number of frozen batches after reopening, got %d, want 5", frozen)
+	}
+	if got := ReadFinalizedBatchMeta(reopened, 4); !reflect.DeepEqual(got, metas[4]) {
+		t.Errorf("batch 4 metadata mismatch after reopening, got %v, want %v", got, metas[4])
+	}
+}
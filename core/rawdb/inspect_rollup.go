@@ -0,0 +1,142 @@
+package rawdb
+
+import (
+	"encoding/binary"
+	"fmt"
+
+	"github.com/scroll-tech/go-ethereum/common"
+	"github.com/scroll-tech/go-ethereum/ethdb"
+)
+
+// RollupTableStats describes the entries of one rollup table.
+type RollupTableStats struct {
+	Database string
+	Name     string
+	Count    uint64
+	Size     common.StorageSize
+	Highest  *uint64 // highest batch index or block number in the table
+}
+
+// RollupDataReport is the result of InspectRollupData.
+type RollupDataReport struct {
+	Tables                 []*RollupTableStats
+	SyncedL1BlockNumber    *uint64
+	FinalizedL2BlockNumber *uint64
+	Inconsistencies        []string
+}
+
+// inspectRollupTable iterates over the entries of a table keyed by an uint64 after the prefix
+// and calls fn with the index of every entry.
+func inspectRollupTable(db ethdb.Iteratee, database, name string, prefix []byte, fn func(index uint64, value []byte)) *RollupTableStats {
+	it := db.NewIterator(prefix, nil)
+	defer it.Release()
+
+	stats := &RollupTableStats{Database: database, Name: name}
+	for it.Next() {
+		key := it.Key()
+		if len(key) != len(prefix)+8 {
+			continue
+		}
+		index := binary.BigEndian.Uint64(key[len(prefix):])
+		stats.Count++
+		stats.Size += common.StorageSize(len(key) + len(it.Value()))
+		if stats.Highest == nil || index > *stats.Highest {
+			stats.Highest = &index
+		}
+		if fn != nil {
+			fn(index, it.Value())
+		}
+	}
+	return stats
+}
+
+// InspectRollupData walks the rollup tables of the database, batches frozen by a
+// BatchFreezerDatabase included, and reports their sizes and any inconsistencies
+// between them.
+func InspectRollupData(db ethdb.Database) (*RollupDataReport, error) {
+	report := &RollupDataReport{
+		SyncedL1BlockNumber:    ReadRollupEventSyncedL1BlockNumber(db),
+		FinalizedL2BlockNumber: ReadFinalizedL2BlockNumber(db),
+	}
+	inconsistent := func(format string, args ...interface{}) {
+		report.Inconsistencies = append(report.Inconsistencies, fmt.Sprintf(format, args...))
+	}
+
+	var frozen uint64
+	if frdb, ok := db.(*BatchFreezerDatabase); ok {
+		var err error
+		if frozen, err = frdb.FrozenBatches(); err != nil {
+			return nil, err
+		}
+		for _, table := range []struct{ kind, name string }{
+			{freezerBatchChunkRangesTable, "Batch chunk ranges"},
+			{freezerBatchMetaTable, "Finalized batch meta"},
+		} {
+			size, err := frdb.freezer.AncientSize(table.kind)
+			if err != nil {
+				return nil, err
+			}
+			stats := &RollupTableStats{Database: "Ancient store", Name: table.name, Count: frozen, Size: common.StorageSize(size)}
+			if frozen > 0 {
+				highest := frozen - 1
+				stats.Highest = &highest
+			}
+			report.Tables = append(report.Tables, stats)
+		}
+	}
+
+	chunkRanges := inspectRollupTable(db, "Key-Value store", "Batch chunk ranges", batchChunkRangesPrefix, nil)
+	var finalizedBatches []uint64
+	batchMeta := inspectRollupTable(db, "Key-Value store", "Finalized batch meta", batchMetaPrefix, func(index uint64, value []byte) {
+		finalizedBatches = append(finalizedBatches, index)
+	})
+	batchL1Meta := inspectRollupTable(db, "Key-Value store", "Batch L1 meta", batchL1MetaPrefix, nil)
+	batchEndBlocks := inspectRollupTable(db, "Key-Value store", "Batch end block index", batchEndBlockPrefix, func(endBlockNumber uint64, value []byte) {
+		if len(value) != 8 {
+			inconsistent("invalid batch end block index entry for block %d", endBlockNumber)
+			return
+		}
+		batchIndex := binary.BigEndian.Uint64(value)
+		if chunkBlockRanges := ReadBatchChunkRanges(db, batchIndex); len(chunkBlockRanges) == 0 {
+			inconsistent("batch end block index entry for block %d refers to batch %d without chunk ranges", endBlockNumber, batchIndex)
+		} else if end := chunkBlockRanges[len(chunkBlockRanges)-1].EndBlockNumber; end != endBlockNumber {
+			inconsistent("batch end block index entry for block %d refers to batch %d ending at block %d", endBlockNumber, batchIndex, end)
+		}
+	})
+	report.Tables = append(report.Tables, chunkRanges, batchMeta, batchL1Meta, batchEndBlocks)
+
+	// Every finalized batch must have chunk ranges, and finalized batches must be contiguous.
+	for i, batchIndex := range finalizedBatches {
+		if ok, _ := db.Has(batchChunkRangesKey(batchIndex)); !ok {
+			inconsistent("finalized batch %d has no chunk ranges", batchIndex)
+		}
+		if i > 0 && finalizedBatches[i-1]+1 != batchIndex {
+			inconsistent("finalized batches %d to %d are missing", finalizedBatches[i-1]+1, batchIndex-1)
+		}
+	}
+	if len(finalizedBatches) > 0 && finalizedBatches[0] > frozen {
+		inconsistent("finalized batches %d to %d are missing", frozen, finalizedBatches[0]-1)
+	}
+
+	// The finalized L2 block must be the last block of the highest finalized batch.
+	var highestFinalized *uint64
+	if batchMeta.Highest != nil {
+		highestFinalized = batchMeta.Highest
+	} else if frozen > 0 {
+		highest := frozen - 1
+		highestFinalized = &highest
+	}
+	switch {
+	case report.FinalizedL2BlockNumber != nil && highestFinalized == nil:
+		inconsistent("finalized L2 block %d is set, but there are no finalized batches", *report.FinalizedL2BlockNumber)
+	case report.FinalizedL2BlockNumber == nil && highestFinalized != nil:
+		inconsistent("finalized batch %d is stored, but the finalized L2 block is not set", *highestFinalized)
+	case highestFinalized != nil:
+		if chunkBlockRanges := ReadBatchChunkRanges(db, *highestFinalized); len(chunkBlockRanges) != 0 {
+			if end := chunkBlockRanges[len(chunkBlockRanges)-1].EndBlockNumber; end != *report.FinalizedL2BlockNumber {
+				inconsistent("finalized L2 block %d is not the last block %d of the highest finalized batch %d", *report.FinalizedL2BlockNumber, end, *highestFinalized)
+			}
+		}
+	}
+	return report, nil
+}
@@ -0,0 +1,75 @@
+package rawdb
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestInspectRollupData(t *testing.T) {
+	db, err := NewBatchFreezerDatabase(NewMemoryDatabase(), t.TempDir(), "", false)
+	if err != nil {
+		t.Fatalf("failed to open batch freezer: %v", err)
+	}
+	defer db.Close()
+
+	report, err := InspectRollupData(db)
+	if err != nil {
+		t.Fatalf("failed to inspect rollup data: %v", err)
+	}
+	if len(report.Inconsistencies) != 0 {
+		t.Fatalf("unexpected inconsistencies in empty database: %v", report.Inconsistencies)
+	}
+
+	for i := uint64(0); i < 4; i++ {
+		WriteBatchChunkRanges(db, i, []*ChunkBlockRange{{StartBlockNumber: i * 10, EndBlockNumber: i*10 + 9}})
+		WriteBatchEndBlock(db, i*10+9, i)
+		WriteFinalizedBatchMeta(db, i, &FinalizedBatchMeta{TotalL1MessagePopped: i})
+	}
+	WriteFinalizedL2BlockNumber(db, 39)
+	WriteRollupEventSyncedL1BlockNumber(db, 100)
+	if _, err := db.FreezeBatches(2); err != nil {
+		t.Fatalf("failed to freeze batches: %v", err)
+	}
+
+	report, err = InspectRollupData(db)
+	if err != nil {
+		t.Fatalf("failed to inspect rollup data: %v", err)
+	}
+	if len(report.Inconsistencies) != 0 {
+		t.Fatalf("unexpected inconsistencies: %v", report.Inconsistencies)
+	}
+	if report.SyncedL1BlockNumber == nil || *report.SyncedL1BlockNumber != 100 {
+		t.Fatalf("unexpected synced L1 block number: %v", report.SyncedL1BlockNumber)
+	}
+	counts := make(map[string]uint64)
+	for _, table := range report.Tables {
+		counts[table.Database+"/"+table.Name] = table.Count
+	}
+	want := map[string]uint64{
+		"Ancient store/Batch chunk ranges":      2,
+		"Ancient store/Finalized batch meta":    2,
+		"Key-Value store/Batch chunk ranges":    2,
+		"Key-Value store/Finalized batch meta":  2,
+		"Key-Value store/Batch L1 meta":         0,
+		"Key-Value store/Batch end block index": 4,
+	}
+	if !reflect.DeepEqual(counts, want) {
+		t.Fatalf("unexpected table counts, got %v, want %v", counts, want)
+	}
+
+	// break the database
+	DeleteBatchChunkRanges(db, 3)
+	DeleteFinalizedBatchMeta(db, 2)
+	report, err = InspectRollupData(db)
+	if err != nil {
+		t.Fatalf("failed to inspect rollup data: %v", err)
+	}
+	wantInconsistencies := []string{
+		"batch end block index entry for block 39 refers to batch 3 without chunk ranges",
+		"finalized batch 3 has no chunk ranges",
+		"finalized batches 2 to 2 are missing",
+	}
+	if !reflect.DeepEqual(report.Inconsistencies, wantInconsistencies) {
+		t.Fatalf("unexpected inconsistencies, got %q, want %q", report.Inconsistencies, wantInconsistencies)
+	}
+}
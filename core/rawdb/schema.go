@@ -109,16 +109,63 @@ var (
 	l1MessagePrefix                   = []byte("L1") // l1MessagePrefix + queueIndex (uint64 big endian) -> L1MessageTx
 	firstQueueIndexNotInL2BlockPrefix = []byte("q")  // firstQueueIndexNotInL2BlockPrefix + L2 block hash -> enqueue index
 	highestSyncedQueueIndexKey        = []byte("HighestSyncedQueueIndex")
+	l1ModeKey                         = []byte("L1Mode")
+	l1MessageFeePrefix                = []byte("mf") // l1MessageFeePrefix + queueIndex (uint64 big endian) -> L1MessageFee
+	l1MessageOriginPrefix             = []byte("mo") // l1MessageOriginPrefix + queueIndex (uint64 big endian) -> L1MessageOrigin
+	l1MessageInclusionPrefix          = []byte("mi") // l1MessageInclusionPrefix + queueIndex (uint64 big endian) -> L1MessageInclusion
 
 	// Scroll rollup event store
 	rollupEventSyncedL1BlockNumberKey = []byte("R-LastRollupEventSyncedL1BlockNumber")
 	batchChunkRangesPrefix            = []byte("R-bcr")
 	batchMetaPrefix                   = []byte("R-bm")
 	finalizedL2BlockNumberKey         = []byte("R-finalized")
+	batchL1MetaPrefix                 = []byte("R-bl1")
+	batchEndBlockPrefix               = []byte("R-be") // batchEndBlockPrefix + last L2 block number of batch (uint64 big endian) -> batch index
+	batchL1BlockPrefix                = []byte("R-lb") // batchL1BlockPrefix + L1 block number (uint64 big endian) + event kind + batch index (uint64 big endian) -> empty
+	poisonedBatchPrefix               = []byte("R-pb") // poisonedBatchPrefix + batch index (uint64 big endian) -> PoisonedBatch
+	revertedBatchPrefix               = []byte("R-rv") // revertedBatchPrefix + batch index (uint64 big endian) -> RevertedBatch
+	rollupSyncCheckpointPrefix        = []byte("R-cp") // rollupSyncCheckpointPrefix + L1 block number (uint64 big endian) -> RollupSyncCheckpoint
+	l1EndpointRangePrefix             = []byte("R-ep") // l1EndpointRangePrefix + last L1 block number of range (uint64 big endian) -> L1EndpointRange
+	batchSkippedL1MessagesPrefix      = []byte("R-sb") // batchSkippedL1MessagesPrefix + batch index (uint64 big endian) -> skipped queue indices
+	skippedL1MessagePrefix            = []byte("R-sq") // skippedL1MessagePrefix + queue index (uint64 big endian) -> batch index
+	enforcedBatchModeKey              = []byte("R-enforced")
+	rollupSyncRecoveryKey             = []byte("R-recovery")
+	rollupSyncBatchPointersKey        = []byte("R-pointers")
+	l1FinalizedBlockKey               = []byte("R-l1finalized")
+	rollupScrollChainAddressKey       = []byte("R-scrollchain")
+	rollupBatchGapsKey                = []byte("R-gaps")
+	metadataSinkRecordPrefix          = []byte("R-sr") // metadataSinkRecordPrefix + sequence number (uint64 big endian) -> MetadataSinkRecord
+	metadataSinkCursorKey             = []byte("R-sinkcursor")
+
+	// keys and key prefixes of the rollup event store, removed by DeleteRollupEventStore
+	rollupEventStoreKeys = [][]byte{
+		rollupEventSyncedL1BlockNumberKey, finalizedL2BlockNumberKey, enforcedBatchModeKey,
+		rollupSyncRecoveryKey, rollupSyncBatchPointersKey, l1FinalizedBlockKey, rollupScrollChainAddressKey,
+		rollupBatchGapsKey, metadataSinkCursorKey,
+	}
+	rollupEventStorePrefixes = [][]byte{
+		batchChunkRangesPrefix, batchMetaPrefix, batchL1MetaPrefix, batchEndBlockPrefix, batchL1BlockPrefix,
+		poisonedBatchPrefix, revertedBatchPrefix, rollupSyncCheckpointPrefix, l1EndpointRangePrefix,
+		batchSkippedL1MessagesPrefix, skippedL1MessagePrefix, metadataSinkRecordPrefix,
+	}
 
 	// Row consumption
 	rowConsumptionPrefix = []byte("rc") // rowConsumptionPrefix + hash -> row consumption by block
 
+	// Withdraw trie roots
+	withdrawRootPrefix         = []byte("wr") // withdrawRootPrefix + hash -> withdraw trie root after the block
+	withdrawMessagePrefix      = []byte("wm") // withdrawMessagePrefix + nonce (uint64 big endian) -> WithdrawMessage
+	withdrawMessageNoncePrefix = []byte("wn") // withdrawMessageNoncePrefix + message hash -> nonce
+	withdrawRootBackfillKey    = []byte("WithdrawRootBackfill")
+
+	// Shadow MPT state of the dual-trie mode
+	ShadowStatePrefix     = []byte("sm-") // ShadowStatePrefix + key -> trie node or code of the shadow MPT state
+	shadowStateRootPrefix = []byte("sr")  // shadowStateRootPrefix + hash -> root of the shadow MPT state after the block
+
+	// State migration between the zktrie and the MPT
+	stateMigrationKey       = []byte("StateMigration")
+	migratedStateRootPrefix = []byte("ms") // migratedStateRootPrefix + hash -> root of the migrated state after the block
+
 	// Skipped transactions
 	numSkippedTransactionsKey    = []byte("NumberOfSkippedTransactions")
 	skippedTransactionPrefix     = []byte("skip") // skippedTransactionPrefix + tx hash -> skipped transaction
@@ -275,6 +322,21 @@ func L1MessageKey(queueIndex uint64) []byte {
 	return append(l1MessagePrefix, encodeBigEndian(queueIndex)...)
 }
 
+// l1MessageFeeKey = l1MessageFeePrefix + queueIndex (uint64 big endian)
+func l1MessageFeeKey(queueIndex uint64) []byte {
+	return append(l1MessageFeePrefix, encodeBigEndian(queueIndex)...)
+}
+
+// l1MessageOriginKey = l1MessageOriginPrefix + queueIndex (uint64 big endian)
+func l1MessageOriginKey(queueIndex uint64) []byte {
+	return append(l1MessageOriginPrefix, encodeBigEndian(queueIndex)...)
+}
+
+// l1MessageInclusionKey = l1MessageInclusionPrefix + queueIndex (uint64 big endian)
+func l1MessageInclusionKey(queueIndex uint64) []byte {
+	return append(l1MessageInclusionPrefix, encodeBigEndian(queueIndex)...)
+}
+
 // FirstQueueIndexNotInL2BlockKey = firstQueueIndexNotInL2BlockPrefix + L2 block hash
 func FirstQueueIndexNotInL2BlockKey(l2BlockHash common.Hash) []byte {
 	return append(firstQueueIndexNotInL2BlockPrefix, l2BlockHash.Bytes()...)
@@ -285,6 +347,31 @@ func rowConsumptionKey(hash common.Hash) []byte {
 	return append(rowConsumptionPrefix, hash.Bytes()...)
 }
 
+// withdrawRootKey = withdrawRootPrefix + hash
+func withdrawRootKey(hash common.Hash) []byte {
+	return append(withdrawRootPrefix, hash.Bytes()...)
+}
+
+// shadowStateRootKey = shadowStateRootPrefix + hash
+func shadowStateRootKey(hash common.Hash) []byte {
+	return append(shadowStateRootPrefix, hash.Bytes()...)
+}
+
+// migratedStateRootKey = migratedStateRootPrefix + hash
+func migratedStateRootKey(hash common.Hash) []byte {
+	return append(migratedStateRootPrefix, hash.Bytes()...)
+}
+
+// withdrawMessageKey = withdrawMessagePrefix + nonce (uint64 big endian)
+func withdrawMessageKey(nonce uint64) []byte {
+	return append(withdrawMessagePrefix, encodeBigEndian(nonce)...)
+}
+
+// withdrawMessageNonceKey = withdrawMessageNoncePrefix + message hash
+func withdrawMessageNonceKey(messageHash common.Hash) []byte {
+	return append(withdrawMessageNoncePrefix, messageHash.Bytes()...)
+}
+
 func isNotFoundErr(err error) bool {
 	return errors.Is(err, leveldb.ErrNotFound) || errors.Is(err, memorydb.ErrMemorydbNotFound)
 }
@@ -308,3 +395,54 @@ func batchChunkRangesKey(batchIndex uint64) []byte {
 func batchMetaKey(batchIndex uint64) []byte {
 	return append(batchMetaPrefix, encodeBigEndian(batchIndex)...)
 }
+
+// batchL1MetaKey = batchL1MetaPrefix + batch index (uint64 big endian)
+func batchL1MetaKey(batchIndex uint64) []byte {
+	return append(batchL1MetaPrefix, encodeBigEndian(batchIndex)...)
+}
+
+// batchL1BlockKey = batchL1BlockPrefix + L1 block number (uint64 big endian) + event kind + batch index (uint64 big endian)
+func batchL1BlockKey(l1BlockNumber uint64, kind byte, batchIndex uint64) []byte {
+	key := append(append(batchL1BlockPrefix, encodeBigEndian(l1BlockNumber)...), kind)
+	return append(key, encodeBigEndian(batchIndex)...)
+}
+
+// batchEndBlockKey = batchEndBlockPrefix + L2 block number (uint64 big endian)
+func batchEndBlockKey(l2BlockNumber uint64) []byte {
+	return append(batchEndBlockPrefix, encodeBigEndian(l2BlockNumber)...)
+}
+
+// poisonedBatchKey = poisonedBatchPrefix + batch index (uint64 big endian)
+func poisonedBatchKey(batchIndex uint64) []byte {
+	return append(poisonedBatchPrefix, encodeBigEndian(batchIndex)...)
+}
+
+// revertedBatchKey = revertedBatchPrefix + batch index (uint64 big endian)
+func revertedBatchKey(batchIndex uint64) []byte {
+	return append(revertedBatchPrefix, encodeBigEndian(batchIndex)...)
+}
+
+// rollupSyncCheckpointKey = rollupSyncCheckpointPrefix + L1 block number (uint64 big endian)
+func rollupSyncCheckpointKey(l1BlockNumber uint64) []byte {
+	return append(rollupSyncCheckpointPrefix, encodeBigEndian(l1BlockNumber)...)
+}
+
+// batchSkippedL1MessagesKey = batchSkippedL1MessagesPrefix + batch index (uint64 big endian)
+func batchSkippedL1MessagesKey(batchIndex uint64) []byte {
+	return append(batchSkippedL1MessagesPrefix, encodeBigEndian(batchIndex)...)
+}
+
+// skippedL1MessageKey = skippedL1MessagePrefix + queue index (uint64 big endian)
+func skippedL1MessageKey(queueIndex uint64) []byte {
+	return append(skippedL1MessagePrefix, encodeBigEndian(queueIndex)...)
+}
+
+// l1EndpointRangeKey = l1EndpointRangePrefix + L1 block number (uint64 big endian)
+func l1EndpointRangeKey(l1BlockNumber uint64) []byte {
+	return append(l1EndpointRangePrefix, encodeBigEndian(l1BlockNumber)...)
+}
+
+// metadataSinkRecordKey = metadataSinkRecordPrefix + sequence number (uint64 big endian)
+func metadataSinkRecordKey(sequence uint64) []byte {
+	return append(metadataSinkRecordPrefix, encodeBigEndian(sequence)...)
+}
@@ -0,0 +1,67 @@
+package state
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+
+	"github.com/scroll-tech/go-ethereum/common"
+)
+
+// DiffLoadedState compares the accounts and the storage slots loaded by either of two states
+// with their values in the other state, and returns an error describing the first difference.
+// The states are compared by value, so that states backed by different kinds of tries, like
+// the zktrie and the MPT, are comparable. Accounts and slots loaded by neither state are not
+// compared.
+func DiffLoadedState(a, b *StateDB) error {
+	var (
+		addrs    []common.Address
+		slots    = make(map[common.Address]map[common.Hash]struct{})
+		addrSeen = make(map[common.Address]struct{})
+	)
+	for _, s := range []*StateDB{a, b} {
+		for addr, obj := range s.stateObjects {
+			if _, seen := addrSeen[addr]; !seen {
+				addrSeen[addr] = struct{}{}
+				addrs = append(addrs, addr)
+				slots[addr] = make(map[common.Hash]struct{})
+			}
+			for key := range obj.originStorage {
+				slots[addr][key] = struct{}{}
+			}
+			for key := range obj.pendingStorage {
+				slots[addr][key] = struct{}{}
+			}
+			for key := range obj.dirtyStorage {
+				slots[addr][key] = struct{}{}
+			}
+		}
+	}
+	sort.Slice(addrs, func(i, j int) bool { return bytes.Compare(addrs[i][:], addrs[j][:]) < 0 })
+
+	for _, addr := range addrs {
+		if exist := a.Exist(addr); exist != b.Exist(addr) {
+			return fmt.Errorf("account %v exists: %v, other: %v", addr, exist, !exist)
+		}
+		if nonce, other := a.GetNonce(addr), b.GetNonce(addr); nonce != other {
+			return fmt.Errorf("account %v nonce: %d, other: %d", addr, nonce, other)
+		}
+		if balance, other := a.GetBalance(addr), b.GetBalance(addr); balance.Cmp(other) != 0 {
+			return fmt.Errorf("account %v balance: %v, other: %v", addr, balance, other)
+		}
+		if codeHash, other := a.GetKeccakCodeHash(addr), b.GetKeccakCodeHash(addr); codeHash != other {
+			return fmt.Errorf("account %v code hash: %v, other: %v", addr, codeHash, other)
+		}
+		keys := make([]common.Hash, 0, len(slots[addr]))
+		for key := range slots[addr] {
+			keys = append(keys, key)
+		}
+		sort.Slice(keys, func(i, j int) bool { return bytes.Compare(keys[i][:], keys[j][:]) < 0 })
+		for _, key := range keys {
+			if value, other := a.GetState(addr, key), b.GetState(addr, key); value != other {
+				return fmt.Errorf("account %v slot %v: %v, other: %v", addr, key, value, other)
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,274 @@
+package migrator
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/scroll-tech/go-ethereum/common"
+	"github.com/scroll-tech/go-ethereum/core/rawdb"
+	"github.com/scroll-tech/go-ethereum/core/state"
+	"github.com/scroll-tech/go-ethereum/core/types"
+	"github.com/scroll-tech/go-ethereum/crypto"
+	"github.com/scroll-tech/go-ethereum/crypto/codehash"
+	"github.com/scroll-tech/go-ethereum/ethdb"
+	"github.com/scroll-tech/go-ethereum/log"
+	"github.com/scroll-tech/go-ethereum/rlp"
+	"github.com/scroll-tech/go-ethereum/trie"
+)
+
+// checkpointAccounts is the number of accounts migrated between two checkpoints of the
+// migration progress.
+const checkpointAccounts = 10000
+
+var (
+	// emptyRoot is the known root hash of an empty trie.
+	emptyRoot = common.HexToHash("56e81f171bcc55a6ff8345e692c0f86e5b48e01b996cadc001622fb5e363b421")
+
+	// ErrInterrupted is returned if the migration is interrupted before it finishes. The
+	// progress is persisted, and a later migration of the same block resumes from it.
+	ErrInterrupted = errors.New("state migration interrupted")
+)
+
+// Progress is the persisted progress of the migration of the state after a block.
+type Progress struct {
+	Number     uint64      // number of the block whose state is migrated
+	Hash       common.Hash // hash of the block whose state is migrated
+	Root       common.Hash // root of the migrated state in the source trie
+	Accounts   uint64      // number of accounts migrated so far, in the iteration order of the source trie
+	TargetRoot common.Hash // root of the accounts migrated so far in the target trie
+}
+
+// ReadProgress retrieves the progress of the last state migration, nil if there is none.
+func ReadProgress(db ethdb.KeyValueReader) (*Progress, error) {
+	blob := rawdb.ReadStateMigrationProgress(db)
+	if len(blob) == 0 {
+		return nil, nil
+	}
+	progress := new(Progress)
+	if err := rlp.DecodeBytes(blob, progress); err != nil {
+		return nil, err
+	}
+	return progress, nil
+}
+
+func writeProgress(db ethdb.KeyValueWriter, progress *Progress) error {
+	blob, err := rlp.EncodeToBytes(progress)
+	if err != nil {
+		return err
+	}
+	rawdb.WriteStateMigrationProgress(db, blob)
+	return nil
+}
+
+// Migrator copies the state after a block from the zktrie into the MPT, or the other way
+// around. The target trie is rebuilt account by account from the leaves of the source trie,
+// which both live in the same database: the nodes of the two tries are keyed by different
+// hashes, and the codes and preimages are shared between them. The keys of the source trie
+// are resolved through their preimages, so both state databases are expected to record
+// preimages.
+//
+// The source trie is walked leaf by leaf, the zktrie with ZkTrie.IterateLeaves and the MPT
+// with a node iterator, rather than through the snapshot, which zktrie chains might not
+// have. Every node of the source state is therefore read from the database once.
+//
+// The progress is checkpointed regularly, so that an interrupted migration resumes where
+// it was left, and the migrated state root of the block is stored once it is done.
+type Migrator struct {
+	db     ethdb.Database
+	source state.Database
+	target state.Database
+}
+
+// NewMigrator creates a migrator copying the states of source into target.
+func NewMigrator(db ethdb.Database, source, target state.Database) *Migrator {
+	return &Migrator{
+		db:     db,
+		source: source,
+		target: target,
+	}
+}
+
+// Migrate copies the state after the given block into the target trie and returns its
+// root, resuming the previous migration of the same block. The migration stops with
+// ErrInterrupted once quit is closed.
+func (m *Migrator) Migrate(header *types.Header, quit <-chan struct{}) (common.Hash, error) {
+	if root := rawdb.ReadMigratedStateRoot(m.db, header.Hash()); root != nil {
+		return *root, nil
+	}
+	progress, err := ReadProgress(m.db)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	if progress != nil && progress.Hash != header.Hash() {
+		log.Warn("Discarding the progress of the state migration of another block", "number", progress.Number, "hash", progress.Hash, "accounts", progress.Accounts)
+		progress = nil
+	}
+	if progress == nil {
+		progress = &Progress{Number: header.Number.Uint64(), Hash: header.Hash(), Root: header.Root}
+	} else {
+		log.Info("Resuming state migration", "number", progress.Number, "hash", progress.Hash, "accounts", progress.Accounts)
+	}
+	statedb, err := state.New(progress.TargetRoot, m.target, nil)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	var (
+		skip   = progress.Accounts
+		start  = time.Now()
+		logged = time.Now()
+	)
+	err = m.forEachAccount(progress.Root, func(addr common.Address, account *types.StateAccount) error {
+		if skip > 0 {
+			skip--
+			return nil
+		}
+		select {
+		case <-quit:
+			return ErrInterrupted
+		default:
+		}
+		if err := m.copyAccount(statedb, addr, account); err != nil {
+			return fmt.Errorf("account %v: %w", addr, err)
+		}
+		progress.Accounts++
+		if progress.Accounts%checkpointAccounts == 0 {
+			if err := m.checkpoint(statedb, progress); err != nil {
+				return err
+			}
+			if statedb, err = state.New(progress.TargetRoot, m.target, nil); err != nil {
+				return err
+			}
+		}
+		if time.Since(logged) > 8*time.Second {
+			log.Info("Migrating state", "number", progress.Number, "accounts", progress.Accounts, "elapsed", common.PrettyDuration(time.Since(start)))
+			logged = time.Now()
+		}
+		return nil
+	})
+	if errors.Is(err, ErrInterrupted) {
+		if err := m.checkpoint(statedb, progress); err != nil {
+			return common.Hash{}, err
+		}
+		log.Info("Interrupted state migration", "number", progress.Number, "accounts", progress.Accounts, "elapsed", common.PrettyDuration(time.Since(start)))
+		return common.Hash{}, ErrInterrupted
+	}
+	if err != nil {
+		return common.Hash{}, err
+	}
+	if err := m.checkpoint(statedb, progress); err != nil {
+		return common.Hash{}, err
+	}
+	rawdb.WriteMigratedStateRoot(m.db, progress.Hash, progress.TargetRoot)
+	log.Info("Migrated state", "number", progress.Number, "hash", progress.Hash, "root", progress.Root, "migrated", progress.TargetRoot, "accounts", progress.Accounts, "elapsed", common.PrettyDuration(time.Since(start)))
+	return progress.TargetRoot, nil
+}
+
+// checkpoint writes the accounts migrated so far to the disk and persists the progress.
+func (m *Migrator) checkpoint(statedb *state.StateDB, progress *Progress) error {
+	// the empty accounts of the source state are kept
+	root, err := statedb.Commit(false)
+	if err != nil {
+		return err
+	}
+	if err := m.target.TrieDB().Commit(root, false, nil); err != nil {
+		return err
+	}
+	progress.TargetRoot = root
+	return writeProgress(m.db, progress)
+}
+
+// copyAccount copies an account of the source state, including its code and storage, into
+// the target state.
+func (m *Migrator) copyAccount(statedb *state.StateDB, addr common.Address, account *types.StateAccount) error {
+	statedb.SetNonce(addr, account.Nonce)
+	statedb.SetBalance(addr, account.Balance)
+	if codeHash := common.BytesToHash(account.KeccakCodeHash); codeHash != codehash.EmptyKeccakCodeHash {
+		code, err := m.source.ContractCode(crypto.Keccak256Hash(addr.Bytes()), codeHash)
+		if err != nil {
+			return fmt.Errorf("code: %w", err)
+		}
+		statedb.SetCode(addr, code)
+	}
+	return m.forEachSlot(addr, account.Root, func(key, value common.Hash) error {
+		statedb.SetState(addr, key, value)
+		return nil
+	})
+}
+
+// forEachAccount calls fn with the address and the account of every account of the source
+// state with the given root, in the iteration order of the source trie.
+func (m *Migrator) forEachAccount(root common.Hash, fn func(addr common.Address, account *types.StateAccount) error) error {
+	tr, err := m.source.OpenTrie(root)
+	if err != nil {
+		return err
+	}
+	switch tr := tr.(type) {
+	case *trie.ZkTrie:
+		return tr.IterateLeaves(func(key, value []byte) error {
+			account, err := types.UnmarshalStateAccount(value)
+			if err != nil {
+				return err
+			}
+			return fn(common.BytesToAddress(key), account)
+		})
+	case *trie.SecureTrie:
+		it := trie.NewIterator(tr.NodeIterator(nil))
+		for it.Next() {
+			key := tr.GetKey(it.Key)
+			if key == nil {
+				return fmt.Errorf("missing preimage of key %x", it.Key)
+			}
+			account := new(types.StateAccount)
+			if err := rlp.DecodeBytes(it.Value, account); err != nil {
+				return err
+			}
+			if err := fn(common.BytesToAddress(key), account); err != nil {
+				return err
+			}
+		}
+		return it.Err
+	default:
+		return fmt.Errorf("unexpected trie type %T", tr)
+	}
+}
+
+// forEachSlot calls fn with the key and the value of every storage slot of an account of
+// the source state.
+func (m *Migrator) forEachSlot(addr common.Address, root common.Hash, fn func(key, value common.Hash) error) error {
+	if root == (common.Hash{}) || root == emptyRoot {
+		return nil
+	}
+	tr, err := m.source.OpenStorageTrie(crypto.Keccak256Hash(addr.Bytes()), root)
+	if err != nil {
+		return fmt.Errorf("storage: %w", err)
+	}
+	switch tr := tr.(type) {
+	case *trie.ZkTrie:
+		err = tr.IterateLeaves(func(key, value []byte) error {
+			return fn(common.BytesToHash(key), common.BytesToHash(value))
+		})
+	case *trie.SecureTrie:
+		it := trie.NewIterator(tr.NodeIterator(nil))
+		for it.Next() && err == nil {
+			key := tr.GetKey(it.Key)
+			if key == nil {
+				err = fmt.Errorf("missing preimage of key %x", it.Key)
+				break
+			}
+			var content []byte
+			if _, content, _, err = rlp.Split(it.Value); err == nil {
+				err = fn(common.BytesToHash(key), common.BytesToHash(content))
+			}
+		}
+		if err == nil {
+			err = it.Err
+		}
+	default:
+		err = fmt.Errorf("unexpected trie type %T", tr)
+	}
+	if err != nil {
+		return fmt.Errorf("storage: %w", err)
+	}
+	return nil
+}
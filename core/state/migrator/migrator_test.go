@@ -0,0 +1,97 @@
+package migrator
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/scroll-tech/go-ethereum/common"
+	"github.com/scroll-tech/go-ethereum/core/rawdb"
+	"github.com/scroll-tech/go-ethereum/core/state"
+	"github.com/scroll-tech/go-ethereum/core/types"
+	"github.com/scroll-tech/go-ethereum/ethdb"
+	"github.com/scroll-tech/go-ethereum/trie"
+)
+
+// makeState commits a state with some accounts, codes and storage slots.
+func makeState(t *testing.T, db ethdb.Database, zktrie bool) (state.Database, common.Hash) {
+	sdb := state.NewDatabaseWithConfig(db, &trie.Config{Zktrie: zktrie, Preimages: true})
+	statedb, err := state.New(common.Hash{}, sdb, nil)
+	if err != nil {
+		t.Fatalf("failed to create state: %v", err)
+	}
+	for i := byte(1); i <= 20; i++ {
+		addr := common.BytesToAddress([]byte{i})
+		statedb.SetBalance(addr, big.NewInt(int64(i)*1000))
+		statedb.SetNonce(addr, uint64(i))
+		if i%3 == 0 {
+			statedb.SetCode(addr, []byte{i, 0x60, 0x00})
+			for j := byte(1); j <= i; j++ {
+				statedb.SetState(addr, common.BytesToHash([]byte{j}), common.BytesToHash([]byte{i, j}))
+			}
+		}
+	}
+	// an empty account
+	statedb.CreateAccount(common.BytesToAddress([]byte{0xff}))
+	root, err := statedb.Commit(false)
+	if err != nil {
+		t.Fatalf("failed to commit state: %v", err)
+	}
+	if err := sdb.TrieDB().Commit(root, false, nil); err != nil {
+		t.Fatalf("failed to commit trie: %v", err)
+	}
+	return sdb, root
+}
+
+func testMigrate(t *testing.T, fromZktrie bool) {
+	db := rawdb.NewMemoryDatabase()
+	source, root := makeState(t, db, fromZktrie)
+	_, expected := makeState(t, rawdb.NewMemoryDatabase(), !fromZktrie)
+
+	target := state.NewDatabaseWithConfig(db, &trie.Config{Zktrie: !fromZktrie, Preimages: true})
+	header := &types.Header{Number: big.NewInt(7), Root: root}
+	migrator := NewMigrator(db, source, target)
+
+	// an interrupted migration is resumed
+	quit := make(chan struct{})
+	close(quit)
+	if _, err := migrator.Migrate(header, quit); err != ErrInterrupted {
+		t.Fatalf("expected interruption, got %v", err)
+	}
+	progress, err := ReadProgress(db)
+	if err != nil || progress == nil || progress.Hash != header.Hash() {
+		t.Fatalf("unexpected progress %v, err %v", progress, err)
+	}
+	migrated, err := migrator.Migrate(header, nil)
+	if err != nil {
+		t.Fatalf("failed to migrate state: %v", err)
+	}
+	if migrated != expected {
+		t.Fatalf("migrated root mismatch, expected %v, got %v", expected, migrated)
+	}
+	if got := rawdb.ReadMigratedStateRoot(db, header.Hash()); got == nil || *got != expected {
+		t.Fatalf("stored migrated root mismatch, expected %v, got %v", expected, got)
+	}
+
+	// the migrated state is readable from the target trie, and can be migrated back
+	statedb, err := state.New(migrated, target, nil)
+	if err != nil {
+		t.Fatalf("failed to open migrated state: %v", err)
+	}
+	addr := common.BytesToAddress([]byte{9})
+	if balance := statedb.GetBalance(addr); balance.Cmp(big.NewInt(9000)) != 0 {
+		t.Fatalf("balance mismatch, got %v", balance)
+	}
+	if value := statedb.GetState(addr, common.BytesToHash([]byte{4})); value != common.BytesToHash([]byte{9, 4}) {
+		t.Fatalf("storage mismatch, got %v", value)
+	}
+	back, err := NewMigrator(db, target, source).Migrate(&types.Header{Number: big.NewInt(8), Root: migrated}, nil)
+	if err != nil {
+		t.Fatalf("failed to migrate state back: %v", err)
+	}
+	if back != root {
+		t.Fatalf("root mismatch after migrating back, expected %v, got %v", root, back)
+	}
+}
+
+func TestMigrateFromZktrie(t *testing.T) { testMigrate(t, true) }
+func TestMigrateFromMPT(t *testing.T)    { testMigrate(t, false) }
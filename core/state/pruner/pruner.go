@@ -83,13 +83,32 @@ type Pruner struct {
 	snaptree      *snapshot.Tree
 }
 
+// prunableTrieDatabase opens the trie database of the state of the head block. The pruning
+// of zktrie states is not supported: the state bloom filter holds the hashes of the nodes
+// of Merkle Patricia tries only, which would leave the genesis state and the states before
+// a state scheme switch unprotected.
+func prunableTrieDatabase(db ethdb.Database, headBlock *types.Block) (*trie.Database, error) {
+	config := rawdb.ReadChainConfig(db, rawdb.ReadCanonicalHash(db, 0))
+	if config == nil {
+		return nil, errors.New("Failed to load chain config")
+	}
+	if config.Scroll.ZktrieEnabledAt(common.Big0) || config.Scroll.ZktrieEnabledAt(headBlock.Number()) {
+		return nil, errors.New("pruning of zktrie states is not supported")
+	}
+	return trie.NewDatabase(db), nil
+}
+
 // NewPruner creates the pruner instance.
 func NewPruner(db ethdb.Database, datadir, trieCachePath string, bloomSize uint64) (*Pruner, error) {
 	headBlock := rawdb.ReadHeadBlock(db)
 	if headBlock == nil {
 		return nil, errors.New("Failed to load head block")
 	}
-	snaptree, err := snapshot.New(db, trie.NewDatabase(db), 256, headBlock.Root(), false, false, false)
+	triedb, err := prunableTrieDatabase(db, headBlock)
+	if err != nil {
+		return nil, err
+	}
+	snaptree, err := snapshot.New(db, triedb, 256, headBlock.Root(), false, false, false)
 	if err != nil {
 		return nil, err // The relevant snapshot(s) might not exist
 	}
@@ -362,7 +381,11 @@ func RecoverPruning(datadir string, db ethdb.Database, trieCachePath string) err
 	// - The state HEAD is rewound already because of multiple incomplete `prune-state`
 	// In this case, even the state HEAD is not exactly matched with snapshot, it
 	// still feasible to recover the pruning correctly.
-	snaptree, err := snapshot.New(db, trie.NewDatabase(db), 256, headBlock.Root(), false, false, true)
+	triedb, err := prunableTrieDatabase(db, headBlock)
+	if err != nil {
+		return err
+	}
+	snaptree, err := snapshot.New(db, triedb, 256, headBlock.Root(), false, false, true)
 	if err != nil {
 		return err // The relevant snapshot(s) might not exist
 	}
@@ -21,6 +21,7 @@ import (
 	"math/big"
 
 	"github.com/scroll-tech/go-ethereum/common"
+	"github.com/scroll-tech/go-ethereum/core/types"
 	"github.com/scroll-tech/go-ethereum/rlp"
 )
 
@@ -90,3 +91,12 @@ func FullAccountRLP(data []byte) ([]byte, error) {
 	}
 	return rlp.EncodeToBytes(account)
 }
+
+// zkAccountRLP converts an account in the zktrie encoding into the full RLP-format.
+func zkAccountRLP(blob []byte) ([]byte, error) {
+	account, err := types.UnmarshalStateAccount(blob)
+	if err != nil {
+		return nil, err
+	}
+	return rlp.EncodeToBytes(account)
+}
@@ -62,6 +62,10 @@ var (
 	// errMissingTrie is returned if the target trie is missing while the generation
 	// is running. In this case the generation is aborted and wait the new signal.
 	errMissingTrie = errors.New("missing trie")
+
+	// errZktrieRange is the proof error of the ranges of a zktrie snapshot, which can't
+	// be proven and are always checked against the trie instead.
+	errZktrieRange = errors.New("zktrie range proofs are not supported")
 )
 
 // Metrics in generation
@@ -298,6 +302,14 @@ func (dl *diskLayer) proveRange(stats *generatorStats, root common.Hash, prefix
 	} else {
 		snapAccountSnapReadCounter.Inc(time.Since(start).Nanoseconds())
 	}
+	if dl.triedb.Zktrie {
+		return &proofResult{
+			keys:     keys,
+			vals:     vals,
+			diskMore: diskMore,
+			proofErr: errZktrieRange,
+		}, nil
+	}
 	defer func(start time.Time) {
 		if kind == "storage" {
 			snapStorageProveCounter.Inc(time.Since(start).Nanoseconds())
@@ -412,8 +424,10 @@ func (dl *diskLayer) generateRange(root common.Hash, prefix []byte, kind string,
 		// Only abort the iteration when both database and trie are exhausted
 		return !result.diskMore && !result.trieMore, last, nil
 	}
-	logger.Trace("Detected outdated state range", "last", hexutil.Encode(last), "err", result.proofErr)
-	snapFailedRangeProofMeter.Mark(1)
+	if result.proofErr != errZktrieRange {
+		logger.Trace("Detected outdated state range", "last", hexutil.Encode(last), "err", result.proofErr)
+		snapFailedRangeProofMeter.Mark(1)
+	}
 
 	// Special case, the entire trie is missing. In the original trie scheme,
 	// all the duplicated subtries will be filter out(only one copy of data
@@ -431,7 +445,7 @@ func (dl *diskLayer) generateRange(root common.Hash, prefix []byte, kind string,
 	// We use the snap data to build up a cache which can be used by the
 	// main account trie as a primary lookup when resolving hashes
 	var snapNodeCache ethdb.KeyValueStore
-	if len(result.keys) > 0 {
+	if len(result.keys) > 0 && !dl.triedb.Zktrie {
 		snapNodeCache = memorydb.New()
 		snapTrieDb := trie.NewDatabase(snapNodeCache)
 		snapTrie, _ := trie.New(common.Hash{}, snapTrieDb)
@@ -441,13 +455,12 @@ func (dl *diskLayer) generateRange(root common.Hash, prefix []byte, kind string,
 		root, _, _ := snapTrie.Commit(nil)
 		snapTrieDb.Commit(root, false, nil)
 	}
-	tr := result.tr
-	if tr == nil {
-		tr, err = trie.New(root, dl.triedb)
-		if err != nil {
-			stats.Log("Trie missing, state snapshotting paused", dl.root, dl.genMarker)
-			return false, nil, errMissingTrie
-		}
+	var tr iterableTrie
+	if result.tr != nil {
+		tr = result.tr
+	} else if tr, err = dl.openTrie(root); err != nil {
+		stats.Log("Trie missing, state snapshotting paused", dl.root, dl.genMarker)
+		return false, nil, errMissingTrie
 	}
 
 	var (
@@ -474,6 +487,10 @@ func (dl *diskLayer) generateRange(root common.Hash, prefix []byte, kind string,
 			break
 		}
 		count++
+		value, err := dl.trieValue(kind, iter.Value)
+		if err != nil {
+			return false, nil, err
+		}
 		write := true
 		created++
 		for len(kvkeys) > 0 {
@@ -491,7 +508,7 @@ func (dl *diskLayer) generateRange(root common.Hash, prefix []byte, kind string,
 			} else if cmp == 0 {
 				// the snapshot key can be overwritten
 				created--
-				if write = !bytes.Equal(kvvals[0], iter.Value); write {
+				if write = !bytes.Equal(kvvals[0], value); write {
 					updated++
 				} else {
 					untouched++
@@ -502,7 +519,7 @@ func (dl *diskLayer) generateRange(root common.Hash, prefix []byte, kind string,
 			break
 		}
 		istart := time.Now()
-		if err := onState(iter.Key, iter.Value, write, false); err != nil {
+		if err := onState(iter.Key, value, write, false); err != nil {
 			return false, nil, err
 		}
 		internal += time.Since(istart)
@@ -534,6 +551,31 @@ func (dl *diskLayer) generateRange(root common.Hash, prefix []byte, kind string,
 	return !trieMore && !result.diskMore, last, nil
 }
 
+// iterableTrie is a trie whose nodes can be iterated, either a Merkle Patricia trie or a
+// zktrie.
+type iterableTrie interface {
+	NodeIterator(start []byte) trie.NodeIterator
+}
+
+// openTrie opens the account or storage trie with the given root, in the trie scheme of
+// the snapshot.
+func (dl *diskLayer) openTrie(root common.Hash) (iterableTrie, error) {
+	if dl.triedb.Zktrie {
+		return trie.NewZkTrie(root, trie.NewZktrieDatabaseFromTriedb(dl.triedb))
+	}
+	return trie.New(root, dl.triedb)
+}
+
+// trieValue converts the value of a trie leaf into its canonical encoding, the one of the
+// Merkle Patricia trie. The zktrie encodes accounts differently, while the encoding of the
+// storage slots is the one of the snapshot for both tries.
+func (dl *diskLayer) trieValue(kind string, blob []byte) ([]byte, error) {
+	if !dl.triedb.Zktrie || kind != "account" {
+		return blob, nil
+	}
+	return zkAccountRLP(blob)
+}
+
 // generate is a background thread that iterates over the state and storage tries,
 // constructing the state snapshot. All the arguments are purely for statistics
 // gathering and logging, since the method surfs the blocks as they arrive, often
@@ -657,7 +699,7 @@ func (dl *diskLayer) generate(stats *generatorStats) {
 		}
 		// If the iterated account is the contract, create a further loop to
 		// verify or regenerate the contract storage.
-		if acc.Root == emptyRoot {
+		if acc.Root == emptyRoot || acc.Root == (common.Hash{}) {
 			// If the root is empty, we still need to ensure that any previous snapshot
 			// storage values are cleared
 			// TODO: investigate if this can be avoided, this will be very costly since it
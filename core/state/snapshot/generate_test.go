@@ -17,6 +17,7 @@
 package snapshot
 
 import (
+	"bytes"
 	"fmt"
 	"math/big"
 	"os"
@@ -27,6 +28,7 @@ import (
 
 	"github.com/scroll-tech/go-ethereum/common"
 	"github.com/scroll-tech/go-ethereum/core/rawdb"
+	"github.com/scroll-tech/go-ethereum/core/types"
 	"github.com/scroll-tech/go-ethereum/ethdb"
 	"github.com/scroll-tech/go-ethereum/ethdb/memorydb"
 	"github.com/scroll-tech/go-ethereum/log"
@@ -82,6 +84,87 @@ func TestGeneration(t *testing.T) {
 	<-stop
 }
 
+// Tests that snapshot generation of a zktrie state keys the flat state by the leaf keys of
+// the trie, and repairs an existent outdated flat state.
+func TestGenerateZktrie(t *testing.T) {
+	var (
+		diskdb = memorydb.New()
+		triedb = trie.NewDatabaseWithConfig(diskdb, &trie.Config{Zktrie: true})
+		zkdb   = trie.NewZktrieDatabaseFromTriedb(triedb)
+	)
+	leafKey := func(key []byte) common.Hash {
+		hash, err := trie.ZkLeafKey(key)
+		if err != nil {
+			t.Fatalf("failed to compute leaf key: %v", err)
+		}
+		return hash
+	}
+	stTrie, _ := trie.NewZkTrie(common.Hash{}, zkdb)
+	for i := byte(1); i <= 3; i++ {
+		stTrie.Update(common.BytesToHash([]byte{i}).Bytes(), common.BytesToHash([]byte{i, i}).Bytes())
+	}
+	accTrie, _ := trie.NewZkTrie(common.Hash{}, zkdb)
+	accounts := make(map[common.Address]*types.StateAccount)
+	for i := byte(1); i <= 10; i++ {
+		acc := &types.StateAccount{Nonce: uint64(i), Balance: big.NewInt(int64(i)), KeccakCodeHash: emptyKeccakCode.Bytes(), PoseidonCodeHash: emptyPoseidonCode.Bytes()}
+		if i%2 == 0 {
+			acc.Root = stTrie.Hash()
+		}
+		addr := common.BytesToAddress([]byte{i})
+		if err := accTrie.TryUpdateAccount(addr.Bytes(), acc); err != nil {
+			t.Fatalf("failed to update account: %v", err)
+		}
+		accounts[addr] = acc
+	}
+	root := accTrie.Hash()
+
+	// an outdated account, a deleted account and a deleted slot in the flat state
+	outdated, stale := common.BytesToAddress([]byte{1}), common.BytesToAddress([]byte{0xff})
+	rawdb.WriteAccountSnapshot(diskdb, leafKey(outdated.Bytes()), SlimAccountRLP(0, big.NewInt(100), common.Hash{}, emptyKeccakCode.Bytes(), emptyPoseidonCode.Bytes(), 0))
+	rawdb.WriteAccountSnapshot(diskdb, leafKey(stale.Bytes()), SlimAccountRLP(0, big.NewInt(100), common.Hash{}, emptyKeccakCode.Bytes(), emptyPoseidonCode.Bytes(), 0))
+	contract := leafKey(common.BytesToAddress([]byte{2}).Bytes())
+	rawdb.WriteStorageSnapshot(diskdb, contract, leafKey(common.BytesToHash([]byte{4}).Bytes()), common.BytesToHash([]byte{4, 4}).Bytes())
+
+	snap := generateSnapshot(diskdb, triedb, 16, root)
+	select {
+	case <-snap.genPending:
+		// Snapshot generation succeeded
+
+	case <-time.After(3 * time.Second):
+		t.Fatalf("Snapshot generation failed")
+	}
+	for addr, acc := range accounts {
+		want := SlimAccountRLP(acc.Nonce, acc.Balance, acc.Root, acc.KeccakCodeHash, acc.PoseidonCodeHash, acc.CodeSize)
+		if have := rawdb.ReadAccountSnapshot(diskdb, leafKey(addr.Bytes())); !bytes.Equal(have, want) {
+			t.Fatalf("account %x: have %#x want %#x", addr, have, want)
+		}
+	}
+	if blob := rawdb.ReadAccountSnapshot(diskdb, leafKey(stale.Bytes())); len(blob) != 0 {
+		t.Fatalf("deleted account not wiped: %#x", blob)
+	}
+	for i := byte(1); i <= 4; i++ {
+		want := common.BytesToHash([]byte{i, i}).Bytes()
+		if i == 4 {
+			want = nil
+		}
+		if have := rawdb.ReadStorageSnapshot(diskdb, contract, leafKey(common.BytesToHash([]byte{i}).Bytes())); !bytes.Equal(have, want) {
+			t.Fatalf("slot %d: have %#x want %#x", i, have, want)
+		}
+	}
+	tree := &Tree{diskdb: diskdb, triedb: triedb, layers: map[common.Hash]snapshot{root: snap}}
+	if err := tree.Verify(root); err != nil {
+		t.Fatalf("failed to verify snapshot: %v", err)
+	}
+	rawdb.WriteStorageSnapshot(diskdb, contract, leafKey(common.BytesToHash([]byte{4}).Bytes()), common.BytesToHash([]byte{4, 4}).Bytes())
+	if err := tree.Verify(root); err == nil {
+		t.Fatalf("verified snapshot with a deleted slot")
+	}
+	// Signal abortion to the generator and wait for it to tear down
+	stop := make(chan *generatorStats)
+	snap.genAbort <- stop
+	<-stop
+}
+
 func hashData(input []byte) common.Hash {
 	var hasher = sha3.NewLegacyKeccak256()
 	var hash common.Hash
@@ -185,9 +185,6 @@ type Tree struct {
 //   a background thread.
 func New(diskdb ethdb.KeyValueStore, triedb *trie.Database, cache int, root common.Hash, async bool, rebuild bool, recovery bool) (*Tree, error) {
 	// Create a new, empty snapshot tree
-	if triedb.Zktrie {
-		panic("zktrie does not support snapshot yet")
-	}
 	snap := &Tree{
 		diskdb: diskdb,
 		triedb: triedb,
@@ -697,6 +694,21 @@ func (t *Tree) Rebuild(root common.Hash) {
 	t.lock.Lock()
 	defer t.lock.Unlock()
 
+	t.rebuild(root)
+}
+
+// Reset wipes all snapshot data like Rebuild, and starts a new snapshot generator with
+// the given root hash of another trie database, e.g. after the state scheme of the chain
+// was switched.
+func (t *Tree) Reset(triedb *trie.Database, root common.Hash) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	t.triedb = triedb
+	t.rebuild(root)
+}
+
+func (t *Tree) rebuild(root common.Hash) {
 	// Firstly delete any recovery flag in the database. Because now we are
 	// building a brand new snapshot. Also reenable the snapshot feature.
 	rawdb.DeleteSnapshotRecoveryNumber(t.diskdb)
@@ -770,6 +782,10 @@ func (t *Tree) Verify(root common.Hash) error {
 	}
 	defer acctIt.Release()
 
+	if t.triedb.Zktrie {
+		return t.verifyZktrie(root, acctIt)
+	}
+
 	got, err := generateTrieRoot(nil, acctIt, common.Hash{}, stackTrieGenerate, func(db ethdb.KeyValueWriter, accountHash, codeHash common.Hash, stat *generateStats) (common.Hash, error) {
 		storageIt, err := t.StorageIterator(root, accountHash, common.Hash{})
 		if err != nil {
@@ -793,6 +809,86 @@ func (t *Tree) Verify(root common.Hash) error {
 	return nil
 }
 
+// verifyZktrie compares the whole state with the specific root against the zktrie. The
+// root of a zktrie can't be recomputed from the snapshot, which lacks the preimages of the
+// keys, but the snapshot entries are ordered like the leaves of the trie.
+func (t *Tree) verifyZktrie(root common.Hash, acctIt AccountIterator) error {
+	db := trie.NewZktrieDatabaseFromTriedb(t.triedb)
+	accTrie, err := trie.NewZkTrie(root, db)
+	if err != nil {
+		return err
+	}
+	var accounts, slots int
+	err = compareZkLeaves(acctIt, func() ([]byte, error) { return FullAccountRLP(acctIt.Account()) }, accTrie.NodeIterator(nil), zkAccountRLP, func(accountHash common.Hash, blob []byte) error {
+		accounts++
+
+		var acc Account
+		if err := rlp.DecodeBytes(blob, &acc); err != nil {
+			return err
+		}
+		storageTrie, err := trie.NewZkTrie(common.BytesToHash(acc.Root), db)
+		if err != nil {
+			return err
+		}
+		storageIt, err := t.StorageIterator(root, accountHash, common.Hash{})
+		if err != nil {
+			return err
+		}
+		defer storageIt.Release()
+
+		return compareZkLeaves(storageIt, func() ([]byte, error) { return storageIt.Slot(), nil }, storageTrie.NodeIterator(nil), nil, func(common.Hash, []byte) error {
+			slots++
+			return nil
+		})
+	})
+	if err != nil {
+		return err
+	}
+	log.Info("Verified zktrie state snapshot", "root", root, "accounts", accounts, "slots", slots)
+	return nil
+}
+
+// compareZkLeaves steps over a snapshot iterator and the leaves of a zktrie together, and
+// checks that they hold the same entries. The values of the trie leaves are converted by
+// convert if set, onEntry is called with every entry after its check.
+func compareZkLeaves(it Iterator, value func() ([]byte, error), nodeIt trie.NodeIterator, convert func([]byte) ([]byte, error), onEntry func(hash common.Hash, blob []byte) error) error {
+	leaves := trie.NewIterator(nodeIt)
+	for it.Next() {
+		if !leaves.Next() {
+			if leaves.Err != nil {
+				return leaves.Err
+			}
+			return fmt.Errorf("snapshot entry %#x missing from the trie", it.Hash())
+		}
+		if hash := common.BytesToHash(leaves.Key); hash != it.Hash() {
+			return fmt.Errorf("snapshot entry mismatch: got %#x, want %#x", it.Hash(), hash)
+		}
+		have, err := value()
+		if err != nil {
+			return err
+		}
+		want := leaves.Value
+		if convert != nil {
+			if want, err = convert(want); err != nil {
+				return err
+			}
+		}
+		if !bytes.Equal(have, want) {
+			return fmt.Errorf("snapshot value mismatch of %#x: got %#x, want %#x", it.Hash(), have, want)
+		}
+		if err := onEntry(it.Hash(), have); err != nil {
+			return err
+		}
+	}
+	if err := it.Error(); err != nil {
+		return err
+	}
+	if leaves.Next() {
+		return fmt.Errorf("trie leaf %#x missing from the snapshot", leaves.Key)
+	}
+	return leaves.Err
+}
+
 // disklayer is an internal helper function to return the disk layer.
 // The lock of snapTree is assumed to be held already.
 func (t *Tree) disklayer() *diskLayer {
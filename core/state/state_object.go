@@ -68,6 +68,7 @@ func (s Storage) Copy() Storage {
 type stateObject struct {
 	address  common.Address
 	addrHash common.Hash // hash of ethereum address of the account
+	snapHash common.Hash // key of the account in the state snapshot, set if the snapshot is used
 	data     types.StateAccount
 	db       *StateDB
 
@@ -114,7 +115,7 @@ func newObject(db *StateDB, address common.Address, data types.StateAccount) *st
 	if data.Root == (common.Hash{}) {
 		data.Root = db.db.TrieDB().EmptyRoot()
 	}
-	return &stateObject{
+	obj := &stateObject{
 		db:             db,
 		address:        address,
 		addrHash:       crypto.Keccak256Hash(address[:]),
@@ -123,6 +124,13 @@ func newObject(db *StateDB, address common.Address, data types.StateAccount) *st
 		pendingStorage: make(Storage),
 		dirtyStorage:   make(Storage),
 	}
+	if db.snap != nil {
+		obj.snapHash = obj.addrHash
+		if db.IsZktrie() {
+			obj.snapHash = db.snapAccountHash(address)
+		}
+	}
+	return obj
 }
 
 // EncodeRLP implements rlp.Encoder.
@@ -228,10 +236,10 @@ func (s *stateObject) GetCommittedState(db Database, key common.Hash) common.Has
 		//   1) resurrect happened, and new slot values were set -- those should
 		//      have been handles via pendingStorage above.
 		//   2) we don't have new values, and can deliver empty response back
-		if _, destructed := s.db.snapDestructs[s.addrHash]; destructed {
+		if _, destructed := s.db.snapDestructs[s.snapHash]; destructed {
 			return common.Hash{}
 		}
-		enc, err = s.db.snap.Storage(s.addrHash, crypto.Keccak256Hash(key.Bytes()))
+		enc, err = s.db.snap.Storage(s.snapHash, s.db.snapStorageHash(key))
 	}
 	// If the snapshot is unavailable or reading from it fails, load from the database.
 	if s.db.snap == nil || err != nil {
@@ -342,7 +350,6 @@ func (s *stateObject) updateTrie(db Database) Trie {
 	var storage map[common.Hash][]byte
 	// Insert all the pending updates into the trie
 	tr := s.getTrie(db)
-	hasher := s.db.hasher
 
 	usedStorage := make([][]byte, 0, len(s.pendingStorage))
 	for key, value := range s.pendingStorage {
@@ -370,12 +377,12 @@ func (s *stateObject) updateTrie(db Database) Trie {
 		if s.db.snap != nil {
 			if storage == nil {
 				// Retrieve the old storage map, if available, create a new one otherwise
-				if storage = s.db.snapStorage[s.addrHash]; storage == nil {
+				if storage = s.db.snapStorage[s.snapHash]; storage == nil {
 					storage = make(map[common.Hash][]byte)
-					s.db.snapStorage[s.addrHash] = storage
+					s.db.snapStorage[s.snapHash] = storage
 				}
 			}
-			storage[crypto.HashData(hasher, key[:])] = v // v will be nil if it's deleted
+			storage[s.db.snapStorageHash(key)] = v // v will be nil if it's deleted
 		}
 		usedStorage = append(usedStorage, common.CopyBytes(key[:])) // Copy needed for closure
 	}
@@ -462,6 +469,7 @@ func (s *stateObject) deepCopy(db *StateDB) *stateObject {
 	if s.trie != nil {
 		stateObject.trie = db.db.CopyTrie(s.trie)
 	}
+	stateObject.snapHash = s.snapHash
 	stateObject.code = s.code
 	stateObject.dirtyStorage = s.dirtyStorage.Copy()
 	stateObject.originStorage = s.originStorage.Copy()
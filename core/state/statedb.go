@@ -189,6 +189,32 @@ func (s *StateDB) IsZktrie() bool {
 	return s.db.TrieDB().Zktrie
 }
 
+// snapAccountHash returns the key of the account of the given address in the state
+// snapshot, the hash of the address for the MPT and its leaf key for the zktrie.
+func (s *StateDB) snapAccountHash(addr common.Address) common.Hash {
+	if s.IsZktrie() {
+		return s.zktrieLeafKey(addr.Bytes())
+	}
+	return crypto.HashData(s.hasher, addr.Bytes())
+}
+
+// snapStorageHash returns the key of the given storage slot in the state snapshot, the
+// hash of the slot for the MPT and its leaf key for the zktrie.
+func (s *StateDB) snapStorageHash(key common.Hash) common.Hash {
+	if s.IsZktrie() {
+		return s.zktrieLeafKey(key.Bytes())
+	}
+	return crypto.HashData(s.hasher, key.Bytes())
+}
+
+func (s *StateDB) zktrieLeafKey(key []byte) common.Hash {
+	leafKey, err := trie.ZkLeafKey(key)
+	if err != nil {
+		s.setError(fmt.Errorf("zktrie leaf key (%x) error: %v", key, err))
+	}
+	return leafKey
+}
+
 func (s *StateDB) AddLog(log *types.Log) {
 	s.journal.append(addLogChange{txhash: s.thash})
 
@@ -493,7 +519,7 @@ func (s *StateDB) updateStateObject(obj *stateObject) {
 	// enough to track account updates at commit time, deletions need tracking
 	// at transaction boundary level to ensure we capture state clearing.
 	if s.snap != nil {
-		s.snapAccounts[obj.addrHash] = snapshot.SlimAccountRLP(obj.data.Nonce, obj.data.Balance, obj.data.Root, obj.data.KeccakCodeHash, obj.data.PoseidonCodeHash, obj.data.CodeSize)
+		s.snapAccounts[obj.snapHash] = snapshot.SlimAccountRLP(obj.data.Nonce, obj.data.Balance, obj.data.Root, obj.data.KeccakCodeHash, obj.data.PoseidonCodeHash, obj.data.CodeSize)
 	}
 }
 
@@ -539,7 +565,7 @@ func (s *StateDB) getDeletedStateObject(addr common.Address) *stateObject {
 			defer func(start time.Time) { s.SnapshotAccountReads += time.Since(start) }(time.Now())
 		}
 		var acc *snapshot.Account
-		if acc, err = s.snap.Account(crypto.HashData(s.hasher, addr.Bytes())); err == nil {
+		if acc, err = s.snap.Account(s.snapAccountHash(addr)); err == nil {
 			if acc == nil {
 				return nil
 			}
@@ -611,9 +637,9 @@ func (s *StateDB) createObject(addr common.Address) (newobj, prev *stateObject)
 
 	var prevdestruct bool
 	if s.snap != nil && prev != nil {
-		_, prevdestruct = s.snapDestructs[prev.addrHash]
+		_, prevdestruct = s.snapDestructs[prev.snapHash]
 		if !prevdestruct {
-			s.snapDestructs[prev.addrHash] = struct{}{}
+			s.snapDestructs[prev.snapHash] = struct{}{}
 		}
 	}
 	newobj = newObject(s, addr, types.StateAccount{})
@@ -828,9 +854,9 @@ func (s *StateDB) Finalise(deleteEmptyObjects bool) {
 			// transactions within the same block might self destruct and then
 			// ressurrect an account; but the snapshotter needs both events.
 			if s.snap != nil {
-				s.snapDestructs[obj.addrHash] = struct{}{} // We need to maintain account deletions explicitly (will remain set indefinitely)
-				delete(s.snapAccounts, obj.addrHash)       // Clear out any previously updated account data (may be recreated via a ressurrect)
-				delete(s.snapStorage, obj.addrHash)        // Clear out any previously updated storage data (may be recreated via a ressurrect)
+				s.snapDestructs[obj.snapHash] = struct{}{} // We need to maintain account deletions explicitly (will remain set indefinitely)
+				delete(s.snapAccounts, obj.snapHash)       // Clear out any previously updated account data (may be recreated via a ressurrect)
+				delete(s.snapStorage, obj.snapHash)        // Clear out any previously updated storage data (may be recreated via a ressurrect)
 			}
 		} else {
 			obj.finalise(true) // Prefetch slots in the background
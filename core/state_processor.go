@@ -98,13 +98,13 @@ func applyTransaction(msg types.Message, config *params.ChainConfig, bc ChainCon
 	txContext := NewEVMTxContext(msg)
 	evm.Reset(txContext, statedb)
 
-	l1DataFee, err := fees.CalculateL1DataFee(tx, statedb)
+	l1Fee, err := fees.CalculateL1Fee(tx, statedb)
 	if err != nil {
 		return nil, err
 	}
 
 	// Apply the transaction to the current state (included in the env).
-	result, err := ApplyMessage(evm, msg, gp, l1DataFee)
+	result, err := ApplyMessage(evm, msg, gp, l1Fee.L1DataFee)
 	if err != nil {
 		return nil, err
 	}
@@ -146,6 +146,10 @@ func applyTransaction(msg types.Message, config *params.ChainConfig, bc ChainCon
 	receipt.BlockNumber = blockNumber
 	receipt.TransactionIndex = uint(statedb.TxIndex())
 	receipt.L1Fee = result.L1DataFee
+	receipt.L1GasUsed = l1Fee.L1GasUsed
+	receipt.L1BaseFee = l1Fee.L1BaseFee
+	receipt.L1FeeOverhead = l1Fee.Overhead
+	receipt.L1FeeScalar = l1Fee.Scalar
 	return receipt, err
 }
 
@@ -0,0 +1,374 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/scroll-tech/go-ethereum/common"
+	"github.com/scroll-tech/go-ethereum/core/rawdb"
+	"github.com/scroll-tech/go-ethereum/core/state"
+	"github.com/scroll-tech/go-ethereum/core/state/migrator"
+	"github.com/scroll-tech/go-ethereum/core/types"
+	"github.com/scroll-tech/go-ethereum/log"
+	"github.com/scroll-tech/go-ethereum/metrics"
+	"github.com/scroll-tech/go-ethereum/trie"
+)
+
+var stateMigrationBlockTimer = metrics.NewRegisteredTimer("chain/trieswitch/block", nil)
+
+// switchableDatabases holds the state database of the chain and, once the state scheme
+// was switched, the state database of the states before the switch.
+type switchableDatabases struct {
+	current state.Database
+	legacy  state.Database
+}
+
+// switchableDatabase is the state database of the chain. It is switched atomically to the
+// state database of the other trie at the state scheme switch block.
+type switchableDatabase struct {
+	dbs atomic.Value // *switchableDatabases
+}
+
+func newSwitchableDatabase(current, legacy state.Database) *switchableDatabase {
+	db := new(switchableDatabase)
+	db.dbs.Store(&switchableDatabases{current: current, legacy: legacy})
+	return db
+}
+
+func (db *switchableDatabase) load() *switchableDatabases {
+	return db.dbs.Load().(*switchableDatabases)
+}
+
+// switchTo makes target the state database of the chain, the current one is kept for the
+// states before the switch.
+func (db *switchableDatabase) switchTo(target state.Database) {
+	db.dbs.Store(&switchableDatabases{current: target, legacy: db.load().current})
+}
+
+// switchBack makes the state database of the states before the switch the state database
+// of the chain again.
+func (db *switchableDatabase) switchBack() {
+	db.dbs.Store(&switchableDatabases{current: db.load().legacy})
+}
+
+// legacy returns the state database of the states before the state scheme switch, nil if
+// the state scheme was not switched.
+func (db *switchableDatabase) legacy() state.Database {
+	return db.load().legacy
+}
+
+func (db *switchableDatabase) OpenTrie(root common.Hash) (state.Trie, error) {
+	return db.load().current.OpenTrie(root)
+}
+
+func (db *switchableDatabase) OpenStorageTrie(addrHash, root common.Hash) (state.Trie, error) {
+	return db.load().current.OpenStorageTrie(addrHash, root)
+}
+
+func (db *switchableDatabase) CopyTrie(t state.Trie) state.Trie {
+	return db.load().current.CopyTrie(t)
+}
+
+func (db *switchableDatabase) ContractCode(addrHash, codeHash common.Hash) ([]byte, error) {
+	return db.load().current.ContractCode(addrHash, codeHash)
+}
+
+func (db *switchableDatabase) ContractCodeWithPrefix(addrHash, codeHash common.Hash) ([]byte, error) {
+	type codeReader interface {
+		ContractCodeWithPrefix(addrHash, codeHash common.Hash) ([]byte, error)
+	}
+	return db.load().current.(codeReader).ContractCodeWithPrefix(addrHash, codeHash)
+}
+
+func (db *switchableDatabase) ContractCodeSize(addrHash, codeHash common.Hash) (int, error) {
+	return db.load().current.ContractCodeSize(addrHash, codeHash)
+}
+
+func (db *switchableDatabase) TrieDB() *trie.Database {
+	return db.load().current.TrieDB()
+}
+
+// stateMigration migrates the state of the chain into the other trie ahead of the state
+// scheme switch block. The state after the head block is migrated in the background while
+// the chain keeps importing blocks, then the blocks imported in the meantime are replayed on
+// the migrated state, and from then on every imported block is replayed as it is written.
+// The migrated state roots are recorded for every block, the one of the parent of the
+// switch block becomes the state the chain continues on.
+type stateMigration struct {
+	bc        *BlockChain
+	target    state.Database
+	processor Processor
+	migrator  *migrator.Migrator
+	start     *types.Header // block whose state is migrated in the background
+
+	mu     sync.Mutex // serializes the replays of blocks on the target state database
+	synced bool       // whether the imported blocks are replayed as they are written
+	err    error      // error stopping the migration
+
+	quit chan struct{}
+	done chan struct{}
+}
+
+// newStateMigration starts the migration of the state of the chain into the other trie. A
+// previously interrupted migration is resumed if the state of its block is available.
+func newStateMigration(bc *BlockChain) (*stateMigration, error) {
+	if !bc.cacheConfig.Preimages {
+		return nil, fmt.Errorf("the state scheme switch at block %v requires the recording of preimages", bc.chainConfig.Scroll.TrieSwitchBlock)
+	}
+	start := bc.CurrentBlock().Header()
+	progress, err := migrator.ReadProgress(bc.db)
+	if err != nil {
+		return nil, err
+	}
+	if progress != nil {
+		if header := bc.GetHeader(progress.Hash, progress.Number); header != nil && bc.HasState(header.Root) {
+			start = header
+		}
+	}
+	// the background migration reads the source state from the disk
+	if !bc.chainConfig.Scroll.ZktrieEnabledAt(start.Number) {
+		if err := bc.stateCache.TrieDB().Commit(start.Root, false, nil); err != nil {
+			return nil, err
+		}
+	}
+	target := state.NewDatabaseWithConfig(bc.db, &trie.Config{
+		Cache:     bc.cacheConfig.TrieCleanLimit,
+		Preimages: true,
+		Zktrie:    bc.chainConfig.Scroll.ZktrieEnabledAt(bc.chainConfig.Scroll.TrieSwitchBlock),
+	})
+	m := &stateMigration{
+		bc:        bc,
+		target:    target,
+		processor: NewStateProcessor(bc.chainConfig, bc, bc.engine),
+		migrator:  migrator.NewMigrator(bc.db, bc.stateCache, target),
+		start:     start,
+		quit:      make(chan struct{}),
+		done:      make(chan struct{}),
+	}
+	log.Info("Migrating state ahead of the state scheme switch", "switch", bc.chainConfig.Scroll.TrieSwitchBlock, "number", start.Number, "hash", start.Hash())
+	go m.loop()
+	return m, nil
+}
+
+// loop migrates the state of the start block, and replays the blocks imported since then.
+func (m *stateMigration) loop() {
+	defer close(m.done)
+
+	if _, err := m.migrator.Migrate(m.start, m.quit); err != nil {
+		m.fail(err)
+		return
+	}
+	for number := m.start.Number.Uint64() + 1; ; number++ {
+		select {
+		case <-m.quit:
+			m.fail(migrator.ErrInterrupted)
+			return
+		default:
+		}
+		m.mu.Lock()
+		block := m.bc.GetBlockByNumber(number)
+		if block == nil {
+			m.synced = true
+			m.mu.Unlock()
+			log.Info("Caught up with the chain in the migrated state", "number", number-1)
+			return
+		}
+		_, err := m.rootOf(block.Header())
+		m.mu.Unlock()
+		if err != nil {
+			m.fail(err)
+			return
+		}
+	}
+}
+
+// fail stops the migration after an error.
+func (m *stateMigration) fail(err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.err = err
+	if !errors.Is(err, migrator.ErrInterrupted) {
+		log.Error("State migration failed", "err", err)
+	}
+}
+
+// process replays a block written to the chain on the migrated state, once the migration
+// caught up with the chain.
+func (m *stateMigration) process(block *types.Block) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if !m.synced || m.err != nil {
+		return
+	}
+	if _, err := m.rootOf(block.Header()); err != nil {
+		m.err = err
+		log.Error("State migration failed", "number", block.NumberU64(), "hash", block.Hash(), "err", err)
+	}
+}
+
+// rootOf returns the root of the migrated state after the given block, replaying the blocks
+// since its last migrated ancestor. It must be called with m.mu held.
+func (m *stateMigration) rootOf(header *types.Header) (common.Hash, error) {
+	var blocks []*types.Block
+	for {
+		if root := rawdb.ReadMigratedStateRoot(m.bc.db, header.Hash()); root != nil {
+			break
+		}
+		number := header.Number.Uint64()
+		if number <= m.start.Number.Uint64() {
+			return common.Hash{}, fmt.Errorf("state of block %d (%v) was not migrated", number, header.Hash())
+		}
+		block := m.bc.GetBlock(header.Hash(), number)
+		if block == nil {
+			return common.Hash{}, fmt.Errorf("missing block %d (%v)", number, header.Hash())
+		}
+		blocks = append(blocks, block)
+		if header = m.bc.GetHeader(header.ParentHash, number-1); header == nil {
+			return common.Hash{}, fmt.Errorf("missing block %d (%v)", number-1, block.ParentHash())
+		}
+	}
+	root := *rawdb.ReadMigratedStateRoot(m.bc.db, header.Hash())
+	for i := len(blocks) - 1; i >= 0; i-- {
+		start := time.Now()
+		statedb, err := state.New(root, m.target, nil)
+		if err != nil {
+			return common.Hash{}, err
+		}
+		if err := replayBlock(m.processor, blocks[i], statedb); err != nil {
+			return common.Hash{}, fmt.Errorf("block %d (%v): %w", blocks[i].NumberU64(), blocks[i].Hash(), err)
+		}
+		if root, err = commitState(m.target, statedb, m.bc.chainConfig.IsEIP158(blocks[i].Number())); err != nil {
+			return common.Hash{}, err
+		}
+		rawdb.WriteMigratedStateRoot(m.bc.db, blocks[i].Hash(), root)
+		stateMigrationBlockTimer.UpdateSince(start)
+	}
+	return root, nil
+}
+
+// parentState returns the migrated state after the parent of the switch block, waiting for
+// the background migration if it is still running.
+func (m *stateMigration) parentState(parent *types.Header) (*state.StateDB, error) {
+	if root := rawdb.ReadMigratedStateRoot(m.bc.db, parent.Hash()); root != nil {
+		return state.New(*root, m.target, nil)
+	}
+	select {
+	case <-m.done:
+	default:
+		log.Info("Waiting for the state migration before the state scheme switch", "number", parent.Number, "hash", parent.Hash())
+		select {
+		case <-m.done:
+		case <-m.bc.quit:
+			return nil, errInsertionInterrupted
+		}
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.err != nil {
+		return nil, fmt.Errorf("state migration failed: %w", m.err)
+	}
+	root, err := m.rootOf(parent)
+	if err != nil {
+		return nil, err
+	}
+	return state.New(root, m.target, nil)
+}
+
+// stop interrupts the background migration and waits for it to exit.
+func (m *stateMigration) stop() {
+	select {
+	case <-m.quit:
+	default:
+		close(m.quit)
+	}
+	<-m.done
+}
+
+// StateForChild returns the state to execute a child block of the given header on. It is the
+// state after the header, except for the first block of the state scheme switch, which is
+// executed on the state after the header migrated into the other trie.
+func (bc *BlockChain) StateForChild(parent *types.Header) (*state.StateDB, error) {
+	if !bc.chainConfig.Scroll.IsTrieSwitchBlock(new(big.Int).Add(parent.Number, common.Big1)) {
+		// the parents of side chain blocks might precede the switch
+		return bc.StateAt(parent.Root)
+	}
+	if bc.migration != nil {
+		return bc.migration.parentState(parent)
+	}
+	// no migration runs once the node was restarted after the switch, the parents of the
+	// switch blocks of side chains are migrated on the spot
+	legacy := bc.stateCache.legacy()
+	if legacy == nil {
+		return nil, errors.New("state is not migrated for the state scheme switch")
+	}
+	root, err := migrator.NewMigrator(bc.db, legacy, bc.stateCache.load().current).Migrate(parent, bc.quit)
+	if err != nil {
+		return nil, err
+	}
+	return state.New(root, bc.stateCache, nil)
+}
+
+// switchStateScheme switches the state database of the chain to the one of the migrated
+// state, once the first block of the state scheme switch is written. The states of the
+// switch block and of its parent are flushed to the disk, so that the chain can be rewound
+// across the switch, and the state snapshot is regenerated in the other trie.
+func (bc *BlockChain) switchStateScheme(block *types.Block) error {
+	if bc.stateCache.legacy() != nil {
+		return nil
+	}
+	if parent := bc.GetHeader(block.ParentHash(), block.NumberU64()-1); parent != nil {
+		if err := bc.stateCache.TrieDB().Commit(parent.Root, false, nil); err != nil {
+			return err
+		}
+	}
+	bc.stateCache.switchTo(bc.migration.target)
+	if err := bc.stateCache.TrieDB().Commit(block.Root(), false, nil); err != nil {
+		return err
+	}
+	bc.resetSnapshots(block.Root())
+	log.Info("Switched the state scheme", "number", block.NumberU64(), "hash", block.Hash(), "root", block.Root(), "zktrie", bc.chainConfig.Scroll.ZktrieEnabledAt(block.Number()))
+	return nil
+}
+
+// revertStateScheme switches the state database of the chain back to the one of the states
+// before the state scheme switch, after the chain was rewound below the switch block. The
+// migration of the blocks imported from then on resumes from the migrated state of the head.
+func (bc *BlockChain) revertStateScheme() error {
+	switchBlock, head := bc.chainConfig.Scroll.TrieSwitchBlock, bc.CurrentBlock()
+	if bc.stateCache.legacy() == nil || switchBlock == nil || head.Number().Cmp(switchBlock) >= 0 {
+		return nil
+	}
+	bc.stateCache.switchBack()
+	bc.resetSnapshots(head.Root())
+	log.Warn("Switched the state scheme back, the chain was rewound below the switch", "number", head.Number(), "hash", head.Hash(), "switch", switchBlock)
+
+	if bc.migration == nil {
+		var err error
+		if bc.migration, err = newStateMigration(bc); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// resetSnapshots regenerates the state snapshot of the given state in the background, after
+// the state database of the chain was switched. The snapshot is disabled instead if the
+// state is a zktrie state and zktrie snapshots are not enabled.
+func (bc *BlockChain) resetSnapshots(root common.Hash) {
+	if bc.snaps == nil {
+		return
+	}
+	if triedb := bc.stateCache.TrieDB(); triedb.Zktrie && !bc.cacheConfig.ZktrieSnapshot {
+		log.Warn("Snapshot has been disabled by zktrie, use --snapshot.zktrie to enable it")
+		bc.snaps.Disable()
+	} else {
+		bc.snaps.Reset(triedb, root)
+	}
+}
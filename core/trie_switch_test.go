@@ -0,0 +1,160 @@
+package core
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/scroll-tech/go-ethereum/common"
+	"github.com/scroll-tech/go-ethereum/consensus/ethash"
+	"github.com/scroll-tech/go-ethereum/core/rawdb"
+	"github.com/scroll-tech/go-ethereum/core/types"
+	"github.com/scroll-tech/go-ethereum/core/vm"
+	"github.com/scroll-tech/go-ethereum/crypto"
+	"github.com/scroll-tech/go-ethereum/params"
+)
+
+func TestTrieSwitchFromZktrie(t *testing.T) { testTrieSwitch(t, true) }
+func TestTrieSwitchFromMPT(t *testing.T)    { testTrieSwitch(t, false) }
+
+func testTrieSwitch(t *testing.T, fromZktrie bool) {
+	var (
+		key, _   = crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+		sender   = crypto.PubkeyToAddress(key.PublicKey)
+		contract = common.HexToAddress("0xc0de")
+		// SSTORE(NUMBER, NUMBER), SSTORE(1, 0)
+		code         = common.FromHex("0x4343556000600155")
+		targetConfig = *params.TestChainConfig
+	)
+	// other tests modify the limits of the shared test config
+	targetConfig.Scroll.UseZktrie = !fromZktrie
+	targetConfig.Scroll.MaxTxPerBlock = nil
+	targetConfig.Scroll.MaxTxPayloadBytesPerBlock = nil
+	config := targetConfig
+	config.Scroll.UseZktrie = fromZktrie
+	config.Scroll.TrieSwitchBlock = big.NewInt(3)
+
+	gspec := func(config *params.ChainConfig) *Genesis {
+		return &Genesis{
+			Config: config,
+			Alloc: GenesisAlloc{
+				sender:   {Balance: big.NewInt(params.Ether)},
+				contract: {Balance: common.Big0, Code: code, Storage: map[common.Hash]common.Hash{common.BigToHash(common.Big1): common.BigToHash(common.Big1)}},
+			},
+		}
+	}
+	generate := func(i int, b *BlockGen) {
+		signer := types.HomesteadSigner{}
+		tx, _ := types.SignTx(types.NewTransaction(b.TxNonce(sender), common.Address{byte(i + 1)}, common.Big1, params.TxGas, b.BaseFee(), nil), signer, key)
+		b.AddTx(tx)
+		tx, _ = types.SignTx(types.NewTransaction(b.TxNonce(sender), contract, common.Big0, 100000, b.BaseFee(), nil), signer, key)
+		b.AddTx(tx)
+	}
+
+	// the same chain on the other trie, whose state roots the chain has from the switch on
+	targetDb := rawdb.NewMemoryDatabase()
+	targetGenesis := gspec(&targetConfig).MustCommit(targetDb)
+	targetBlocks, _ := GenerateChain(&targetConfig, targetGenesis, ethash.NewFaker(), targetDb, 5, generate)
+
+	gendb := rawdb.NewMemoryDatabase()
+	genesis := gspec(&config).MustCommit(gendb)
+	blocks, _ := GenerateChain(&config, genesis, ethash.NewFaker(), gendb, 5, generate)
+	for i, block := range blocks {
+		if switched := block.Root() == targetBlocks[i].Root(); switched != (i >= 2) {
+			t.Fatalf("block %d: unexpected state root %v, root in the other trie %v", block.NumberU64(), block.Root(), targetBlocks[i].Root())
+		}
+	}
+
+	db := rawdb.NewMemoryDatabase()
+	gspec(&config).MustCommit(db)
+	cacheConfig := &CacheConfig{TrieCleanLimit: 256, TrieDirtyLimit: 256, TrieTimeLimit: 5 * time.Minute, SnapshotLimit: 256, ZktrieSnapshot: true, SnapshotWait: true, Preimages: true}
+	chain, err := NewBlockChain(db, cacheConfig, &config, ethash.NewFaker(), vm.Config{}, nil, nil)
+	if err != nil {
+		t.Fatalf("failed to create chain: %v", err)
+	}
+	if chain.migration == nil {
+		t.Fatal("state migration not started")
+	}
+	if _, err := chain.InsertChain(blocks); err != nil {
+		t.Fatalf("failed to insert chain: %v", err)
+	}
+	if head := chain.CurrentBlock(); head.Hash() != blocks[4].Hash() {
+		t.Fatalf("head mismatch, expected %d, got %d", blocks[4].NumberU64(), head.NumberU64())
+	}
+	if chain.stateCache.legacy() == nil {
+		t.Fatal("state scheme not switched")
+	}
+	// the states before and after the switch are served
+	for _, block := range []*types.Block{blocks[1], blocks[4]} {
+		statedb, err := chain.StateAt(block.Root())
+		if err != nil {
+			t.Fatalf("block %d: failed to open state: %v", block.NumberU64(), err)
+		}
+		if zktrie := statedb.IsZktrie(); zktrie != config.Scroll.ZktrieEnabledAt(block.Number()) {
+			t.Fatalf("block %d: unexpected state scheme, zktrie: %v", block.NumberU64(), zktrie)
+		}
+		if value := statedb.GetState(contract, common.BigToHash(block.Number())); value != common.BigToHash(block.Number()) {
+			t.Fatalf("block %d: storage mismatch, got %v", block.NumberU64(), value)
+		}
+	}
+	chain.Stop()
+
+	// the chain reopens on the switched state scheme
+	chain, err = NewBlockChain(db, cacheConfig, &config, ethash.NewFaker(), vm.Config{}, nil, nil)
+	if err != nil {
+		t.Fatalf("failed to reopen chain: %v", err)
+	}
+	defer chain.Stop()
+	if chain.migration != nil {
+		t.Fatal("state migration restarted after the switch")
+	}
+	statedb, err := chain.State()
+	if err != nil {
+		t.Fatalf("failed to open head state: %v", err)
+	}
+	if statedb.IsZktrie() == fromZktrie {
+		t.Fatalf("head state not switched, zktrie: %v", statedb.IsZktrie())
+	}
+
+	// rewinding below the switch block switches the state scheme back
+	if err := chain.SetHead(2); err != nil {
+		t.Fatalf("failed to rewind chain: %v", err)
+	}
+	if head := chain.CurrentBlock(); head.Hash() != blocks[1].Hash() {
+		t.Fatalf("head mismatch after rewind, expected %d, got %d", blocks[1].NumberU64(), head.NumberU64())
+	}
+	if chain.stateCache.legacy() != nil {
+		t.Fatal("state scheme not switched back")
+	}
+	if chain.migration == nil {
+		t.Fatal("state migration not restarted after the rewind")
+	}
+	if chain.snaps.Snapshot(blocks[1].Root()) == nil {
+		t.Fatal("snapshot not reset to the rewound head")
+	}
+	if statedb, err = chain.State(); err != nil {
+		t.Fatalf("failed to open head state after rewind: %v", err)
+	}
+	if statedb.IsZktrie() != fromZktrie {
+		t.Fatalf("head state not switched back, zktrie: %v", statedb.IsZktrie())
+	}
+	// and the chain switches again on the reimported blocks
+	if _, err := chain.InsertChain(blocks[2:]); err != nil {
+		t.Fatalf("failed to reinsert chain: %v", err)
+	}
+	if head := chain.CurrentBlock(); head.Hash() != blocks[4].Hash() {
+		t.Fatalf("head mismatch after reinsert, expected %d, got %d", blocks[4].NumberU64(), head.NumberU64())
+	}
+	if chain.stateCache.legacy() == nil {
+		t.Fatal("state scheme not switched again")
+	}
+	if chain.snaps.Snapshot(blocks[4].Root()) == nil {
+		t.Fatal("snapshot not reset at the switch")
+	}
+	if statedb, err = chain.State(); err != nil {
+		t.Fatalf("failed to open head state after reinsert: %v", err)
+	}
+	if statedb.IsZktrie() == fromZktrie {
+		t.Fatalf("head state not switched again, zktrie: %v", statedb.IsZktrie())
+	}
+}
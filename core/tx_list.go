@@ -194,6 +194,12 @@ func (m *txSortedMap) Remove(nonce uint64) bool {
 // prevent getting into and invalid state. This is not something that should ever
 // happen but better to be self correcting than failing!
 func (m *txSortedMap) Ready(start uint64) types.Transactions {
+	return m.ReadyWhile(start, nil)
+}
+
+// ReadyWhile is like Ready, but stops at the first transaction rejected by the
+// given check, which stays in the list. A nil check accepts all transactions.
+func (m *txSortedMap) ReadyWhile(start uint64, check func(*types.Transaction) bool) types.Transactions {
 	// Short circuit if no transactions are available
 	if m.index.Len() == 0 || (*m.index)[0] > start {
 		return nil
@@ -201,6 +207,9 @@ func (m *txSortedMap) Ready(start uint64) types.Transactions {
 	// Otherwise start accumulating incremental transactions
 	var ready types.Transactions
 	for next := (*m.index)[0]; m.index.Len() > 0 && (*m.index)[0] == next; next++ {
+		if check != nil && !check(m.items[next]) {
+			break
+		}
 		ready = append(ready, m.items[next])
 		delete(m.items, next)
 		heap.Pop(m.index)
@@ -393,6 +402,25 @@ func (l *txList) Ready(start uint64) types.Transactions {
 	return l.txs.Ready(start)
 }
 
+// ReadyWhile is like Ready, but stops at the first transaction rejected by the
+// given check, which stays in the list together with all subsequent ones.
+func (l *txList) ReadyWhile(start uint64, check func(*types.Transaction) bool) types.Transactions {
+	return l.txs.ReadyWhile(start, check)
+}
+
+// FilterFrom removes the first transaction rejected by the given check and all
+// transactions with higher nonces, returning them. The check is evaluated in nonce
+// order, so that the remaining transactions are the ones up to the rejected one.
+func (l *txList) FilterFrom(check func(*types.Transaction) bool) types.Transactions {
+	for _, tx := range l.txs.flatten() {
+		if !check(tx) {
+			lowest := tx.Nonce()
+			return l.txs.Filter(func(tx *types.Transaction) bool { return tx.Nonce() >= lowest })
+		}
+	}
+	return nil
+}
+
 // Len returns the length of the transaction list.
 func (l *txList) Len() int {
 	return l.txs.Len()
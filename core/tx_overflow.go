@@ -0,0 +1,106 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+
+	lru "github.com/hashicorp/golang-lru"
+
+	"github.com/scroll-tech/go-ethereum/common"
+	"github.com/scroll-tech/go-ethereum/core/types"
+	"github.com/scroll-tech/go-ethereum/crypto"
+)
+
+// txOverflowCacheSize is the number of transaction payloads remembered to exceed
+// the circuit capacity.
+const txOverflowCacheSize = 4096
+
+// RowConsumptionOverflowErrorCode is the JSON-RPC error code of transactions rejected
+// because their execution exceeds the circuit capacity.
+const RowConsumptionOverflowErrorCode = -32010
+
+// ErrRowConsumptionOverflow is returned if the execution of a transaction is known to
+// exceed the row capacity of the zk circuit, so that it can never be included in a block.
+var ErrRowConsumptionOverflow = errors.New("transaction exceeds circuit capacity")
+
+// rowConsumptionOverflowError is returned for transactions rejected by txOverflows.
+type rowConsumptionOverflowError struct {
+	gas uint64 // lowest gas limit the payload is known to overflow with
+}
+
+func (e *rowConsumptionOverflowError) Error() string {
+	return fmt.Sprintf("%v: payload overflowed with gas limit %d", ErrRowConsumptionOverflow, e.gas)
+}
+
+func (e *rowConsumptionOverflowError) Unwrap() error { return ErrRowConsumptionOverflow }
+
+// ErrorCode returns the JSON-RPC error code, so that clients can tell the rejection
+// apart from transient ones.
+func (e *rowConsumptionOverflowError) ErrorCode() int { return RowConsumptionOverflowErrorCode }
+
+// txOverflows remembers the payloads of transactions whose execution on its own
+// exceeded the circuit capacity while building a block. A transaction of the same
+// sender and nonce with the same recipient, value and input is likely to overflow
+// again given at least the same gas limit, so it is rejected without tracing it again.
+// This is a heuristic: the state the payload executes on may have changed since, the
+// LRU bounds how long a wrong rejection can last. The estimate is keyed by payload
+// rather than by hash, so that re-signing the transaction with a higher price doesn't
+// help. The sender and nonce are part of the key, as the execution of the payload
+// depends on the state of the sender.
+type txOverflows struct {
+	gas *lru.Cache // payload hash -> lowest gas limit known to overflow
+}
+
+func newTxOverflows() *txOverflows {
+	cache, _ := lru.New(txOverflowCacheSize)
+	return &txOverflows{gas: cache}
+}
+
+// payloadHash returns the key of the sender, nonce, recipient, value and input of the
+// transaction.
+func (o *txOverflows) payloadHash(from common.Address, tx *types.Transaction) common.Hash {
+	var to []byte
+	if tx.To() != nil {
+		to = tx.To().Bytes()
+	}
+	var nonce [8]byte
+	binary.BigEndian.PutUint64(nonce[:], tx.Nonce())
+	value := tx.Value().FillBytes(make([]byte, 32))
+	return crypto.Keccak256Hash(from.Bytes(), nonce[:], to, value, tx.Data())
+}
+
+// add records that the execution of the transaction sent by from exceeded the circuit
+// capacity.
+func (o *txOverflows) add(from common.Address, tx *types.Transaction) {
+	key := o.payloadHash(from, tx)
+	if gas, ok := o.gas.Get(key); ok && gas.(uint64) <= tx.Gas() {
+		return
+	}
+	o.gas.Add(key, tx.Gas())
+}
+
+// check returns an error if the execution of the transaction sent by from is known to
+// exceed the circuit capacity.
+func (o *txOverflows) check(from common.Address, tx *types.Transaction) error {
+	if gas, ok := o.gas.Get(o.payloadHash(from, tx)); ok && gas.(uint64) <= tx.Gas() {
+		return &rowConsumptionOverflowError{gas: gas.(uint64)}
+	}
+	return nil
+}
@@ -100,6 +100,7 @@ var (
 	pendingReplaceMeter   = metrics.NewRegisteredMeter("txpool/pending/replace", nil)
 	pendingRateLimitMeter = metrics.NewRegisteredMeter("txpool/pending/ratelimit", nil) // Dropped due to rate limiting
 	pendingNofundsMeter   = metrics.NewRegisteredMeter("txpool/pending/nofunds", nil)   // Dropped due to out-of-funds
+	pendingL1DataFeeMeter = metrics.NewRegisteredMeter("txpool/pending/l1datafee", nil) // Demoted due to out-of-funds for the L1 data fee
 
 	// Metrics for the queued pool
 	queuedDiscardMeter   = metrics.NewRegisteredMeter("txpool/queued/discard", nil)
@@ -273,6 +274,11 @@ type TxPool struct {
 	all     *txLookup                    // All transactions to allow lookups
 	priced  *txPricedList                // All transactions sorted by price
 
+	overflows *txOverflows // Payloads known to exceed the circuit capacity
+
+	l1Fees     *fees.L1Fees             // L1 fee values of the current state the cached L1 data fees are based on
+	l1DataFees map[common.Hash]*big.Int // L1 data fees of the pooled transactions
+
 	chainHeadCh     chan ChainHeadEvent
 	chainHeadSub    event.Subscription
 	reqResetCh      chan *txpoolResetRequest
@@ -308,6 +314,8 @@ func NewTxPool(config TxPoolConfig, chainconfig *params.ChainConfig, chain block
 		queue:           make(map[common.Address]*txList),
 		beats:           make(map[common.Address]time.Time),
 		all:             newTxLookup(),
+		overflows:       newTxOverflows(),
+		l1DataFees:      make(map[common.Hash]*big.Int),
 		chainHeadCh:     make(chan ChainHeadEvent, chainHeadChanSize),
 		reqResetCh:      make(chan *txpoolResetRequest),
 		reqPromoteCh:    make(chan *accountSet),
@@ -649,6 +657,10 @@ func (pool *TxPool) validateTx(tx *types.Transaction, local bool) error {
 	if err != nil {
 		return ErrInvalidSender
 	}
+	// Reject transactions whose execution is known to exceed the circuit capacity.
+	if err := pool.overflows.check(from, tx); err != nil {
+		return err
+	}
 	// Drop non-local transactions under our own minimal accepted gas price or tip.
 	pendingBaseFee := pool.priced.urgent.baseFee
 	if !local && tx.EffectiveGasTipIntCmp(pool.gasPrice, pendingBaseFee) < 0 {
@@ -665,17 +677,8 @@ func (pool *TxPool) validateTx(tx *types.Transaction, local bool) error {
 		return ErrInsufficientFunds
 	}
 	// 2. If FeeVault is enabled, perform an additional check for L1 data fees.
-	if pool.chainconfig.Scroll.FeeVaultEnabled() {
-		// Get L1 data fee in current state
-		l1DataFee, err := fees.CalculateL1DataFee(tx, pool.currentState)
-		if err != nil {
-			return fmt.Errorf("failed to calculate L1 data fee, err: %w", err)
-		}
-		// Transactor should have enough funds to cover the costs
-		// cost == L1 data fee + V + GP * GL
-		if b := pool.currentState.GetBalance(from); b.Cmp(new(big.Int).Add(tx.Cost(), l1DataFee)) < 0 {
-			return errors.New("invalid transaction: insufficient funds for l1fee + gas * price + value")
-		}
+	if err := pool.validateL1DataFee(from, tx); err != nil {
+		return err
 	}
 	// Ensure the transaction has more gas than the basic tx fee.
 	intrGas, err := IntrinsicGas(tx.Data(), tx.AccessList(), tx.To() == nil, true, pool.istanbul, pool.shanghai)
@@ -688,6 +691,68 @@ func (pool *TxPool) validateTx(tx *types.Transaction, local bool) error {
 	return nil
 }
 
+// validateL1DataFee checks whether the sender can cover the cost of the transaction
+// including the L1 data fee in the current state. No L1 data fee is charged if the
+// fee vault is disabled, so the check always passes then.
+func (pool *TxPool) validateL1DataFee(from common.Address, tx *types.Transaction) error {
+	if !pool.chainconfig.Scroll.FeeVaultEnabled() {
+		return nil
+	}
+	// Get L1 data fee in current state
+	l1DataFee, err := pool.l1DataFee(tx)
+	if err != nil {
+		return fmt.Errorf("failed to calculate L1 data fee, err: %w", err)
+	}
+	// Transactor should have enough funds to cover the costs
+	// cost == L1 data fee + V + GP * GL
+	if b := pool.currentState.GetBalance(from); b.Cmp(new(big.Int).Add(tx.Cost(), l1DataFee)) < 0 {
+		return ErrInsufficientFundsForL1DataFee
+	}
+	return nil
+}
+
+// l1DataFee returns the L1 data fee of the transaction in the current state. The fees
+// are cached, as they are checked for every pooled transaction on each reset.
+func (pool *TxPool) l1DataFee(tx *types.Transaction) (*big.Int, error) {
+	hash := tx.Hash()
+	if l1DataFee, ok := pool.l1DataFees[hash]; ok {
+		return l1DataFee, nil
+	}
+	l1DataFee, err := fees.CalculateL1DataFee(tx, pool.currentState)
+	if err != nil {
+		return nil, err
+	}
+	pool.l1DataFees[hash] = l1DataFee
+	return l1DataFee, nil
+}
+
+// resetL1DataFees drops the cached L1 data fees if the L1 fee values changed with the
+// new state, otherwise only the fees of the transactions no longer in the pool.
+func (pool *TxPool) resetL1DataFees() {
+	if !pool.chainconfig.Scroll.FeeVaultEnabled() {
+		return
+	}
+	l1Fees := fees.ReadL1Fees(pool.currentState)
+	if pool.l1Fees == nil || !pool.l1Fees.Equal(l1Fees) {
+		pool.l1DataFees = make(map[common.Hash]*big.Int)
+	} else {
+		for hash := range pool.l1DataFees {
+			if pool.all.Get(hash) == nil {
+				delete(pool.l1DataFees, hash)
+			}
+		}
+	}
+	pool.l1Fees = l1Fees
+}
+
+// l1DataFeeCovered reports whether the sender can cover the cost of the transaction
+// including the L1 data fee in the current state.
+func (pool *TxPool) l1DataFeeCovered(from common.Address) func(*types.Transaction) bool {
+	return func(tx *types.Transaction) bool {
+		return pool.validateL1DataFee(from, tx) == nil
+	}
+}
+
 // add validates a transaction and inserts it into the non-executable queue for later
 // pending promotion and execution. If the transaction is a replacement for an already
 // pending or queued one, it overwrites the previous transaction if its price is higher.
@@ -1038,6 +1103,20 @@ func (pool *TxPool) RemoveTx(hash common.Hash, outofbound bool) {
 	pool.removeTx(hash, outofbound)
 }
 
+// RemoveOverflowingTx removes a transaction whose execution on its own exceeded
+// the circuit capacity, and rejects transactions with the same sender, nonce and
+// payload from then on.
+func (pool *TxPool) RemoveOverflowingTx(hash common.Hash) {
+	pool.mu.Lock()
+	defer pool.mu.Unlock()
+
+	if tx := pool.all.Get(hash); tx != nil {
+		from, _ := types.Sender(pool.signer, tx) // already validated
+		pool.overflows.add(from, tx)
+	}
+	pool.removeTx(hash, true)
+}
+
 // removeTx removes a single transaction from the queue, moving all subsequent
 // transactions back to the future queue.
 func (pool *TxPool) removeTx(hash common.Hash, outofbound bool) {
@@ -1346,6 +1425,7 @@ func (pool *TxPool) reset(oldHead, newHead *types.Header) {
 	pool.currentState = statedb
 	pool.pendingNonces = newTxNoncer(statedb)
 	pool.currentMaxGas = newHead.GasLimit
+	pool.resetL1DataFees()
 
 	// Inject any transactions discarded due to reorgs
 	log.Debug("Reinjecting stale transactions", "count", len(reinject))
@@ -1390,8 +1470,9 @@ func (pool *TxPool) promoteExecutables(accounts []common.Address) []*types.Trans
 		log.Trace("Removed unpayable queued transactions", "count", len(drops))
 		queuedNofundsMeter.Mark(int64(len(drops)))
 
-		// Gather all executable transactions and promote them
-		readies := list.Ready(pool.pendingNonces.get(addr))
+		// Gather all executable transactions and promote them, the ones that can't
+		// cover the L1 data fee yet stay queued until the fee drops or the balance rises
+		readies := list.ReadyWhile(pool.pendingNonces.get(addr), pool.l1DataFeeCovered(addr))
 		for _, tx := range readies {
 			hash := tx.Hash()
 			if pool.promoteTx(addr, hash, tx) {
@@ -1596,9 +1677,22 @@ func (pool *TxPool) demoteUnexecutables() {
 			// Internal shuffle shouldn't touch the lookup set.
 			pool.enqueueTx(hash, tx, false, false)
 		}
-		pendingGauge.Dec(int64(len(olds) + len(drops) + len(invalids)))
+		// Postpone all transactions starting at the first one that can't cover the L1 data
+		// fee anymore, e.g. after a rise of the L1 base fee, they are promoted again once
+		// they can cover the fee
+		unfunded := list.FilterFrom(pool.l1DataFeeCovered(addr))
+		for _, tx := range unfunded {
+			hash := tx.Hash()
+			log.Trace("Demoting pending transaction not covering L1 data fee", "hash", hash)
+
+			// Internal shuffle shouldn't touch the lookup set.
+			pool.enqueueTx(hash, tx, false, false)
+		}
+		pendingL1DataFeeMeter.Mark(int64(len(unfunded)))
+
+		pendingGauge.Dec(int64(len(olds) + len(drops) + len(invalids) + len(unfunded)))
 		if pool.locals.contains(addr) {
-			localGauge.Dec(int64(len(olds) + len(drops) + len(invalids)))
+			localGauge.Dec(int64(len(olds) + len(drops) + len(invalids) + len(unfunded)))
 		}
 		// If there's a gap in front, alert (should never happen) and postpone all transactions
 		if list.Len() > 0 && list.txs.Get(nonce) == nil {
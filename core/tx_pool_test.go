@@ -35,6 +35,8 @@ import (
 	"github.com/scroll-tech/go-ethereum/crypto"
 	"github.com/scroll-tech/go-ethereum/event"
 	"github.com/scroll-tech/go-ethereum/params"
+	"github.com/scroll-tech/go-ethereum/rollup/fees"
+	"github.com/scroll-tech/go-ethereum/rollup/rcfg"
 	"github.com/scroll-tech/go-ethereum/trie"
 )
 
@@ -324,6 +326,168 @@ func TestInvalidTransactions(t *testing.T) {
 	}
 }
 
+func testSetL1BaseFee(pool *TxPool, l1BaseFee *big.Int) {
+	pool.mu.Lock()
+	pool.currentState.SetState(rcfg.L1GasPriceOracleAddress, rcfg.L1BaseFeeSlot, common.BigToHash(l1BaseFee))
+	pool.currentState.SetState(rcfg.L1GasPriceOracleAddress, rcfg.ScalarSlot, common.BigToHash(big.NewInt(1e9)))
+	pool.mu.Unlock()
+}
+
+// Tests that transactions which can't cover the L1 data fee are rejected, and that
+// pending ones are postponed while they can't cover the fee after it rose.
+func TestTransactionL1DataFeeFunds(t *testing.T) {
+	t.Parallel()
+
+	pool, key := setupTxPool()
+	defer pool.Stop()
+
+	testSetL1BaseFee(pool, big.NewInt(1000))
+	tx0, tx1 := transaction(0, 100000, key), transaction(1, 100000, key)
+	from, _ := deriveSender(tx0)
+
+	l1DataFee, err := fees.CalculateL1DataFee(tx0, pool.currentState)
+	if err != nil {
+		t.Fatalf("failed to calculate L1 data fee: %v", err)
+	}
+	if l1DataFee.Sign() == 0 {
+		t.Fatalf("L1 data fee is zero")
+	}
+	testAddBalance(pool, from, new(big.Int).Sub(new(big.Int).Add(tx0.Cost(), l1DataFee), big.NewInt(1)))
+	if err := pool.AddRemote(tx0); !errors.Is(err, ErrInsufficientFundsForL1DataFee) {
+		t.Fatalf("expected %v, got %v", ErrInsufficientFundsForL1DataFee, err)
+	}
+	testAddBalance(pool, from, big.NewInt(1))
+	if err := pool.addRemoteSync(tx0); err != nil {
+		t.Fatalf("failed to add transaction: %v", err)
+	}
+	// the L1 data fee of every transaction differs slightly due to its signature
+	testAddBalance(pool, from, l1DataFee)
+	if err := pool.addRemoteSync(tx1); err != nil {
+		t.Fatalf("failed to add transaction: %v", err)
+	}
+	if pending, queued := pool.Stats(); pending != 2 || queued != 0 {
+		t.Fatalf("pending/queued mismatch: have %d/%d, want 2/0", pending, queued)
+	}
+
+	// a rise of the L1 base fee postpones the transactions
+	testSetL1BaseFee(pool, big.NewInt(4000))
+	<-pool.requestReset(nil, nil)
+	if pending, queued := pool.Stats(); pending != 0 || queued != 2 {
+		t.Fatalf("pending/queued mismatch: have %d/%d, want 0/2", pending, queued)
+	}
+	if err := validateTxPoolInternals(pool); err != nil {
+		t.Fatalf("pool internal state corrupted: %v", err)
+	}
+
+	// the transactions are promoted again once they can cover the fee
+	testSetL1BaseFee(pool, big.NewInt(1000))
+	<-pool.requestReset(nil, nil)
+	if pending, queued := pool.Stats(); pending != 2 || queued != 0 {
+		t.Fatalf("pending/queued mismatch: have %d/%d, want 2/0", pending, queued)
+	}
+	if err := validateTxPoolInternals(pool); err != nil {
+		t.Fatalf("pool internal state corrupted: %v", err)
+	}
+}
+
+// Tests that the L1 data fees of the pooled transactions are cached until the L1 fee
+// values change, and dropped once the transactions leave the pool.
+func TestTransactionL1DataFeeCache(t *testing.T) {
+	t.Parallel()
+
+	pool, key := setupTxPool()
+	defer pool.Stop()
+
+	testSetL1BaseFee(pool, big.NewInt(1000))
+	<-pool.requestReset(nil, nil)
+
+	tx0, tx1 := transaction(0, 100000, key), transaction(1, 100000, key)
+	from, _ := deriveSender(tx0)
+	testAddBalance(pool, from, big.NewInt(1000000000))
+	if err := pool.addRemoteSync(tx0); err != nil {
+		t.Fatalf("failed to add transaction: %v", err)
+	}
+	if err := pool.addRemoteSync(tx1); err != nil {
+		t.Fatalf("failed to add transaction: %v", err)
+	}
+	cached := pool.l1DataFees[tx0.Hash()]
+	if cached == nil {
+		t.Fatalf("L1 data fee not cached")
+	}
+	// the cache is kept while the L1 fee values are unchanged
+	<-pool.requestReset(nil, nil)
+	if pool.l1DataFees[tx0.Hash()] != cached {
+		t.Fatalf("L1 data fee recomputed without a change of the L1 fee values")
+	}
+	// the fees of removed transactions are dropped on reset
+	pool.mu.Lock()
+	pool.removeTx(tx1.Hash(), true)
+	pool.mu.Unlock()
+	<-pool.requestReset(nil, nil)
+	if _, ok := pool.l1DataFees[tx1.Hash()]; ok {
+		t.Fatalf("L1 data fee of removed transaction still cached")
+	}
+	// a change of the L1 fee values invalidates the cache
+	testSetL1BaseFee(pool, big.NewInt(2000))
+	<-pool.requestReset(nil, nil)
+	want, err := fees.CalculateL1DataFee(tx0, pool.currentState)
+	if err != nil {
+		t.Fatalf("failed to calculate L1 data fee: %v", err)
+	}
+	if have := pool.l1DataFees[tx0.Hash()]; have == nil || have.Cmp(want) != 0 {
+		t.Fatalf("L1 data fee mismatch: have %v, want %v", have, want)
+	}
+}
+
+// Tests that transactions with the sender, nonce and payload of a transaction that
+// exceeded the circuit capacity are rejected, unless they have a lower gas limit.
+func TestTransactionRowConsumptionOverflow(t *testing.T) {
+	t.Parallel()
+
+	pool, key := setupTxPoolWithConfig(noL1DataFeeConfig)
+	defer pool.Stop()
+
+	testAddBalance(pool, crypto.PubkeyToAddress(key.PublicKey), big.NewInt(1000000000))
+	tx := transaction(0, 100000, key)
+	if err := pool.addRemoteSync(tx); err != nil {
+		t.Fatalf("failed to add transaction: %v", err)
+	}
+	pool.RemoveOverflowingTx(tx.Hash())
+	if pending, queued := pool.Stats(); pending != 0 || queued != 0 {
+		t.Fatalf("pending/queued mismatch: have %d/%d, want 0/0", pending, queued)
+	}
+
+	// re-signing the payload with a higher price doesn't help
+	err := pool.AddRemote(pricedTransaction(0, 100000, big.NewInt(2), key))
+	if !errors.Is(err, ErrRowConsumptionOverflow) {
+		t.Fatalf("expected %v, got %v", ErrRowConsumptionOverflow, err)
+	}
+	if code := err.(interface{ ErrorCode() int }).ErrorCode(); code != RowConsumptionOverflowErrorCode {
+		t.Fatalf("error code mismatch: have %d, want %d", code, RowConsumptionOverflowErrorCode)
+	}
+	if err := pool.AddLocal(transaction(0, 200000, key)); !errors.Is(err, ErrRowConsumptionOverflow) {
+		t.Fatalf("expected %v, got %v", ErrRowConsumptionOverflow, err)
+	}
+	// the payload executes differently for another sender or nonce
+	other, _ := crypto.GenerateKey()
+	testAddBalance(pool, crypto.PubkeyToAddress(other.PublicKey), big.NewInt(1000000000))
+	if err := pool.addRemoteSync(transaction(0, 100000, other)); err != nil {
+		t.Fatalf("failed to add transaction of another sender: %v", err)
+	}
+	if err := pool.overflows.check(crypto.PubkeyToAddress(key.PublicKey), transaction(1, 100000, key)); err != nil {
+		t.Fatalf("transaction with another nonce rejected: %v", err)
+	}
+	// another value may take another execution path
+	valueTx, _ := types.SignTx(types.NewTransaction(0, common.Address{}, big.NewInt(200), 100000, big.NewInt(1), nil), types.HomesteadSigner{}, key)
+	if err := pool.overflows.check(crypto.PubkeyToAddress(key.PublicKey), valueTx); err != nil {
+		t.Fatalf("transaction with another value rejected: %v", err)
+	}
+	// a lower gas limit may stop the execution before the capacity is exceeded
+	if err := pool.addRemoteSync(transaction(0, 50000, key)); err != nil {
+		t.Fatalf("failed to add transaction: %v", err)
+	}
+}
+
 func TestTransactionQueue(t *testing.T) {
 	t.Parallel()
 
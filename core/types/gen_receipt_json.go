@@ -30,6 +30,10 @@ func (r Receipt) MarshalJSON() ([]byte, error) {
 		TransactionIndex  hexutil.Uint   `json:"transactionIndex"`
 		ReturnValue       []byte         `json:"returnValue,omitempty"`
 		L1Fee             *hexutil.Big   `json:"l1Fee,omitempty"`
+		L1GasUsed         *hexutil.Big   `json:"l1GasUsed,omitempty"`
+		L1BaseFee         *hexutil.Big   `json:"l1BaseFee,omitempty"`
+		L1FeeOverhead     *hexutil.Big   `json:"l1FeeOverhead,omitempty"`
+		L1FeeScalar       *hexutil.Big   `json:"l1FeeScalar,omitempty"`
 	}
 	var enc Receipt
 	enc.Type = hexutil.Uint64(r.Type)
@@ -46,6 +50,10 @@ func (r Receipt) MarshalJSON() ([]byte, error) {
 	enc.TransactionIndex = hexutil.Uint(r.TransactionIndex)
 	enc.ReturnValue = r.ReturnValue
 	enc.L1Fee = (*hexutil.Big)(r.L1Fee)
+	enc.L1GasUsed = (*hexutil.Big)(r.L1GasUsed)
+	enc.L1BaseFee = (*hexutil.Big)(r.L1BaseFee)
+	enc.L1FeeOverhead = (*hexutil.Big)(r.L1FeeOverhead)
+	enc.L1FeeScalar = (*hexutil.Big)(r.L1FeeScalar)
 	return json.Marshal(&enc)
 }
 
@@ -66,6 +74,10 @@ func (r *Receipt) UnmarshalJSON(input []byte) error {
 		TransactionIndex  *hexutil.Uint   `json:"transactionIndex"`
 		ReturnValue       []byte          `json:"returnValue,omitempty"`
 		L1Fee             *hexutil.Big    `json:"l1Fee,omitempty"`
+		L1GasUsed         *hexutil.Big    `json:"l1GasUsed,omitempty"`
+		L1BaseFee         *hexutil.Big    `json:"l1BaseFee,omitempty"`
+		L1FeeOverhead     *hexutil.Big    `json:"l1FeeOverhead,omitempty"`
+		L1FeeScalar       *hexutil.Big    `json:"l1FeeScalar,omitempty"`
 	}
 	var dec Receipt
 	if err := json.Unmarshal(input, &dec); err != nil {
@@ -118,5 +130,17 @@ func (r *Receipt) UnmarshalJSON(input []byte) error {
 	if dec.L1Fee != nil {
 		r.L1Fee = (*big.Int)(dec.L1Fee)
 	}
+	if dec.L1GasUsed != nil {
+		r.L1GasUsed = (*big.Int)(dec.L1GasUsed)
+	}
+	if dec.L1BaseFee != nil {
+		r.L1BaseFee = (*big.Int)(dec.L1BaseFee)
+	}
+	if dec.L1FeeOverhead != nil {
+		r.L1FeeOverhead = (*big.Int)(dec.L1FeeOverhead)
+	}
+	if dec.L1FeeScalar != nil {
+		r.L1FeeScalar = (*big.Int)(dec.L1FeeScalar)
+	}
 	return nil
 }
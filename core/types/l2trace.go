@@ -24,6 +24,46 @@ type BlockTrace struct {
 	StartL1QueueIndex uint64             `json:"startL1QueueIndex"`
 }
 
+// ProverBlockTrace bundles the execution trace of a block with the block data provers need
+// beside it, so that the input of a block proof is assembled by a single call.
+type ProverBlockTrace struct {
+	*BlockTrace
+	// EndL1QueueIndex is the first L1 message queue index not processed by the block,
+	// the block processes the queue indices in [StartL1QueueIndex, EndL1QueueIndex).
+	EndL1QueueIndex uint64 `json:"endL1QueueIndex"`
+	// RowConsumption and EstimatedRows are nil if the block was not checked by the
+	// circuit capacity checker of this node.
+	RowConsumption *RowConsumption `json:"rowConsumption,omitempty"`
+	EstimatedRows  *uint64         `json:"estimatedRows,omitempty"` // rows of the largest sub-circuit
+	CodecHints     *CodecHints     `json:"codecHints,omitempty"`
+}
+
+// CodecHints describe the data availability encoding of a block by the codec that newly
+// built batches are committed with.
+type CodecHints struct {
+	CodecVersion uint8  `json:"codecVersion"`
+	EncodedSize  uint64 `json:"encodedSize"` // bytes the block adds to the encoding of its chunk
+}
+
+// BlockWitness is the stateless witness of a block: the state the block reads and writes,
+// proven against the state root of its parent, together with the code it executes and the
+// block hashes it accesses, so that the block is re-executed without a state database.
+type BlockWitness struct {
+	ChainID      uint64             `json:"chainID"`
+	Header       *Header            `json:"header"`
+	Transactions []*TransactionData `json:"transactions"`
+	// StorageTrace holds the proofs of the accounts and storage slots accessed by the block
+	StorageTrace *StorageTrace       `json:"storageTrace"`
+	Codes        []hexutil.Bytes     `json:"codes"`
+	BlockHashes  []*BlockHashWitness `json:"blockHashes"`
+}
+
+// BlockHashWitness is the result of a BLOCKHASH lookup in a block.
+type BlockHashWitness struct {
+	Number hexutil.Uint64 `json:"number"`
+	Hash   common.Hash    `json:"hash"`
+}
+
 // StorageTrace stores proofs of storage needed by storage circuit
 type StorageTrace struct {
 	// Root hash before block execution:
@@ -0,0 +1,103 @@
+package types
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+)
+
+// WriteProverBlockTraceJSON writes the JSON encoding of a trace to w. The encoding is the one
+// of json.Marshal, which the RPC server responds with, but the execution results and their
+// struct logs are encoded one at a time, so that the encoding of a large block is never held
+// in memory as a whole. Writes are small, w should be buffered.
+func WriteProverBlockTraceJSON(w io.Writer, trace *ProverBlockTrace) error {
+	if trace == nil || trace.BlockTrace == nil || trace.ExecutionResults == nil {
+		return writeJSON(w, trace)
+	}
+	results := trace.ExecutionResults
+	full, tail := *trace, *trace
+	blockTrace := *trace.BlockTrace
+	blockTrace.ExecutionResults = []*ExecutionResult{}
+	full.BlockTrace = &blockTrace
+	// the fields following the execution results, behind zero fields of a fixed encoding
+	tail.BlockTrace = &BlockTrace{
+		ExecutionResults:  []*ExecutionResult{},
+		MPTWitness:        trace.MPTWitness,
+		WithdrawTrieRoot:  trace.WithdrawTrieRoot,
+		StartL1QueueIndex: trace.StartL1QueueIndex,
+	}
+	return writeStreamedJSONArray(w, &full, &tail, "executionResults", len(results), func(i int) error {
+		return writeExecutionResultJSON(w, results[i])
+	})
+}
+
+func writeExecutionResultJSON(w io.Writer, result *ExecutionResult) error {
+	if result == nil || result.StructLogs == nil {
+		return writeJSON(w, result)
+	}
+	full := *result
+	full.StructLogs = []*StructLogRes{}
+	// the fields following the struct logs, behind zero fields of a fixed encoding
+	tail := ExecutionResult{
+		StructLogs: []*StructLogRes{},
+		CallTrace:  result.CallTrace,
+		Prestate:   result.Prestate,
+	}
+	return writeStreamedJSONArray(w, &full, &tail, "structLogs", len(result.StructLogs), func(i int) error {
+		return writeJSON(w, result.StructLogs[i])
+	})
+}
+
+func writeJSON(w io.Writer, v interface{}) error {
+	enc, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(enc)
+	return err
+}
+
+// writeStreamedJSONArray writes the encoding of full, an object whose field holds an empty
+// array, with the n elements written by writeElem in the array. The encoding of tail, whose
+// fields before the array have a fixed encoding, locates the fields following the array.
+func writeStreamedJSONArray(w io.Writer, full, tail interface{}, field string, n int, writeElem func(i int) error) error {
+	fullEnc, err := json.Marshal(full)
+	if err != nil {
+		return err
+	}
+	tailEnc, err := json.Marshal(tail)
+	if err != nil {
+		return err
+	}
+	marker := append(strconv.AppendQuote(nil, field), ":[]"...)
+	pos := bytes.Index(tailEnc, marker)
+	if pos < 0 {
+		return fmt.Errorf("missing field %s", field)
+	}
+	suffix := tailEnc[pos+len(marker):]
+	split := len(fullEnc) - len(suffix) - len(marker)
+	if split < 0 || !bytes.Equal(fullEnc[split:], tailEnc[pos:]) {
+		return fmt.Errorf("unexpected encoding of field %s", field)
+	}
+	// everything up to the opening bracket of the array
+	if _, err := w.Write(fullEnc[:split+len(marker)-1]); err != nil {
+		return err
+	}
+	for i := 0; i < n; i++ {
+		if i > 0 {
+			if _, err := io.WriteString(w, ","); err != nil {
+				return err
+			}
+		}
+		if err := writeElem(i); err != nil {
+			return err
+		}
+	}
+	if _, err := io.WriteString(w, "]"); err != nil {
+		return err
+	}
+	_, err = w.Write(suffix)
+	return err
+}
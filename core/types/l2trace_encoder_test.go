@@ -0,0 +1,62 @@
+package types
+
+import (
+	"bytes"
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	"github.com/scroll-tech/go-ethereum/common"
+)
+
+func TestWriteProverBlockTraceJSON(t *testing.T) {
+	rows := uint64(300)
+	witness := json.RawMessage(`{"executionResults":[]}`)
+	traces := []*ProverBlockTrace{
+		nil,
+		{EndL1QueueIndex: 3},
+		{BlockTrace: &BlockTrace{ChainID: 534352}, EndL1QueueIndex: 3},
+		{BlockTrace: &BlockTrace{ExecutionResults: []*ExecutionResult{}}},
+		{
+			BlockTrace: &BlockTrace{
+				ChainID: 534352,
+				Header:  &Header{Number: big.NewInt(10), Difficulty: common.Big0},
+				ExecutionResults: []*ExecutionResult{
+					{Gas: 21000, ReturnValue: "0x"},
+					nil,
+					{Gas: 21000, StructLogs: []*StructLogRes{}, Prestate: json.RawMessage(`{}`)},
+					{
+						Gas:              50000,
+						Failed:           true,
+						CallTrace:        json.RawMessage(`{"type":"CALL"}`),
+						PoseidonCodeHash: &common.Hash{1},
+						StructLogs: []*StructLogRes{
+							{Pc: 0, Op: "PUSH1", Gas: 100, GasCost: 3},
+							{Pc: 2, Op: "SLOAD", Gas: 97, GasCost: 2100, Storage: map[string]string{"0x01": "0x02"}},
+						},
+					},
+				},
+				MPTWitness:        &witness,
+				WithdrawTrieRoot:  common.HexToHash("0xaa"),
+				StartL1QueueIndex: 1,
+			},
+			EndL1QueueIndex: 2,
+			RowConsumption:  &RowConsumption{{Name: "evm", RowNumber: rows}},
+			EstimatedRows:   &rows,
+			CodecHints:      &CodecHints{CodecVersion: 0, EncodedSize: 160},
+		},
+	}
+	for i, trace := range traces {
+		want, err := json.Marshal(trace)
+		if err != nil {
+			t.Fatalf("trace %d: failed to marshal: %v", i, err)
+		}
+		var buf bytes.Buffer
+		if err := WriteProverBlockTraceJSON(&buf, trace); err != nil {
+			t.Fatalf("trace %d: failed to write: %v", i, err)
+		}
+		if !bytes.Equal(buf.Bytes(), want) {
+			t.Errorf("trace %d: encoding mismatch\nhave %s\nwant %s", i, buf.Bytes(), want)
+		}
+	}
+}
@@ -79,6 +79,12 @@ type Receipt struct {
 
 	// Scroll rollup
 	L1Fee *big.Int `json:"l1Fee,omitempty"`
+	// The L1 gas charged for the transaction data and the values of the L1GasPriceOracle
+	// the L1 fee was computed from. Not set for L1 messages and receipts of older nodes.
+	L1GasUsed     *big.Int `json:"l1GasUsed,omitempty"`
+	L1BaseFee     *big.Int `json:"l1BaseFee,omitempty"`
+	L1FeeOverhead *big.Int `json:"l1FeeOverhead,omitempty"`
+	L1FeeScalar   *big.Int `json:"l1FeeScalar,omitempty"`
 }
 
 type receiptMarshaling struct {
@@ -91,6 +97,10 @@ type receiptMarshaling struct {
 	BlockNumber       *hexutil.Big
 	TransactionIndex  hexutil.Uint
 	L1Fee             *hexutil.Big
+	L1GasUsed         *hexutil.Big
+	L1BaseFee         *hexutil.Big
+	L1FeeOverhead     *hexutil.Big
+	L1FeeScalar       *hexutil.Big
 }
 
 // receiptRLP is the consensus encoding of a receipt.
@@ -107,6 +117,10 @@ type storedReceiptRLP struct {
 	CumulativeGasUsed uint64
 	Logs              []*LogForStorage
 	L1Fee             *big.Int
+	L1GasUsed         *big.Int `rlp:"optional"`
+	L1BaseFee         *big.Int `rlp:"optional"`
+	L1FeeOverhead     *big.Int `rlp:"optional"`
+	L1FeeScalar       *big.Int `rlp:"optional"`
 }
 
 // v5StoredReceiptRLP is the storage encoding of a receipt used in database version 5.
@@ -301,6 +315,10 @@ func (r *ReceiptForStorage) EncodeRLP(w io.Writer) error {
 		CumulativeGasUsed: r.CumulativeGasUsed,
 		Logs:              make([]*LogForStorage, len(r.Logs)),
 		L1Fee:             r.L1Fee,
+		L1GasUsed:         r.L1GasUsed,
+		L1BaseFee:         r.L1BaseFee,
+		L1FeeOverhead:     r.L1FeeOverhead,
+		L1FeeScalar:       r.L1FeeScalar,
 	}
 	for i, log := range r.Logs {
 		enc.Logs[i] = (*LogForStorage)(log)
@@ -346,6 +364,10 @@ func decodeStoredReceiptRLP(r *ReceiptForStorage, blob []byte) error {
 	}
 	r.Bloom = CreateBloom(Receipts{(*Receipt)(r)})
 	r.L1Fee = stored.L1Fee
+	r.L1GasUsed = stored.L1GasUsed
+	r.L1BaseFee = stored.L1BaseFee
+	r.L1FeeOverhead = stored.L1FeeOverhead
+	r.L1FeeScalar = stored.L1FeeScalar
 
 	return nil
 }
@@ -174,6 +174,54 @@ func TestLegacyReceiptDecoding(t *testing.T) {
 	}
 }
 
+func TestReceiptL1FeeStorage(t *testing.T) {
+	receipt := &Receipt{
+		Status:            ReceiptStatusSuccessful,
+		CumulativeGasUsed: 1,
+		Logs:              []*Log{},
+		L1Fee:             big.NewInt(1000),
+		L1GasUsed:         big.NewInt(2000),
+		L1BaseFee:         big.NewInt(3),
+		L1FeeOverhead:     big.NewInt(100),
+		L1FeeScalar:       big.NewInt(1000000000),
+	}
+	enc, err := rlp.EncodeToBytes((*ReceiptForStorage)(receipt))
+	if err != nil {
+		t.Fatalf("Error encoding receipt: %v", err)
+	}
+	var dec ReceiptForStorage
+	if err := rlp.DecodeBytes(enc, &dec); err != nil {
+		t.Fatalf("Error decoding RLP receipt: %v", err)
+	}
+	for _, field := range []struct {
+		name       string
+		want, have *big.Int
+	}{
+		{"L1Fee", receipt.L1Fee, dec.L1Fee},
+		{"L1GasUsed", receipt.L1GasUsed, dec.L1GasUsed},
+		{"L1BaseFee", receipt.L1BaseFee, dec.L1BaseFee},
+		{"L1FeeOverhead", receipt.L1FeeOverhead, dec.L1FeeOverhead},
+		{"L1FeeScalar", receipt.L1FeeScalar, dec.L1FeeScalar},
+	} {
+		if field.have == nil || field.have.Cmp(field.want) != 0 {
+			t.Fatalf("Receipt %s mismatch, want %v, have %v", field.name, field.want, field.have)
+		}
+	}
+
+	// receipts stored without the fee components only carry the L1 fee
+	receipt.L1GasUsed, receipt.L1BaseFee, receipt.L1FeeOverhead, receipt.L1FeeScalar = nil, nil, nil, nil
+	if enc, err = encodeAsStoredReceiptRLP(receipt); err != nil {
+		t.Fatalf("Error encoding receipt: %v", err)
+	}
+	dec = ReceiptForStorage{}
+	if err := rlp.DecodeBytes(enc, &dec); err != nil {
+		t.Fatalf("Error decoding RLP receipt: %v", err)
+	}
+	if dec.L1Fee.Cmp(receipt.L1Fee) != 0 || dec.L1GasUsed != nil || dec.L1FeeScalar != nil {
+		t.Fatalf("Receipt L1 fee mismatch, want %v, have %v (gas used %v)", receipt.L1Fee, dec.L1Fee, dec.L1GasUsed)
+	}
+}
+
 func encodeAsStoredReceiptRLP(want *Receipt) ([]byte, error) {
 	stored := &storedReceiptRLP{
 		PostStateOrStatus: want.statusEncoding(),
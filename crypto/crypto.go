@@ -61,6 +61,18 @@ type KeccakState interface {
 	Read([]byte) (int, error)
 }
 
+// Implementations of Keccak-256 of golang.org/x/crypto/sha3. The portable Go implementation
+// is selected at build time with the purego build tag, see KeccakBackend.
+const (
+	KeccakBackendNative = "native" // using assembly where available
+	KeccakBackendGo     = "go"     // portable Go implementation
+)
+
+// KeccakBackend returns the name of the Keccak-256 implementation the binary was built with.
+func KeccakBackend() string {
+	return keccakBackend
+}
+
 // NewKeccakState creates a new KeccakState
 func NewKeccakState() KeccakState {
 	return sha3.NewLegacyKeccak256().(KeccakState)
@@ -49,6 +49,15 @@ func TestKeccak256Hasher(t *testing.T) {
 	checkhash(t, "Sha3-256-array", func(in []byte) []byte { h := HashData(hasher, in); return h[:] }, msg, exp)
 }
 
+func TestKeccakBackend(t *testing.T) {
+	if backend := KeccakBackend(); backend != KeccakBackendNative && backend != KeccakBackendGo {
+		t.Fatalf("unknown backend %s", backend)
+	}
+	msg := []byte("abc")
+	exp, _ := hex.DecodeString("4e03657aea45a94fc7d47ba826c8d667c0d1e6e33a64a036ec44f58fa12d6c45")
+	checkhash(t, "Keccak256-"+KeccakBackend(), func(in []byte) []byte { return Keccak256(in) }, msg, exp)
+}
+
 func TestToECDSAErrors(t *testing.T) {
 	if _, err := HexToECDSA("0000000000000000000000000000000000000000000000000000000000000000"); err == nil {
 		t.Fatal("HexToECDSA should've returned error")
@@ -19,14 +19,113 @@ package kzg4844
 
 import (
 	"embed"
+	"encoding/json"
 	"errors"
+	"fmt"
 	"hash"
+	"os"
+	"sync"
 	"sync/atomic"
+
+	gokzg4844 "github.com/crate-crypto/go-kzg-4844"
 )
 
 //go:embed trusted_setup.json
 var content embed.FS
 
+var (
+	// trustedSetup is the JSON encoded trusted setup the KZG libraries are initialized
+	// with. If nil, the setup embedded in the binary is used.
+	trustedSetup     []byte
+	trustedSetupLock sync.Mutex
+
+	// trustedSetupLoaded is set once a KZG library has been initialized.
+	trustedSetupLoaded atomic.Bool
+)
+
+// UseTrustedSetup overrides the embedded trusted setup with the JSON encoded setup
+// stored at path. It must be called before any KZG operation is performed.
+func UseTrustedSetup(path string) error {
+	config, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	if _, err := parseTrustedSetup(config); err != nil {
+		return fmt.Errorf("invalid trusted setup %s: %w", path, err)
+	}
+
+	trustedSetupLock.Lock()
+	defer trustedSetupLock.Unlock()
+
+	if trustedSetupLoaded.Load() {
+		return errors.New("trusted setup cannot be changed after KZG has been initialized")
+	}
+	trustedSetup = config
+	return nil
+}
+
+// VerifyTrustedSetup initializes the active KZG library and checks that a proof
+// created with the trusted setup verifies against it.
+func VerifyTrustedSetup() error {
+	var blob Blob
+	for i := 0; i < len(blob); i += 32 {
+		// keep each field element below the BLS modulus
+		blob[i+31] = byte(i / 32)
+	}
+	commitment, err := BlobToCommitment(blob)
+	if err != nil {
+		return err
+	}
+	proof, err := ComputeBlobProof(blob, commitment)
+	if err != nil {
+		return err
+	}
+	return VerifyBlobProof(blob, commitment, proof)
+}
+
+// loadTrustedSetup returns the parameters of the configured trusted setup and
+// prevents it from being changed afterwards.
+func loadTrustedSetup() (*gokzg4844.JSONTrustedSetup, error) {
+	trustedSetupLock.Lock()
+	defer trustedSetupLock.Unlock()
+
+	config := trustedSetup
+	if config == nil {
+		var err error
+		if config, err = content.ReadFile("trusted_setup.json"); err != nil {
+			return nil, err
+		}
+	}
+	trustedSetupLoaded.Store(true)
+	return parseTrustedSetup(config)
+}
+
+// parseTrustedSetup decodes a JSON encoded trusted setup and checks that it is well formed.
+func parseTrustedSetup(config []byte) (*gokzg4844.JSONTrustedSetup, error) {
+	params := new(gokzg4844.JSONTrustedSetup)
+	if err := json.Unmarshal(config, params); err != nil {
+		return nil, err
+	}
+	// note: the library expects 0x prefixed compressed points of the correct length
+	for i, g1 := range params.SetupG1Lagrange {
+		if len(g1) != 2+2*48 {
+			return nil, fmt.Errorf("invalid G1 point %d", i)
+		}
+	}
+	if len(params.SetupG2) == 0 {
+		return nil, errors.New("missing G2 points")
+	}
+	for i, g2 := range params.SetupG2 {
+		if len(g2) != 2+2*96 {
+			return nil, fmt.Errorf("invalid G2 point %d", i)
+		}
+	}
+	if err := gokzg4844.CheckTrustedSetupIsWellFormed(params); err != nil {
+		return nil, err
+	}
+	return params, nil
+}
+
 // Blob represents a 4844 data blob.
 type Blob [131072]byte
 
@@ -19,11 +19,9 @@
 package kzg4844
 
 import (
-	"encoding/json"
 	"errors"
 	"sync"
 
-	gokzg4844 "github.com/crate-crypto/go-kzg-4844"
 	ckzg4844 "github.com/ethereum/c-kzg-4844/bindings/go"
 
 	"github.com/scroll-tech/go-ethereum/common/hexutil"
@@ -37,17 +35,10 @@ var ckzgIniter sync.Once
 
 // ckzgInit initializes the KZG library with the provided trusted setup.
 func ckzgInit() {
-	config, err := content.ReadFile("trusted_setup.json")
+	params, err := loadTrustedSetup()
 	if err != nil {
 		panic(err)
 	}
-	params := new(gokzg4844.JSONTrustedSetup)
-	if err = json.Unmarshal(config, params); err != nil {
-		panic(err)
-	}
-	if err = gokzg4844.CheckTrustedSetupIsWellFormed(params); err != nil {
-		panic(err)
-	}
 	g1s := make([]byte, len(params.SetupG1Lagrange)*(len(params.SetupG1Lagrange[0])-2)/2)
 	for i, g1 := range params.SetupG1Lagrange {
 		copy(g1s[i*(len(g1)-2)/2:], hexutil.MustDecode(g1))
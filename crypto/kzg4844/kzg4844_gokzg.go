@@ -17,7 +17,6 @@
 package kzg4844
 
 import (
-	"encoding/json"
 	"sync"
 
 	gokzg4844 "github.com/crate-crypto/go-kzg-4844"
@@ -31,14 +30,10 @@ var gokzgIniter sync.Once
 
 // gokzgInit initializes the KZG library with the provided trusted setup.
 func gokzgInit() {
-	config, err := content.ReadFile("trusted_setup.json")
+	params, err := loadTrustedSetup()
 	if err != nil {
 		panic(err)
 	}
-	params := new(gokzg4844.JSONTrustedSetup)
-	if err = json.Unmarshal(config, params); err != nil {
-		panic(err)
-	}
 	context, err = gokzg4844.NewContext4096(params)
 	if err != nil {
 		panic(err)
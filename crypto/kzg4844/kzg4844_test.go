@@ -18,6 +18,8 @@ package kzg4844
 
 import (
 	"crypto/rand"
+	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/consensys/gnark-crypto/ecc/bls12-381/fr"
@@ -193,3 +195,34 @@ func benchmarkVerifyBlobProof(b *testing.B, ckzg bool) {
 		VerifyBlobProof(blob, commitment, proof)
 	}
 }
+
+func TestTrustedSetup(t *testing.T) {
+	dir := t.TempDir()
+	invalid := filepath.Join(dir, "invalid.json")
+	if err := os.WriteFile(invalid, []byte(`{"g1_lagrange": [], "g2_monomial": []}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := UseTrustedSetup(invalid); err == nil {
+		t.Fatal("expected malformed trusted setup to be rejected")
+	}
+	if err := UseTrustedSetup(filepath.Join(dir, "missing.json")); err == nil {
+		t.Fatal("expected missing trusted setup to be rejected")
+	}
+
+	if err := VerifyTrustedSetup(); err != nil {
+		t.Fatalf("failed to verify embedded trusted setup: %v", err)
+	}
+
+	// the setup cannot be replaced once it is in use
+	config, err := content.ReadFile("trusted_setup.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	valid := filepath.Join(dir, "valid.json")
+	if err := os.WriteFile(valid, config, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := UseTrustedSetup(valid); err == nil {
+		t.Fatal("expected trusted setup override after initialization to fail")
+	}
+}
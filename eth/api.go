@@ -35,9 +35,15 @@ import (
 	"github.com/scroll-tech/go-ethereum/core/rawdb"
 	"github.com/scroll-tech/go-ethereum/core/state"
 	"github.com/scroll-tech/go-ethereum/core/types"
+	"github.com/scroll-tech/go-ethereum/ethdb"
 	"github.com/scroll-tech/go-ethereum/internal/ethapi"
 	"github.com/scroll-tech/go-ethereum/log"
 	"github.com/scroll-tech/go-ethereum/rlp"
+	"github.com/scroll-tech/go-ethereum/rollup/eventbus"
+	"github.com/scroll-tech/go-ethereum/rollup/rcfg"
+	"github.com/scroll-tech/go-ethereum/rollup/rollup_sync_service"
+	"github.com/scroll-tech/go-ethereum/rollup/sync_service"
+	"github.com/scroll-tech/go-ethereum/rollup/withdrawtrie"
 	"github.com/scroll-tech/go-ethereum/rpc"
 	"github.com/scroll-tech/go-ethereum/trie"
 )
@@ -258,6 +264,144 @@ func (api *PrivateAdminAPI) ImportChain(file string) (bool, error) {
 	return true, nil
 }
 
+// rollupSyncService returns the rollup sync service, or an error if rollup verification is disabled.
+func (api *PrivateAdminAPI) rollupSyncService() (*rollup_sync_service.RollupSyncService, error) {
+	service := api.eth.RollupSyncService()
+	if service == nil {
+		return nil, errors.New("rollup verifier is not enabled")
+	}
+	return service, nil
+}
+
+// RollupSyncStatus returns the state of the rollup sync service.
+func (api *PrivateAdminAPI) RollupSyncStatus() (*rollup_sync_service.Status, error) {
+	service, err := api.rollupSyncService()
+	if err != nil {
+		return nil, err
+	}
+	return service.Status(), nil
+}
+
+// RollupSyncPause stops the ingestion of rollup events, e.g. during maintenance.
+func (api *PrivateAdminAPI) RollupSyncPause() (bool, error) {
+	service, err := api.rollupSyncService()
+	if err != nil {
+		return false, err
+	}
+	service.Pause()
+	return true, nil
+}
+
+// RollupSyncResume restarts the ingestion of rollup events after a pause or a halt at a poisoned batch.
+func (api *PrivateAdminAPI) RollupSyncResume() (bool, error) {
+	service, err := api.rollupSyncService()
+	if err != nil {
+		return false, err
+	}
+	service.Resume()
+	return true, nil
+}
+
+// RollupSyncResetTo rewinds the rollup sync progress to the given L1 block, so that
+// the rollup events after it are fetched and validated again.
+func (api *PrivateAdminAPI) RollupSyncResetTo(l1Block uint64) (bool, error) {
+	service, err := api.rollupSyncService()
+	if err != nil {
+		return false, err
+	}
+	if err := service.ResetTo(l1Block); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// RollupSyncClearPoisonedBatch removes the poisoned marker of a batch that failed validation,
+// accepting the values finalized on L1. The sync, and in the halt-import verify mode the
+// block import, resume once no poisoned batch is left.
+func (api *PrivateAdminAPI) RollupSyncClearPoisonedBatch(batchIndex uint64) (bool, error) {
+	service, err := api.rollupSyncService()
+	if err != nil {
+		return false, err
+	}
+	if err := service.ClearPoisonedBatch(batchIndex); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// RollupSyncRetryPoisonedBatch removes the poisoned marker of a batch that failed validation and
+// validates the batch again. The sync, and in the halt-import verify mode the block import,
+// resume once no poisoned batch is left.
+func (api *PrivateAdminAPI) RollupSyncRetryPoisonedBatch(batchIndex uint64) (bool, error) {
+	service, err := api.rollupSyncService()
+	if err != nil {
+		return false, err
+	}
+	if err := service.RetryPoisonedBatch(batchIndex); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// defaultL1ReverifyBlocks is the number of recently processed L1 blocks that are
+// fetched again after switching the L1 mode, about a day of L1 blocks.
+const defaultL1ReverifyBlocks = 7200
+
+// L1Mode returns the current source of L1 data, "trusted" or "verified".
+func (api *PrivateAdminAPI) L1Mode() (sync_service.L1Mode, error) {
+	if api.eth.l1ModeClient == nil {
+		return sync_service.L1ModeTrusted, nil
+	}
+	return api.eth.l1ModeClient.Mode(), nil
+}
+
+// SetL1Mode switches the source of L1 data without resync. The mode is kept across
+// restarts unless --l1.mode is set. The L1 message and rollup sync services then fetch
+// the last reverifyBlocks processed L1 blocks again from the new source, so that the
+// recent rollup events and L1 messages are verified under the new trust model. It
+// defaults to defaultL1ReverifyBlocks, zero skips re-verification.
+func (api *PrivateAdminAPI) SetL1Mode(mode string, reverifyBlocks *hexutil.Uint64) (bool, error) {
+	if api.eth.l1ModeClient == nil {
+		return false, errors.New("no verified L1 endpoint configured")
+	}
+	l1Mode, err := sync_service.ParseL1Mode(mode)
+	if err != nil {
+		return false, err
+	}
+	if l1Mode == api.eth.l1ModeClient.Mode() {
+		return false, nil
+	}
+	if err := api.eth.l1ModeClient.SetMode(l1Mode); err != nil {
+		return false, err
+	}
+	rawdb.WriteL1Mode(api.eth.chainDb, string(l1Mode))
+
+	blocks := uint64(defaultL1ReverifyBlocks)
+	if reverifyBlocks != nil {
+		blocks = uint64(*reverifyBlocks)
+	}
+	if blocks == 0 {
+		return true, nil
+	}
+	rewind := func(latest uint64) uint64 {
+		if latest > blocks {
+			return latest - blocks
+		}
+		return 0
+	}
+	if service := api.eth.SyncService(); service != nil {
+		if err := service.ResetTo(rewind(service.LatestProcessedBlock())); err != nil {
+			return true, fmt.Errorf("failed to re-verify L1 messages: %v", err)
+		}
+	}
+	if service := api.eth.RollupSyncService(); service != nil {
+		if err := service.ResetTo(rewind(service.Status().SyncedL1BlockNumber)); err != nil {
+			return true, fmt.Errorf("failed to re-verify rollup events: %v", err)
+		}
+	}
+	return true, nil
+}
+
 // PublicDebugAPI is the collection of Ethereum full node APIs exposed
 // over the public debugging endpoint.
 type PublicDebugAPI struct {
@@ -623,6 +767,61 @@ type l1MessageTxRPC struct {
 	Data       hexutil.Bytes   `json:"data"`
 	Sender     common.Address  `json:"sender"`
 	Hash       common.Hash     `json:"hash"`
+
+	// L1 transaction that enqueued the message, if recorded when it was synced
+	L1BlockNumber *uint64      `json:"l1BlockNumber,omitempty"`
+	L1TxHash      *common.Hash `json:"l1TxHash,omitempty"`
+
+	// fee fields of messages enqueued in the L1MessageQueueV2 contract
+	L2BaseFee *hexutil.Big `json:"l2BaseFee,omitempty"`
+	Fee       *hexutil.Big `json:"fee,omitempty"`
+}
+
+// newL1MessageTxRPC returns the RPC-layer representation of an L1 message,
+// including the metadata stored alongside it.
+func newL1MessageTxRPC(db ethdb.Reader, msg *types.L1MessageTx) *l1MessageTxRPC {
+	rpcMsg := &l1MessageTxRPC{
+		QueueIndex: msg.QueueIndex,
+		Gas:        msg.Gas,
+		To:         msg.To,
+		Value:      (*hexutil.Big)(msg.Value),
+		Data:       msg.Data,
+		Sender:     msg.Sender,
+		Hash:       types.NewTx(msg).Hash(),
+	}
+	if origin := rawdb.ReadL1MessageOrigin(db, msg.QueueIndex); origin != nil {
+		rpcMsg.L1BlockNumber = &origin.L1BlockNumber
+		rpcMsg.L1TxHash = &origin.L1TxHash
+	}
+	if fee := rawdb.ReadL1MessageFee(db, msg.QueueIndex); fee != nil {
+		rpcMsg.L2BaseFee = (*hexutil.Big)(fee.L2BaseFee)
+		rpcMsg.Fee = (*hexutil.Big)(fee.Fee)
+	}
+	return rpcMsg
+}
+
+// maxL1MessagesPerPage caps the number of L1 messages returned by one GetL1MessagesInRange call.
+const maxL1MessagesPerPage = 1000
+
+// rpcL1MessagePage is a page of the L1 messages of a queue index range.
+type rpcL1MessagePage struct {
+	Messages       []*l1MessageTxRPC `json:"messages"`
+	NextQueueIndex *uint64           `json:"nextQueueIndex,omitempty"` // start of the next page, unset on the last page
+}
+
+// rpcL1MessageInclusion is the canonical L2 transaction that executed an L1 message.
+type rpcL1MessageInclusion struct {
+	QueueIndex  uint64      `json:"queueIndex"`
+	BlockNumber uint64      `json:"blockNumber"`
+	BlockHash   common.Hash `json:"blockHash"`
+	TxHash      common.Hash `json:"txHash"`
+}
+
+// rpcSkippedL1Message is an L1 message that a committed batch skipped instead of including it.
+type rpcSkippedL1Message struct {
+	QueueIndex uint64          `json:"queueIndex"`
+	BatchIndex uint64          `json:"batchIndex"`
+	Message    *l1MessageTxRPC `json:"message,omitempty"` // unset if the message is not synced locally
 }
 
 // NewScrollAPI creates a new RPC service to query the L1 message database.
@@ -641,16 +840,79 @@ func (api *ScrollAPI) GetL1MessageByIndex(ctx context.Context, queueIndex uint64
 	if msg == nil {
 		return nil, nil
 	}
-	rpcMsg := l1MessageTxRPC{
-		QueueIndex: msg.QueueIndex,
-		Gas:        msg.Gas,
-		To:         msg.To,
-		Value:      (*hexutil.Big)(msg.Value),
-		Data:       msg.Data,
-		Sender:     msg.Sender,
-		Hash:       types.NewTx(msg).Hash(),
+	return newL1MessageTxRPC(api.eth.ChainDb(), msg), nil
+}
+
+// GetL1MessagesInRange returns the synced L1 messages with queue indices in [from, to]. At most
+// limit messages are returned per call, maxL1MessagesPerPage by default, and the next page starts
+// at NextQueueIndex of the result.
+func (api *ScrollAPI) GetL1MessagesInRange(ctx context.Context, from, to uint64, limit *uint64) (*rpcL1MessagePage, error) {
+	if from > to {
+		return nil, fmt.Errorf("invalid queue index range, from: %d, to: %d", from, to)
+	}
+	count := uint64(maxL1MessagesPerPage)
+	if limit != nil && *limit > 0 && *limit < count {
+		count = *limit
+	}
+	if to-from < count {
+		count = to - from + 1
+	}
+
+	page := &rpcL1MessagePage{Messages: []*l1MessageTxRPC{}}
+	if from > rawdb.ReadHighestSyncedQueueIndex(api.eth.ChainDb()) {
+		return page, nil
+	}
+	msgs := rawdb.ReadL1MessagesFrom(api.eth.ChainDb(), from, count)
+	for i := range msgs {
+		page.Messages = append(page.Messages, newL1MessageTxRPC(api.eth.ChainDb(), &msgs[i]))
+	}
+	if uint64(len(msgs)) == count {
+		if last := msgs[len(msgs)-1].QueueIndex; last < to && last < rawdb.ReadHighestSyncedQueueIndex(api.eth.ChainDb()) {
+			next := last + 1
+			page.NextQueueIndex = &next
+		}
+	}
+	return page, nil
+}
+
+// GetL1MessageInclusion returns the L2 block and transaction that executed an L1 message,
+// or nil if the message is not included in the canonical chain yet. Messages included in
+// blocks imported by an older version are looked up by their transaction hash.
+func (api *ScrollAPI) GetL1MessageInclusion(ctx context.Context, queueIndex uint64) (*rpcL1MessageInclusion, error) {
+	db := api.eth.ChainDb()
+	// note: the index of blocks rewound by SetHead is not removed, so check that the block is canonical
+	if inclusion := rawdb.ReadL1MessageInclusion(db, queueIndex); inclusion != nil && rawdb.ReadCanonicalHash(db, inclusion.L2BlockNumber) == inclusion.L2BlockHash {
+		return &rpcL1MessageInclusion{QueueIndex: queueIndex, BlockNumber: inclusion.L2BlockNumber, BlockHash: inclusion.L2BlockHash, TxHash: inclusion.TxHash}, nil
+	}
+	msg := rawdb.ReadL1Message(db, queueIndex)
+	if msg == nil {
+		return nil, nil
+	}
+	txHash := types.NewTx(msg).Hash()
+	tx, blockHash, blockNumber, _ := rawdb.ReadTransaction(db, txHash)
+	if tx == nil {
+		return nil, nil
 	}
-	return &rpcMsg, nil
+	return &rpcL1MessageInclusion{QueueIndex: queueIndex, BlockNumber: blockNumber, BlockHash: blockHash, TxHash: txHash}, nil
+}
+
+// GetSkippedL1Messages returns the L1 messages with queue indices in [from, to] that committed
+// batches skipped, at most maxL1MessagesPerPage per call. Skipped messages are recorded by the
+// rollup verifier from the commit data on L1.
+func (api *ScrollAPI) GetSkippedL1Messages(ctx context.Context, from, to uint64) ([]*rpcSkippedL1Message, error) {
+	if from > to {
+		return nil, fmt.Errorf("invalid queue index range, from: %d, to: %d", from, to)
+	}
+	db := api.eth.ChainDb()
+	messages := []*rpcSkippedL1Message{}
+	for _, skipped := range rawdb.ReadSkippedL1Messages(db, from, to, maxL1MessagesPerPage) {
+		message := &rpcSkippedL1Message{QueueIndex: skipped.QueueIndex, BatchIndex: skipped.BatchIndex}
+		if msg := rawdb.ReadL1Message(db, skipped.QueueIndex); msg != nil {
+			message.Message = newL1MessageTxRPC(db, msg)
+		}
+		messages = append(messages, message)
+	}
+	return messages, nil
 }
 
 // GetFirstQueueIndexNotInL2Block returns the first L1 message queue index that is
@@ -691,11 +953,17 @@ func (api *ScrollAPI) rpcMarshalBlock(ctx context.Context, b *types.Block, fullT
 }
 
 // GetBlockByHash returns the requested block. When fullTx is true all transactions in the block are returned in full
-// detail, otherwise only the transaction hash is returned.
+// detail, otherwise only the transaction hash is returned. The canonical and finalized fields report whether the
+// block is part of the local canonical chain and whether it is finalized.
 func (api *ScrollAPI) GetBlockByHash(ctx context.Context, hash common.Hash, fullTx bool) (map[string]interface{}, error) {
 	block, err := api.eth.APIBackend.BlockByHash(ctx, hash)
 	if block != nil {
-		return api.rpcMarshalBlock(ctx, block, fullTx)
+		fields, err := api.rpcMarshalBlock(ctx, block, fullTx)
+		if err != nil {
+			return nil, err
+		}
+		ethapi.RPCMarshalChainMembership(ctx, api.eth.APIBackend, block.Header(), fields)
+		return fields, nil
 	}
 	return nil, err
 }
@@ -716,25 +984,44 @@ func (api *ScrollAPI) GetNumSkippedTransactions(ctx context.Context) (uint64, er
 }
 
 // SyncStatus includes L2 block sync height, L1 rollup sync height,
-// L1 message sync height, and L2 finalized block height.
+// L1 message sync height, and L2 finalized block height. The batch
+// and lag fields are only set if the rollup verifier is enabled.
 type SyncStatus struct {
 	L2BlockSyncHeight      uint64 `json:"l2BlockSyncHeight,omitempty"`
 	L1RollupSyncHeight     uint64 `json:"l1RollupSyncHeight,omitempty"`
 	L1MessageSyncHeight    uint64 `json:"l1MessageSyncHeight,omitempty"`
 	L2FinalizedBlockHeight uint64 `json:"l2FinalizedBlockHeight,omitempty"`
+
+	LatestCommittedBatchIndex *uint64 `json:"latestCommittedBatchIndex,omitempty"`
+	LatestFinalizedBatchIndex *uint64 `json:"latestFinalizedBatchIndex,omitempty"`
+	L1RollupSyncLag           *uint64 `json:"l1RollupSyncLag,omitempty"`     // confirmed L1 blocks not processed yet
+	L2FinalizedBlockLag       *uint64 `json:"l2FinalizedBlockLag,omitempty"` // local L2 blocks not finalized yet
+
+	L1RollupSyncRecovery *rollup_sync_service.RecoveryProgress  `json:"l1RollupSyncRecovery,omitempty"` // set while catching up with L1
+	L1Finalized          *rollup_sync_service.L1FinalizedStatus `json:"l1Finalized,omitempty"`          // latest finalized L1 block observed
+	L1BatchPointers      *rollup_sync_service.L1BatchPointers   `json:"l1BatchPointers,omitempty"`      // newest batches reported by L1 while catching up, not validated yet
+
+	PeerFinalizedL2BlockHeight *uint64 `json:"peerFinalizedL2BlockHeight,omitempty"` // highest finalized L2 block advertised by peers serving batch metadata, not verified
 }
 
 // SyncStatus returns the overall rollup status including L2 block sync height, L1 rollup sync height,
-// L1 message sync height, and L2 finalized block height.
-func (api *ScrollAPI) SyncStatus(_ context.Context) *SyncStatus {
+// L1 message sync height, L2 finalized block height, and if the rollup verifier is enabled, the latest
+// committed and finalized batches, the estimated lag of the finality data and the staleness of the
+// L1 finalized block. The L2 finalized block height is omitted while the L1 finalized block is stale.
+// While the verifier catches up with L1, the newest batches reported by L1 are included before
+// they are validated. The finalized L2 block advertised by peers serving batch metadata is
+// included as an unverified reference.
+func (api *ScrollAPI) SyncStatus(ctx context.Context) *SyncStatus {
 	status := &SyncStatus{}
+	reader := api.eth.BatchReader()
 
 	l2BlockHeader := api.eth.blockchain.CurrentHeader()
 	if l2BlockHeader != nil {
 		status.L2BlockSyncHeight = l2BlockHeader.Number.Uint64()
 	}
 
-	l1RollupSyncHeightPtr := rawdb.ReadRollupEventSyncedL1BlockNumber(api.eth.ChainDb())
+	// note: the rollup heights of a follower node are left unset if the writer node is unreachable.
+	l1RollupSyncHeightPtr, _ := reader.RollupEventSyncedL1BlockNumber(ctx)
 	if l1RollupSyncHeightPtr != nil {
 		status.L1RollupSyncHeight = *l1RollupSyncHeightPtr
 	}
@@ -744,14 +1031,570 @@ func (api *ScrollAPI) SyncStatus(_ context.Context) *SyncStatus {
 		status.L1MessageSyncHeight = *l1MessageSyncHeightPtr
 	}
 
-	l2FinalizedBlockHeightPtr := rawdb.ReadFinalizedL2BlockNumber(api.eth.ChainDb())
+	status.PeerFinalizedL2BlockHeight = api.eth.handler.peerFinalizedL2Block()
+
+	l2FinalizedBlockHeightPtr, _ := reader.FinalizedL2BlockNumber(ctx)
 	if l2FinalizedBlockHeightPtr != nil {
 		status.L2FinalizedBlockHeight = *l2FinalizedBlockHeightPtr
 	}
 
+	if service := api.eth.RollupSyncService(); service != nil {
+		progress := service.Progress()
+		status.LatestCommittedBatchIndex = progress.LatestCommittedBatch
+		status.LatestFinalizedBatchIndex = progress.LatestFinalizedBatch
+		status.L1RollupSyncLag = &progress.L1BlockLag
+		status.L2FinalizedBlockLag = &progress.FinalizedL2BlockLag
+		status.L1RollupSyncRecovery = service.RecoveryProgress()
+		status.L1Finalized = service.L1Finalized()
+		status.L1BatchPointers = service.L1BatchPointers()
+		if status.L1Finalized != nil && status.L1Finalized.Stale {
+			// note: the finalized height is derived from a stalled L1 view
+			status.L2FinalizedBlockHeight = 0
+		}
+	} else if api.eth.RollupFollower() != nil {
+		if progress, err := rollup_sync_service.ReadProgress(ctx, reader, status.L2BlockSyncHeight); err == nil {
+			status.LatestCommittedBatchIndex = progress.LatestCommittedBatch
+			status.LatestFinalizedBatchIndex = progress.LatestFinalizedBatch
+			status.L2FinalizedBlockLag = &progress.FinalizedL2BlockLag
+		}
+		status.L1RollupSyncRecovery, _ = reader.RecoveryProgress(ctx)
+		status.L1BatchPointers, _ = reader.L1BatchPointers(ctx)
+	}
+
 	return status
 }
 
+// rpcChunkBlockRange is the RPC-layer representation of the block range of a chunk.
+type rpcChunkBlockRange struct {
+	StartBlockNumber uint64 `json:"startBlockNumber"`
+	EndBlockNumber   uint64 `json:"endBlockNumber"`
+}
+
+// rpcFinalizedBatch is the RPC-layer representation of a finalized batch.
+type rpcFinalizedBatch struct {
+	BatchIndex            uint64                `json:"batchIndex"`
+	BatchHash             common.Hash           `json:"batchHash"`
+	StateRoot             common.Hash           `json:"stateRoot"`
+	WithdrawRoot          common.Hash           `json:"withdrawRoot"`
+	TotalL1MessagePopped  uint64                `json:"totalL1MessagePopped"`
+	ChunkBlockRanges      []*rpcChunkBlockRange `json:"chunkBlockRanges"`
+	CommitTxHash          *common.Hash          `json:"commitTxHash,omitempty"`
+	CommitL1BlockNumber   *uint64               `json:"commitL1BlockNumber,omitempty"`
+	CommitL1BlockHash     *common.Hash          `json:"commitL1BlockHash,omitempty"`
+	FinalizeTxHash        *common.Hash          `json:"finalizeTxHash,omitempty"`
+	FinalizeL1BlockNumber *uint64               `json:"finalizeL1BlockNumber,omitempty"`
+	FinalizeL1BlockHash   *common.Hash          `json:"finalizeL1BlockHash,omitempty"`
+}
+
+// GetBatchByIndex returns the metadata of a finalized batch, or nil if the batch is not finalized
+// or has not been synced by the rollup verifier yet.
+func (api *ScrollAPI) GetBatchByIndex(ctx context.Context, batchIndex uint64) (*rpcFinalizedBatch, error) {
+	reader := api.eth.BatchReader()
+	meta, err := reader.FinalizedBatchMeta(ctx, batchIndex)
+	if meta == nil || err != nil {
+		return nil, err
+	}
+	batch := &rpcFinalizedBatch{
+		BatchIndex:           batchIndex,
+		BatchHash:            meta.BatchHash,
+		StateRoot:            meta.StateRoot,
+		WithdrawRoot:         meta.WithdrawRoot,
+		TotalL1MessagePopped: meta.TotalL1MessagePopped,
+	}
+	chunkBlockRanges, err := reader.BatchChunkRanges(ctx, batchIndex)
+	if err != nil {
+		return nil, err
+	}
+	for _, cr := range chunkBlockRanges {
+		batch.ChunkBlockRanges = append(batch.ChunkBlockRanges, &rpcChunkBlockRange{
+			StartBlockNumber: cr.StartBlockNumber,
+			EndBlockNumber:   cr.EndBlockNumber,
+		})
+	}
+	l1Meta, err := reader.BatchL1Meta(ctx, batchIndex)
+	if err != nil {
+		return nil, err
+	}
+	if l1Meta != nil {
+		if l1Meta.CommitTxHash != (common.Hash{}) {
+			batch.CommitTxHash = &l1Meta.CommitTxHash
+			batch.CommitL1BlockNumber = &l1Meta.CommitL1BlockNumber
+			if l1Meta.CommitL1BlockHash != (common.Hash{}) {
+				batch.CommitL1BlockHash = &l1Meta.CommitL1BlockHash
+			}
+		}
+		if l1Meta.FinalizeTxHash != (common.Hash{}) {
+			batch.FinalizeTxHash = &l1Meta.FinalizeTxHash
+			batch.FinalizeL1BlockNumber = &l1Meta.FinalizeL1BlockNumber
+			if l1Meta.FinalizeL1BlockHash != (common.Hash{}) {
+				batch.FinalizeL1BlockHash = &l1Meta.FinalizeL1BlockHash
+			}
+		}
+	}
+	return batch, nil
+}
+
+// rpcBlockBatchStatus describes the batch that contains an L2 block.
+type rpcBlockBatchStatus struct {
+	BlockNumber uint64       `json:"blockNumber"`
+	BlockHash   common.Hash  `json:"blockHash"`
+	BatchIndex  uint64       `json:"batchIndex"`
+	Committed   bool         `json:"committed"`
+	Finalized   bool         `json:"finalized"`
+	BatchHash   *common.Hash `json:"batchHash,omitempty"`
+}
+
+// GetBatchByBlockNumber returns the index and status of the batch that contains the given L2 block,
+// or nil if the block is not part of any batch known to the rollup verifier yet.
+func (api *ScrollAPI) GetBatchByBlockNumber(ctx context.Context, blockNrOrHash rpc.BlockNumberOrHash) (*rpcBlockBatchStatus, error) {
+	header, err := api.eth.APIBackend.HeaderByNumberOrHash(ctx, blockNrOrHash)
+	if header == nil || err != nil {
+		return nil, err
+	}
+	blockNumber := header.Number.Uint64()
+
+	reader := api.eth.BatchReader()
+	batchIndex, err := reader.BatchIndexByL2BlockNumber(ctx, blockNumber)
+	if batchIndex == nil || err != nil {
+		return nil, err
+	}
+	chunkBlockRanges, err := reader.BatchChunkRanges(ctx, *batchIndex)
+	if err != nil || len(chunkBlockRanges) == 0 || chunkBlockRanges[0].StartBlockNumber > blockNumber {
+		return nil, err
+	}
+
+	status := &rpcBlockBatchStatus{
+		BlockNumber: blockNumber,
+		BlockHash:   header.Hash(),
+		BatchIndex:  *batchIndex,
+		Committed:   true,
+	}
+	meta, err := reader.FinalizedBatchMeta(ctx, *batchIndex)
+	if err != nil {
+		return nil, err
+	}
+	if meta != nil {
+		status.Finalized = true
+		status.BatchHash = &meta.BatchHash
+	}
+	return status, nil
+}
+
+// GetBatchByL1Block returns the batches committed and finalized in the first L1 block at or after
+// the given L1 block number that committed or finalized a batch, or nil if there is no such block.
+// Only the L1 events processed since the L1 block index was introduced are covered.
+func (api *ScrollAPI) GetBatchByL1Block(ctx context.Context, l1BlockNumber uint64) (*rollup_sync_service.L1BlockBatches, error) {
+	return api.eth.BatchReader().BatchesByL1Block(ctx, l1BlockNumber)
+}
+
+// rpcFinalizedRoots are the roots of a batch as recorded by its finalize event on L1.
+type rpcFinalizedRoots struct {
+	BatchIndex            uint64      `json:"batchIndex"`
+	StateRoot             common.Hash `json:"stateRoot"`
+	WithdrawRoot          common.Hash `json:"withdrawRoot"`
+	FinalizeTxHash        common.Hash `json:"finalizeTxHash"`
+	FinalizeL1BlockNumber uint64      `json:"finalizeL1BlockNumber"`
+}
+
+// GetFinalizedRoots returns the state root and withdraw root finalized on L1 for the given batch,
+// as recorded in the finalize event processed by the rollup verifier, so that independent state
+// reconstructions can be compared with the on-chain record without querying L1. It returns nil if
+// the batch is not finalized, if it was finalized within a bundle without being its last batch, as
+// L1 only records the roots of the last batch, or if it was synced before the roots were recorded.
+func (api *ScrollAPI) GetFinalizedRoots(ctx context.Context, batchIndex uint64) (*rpcFinalizedRoots, error) {
+	l1Meta, err := api.eth.BatchReader().BatchL1Meta(ctx, batchIndex)
+	if l1Meta == nil || err != nil {
+		return nil, err
+	}
+	if l1Meta.FinalizeTxHash == (common.Hash{}) || l1Meta.FinalizedStateRoot == (common.Hash{}) {
+		return nil, nil
+	}
+	return &rpcFinalizedRoots{
+		BatchIndex:            batchIndex,
+		StateRoot:             l1Meta.FinalizedStateRoot,
+		WithdrawRoot:          l1Meta.FinalizedWithdrawRoot,
+		FinalizeTxHash:        l1Meta.FinalizeTxHash,
+		FinalizeL1BlockNumber: l1Meta.FinalizeL1BlockNumber,
+	}, nil
+}
+
+// rpcWithdrawProof is the Merkle proof of an L2->L1 message against the withdraw root of a
+// finalized batch, as submitted to relayMessageWithProof of the L1ScrollMessenger.
+type rpcWithdrawProof struct {
+	MessageHash  common.Hash   `json:"messageHash"`
+	MessageNonce uint64        `json:"messageNonce"`
+	BatchIndex   uint64        `json:"batchIndex"`
+	WithdrawRoot common.Hash   `json:"withdrawRoot"`
+	Proof        hexutil.Bytes `json:"proof"`
+}
+
+// GetWithdrawProof returns the Merkle proof of the L2->L1 message with the given hash against the
+// withdraw root of a finalized batch. The message leaves are read from the withdraw message index,
+// or collected from the AppendMessage events of the local blocks up to the end of the batch if the
+// index does not cover them, in which case the call takes a while on long chains.
+func (api *ScrollAPI) GetWithdrawProof(ctx context.Context, messageHash common.Hash, batchIndex uint64) (*rpcWithdrawProof, error) {
+	reader := api.eth.BatchReader()
+	meta, err := reader.FinalizedBatchMeta(ctx, batchIndex)
+	if err != nil {
+		return nil, err
+	}
+	if meta == nil {
+		return nil, fmt.Errorf("batch %d is not finalized", batchIndex)
+	}
+	chunkBlockRanges, err := reader.BatchChunkRanges(ctx, batchIndex)
+	if err != nil {
+		return nil, err
+	}
+	if len(chunkBlockRanges) == 0 {
+		return nil, fmt.Errorf("chunk block ranges of batch %d not found", batchIndex)
+	}
+	endBlockNumber := chunkBlockRanges[len(chunkBlockRanges)-1].EndBlockNumber
+
+	var nonce *uint64
+	leaves, ok := withdrawtrie.MessageLeaves(api.eth.ChainDb(), endBlockNumber)
+	if ok {
+		nonce = rawdb.ReadWithdrawMessageNonce(api.eth.ChainDb(), messageHash)
+	} else {
+		if leaves, err = api.scanWithdrawMessages(ctx, endBlockNumber); err != nil {
+			return nil, err
+		}
+		for i, leaf := range leaves {
+			if leaf == messageHash {
+				index := uint64(i)
+				nonce = &index
+				break
+			}
+		}
+	}
+	if nonce == nil || *nonce >= uint64(len(leaves)) || leaves[*nonce] != messageHash {
+		return nil, fmt.Errorf("withdraw message %s not found up to batch %d", messageHash.Hex(), batchIndex)
+	}
+	root, proof, err := withdrawtrie.GenerateProof(leaves, *nonce)
+	if err != nil {
+		return nil, err
+	}
+	if root != meta.WithdrawRoot {
+		return nil, fmt.Errorf("withdraw root mismatch in batch %d, local: %s, finalized: %s", batchIndex, root.Hex(), meta.WithdrawRoot.Hex())
+	}
+	result := &rpcWithdrawProof{
+		MessageHash:  messageHash,
+		MessageNonce: *nonce,
+		BatchIndex:   batchIndex,
+		WithdrawRoot: root,
+	}
+	for _, item := range proof {
+		result.Proof = append(result.Proof, item.Bytes()...)
+	}
+	return result, nil
+}
+
+// withdrawRootTarget selects the point of a withdraw root query: a block number, tag or hash as
+// accepted by eth_getBlockByNumber and eth_getBalance, or a batch as {"batchIndex": "0x..."}.
+type withdrawRootTarget struct {
+	rpc.BlockNumberOrHash
+	BatchIndex *hexutil.Uint64
+}
+
+func (t *withdrawRootTarget) UnmarshalJSON(data []byte) error {
+	var batch struct {
+		BatchIndex *hexutil.Uint64 `json:"batchIndex"`
+	}
+	if err := json.Unmarshal(data, &batch); err == nil && batch.BatchIndex != nil {
+		t.BatchIndex = batch.BatchIndex
+		return nil
+	}
+	return t.BlockNumberOrHash.UnmarshalJSON(data)
+}
+
+// rpcWithdrawRoot is the withdraw trie root after an L2 block.
+type rpcWithdrawRoot struct {
+	BlockNumber  uint64      `json:"blockNumber"`
+	BlockHash    common.Hash `json:"blockHash"`
+	BatchIndex   *uint64     `json:"batchIndex,omitempty"` // set if the root was queried by batch
+	WithdrawRoot common.Hash `json:"withdrawRoot"`
+}
+
+// GetWithdrawRoot returns the withdraw trie root after the given block, or after the last block of
+// the given batch. It is served from the withdraw roots persisted at block import or the withdraw
+// message index, and only falls back to the block state for blocks imported before either existed.
+func (api *ScrollAPI) GetWithdrawRoot(ctx context.Context, target withdrawRootTarget) (*rpcWithdrawRoot, error) {
+	var (
+		header     *types.Header
+		batchIndex *uint64
+		err        error
+	)
+	if target.BatchIndex != nil {
+		index := uint64(*target.BatchIndex)
+		chunkBlockRanges, err := api.eth.BatchReader().BatchChunkRanges(ctx, index)
+		if err != nil {
+			return nil, err
+		}
+		if len(chunkBlockRanges) == 0 {
+			return nil, fmt.Errorf("batch %d not found", index)
+		}
+		endBlockNumber := chunkBlockRanges[len(chunkBlockRanges)-1].EndBlockNumber
+		if header, err = api.eth.APIBackend.HeaderByNumber(ctx, rpc.BlockNumber(endBlockNumber)); err != nil {
+			return nil, err
+		}
+		batchIndex = &index
+	} else if header, err = api.eth.APIBackend.HeaderByNumberOrHash(ctx, target.BlockNumberOrHash); err != nil {
+		return nil, err
+	}
+	if header == nil {
+		return nil, errors.New("block not found")
+	}
+	result := &rpcWithdrawRoot{
+		BlockNumber: header.Number.Uint64(),
+		BlockHash:   header.Hash(),
+		BatchIndex:  batchIndex,
+	}
+
+	if root := rawdb.ReadBlockWithdrawRoot(api.eth.ChainDb(), header.Hash()); root != nil {
+		result.WithdrawRoot = *root
+		return result, nil
+	}
+	if rawdb.ReadCanonicalHash(api.eth.ChainDb(), result.BlockNumber) == header.Hash() {
+		if leaves, ok := withdrawtrie.MessageLeaves(api.eth.ChainDb(), result.BlockNumber); ok {
+			result.WithdrawRoot = withdrawtrie.MessageRoot(leaves)
+			return result, nil
+		}
+	}
+	statedb, _, err := api.eth.APIBackend.StateAndHeaderByNumberOrHash(ctx, rpc.BlockNumberOrHashWithHash(header.Hash(), false))
+	if statedb == nil || err != nil {
+		return nil, fmt.Errorf("withdraw root of block %d is not available: %v", result.BlockNumber, err)
+	}
+	result.WithdrawRoot = withdrawtrie.ReadWTRSlot(rcfg.L2MessageQueueAddress, statedb)
+	return result, statedb.Error()
+}
+
+// scanWithdrawMessages collects the leaves of the withdraw trie after the given block from the
+// AppendMessage events of the local blocks.
+func (api *ScrollAPI) scanWithdrawMessages(ctx context.Context, endBlockNumber uint64) ([]common.Hash, error) {
+	var leaves []common.Hash
+	for number := uint64(0); number <= endBlockNumber; number++ {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		hash := rawdb.ReadCanonicalHash(api.eth.ChainDb(), number)
+		if hash == (common.Hash{}) {
+			return nil, fmt.Errorf("block %d not found", number)
+		}
+		for _, receipt := range rawdb.ReadRawReceipts(api.eth.ChainDb(), hash, number) {
+			for _, l := range receipt.Logs {
+				nonce, leaf, ok := withdrawtrie.ParseAppendMessageLog(l)
+				if !ok {
+					continue
+				}
+				if nonce != uint64(len(leaves)) {
+					return nil, fmt.Errorf("unexpected withdraw message nonce in block %d, expected: %d, got: %d", number, len(leaves), nonce)
+				}
+				leaves = append(leaves, leaf)
+			}
+		}
+	}
+	return leaves, nil
+}
+
+// SyncGaps returns the ranges of batch indices whose CommitBatch events were missing
+// from the L1 logs and could not be recovered by re-querying L1.
+func (api *ScrollAPI) SyncGaps(ctx context.Context) ([]rollup_sync_service.BatchGap, error) {
+	if api.eth.RollupSyncService() == nil && api.eth.RollupFollower() == nil {
+		return nil, errors.New("rollup verifier is not enabled")
+	}
+	return api.eth.BatchReader().BatchGaps(ctx)
+}
+
+// RevalidateBatch re-runs the validation of a finalized batch against the local chain
+// and reports the fields that differ from the stored batch metadata. It does not
+// modify the database, so it can be used to check integrity after hardware incidents.
+func (api *ScrollAPI) RevalidateBatch(ctx context.Context, batchIndex uint64) (*rollup_sync_service.RevalidationResult, error) {
+	service := api.eth.RollupSyncService()
+	if follower := api.eth.RollupFollower(); service == nil && follower != nil {
+		var result *rollup_sync_service.RevalidationResult
+		return result, follower.Forward(ctx, &result, "scroll_revalidateBatch", batchIndex)
+	}
+	if service == nil {
+		return nil, errors.New("rollup verifier is not enabled")
+	}
+	return service.RevalidateBatch(batchIndex)
+}
+
+// GetTxBatchInclusionProof returns the data needed to check that a transaction is part of a
+// batch finalized on L1 without a full node: the batch header, the chunk hashes of the batch,
+// and the position of the transaction in the chunk containing its block.
+func (api *ScrollAPI) GetTxBatchInclusionProof(ctx context.Context, txHash common.Hash) (*rollup_sync_service.TxInclusionProof, error) {
+	service := api.eth.RollupSyncService()
+	if follower := api.eth.RollupFollower(); service == nil && follower != nil {
+		var proof *rollup_sync_service.TxInclusionProof
+		return proof, follower.Forward(ctx, &proof, "scroll_getTxBatchInclusionProof", txHash)
+	}
+	if service == nil {
+		return nil, errors.New("rollup verifier is not enabled")
+	}
+	return service.TxInclusionProof(txHash)
+}
+
+// GetDAUsage returns how many bytes the local blocks after the last committed batch would
+// occupy on L1 for every supported codec, and how close they are to the chunk and batch limits.
+func (api *ScrollAPI) GetDAUsage(ctx context.Context) (*rollup_sync_service.DAUsage, error) {
+	service := api.eth.RollupSyncService()
+	if follower := api.eth.RollupFollower(); service == nil && follower != nil {
+		var usage *rollup_sync_service.DAUsage
+		return usage, follower.Forward(ctx, &usage, "scroll_getDAUsage")
+	}
+	if service == nil {
+		return nil, errors.New("rollup verifier is not enabled")
+	}
+	return service.DAUsage()
+}
+
+// rpcL1EndpointRange describes the L1 endpoints that served the rollup events of a range of L1 blocks.
+type rpcL1EndpointRange struct {
+	FromL1BlockNumber uint64   `json:"fromL1BlockNumber"`
+	ToL1BlockNumber   uint64   `json:"toL1BlockNumber"`
+	Endpoints         []string `json:"endpoints"`
+}
+
+// GetL1EndpointHistory returns the L1 endpoints that served the rollup events of the processed
+// L1 block ranges overlapping [from, to]. Endpoints are only recorded if multiple are configured.
+func (api *ScrollAPI) GetL1EndpointHistory(ctx context.Context, from, to uint64) ([]*rpcL1EndpointRange, error) {
+	if from > to {
+		return nil, fmt.Errorf("invalid L1 block range, from: %d, to: %d", from, to)
+	}
+	endpointRanges, err := api.eth.BatchReader().L1EndpointRanges(ctx, from, to)
+	if err != nil {
+		return nil, err
+	}
+	ranges := []*rpcL1EndpointRange{}
+	for _, r := range endpointRanges {
+		ranges = append(ranges, &rpcL1EndpointRange{
+			FromL1BlockNumber: r.FromL1BlockNumber,
+			ToL1BlockNumber:   r.ToL1BlockNumber,
+			Endpoints:         r.Endpoints,
+		})
+	}
+	return ranges, nil
+}
+
+// rpcRollupEvent is the notification sent to rollupEvents subscribers.
+type rpcRollupEvent struct {
+	Type             string       `json:"type"` // "commit", "revert" or "finalize"
+	BatchIndex       uint64       `json:"batchIndex"`
+	BatchHash        common.Hash  `json:"batchHash"`
+	StartBlockNumber *uint64      `json:"startBlockNumber,omitempty"`
+	EndBlockNumber   *uint64      `json:"endBlockNumber,omitempty"`
+	StateRoot        *common.Hash `json:"stateRoot,omitempty"`
+	WithdrawRoot     *common.Hash `json:"withdrawRoot,omitempty"`
+	L1BlockNumber    uint64       `json:"l1BlockNumber"`
+	L1TxHash         common.Hash  `json:"l1TxHash"`
+}
+
+// RollupEvents creates a subscription that is triggered each time a batch is
+// committed, reverted or finalized on L1 and processed by the rollup verifier.
+func (api *ScrollAPI) RollupEvents(ctx context.Context) (*rpc.Subscription, error) {
+	follower := api.eth.RollupFollower()
+	if api.eth.RollupSyncService() == nil && follower == nil {
+		return &rpc.Subscription{}, errors.New("rollup verifier is not enabled")
+	}
+	notifier, supported := rpc.NotifierFromContext(ctx)
+	if !supported {
+		return &rpc.Subscription{}, rpc.ErrNotificationsUnsupported
+	}
+
+	if api.eth.RollupSyncService() == nil {
+		return api.followRollupEvents(notifier, follower)
+	}
+
+	rpcSub := notifier.CreateSubscription()
+
+	go func() {
+		bus := api.eth.RollupEventBus()
+		committedCh := make(chan eventbus.BatchCommittedEvent, 16)
+		revertedCh := make(chan eventbus.BatchRevertedEvent, 16)
+		finalizedCh := make(chan eventbus.BatchFinalizedEvent, 16)
+		committedSub := bus.SubscribeBatchCommitted(committedCh)
+		revertedSub := bus.SubscribeBatchReverted(revertedCh)
+		finalizedSub := bus.SubscribeBatchFinalized(finalizedCh)
+		defer committedSub.Unsubscribe()
+		defer revertedSub.Unsubscribe()
+		defer finalizedSub.Unsubscribe()
+
+		for {
+			select {
+			case ev := <-committedCh:
+				notifier.Notify(rpcSub.ID, &rpcRollupEvent{
+					Type:             "commit",
+					BatchIndex:       ev.BatchIndex,
+					BatchHash:        ev.BatchHash,
+					StartBlockNumber: &ev.StartBlockNumber,
+					EndBlockNumber:   &ev.EndBlockNumber,
+					L1BlockNumber:    ev.L1BlockNumber,
+					L1TxHash:         ev.L1TxHash,
+				})
+			case ev := <-revertedCh:
+				notifier.Notify(rpcSub.ID, &rpcRollupEvent{
+					Type:          "revert",
+					BatchIndex:    ev.BatchIndex,
+					BatchHash:     ev.BatchHash,
+					L1BlockNumber: ev.L1BlockNumber,
+					L1TxHash:      ev.L1TxHash,
+				})
+			case ev := <-finalizedCh:
+				notifier.Notify(rpcSub.ID, &rpcRollupEvent{
+					Type:             "finalize",
+					BatchIndex:       ev.BatchIndex,
+					BatchHash:        ev.BatchHash,
+					StartBlockNumber: &ev.StartBlockNumber,
+					EndBlockNumber:   &ev.EndBlockNumber,
+					StateRoot:        &ev.StateRoot,
+					WithdrawRoot:     &ev.WithdrawRoot,
+					L1BlockNumber:    ev.L1BlockNumber,
+					L1TxHash:         ev.L1TxHash,
+				})
+			case <-rpcSub.Err():
+				return
+			case <-notifier.Closed():
+				return
+			}
+		}
+	}()
+
+	return rpcSub, nil
+}
+
+// followRollupEvents relays the rollup events of the writer node followed by a follower node.
+func (api *ScrollAPI) followRollupEvents(notifier *rpc.Notifier, follower *rollup_sync_service.Follower) (*rpc.Subscription, error) {
+	events := make(chan *rpcRollupEvent, 16)
+	sub, err := follower.Subscribe(events, "rollupEvents")
+	if err != nil {
+		return &rpc.Subscription{}, err
+	}
+
+	rpcSub := notifier.CreateSubscription()
+
+	go func() {
+		defer sub.Unsubscribe()
+
+		for {
+			select {
+			case ev := <-events:
+				notifier.Notify(rpcSub.ID, ev)
+			case err := <-sub.Err():
+				if err != nil {
+					log.Warn("Rollup events subscription of rollup writer node failed", "err", err)
+				}
+				return
+			case <-rpcSub.Err():
+				return
+			case <-notifier.Closed():
+				return
+			}
+		}
+	}()
+
+	return rpcSub, nil
+}
+
 // EstimateL1DataFee returns an estimate of the L1 data fee required to
 // process the given transaction against the current pending block.
 func (api *ScrollAPI) EstimateL1DataFee(ctx context.Context, args ethapi.TransactionArgs, blockNrOrHash *rpc.BlockNumberOrHash) (*hexutil.Uint64, error) {
@@ -769,6 +1612,48 @@ func (api *ScrollAPI) EstimateL1DataFee(ctx context.Context, args ethapi.Transac
 	return &result, nil
 }
 
+// FeeEstimate is the estimated cost of a transaction on L2: the execution gas and the L1 data
+// fee, along with the L1GasPriceOracle values the data fee is computed from. The components
+// are not set for L1 messages or if the fee vault is disabled.
+type FeeEstimate struct {
+	Gas           hexutil.Uint64 `json:"gas"`
+	L1DataFee     *hexutil.Big   `json:"l1DataFee"`
+	L1GasUsed     *hexutil.Big   `json:"l1GasUsed,omitempty"`
+	L1BaseFee     *hexutil.Big   `json:"l1BaseFee,omitempty"`
+	L1FeeOverhead *hexutil.Big   `json:"l1FeeOverhead,omitempty"`
+	L1FeeScalar   *hexutil.Big   `json:"l1FeeScalar,omitempty"`
+}
+
+// EstimateFees estimates the execution gas of the given transaction and the L1 data fee of
+// the transaction with the estimated gas limit, against the current pending block.
+func (api *ScrollAPI) EstimateFees(ctx context.Context, args ethapi.TransactionArgs, blockNrOrHash *rpc.BlockNumberOrHash) (*FeeEstimate, error) {
+	bNrOrHash := rpc.BlockNumberOrHashWithNumber(rpc.PendingBlockNumber)
+	if blockNrOrHash != nil {
+		bNrOrHash = *blockNrOrHash
+	}
+	backend := api.eth.APIBackend
+
+	gas, err := ethapi.DoEstimateGas(ctx, backend, args, bNrOrHash, backend.RPCGasCap())
+	if err != nil {
+		return nil, err
+	}
+	// the gas limit is part of the transaction posted to L1
+	args.Gas = &gas
+	l1Fee, err := ethapi.EstimateL1Fee(ctx, backend, args, bNrOrHash, nil, 0, backend.RPCGasCap(), backend.ChainConfig())
+	if err != nil {
+		return nil, fmt.Errorf("failed to estimate L1 data fee: %w", err)
+	}
+
+	return &FeeEstimate{
+		Gas:           gas,
+		L1DataFee:     (*hexutil.Big)(l1Fee.L1DataFee),
+		L1GasUsed:     (*hexutil.Big)(l1Fee.L1GasUsed),
+		L1BaseFee:     (*hexutil.Big)(l1Fee.L1BaseFee),
+		L1FeeOverhead: (*hexutil.Big)(l1Fee.Overhead),
+		L1FeeScalar:   (*hexutil.Big)(l1Fee.Scalar),
+	}, nil
+}
+
 // RPCTransaction is the standard RPC transaction return type with some additional skip-related fields.
 type RPCTransaction struct {
 	ethapi.RPCTransaction
@@ -37,6 +37,8 @@ import (
 	"github.com/scroll-tech/go-ethereum/event"
 	"github.com/scroll-tech/go-ethereum/miner"
 	"github.com/scroll-tech/go-ethereum/params"
+	"github.com/scroll-tech/go-ethereum/rollup/fees"
+	"github.com/scroll-tech/go-ethereum/rollup/rollup_sync_service"
 	"github.com/scroll-tech/go-ethereum/rpc"
 )
 
@@ -77,12 +79,18 @@ func (b *EthAPIBackend) HeaderByNumber(ctx context.Context, number rpc.BlockNumb
 		return b.eth.blockchain.CurrentBlock().Header(), nil
 	}
 	if number == rpc.FinalizedBlockNumber {
-		finalizedBlockHeightPtr := rawdb.ReadFinalizedL2BlockNumber(b.eth.ChainDb())
-		if finalizedBlockHeightPtr == nil {
-			return nil, errors.New("L2 finalized block height not found in database")
+		header := b.eth.blockchain.CurrentFinalizedBlock()
+		if header == nil {
+			return nil, errors.New("finalized block not found")
 		}
-		number = rpc.BlockNumber(*finalizedBlockHeightPtr)
-		return b.eth.blockchain.GetHeaderByNumber(uint64(number)), nil
+		return header, nil
+	}
+	if number == rpc.SafeBlockNumber {
+		header := b.eth.blockchain.CurrentSafeBlock()
+		if header == nil {
+			return nil, errors.New("safe block not found")
+		}
+		return header, nil
 	}
 	return b.eth.blockchain.GetHeaderByNumber(uint64(number)), nil
 }
@@ -119,12 +127,18 @@ func (b *EthAPIBackend) BlockByNumber(ctx context.Context, number rpc.BlockNumbe
 		return b.eth.blockchain.CurrentBlock(), nil
 	}
 	if number == rpc.FinalizedBlockNumber {
-		finalizedBlockHeightPtr := rawdb.ReadFinalizedL2BlockNumber(b.eth.ChainDb())
-		if finalizedBlockHeightPtr == nil {
-			return nil, errors.New("L2 finalized block height not found in database")
+		header := b.eth.blockchain.CurrentFinalizedBlock()
+		if header == nil {
+			return nil, errors.New("finalized block not found")
 		}
-		number = rpc.BlockNumber(*finalizedBlockHeightPtr)
-		return b.eth.blockchain.GetBlockByNumber(uint64(number)), nil
+		return b.eth.blockchain.GetBlock(header.Hash(), header.Number.Uint64()), nil
+	}
+	if number == rpc.SafeBlockNumber {
+		header := b.eth.blockchain.CurrentSafeBlock()
+		if header == nil {
+			return nil, errors.New("safe block not found")
+		}
+		return b.eth.blockchain.GetBlock(header.Hash(), header.Number.Uint64()), nil
 	}
 	return b.eth.blockchain.GetBlockByNumber(uint64(number)), nil
 }
@@ -303,7 +317,14 @@ func (b *EthAPIBackend) SubscribeNewTxsEvent(ch chan<- core.NewTxsEvent) event.S
 }
 
 func (b *EthAPIBackend) SyncProgress() ethereum.SyncProgress {
-	return b.eth.Downloader().Progress()
+	progress := b.eth.Downloader().Progress()
+	if service := b.eth.RollupSyncService(); service != nil {
+		progress.Rollup = service.Progress()
+	} else if b.eth.RollupFollower() != nil {
+		// note: the rollup progress is omitted if the writer node is unreachable
+		progress.Rollup, _ = rollup_sync_service.ReadProgress(context.Background(), b.eth.BatchReader(), b.eth.blockchain.CurrentHeader().Number.Uint64())
+	}
+	return progress
 }
 
 func (b *EthAPIBackend) SuggestGasTipCap(ctx context.Context) (*big.Int, error) {
@@ -314,6 +335,10 @@ func (b *EthAPIBackend) FeeHistory(ctx context.Context, blockCount int, lastBloc
 	return b.gpo.FeeHistory(ctx, blockCount, lastBlock, rewardPercentiles)
 }
 
+func (b *EthAPIBackend) L1FeeHistory(ctx context.Context, firstBlock uint64, blockCount int) ([]*fees.L1Fees, error) {
+	return b.gpo.L1FeeHistory(ctx, firstBlock, blockCount)
+}
+
 func (b *EthAPIBackend) ChainDb() ethdb.Database {
 	return b.eth.ChainDb()
 }
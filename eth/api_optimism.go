@@ -0,0 +1,112 @@
+package eth
+
+import (
+	"context"
+
+	"github.com/scroll-tech/go-ethereum/common"
+	"github.com/scroll-tech/go-ethereum/core/rawdb"
+	"github.com/scroll-tech/go-ethereum/core/types"
+	"github.com/scroll-tech/go-ethereum/params"
+)
+
+// OptimismAPI serves the rollup sync state in the shape of the optimism rollup node
+// RPC, so that monitoring built for op-node can be pointed at this node. It is only
+// served if the "optimism" namespace is enabled explicitly.
+type OptimismAPI struct {
+	eth *Ethereum
+}
+
+// NewOptimismAPI creates a new optimism compatibility API.
+func NewOptimismAPI(eth *Ethereum) *OptimismAPI {
+	return &OptimismAPI{eth}
+}
+
+// opL1BlockRef mirrors eth.L1BlockRef of op-node. L1 block hashes are not
+// tracked locally, only the numbers are reported.
+type opL1BlockRef struct {
+	Hash       common.Hash `json:"hash"`
+	Number     uint64      `json:"number"`
+	ParentHash common.Hash `json:"parentHash"`
+	Time       uint64      `json:"timestamp"`
+}
+
+// opBlockID mirrors eth.BlockID of op-node.
+type opBlockID struct {
+	Hash   common.Hash `json:"hash"`
+	Number uint64      `json:"number"`
+}
+
+// opL2BlockRef mirrors eth.L2BlockRef of op-node.
+type opL2BlockRef struct {
+	Hash           common.Hash `json:"hash"`
+	Number         uint64      `json:"number"`
+	ParentHash     common.Hash `json:"parentHash"`
+	Time           uint64      `json:"timestamp"`
+	L1Origin       opBlockID   `json:"l1origin"`
+	SequenceNumber uint64      `json:"sequenceNumber"`
+}
+
+// opSyncStatus mirrors eth.SyncStatus of op-node.
+type opSyncStatus struct {
+	CurrentL1          opL1BlockRef `json:"current_l1"`
+	CurrentL1Finalized opL1BlockRef `json:"current_l1_finalized"`
+	HeadL1             opL1BlockRef `json:"head_l1"`
+	SafeL1             opL1BlockRef `json:"safe_l1"`
+	FinalizedL1        opL1BlockRef `json:"finalized_l1"`
+	UnsafeL2           opL2BlockRef `json:"unsafe_l2"`
+	SafeL2             opL2BlockRef `json:"safe_l2"`
+	FinalizedL2        opL2BlockRef `json:"finalized_l2"`
+}
+
+// SyncStatus returns the sync state in the shape of optimism_syncStatus:
+// the L1 heads are the L1 heights synced by the L1 message and rollup sync services,
+// the unsafe L2 head is the local chain head and the safe and finalized L2 heads are
+// the latest L2 block finalized on L1, since there is no separate safe stage.
+func (api *OptimismAPI) SyncStatus(ctx context.Context) (*opSyncStatus, error) {
+	db := api.eth.ChainDb()
+	reader := api.eth.BatchReader()
+	status := &opSyncStatus{}
+
+	number, err := reader.RollupEventSyncedL1BlockNumber(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if number != nil {
+		status.CurrentL1 = opL1BlockRef{Number: *number}
+		status.CurrentL1Finalized = status.CurrentL1
+		status.SafeL1 = status.CurrentL1
+		status.FinalizedL1 = status.CurrentL1
+	}
+	if number := rawdb.ReadSyncedL1BlockNumber(db); number != nil {
+		status.HeadL1 = opL1BlockRef{Number: *number}
+	}
+
+	if header := api.eth.blockchain.CurrentHeader(); header != nil {
+		status.UnsafeL2 = opL2BlockRefFromHeader(header)
+	}
+	number, err = reader.FinalizedL2BlockNumber(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if number != nil {
+		if header := api.eth.blockchain.GetHeaderByNumber(*number); header != nil {
+			status.FinalizedL2 = opL2BlockRefFromHeader(header)
+			status.SafeL2 = status.FinalizedL2
+		}
+	}
+	return status, nil
+}
+
+// Version returns the version of the node, as optimism_version does.
+func (api *OptimismAPI) Version(_ context.Context) string {
+	return params.VersionWithMeta
+}
+
+func opL2BlockRefFromHeader(header *types.Header) opL2BlockRef {
+	return opL2BlockRef{
+		Hash:       header.Hash(),
+		Number:     header.Number.Uint64(),
+		ParentHash: header.ParentHash,
+		Time:       header.Time,
+	}
+}
@@ -0,0 +1,30 @@
+package eth
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/scroll-tech/go-ethereum/core/rawdb"
+)
+
+func TestOptimismSyncStatus(t *testing.T) {
+	chain := newDivergenceTestChain(t, 10, func(i int) int64 { return 1 })
+	api := NewOptimismAPI(&Ethereum{blockchain: chain.bc, chainDb: chain.db})
+
+	rawdb.WriteRollupEventSyncedL1BlockNumber(chain.db, 100)
+	rawdb.WriteSyncedL1BlockNumber(chain.db, 120)
+	rawdb.WriteFinalizedL2BlockNumber(chain.db, 6)
+
+	status, err := api.SyncStatus(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, uint64(100), status.CurrentL1.Number)
+	assert.Equal(t, uint64(100), status.FinalizedL1.Number)
+	assert.Equal(t, uint64(120), status.HeadL1.Number)
+	assert.Equal(t, uint64(10), status.UnsafeL2.Number)
+	assert.Equal(t, chain.bc.CurrentHeader().Hash(), status.UnsafeL2.Hash)
+	assert.Equal(t, uint64(6), status.FinalizedL2.Number)
+	assert.Equal(t, chain.bc.GetHeaderByNumber(6).Hash(), status.SafeL2.Hash)
+}
@@ -0,0 +1,74 @@
+package eth
+
+import (
+	"context"
+	"reflect"
+	"unicode"
+
+	"github.com/scroll-tech/go-ethereum/internal/jsonschema"
+	"github.com/scroll-tech/go-ethereum/rpc"
+)
+
+var (
+	errorType        = reflect.TypeOf((*error)(nil)).Elem()
+	subscriptionType = reflect.TypeOf((*rpc.Subscription)(nil))
+)
+
+// scrollNotificationTypes are the types of the notifications of the scroll subscriptions,
+// which cannot be derived from the method signatures.
+var scrollNotificationTypes = map[string]reflect.Type{
+	"rollupEvents": reflect.TypeOf(rpcRollupEvent{}),
+}
+
+// rpcResultSchemas holds the JSON schemas of the results of the scroll methods. A schema
+// of a subscription describes its notifications. The named types are defined once.
+type rpcResultSchemas struct {
+	Schema        string                        `json:"$schema"`
+	Methods       map[string]*jsonschema.Schema `json:"methods"`
+	Subscriptions map[string]*jsonschema.Schema `json:"subscriptions"`
+	Definitions   map[string]*jsonschema.Schema `json:"$defs"`
+}
+
+// GetResultSchemas returns the JSON schemas of the results of all methods of the scroll API,
+// so that client libraries can generate typed bindings, including the hex encodings of
+// hashes and quantities.
+func (api *ScrollAPI) GetResultSchemas(ctx context.Context) *rpcResultSchemas {
+	return scrollResultSchemas(reflect.TypeOf(api))
+}
+
+// scrollResultSchemas derives the result schemas of the methods of an API type the way
+// the RPC server registers them.
+func scrollResultSchemas(apiType reflect.Type) *rpcResultSchemas {
+	var (
+		g       = jsonschema.NewGenerator()
+		schemas = &rpcResultSchemas{
+			Schema:        jsonschema.Draft,
+			Methods:       make(map[string]*jsonschema.Schema),
+			Subscriptions: make(map[string]*jsonschema.Schema),
+		}
+	)
+	for i := 0; i < apiType.NumMethod(); i++ {
+		method := apiType.Method(i)
+		name := []rune(method.Name)
+		name[0] = unicode.ToLower(name[0])
+
+		var results []reflect.Type
+		for j := 0; j < method.Type.NumOut(); j++ {
+			if out := method.Type.Out(j); out != errorType {
+				results = append(results, out)
+			}
+		}
+		switch {
+		case len(results) == 0:
+			schemas.Methods[string(name)] = &jsonschema.Schema{Type: "null"}
+		case results[0] == subscriptionType:
+			if notification, ok := scrollNotificationTypes[string(name)]; ok {
+				schemas.Subscriptions[string(name)] = g.Schema(notification)
+			}
+		default:
+			schemas.Methods[string(name)] = g.Schema(results[0])
+		}
+	}
+	schemas.Definitions = g.Definitions()
+	return schemas
+}
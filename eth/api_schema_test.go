@@ -0,0 +1,55 @@
+package eth
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/scroll-tech/go-ethereum/internal/jsonschema"
+)
+
+func TestScrollResultSchemas(t *testing.T) {
+	schemas := scrollResultSchemas(reflect.TypeOf(&ScrollAPI{}))
+	if _, err := json.Marshal(schemas); err != nil {
+		t.Fatalf("failed to encode schemas: %v", err)
+	}
+
+	method, ok := schemas.Methods["getBatchByIndex"]
+	if !ok {
+		t.Fatal("no schema of getBatchByIndex")
+	}
+	if len(method.AnyOf) != 2 || method.AnyOf[0].Ref != "#/$defs/FinalizedBatch" || method.AnyOf[1].Type != "null" {
+		t.Fatalf("unexpected schema of getBatchByIndex: %+v", method)
+	}
+	batch := schemas.Definitions["FinalizedBatch"]
+	if batch == nil {
+		t.Fatal("FinalizedBatch is not defined")
+	}
+	if hash := batch.Properties["batchHash"]; hash == nil || hash.Pattern != "^0x[0-9a-f]{64}$" {
+		t.Fatalf("unexpected schema of batchHash: %+v", hash)
+	}
+	if index := batch.Properties["batchIndex"]; index == nil || index.Type != "integer" {
+		t.Fatalf("unexpected schema of batchIndex: %+v", index)
+	}
+	required := make(map[string]bool)
+	for _, name := range batch.Required {
+		required[name] = true
+	}
+	if !required["batchIndex"] || required["commitTxHash"] {
+		t.Fatalf("unexpected required properties: %v", batch.Required)
+	}
+
+	// subscriptions describe their notifications, the discovery method itself is described
+	if event := schemas.Subscriptions["rollupEvents"]; event == nil || event.Ref != "#/$defs/RollupEvent" {
+		t.Fatalf("unexpected schema of rollupEvents: %+v", event)
+	}
+	if _, ok := schemas.Methods["rollupEvents"]; ok {
+		t.Fatal("subscription listed as method")
+	}
+	if _, ok := schemas.Methods["getResultSchemas"]; !ok {
+		t.Fatal("no schema of getResultSchemas")
+	}
+	if schemas.Schema != jsonschema.Draft {
+		t.Fatalf("unexpected dialect %q", schemas.Schema)
+	}
+}
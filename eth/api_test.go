@@ -18,6 +18,8 @@ package eth
 
 import (
 	"bytes"
+	"context"
+	"encoding/json"
 	"fmt"
 	"math/big"
 	"reflect"
@@ -27,9 +29,17 @@ import (
 	"github.com/davecgh/go-spew/spew"
 
 	"github.com/scroll-tech/go-ethereum/common"
+	"github.com/scroll-tech/go-ethereum/consensus/ethash"
+	"github.com/scroll-tech/go-ethereum/core"
 	"github.com/scroll-tech/go-ethereum/core/rawdb"
 	"github.com/scroll-tech/go-ethereum/core/state"
+	"github.com/scroll-tech/go-ethereum/core/types"
+	"github.com/scroll-tech/go-ethereum/core/vm"
 	"github.com/scroll-tech/go-ethereum/crypto"
+	"github.com/scroll-tech/go-ethereum/internal/ethapi"
+	"github.com/scroll-tech/go-ethereum/params"
+	"github.com/scroll-tech/go-ethereum/rollup/rcfg"
+	"github.com/scroll-tech/go-ethereum/rollup/withdrawtrie"
 	"github.com/scroll-tech/go-ethereum/trie"
 )
 
@@ -220,3 +230,298 @@ func TestStorageRangeAt(t *testing.T) {
 		}
 	}
 }
+
+func TestGetL1MessagesInRange(t *testing.T) {
+	db := rawdb.NewMemoryDatabase()
+	for i := uint64(0); i < 5; i++ {
+		rawdb.WriteL1Message(db, types.L1MessageTx{QueueIndex: i, To: &common.Address{}, Value: big.NewInt(0)})
+		rawdb.WriteL1MessageOrigin(db, i, &rawdb.L1MessageOrigin{L1BlockNumber: 100 + i, L1TxHash: common.Hash{byte(i)}})
+	}
+	api := NewScrollAPI(&Ethereum{chainDb: db})
+
+	limit := uint64(2)
+	var indices []uint64
+	for from := uint64(1); ; {
+		page, err := api.GetL1MessagesInRange(context.Background(), from, 10, &limit)
+		if err != nil {
+			t.Fatalf("failed to get L1 messages: %v", err)
+		}
+		if len(page.Messages) > int(limit) {
+			t.Fatalf("page exceeds limit: %d messages", len(page.Messages))
+		}
+		for _, msg := range page.Messages {
+			if msg.L1BlockNumber == nil || *msg.L1BlockNumber != 100+msg.QueueIndex {
+				t.Fatalf("message %d: unexpected L1 block number %v", msg.QueueIndex, msg.L1BlockNumber)
+			}
+			indices = append(indices, msg.QueueIndex)
+		}
+		if page.NextQueueIndex == nil {
+			break
+		}
+		from = *page.NextQueueIndex
+	}
+	if !reflect.DeepEqual(indices, []uint64{1, 2, 3, 4}) {
+		t.Fatalf("unexpected queue indices: %v", indices)
+	}
+
+	page, err := api.GetL1MessagesInRange(context.Background(), 1, 2, nil)
+	if err != nil {
+		t.Fatalf("failed to get L1 messages: %v", err)
+	}
+	if len(page.Messages) != 2 || page.NextQueueIndex != nil {
+		t.Fatalf("unexpected page: %d messages, next %v", len(page.Messages), page.NextQueueIndex)
+	}
+	if page, _ := api.GetL1MessagesInRange(context.Background(), 7, 9, nil); len(page.Messages) != 0 {
+		t.Fatalf("unexpected messages beyond the synced queue index: %d", len(page.Messages))
+	}
+	if _, err := api.GetL1MessagesInRange(context.Background(), 2, 1, nil); err == nil {
+		t.Fatalf("expected error for invalid range")
+	}
+}
+
+func TestGetL1MessageInclusion(t *testing.T) {
+	db := rawdb.NewMemoryDatabase()
+	msgs := []types.L1MessageTx{
+		{QueueIndex: 0, To: &common.Address{}, Value: big.NewInt(0)},
+		{QueueIndex: 1, To: &common.Address{}, Value: big.NewInt(0)},
+		{QueueIndex: 2, To: &common.Address{}, Value: big.NewInt(0)},
+	}
+	rawdb.WriteL1Messages(db, msgs)
+	block := types.NewBlockWithHeader(&types.Header{Number: big.NewInt(1)}).WithBody([]*types.Transaction{types.NewTx(&msgs[0]), types.NewTx(&msgs[1])}, nil)
+	rawdb.WriteBlock(db, block)
+	rawdb.WriteCanonicalHash(db, block.Hash(), 1)
+	rawdb.WriteTxLookupEntriesByBlock(db, block)
+	// message 1 is only found through its transaction hash, as if it was imported by an older version
+	rawdb.WriteL1MessageInclusionsByBlock(db, block)
+	rawdb.DeleteL1MessageInclusion(db, 1)
+	api := NewScrollAPI(&Ethereum{chainDb: db})
+
+	for i := uint64(0); i < 2; i++ {
+		inclusion, err := api.GetL1MessageInclusion(context.Background(), i)
+		if err != nil {
+			t.Fatalf("failed to get L1 message inclusion: %v", err)
+		}
+		if inclusion == nil || inclusion.BlockHash != block.Hash() || inclusion.BlockNumber != 1 || inclusion.TxHash != block.Transactions()[i].Hash() {
+			t.Fatalf("message %d: unexpected inclusion %+v", i, inclusion)
+		}
+	}
+	if inclusion, _ := api.GetL1MessageInclusion(context.Background(), 2); inclusion != nil {
+		t.Fatalf("unexpected inclusion of pending message: %+v", inclusion)
+	}
+	if inclusion, _ := api.GetL1MessageInclusion(context.Background(), 3); inclusion != nil {
+		t.Fatalf("unexpected inclusion of unknown message: %+v", inclusion)
+	}
+
+	// inclusions in non-canonical blocks are ignored
+	rawdb.WriteCanonicalHash(db, common.Hash{0x01}, 1)
+	if inclusion, _ := api.GetL1MessageInclusion(context.Background(), 0); inclusion != nil {
+		t.Fatalf("unexpected inclusion in non-canonical block: %+v", inclusion)
+	}
+}
+
+func TestGetSkippedL1Messages(t *testing.T) {
+	db := rawdb.NewMemoryDatabase()
+	rawdb.WriteL1Message(db, types.L1MessageTx{QueueIndex: 3, To: &common.Address{}, Value: big.NewInt(0)})
+	rawdb.WriteBatchSkippedL1Messages(db, 1, []uint64{3, 4})
+	rawdb.WriteBatchSkippedL1Messages(db, 2, []uint64{9})
+	api := NewScrollAPI(&Ethereum{chainDb: db})
+
+	messages, err := api.GetSkippedL1Messages(context.Background(), 0, 5)
+	if err != nil {
+		t.Fatalf("failed to get skipped L1 messages: %v", err)
+	}
+	if len(messages) != 2 || messages[0].QueueIndex != 3 || messages[0].BatchIndex != 1 || messages[1].QueueIndex != 4 {
+		t.Fatalf("unexpected skipped L1 messages: %+v", messages)
+	}
+	if messages[0].Message == nil || messages[0].Message.QueueIndex != 3 {
+		t.Fatalf("unexpected message of skipped L1 message 3: %+v", messages[0].Message)
+	}
+	if messages[1].Message != nil {
+		t.Fatalf("unexpected message of unsynced L1 message 4: %+v", messages[1].Message)
+	}
+	if messages, _ := api.GetSkippedL1Messages(context.Background(), 5, 8); len(messages) != 0 {
+		t.Fatalf("unexpected skipped L1 messages: %+v", messages)
+	}
+	if _, err := api.GetSkippedL1Messages(context.Background(), 2, 1); err == nil {
+		t.Fatalf("expected error for invalid range")
+	}
+}
+
+func TestGetBlockByHashChainMembership(t *testing.T) {
+	var (
+		db      = rawdb.NewMemoryDatabase()
+		gspec   = &core.Genesis{Config: params.TestChainConfig, BaseFee: big.NewInt(params.InitialBaseFee)}
+		genesis = gspec.MustCommit(db)
+	)
+	blocks, _ := core.GenerateChain(gspec.Config, genesis, ethash.NewFaker(), db, 4, nil)
+	sideBlocks, _ := core.GenerateChain(gspec.Config, genesis, ethash.NewFaker(), db, 1, func(i int, b *core.BlockGen) {
+		b.SetCoinbase(common.Address{0x01})
+	})
+	bc, err := core.NewBlockChain(db, nil, gspec.Config, ethash.NewFaker(), vm.Config{}, nil, nil)
+	if err != nil {
+		t.Fatalf("failed to create blockchain: %v", err)
+	}
+	defer bc.Stop()
+	if _, err := bc.InsertChain(blocks); err != nil {
+		t.Fatalf("failed to insert chain: %v", err)
+	}
+	rawdb.WriteBlock(db, sideBlocks[0])
+	bc.SetFinalized(blocks[1].Header())
+
+	eth := &Ethereum{blockchain: bc, chainDb: db}
+	eth.APIBackend = &EthAPIBackend{eth: eth}
+	apis := []struct {
+		name           string
+		getBlockByHash func(ctx context.Context, hash common.Hash, fullTx bool) (map[string]interface{}, error)
+	}{
+		{"eth", ethapi.NewPublicBlockChainAPI(eth.APIBackend).GetBlockByHash},
+		{"scroll", NewScrollAPI(eth).GetBlockByHash},
+	}
+	tests := []struct {
+		block                *types.Block
+		canonical, finalized bool
+	}{
+		{blocks[1], true, true},
+		{blocks[2], true, false},
+		{sideBlocks[0], false, false},
+	}
+	for _, api := range apis {
+		for _, tt := range tests {
+			fields, err := api.getBlockByHash(context.Background(), tt.block.Hash(), false)
+			if err != nil {
+				t.Fatalf("%s: failed to get block %d: %v", api.name, tt.block.NumberU64(), err)
+			}
+			if fields["canonical"] != tt.canonical || fields["finalized"] != tt.finalized {
+				t.Fatalf("%s: block %d: unexpected chain membership, canonical: %v, finalized: %v, want canonical: %v, finalized: %v",
+					api.name, tt.block.NumberU64(), fields["canonical"], fields["finalized"], tt.canonical, tt.finalized)
+			}
+		}
+	}
+}
+
+func TestGetWithdrawProof(t *testing.T) {
+	db := rawdb.NewMemoryDatabase()
+	appendMessageLog := func(nonce uint64, messageHash common.Hash) *types.Log {
+		data := append(common.BigToHash(new(big.Int).SetUint64(nonce)).Bytes(), messageHash.Bytes()...)
+		return &types.Log{Address: rcfg.L2MessageQueueAddress, Topics: []common.Hash{withdrawtrie.AppendMessageEventTopic}, Data: data}
+	}
+	// blocks 1 and 2 append messages 0-2, block 3 is not part of the batch
+	leaves := []common.Hash{{0x10}, {0x11}, {0x12}, {0x13}}
+	logs := [][]*types.Log{nil, {appendMessageLog(0, leaves[0]), appendMessageLog(1, leaves[1])}, {appendMessageLog(2, leaves[2])}, {appendMessageLog(3, leaves[3])}}
+	for number, blockLogs := range logs {
+		block := types.NewBlockWithHeader(&types.Header{Number: big.NewInt(int64(number))})
+		rawdb.WriteBlock(db, block)
+		rawdb.WriteCanonicalHash(db, block.Hash(), uint64(number))
+		rawdb.WriteReceipts(db, block.Hash(), uint64(number), types.Receipts{{Status: types.ReceiptStatusSuccessful, Logs: blockLogs}})
+	}
+	root, _, _ := withdrawtrie.GenerateProof(leaves[:3], 0)
+	rawdb.WriteFinalizedBatchMeta(db, 1, &rawdb.FinalizedBatchMeta{WithdrawRoot: root})
+	rawdb.WriteBatchChunkRanges(db, 1, []*rawdb.ChunkBlockRange{{StartBlockNumber: 1, EndBlockNumber: 1}, {StartBlockNumber: 2, EndBlockNumber: 2}})
+	api := NewScrollAPI(&Ethereum{chainDb: db})
+
+	for nonce := uint64(0); nonce < 3; nonce++ {
+		result, err := api.GetWithdrawProof(context.Background(), leaves[nonce], 1)
+		if err != nil {
+			t.Fatalf("failed to get withdraw proof of message %d: %v", nonce, err)
+		}
+		if result.MessageNonce != nonce || result.WithdrawRoot != root || len(result.Proof) != 2*common.HashLength {
+			t.Fatalf("message %d: unexpected withdraw proof %+v", nonce, result)
+		}
+		proof := []common.Hash{common.BytesToHash(result.Proof[:32]), common.BytesToHash(result.Proof[32:])}
+		if !withdrawtrie.VerifyProof(root, leaves[nonce], nonce, proof) {
+			t.Fatalf("message %d: invalid withdraw proof", nonce)
+		}
+	}
+	if _, err := api.GetWithdrawProof(context.Background(), leaves[3], 1); err == nil {
+		t.Fatalf("expected error for message appended after the batch")
+	}
+	if _, err := api.GetWithdrawProof(context.Background(), leaves[0], 2); err == nil {
+		t.Fatalf("expected error for unfinalized batch")
+	}
+
+	// once indexed, the leaves are read from the withdraw message index instead of the receipts
+	for number := range logs {
+		hash := rawdb.ReadCanonicalHash(db, uint64(number))
+		withdrawtrie.IndexBlockMessages(db, hash, uint64(number), rawdb.ReadRawReceipts(db, hash, uint64(number)))
+		rawdb.DeleteReceipts(db, hash, uint64(number))
+	}
+	result, err := api.GetWithdrawProof(context.Background(), leaves[2], 1)
+	if err != nil {
+		t.Fatalf("failed to get indexed withdraw proof: %v", err)
+	}
+	if result.MessageNonce != 2 || result.WithdrawRoot != root {
+		t.Fatalf("unexpected indexed withdraw proof %+v", result)
+	}
+	if _, err := api.GetWithdrawProof(context.Background(), leaves[3], 1); err == nil {
+		t.Fatalf("expected error for indexed message appended after the batch")
+	}
+
+	rawdb.WriteFinalizedBatchMeta(db, 1, &rawdb.FinalizedBatchMeta{WithdrawRoot: common.Hash{0x01}})
+	if _, err := api.GetWithdrawProof(context.Background(), leaves[0], 1); err == nil {
+		t.Fatalf("expected error for withdraw root mismatch")
+	}
+}
+
+func TestGetWithdrawRoot(t *testing.T) {
+	var (
+		db      = rawdb.NewMemoryDatabase()
+		gspec   = &core.Genesis{Config: params.TestChainConfig, BaseFee: big.NewInt(params.InitialBaseFee)}
+		genesis = gspec.MustCommit(db)
+	)
+	blocks, _ := core.GenerateChain(gspec.Config, genesis, ethash.NewFaker(), db, 4, nil)
+	bc, err := core.NewBlockChain(db, nil, gspec.Config, ethash.NewFaker(), vm.Config{}, nil, nil)
+	if err != nil {
+		t.Fatalf("failed to create blockchain: %v", err)
+	}
+	defer bc.Stop()
+	if _, err := bc.InsertChain(blocks); err != nil {
+		t.Fatalf("failed to insert chain: %v", err)
+	}
+	eth := &Ethereum{blockchain: bc, chainDb: db}
+	eth.APIBackend = &EthAPIBackend{eth: eth}
+	api := NewScrollAPI(eth)
+
+	getWithdrawRoot := func(target string) *rpcWithdrawRoot {
+		var query withdrawRootTarget
+		if err := json.Unmarshal([]byte(target), &query); err != nil {
+			t.Fatalf("failed to decode withdraw root target %s: %v", target, err)
+		}
+		result, err := api.GetWithdrawRoot(context.Background(), query)
+		if err != nil {
+			t.Fatalf("failed to get withdraw root of %s: %v", target, err)
+		}
+		return result
+	}
+
+	// persisted withdraw roots are served by block number, hash and batch
+	rawdb.WriteBlockWithdrawRoot(db, blocks[1].Hash(), common.Hash{0x02})
+	rawdb.WriteBatchChunkRanges(db, 1, []*rawdb.ChunkBlockRange{{StartBlockNumber: 1, EndBlockNumber: 2}})
+	for _, target := range []string{`"0x2"`, fmt.Sprintf(`{"blockHash": "%s"}`, blocks[1].Hash().Hex()), `{"batchIndex": "0x1"}`} {
+		result := getWithdrawRoot(target)
+		if result.BlockNumber != 2 || result.BlockHash != blocks[1].Hash() || result.WithdrawRoot != (common.Hash{0x02}) {
+			t.Fatalf("%s: unexpected withdraw root %+v", target, result)
+		}
+		if (result.BatchIndex != nil) != (target == `{"batchIndex": "0x1"}`) {
+			t.Fatalf("%s: unexpected batch index %v", target, result.BatchIndex)
+		}
+	}
+
+	// without a persisted root, the root is read from the block state
+	rawdb.DeleteBlockWithdrawRoot(db, blocks[3].Hash())
+	if result := getWithdrawRoot(`"latest"`); result.BlockNumber != 4 || result.WithdrawRoot != (common.Hash{}) {
+		t.Fatalf("unexpected withdraw root from state %+v", result)
+	}
+	// or computed from the withdraw message index if it covers the block
+	rawdb.WriteWithdrawMessage(db, 0, &rawdb.WithdrawMessage{MessageHash: common.Hash{0x10}, BlockNumber: 3, BlockHash: blocks[2].Hash()})
+	if result := getWithdrawRoot(`"0x4"`); result.WithdrawRoot != (common.Hash{0x10}) {
+		t.Fatalf("unexpected withdraw root from index %+v", result)
+	}
+
+	var query withdrawRootTarget
+	if err := json.Unmarshal([]byte(`{"batchIndex": "0x2"}`), &query); err != nil {
+		t.Fatalf("failed to decode withdraw root target: %v", err)
+	}
+	if _, err := api.GetWithdrawRoot(context.Background(), query); err == nil {
+		t.Fatalf("expected error for unknown batch")
+	}
+}
@@ -35,6 +35,7 @@ import (
 	"github.com/scroll-tech/go-ethereum/core"
 	"github.com/scroll-tech/go-ethereum/core/bloombits"
 	"github.com/scroll-tech/go-ethereum/core/rawdb"
+	"github.com/scroll-tech/go-ethereum/core/state"
 	"github.com/scroll-tech/go-ethereum/core/state/pruner"
 	"github.com/scroll-tech/go-ethereum/core/types"
 	"github.com/scroll-tech/go-ethereum/core/vm"
@@ -43,6 +44,7 @@ import (
 	"github.com/scroll-tech/go-ethereum/eth/filters"
 	"github.com/scroll-tech/go-ethereum/eth/gasprice"
 	"github.com/scroll-tech/go-ethereum/eth/protocols/eth"
+	"github.com/scroll-tech/go-ethereum/eth/protocols/scroll"
 	"github.com/scroll-tech/go-ethereum/eth/protocols/snap"
 	"github.com/scroll-tech/go-ethereum/ethdb"
 	"github.com/scroll-tech/go-ethereum/event"
@@ -55,9 +57,12 @@ import (
 	"github.com/scroll-tech/go-ethereum/p2p/enode"
 	"github.com/scroll-tech/go-ethereum/params"
 	"github.com/scroll-tech/go-ethereum/rlp"
+	"github.com/scroll-tech/go-ethereum/rollup/chunkchecker"
+	"github.com/scroll-tech/go-ethereum/rollup/eventbus"
 	"github.com/scroll-tech/go-ethereum/rollup/rollup_sync_service"
 	"github.com/scroll-tech/go-ethereum/rollup/sync_service"
 	"github.com/scroll-tech/go-ethereum/rollup/tracing"
+	"github.com/scroll-tech/go-ethereum/rollup/withdrawtrie"
 	"github.com/scroll-tech/go-ethereum/rpc"
 )
 
@@ -72,7 +77,12 @@ type Ethereum struct {
 	// Handlers
 	txPool             *core.TxPool
 	syncService        *sync_service.SyncService
+	l1ModeClient       *sync_service.ModeSwitchClient // nil if no verified L1 endpoint is configured
 	rollupSyncService  *rollup_sync_service.RollupSyncService
+	rollupFollower     *rollup_sync_service.Follower
+	withdrawBackfiller *withdrawtrie.Backfiller // nil if the withdraw root backfill is disabled
+	batchReader        rollup_sync_service.BatchReader
+	rollupEventBus     *eventbus.Bus
 	blockchain         *core.BlockChain
 	handler            *handler
 	ethDialCandidates  enode.Iterator
@@ -137,6 +147,16 @@ func New(stack *node.Node, config *ethconfig.Config, l1Client sync_service.EthCl
 	if err != nil {
 		return nil, err
 	}
+	// Serve old finalized batch metadata from the batch freezer once it has been created by `geth db rollup-freeze`.
+	if stack.DataDir() != "" {
+		if dir := rawdb.BatchFreezerDir(stack.ResolveAncient("chaindata", config.DatabaseFreezer)); common.FileExist(dir) {
+			batchFreezerDb, err := rawdb.NewBatchFreezerDatabase(chainDb, dir, "eth/db/chaindata/rollup/", false)
+			if err != nil {
+				return nil, err
+			}
+			chainDb = batchFreezerDb
+		}
+	}
 	chainConfig, genesisHash, genesisErr := core.SetupGenesisBlockWithOverride(chainDb, config.Genesis, config.OverrideArrowGlacier)
 	if _, ok := genesisErr.(*params.ConfigCompatError); genesisErr != nil && !ok {
 		return nil, genesisErr
@@ -150,6 +170,7 @@ func New(stack *node.Node, config *ethconfig.Config, l1Client sync_service.EthCl
 		config:            config,
 		chainDb:           chainDb,
 		eventMux:          stack.EventMux(),
+		rollupEventBus:    eventbus.New(),
 		accountManager:    stack.AccountManager(),
 		engine:            ethconfig.CreateConsensusEngine(stack, chainConfig, &ethashConfig, config.Miner.Notify, config.Miner.Noverify, chainDb),
 		closeBloomHandler: make(chan struct{}),
@@ -191,14 +212,18 @@ func New(stack *node.Node, config *ethconfig.Config, l1Client sync_service.EthCl
 			TrieDirtyDisabled:   config.NoPruning,
 			TrieTimeLimit:       config.TrieTimeout,
 			SnapshotLimit:       config.SnapshotCache,
+			ZktrieSnapshot:      config.SnapshotZktrie,
 			Preimages:           config.Preimages,
 			MPTWitness:          config.MPTWitness,
+			DualTrie:            config.DualTrie,
 		}
 	)
 	eth.blockchain, err = core.NewBlockChain(chainDb, cacheConfig, chainConfig, eth.engine, vmConfig, eth.shouldPreserve, &config.TxLookupLimit)
 	if err != nil {
 		return nil, err
 	}
+	eth.blockchain.SetWithdrawRootFn(func(statedb *state.StateDB) common.Hash { return withdrawtrie.WithdrawRoot(statedb) })
+	eth.blockchain.SetWithdrawMessageIndexer(withdrawtrie.MessageIndexer{})
 	if config.CheckCircuitCapacity {
 		tracer := tracing.NewTracerWrapper()
 		eth.blockchain.Validator().SetupTracerAndCircuitCapacityChecker(tracer)
@@ -217,22 +242,54 @@ func New(stack *node.Node, config *ethconfig.Config, l1Client sync_service.EthCl
 	}
 	eth.txPool = core.NewTxPool(config.TxPool, chainConfig, eth.blockchain)
 
+	eth.l1ModeClient, _ = l1Client.(*sync_service.ModeSwitchClient)
+	if mode := rawdb.ReadL1Mode(chainDb); eth.l1ModeClient != nil && mode != "" && stack.Config().L1Mode == "" {
+		// resume with the L1 mode last selected by admin_setL1Mode, unless a mode is configured
+		if err := eth.l1ModeClient.SetMode(sync_service.L1Mode(mode)); err != nil {
+			log.Warn("Ignoring stored L1 mode", "mode", mode, "err", err)
+		}
+	}
+
 	// initialize and start L1 message sync service
-	eth.syncService, err = sync_service.NewSyncService(context.Background(), chainConfig, stack.Config(), eth.chainDb, l1Client)
+	eth.syncService, err = sync_service.NewSyncService(context.Background(), chainConfig, stack.Config(), eth.chainDb, l1Client, eth.rollupEventBus)
 	if err != nil {
 		return nil, fmt.Errorf("cannot initialize L1 sync service: %w", err)
 	}
 	eth.syncService.Start()
 
 	if config.EnableRollupVerify {
+		if config.RollupSync.PayloadCacheDir != "" {
+			config.RollupSync.PayloadCacheDir = stack.ResolvePath(config.RollupSync.PayloadCacheDir)
+		}
+		if sources := config.RollupSync.BlobSources; sources != nil && sources.DiskCacheDir != "" {
+			sources.DiskCacheDir = stack.ResolvePath(sources.DiskCacheDir)
+		}
 		// initialize and start rollup event sync service
-		eth.rollupSyncService, err = rollup_sync_service.NewRollupSyncService(context.Background(), chainConfig, eth.chainDb, l1Client, eth.blockchain, stack.Config().L1DeploymentBlock)
+		eth.rollupSyncService, err = rollup_sync_service.NewRollupSyncService(context.Background(), chainConfig, eth.chainDb, l1Client, eth.blockchain, stack.Config().L1DeploymentBlock, &config.RollupSync, eth.rollupEventBus)
 		if err != nil {
 			return nil, fmt.Errorf("cannot initialize rollup event sync service: %w", err)
 		}
 		eth.rollupSyncService.Start()
 	}
 
+	if config.RollupFollow != "" {
+		// serve the rollup metadata of the writer node instead of the local database
+		eth.rollupFollower, err = rollup_sync_service.NewFollower(context.Background(), config.RollupFollow, eth.blockchain)
+		if err != nil {
+			return nil, fmt.Errorf("cannot initialize rollup metadata follower: %w", err)
+		}
+		eth.batchReader = eth.rollupFollower.Reader()
+		eth.rollupFollower.Start()
+	}
+
+	if config.WithdrawRootBackfill {
+		if !config.NoPruning {
+			log.Warn("Withdraw root backfill enabled on a pruning node, the roots of blocks with pruned state are not backfilled")
+		}
+		eth.withdrawBackfiller = withdrawtrie.NewBackfiller(eth.chainDb, eth.blockchain, config.WithdrawRootBackfillRateLimit)
+		eth.withdrawBackfiller.Start()
+	}
+
 	// Permit the downloader to use the trie cache allowance during fast sync
 	cacheLimit := cacheConfig.TrieCleanLimit + cacheConfig.TrieDirtyLimit + cacheConfig.SnapshotLimit
 	checkpoint := config.Checkpoint
@@ -249,10 +306,21 @@ func New(stack *node.Node, config *ethconfig.Config, l1Client sync_service.EthCl
 		EventMux:   eth.eventMux,
 		Checkpoint: checkpoint,
 		Whitelist:  config.Whitelist,
+
+		BatchMetadata: eth.rollupSyncService != nil || eth.rollupFollower != nil,
 	}); err != nil {
 		return nil, err
 	}
 
+	if config.Miner.ChunkConstraintChecker == nil {
+		// build blocks that fit in a batch of the latest codec and satisfy the chunk
+		// rules the rollup verifier is configured with
+		chunkRules := rollup_sync_service.DefaultChunkRules
+		if len(config.RollupSync.ChunkLimits) > 0 {
+			chunkRules = rollup_sync_service.NewChunkRules(config.RollupSync.ChunkLimits)
+		}
+		config.Miner.ChunkConstraintChecker = chunkchecker.NewChunkChecker(rollup_sync_service.LatestCodec(), chunkRules)
+	}
 	eth.miner = miner.New(eth, &config.Miner, chainConfig, eth.EventMux(), eth.engine, eth.isLocalBlock)
 	eth.miner.SetExtra(makeExtraData(config.Miner.ExtraData))
 
@@ -282,6 +350,13 @@ func New(stack *node.Node, config *ethconfig.Config, l1Client sync_service.EthCl
 
 	// Register the backend on the node
 	stack.RegisterAPIs(eth.APIs())
+	// The rollup status mirrors the scroll namespace, only expose it if that is enabled over HTTP.
+	for _, module := range stack.Config().HTTPModules {
+		if module == "scroll" {
+			stack.RegisterHandler("Rollup status", rollupStatusPath, &rollupStatusHandler{eth: eth})
+			break
+		}
+	}
 	stack.RegisterProtocols(eth.Protocols())
 	stack.RegisterLifecycle(eth)
 	// Check for unclean shutdown
@@ -375,6 +450,16 @@ func (s *Ethereum) APIs() []rpc.API {
 			Version:   "1.0",
 			Service:   NewScrollAPI(s),
 			Public:    false,
+		}, {
+			Namespace: "optimism",
+			Version:   "1.0",
+			Service:   NewOptimismAPI(s),
+			Public:    false,
+		}, {
+			Namespace: "rollupdb",
+			Version:   "1.0",
+			Service:   rollup_sync_service.NewBatchReaderAPI(s.BatchReader()),
+			Public:    false,
 		},
 	}...)
 }
@@ -544,6 +629,22 @@ func (s *Ethereum) Synced() bool                           { return atomic.LoadU
 func (s *Ethereum) ArchiveMode() bool                      { return s.config.NoPruning }
 func (s *Ethereum) BloomIndexer() *core.ChainIndexer       { return s.bloomIndexer }
 func (s *Ethereum) SyncService() *sync_service.SyncService { return s.syncService }
+func (s *Ethereum) RollupSyncService() *rollup_sync_service.RollupSyncService {
+	return s.rollupSyncService
+}
+func (s *Ethereum) RollupEventBus() *eventbus.Bus { return s.rollupEventBus }
+func (s *Ethereum) RollupFollower() *rollup_sync_service.Follower {
+	return s.rollupFollower
+}
+
+// BatchReader returns the reader of the rollup metadata, which is the local database
+// or the writer node followed by this node.
+func (s *Ethereum) BatchReader() rollup_sync_service.BatchReader {
+	if s.batchReader == nil {
+		return rollup_sync_service.NewDBBatchReader(s.chainDb)
+	}
+	return s.batchReader
+}
 
 // Protocols returns all the currently configured
 // network protocols to start.
@@ -552,6 +653,7 @@ func (s *Ethereum) Protocols() []p2p.Protocol {
 	if !s.blockchain.Config().Scroll.ZktrieEnabled() && s.config.SnapshotCache > 0 {
 		protos = append(protos, snap.MakeProtocols((*snapHandler)(s.handler), s.snapDialCandidates)...)
 	}
+	protos = append(protos, scroll.MakeProtocols((*scrollHandler)(s.handler))...)
 	return protos
 }
 
@@ -592,6 +694,9 @@ func (s *Ethereum) Stop() error {
 	if s.config.EnableRollupVerify {
 		s.rollupSyncService.Stop()
 	}
+	s.rollupFollower.Stop()
+	s.withdrawBackfiller.Stop()
+	s.rollupEventBus.Close()
 	s.miner.Close()
 	s.blockchain.Stop()
 	s.engine.Close()
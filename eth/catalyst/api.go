@@ -90,7 +90,7 @@ func (env *blockExecutionEnv) commitTransaction(tx *types.Transaction, coinbase
 }
 
 func (api *consensusAPI) makeEnv(parent *types.Block, header *types.Header) (*blockExecutionEnv, error) {
-	state, err := api.eth.BlockChain().StateAt(parent.Root())
+	state, err := api.eth.BlockChain().StateForChild(parent.Header())
 	if err != nil {
 		return nil, err
 	}
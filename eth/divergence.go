@@ -0,0 +1,319 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package eth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/scroll-tech/go-ethereum"
+	"github.com/scroll-tech/go-ethereum/common"
+	"github.com/scroll-tech/go-ethereum/common/hexutil"
+	"github.com/scroll-tech/go-ethereum/core"
+	"github.com/scroll-tech/go-ethereum/core/types"
+	"github.com/scroll-tech/go-ethereum/crypto"
+	"github.com/scroll-tech/go-ethereum/log"
+	"github.com/scroll-tech/go-ethereum/rpc"
+)
+
+// divergenceRemote is the subset of the remote node API required to localize a divergence.
+type divergenceRemote interface {
+	HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error)
+	TransactionReceipt(ctx context.Context, txHash common.Hash) (*types.Receipt, error)
+	BalanceAt(ctx context.Context, account common.Address, blockNumber *big.Int) (*big.Int, error)
+	NonceAt(ctx context.Context, account common.Address, blockNumber *big.Int) (uint64, error)
+	CodeAt(ctx context.Context, account common.Address, blockNumber *big.Int) ([]byte, error)
+}
+
+// FieldDivergence is a single value that differs between the local and the remote node.
+type FieldDivergence struct {
+	Field  string `json:"field"`
+	Local  string `json:"local"`
+	Remote string `json:"remote"`
+}
+
+// ReceiptDivergence lists the receipt fields of a transaction that differ between the nodes.
+type ReceiptDivergence struct {
+	TxHash  common.Hash       `json:"txHash"`
+	TxIndex hexutil.Uint      `json:"txIndex"`
+	Fields  []FieldDivergence `json:"fields"`
+}
+
+// AccountDivergence lists the account fields that differ between the nodes after the divergent block.
+type AccountDivergence struct {
+	Address common.Address    `json:"address"`
+	Fields  []FieldDivergence `json:"fields"`
+}
+
+// DivergenceResult describes the first block whose state root differs between the nodes.
+type DivergenceResult struct {
+	BlockNumber     hexutil.Uint64      `json:"blockNumber"`
+	LocalHash       common.Hash         `json:"localHash"`
+	RemoteHash      common.Hash         `json:"remoteHash"`
+	LocalStateRoot  common.Hash         `json:"localStateRoot"`
+	RemoteStateRoot common.Hash         `json:"remoteStateRoot"`
+	Receipts        []ReceiptDivergence `json:"receipts"`
+	Accounts        []AccountDivergence `json:"accounts"`
+}
+
+// FindDivergence binary-searches the state roots of the local chain against the node
+// at remoteRPC to find the first block whose post-state differs, then diffs the
+// receipts and touched accounts of that block. It returns null if the nodes agree
+// up to the lower of the two chain heads.
+func (api *PrivateDebugAPI) FindDivergence(ctx context.Context, remoteRPC string) (*DivergenceResult, error) {
+	client, err := rpc.DialContext(ctx, remoteRPC)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to remote node: %w", err)
+	}
+	defer client.Close()
+
+	return findDivergence(ctx, api.eth.BlockChain(), &rpcDivergenceRemote{client})
+}
+
+// rpcDivergenceRemote implements divergenceRemote over the eth namespace of a remote node.
+// note: ethclient is not used, its tests import this package.
+type rpcDivergenceRemote struct {
+	client *rpc.Client
+}
+
+func toDivergenceBlockArg(number *big.Int) string {
+	if number == nil {
+		return "latest"
+	}
+	return hexutil.EncodeBig(number)
+}
+
+func (r *rpcDivergenceRemote) HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error) {
+	var head *types.Header
+	if err := r.client.CallContext(ctx, &head, "eth_getBlockByNumber", toDivergenceBlockArg(number), false); err != nil {
+		return nil, err
+	}
+	if head == nil {
+		return nil, ethereum.NotFound
+	}
+	return head, nil
+}
+
+func (r *rpcDivergenceRemote) TransactionReceipt(ctx context.Context, txHash common.Hash) (*types.Receipt, error) {
+	var receipt *types.Receipt
+	if err := r.client.CallContext(ctx, &receipt, "eth_getTransactionReceipt", txHash); err != nil {
+		return nil, err
+	}
+	if receipt == nil {
+		return nil, ethereum.NotFound
+	}
+	return receipt, nil
+}
+
+func (r *rpcDivergenceRemote) BalanceAt(ctx context.Context, account common.Address, blockNumber *big.Int) (*big.Int, error) {
+	var balance hexutil.Big
+	err := r.client.CallContext(ctx, &balance, "eth_getBalance", account, toDivergenceBlockArg(blockNumber))
+	return (*big.Int)(&balance), err
+}
+
+func (r *rpcDivergenceRemote) NonceAt(ctx context.Context, account common.Address, blockNumber *big.Int) (uint64, error) {
+	var nonce hexutil.Uint64
+	err := r.client.CallContext(ctx, &nonce, "eth_getTransactionCount", account, toDivergenceBlockArg(blockNumber))
+	return uint64(nonce), err
+}
+
+func (r *rpcDivergenceRemote) CodeAt(ctx context.Context, account common.Address, blockNumber *big.Int) ([]byte, error) {
+	var code hexutil.Bytes
+	err := r.client.CallContext(ctx, &code, "eth_getCode", account, toDivergenceBlockArg(blockNumber))
+	return code, err
+}
+
+func findDivergence(ctx context.Context, bc *core.BlockChain, remote divergenceRemote) (*DivergenceResult, error) {
+	remoteHead, err := remote.HeaderByNumber(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get remote head: %w", err)
+	}
+	head := bc.CurrentBlock().NumberU64()
+	if remoteHead.Number.Uint64() < head {
+		head = remoteHead.Number.Uint64()
+	}
+
+	// rootsMatch reports whether both nodes have the same state root after the given block.
+	rootsMatch := func(number uint64) (bool, error) {
+		local := bc.GetHeaderByNumber(number)
+		if local == nil {
+			return false, fmt.Errorf("local header %d not found", number)
+		}
+		header, err := remote.HeaderByNumber(ctx, new(big.Int).SetUint64(number))
+		if err != nil {
+			return false, fmt.Errorf("failed to get remote header %d: %w", number, err)
+		}
+		return local.Root == header.Root, nil
+	}
+
+	if match, err := rootsMatch(head); err != nil || match {
+		return nil, err
+	}
+
+	// invariant: the roots match after block lo (unless lo is the genesis) and differ after block hi
+	var lo, hi uint64 = 0, head
+	if match, err := rootsMatch(0); err != nil {
+		return nil, err
+	} else if !match {
+		hi = 0
+	}
+	for lo+1 < hi {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		mid := lo + (hi-lo)/2
+		match, err := rootsMatch(mid)
+		if err != nil {
+			return nil, err
+		}
+		if match {
+			lo = mid
+		} else {
+			hi = mid
+		}
+	}
+	log.Info("Found first divergent block", "number", hi)
+
+	return diffBlock(ctx, bc, remote, hi)
+}
+
+// diffBlock compares the receipts and the accounts touched by a block between the nodes.
+func diffBlock(ctx context.Context, bc *core.BlockChain, remote divergenceRemote, number uint64) (*DivergenceResult, error) {
+	block := bc.GetBlockByNumber(number)
+	if block == nil {
+		return nil, fmt.Errorf("local block %d not found", number)
+	}
+	remoteHeader, err := remote.HeaderByNumber(ctx, block.Number())
+	if err != nil {
+		return nil, fmt.Errorf("failed to get remote header %d: %w", number, err)
+	}
+	result := &DivergenceResult{
+		BlockNumber:     hexutil.Uint64(number),
+		LocalHash:       block.Hash(),
+		RemoteHash:      remoteHeader.Hash(),
+		LocalStateRoot:  block.Root(),
+		RemoteStateRoot: remoteHeader.Root,
+		Receipts:        []ReceiptDivergence{},
+		Accounts:        []AccountDivergence{},
+	}
+
+	touched := []common.Address{block.Coinbase()}
+	if config := bc.Config(); config.Scroll.FeeVaultEnabled() {
+		touched = append(touched, *config.Scroll.FeeVaultAddress)
+	}
+
+	signer := types.MakeSigner(bc.Config(), block.Number())
+	receipts := bc.GetReceiptsByHash(block.Hash())
+	for i, tx := range block.Transactions() {
+		if from, err := types.Sender(signer, tx); err == nil {
+			touched = append(touched, from)
+		}
+		if tx.To() != nil {
+			touched = append(touched, *tx.To())
+		}
+		if i >= len(receipts) {
+			continue
+		}
+		local := receipts[i]
+		if local.ContractAddress != (common.Address{}) {
+			touched = append(touched, local.ContractAddress)
+		}
+		for _, l := range local.Logs {
+			touched = append(touched, l.Address)
+		}
+
+		var fields []FieldDivergence
+		remoteReceipt, err := remote.TransactionReceipt(ctx, tx.Hash())
+		switch {
+		case errors.Is(err, ethereum.NotFound):
+			fields = append(fields, FieldDivergence{Field: "receipt", Local: "present", Remote: "missing"})
+		case err != nil:
+			return nil, fmt.Errorf("failed to get remote receipt %v: %w", tx.Hash().Hex(), err)
+		default:
+			fields = diffReceipts(local, remoteReceipt)
+		}
+		if len(fields) > 0 {
+			result.Receipts = append(result.Receipts, ReceiptDivergence{TxHash: tx.Hash(), TxIndex: hexutil.Uint(i), Fields: fields})
+		}
+	}
+
+	statedb, err := bc.StateAt(block.Root())
+	if err != nil {
+		return nil, fmt.Errorf("failed to get local state of block %d: %w", number, err)
+	}
+	seen := make(map[common.Address]struct{})
+	for _, addr := range touched {
+		if _, ok := seen[addr]; ok {
+			continue
+		}
+		seen[addr] = struct{}{}
+
+		balance, err := remote.BalanceAt(ctx, addr, block.Number())
+		if err != nil {
+			return nil, fmt.Errorf("failed to get remote balance of %v: %w", addr.Hex(), err)
+		}
+		nonce, err := remote.NonceAt(ctx, addr, block.Number())
+		if err != nil {
+			return nil, fmt.Errorf("failed to get remote nonce of %v: %w", addr.Hex(), err)
+		}
+		code, err := remote.CodeAt(ctx, addr, block.Number())
+		if err != nil {
+			return nil, fmt.Errorf("failed to get remote code of %v: %w", addr.Hex(), err)
+		}
+
+		var fields []FieldDivergence
+		if local := statedb.GetBalance(addr); local.Cmp(balance) != 0 {
+			fields = append(fields, FieldDivergence{Field: "balance", Local: local.String(), Remote: balance.String()})
+		}
+		if local := statedb.GetNonce(addr); local != nonce {
+			fields = append(fields, FieldDivergence{Field: "nonce", Local: fmt.Sprint(local), Remote: fmt.Sprint(nonce)})
+		}
+		if local, remote := crypto.Keccak256Hash(statedb.GetCode(addr)), crypto.Keccak256Hash(code); local != remote {
+			fields = append(fields, FieldDivergence{Field: "codeHash", Local: local.Hex(), Remote: remote.Hex()})
+		}
+		if len(fields) > 0 {
+			result.Accounts = append(result.Accounts, AccountDivergence{Address: addr, Fields: fields})
+		}
+	}
+	return result, nil
+}
+
+// diffReceipts returns the consensus-relevant receipt fields that differ.
+func diffReceipts(local, remote *types.Receipt) []FieldDivergence {
+	var fields []FieldDivergence
+	if local.BlockHash != remote.BlockHash {
+		fields = append(fields, FieldDivergence{Field: "blockHash", Local: local.BlockHash.Hex(), Remote: remote.BlockHash.Hex()})
+	}
+	if local.Status != remote.Status {
+		fields = append(fields, FieldDivergence{Field: "status", Local: fmt.Sprint(local.Status), Remote: fmt.Sprint(remote.Status)})
+	}
+	if local.GasUsed != remote.GasUsed {
+		fields = append(fields, FieldDivergence{Field: "gasUsed", Local: fmt.Sprint(local.GasUsed), Remote: fmt.Sprint(remote.GasUsed)})
+	}
+	if local.CumulativeGasUsed != remote.CumulativeGasUsed {
+		fields = append(fields, FieldDivergence{Field: "cumulativeGasUsed", Local: fmt.Sprint(local.CumulativeGasUsed), Remote: fmt.Sprint(remote.CumulativeGasUsed)})
+	}
+	if len(local.Logs) != len(remote.Logs) {
+		fields = append(fields, FieldDivergence{Field: "logs", Local: fmt.Sprint(len(local.Logs)), Remote: fmt.Sprint(len(remote.Logs))})
+	}
+	if local.Bloom != remote.Bloom {
+		fields = append(fields, FieldDivergence{Field: "logsBloom", Local: hexutil.Encode(local.Bloom[:]), Remote: hexutil.Encode(remote.Bloom[:])})
+	}
+	return fields
+}
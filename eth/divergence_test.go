@@ -0,0 +1,144 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package eth
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/scroll-tech/go-ethereum"
+	"github.com/scroll-tech/go-ethereum/common"
+	"github.com/scroll-tech/go-ethereum/consensus/ethash"
+	"github.com/scroll-tech/go-ethereum/core"
+	"github.com/scroll-tech/go-ethereum/core/rawdb"
+	"github.com/scroll-tech/go-ethereum/core/types"
+	"github.com/scroll-tech/go-ethereum/core/vm"
+	"github.com/scroll-tech/go-ethereum/crypto"
+	"github.com/scroll-tech/go-ethereum/ethdb"
+	"github.com/scroll-tech/go-ethereum/params"
+)
+
+// chainRemote serves the divergenceRemote API from a local blockchain.
+type chainRemote struct {
+	bc *core.BlockChain
+	db ethdb.Database
+}
+
+func (r *chainRemote) HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error) {
+	if number == nil {
+		return r.bc.CurrentHeader(), nil
+	}
+	return r.bc.GetHeaderByNumber(number.Uint64()), nil
+}
+
+func (r *chainRemote) TransactionReceipt(ctx context.Context, txHash common.Hash) (*types.Receipt, error) {
+	receipt, _, _, _ := rawdb.ReadReceipt(r.db, txHash, r.bc.Config())
+	if receipt == nil {
+		return nil, ethereum.NotFound
+	}
+	return receipt, nil
+}
+
+func (r *chainRemote) BalanceAt(ctx context.Context, account common.Address, number *big.Int) (*big.Int, error) {
+	statedb, err := r.bc.StateAt(r.bc.GetHeaderByNumber(number.Uint64()).Root)
+	if err != nil {
+		return nil, err
+	}
+	return statedb.GetBalance(account), nil
+}
+
+func (r *chainRemote) NonceAt(ctx context.Context, account common.Address, number *big.Int) (uint64, error) {
+	statedb, err := r.bc.StateAt(r.bc.GetHeaderByNumber(number.Uint64()).Root)
+	if err != nil {
+		return 0, err
+	}
+	return statedb.GetNonce(account), nil
+}
+
+func (r *chainRemote) CodeAt(ctx context.Context, account common.Address, number *big.Int) ([]byte, error) {
+	statedb, err := r.bc.StateAt(r.bc.GetHeaderByNumber(number.Uint64()).Root)
+	if err != nil {
+		return nil, err
+	}
+	return statedb.GetCode(account), nil
+}
+
+// newDivergenceTestChain creates a chain of n blocks that transfers value(i) wei to a fixed recipient in every block.
+func newDivergenceTestChain(t *testing.T, n int, value func(i int) int64) *chainRemote {
+	var (
+		key, _  = crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+		address = crypto.PubkeyToAddress(key.PublicKey)
+		gspec   = &core.Genesis{
+			Config:  params.TestChainConfig,
+			Alloc:   core.GenesisAlloc{address: {Balance: big.NewInt(1000000000000000)}},
+			BaseFee: big.NewInt(params.InitialBaseFee),
+		}
+		db      = rawdb.NewMemoryDatabase()
+		genesis = gspec.MustCommit(db)
+		signer  = types.LatestSigner(gspec.Config)
+	)
+	blocks, _ := core.GenerateChain(gspec.Config, genesis, ethash.NewFaker(), db, n, func(i int, b *core.BlockGen) {
+		tx, err := types.SignTx(types.NewTransaction(b.TxNonce(address), common.Address{0x42}, big.NewInt(value(i)), params.TxGas, b.BaseFee(), nil), signer, key)
+		require.NoError(t, err)
+		b.AddTx(tx)
+	})
+	bc, err := core.NewBlockChain(db, nil, gspec.Config, ethash.NewFaker(), vm.Config{}, nil, nil)
+	require.NoError(t, err)
+	_, err = bc.InsertChain(blocks)
+	require.NoError(t, err)
+	t.Cleanup(bc.Stop)
+	return &chainRemote{bc: bc, db: db}
+}
+
+func TestFindDivergence(t *testing.T) {
+	local := newDivergenceTestChain(t, 20, func(i int) int64 { return 1 }).bc
+
+	// identical chains do not diverge
+	result, err := findDivergence(context.Background(), local, newDivergenceTestChain(t, 20, func(i int) int64 { return 1 }))
+	require.NoError(t, err)
+	assert.Nil(t, result)
+
+	// the remote chain transfers a different value from block 13 onwards
+	remote := newDivergenceTestChain(t, 25, func(i int) int64 {
+		if i >= 12 {
+			return 2
+		}
+		return 1
+	})
+	result, err = findDivergence(context.Background(), local, remote)
+	require.NoError(t, err)
+	require.NotNil(t, result)
+	assert.Equal(t, uint64(13), uint64(result.BlockNumber))
+	assert.Equal(t, local.GetHeaderByNumber(13).Root, result.LocalStateRoot)
+	assert.Equal(t, remote.bc.GetHeaderByNumber(13).Root, result.RemoteStateRoot)
+
+	require.Len(t, result.Receipts, 1)
+	assert.Equal(t, []FieldDivergence{{Field: "receipt", Local: "present", Remote: "missing"}}, result.Receipts[0].Fields)
+
+	var recipient *AccountDivergence
+	for i := range result.Accounts {
+		if result.Accounts[i].Address == (common.Address{0x42}) {
+			recipient = &result.Accounts[i]
+		}
+	}
+	require.NotNil(t, recipient)
+	assert.Equal(t, []FieldDivergence{{Field: "balance", Local: "13", Remote: "14"}}, recipient.Fields)
+}
@@ -37,6 +37,7 @@ import (
 	"github.com/scroll-tech/go-ethereum/miner"
 	"github.com/scroll-tech/go-ethereum/node"
 	"github.com/scroll-tech/go-ethereum/params"
+	"github.com/scroll-tech/go-ethereum/rollup/rollup_sync_service"
 )
 
 // FullNodeGPO contains default gasprice oracle settings for full node.
@@ -86,6 +87,8 @@ var Defaults = Config{
 		GasCeil:  8000000,
 		GasPrice: big.NewInt(params.GWei),
 		Recommit: 3 * time.Second,
+
+		MaxSkippedL1Messages: -1,
 	},
 	TxPool:        core.DefaultTxPoolConfig,
 	RPCGasCap:     50000000,
@@ -167,6 +170,7 @@ type Config struct {
 	TrieDirtyCache          int
 	TrieTimeout             time.Duration
 	SnapshotCache           int
+	SnapshotZktrie          bool `toml:",omitempty"` // Whether to maintain the state snapshot of zktrie states
 	Preimages               bool
 
 	// Mining options
@@ -212,11 +216,26 @@ type Config struct {
 	// Check circuit capacity in block validator
 	CheckCircuitCapacity bool
 
+	// Maintain a shadow MPT state alongside the zktrie state and cross-check it after every block
+	DualTrie bool
+
 	// Enable verification of batch consistency between L1 and L2 in rollup
 	EnableRollupVerify bool
 
+	// RPC endpoint of a node running the rollup verifier, whose rollup metadata
+	// is served instead of syncing it from L1
+	RollupFollow string `toml:",omitempty"`
+
 	// Max block range for eth_getLogs api method
 	MaxBlockRange int64
+
+	// Backfill the withdraw roots of the blocks imported before they were stored,
+	// reading at most WithdrawRootBackfillRateLimit block states per second (0 = unlimited)
+	WithdrawRootBackfill          bool
+	WithdrawRootBackfillRateLimit uint64
+
+	// Rollup sync service options, configured through the [Rollup] section of the geth config file
+	RollupSync rollup_sync_service.Config `toml:"-"`
 }
 
 // CreateConsensusEngine creates a consensus engine for the given chain configuration.
@@ -13,57 +13,64 @@ import (
 	"github.com/scroll-tech/go-ethereum/eth/gasprice"
 	"github.com/scroll-tech/go-ethereum/miner"
 	"github.com/scroll-tech/go-ethereum/params"
+	"github.com/scroll-tech/go-ethereum/rollup/rollup_sync_service"
 )
 
 // MarshalTOML marshals as TOML.
 func (c Config) MarshalTOML() (interface{}, error) {
 	type Config struct {
-		Genesis                 *core.Genesis `toml:",omitempty"`
-		NetworkId               uint64
-		SyncMode                downloader.SyncMode
-		EthDiscoveryURLs        []string
-		SnapDiscoveryURLs       []string
-		NoPruning               bool
-		NoPrefetch              bool
-		TxLookupLimit           uint64                 `toml:",omitempty"`
-		Whitelist               map[uint64]common.Hash `toml:"-"`
-		LightServ               int                    `toml:",omitempty"`
-		LightIngress            int                    `toml:",omitempty"`
-		LightEgress             int                    `toml:",omitempty"`
-		LightPeers              int                    `toml:",omitempty"`
-		LightNoPrune            bool                   `toml:",omitempty"`
-		LightNoSyncServe        bool                   `toml:",omitempty"`
-		SyncFromCheckpoint      bool                   `toml:",omitempty"`
-		UltraLightServers       []string               `toml:",omitempty"`
-		UltraLightFraction      int                    `toml:",omitempty"`
-		UltraLightOnlyAnnounce  bool                   `toml:",omitempty"`
-		SkipBcVersionCheck      bool                   `toml:"-"`
-		DatabaseHandles         int                    `toml:"-"`
-		DatabaseCache           int
-		DatabaseFreezer         string
-		TrieCleanCache          int
-		TrieCleanCacheJournal   string        `toml:",omitempty"`
-		TrieCleanCacheRejournal time.Duration `toml:",omitempty"`
-		TrieDirtyCache          int
-		TrieTimeout             time.Duration
-		SnapshotCache           int
-		Preimages               bool
-		Miner                   miner.Config
-		Ethash                  ethash.Config
-		TxPool                  core.TxPoolConfig
-		GPO                     gasprice.Config
-		EnablePreimageRecording bool
-		DocRoot                 string `toml:"-"`
-		RPCGasCap               uint64
-		RPCEVMTimeout           time.Duration
-		RPCTxFeeCap             float64
-		Checkpoint              *params.TrustedCheckpoint      `toml:",omitempty"`
-		CheckpointOracle        *params.CheckpointOracleConfig `toml:",omitempty"`
-		OverrideArrowGlacier    *big.Int                       `toml:",omitempty"`
-		MPTWitness              int
-		CheckCircuitCapacity    bool
-		EnableRollupVerify      bool
-		MaxBlockRange           int64
+		Genesis                       *core.Genesis `toml:",omitempty"`
+		NetworkId                     uint64
+		SyncMode                      downloader.SyncMode
+		EthDiscoveryURLs              []string
+		SnapDiscoveryURLs             []string
+		NoPruning                     bool
+		NoPrefetch                    bool
+		TxLookupLimit                 uint64                 `toml:",omitempty"`
+		Whitelist                     map[uint64]common.Hash `toml:"-"`
+		LightServ                     int                    `toml:",omitempty"`
+		LightIngress                  int                    `toml:",omitempty"`
+		LightEgress                   int                    `toml:",omitempty"`
+		LightPeers                    int                    `toml:",omitempty"`
+		LightNoPrune                  bool                   `toml:",omitempty"`
+		LightNoSyncServe              bool                   `toml:",omitempty"`
+		SyncFromCheckpoint            bool                   `toml:",omitempty"`
+		UltraLightServers             []string               `toml:",omitempty"`
+		UltraLightFraction            int                    `toml:",omitempty"`
+		UltraLightOnlyAnnounce        bool                   `toml:",omitempty"`
+		SkipBcVersionCheck            bool                   `toml:"-"`
+		DatabaseHandles               int                    `toml:"-"`
+		DatabaseCache                 int
+		DatabaseFreezer               string
+		TrieCleanCache                int
+		TrieCleanCacheJournal         string        `toml:",omitempty"`
+		TrieCleanCacheRejournal       time.Duration `toml:",omitempty"`
+		TrieDirtyCache                int
+		TrieTimeout                   time.Duration
+		SnapshotCache                 int
+		SnapshotZktrie                bool `toml:",omitempty"`
+		Preimages                     bool
+		Miner                         miner.Config
+		Ethash                        ethash.Config
+		TxPool                        core.TxPoolConfig
+		GPO                           gasprice.Config
+		EnablePreimageRecording       bool
+		DocRoot                       string `toml:"-"`
+		RPCGasCap                     uint64
+		RPCEVMTimeout                 time.Duration
+		RPCTxFeeCap                   float64
+		Checkpoint                    *params.TrustedCheckpoint      `toml:",omitempty"`
+		CheckpointOracle              *params.CheckpointOracleConfig `toml:",omitempty"`
+		OverrideArrowGlacier          *big.Int                       `toml:",omitempty"`
+		MPTWitness                    int
+		CheckCircuitCapacity          bool
+		DualTrie                      bool
+		EnableRollupVerify            bool
+		RollupFollow                  string `toml:",omitempty"`
+		MaxBlockRange                 int64
+		WithdrawRootBackfill          bool
+		WithdrawRootBackfillRateLimit uint64
+		RollupSync                    rollup_sync_service.Config `toml:"-"`
 	}
 	var enc Config
 	enc.Genesis = c.Genesis
@@ -95,6 +102,7 @@ func (c Config) MarshalTOML() (interface{}, error) {
 	enc.TrieDirtyCache = c.TrieDirtyCache
 	enc.TrieTimeout = c.TrieTimeout
 	enc.SnapshotCache = c.SnapshotCache
+	enc.SnapshotZktrie = c.SnapshotZktrie
 	enc.Preimages = c.Preimages
 	enc.Miner = c.Miner
 	enc.Ethash = c.Ethash
@@ -110,60 +118,71 @@ func (c Config) MarshalTOML() (interface{}, error) {
 	enc.OverrideArrowGlacier = c.OverrideArrowGlacier
 	enc.MPTWitness = c.MPTWitness
 	enc.CheckCircuitCapacity = c.CheckCircuitCapacity
+	enc.DualTrie = c.DualTrie
 	enc.EnableRollupVerify = c.EnableRollupVerify
+	enc.RollupFollow = c.RollupFollow
 	enc.MaxBlockRange = c.MaxBlockRange
+	enc.WithdrawRootBackfill = c.WithdrawRootBackfill
+	enc.WithdrawRootBackfillRateLimit = c.WithdrawRootBackfillRateLimit
+	enc.RollupSync = c.RollupSync
 	return &enc, nil
 }
 
 // UnmarshalTOML unmarshals from TOML.
 func (c *Config) UnmarshalTOML(unmarshal func(interface{}) error) error {
 	type Config struct {
-		Genesis                 *core.Genesis `toml:",omitempty"`
-		NetworkId               *uint64
-		SyncMode                *downloader.SyncMode
-		EthDiscoveryURLs        []string
-		SnapDiscoveryURLs       []string
-		NoPruning               *bool
-		NoPrefetch              *bool
-		TxLookupLimit           *uint64                `toml:",omitempty"`
-		Whitelist               map[uint64]common.Hash `toml:"-"`
-		LightServ               *int                   `toml:",omitempty"`
-		LightIngress            *int                   `toml:",omitempty"`
-		LightEgress             *int                   `toml:",omitempty"`
-		LightPeers              *int                   `toml:",omitempty"`
-		LightNoPrune            *bool                  `toml:",omitempty"`
-		LightNoSyncServe        *bool                  `toml:",omitempty"`
-		SyncFromCheckpoint      *bool                  `toml:",omitempty"`
-		UltraLightServers       []string               `toml:",omitempty"`
-		UltraLightFraction      *int                   `toml:",omitempty"`
-		UltraLightOnlyAnnounce  *bool                  `toml:",omitempty"`
-		SkipBcVersionCheck      *bool                  `toml:"-"`
-		DatabaseHandles         *int                   `toml:"-"`
-		DatabaseCache           *int
-		DatabaseFreezer         *string
-		TrieCleanCache          *int
-		TrieCleanCacheJournal   *string        `toml:",omitempty"`
-		TrieCleanCacheRejournal *time.Duration `toml:",omitempty"`
-		TrieDirtyCache          *int
-		TrieTimeout             *time.Duration
-		SnapshotCache           *int
-		Preimages               *bool
-		Miner                   *miner.Config
-		Ethash                  *ethash.Config
-		TxPool                  *core.TxPoolConfig
-		GPO                     *gasprice.Config
-		EnablePreimageRecording *bool
-		DocRoot                 *string `toml:"-"`
-		RPCGasCap               *uint64
-		RPCEVMTimeout           *time.Duration
-		RPCTxFeeCap             *float64
-		Checkpoint              *params.TrustedCheckpoint      `toml:",omitempty"`
-		CheckpointOracle        *params.CheckpointOracleConfig `toml:",omitempty"`
-		OverrideArrowGlacier    *big.Int                       `toml:",omitempty"`
-		MPTWitness              *int
-		CheckCircuitCapacity    *bool
-		EnableRollupVerify      *bool
-		MaxBlockRange           *int64
+		Genesis                       *core.Genesis `toml:",omitempty"`
+		NetworkId                     *uint64
+		SyncMode                      *downloader.SyncMode
+		EthDiscoveryURLs              []string
+		SnapDiscoveryURLs             []string
+		NoPruning                     *bool
+		NoPrefetch                    *bool
+		TxLookupLimit                 *uint64                `toml:",omitempty"`
+		Whitelist                     map[uint64]common.Hash `toml:"-"`
+		LightServ                     *int                   `toml:",omitempty"`
+		LightIngress                  *int                   `toml:",omitempty"`
+		LightEgress                   *int                   `toml:",omitempty"`
+		LightPeers                    *int                   `toml:",omitempty"`
+		LightNoPrune                  *bool                  `toml:",omitempty"`
+		LightNoSyncServe              *bool                  `toml:",omitempty"`
+		SyncFromCheckpoint            *bool                  `toml:",omitempty"`
+		UltraLightServers             []string               `toml:",omitempty"`
+		UltraLightFraction            *int                   `toml:",omitempty"`
+		UltraLightOnlyAnnounce        *bool                  `toml:",omitempty"`
+		SkipBcVersionCheck            *bool                  `toml:"-"`
+		DatabaseHandles               *int                   `toml:"-"`
+		DatabaseCache                 *int
+		DatabaseFreezer               *string
+		TrieCleanCache                *int
+		TrieCleanCacheJournal         *string        `toml:",omitempty"`
+		TrieCleanCacheRejournal       *time.Duration `toml:",omitempty"`
+		TrieDirtyCache                *int
+		TrieTimeout                   *time.Duration
+		SnapshotCache                 *int
+		SnapshotZktrie                *bool `toml:",omitempty"`
+		Preimages                     *bool
+		Miner                         *miner.Config
+		Ethash                        *ethash.Config
+		TxPool                        *core.TxPoolConfig
+		GPO                           *gasprice.Config
+		EnablePreimageRecording       *bool
+		DocRoot                       *string `toml:"-"`
+		RPCGasCap                     *uint64
+		RPCEVMTimeout                 *time.Duration
+		RPCTxFeeCap                   *float64
+		Checkpoint                    *params.TrustedCheckpoint      `toml:",omitempty"`
+		CheckpointOracle              *params.CheckpointOracleConfig `toml:",omitempty"`
+		OverrideArrowGlacier          *big.Int                       `toml:",omitempty"`
+		MPTWitness                    *int
+		CheckCircuitCapacity          *bool
+		DualTrie                      *bool
+		EnableRollupVerify            *bool
+		RollupFollow                  *string `toml:",omitempty"`
+		MaxBlockRange                 *int64
+		WithdrawRootBackfill          *bool
+		WithdrawRootBackfillRateLimit *uint64
+		RollupSync                    *rollup_sync_service.Config `toml:"-"`
 	}
 	var dec Config
 	if err := unmarshal(&dec); err != nil {
@@ -256,6 +275,9 @@ func (c *Config) UnmarshalTOML(unmarshal func(interface{}) error) error {
 	if dec.SnapshotCache != nil {
 		c.SnapshotCache = *dec.SnapshotCache
 	}
+	if dec.SnapshotZktrie != nil {
+		c.SnapshotZktrie = *dec.SnapshotZktrie
+	}
 	if dec.Preimages != nil {
 		c.Preimages = *dec.Preimages
 	}
@@ -301,11 +323,26 @@ func (c *Config) UnmarshalTOML(unmarshal func(interface{}) error) error {
 	if dec.CheckCircuitCapacity != nil {
 		c.CheckCircuitCapacity = *dec.CheckCircuitCapacity
 	}
+	if dec.DualTrie != nil {
+		c.DualTrie = *dec.DualTrie
+	}
 	if dec.EnableRollupVerify != nil {
 		c.EnableRollupVerify = *dec.EnableRollupVerify
 	}
+	if dec.RollupFollow != nil {
+		c.RollupFollow = *dec.RollupFollow
+	}
 	if dec.MaxBlockRange != nil {
 		c.MaxBlockRange = *dec.MaxBlockRange
 	}
+	if dec.WithdrawRootBackfill != nil {
+		c.WithdrawRootBackfill = *dec.WithdrawRootBackfill
+	}
+	if dec.WithdrawRootBackfillRateLimit != nil {
+		c.WithdrawRootBackfillRateLimit = *dec.WithdrawRootBackfillRateLimit
+	}
+	if dec.RollupSync != nil {
+		c.RollupSync = *dec.RollupSync
+	}
 	return nil
 }
@@ -28,14 +28,17 @@ import (
 
 	"github.com/scroll-tech/go-ethereum/common"
 	"github.com/scroll-tech/go-ethereum/consensus/misc"
+	"github.com/scroll-tech/go-ethereum/core/state"
 	"github.com/scroll-tech/go-ethereum/core/types"
 	"github.com/scroll-tech/go-ethereum/log"
+	"github.com/scroll-tech/go-ethereum/rollup/fees"
 	"github.com/scroll-tech/go-ethereum/rpc"
 )
 
 var (
 	errInvalidPercentile = errors.New("invalid reward percentile")
 	errRequestBeyondHead = errors.New("request beyond head block")
+	errL1FeesUnsupported = errors.New("L1 fee history not supported by backend")
 )
 
 const (
@@ -315,3 +318,48 @@ func (oracle *Oracle) FeeHistory(ctx context.Context, blocks int, unresolvedLast
 	baseFee, gasUsedRatio = baseFee[:firstMissing+1], gasUsedRatio[:firstMissing]
 	return new(big.Int).SetUint64(oldestBlock), reward, baseFee, gasUsedRatio, nil
 }
+
+// l1FeesBackend is implemented by backends that can read the state of past blocks.
+type l1FeesBackend interface {
+	StateAndHeaderByNumber(ctx context.Context, number rpc.BlockNumber) (*state.StateDB, *types.Header, error)
+}
+
+// l1FeesCacheKey is the historyCache key of the L1Fees of a block.
+type l1FeesCacheKey uint64
+
+// L1FeeHistory returns the L1 fee components stored in the L1GasPriceOracle after each block
+// of the given range, as returned by FeeHistory, which the L1 data fees of the transactions
+// of the next block are computed from. The entries of blocks whose state is not available, e.g. because it was pruned,
+// are nil.
+func (oracle *Oracle) L1FeeHistory(ctx context.Context, firstBlock uint64, blocks int) ([]*fees.L1Fees, error) {
+	backend, ok := oracle.backend.(l1FeesBackend)
+	if !ok {
+		return nil, errL1FeesUnsupported
+	}
+	pendingBlock, _ := oracle.backend.PendingBlockAndReceipts()
+
+	history := make([]*fees.L1Fees, blocks)
+	for i := range history {
+		blockNumber := firstBlock + uint64(i)
+		number := rpc.BlockNumber(blockNumber)
+		if pendingBlock != nil && blockNumber == pendingBlock.NumberU64() {
+			number = rpc.PendingBlockNumber
+		} else if cached, ok := oracle.historyCache.Get(l1FeesCacheKey(blockNumber)); ok {
+			history[i] = cached.(*fees.L1Fees)
+			continue
+		}
+		statedb, header, err := backend.StateAndHeaderByNumber(ctx, number)
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		if statedb == nil || header == nil || err != nil {
+			continue
+		}
+		l1Fees := fees.ReadL1Fees(statedb)
+		if number != rpc.PendingBlockNumber {
+			oracle.historyCache.Add(l1FeesCacheKey(blockNumber), l1Fees)
+		}
+		history[i] = l1Fees
+	}
+	return history, nil
+}
@@ -87,3 +87,30 @@ func TestFeeHistory(t *testing.T) {
 		}
 	}
 }
+
+func TestL1FeeHistory(t *testing.T) {
+	backend := newTestBackend(t, big.NewInt(0), true)
+	oracle := NewOracle(backend, Config{MaxHeaderHistory: 1000, MaxBlockHistory: 1000})
+
+	first, _, _, gasUsedRatio, err := oracle.FeeHistory(context.Background(), 5, rpc.PendingBlockNumber, nil)
+	if err != nil {
+		t.Fatalf("Failed to get fee history: %v", err)
+	}
+	history, err := oracle.L1FeeHistory(context.Background(), first.Uint64(), len(gasUsedRatio))
+	if err != nil {
+		t.Fatalf("Failed to get L1 fee history: %v", err)
+	}
+	if len(history) != 5 {
+		t.Fatalf("L1 fee history length mismatch, want %d, got %d", 5, len(history))
+	}
+	for i, fees := range history {
+		if fees == nil || fees.L1BaseFee.Int64() != testL1BaseFee || fees.Scalar.Int64() != testL1Scalar || fees.L1BlobBaseFee.Int64() != testL1BlobBaseFee || fees.BlobScalar.Sign() != 0 {
+			t.Fatalf("L1 fees of block %d mismatch, got %v", first.Uint64()+uint64(i), fees)
+		}
+	}
+	// blocks without state are reported as nil
+	history, err = oracle.L1FeeHistory(context.Background(), testHead+10, 1)
+	if err != nil || len(history) != 1 || history[0] != nil {
+		t.Fatalf("Unexpected L1 fee history beyond head: %v, %v", history, err)
+	}
+}
@@ -18,6 +18,7 @@ package gasprice
 
 import (
 	"context"
+	"math"
 	"math/big"
 	"sort"
 	"sync"
@@ -30,6 +31,7 @@ import (
 	"github.com/scroll-tech/go-ethereum/event"
 	"github.com/scroll-tech/go-ethereum/log"
 	"github.com/scroll-tech/go-ethereum/params"
+	"github.com/scroll-tech/go-ethereum/rollup/rcfg"
 	"github.com/scroll-tech/go-ethereum/rpc"
 )
 
@@ -48,6 +50,16 @@ type Config struct {
 	Default          *big.Int `toml:",omitempty"`
 	MaxPrice         *big.Int `toml:",omitempty"`
 	IgnorePrice      *big.Int `toml:",omitempty"`
+
+	// L1BaseFeeBlocks enables the rollup-aware mode: the given percentile of the L1 data gas
+	// prices (the L1 base fee times the fee scalar) set in the L1GasPriceOracle over this many
+	// recent blocks is converted into a price per L2 gas and added to the suggested tip, so
+	// that the suggestion follows the L1 data fee volatility.
+	L1BaseFeeBlocks int `toml:",omitempty"`
+	// L1BaseFeeWeight is the conversion factor of the L1 data gas price into a price per L2
+	// gas, the expected L1 data gas per L2 gas of a transaction including the fee overhead.
+	// A plain transfer uses about 0.2 L1 data gas per L2 gas.
+	L1BaseFeeWeight float64 `toml:",omitempty"`
 }
 
 // OracleBackend includes all necessary background APIs for oracle.
@@ -74,6 +86,9 @@ type Oracle struct {
 	checkBlocks, percentile           int
 	maxHeaderHistory, maxBlockHistory int
 	historyCache                      *lru.Cache
+
+	l1BaseFeeBlocks int
+	l1BaseFeeWeight *big.Float
 }
 
 // NewOracle returns a new gasprice oracle which can recommend suitable
@@ -115,6 +130,18 @@ func NewOracle(backend OracleBackend, params Config) *Oracle {
 		log.Warn("Sanitizing invalid gasprice oracle max block history", "provided", params.MaxBlockHistory, "updated", maxBlockHistory)
 	}
 
+	l1BaseFeeBlocks, l1BaseFeeWeight := params.L1BaseFeeBlocks, new(big.Float)
+	if l1BaseFeeBlocks < 0 || !(params.L1BaseFeeWeight > 0) || math.IsInf(params.L1BaseFeeWeight, 1) {
+		l1BaseFeeBlocks = 0
+	} else if l1BaseFeeBlocks > maxHeaderHistory {
+		l1BaseFeeBlocks = maxHeaderHistory
+		log.Warn("Sanitizing invalid gasprice oracle L1 base fee blocks", "provided", params.L1BaseFeeBlocks, "updated", l1BaseFeeBlocks)
+	}
+	if l1BaseFeeBlocks > 0 {
+		l1BaseFeeWeight.SetFloat64(params.L1BaseFeeWeight)
+		log.Info("Gasprice oracle is following the L1 base fee", "blocks", l1BaseFeeBlocks, "weight", params.L1BaseFeeWeight)
+	}
+
 	cache, _ := lru.New(2048)
 	headEvent := make(chan core.ChainHeadEvent, 1)
 	backend.SubscribeChainHeadEvent(headEvent)
@@ -138,16 +165,69 @@ func NewOracle(backend OracleBackend, params Config) *Oracle {
 		maxHeaderHistory: maxHeaderHistory,
 		maxBlockHistory:  maxBlockHistory,
 		historyCache:     cache,
+		l1BaseFeeBlocks:  l1BaseFeeBlocks,
+		l1BaseFeeWeight:  l1BaseFeeWeight,
 	}
 }
 
 // SuggestTipCap returns a tip cap so that newly created transaction can have a
-// very high chance to be included in the following blocks.
+// very high chance to be included in the following blocks. In the rollup-aware
+// mode, the L1 component is added to the tip cap based on the L2 congestion.
 //
 // Note, for legacy transactions and the legacy eth_gasPrice RPC call, it will be
 // necessary to add the basefee to the returned number to fall back to the legacy
 // behavior.
 func (oracle *Oracle) SuggestTipCap(ctx context.Context) (*big.Int, error) {
+	tip, err := oracle.suggestL2TipCap(ctx)
+	if err != nil || oracle.l1BaseFeeBlocks == 0 {
+		return tip, err
+	}
+	l1Component, err := oracle.suggestL1Component(ctx)
+	if err != nil {
+		return tip, err
+	}
+	tip.Add(tip, l1Component)
+	if tip.Cmp(oracle.maxPrice) > 0 {
+		tip.Set(oracle.maxPrice)
+	}
+	return tip, nil
+}
+
+// suggestL1Component returns the price per L2 gas of the L1 data fee added to the suggested
+// tip cap in the rollup-aware mode. It is the percentile of the recent L1 data gas prices,
+// converted into a price per L2 gas with the weight.
+func (oracle *Oracle) suggestL1Component(ctx context.Context) (*big.Int, error) {
+	head, err := oracle.backend.HeaderByNumber(ctx, rpc.LatestBlockNumber)
+	if head == nil || err != nil {
+		return new(big.Int), err
+	}
+	blocks := oracle.l1BaseFeeBlocks
+	if number := head.Number.Uint64(); uint64(blocks) > number+1 {
+		blocks = int(number + 1)
+	}
+	history, err := oracle.L1FeeHistory(ctx, head.Number.Uint64()+1-uint64(blocks), blocks)
+	if err != nil {
+		return new(big.Int), err
+	}
+	var l1GasPrices []*big.Int
+	for _, l1Fees := range history {
+		if l1Fees != nil {
+			l1GasPrice := new(big.Int).Mul(l1Fees.L1BaseFee, l1Fees.Scalar)
+			l1GasPrices = append(l1GasPrices, l1GasPrice.Quo(l1GasPrice, rcfg.Precision))
+		}
+	}
+	if len(l1GasPrices) == 0 {
+		return new(big.Int), nil
+	}
+	sort.Sort(bigIntArray(l1GasPrices))
+	l1GasPrice := l1GasPrices[(len(l1GasPrices)-1)*oracle.percentile/100]
+	component, _ := new(big.Float).Mul(new(big.Float).SetInt(l1GasPrice), oracle.l1BaseFeeWeight).Int(nil)
+	return component, nil
+}
+
+// suggestL2TipCap returns the tip cap suggested by the tips of the transactions
+// in recent blocks.
+func (oracle *Oracle) suggestL2TipCap(ctx context.Context) (*big.Int, error) {
 	head, _ := oracle.backend.HeaderByNumber(ctx, rpc.LatestBlockNumber)
 	headHash := head.Hash()
 
@@ -26,15 +26,22 @@ import (
 	"github.com/scroll-tech/go-ethereum/consensus/ethash"
 	"github.com/scroll-tech/go-ethereum/core"
 	"github.com/scroll-tech/go-ethereum/core/rawdb"
+	"github.com/scroll-tech/go-ethereum/core/state"
 	"github.com/scroll-tech/go-ethereum/core/types"
 	"github.com/scroll-tech/go-ethereum/core/vm"
 	"github.com/scroll-tech/go-ethereum/crypto"
 	"github.com/scroll-tech/go-ethereum/event"
 	"github.com/scroll-tech/go-ethereum/params"
+	"github.com/scroll-tech/go-ethereum/rollup/rcfg"
 	"github.com/scroll-tech/go-ethereum/rpc"
 )
 
-const testHead = 32
+const (
+	testHead          = 32
+	testL1BaseFee     = 20 * params.GWei
+	testL1Scalar      = 1150000000 // 115%
+	testL1BlobBaseFee = 2000
+)
 
 type testBackend struct {
 	chain   *core.BlockChain
@@ -87,6 +94,15 @@ func (b *testBackend) PendingBlockAndReceipts() (*types.Block, types.Receipts) {
 	return nil, nil
 }
 
+func (b *testBackend) StateAndHeaderByNumber(ctx context.Context, number rpc.BlockNumber) (*state.StateDB, *types.Header, error) {
+	header, err := b.HeaderByNumber(ctx, number)
+	if header == nil || err != nil {
+		return nil, nil, err
+	}
+	statedb, err := b.chain.StateAt(header.Root)
+	return statedb, header, err
+}
+
 func (b *testBackend) ChainConfig() *params.ChainConfig {
 	return b.chain.Config()
 }
@@ -102,7 +118,14 @@ func newTestBackend(t *testing.T, londonBlock *big.Int, pending bool) *testBacke
 		config = *params.TestChainConfig // needs copy because it is modified below
 		gspec  = &core.Genesis{
 			Config: &config,
-			Alloc:  core.GenesisAlloc{addr: {Balance: big.NewInt(math.MaxInt64)}},
+			Alloc: core.GenesisAlloc{
+				addr: {Balance: big.NewInt(math.MaxInt64)},
+				rcfg.L1GasPriceOracleAddress: {Balance: big.NewInt(0), Storage: map[common.Hash]common.Hash{
+					rcfg.L1BaseFeeSlot:     common.BigToHash(big.NewInt(testL1BaseFee)),
+					rcfg.ScalarSlot:        common.BigToHash(big.NewInt(testL1Scalar)),
+					rcfg.L1BlobBaseFeeSlot: common.BigToHash(big.NewInt(testL1BlobBaseFee)),
+				}},
+			},
 		}
 		signer = types.LatestSigner(gspec.Config)
 	)
@@ -190,3 +213,39 @@ func TestSuggestTipCap(t *testing.T) {
 		}
 	}
 }
+
+func TestSuggestTipCapL1BaseFee(t *testing.T) {
+	config := Config{
+		Blocks:           3,
+		Percentile:       60,
+		MaxHeaderHistory: 1024,
+		Default:          big.NewInt(params.GWei),
+		L1BaseFeeBlocks:  10,
+		L1BaseFeeWeight:  0.2,
+	}
+	backend := newTestBackend(t, big.NewInt(0), false)
+	oracle := NewOracle(backend, config)
+
+	// The L2 gas price sampled is 30G, the L1 data gas price of 20G * 115% is the same in all
+	// blocks, at 0.2 L1 data gas per L2 gas it adds 4.6G
+	got, err := oracle.SuggestTipCap(context.Background())
+	if err != nil {
+		t.Fatalf("Failed to retrieve recommended gas price: %v", err)
+	}
+	expect := big.NewInt(params.GWei*30 + params.GWei*46/10)
+	if got.Cmp(expect) != 0 {
+		t.Fatalf("Gas price mismatch, want %d, got %d", expect, got)
+	}
+
+	// the L1 component is not fed back into the L2 samples
+	if got, _ := oracle.SuggestTipCap(context.Background()); got.Cmp(expect) != 0 {
+		t.Fatalf("Cached gas price mismatch, want %d, got %d", expect, got)
+	}
+
+	// the suggestion is capped
+	config.MaxPrice = big.NewInt(params.GWei * 30)
+	oracle = NewOracle(backend, config)
+	if got, _ := oracle.SuggestTipCap(context.Background()); got.Cmp(config.MaxPrice) != 0 {
+		t.Fatalf("Gas price mismatch, want %d, got %d", config.MaxPrice, got)
+	}
+}
@@ -85,6 +85,8 @@ type handlerConfig struct {
 	EventMux   *event.TypeMux            // Legacy event mux, deprecate for `feed`
 	Checkpoint *params.TrustedCheckpoint // Hard coded checkpoint for sync challenges
 	Whitelist  map[uint64]common.Hash    // Hard coded whitelist for sync challenged
+
+	BatchMetadata bool // Whether rollup batch metadata is stored, advertised on the `scroll` protocol
 }
 
 type handler struct {
@@ -108,6 +110,7 @@ type handler struct {
 	blockFetcher *fetcher.BlockFetcher
 	txFetcher    *fetcher.TxFetcher
 	peers        *peerSet
+	scrollPeers  *scrollPeerSet
 
 	eventMux      *event.TypeMux
 	txsCh         chan core.NewTxsEvent
@@ -116,6 +119,8 @@ type handler struct {
 
 	whitelist map[uint64]common.Hash
 
+	batchMetadata bool
+
 	// channels for fetcher, syncer, txsyncLoop
 	quitSync chan struct{}
 
@@ -140,6 +145,9 @@ func newHandler(config *handlerConfig) (*handler, error) {
 		peers:      newPeerSet(),
 		whitelist:  config.Whitelist,
 		quitSync:   make(chan struct{}),
+
+		scrollPeers:   newScrollPeerSet(),
+		batchMetadata: config.BatchMetadata,
 	}
 	if config.Sync == downloader.FullSync {
 		// The database seems empty as the current block is the genesis. Yet the fast
@@ -405,6 +413,10 @@ func (h *handler) Start(maxPeers int) {
 	h.minedBlockSub = h.eventMux.Subscribe(core.NewMinedBlockEvent{})
 	go h.minedBroadcastLoop()
 
+	// announce the finalized L2 block to scroll peers
+	h.wg.Add(1)
+	go h.finalizedBroadcastLoop()
+
 	// start sync handlers
 	h.wg.Add(1)
 	go h.chainSync.loop()
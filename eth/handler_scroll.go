@@ -0,0 +1,178 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package eth
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/scroll-tech/go-ethereum/eth/protocols/scroll"
+	"github.com/scroll-tech/go-ethereum/p2p/enode"
+	"github.com/scroll-tech/go-ethereum/rollup/rollup_sync_service"
+)
+
+// finalizedAnnounceInterval is the frequency of checking whether the finalized L2 block
+// advanced, in which case it is announced to the `scroll` peers.
+const finalizedAnnounceInterval = 10 * time.Second
+
+// scrollHandler implements the scroll.Backend interface to advertise the rollup
+// capabilities of the node and to track those of its peers.
+type scrollHandler handler
+
+// Capabilities retrieves the rollup capabilities of the local node.
+func (h *scrollHandler) Capabilities() *scroll.StatusPacket {
+	status := &scroll.StatusPacket{
+		CodecVersions: rollup_sync_service.CodecVersions(),
+		BatchMetadata: h.batchMetadata,
+	}
+	if finalized := h.chain.CurrentFinalizedBlock(); finalized != nil {
+		status.FinalizedL2Block = finalized.Number.Uint64()
+	}
+	return status
+}
+
+// RunPeer is invoked when a peer completed the `scroll` handshake.
+func (h *scrollHandler) RunPeer(peer *scroll.Peer, hand scroll.Handler) error {
+	h.peerWG.Add(1)
+	defer h.peerWG.Done()
+
+	if err := h.scrollPeers.register(peer); err != nil {
+		peer.Log().Error("Scroll peer registration failed", "err", err)
+		return err
+	}
+	defer h.scrollPeers.unregister(peer.ID())
+
+	peer.Log().Debug("Scroll peer connected", "codecs", peer.Capabilities().CodecVersions, "batch metadata", peer.Capabilities().BatchMetadata)
+	return hand(peer)
+}
+
+// PeerInfo retrieves all known `scroll` information about a peer.
+func (h *scrollHandler) PeerInfo(id enode.ID) interface{} {
+	if p := h.scrollPeers.peer(id.String()); p != nil {
+		return p.Info()
+	}
+	return nil
+}
+
+// finalizedBroadcastLoop announces the finalized L2 block to the `scroll` peers whenever
+// it advances, so that their view of the local finality stays current after the handshake.
+func (h *handler) finalizedBroadcastLoop() {
+	defer h.wg.Done()
+
+	ticker := time.NewTicker(finalizedAnnounceInterval)
+	defer ticker.Stop()
+
+	var announced uint64
+	for {
+		select {
+		case <-ticker.C:
+			finalized := h.chain.CurrentFinalizedBlock()
+			if finalized == nil || finalized.Number.Uint64() <= announced {
+				continue
+			}
+			announced = finalized.Number.Uint64()
+			for _, peer := range h.scrollPeers.all() {
+				peer := peer
+				go func() {
+					if err := peer.SendFinalized(announced); err != nil {
+						peer.Log().Debug("Failed to announce finalized L2 block", "number", announced, "err", err)
+					}
+				}()
+			}
+		case <-h.quitSync:
+			return
+		}
+	}
+}
+
+// peerFinalizedL2Block returns the highest finalized L2 block advertised by the peers
+// that serve the metadata of batches of the latest codec, or nil if no such peer is
+// connected. The advertised blocks are not verified.
+func (h *handler) peerFinalizedL2Block() *uint64 {
+	peers := h.scrollPeers.batchMetadataPeers(rollup_sync_service.LatestCodec().Version())
+	if len(peers) == 0 {
+		return nil
+	}
+	number := peers[0].FinalizedL2Block()
+	return &number
+}
+
+// scrollPeerSet tracks the peers running the `scroll` protocol.
+type scrollPeerSet struct {
+	peers map[string]*scroll.Peer
+	lock  sync.RWMutex
+}
+
+func newScrollPeerSet() *scrollPeerSet {
+	return &scrollPeerSet{peers: make(map[string]*scroll.Peer)}
+}
+
+func (ps *scrollPeerSet) register(peer *scroll.Peer) error {
+	ps.lock.Lock()
+	defer ps.lock.Unlock()
+
+	if _, ok := ps.peers[peer.ID()]; ok {
+		return errPeerAlreadyRegistered
+	}
+	ps.peers[peer.ID()] = peer
+	return nil
+}
+
+func (ps *scrollPeerSet) unregister(id string) {
+	ps.lock.Lock()
+	defer ps.lock.Unlock()
+
+	delete(ps.peers, id)
+}
+
+func (ps *scrollPeerSet) peer(id string) *scroll.Peer {
+	ps.lock.RLock()
+	defer ps.lock.RUnlock()
+
+	return ps.peers[id]
+}
+
+// all retrieves all the peers running the `scroll` protocol.
+func (ps *scrollPeerSet) all() []*scroll.Peer {
+	ps.lock.RLock()
+	defer ps.lock.RUnlock()
+
+	list := make([]*scroll.Peer, 0, len(ps.peers))
+	for _, p := range ps.peers {
+		list = append(list, p)
+	}
+	return list
+}
+
+// batchMetadataPeers retrieves the peers that serve the metadata of batches with
+// the given codec version, the peers with the highest finalized L2 block first.
+func (ps *scrollPeerSet) batchMetadataPeers(codecVersion uint8) []*scroll.Peer {
+	ps.lock.RLock()
+	defer ps.lock.RUnlock()
+
+	list := make([]*scroll.Peer, 0, len(ps.peers))
+	for _, p := range ps.peers {
+		if p.Capabilities().ServesBatchMetadata(codecVersion) {
+			list = append(list, p)
+		}
+	}
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].FinalizedL2Block() > list[j].FinalizedL2Block()
+	})
+	return list
+}
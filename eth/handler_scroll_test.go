@@ -0,0 +1,89 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package eth
+
+import (
+	"testing"
+
+	"github.com/scroll-tech/go-ethereum/eth/protocols/scroll"
+	"github.com/scroll-tech/go-ethereum/p2p"
+	"github.com/scroll-tech/go-ethereum/p2p/enode"
+	"github.com/scroll-tech/go-ethereum/rollup/rollup_sync_service"
+)
+
+// newScrollTestPeer creates a `scroll` peer that advertised the given capabilities.
+func newScrollTestPeer(t *testing.T, id byte, status *scroll.StatusPacket) *scroll.Peer {
+	app, net := p2p.MsgPipe()
+	t.Cleanup(func() { app.Close(); net.Close() })
+
+	peer := scroll.NewPeer(1, p2p.NewPeer(enode.ID{id}, "", nil), net)
+	remote := scroll.NewPeer(1, p2p.NewPeer(enode.ID{}, "", nil), app)
+	go remote.Handshake(status)
+	if err := peer.Handshake(&scroll.StatusPacket{}); err != nil {
+		t.Fatalf("handshake failed: %v", err)
+	}
+	return peer
+}
+
+// Tests that peers serving batch metadata are preferred by their finalized L2 block.
+func TestScrollBatchMetadataPeers(t *testing.T) {
+	ps := newScrollPeerSet()
+	peers := []*scroll.Peer{
+		newScrollTestPeer(t, 1, &scroll.StatusPacket{CodecVersions: []uint8{0, 1}, BatchMetadata: true, FinalizedL2Block: 10}),
+		newScrollTestPeer(t, 2, &scroll.StatusPacket{CodecVersions: []uint8{0, 1}, FinalizedL2Block: 30}),
+		newScrollTestPeer(t, 3, &scroll.StatusPacket{CodecVersions: []uint8{1}, BatchMetadata: true, FinalizedL2Block: 20}),
+	}
+	for _, peer := range peers {
+		if err := ps.register(peer); err != nil {
+			t.Fatalf("failed to register peer: %v", err)
+		}
+	}
+	if err := ps.register(peers[0]); err != errPeerAlreadyRegistered {
+		t.Fatalf("registered peer twice: %v", err)
+	}
+
+	if got := ps.batchMetadataPeers(1); len(got) != 2 || got[0] != peers[2] || got[1] != peers[0] {
+		t.Fatalf("wrong peers for codec 1: %v", got)
+	}
+	if got := ps.batchMetadataPeers(0); len(got) != 1 || got[0] != peers[0] {
+		t.Fatalf("wrong peers for codec 0: %v", got)
+	}
+	ps.unregister(peers[0].ID())
+	if got := ps.batchMetadataPeers(0); len(got) != 0 {
+		t.Fatalf("unregistered peer returned: %v", got)
+	}
+}
+
+// Tests that the finalized L2 block of the peers serving batch metadata is reported.
+func TestScrollPeerFinalizedL2Block(t *testing.T) {
+	h := &handler{scrollPeers: newScrollPeerSet()}
+	if got := h.peerFinalizedL2Block(); got != nil {
+		t.Fatalf("finalized L2 block reported without peers: %d", *got)
+	}
+	latest := rollup_sync_service.LatestCodec().Version()
+	for i, status := range []*scroll.StatusPacket{
+		{CodecVersions: []uint8{latest}, BatchMetadata: true, FinalizedL2Block: 10},
+		{CodecVersions: []uint8{latest}, FinalizedL2Block: 30},
+	} {
+		if err := h.scrollPeers.register(newScrollTestPeer(t, byte(i+1), status)); err != nil {
+			t.Fatalf("failed to register peer: %v", err)
+		}
+	}
+	if got := h.peerFinalizedL2Block(); got == nil || *got != 10 {
+		t.Fatalf("wrong finalized L2 block: %v", got)
+	}
+}
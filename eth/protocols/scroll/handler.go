@@ -0,0 +1,129 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package scroll
+
+import (
+	"fmt"
+
+	"github.com/scroll-tech/go-ethereum/p2p"
+	"github.com/scroll-tech/go-ethereum/p2p/enode"
+)
+
+// Handler is a callback to invoke from an outside runner after the boilerplate
+// exchanges have passed.
+type Handler func(peer *Peer) error
+
+// Backend defines the methods to advertise the local rollup capabilities and to
+// track the capabilities of remote peers.
+type Backend interface {
+	// Capabilities retrieves the rollup capabilities of the local node, they are
+	// sent to every peer joining on the `scroll` protocol.
+	Capabilities() *StatusPacket
+
+	// RunPeer is invoked when a peer completed the `scroll` handshake. The handler
+	// should track the peer until control is given back to the `handler`.
+	RunPeer(peer *Peer, handler Handler) error
+
+	// PeerInfo retrieves all known `scroll` information about a peer.
+	PeerInfo(id enode.ID) interface{}
+}
+
+// MakeProtocols constructs the P2P protocol definitions for `scroll`.
+func MakeProtocols(backend Backend) []p2p.Protocol {
+	protocols := make([]p2p.Protocol, len(ProtocolVersions))
+	for i, version := range ProtocolVersions {
+		version := version // Closure
+
+		protocols[i] = p2p.Protocol{
+			Name:    ProtocolName,
+			Version: version,
+			Length:  protocolLengths[version],
+			Run: func(p *p2p.Peer, rw p2p.MsgReadWriter) error {
+				peer := NewPeer(version, p, rw)
+				if err := peer.Handshake(backend.Capabilities()); err != nil {
+					peer.Log().Debug("Scroll handshake failed", "err", err)
+					return err
+				}
+				return backend.RunPeer(peer, Handle)
+			},
+			NodeInfo: func() interface{} {
+				return nodeInfo(backend)
+			},
+			PeerInfo: func(id enode.ID) interface{} {
+				return backend.PeerInfo(id)
+			},
+		}
+	}
+	return protocols
+}
+
+// Handle is the callback invoked to manage the life cycle of a `scroll` peer.
+// It records the finalized L2 blocks announced by the peer until the peer sends
+// an invalid message or disconnects.
+func Handle(peer *Peer) error {
+	for {
+		if err := handleMessage(peer); err != nil {
+			peer.Log().Debug("Message handling failed in `scroll`", "err", err)
+			return err
+		}
+	}
+}
+
+// handleMessage is invoked whenever an inbound message is received from a remote
+// peer. The remote connection is torn down upon returning any error.
+func handleMessage(peer *Peer) error {
+	msg, err := peer.rw.ReadMsg()
+	if err != nil {
+		return err
+	}
+	defer msg.Discard()
+
+	if msg.Size > maxMessageSize {
+		return fmt.Errorf("%w: %v > %v", errMsgTooLarge, msg.Size, maxMessageSize)
+	}
+	switch msg.Code {
+	case StatusMsg:
+		return fmt.Errorf("%w: code %x", errExtraStatusMsg, msg.Code)
+	case FinalizedMsg:
+		var packet FinalizedPacket
+		if err := msg.Decode(&packet); err != nil {
+			return fmt.Errorf("%w: message %v: %v", errDecode, msg, err)
+		}
+		peer.setFinalizedL2Block(packet.FinalizedL2Block)
+		return nil
+	default:
+		return fmt.Errorf("%w: %v", errInvalidMsgCode, msg.Code)
+	}
+}
+
+// NodeInfo represents a short summary of the `scroll` sub-protocol metadata
+// known about the host peer.
+type NodeInfo struct {
+	CodecVersions    []uint8 `json:"codecVersions"`    // Batch codec versions the node can decode
+	BatchMetadata    bool    `json:"batchMetadata"`    // Whether the node stores batch metadata
+	FinalizedL2Block uint64  `json:"finalizedL2Block"` // Highest finalized L2 block known to the node
+}
+
+// nodeInfo retrieves some `scroll` protocol metadata about the running host node.
+func nodeInfo(backend Backend) *NodeInfo {
+	status := backend.Capabilities()
+	return &NodeInfo{
+		CodecVersions:    status.CodecVersions,
+		BatchMetadata:    status.BatchMetadata,
+		FinalizedL2Block: status.FinalizedL2Block,
+	}
+}
@@ -0,0 +1,68 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package scroll
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/scroll-tech/go-ethereum/p2p"
+	"github.com/scroll-tech/go-ethereum/p2p/enode"
+)
+
+// Tests that the finalized L2 blocks announced after the handshake are recorded, and
+// that a second status message disconnects the peer.
+func TestHandleFinalized(t *testing.T) {
+	t.Parallel()
+
+	app, net := p2p.MsgPipe()
+	defer app.Close()
+	defer net.Close()
+
+	peer := NewPeer(scroll1, p2p.NewPeer(enode.ID{}, "peer", nil), net)
+	remotePeer := NewPeer(scroll1, p2p.NewPeer(enode.ID{1}, "remote", nil), app)
+
+	errc := make(chan error, 1)
+	go func() { errc <- remotePeer.Handshake(&StatusPacket{FinalizedL2Block: 50}) }()
+	if err := peer.Handshake(&StatusPacket{}); err != nil {
+		t.Fatalf("handshake failed: %v", err)
+	}
+	if err := <-errc; err != nil {
+		t.Fatalf("remote handshake failed: %v", err)
+	}
+
+	handled := make(chan error, 1)
+	go func() { handled <- Handle(peer) }()
+
+	for _, number := range []uint64{70, 60} {
+		if err := remotePeer.SendFinalized(number); err != nil {
+			t.Fatalf("failed to send finalized block: %v", err)
+		}
+	}
+	if err := p2p.Send(app, StatusMsg, &StatusPacket{}); err != nil {
+		t.Fatalf("failed to send status: %v", err)
+	}
+	if err := <-handled; !errors.Is(err, errExtraStatusMsg) {
+		t.Fatalf("wrong error: got %v, want %v", err, errExtraStatusMsg)
+	}
+	if have := peer.FinalizedL2Block(); have != 70 {
+		t.Fatalf("finalized L2 block mismatch: have %d, want %d", have, 70)
+	}
+	if have := peer.Info().FinalizedL2Block; have != 70 {
+		t.Fatalf("peer info finalized L2 block mismatch: have %d, want %d", have, 70)
+	}
+}
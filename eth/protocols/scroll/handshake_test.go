@@ -0,0 +1,101 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package scroll
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/scroll-tech/go-ethereum/p2p"
+	"github.com/scroll-tech/go-ethereum/p2p/enode"
+	"github.com/scroll-tech/go-ethereum/rlp"
+)
+
+// Tests that the rollup capabilities are exchanged in the handshake.
+func TestHandshake(t *testing.T) {
+	t.Parallel()
+
+	app, net := p2p.MsgPipe()
+	defer app.Close()
+	defer net.Close()
+
+	local := &StatusPacket{CodecVersions: []uint8{0, 1}, BatchMetadata: true, FinalizedL2Block: 100}
+	remote := &StatusPacket{CodecVersions: []uint8{1}, FinalizedL2Block: 50}
+
+	peer := NewPeer(scroll1, p2p.NewPeer(enode.ID{}, "peer", nil), net)
+	remotePeer := NewPeer(scroll1, p2p.NewPeer(enode.ID{1}, "remote", nil), app)
+
+	errc := make(chan error, 1)
+	go func() { errc <- remotePeer.Handshake(remote) }()
+	if err := peer.Handshake(local); err != nil {
+		t.Fatalf("handshake failed: %v", err)
+	}
+	if err := <-errc; err != nil {
+		t.Fatalf("remote handshake failed: %v", err)
+	}
+	if got := peer.Capabilities(); !reflect.DeepEqual(got.CodecVersions, remote.CodecVersions) || got.BatchMetadata || got.FinalizedL2Block != 50 {
+		t.Fatalf("capabilities mismatch: have %+v, want %+v", got, remote)
+	}
+	if got := remotePeer.Capabilities(); !got.ServesBatchMetadata(1) || got.ServesBatchMetadata(2) || got.FinalizedL2Block != 100 {
+		t.Fatalf("capabilities mismatch: have %+v, want %+v", got, local)
+	}
+}
+
+// Tests that handshake failures are detected and reported correctly, and that
+// status messages with additional fields are accepted.
+func TestHandshakeFailures(t *testing.T) {
+	t.Parallel()
+
+	extended := []interface{}{[]uint8{1}, true, uint64(10), "future field"}
+	tests := []struct {
+		code uint64
+		data interface{}
+		want error
+	}{
+		{code: 0x01, data: []interface{}{}, want: errNoStatusMsg},
+		{code: StatusMsg, data: []interface{}{"invalid"}, want: errDecode},
+		{code: StatusMsg, data: extended, want: nil},
+	}
+	for i, test := range tests {
+		app, net := p2p.MsgPipe()
+		defer app.Close()
+		defer net.Close()
+
+		peer := NewPeer(scroll1, p2p.NewPeer(enode.ID{}, "peer", nil), net)
+		go p2p.Send(app, test.code, test.data)
+		go func() {
+			// consume the local status
+			if msg, err := app.ReadMsg(); err == nil {
+				msg.Discard()
+			}
+		}()
+
+		if err := peer.Handshake(&StatusPacket{}); !errors.Is(err, test.want) {
+			t.Errorf("test %d: wrong error: got %v, want %v", i, err, test.want)
+		}
+		if test.want == nil {
+			if !peer.Capabilities().ServesBatchMetadata(1) || len(peer.Capabilities().Rest) != 1 {
+				t.Errorf("test %d: capabilities mismatch: have %+v", i, peer.Capabilities())
+			}
+			var field string
+			if err := rlp.DecodeBytes(peer.Capabilities().Rest[0], &field); err != nil || field != "future field" {
+				t.Errorf("test %d: additional field mismatch: have %q, err %v", i, field, err)
+			}
+		}
+	}
+}
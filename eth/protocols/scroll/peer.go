@@ -0,0 +1,114 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package scroll
+
+import (
+	"sync/atomic"
+
+	"github.com/scroll-tech/go-ethereum/log"
+	"github.com/scroll-tech/go-ethereum/p2p"
+)
+
+// Peer is a collection of relevant information we have about a `scroll` peer.
+type Peer struct {
+	id string // Unique ID for the peer, cached
+
+	*p2p.Peer                   // The embedded P2P package peer
+	rw        p2p.MsgReadWriter // Input/output streams for scroll
+	version   uint              // Protocol version negotiated
+
+	status    *StatusPacket // Rollup capabilities advertised in the handshake
+	finalized uint64        // Highest finalized L2 block advertised by the peer (atomic)
+
+	logger log.Logger // Contextual logger with the peer id injected
+}
+
+// NewPeer create a wrapper for a network connection and negotiated protocol
+// version.
+func NewPeer(version uint, p *p2p.Peer, rw p2p.MsgReadWriter) *Peer {
+	id := p.ID().String()
+	return &Peer{
+		id:      id,
+		Peer:    p,
+		rw:      rw,
+		version: version,
+		status:  new(StatusPacket),
+		logger:  log.New("peer", id[:8]),
+	}
+}
+
+// ID retrieves the peer's unique identifier.
+func (p *Peer) ID() string {
+	return p.id
+}
+
+// Version retrieves the peer's negotiated `scroll` protocol version.
+func (p *Peer) Version() uint {
+	return p.version
+}
+
+// Log overrides the P2P logger with the higher level one containing only the id.
+func (p *Peer) Log() log.Logger {
+	return p.logger
+}
+
+// Capabilities retrieves the rollup capabilities advertised by the peer in the
+// handshake. They are empty until the handshake completed.
+func (p *Peer) Capabilities() *StatusPacket {
+	return p.status
+}
+
+// FinalizedL2Block retrieves the highest L2 block finalized on L1 that the peer advertised,
+// in the handshake or in a later update.
+func (p *Peer) FinalizedL2Block() uint64 {
+	return atomic.LoadUint64(&p.finalized)
+}
+
+// setFinalizedL2Block records a finalized L2 block advertised by the peer. Older blocks are
+// ignored, so that reordered updates cannot move the finalized block back.
+func (p *Peer) setFinalizedL2Block(number uint64) {
+	for {
+		current := atomic.LoadUint64(&p.finalized)
+		if number <= current || atomic.CompareAndSwapUint64(&p.finalized, current, number) {
+			return
+		}
+	}
+}
+
+// SendFinalized announces the highest L2 block finalized on L1 known to the local node.
+func (p *Peer) SendFinalized(number uint64) error {
+	return p2p.Send(p.rw, FinalizedMsg, &FinalizedPacket{FinalizedL2Block: number})
+}
+
+// PeerInfo represents a short summary of the `scroll` sub-protocol metadata known
+// about a connected peer.
+type PeerInfo struct {
+	Version          uint    `json:"version"`          // Scroll protocol version negotiated
+	CodecVersions    []uint8 `json:"codecVersions"`    // Batch codec versions the peer can decode
+	BatchMetadata    bool    `json:"batchMetadata"`    // Whether the peer stores batch metadata
+	FinalizedL2Block uint64  `json:"finalizedL2Block"` // Highest finalized L2 block advertised by the peer
+}
+
+// Info gathers and returns some `scroll` protocol metadata known about a peer.
+func (p *Peer) Info() *PeerInfo {
+	return &PeerInfo{
+		Version:          p.version,
+		CodecVersions:    p.status.CodecVersions,
+		BatchMetadata:    p.status.BatchMetadata,
+		FinalizedL2Block: p.FinalizedL2Block(),
+	}
+}
@@ -0,0 +1,93 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package scroll
+
+import (
+	"errors"
+
+	"github.com/scroll-tech/go-ethereum/rlp"
+)
+
+// Constants to match up protocol versions and messages
+const (
+	scroll1 = 1
+)
+
+// ProtocolName is the official short name of the `scroll` protocol used during
+// devp2p capability negotiation.
+const ProtocolName = "scroll"
+
+// ProtocolVersions are the supported versions of the `scroll` protocol (first
+// is primary).
+var ProtocolVersions = []uint{scroll1}
+
+// protocolLengths are the number of implemented message corresponding to
+// different protocol versions.
+var protocolLengths = map[uint]uint64{scroll1: 2}
+
+// maxMessageSize is the maximum cap on the size of a protocol message.
+const maxMessageSize = 1024
+
+const (
+	StatusMsg    = 0x00
+	FinalizedMsg = 0x01
+)
+
+var (
+	errNoStatusMsg    = errors.New("no status message")
+	errMsgTooLarge    = errors.New("message too long")
+	errDecode         = errors.New("invalid message")
+	errExtraStatusMsg = errors.New("extra status message")
+	errInvalidMsgCode = errors.New("invalid message code")
+)
+
+// StatusPacket is the handshake of the `scroll` protocol, advertising the rollup
+// capabilities of a node. Nodes that do not run the protocol are not affected,
+// the `eth` handshake is unchanged.
+type StatusPacket struct {
+	CodecVersions    []uint8 // Batch codec versions the node can decode, ascending
+	BatchMetadata    bool    // Whether the node stores the metadata of committed and finalized batches
+	FinalizedL2Block uint64  // Highest L2 block finalized on L1 known to the node, 0 if unknown
+
+	// Ignore additional fields (for forward compatibility).
+	Rest []rlp.RawValue `rlp:"tail"`
+}
+
+// FinalizedPacket is sent after the handshake whenever the highest L2 block finalized on L1
+// known to the node advances.
+type FinalizedPacket struct {
+	FinalizedL2Block uint64
+
+	// Ignore additional fields (for forward compatibility).
+	Rest []rlp.RawValue `rlp:"tail"`
+}
+
+// SupportsCodec reports whether the node can decode batches of the given codec version.
+func (p *StatusPacket) SupportsCodec(version uint8) bool {
+	for _, v := range p.CodecVersions {
+		if v == version {
+			return true
+		}
+	}
+	return false
+}
+
+// ServesBatchMetadata reports whether the node can serve the metadata of batches
+// committed with the given codec version.
+func (p *StatusPacket) ServesBatchMetadata(codecVersion uint8) bool {
+	return p.BatchMetadata && p.SupportsCodec(codecVersion)
+}
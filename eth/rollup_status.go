@@ -0,0 +1,40 @@
+package eth
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/scroll-tech/go-ethereum/log"
+	"github.com/scroll-tech/go-ethereum/rollup/rollup_sync_service"
+)
+
+// rollupStatusPath is the path of the HTTP endpoint serving the rollup status document.
+const rollupStatusPath = "/debug/rollup"
+
+// rollupStatus is the JSON document served on rollupStatusPath.
+type rollupStatus struct {
+	Sync       *SyncStatus                 `json:"sync"`
+	RollupSync *rollup_sync_service.Status `json:"rollupSync,omitempty"`
+}
+
+// rollupStatusHandler serves the state of all rollup subsystems as a single JSON document.
+type rollupStatusHandler struct {
+	eth *Ethereum
+}
+
+func (h *rollupStatusHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	status := &rollupStatus{
+		Sync: NewScrollAPI(h.eth).SyncStatus(r.Context()),
+	}
+	if h.eth.rollupSyncService != nil {
+		status.RollupSync = h.eth.rollupSyncService.Status()
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(status); err != nil {
+		log.Debug("Failed to write rollup status", "err", err)
+	}
+}
@@ -52,6 +52,7 @@ func (eth *Ethereum) stateAtBlock(block *types.Block, reexec uint64, base *state
 		database state.Database
 		report   = true
 		origin   = block.NumberU64()
+		zktrie   = eth.blockchain.Config().Scroll.ZktrieEnabledAt(block.Number())
 	)
 	// Check the live database first if we have the state fully available, use that.
 	if checkLive {
@@ -64,7 +65,7 @@ func (eth *Ethereum) stateAtBlock(block *types.Block, reexec uint64, base *state
 		if preferDisk {
 			// Create an ephemeral trie.Database for isolating the live one. Otherwise
 			// the internal junks created by tracing will be persisted into the disk.
-			database = state.NewDatabaseWithConfig(eth.chainDb, &trie.Config{Cache: 16})
+			database = state.NewDatabaseWithConfig(eth.chainDb, &trie.Config{Cache: 16, Zktrie: zktrie})
 			if statedb, err = state.New(block.Root(), database, nil); err == nil {
 				log.Info("Found disk backend for state trie", "root", block.Root(), "number", block.Number())
 				return statedb, nil
@@ -79,7 +80,7 @@ func (eth *Ethereum) stateAtBlock(block *types.Block, reexec uint64, base *state
 
 		// Create an ephemeral trie.Database for isolating the live one. Otherwise
 		// the internal junks created by tracing will be persisted into the disk.
-		database = state.NewDatabaseWithConfig(eth.chainDb, &trie.Config{Cache: 16})
+		database = state.NewDatabaseWithConfig(eth.chainDb, &trie.Config{Cache: 16, Zktrie: zktrie})
 
 		// If we didn't check the dirty database, do check the clean one, otherwise
 		// we would rewind past a persisted block (specific corner case is chain
@@ -99,6 +100,10 @@ func (eth *Ethereum) stateAtBlock(block *types.Block, reexec uint64, base *state
 			if parent == nil {
 				return nil, fmt.Errorf("missing block %v %d", current.ParentHash(), current.NumberU64()-1)
 			}
+			// the states before the state scheme switch are in the other trie
+			if eth.blockchain.Config().Scroll.ZktrieEnabledAt(parent.Number()) != zktrie {
+				return nil, fmt.Errorf("required historical state unavailable after the state scheme switch (reexec=%d)", reexec)
+			}
 			current = parent
 
 			statedb, err = state.New(current.Root(), database, nil)
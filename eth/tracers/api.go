@@ -88,6 +88,7 @@ type Backend interface {
 type API struct {
 	backend             Backend
 	scrollTracerWrapper scrollTracerWrapper
+	dumpDir             string // directory of the block traces dumped by DumpBlockTrace
 }
 
 // NewAPI creates a new API definition for the tracing methods of the Ethereum service.
@@ -979,14 +980,17 @@ func (api *API) traceTx(ctx context.Context, message core.Message, txctx *Contex
 	}
 }
 
-// APIs return the collection of RPC services the tracer package offers.
-func APIs(backend Backend, scrollTracerWrapper scrollTracerWrapper) []rpc.API {
+// APIs return the collection of RPC services the tracer package offers. The block traces
+// dumped by debug_dumpBlockTrace are written to dumpDir.
+func APIs(backend Backend, scrollTracerWrapper scrollTracerWrapper, dumpDir string) []rpc.API {
+	debugAPI := NewAPI(backend, scrollTracerWrapper)
+	debugAPI.dumpDir = dumpDir
 	// Append all the local APIs and return
 	return []rpc.API{
 		{
 			Namespace: "debug",
 			Version:   "1.0",
-			Service:   NewAPI(backend, scrollTracerWrapper),
+			Service:   debugAPI,
 			Public:    false,
 		},
 		{
@@ -1,8 +1,15 @@
 package tracers
 
 import (
+	"bufio"
 	"context"
 	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
 
 	"github.com/scroll-tech/go-ethereum/consensus"
 	"github.com/scroll-tech/go-ethereum/core"
@@ -17,37 +24,164 @@ import (
 
 var errNoScrollTracerWrapper = errors.New("no ScrollTracerWrapper")
 
+// maxBlockTracesPerRequest is the maximum number of blocks traced by a single
+// GetBlockTracesByNumberOrHash request.
+const maxBlockTracesPerRequest = 32
+
+// maxBlockTraceDumps is the number of block traces kept in the dump directory, the oldest
+// dumps are removed by DumpBlockTrace.
+const maxBlockTraceDumps = 16
+
+// dumpLock serializes the block trace dumps, so that a dump never removes a file that is
+// still being written.
+var dumpLock sync.Mutex
+
 type TraceBlock interface {
-	GetBlockTraceByNumberOrHash(ctx context.Context, blockNrOrHash rpc.BlockNumberOrHash, config *TraceConfig) (trace *types.BlockTrace, err error)
+	GetBlockTraceByNumberOrHash(ctx context.Context, blockNrOrHash rpc.BlockNumberOrHash, config *TraceConfig) (trace *types.ProverBlockTrace, err error)
+	GetBlockTracesByNumberOrHash(ctx context.Context, blockNrOrHashes []rpc.BlockNumberOrHash, config *TraceConfig) ([]*types.ProverBlockTrace, error)
 	GetTxBlockTraceOnTopOfBlock(ctx context.Context, tx *types.Transaction, blockNrOrHash rpc.BlockNumberOrHash, config *TraceConfig) (*types.BlockTrace, error)
+	GetBlockWitness(ctx context.Context, blockNrOrHash rpc.BlockNumberOrHash, config *TraceConfig) (*types.BlockWitness, error)
 }
 
 type scrollTracerWrapper interface {
 	CreateTraceEnvAndGetBlockTrace(*params.ChainConfig, core.ChainContext, consensus.Engine, ethdb.Database, *state.StateDB, *types.Block, *types.Block, bool) (*types.BlockTrace, error)
+	CreateTraceEnvAndGetProverBlockTrace(*params.ChainConfig, core.ChainContext, consensus.Engine, ethdb.Database, *state.StateDB, *types.Block, *types.Block, bool) (*types.ProverBlockTrace, error)
+	CreateBlockWitness(*params.ChainConfig, core.ChainContext, *state.StateDB, *types.Block, *types.Block) (*types.BlockWitness, error)
 }
 
-// GetBlockTraceByNumberOrHash replays the block and returns the structured BlockTrace by hash or number.
-func (api *API) GetBlockTraceByNumberOrHash(ctx context.Context, blockNrOrHash rpc.BlockNumberOrHash, config *TraceConfig) (trace *types.BlockTrace, err error) {
+// GetBlockTraceByNumberOrHash replays the block and returns the structured BlockTrace by hash or number,
+// together with the withdraw root, the L1 message queue bounds, the row consumption and the codec hints
+// of the block.
+func (api *API) GetBlockTraceByNumberOrHash(ctx context.Context, blockNrOrHash rpc.BlockNumberOrHash, config *TraceConfig) (trace *types.ProverBlockTrace, err error) {
 	if api.scrollTracerWrapper == nil {
 		return nil, errNoScrollTracerWrapper
 	}
 
-	var block *types.Block
-	if number, ok := blockNrOrHash.Number(); ok {
-		block, err = api.blockByNumber(ctx, number)
-	} else if hash, ok := blockNrOrHash.Hash(); ok {
-		block, err = api.blockByHash(ctx, hash)
-	} else {
-		return nil, errors.New("invalid arguments; neither block number nor hash specified")
-	}
+	block, err := api.traceableBlock(ctx, blockNrOrHash)
 	if err != nil {
 		return nil, err
 	}
-	if block.NumberU64() == 0 {
-		return nil, errors.New("genesis is not traceable")
+	return api.createTraceEnvAndGetProverBlockTrace(ctx, config, block)
+}
+
+// GetBlockTracesByNumberOrHash returns the traces of several blocks like GetBlockTraceByNumberOrHash,
+// in the order of the requested blocks.
+func (api *API) GetBlockTracesByNumberOrHash(ctx context.Context, blockNrOrHashes []rpc.BlockNumberOrHash, config *TraceConfig) ([]*types.ProverBlockTrace, error) {
+	if api.scrollTracerWrapper == nil {
+		return nil, errNoScrollTracerWrapper
+	}
+	if len(blockNrOrHashes) > maxBlockTracesPerRequest {
+		return nil, fmt.Errorf("too many blocks requested: %d, at most %d blocks are traced per request", len(blockNrOrHashes), maxBlockTracesPerRequest)
 	}
 
-	return api.createTraceEnvAndGetBlockTrace(ctx, config, block)
+	// Resolve all blocks first, so that an invalid request fails before any block is traced
+	blocks := make([]*types.Block, len(blockNrOrHashes))
+	for i, blockNrOrHash := range blockNrOrHashes {
+		block, err := api.traceableBlock(ctx, blockNrOrHash)
+		if err != nil {
+			return nil, fmt.Errorf("block %v: %w", blockNrOrHash.String(), err)
+		}
+		blocks[i] = block
+	}
+	traces := make([]*types.ProverBlockTrace, len(blocks))
+	for i, block := range blocks {
+		trace, err := api.createTraceEnvAndGetProverBlockTrace(ctx, config, block)
+		if err != nil {
+			return nil, fmt.Errorf("block %d: %w", block.NumberU64(), err)
+		}
+		traces[i] = trace
+	}
+	return traces, nil
+}
+
+// DumpBlockTrace traces the block like GetBlockTraceByNumberOrHash and writes the response of
+// GetBlockTraceByNumberOrHash to a file in the block trace directory of the node, whose name is
+// returned. The trace is encoded while it is written, instead of in memory as a whole. Only the
+// newest maxBlockTraceDumps dumps are kept.
+func (api *API) DumpBlockTrace(ctx context.Context, blockNrOrHash rpc.BlockNumberOrHash, config *TraceConfig) (string, error) {
+	if api.scrollTracerWrapper == nil {
+		return "", errNoScrollTracerWrapper
+	}
+	if api.dumpDir == "" {
+		return "", errors.New("no block trace directory")
+	}
+
+	block, err := api.traceableBlock(ctx, blockNrOrHash)
+	if err != nil {
+		return "", err
+	}
+	trace, err := api.createTraceEnvAndGetProverBlockTrace(ctx, config, block)
+	if err != nil {
+		return "", err
+	}
+
+	dumpLock.Lock()
+	defer dumpLock.Unlock()
+
+	if err := os.MkdirAll(api.dumpDir, 0755); err != nil {
+		return "", err
+	}
+	name := filepath.Join(api.dumpDir, fmt.Sprintf("blocktrace_%d-%#x.json", block.NumberU64(), block.Hash().Bytes()[:4]))
+	// Write to a temporary file first, so that an interrupted dump never leaves a partial trace
+	dump, err := ioutil.TempFile(api.dumpDir, "blocktrace_*.tmp")
+	if err != nil {
+		return "", err
+	}
+	writer := bufio.NewWriter(dump)
+	err = types.WriteProverBlockTraceJSON(writer, trace)
+	if err == nil {
+		err = writer.Flush()
+	}
+	if closeErr := dump.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(dump.Name(), name)
+	}
+	if err != nil {
+		os.Remove(dump.Name())
+		return "", fmt.Errorf("failed to dump block trace: %w", err)
+	}
+	if err := pruneBlockTraceDumps(api.dumpDir, maxBlockTraceDumps); err != nil {
+		log.Warn("Failed to prune block trace dumps", "dir", api.dumpDir, "err", err)
+	}
+	return name, nil
+}
+
+// pruneBlockTraceDumps removes the temporary files left by interrupted dumps and all but the
+// newest keep block trace dumps in dir.
+func pruneBlockTraceDumps(dir string, keep int) error {
+	tmps, err := filepath.Glob(filepath.Join(dir, "blocktrace_*.tmp"))
+	if err != nil {
+		return err
+	}
+	for _, tmp := range tmps {
+		if err := os.Remove(tmp); err != nil {
+			return err
+		}
+	}
+	names, err := filepath.Glob(filepath.Join(dir, "blocktrace_*.json"))
+	if err != nil {
+		return err
+	}
+	dumps := make([]os.FileInfo, 0, len(names))
+	for _, name := range names {
+		info, err := os.Stat(name)
+		if err != nil {
+			return err
+		}
+		dumps = append(dumps, info)
+	}
+	if len(dumps) <= keep {
+		return nil
+	}
+	sort.Slice(dumps, func(i, j int) bool { return dumps[i].ModTime().After(dumps[j].ModTime()) })
+	for _, info := range dumps[keep:] {
+		if err := os.Remove(filepath.Join(dir, info.Name())); err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
 func (api *API) GetTxBlockTraceOnTopOfBlock(ctx context.Context, tx *types.Transaction, blockNrOrHash rpc.BlockNumberOrHash, config *TraceConfig) (*types.BlockTrace, error) {
@@ -56,6 +190,37 @@ func (api *API) GetTxBlockTraceOnTopOfBlock(ctx context.Context, tx *types.Trans
 	}
 
 	// Try to retrieve the specified block
+	block, err := api.traceableBlock(ctx, blockNrOrHash)
+	if err != nil {
+		return nil, err
+	}
+
+	block = types.NewBlockWithHeader(block.Header()).WithBody([]*types.Transaction{tx}, nil)
+
+	return api.createTraceEnvAndGetBlockTrace(ctx, config, block)
+}
+
+// GetBlockWitness replays the block and returns its stateless witness: the proofs of the accounts and
+// storage slots it accesses against the state root of its parent, the code it loads and the block
+// hashes it looks up, which are sufficient to re-execute the block without a state database.
+func (api *API) GetBlockWitness(ctx context.Context, blockNrOrHash rpc.BlockNumberOrHash, config *TraceConfig) (*types.BlockWitness, error) {
+	if api.scrollTracerWrapper == nil {
+		return nil, errNoScrollTracerWrapper
+	}
+
+	block, err := api.traceableBlock(ctx, blockNrOrHash)
+	if err != nil {
+		return nil, err
+	}
+	parent, statedb, err := api.traceEnvState(ctx, config, block)
+	if err != nil {
+		return nil, err
+	}
+	return api.scrollTracerWrapper.CreateBlockWitness(api.backend.ChainConfig(), api.chainContext(ctx), statedb, parent, block)
+}
+
+// traceableBlock retrieves the block specified by hash or number, the genesis block is not traceable.
+func (api *API) traceableBlock(ctx context.Context, blockNrOrHash rpc.BlockNumberOrHash) (*types.Block, error) {
 	var (
 		err   error
 		block *types.Block
@@ -73,14 +238,29 @@ func (api *API) GetTxBlockTraceOnTopOfBlock(ctx context.Context, tx *types.Trans
 	if block.NumberU64() == 0 {
 		return nil, errors.New("genesis is not traceable")
 	}
-
-	block = types.NewBlockWithHeader(block.Header()).WithBody([]*types.Transaction{tx}, nil)
-
-	return api.createTraceEnvAndGetBlockTrace(ctx, config, block)
+	return block, nil
 }
 
 // Make trace environment for current block, and then get the trace for the block.
 func (api *API) createTraceEnvAndGetBlockTrace(ctx context.Context, config *TraceConfig, block *types.Block) (*types.BlockTrace, error) {
+	parent, statedb, err := api.traceEnvState(ctx, config, block)
+	if err != nil {
+		return nil, err
+	}
+	return api.scrollTracerWrapper.CreateTraceEnvAndGetBlockTrace(api.backend.ChainConfig(), api.chainContext(ctx), api.backend.Engine(), api.backend.ChainDb(), statedb, parent, block, true)
+}
+
+// Make trace environment for current block, and then get the trace and the prover inputs for the block.
+func (api *API) createTraceEnvAndGetProverBlockTrace(ctx context.Context, config *TraceConfig, block *types.Block) (*types.ProverBlockTrace, error) {
+	parent, statedb, err := api.traceEnvState(ctx, config, block)
+	if err != nil {
+		return nil, err
+	}
+	return api.scrollTracerWrapper.CreateTraceEnvAndGetProverBlockTrace(api.backend.ChainConfig(), api.chainContext(ctx), api.backend.Engine(), api.backend.ChainDb(), statedb, parent, block, true)
+}
+
+// traceEnvState returns the parent of a block and the state the block is traced on.
+func (api *API) traceEnvState(ctx context.Context, config *TraceConfig, block *types.Block) (*types.Block, *state.StateDB, error) {
 	if config == nil {
 		config = &TraceConfig{
 			LogConfig: &vm.LogConfig{
@@ -95,7 +275,7 @@ func (api *API) createTraceEnvAndGetBlockTrace(ctx context.Context, config *Trac
 
 	parent, err := api.blockByNumberAndHash(ctx, rpc.BlockNumber(block.NumberU64()-1), block.ParentHash())
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 	reexec := defaultTraceReexec
 	if config != nil && config.Reexec != nil {
@@ -103,9 +283,7 @@ func (api *API) createTraceEnvAndGetBlockTrace(ctx context.Context, config *Trac
 	}
 	statedb, err := api.backend.StateAtBlock(ctx, parent, reexec, nil, true, true)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
-
-	chaindb := api.backend.ChainDb()
-	return api.scrollTracerWrapper.CreateTraceEnvAndGetBlockTrace(api.backend.ChainConfig(), api.chainContext(ctx), api.backend.Engine(), chaindb, statedb, parent, block, true)
+	return parent, statedb, nil
 }
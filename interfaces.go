@@ -103,6 +103,19 @@ type SyncProgress struct {
 	HighestBlock  uint64 // Highest alleged block number in the chain
 	PulledStates  uint64 // Number of state trie entries already downloaded
 	KnownStates   uint64 // Total number of state trie entries known about
+
+	Rollup *RollupSyncProgress // Progress of the rollup verifier, nil if it is not enabled
+}
+
+// RollupSyncProgress gives progress indications of the rollup verifier syncing the
+// committed and finalized batches from L1.
+type RollupSyncProgress struct {
+	L1ProcessedBlock     uint64  // L1 block up to which rollup events are processed
+	L1BlockLag           uint64  // Number of confirmed L1 blocks whose rollup events are not processed yet
+	LatestCommittedBatch *uint64 // Index of the latest committed batch, nil if none
+	LatestFinalizedBatch *uint64 // Index of the latest finalized batch, nil if none
+	FinalizedL2Block     *uint64 // Number of the latest finalized L2 block, nil if none
+	FinalizedL2BlockLag  uint64  // Number of local L2 blocks that are not finalized yet
 }
 
 // ChainSyncReader wraps access to the node's current sync status. If there's no
@@ -88,9 +88,20 @@ type feeHistoryResult struct {
 	Reward       [][]*hexutil.Big `json:"reward,omitempty"`
 	BaseFee      []*hexutil.Big   `json:"baseFeePerGas,omitempty"`
 	GasUsedRatio []float64        `json:"gasUsedRatio"`
+
+	// L1 fee components stored in the L1GasPriceOracle after each block, only set if requested.
+	// The entries of blocks whose state is not available are null.
+	L1BaseFee      []*hexutil.Big `json:"l1BaseFeePerGas,omitempty"`
+	L1FeeOverhead  []*hexutil.Big `json:"l1FeeOverhead,omitempty"`
+	L1FeeScalar    []*hexutil.Big `json:"l1FeeScalar,omitempty"`
+	L1BlobBaseFee  []*hexutil.Big `json:"l1BlobBaseFeePerGas,omitempty"`
+	L1CommitScalar []*hexutil.Big `json:"l1CommitScalar,omitempty"`
+	L1BlobScalar   []*hexutil.Big `json:"l1BlobScalar,omitempty"`
 }
 
-func (s *PublicEthereumAPI) FeeHistory(ctx context.Context, blockCount rpc.DecimalOrHex, lastBlock rpc.BlockNumber, rewardPercentiles []float64) (*feeHistoryResult, error) {
+// FeeHistory returns the fee market history of the given range of blocks. If includeL1Fees is
+// set, the L1 fee components of every block are included as well.
+func (s *PublicEthereumAPI) FeeHistory(ctx context.Context, blockCount rpc.DecimalOrHex, lastBlock rpc.BlockNumber, rewardPercentiles []float64, includeL1Fees *bool) (*feeHistoryResult, error) {
 	oldest, reward, baseFee, gasUsed, err := s.b.FeeHistory(ctx, int(blockCount), lastBlock, rewardPercentiles)
 	if err != nil {
 		return nil, err
@@ -114,6 +125,24 @@ func (s *PublicEthereumAPI) FeeHistory(ctx context.Context, blockCount rpc.Decim
 			results.BaseFee[i] = (*hexutil.Big)(v)
 		}
 	}
+	if includeL1Fees != nil && *includeL1Fees && len(gasUsed) > 0 {
+		l1Fees, err := s.b.L1FeeHistory(ctx, oldest.Uint64(), len(gasUsed))
+		if err != nil {
+			return nil, err
+		}
+		results.L1BaseFee = make([]*hexutil.Big, len(l1Fees))
+		results.L1FeeOverhead = make([]*hexutil.Big, len(l1Fees))
+		results.L1FeeScalar = make([]*hexutil.Big, len(l1Fees))
+		results.L1BlobBaseFee = make([]*hexutil.Big, len(l1Fees))
+		results.L1CommitScalar = make([]*hexutil.Big, len(l1Fees))
+		results.L1BlobScalar = make([]*hexutil.Big, len(l1Fees))
+		for i, v := range l1Fees {
+			if v != nil {
+				results.L1BaseFee[i], results.L1FeeOverhead[i], results.L1FeeScalar[i] = (*hexutil.Big)(v.L1BaseFee), (*hexutil.Big)(v.Overhead), (*hexutil.Big)(v.Scalar)
+				results.L1BlobBaseFee[i], results.L1CommitScalar[i], results.L1BlobScalar[i] = (*hexutil.Big)(v.L1BlobBaseFee), (*hexutil.Big)(v.CommitScalar), (*hexutil.Big)(v.BlobScalar)
+			}
+		}
+	}
 	return results, nil
 }
 
@@ -124,6 +153,7 @@ func (s *PublicEthereumAPI) FeeHistory(ctx context.Context, blockCount rpc.Decim
 // - highestBlock:  block number of the highest block header this node has received from peers
 // - pulledStates:  number of state entries processed until now
 // - knownStates:   number of known state entries that still need to be pulled
+// - rollup:        progress of the rollup verifier, if it is enabled
 func (s *PublicEthereumAPI) Syncing() (interface{}, error) {
 	progress := s.b.SyncProgress()
 
@@ -132,13 +162,34 @@ func (s *PublicEthereumAPI) Syncing() (interface{}, error) {
 		return false, nil
 	}
 	// Otherwise gather the block sync stats
-	return map[string]interface{}{
+	fields := map[string]interface{}{
 		"startingBlock": hexutil.Uint64(progress.StartingBlock),
 		"currentBlock":  hexutil.Uint64(progress.CurrentBlock),
 		"highestBlock":  hexutil.Uint64(progress.HighestBlock),
 		"pulledStates":  hexutil.Uint64(progress.PulledStates),
 		"knownStates":   hexutil.Uint64(progress.KnownStates),
-	}, nil
+	}
+	if rollup := progress.Rollup; rollup != nil {
+		fields["rollup"] = &rpcRollupSyncProgress{
+			L1ProcessedBlock:     hexutil.Uint64(rollup.L1ProcessedBlock),
+			L1BlockLag:           hexutil.Uint64(rollup.L1BlockLag),
+			LatestCommittedBatch: (*hexutil.Uint64)(rollup.LatestCommittedBatch),
+			LatestFinalizedBatch: (*hexutil.Uint64)(rollup.LatestFinalizedBatch),
+			FinalizedL2Block:     (*hexutil.Uint64)(rollup.FinalizedL2Block),
+			FinalizedL2BlockLag:  hexutil.Uint64(rollup.FinalizedL2BlockLag),
+		}
+	}
+	return fields, nil
+}
+
+// rpcRollupSyncProgress is the RPC representation of ethereum.RollupSyncProgress.
+type rpcRollupSyncProgress struct {
+	L1ProcessedBlock     hexutil.Uint64  `json:"l1ProcessedBlock"`
+	L1BlockLag           hexutil.Uint64  `json:"l1BlockLag"`
+	LatestCommittedBatch *hexutil.Uint64 `json:"latestCommittedBatch"`
+	LatestFinalizedBatch *hexutil.Uint64 `json:"latestFinalizedBatch"`
+	FinalizedL2Block     *hexutil.Uint64 `json:"finalizedL2Block"`
+	FinalizedL2BlockLag  hexutil.Uint64  `json:"finalizedL2BlockLag"`
 }
 
 // PublicTxPoolAPI offers and API for the transaction pool. It only operates on data that is non confidential.
@@ -656,12 +707,12 @@ type StorageResult struct {
 
 // GetProof returns the Merkle-proof for a given account and optionally some storage keys.
 func (s *PublicBlockChainAPI) GetProof(ctx context.Context, address common.Address, storageKeys []string, blockNrOrHash rpc.BlockNumberOrHash) (*AccountResult, error) {
-	state, _, err := s.b.StateAndHeaderByNumberOrHash(ctx, blockNrOrHash)
+	state, header, err := s.b.StateAndHeaderByNumberOrHash(ctx, blockNrOrHash)
 	if state == nil || err != nil {
 		return nil, err
 	}
 
-	zktrie := s.b.ChainConfig().Scroll.ZktrieEnabled()
+	zktrie := s.b.ChainConfig().Scroll.ZktrieEnabledAt(header.Number)
 
 	storageTrie := state.StorageTrie(address)
 	var storageHash common.Hash
@@ -761,11 +812,17 @@ func (s *PublicBlockChainAPI) GetBlockByNumber(ctx context.Context, number rpc.B
 }
 
 // GetBlockByHash returns the requested block. When fullTx is true all transactions in the block are returned in full
-// detail, otherwise only the transaction hash is returned.
+// detail, otherwise only the transaction hash is returned. The canonical and finalized fields report whether the
+// block is part of the local canonical chain and whether it is finalized.
 func (s *PublicBlockChainAPI) GetBlockByHash(ctx context.Context, hash common.Hash, fullTx bool) (map[string]interface{}, error) {
 	block, err := s.b.BlockByHash(ctx, hash)
 	if block != nil {
-		return s.rpcMarshalBlock(ctx, block, true, fullTx)
+		fields, err := s.rpcMarshalBlock(ctx, block, true, fullTx)
+		if err != nil {
+			return nil, err
+		}
+		RPCMarshalChainMembership(ctx, s.b, block.Header(), fields)
+		return fields, nil
 	}
 	return nil, err
 }
@@ -895,8 +952,19 @@ func (diff *StateOverride) Apply(state *state.StateDB) error {
 }
 
 func EstimateL1MsgFee(ctx context.Context, b Backend, args TransactionArgs, blockNrOrHash rpc.BlockNumberOrHash, overrides *StateOverride, timeout time.Duration, globalGasCap uint64, config *params.ChainConfig) (*big.Int, error) {
+	fee, err := EstimateL1Fee(ctx, b, args, blockNrOrHash, overrides, timeout, globalGasCap, config)
+	if err != nil {
+		return nil, err
+	}
+	return fee.L1DataFee, nil
+}
+
+// EstimateL1Fee estimates the L1 data fee of the transaction described by args, along with
+// the L1 gas used and the L1GasPriceOracle values of the given block it is computed from.
+// Only the zero L1DataFee is set if the fee vault is disabled.
+func EstimateL1Fee(ctx context.Context, b Backend, args TransactionArgs, blockNrOrHash rpc.BlockNumberOrHash, overrides *StateOverride, timeout time.Duration, globalGasCap uint64, config *params.ChainConfig) (*fees.L1Fee, error) {
 	if !config.Scroll.FeeVaultEnabled() {
-		return big.NewInt(0), nil
+		return &fees.L1Fee{L1DataFee: big.NewInt(0)}, nil
 	}
 
 	state, header, err := b.StateAndHeaderByNumberOrHash(ctx, blockNrOrHash)
@@ -935,7 +1003,7 @@ func EstimateL1MsgFee(ctx context.Context, b Backend, args TransactionArgs, bloc
 	}()
 
 	signer := types.MakeSigner(config, header.Number)
-	return fees.EstimateL1DataFeeForMessage(msg, header.BaseFee, config.ChainID, signer, evm.StateDB)
+	return fees.EstimateL1FeeForMessage(msg, header.BaseFee, config.ChainID, signer, evm.StateDB)
 }
 
 func DoCall(ctx context.Context, b Backend, args TransactionArgs, blockNrOrHash rpc.BlockNumberOrHash, overrides *StateOverride, timeout time.Duration, globalGasCap uint64) (*core.ExecutionResult, error) {
@@ -1271,6 +1339,24 @@ func (s *PublicBlockChainAPI) rpcMarshalBlock(ctx context.Context, b *types.Bloc
 	return fields, err
 }
 
+// RPCMarshalChainMembership adds whether the given header is part of the local canonical chain and
+// whether it is finalized to the RPC output of a block. Blocks queried by hash may be stale, e.g. on
+// a follower that diverged from its writer node, and clients use these fields to detect them.
+func RPCMarshalChainMembership(ctx context.Context, b Backend, head *types.Header, fields map[string]interface{}) {
+	canonical := false
+	if header, _ := b.HeaderByNumber(ctx, rpc.BlockNumber(head.Number.Int64())); header != nil {
+		canonical = header.Hash() == head.Hash()
+	}
+	finalized := false
+	if canonical {
+		if header, _ := b.HeaderByNumber(ctx, rpc.FinalizedBlockNumber); header != nil {
+			finalized = head.Number.Cmp(header.Number) <= 0
+		}
+	}
+	fields["canonical"] = canonical
+	fields["finalized"] = finalized
+}
+
 // RPCTransaction represents a transaction that will serialize to the RPC representation of a transaction
 type RPCTransaction struct {
 	BlockHash        *common.Hash      `json:"blockHash"`
@@ -1631,17 +1717,49 @@ func (s *PublicTransactionPoolAPI) GetTransactionReceipt(ctx context.Context, ha
 	if len(receipts) <= int(index) {
 		return nil, nil
 	}
-	receipt := receipts[index]
+	var header *types.Header
+	if s.b.ChainConfig().IsLondon(new(big.Int).SetUint64(blockNumber)) {
+		if header, err = s.b.HeaderByHash(ctx, blockHash); err != nil {
+			return nil, err
+		}
+	}
+	return marshalReceipt(s.b.ChainConfig(), receipts[index], blockHash, blockNumber, header, tx, index), nil
+}
+
+// GetBlockReceipts returns the receipts of all transactions in the given block,
+// or nil if the block does not exist.
+func (s *PublicTransactionPoolAPI) GetBlockReceipts(ctx context.Context, blockNrOrHash rpc.BlockNumberOrHash) ([]map[string]interface{}, error) {
+	block, err := s.b.BlockByNumberOrHash(ctx, blockNrOrHash)
+	if block == nil || err != nil {
+		return nil, err
+	}
+	receipts, err := s.b.GetReceipts(ctx, block.Hash())
+	if err != nil {
+		return nil, err
+	}
+	txs := block.Transactions()
+	if len(txs) != len(receipts) {
+		return nil, fmt.Errorf("receipts length mismatch: %d vs %d", len(txs), len(receipts))
+	}
+	result := make([]map[string]interface{}, len(receipts))
+	for i, receipt := range receipts {
+		result[i] = marshalReceipt(s.b.ChainConfig(), receipt, block.Hash(), block.NumberU64(), block.Header(), txs[i], uint64(i))
+	}
+	return result, nil
+}
 
+// marshalReceipt converts the receipt of tx into the RPC representation. The header of the
+// block is only required after London, to compute the effective gas price.
+func marshalReceipt(config *params.ChainConfig, receipt *types.Receipt, blockHash common.Hash, blockNumber uint64, header *types.Header, tx *types.Transaction, index uint64) map[string]interface{} {
 	// Derive the sender.
 	bigblock := new(big.Int).SetUint64(blockNumber)
-	signer := types.MakeSigner(s.b.ChainConfig(), bigblock)
+	signer := types.MakeSigner(config, bigblock)
 	from, _ := types.Sender(signer, tx)
 
 	fields := map[string]interface{}{
 		"blockHash":         blockHash,
 		"blockNumber":       hexutil.Uint64(blockNumber),
-		"transactionHash":   hash,
+		"transactionHash":   tx.Hash(),
 		"transactionIndex":  hexutil.Uint64(index),
 		"from":              from,
 		"to":                tx.To(),
@@ -1652,16 +1770,15 @@ func (s *PublicTransactionPoolAPI) GetTransactionReceipt(ctx context.Context, ha
 		"logsBloom":         receipt.Bloom,
 		"type":              hexutil.Uint(tx.Type()),
 		"l1Fee":             (*hexutil.Big)(receipt.L1Fee),
+		"l1GasUsed":         (*hexutil.Big)(receipt.L1GasUsed),
+		"l1BaseFee":         (*hexutil.Big)(receipt.L1BaseFee),
+		"l1FeeOverhead":     (*hexutil.Big)(receipt.L1FeeOverhead),
+		"l1FeeScalar":       (*hexutil.Big)(receipt.L1FeeScalar),
 	}
 	// Assign the effective gas price paid
-	if !s.b.ChainConfig().IsLondon(bigblock) {
+	if !config.IsLondon(bigblock) {
 		fields["effectiveGasPrice"] = hexutil.Uint64(tx.GasPrice().Uint64())
 	} else {
-		header, err := s.b.HeaderByHash(ctx, blockHash)
-		if err != nil {
-			return nil, err
-		}
-
 		baseFee := header.BaseFee
 		if baseFee == nil {
 			baseFee = big.NewInt(0)
@@ -1683,7 +1800,7 @@ func (s *PublicTransactionPoolAPI) GetTransactionReceipt(ctx context.Context, ha
 	if receipt.ContractAddress != (common.Address{}) {
 		fields["contractAddress"] = receipt.ContractAddress
 	}
-	return fields, nil
+	return fields
 }
 
 // sign is a helper function that signs a transaction with the private key of the given address.
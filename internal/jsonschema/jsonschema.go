@@ -0,0 +1,197 @@
+// Package jsonschema derives JSON schemas of Go types as they are encoded by encoding/json,
+// including the hex encodings of the common and hexutil types.
+package jsonschema
+
+import (
+	"encoding"
+	"encoding/json"
+	"math/big"
+	"reflect"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/scroll-tech/go-ethereum/common"
+	"github.com/scroll-tech/go-ethereum/common/hexutil"
+)
+
+// Draft is the JSON schema dialect of the generated schemas.
+const Draft = "https://json-schema.org/draft/2020-12/schema"
+
+// Schema is a JSON schema, limited to the keywords used for Go types.
+type Schema struct {
+	Ref                  string             `json:"$ref,omitempty"`
+	Description          string             `json:"description,omitempty"`
+	Type                 string             `json:"type,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	Pattern              string             `json:"pattern,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	AdditionalProperties *Schema            `json:"additionalProperties,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	AnyOf                []*Schema          `json:"anyOf,omitempty"`
+}
+
+var (
+	hexQuantity = &Schema{Type: "string", Pattern: "^0x(0|[1-9a-f][0-9a-f]*)$", Description: "hex encoded quantity"}
+	hexBytes    = &Schema{Type: "string", Pattern: "^0x([0-9a-f]{2})*$", Description: "hex encoded bytes"}
+
+	// knownTypes are the types with custom JSON encodings.
+	knownTypes = map[reflect.Type]*Schema{
+		reflect.TypeOf(common.Hash{}):     {Type: "string", Pattern: "^0x[0-9a-f]{64}$", Description: "32 byte hash"},
+		reflect.TypeOf(common.Address{}):  {Type: "string", Pattern: "^0x[0-9a-fA-F]{40}$", Description: "20 byte address"},
+		reflect.TypeOf(hexutil.Bytes{}):   hexBytes,
+		reflect.TypeOf(hexutil.Big{}):     hexQuantity,
+		reflect.TypeOf(hexutil.Uint64(0)): hexQuantity,
+		reflect.TypeOf(hexutil.Uint(0)):   hexQuantity,
+		reflect.TypeOf(big.Int{}):         {Type: "integer", Description: "arbitrary precision integer"},
+		reflect.TypeOf(time.Time{}):       {Type: "string", Format: "date-time"},
+		reflect.TypeOf(json.RawMessage{}): {},
+	}
+
+	textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+	jsonMarshalerType = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+)
+
+// Generator builds the schemas of Go types. Named struct types are defined once and
+// referenced from all schemas of the generator, see Definitions.
+type Generator struct {
+	defs  map[string]*Schema
+	names map[reflect.Type]string
+}
+
+// NewGenerator creates a generator without definitions.
+func NewGenerator() *Generator {
+	return &Generator{defs: make(map[string]*Schema), names: make(map[reflect.Type]string)}
+}
+
+// Definitions returns the schemas of the named struct types, referenced as #/$defs/<name>.
+func (g *Generator) Definitions() map[string]*Schema {
+	return g.defs
+}
+
+// Schema returns the schema of values of type t.
+func (g *Generator) Schema(t reflect.Type) *Schema {
+	if schema, ok := knownTypes[t]; ok {
+		return schema
+	}
+	switch t.Kind() {
+	case reflect.Ptr:
+		return nullable(g.Schema(t.Elem()))
+	case reflect.Interface:
+		return &Schema{}
+	}
+	if t.Implements(jsonMarshalerType) || reflect.PtrTo(t).Implements(jsonMarshalerType) {
+		// note: the encoding of other marshalers is unknown
+		return &Schema{Description: "custom encoding of " + t.String()}
+	}
+	if t.Implements(textMarshalerType) || reflect.PtrTo(t).Implements(textMarshalerType) {
+		return &Schema{Type: "string"}
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return &Schema{Type: "integer"}
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: "number"}
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Slice:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return nullable(&Schema{Type: "string", Format: "byte", Description: "base64 encoded bytes"})
+		}
+		return nullable(&Schema{Type: "array", Items: g.Schema(t.Elem())})
+	case reflect.Array:
+		return &Schema{Type: "array", Items: g.Schema(t.Elem())}
+	case reflect.Map:
+		return nullable(&Schema{Type: "object", AdditionalProperties: g.Schema(t.Elem())})
+	case reflect.Struct:
+		if t.Name() == "" {
+			return g.structSchema(t)
+		}
+		return &Schema{Ref: "#/$defs/" + g.define(t)}
+	default:
+		return &Schema{Description: "unsupported type " + t.String()}
+	}
+}
+
+// define adds the definition of a named struct type and returns its name.
+func (g *Generator) define(t reflect.Type) string {
+	if name, ok := g.names[t]; ok {
+		return name
+	}
+	name := definitionName(t)
+	if _, taken := g.defs[name]; taken {
+		parts := strings.Split(t.PkgPath(), "/")
+		name = exportedName(parts[len(parts)-1]) + name
+	}
+	g.names[t] = name
+	g.defs[name] = nil // reserved for recursive types
+	g.defs[name] = g.structSchema(t)
+	return name
+}
+
+// structSchema returns the schema of the fields of a struct, as selected by encoding/json.
+func (g *Generator) structSchema(t reflect.Type) *Schema {
+	schema := &Schema{Type: "object", Properties: make(map[string]*Schema)}
+	g.addFields(schema, t)
+	return schema
+}
+
+func (g *Generator) addFields(schema *Schema, t reflect.Type) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, opts, _ := strings.Cut(tag, ",")
+		if field.Anonymous && name == "" {
+			ft := field.Type
+			if ft.Kind() == reflect.Ptr {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct {
+				g.addFields(schema, ft)
+				continue
+			}
+		}
+		if !field.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		fieldSchema := g.Schema(field.Type)
+		if strings.Contains(opts, "string") {
+			fieldSchema = &Schema{Type: "string", Description: "JSON encoded value in a string"}
+		}
+		schema.Properties[name] = fieldSchema
+		if !strings.Contains(opts, "omitempty") {
+			schema.Required = append(schema.Required, name)
+		}
+	}
+}
+
+// nullable allows null in place of values of the schema.
+func nullable(schema *Schema) *Schema {
+	return &Schema{AnyOf: []*Schema{schema, {Type: "null"}}}
+}
+
+// definitionName returns the exported name of a named type, without the rpc prefix of
+// the RPC representations.
+func definitionName(t reflect.Type) string {
+	return exportedName(strings.TrimPrefix(t.Name(), "rpc"))
+}
+
+func exportedName(name string) string {
+	if name == "" {
+		return name
+	}
+	runes := []rune(name)
+	runes[0] = unicode.ToUpper(runes[0])
+	return strings.ReplaceAll(string(runes), "_", "")
+}
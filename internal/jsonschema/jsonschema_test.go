@@ -0,0 +1,81 @@
+package jsonschema
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/scroll-tech/go-ethereum/common"
+	"github.com/scroll-tech/go-ethereum/common/hexutil"
+)
+
+type testEmbedded struct {
+	Embedded string `json:"embedded"`
+}
+
+type testNode struct {
+	testEmbedded
+	Hash     common.Hash     `json:"hash"`
+	Number   hexutil.Uint64  `json:"number"`
+	Index    uint64          `json:"index"`
+	Optional *common.Address `json:"optional,omitempty"`
+	Children []*testNode     `json:"children"`
+	Labels   map[string]bool `json:"labels"`
+	Quoted   uint64          `json:"quoted,string"`
+	Ignored  int             `json:"-"`
+	Untagged string
+	private  int
+}
+
+func TestGenerator(t *testing.T) {
+	g := NewGenerator()
+	schema := g.Schema(reflect.TypeOf(&testNode{}))
+	if len(schema.AnyOf) != 2 || schema.AnyOf[0].Ref != "#/$defs/TestNode" || schema.AnyOf[1].Type != "null" {
+		t.Fatalf("unexpected schema: %+v", schema)
+	}
+	node := g.Definitions()["TestNode"]
+	if node == nil {
+		t.Fatal("TestNode is not defined")
+	}
+
+	var names []string
+	for name := range node.Properties {
+		names = append(names, name)
+	}
+	want := map[string]string{
+		"embedded": "string",
+		"hash":     "string",
+		"number":   "string",
+		"index":    "integer",
+		"optional": "",
+		"children": "",
+		"labels":   "",
+		"quoted":   "string",
+		"Untagged": "string",
+	}
+	if len(node.Properties) != len(want) {
+		t.Fatalf("unexpected properties %v", names)
+	}
+	for name, typ := range want {
+		property, ok := node.Properties[name]
+		if !ok {
+			t.Fatalf("missing property %q", name)
+		}
+		if property.Type != typ {
+			t.Errorf("property %q: type %q, want %q", name, property.Type, typ)
+		}
+	}
+	if node.Properties["number"].Pattern != hexQuantity.Pattern {
+		t.Errorf("unexpected schema of hex quantity: %+v", node.Properties["number"])
+	}
+	if items := node.Properties["children"].AnyOf[0].Items; items.AnyOf[0].Ref != "#/$defs/TestNode" {
+		t.Errorf("unexpected schema of recursive field: %+v", items)
+	}
+	for _, name := range node.Required {
+		if name == "optional" {
+			t.Errorf("omitempty property is required")
+		}
+	}
+	if len(node.Required) != len(want)-1 {
+		t.Errorf("unexpected required properties %v", node.Required)
+	}
+}
@@ -153,6 +153,35 @@ web3._extend({
 			call: 'admin_importChain',
 			params: 1
 		}),
+		new web3._extend.Method({
+			name: 'rollupSyncPause',
+			call: 'admin_rollupSyncPause',
+		}),
+		new web3._extend.Method({
+			name: 'rollupSyncResume',
+			call: 'admin_rollupSyncResume',
+		}),
+		new web3._extend.Method({
+			name: 'rollupSyncResetTo',
+			call: 'admin_rollupSyncResetTo',
+			params: 1
+		}),
+		new web3._extend.Method({
+			name: 'rollupSyncClearPoisonedBatch',
+			call: 'admin_rollupSyncClearPoisonedBatch',
+			params: 1
+		}),
+		new web3._extend.Method({
+			name: 'rollupSyncRetryPoisonedBatch',
+			call: 'admin_rollupSyncRetryPoisonedBatch',
+			params: 1
+		}),
+		new web3._extend.Method({
+			name: 'setL1Mode',
+			call: 'admin_setL1Mode',
+			params: 2,
+			inputFormatter: [null, null]
+		}),
 		new web3._extend.Method({
 			name: 'sleepBlocks',
 			call: 'admin_sleepBlocks',
@@ -204,6 +233,14 @@ web3._extend({
 			name: 'datadir',
 			getter: 'admin_datadir'
 		}),
+		new web3._extend.Property({
+			name: 'rollupSyncStatus',
+			getter: 'admin_rollupSyncStatus'
+		}),
+		new web3._extend.Property({
+			name: 'l1Mode',
+			getter: 'admin_l1Mode'
+		}),
 	]
 });
 `
@@ -409,6 +446,12 @@ web3._extend({
 			params: 2,
 			inputFormatter: [null, null]
 		}),
+		new web3._extend.Method({
+			name: 'dumpBlockTrace',
+			call: 'debug_dumpBlockTrace',
+			params: 2,
+			inputFormatter: [null, null]
+		}),
 		new web3._extend.Method({
 			name: 'traceBlockByNumber',
 			call: 'debug_traceBlockByNumber',
@@ -455,6 +498,11 @@ web3._extend({
 			params: 2,
 			inputFormatter: [null, null],
 		}),
+		new web3._extend.Method({
+			name: 'findDivergence',
+			call: 'debug_findDivergence',
+			params: 1,
+		}),
 		new web3._extend.Method({
 			name: 'getModifiedAccountsByHash',
 			call: 'debug_getModifiedAccountsByHash',
@@ -534,6 +582,12 @@ web3._extend({
 			call: 'eth_getHeaderByHash',
 			params: 1
 		}),
+		new web3._extend.Method({
+			name: 'getBlockReceipts',
+			call: 'eth_getBlockReceipts',
+			params: 1,
+			inputFormatter: [web3._extend.formatters.inputBlockNumberFormatter]
+		}),
 		new web3._extend.Method({
 			name: 'getBlockByNumber',
 			call: 'eth_getBlockByNumber',
@@ -865,6 +919,17 @@ web3._extend({
 			call: 'scroll_getBlockTraceByNumberOrHash',
 			params: 1
 		}),
+		new web3._extend.Method({
+			name: 'getBlockTracesByNumberOrHash',
+			call: 'scroll_getBlockTracesByNumberOrHash',
+			params: 1
+		}),
+		new web3._extend.Method({
+			name: 'getBlockWitness',
+			call: 'scroll_getBlockWitness',
+			params: 1,
+			inputFormatter: [web3._extend.formatters.inputBlockNumberFormatter]
+		}),
 		new web3._extend.Method({
 			name: 'getTxBlockTraceOnTopOfBlock',
 			call: 'scroll_getTxBlockTraceOnTopOfBlock',
@@ -876,6 +941,32 @@ web3._extend({
 			call: 'scroll_getL1MessageByIndex',
 			params: 1
 		}),
+		new web3._extend.Method({
+			name: 'getL1MessagesInRange',
+			call: 'scroll_getL1MessagesInRange',
+			params: 3,
+			inputFormatter: [null, null, null]
+		}),
+		new web3._extend.Method({
+			name: 'getL1MessageInclusion',
+			call: 'scroll_getL1MessageInclusion',
+			params: 1
+		}),
+		new web3._extend.Method({
+			name: 'getSkippedL1Messages',
+			call: 'scroll_getSkippedL1Messages',
+			params: 2
+		}),
+		new web3._extend.Method({
+			name: 'getWithdrawProof',
+			call: 'scroll_getWithdrawProof',
+			params: 2
+		}),
+		new web3._extend.Method({
+			name: 'getWithdrawRoot',
+			call: 'scroll_getWithdrawRoot',
+			params: 1
+		}),
 		new web3._extend.Method({
 			name: 'getFirstQueueIndexNotInL2Block',
 			call: 'scroll_getFirstQueueIndexNotInL2Block',
@@ -910,6 +1001,63 @@ web3._extend({
 			inputFormatter: [web3._extend.formatters.inputCallFormatter, web3._extend.formatters.inputBlockNumberFormatter],
 			outputFormatter: web3._extend.utils.toDecimal
 		}),
+		new web3._extend.Method({
+			name: 'estimateFees',
+			call: 'scroll_estimateFees',
+			params: 2,
+			inputFormatter: [web3._extend.formatters.inputCallFormatter, web3._extend.formatters.inputBlockNumberFormatter]
+		}),
+		new web3._extend.Method({
+			name: 'getBatchByIndex',
+			call: 'scroll_getBatchByIndex',
+			params: 1
+		}),
+		new web3._extend.Method({
+			name: 'getBatchByBlockNumber',
+			call: 'scroll_getBatchByBlockNumber',
+			params: 1,
+			inputFormatter: [web3._extend.formatters.inputBlockNumberFormatter]
+		}),
+		new web3._extend.Method({
+			name: 'getBatchByL1Block',
+			call: 'scroll_getBatchByL1Block',
+			params: 1
+		}),
+		new web3._extend.Method({
+			name: 'getFinalizedRoots',
+			call: 'scroll_getFinalizedRoots',
+			params: 1
+		}),
+		new web3._extend.Method({
+			name: 'syncGaps',
+			call: 'scroll_syncGaps',
+			params: 0
+		}),
+		new web3._extend.Method({
+			name: 'revalidateBatch',
+			call: 'scroll_revalidateBatch',
+			params: 1
+		}),
+		new web3._extend.Method({
+			name: 'getTxBatchInclusionProof',
+			call: 'scroll_getTxBatchInclusionProof',
+			params: 1
+		}),
+		new web3._extend.Method({
+			name: 'getDAUsage',
+			call: 'scroll_getDAUsage',
+			params: 0
+		}),
+		new web3._extend.Method({
+			name: 'getL1EndpointHistory',
+			call: 'scroll_getL1EndpointHistory',
+			params: 2
+		}),
+		new web3._extend.Method({
+			name: 'getResultSchemas',
+			call: 'scroll_getResultSchemas',
+			params: 0
+		}),
 	],
 	properties:
 	[
@@ -58,6 +58,12 @@ type Config struct {
 	Noverify   bool           // Disable remote mining solution verification(only useful in ethash).
 
 	StoreSkippedTxTraces bool // Whether store the wrapped traces when storing a skipped tx
+
+	DeterministicL1Messages bool   // Include pending L1 messages in queue order before any L2 transaction, see below
+	L1MessageGasBudget      uint64 // Maximum gas limit of the L1 messages of a block in the deterministic mode (0 = block gas limit)
+	MaxSkippedL1Messages    int    // Maximum number of L1 messages skipped per block in the deterministic mode (-1 = unlimited)
+
+	ChunkConstraintChecker ChunkConstraintChecker `toml:"-"` // Chunk limits of the built blocks (nil = unchecked)
 }
 
 // Miner creates blocks and searches for proof-of-work values.
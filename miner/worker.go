@@ -91,6 +91,7 @@ var (
 	l1TxCccUnknownErrCounter          = metrics.NewRegisteredCounter("miner/skipped_txs/l1/ccc_unknown_err", nil)
 	l2TxCccUnknownErrCounter          = metrics.NewRegisteredCounter("miner/skipped_txs/l2/ccc_unknown_err", nil)
 	l1TxStrangeErrCounter             = metrics.NewRegisteredCounter("miner/skipped_txs/l1/strange_err", nil)
+	l1TxDeferredCounter               = metrics.NewRegisteredCounter("miner/deferred_txs/l1/skip_limit", nil)
 	l2CommitTxsTimer                  = metrics.NewRegisteredTimer("miner/commit/txs_all", nil)
 	l2CommitTxTimer                   = metrics.NewRegisteredTimer("miner/commit/tx_all", nil)
 	l2CommitTxTraceTimer              = metrics.NewRegisteredTimer("miner/commit/tx_trace", nil)
@@ -115,6 +116,8 @@ type environment struct {
 	tcount    int                // tx count in cycle
 	blockSize common.StorageSize // approximate size of tx payload in bytes
 	l1TxCount int                // l1 msg count in cycle
+	l1TxGas   uint64             // gas limit of the l1 msgs in cycle
+	l1Skipped int                // skipped l1 msg count in cycle
 	gasPool   *core.GasPool      // available gas used to pack transactions
 
 	header   *types.Header
@@ -163,6 +166,20 @@ type prioritizedTransaction struct {
 	tx          *types.Transaction
 }
 
+// ChunkConstraintChecker enforces the limits of the chunks that the built blocks are
+// committed to L1 in, e.g. the payload size or the number of L1 messages, so that every
+// block can be packed into a batch.
+type ChunkConstraintChecker interface {
+	// Reset starts checking a new block, whose L1 messages start at the given queue index.
+	Reset(header *types.Header, nextL1MsgIndex uint64)
+
+	// Check returns an error if including the transaction makes the block violate a limit.
+	Check(tx *types.Transaction) error
+
+	// Commit records that the checked transaction was included in the block.
+	Commit(tx *types.Transaction)
+}
+
 // worker is the main object which takes care of submitting new work to consensus engine
 // and gathering the sealing result.
 type worker struct {
@@ -230,6 +247,7 @@ type worker struct {
 	isLocalBlock func(block *types.Block) bool // Function used to determine whether the specified block is mined by local miner.
 
 	circuitCapacityChecker *circuitcapacitychecker.CircuitCapacityChecker
+	chunkChecker           ChunkConstraintChecker
 	prioritizedTx          *prioritizedTransaction
 
 	// Test hooks
@@ -264,6 +282,7 @@ func newWorker(config *Config, chainConfig *params.ChainConfig, engine consensus
 		resubmitIntervalCh:     make(chan time.Duration),
 		resubmitAdjustCh:       make(chan *intervalAdjust, resubmitAdjustChanSize),
 		circuitCapacityChecker: circuitcapacitychecker.NewCircuitCapacityChecker(true),
+		chunkChecker:           config.ChunkConstraintChecker,
 	}
 	log.Info("created new worker", "CircuitCapacityChecker ID", worker.circuitCapacityChecker.ID)
 
@@ -291,6 +310,12 @@ func newWorker(config *Config, chainConfig *params.ChainConfig, engine consensus
 		log.Warn("Sanitizing miner recommit interval", "provided", recommit, "updated", minRecommitInterval)
 		recommit = minRecommitInterval
 	}
+	// Sanitize the L1 message skip limit, a block must be able to skip the message at the
+	// head of the queue, otherwise a message that can never be included stalls the queue.
+	if worker.config.DeterministicL1Messages && worker.config.MaxSkippedL1Messages == 0 {
+		log.Warn("Sanitizing miner L1 message skip limit", "provided", 0, "updated", 1)
+		worker.config.MaxSkippedL1Messages = 1
+	}
 
 	worker.wg.Add(4)
 	go worker.mainLoop()
@@ -801,7 +826,7 @@ func (w *worker) resultLoop() {
 func (w *worker) makeCurrent(parent *types.Block, header *types.Header) error {
 	// Retrieve the parent state to execute on top and start a prefetcher for
 	// the miner to speed block sealing up a bit
-	state, err := w.chain.StateAt(parent.Root())
+	state, err := w.chain.StateForChild(parent.Header())
 	if err != nil {
 		return err
 	}
@@ -841,7 +866,12 @@ func (w *worker) makeCurrent(parent *types.Block, header *types.Header) error {
 	env.tcount = 0
 	env.blockSize = 0
 	env.l1TxCount = 0
+	env.l1TxGas = 0
+	env.l1Skipped = 0
 	env.nextL1MsgIndex = traceEnv.StartL1QueueIndex
+	if w.chunkChecker != nil {
+		w.chunkChecker.Reset(header, env.nextL1MsgIndex)
+	}
 
 	// Swap out the old work with the new one, terminating any leftover prefetcher
 	// processes in the mean time and starting a new one.
@@ -1064,6 +1094,19 @@ loop:
 			txs.Pop() // skip transactions from this account
 			continue
 		}
+		if tx.IsL1MessageTx() && !w.withinL1MessageGasBudget(tx) {
+			log.Trace("L1 message gas budget reached", "have", w.current.l1TxGas, "want", w.config.L1MessageGasBudget, "tx", tx.Hash().String())
+			break
+		}
+		if err := w.checkChunkLimits(tx); err != nil {
+			log.Trace("Chunk limit reached", "tx", tx.Hash().String(), "err", err)
+			if tx.IsL1MessageTx() {
+				// L1 messages are included in order, stop here and continue in the next block
+				break
+			}
+			txs.Pop() // skip transactions from this account
+			continue
+		}
 		// Error may be ignored here. The error has already been checked
 		// during transaction acceptance in the transaction pool.
 		//
@@ -1090,6 +1133,9 @@ loop:
 			}
 			// A single L1 message leads to out-of-gas. Skip it.
 			queueIndex := tx.AsL1MessageTx().QueueIndex
+			if !w.skipL1Message(queueIndex) {
+				break loop
+			}
 			log.Info("Skipping L1 message", "queueIndex", queueIndex, "tx", tx.Hash().String(), "block", w.current.header.Number, "reason", "gas limit exceeded")
 			w.current.nextL1MsgIndex = queueIndex + 1
 			txs.Shift()
@@ -1119,12 +1165,16 @@ loop:
 			// Everything ok, collect the logs and shift in the next transaction from the same account
 			coalescedLogs = append(coalescedLogs, logs...)
 			w.current.tcount++
+			if w.chunkChecker != nil {
+				w.chunkChecker.Commit(tx)
+			}
 			txs.Shift()
 
 			if tx.IsL1MessageTx() {
 				queueIndex := tx.AsL1MessageTx().QueueIndex
 				log.Debug("Including L1 message", "queueIndex", queueIndex, "tx", tx.Hash().String())
 				w.current.l1TxCount++
+				w.current.l1TxGas += tx.Gas()
 				w.current.nextL1MsgIndex = queueIndex + 1
 			} else {
 				// only consider block size limit for L2 transactions
@@ -1163,11 +1213,15 @@ loop:
 				log.Trace("Circuit capacity limit reached for a single tx", "tx", tx.Hash().String())
 
 				if tx.IsL1MessageTx() {
+					queueIndex := tx.AsL1MessageTx().QueueIndex
+					if !w.skipL1Message(queueIndex) {
+						break loop
+					}
+
 					// Skip L1 message transaction,
 					// shift to the next from the account because we shouldn't skip the entire txs from the same account
 					txs.Shift()
 
-					queueIndex := tx.AsL1MessageTx().QueueIndex
 					log.Info("Skipping L1 message", "queueIndex", queueIndex, "tx", tx.Hash().String(), "block", w.current.header.Number, "reason", "first tx row consumption overflow")
 					w.current.nextL1MsgIndex = queueIndex + 1
 					l1TxRowConsumptionOverflowCounter.Inc(1)
@@ -1175,7 +1229,7 @@ loop:
 					// Skip L2 transaction and all other transactions from the same sender account
 					log.Info("Skipping L2 message", "tx", tx.Hash().String(), "block", w.current.header.Number, "reason", "first tx row consumption overflow")
 					txs.Pop()
-					w.eth.TxPool().RemoveTx(tx.Hash(), true)
+					w.eth.TxPool().RemoveOverflowingTx(tx.Hash())
 					l2TxRowConsumptionOverflowCounter.Inc(1)
 				}
 
@@ -1197,6 +1251,10 @@ loop:
 			// shift to the next from the account because we shouldn't skip the entire txs from the same account
 			queueIndex := tx.AsL1MessageTx().QueueIndex
 			log.Trace("Unknown circuit capacity checker error for L1MessageTx", "tx", tx.Hash().String(), "queueIndex", queueIndex)
+			if !w.skipL1Message(queueIndex) {
+				circuitCapacityReached = true
+				break loop
+			}
 			log.Info("Skipping L1 message", "queueIndex", queueIndex, "tx", tx.Hash().String(), "block", w.current.header.Number, "reason", "unknown row consumption error")
 			w.current.nextL1MsgIndex = queueIndex + 1
 			// TODO: propagate more info about the error from CCC
@@ -1245,6 +1303,9 @@ loop:
 			log.Debug("Transaction failed, account skipped", "hash", tx.Hash().String(), "err", err)
 			if tx.IsL1MessageTx() {
 				queueIndex := tx.AsL1MessageTx().QueueIndex
+				if !w.skipL1Message(queueIndex) {
+					break loop
+				}
 				log.Info("Skipping L1 message", "queueIndex", queueIndex, "tx", tx.Hash().String(), "block", w.current.header.Number, "reason", "strange error", "err", err)
 				w.current.nextL1MsgIndex = queueIndex + 1
 				if w.config.StoreSkippedTxTraces {
@@ -1281,6 +1342,38 @@ loop:
 	return false, circuitCapacityReached
 }
 
+// checkChunkLimits returns an error if including the transaction makes the current block
+// violate the chunk limits of the configured checker, if any.
+func (w *worker) checkChunkLimits(tx *types.Transaction) error {
+	if w.chunkChecker == nil {
+		return nil
+	}
+	return w.chunkChecker.Check(tx)
+}
+
+// withinL1MessageGasBudget reports whether the L1 message fits in the L1 message gas
+// budget of the block. The first L1 message of a block always fits.
+func (w *worker) withinL1MessageGasBudget(tx *types.Transaction) bool {
+	if !w.config.DeterministicL1Messages || w.config.L1MessageGasBudget == 0 || w.current.l1TxCount == 0 {
+		return true
+	}
+	return w.current.l1TxGas+tx.Gas() <= w.config.L1MessageGasBudget
+}
+
+// skipL1Message records that the L1 message is about to be skipped, and reports whether
+// it may be. Once the skip limit of the block is reached in the deterministic mode, the
+// message is deferred to the next block instead. The limit is at least 1, so a deferred
+// message is skipped at the latest in the next block, where it heads the pending messages.
+func (w *worker) skipL1Message(queueIndex uint64) bool {
+	if w.config.DeterministicL1Messages && w.config.MaxSkippedL1Messages >= 0 && w.current.l1Skipped >= w.config.MaxSkippedL1Messages {
+		log.Info("Deferring L1 message", "queueIndex", queueIndex, "block", w.current.header.Number, "reason", "skip limit reached", "skipped", w.current.l1Skipped)
+		l1TxDeferredCounter.Inc(1)
+		return false
+	}
+	w.current.l1Skipped++
+	return true
+}
+
 func (w *worker) checkCurrentTxNumWithCCC(expected int) {
 	match, got, err := w.circuitCapacityChecker.CheckTxNum(expected)
 	if err != nil {
@@ -1439,6 +1532,15 @@ func (w *worker) commitNewWork(interrupt *int32, noempty bool, timestamp int64)
 		if skipCommit {
 			return
 		}
+		if w.config.DeterministicL1Messages && w.current.nextL1MsgIndex <= l1Messages[len(l1Messages)-1].QueueIndex {
+			// the block includes no L2 transactions until the pending L1 messages are processed,
+			// it is produced even if all its messages were skipped so that the queue advances
+			log.Trace("Pending L1 messages not processed, excluding L2 transactions", "next", w.current.nextL1MsgIndex, "last", l1Messages[len(l1Messages)-1].QueueIndex)
+			if w.current.tcount > 0 || w.current.l1Skipped > 0 {
+				w.commit(uncles, w.fullTaskHook, true, tstart)
+			}
+			return
+		}
 	}
 	if w.prioritizedTx != nil && w.current.header.Number.Uint64() > w.prioritizedTx.blockNumber {
 		w.prioritizedTx = nil
@@ -17,6 +17,7 @@
 package miner
 
 import (
+	"fmt"
 	"math/big"
 	"math/rand"
 	"sync/atomic"
@@ -38,7 +39,9 @@ import (
 	"github.com/scroll-tech/go-ethereum/ethdb"
 	"github.com/scroll-tech/go-ethereum/event"
 	"github.com/scroll-tech/go-ethereum/params"
+	"github.com/scroll-tech/go-ethereum/rollup/chunkchecker"
 	"github.com/scroll-tech/go-ethereum/rollup/circuitcapacitychecker"
+	"github.com/scroll-tech/go-ethereum/rollup/rollup_sync_service"
 	"github.com/scroll-tech/go-ethereum/rollup/sync_service"
 )
 
@@ -800,6 +803,10 @@ func TestL1MsgCorrectOrder(t *testing.T) {
 }
 
 func l1MessageTest(t *testing.T, msgs []types.L1MessageTx, withL2Tx bool, callback func(i int, block *types.Block, db ethdb.Database, w *worker) bool) {
+	l1MessageTestWithConfig(t, testConfig, msgs, withL2Tx, callback)
+}
+
+func l1MessageTestWithConfig(t *testing.T, config *Config, msgs []types.L1MessageTx, withL2Tx bool, callback func(i int, block *types.Block, db ethdb.Database, w *worker) bool) {
 	var (
 		engine      consensus.Engine
 		chainConfig *params.ChainConfig
@@ -820,7 +827,10 @@ func l1MessageTest(t *testing.T, msgs []types.L1MessageTx, withL2Tx bool, callba
 	}
 
 	chainConfig.LondonBlock = big.NewInt(0)
-	w, b := newTestWorker(t, chainConfig, engine, db, 0)
+	b := newTestWorkerBackend(t, chainConfig, engine, db, 0)
+	b.txPool.AddLocals(pendingTxs)
+	w := newWorker(config, chainConfig, engine, b, new(event.TypeMux), nil, false)
+	w.setEtherbase(testBankAddress)
 	defer w.close()
 
 	// This test chain imports the mined blocks.
@@ -1253,3 +1263,154 @@ func TestSkippedTransactionDatabaseEntries(t *testing.T) {
 		}
 	})
 }
+
+func TestChunkConstraintL1Messages(t *testing.T) {
+	assert := assert.New(t)
+	var (
+		engine      consensus.Engine
+		chainConfig *params.ChainConfig
+		db          = rawdb.NewMemoryDatabase()
+	)
+	chainConfig = params.AllCliqueProtocolChanges
+	chainConfig.Clique = &params.CliqueConfig{Period: 1, Epoch: 30000}
+	engine = clique.New(chainConfig.Clique, db)
+	chainConfig.Scroll.L1Config = &params.L1Config{
+		NumL1MessagesPerBlock: 10,
+	}
+
+	var msgs []types.L1MessageTx
+	for i := uint64(0); i < 5; i++ {
+		msgs = append(msgs, types.L1MessageTx{QueueIndex: i, Gas: 21016, To: &common.Address{3}, Data: []byte{0x01}, Sender: common.Address{4}})
+	}
+	rawdb.WriteL1Messages(db, msgs)
+
+	// chunks pop at most 2 L1 messages
+	config := *testConfig
+	config.ChunkConstraintChecker = chunkchecker.NewChunkChecker(rollup_sync_service.LatestCodec(), rollup_sync_service.NewChunkRules([]rollup_sync_service.ChunkLimits{{MaxL1Messages: 2}}))
+
+	chainConfig.LondonBlock = big.NewInt(0)
+	b := newTestWorkerBackend(t, chainConfig, engine, db, 0)
+	w := newWorker(&config, chainConfig, engine, b, new(event.TypeMux), nil, false)
+	w.setEtherbase(testBankAddress)
+	defer w.close()
+
+	// Ignore empty commit here for less noise.
+	w.skipSealHook = func(task *task) bool {
+		return len(task.receipts) == 0
+	}
+
+	// Wait for mined blocks.
+	sub := w.mux.Subscribe(core.NewMinedBlockEvent{})
+	defer sub.Unsubscribe()
+
+	// Start mining!
+	w.start()
+
+	for _, expected := range [][]uint64{{0, 1}, {2, 3}, {4}} {
+		select {
+		case ev := <-sub.Chan():
+			block := ev.Data.(core.NewMinedBlockEvent).Block
+			var queueIndices []uint64
+			for _, tx := range block.Transactions() {
+				assert.True(tx.IsL1MessageTx())
+				queueIndices = append(queueIndices, tx.AsL1MessageTx().QueueIndex)
+			}
+			assert.Equal(expected, queueIndices)
+		case <-time.After(3 * time.Second):
+			t.Fatalf("timeout")
+		}
+	}
+}
+
+func TestDeterministicL1MessageGasBudget(t *testing.T) {
+	assert := assert.New(t)
+
+	var msgs []types.L1MessageTx
+	for i := uint64(0); i < 5; i++ {
+		msgs = append(msgs, types.L1MessageTx{QueueIndex: i, Gas: 21016, To: &common.Address{1}, Data: []byte{0x01}, Sender: common.Address{2}})
+	}
+
+	// the budget fits 2 messages, the L2 tx is only included once the collected messages are processed
+	config := *testConfig
+	config.DeterministicL1Messages = true
+	config.L1MessageGasBudget = 50000
+	config.MaxSkippedL1Messages = -1
+
+	l1MessageTestWithConfig(t, &config, msgs, true, func(blockNum int, block *types.Block, db ethdb.Database, w *worker) bool {
+		switch blockNum {
+		case 0:
+			return false
+		case 1, 2:
+			// 3 messages are collected per block, the third one exceeds the budget
+			assert.Equal(2, len(block.Transactions()), "block: %d", blockNum)
+			for i, tx := range block.Transactions() {
+				assert.True(tx.IsL1MessageTx())
+				assert.Equal(uint64(2*(blockNum-1)+i), tx.AsL1MessageTx().QueueIndex)
+			}
+			return false
+		case 3:
+			assert.Equal(2, len(block.Transactions()))
+			assert.True(block.Transactions()[0].IsL1MessageTx())
+			assert.Equal(uint64(4), block.Transactions()[0].AsL1MessageTx().QueueIndex)
+			assert.False(block.Transactions()[1].IsL1MessageTx())
+			return true
+		default:
+			return true
+		}
+	})
+}
+
+func TestDeterministicL1MessageMaxSkips(t *testing.T) {
+	// a limit of 0 would stall the queue at a message that is never included, it is raised to 1
+	for _, maxSkips := range []int{1, 0} {
+		t.Run(fmt.Sprintf("maxskips=%d", maxSkips), func(t *testing.T) {
+			testDeterministicL1MessageMaxSkips(t, maxSkips)
+		})
+	}
+}
+
+func testDeterministicL1MessageMaxSkips(t *testing.T, maxSkips int) {
+	assert := assert.New(t)
+
+	// messages #1 and #2 are skipped because of `Value`
+	msgs := []types.L1MessageTx{
+		{QueueIndex: 0, Gas: 21016, To: &common.Address{1}, Data: []byte{0x01}, Sender: common.Address{2}},
+		{QueueIndex: 1, Gas: 25100, To: &common.Address{1}, Data: []byte{0x01}, Sender: common.Address{3}, Value: big.NewInt(1)},
+		{QueueIndex: 2, Gas: 25100, To: &common.Address{1}, Data: []byte{0x01}, Sender: common.Address{3}, Value: big.NewInt(1)},
+		{QueueIndex: 3, Gas: 21016, To: &common.Address{1}, Data: []byte{0x01}, Sender: common.Address{2}},
+	}
+
+	config := *testConfig
+	config.DeterministicL1Messages = true
+	config.MaxSkippedL1Messages = maxSkips
+
+	l1MessageTestWithConfig(t, &config, msgs, true, func(blockNum int, block *types.Block, db ethdb.Database, w *worker) bool {
+		switch blockNum {
+		case 0:
+			return false
+		case 1:
+			// include #0, skip #1 and defer #2 to the next block, without L2 tx
+			assert.Equal(1, len(block.Transactions()))
+			assert.True(block.Transactions()[0].IsL1MessageTx())
+			assert.Equal(uint64(0), block.Transactions()[0].AsL1MessageTx().QueueIndex)
+
+			queueIndex := rawdb.ReadFirstQueueIndexNotInL2Block(db, block.Hash())
+			assert.NotNil(queueIndex)
+			assert.Equal(uint64(2), *queueIndex)
+			return false
+		case 2:
+			// skip #2, include #3 + one L2 tx
+			assert.Equal(2, len(block.Transactions()))
+			assert.True(block.Transactions()[0].IsL1MessageTx())
+			assert.Equal(uint64(3), block.Transactions()[0].AsL1MessageTx().QueueIndex)
+			assert.False(block.Transactions()[1].IsL1MessageTx())
+
+			queueIndex := rawdb.ReadFirstQueueIndexNotInL2Block(db, block.Hash())
+			assert.NotNil(queueIndex)
+			assert.Equal(uint64(4), *queueIndex)
+			return true
+		default:
+			return true
+		}
+	})
+}
@@ -185,6 +185,8 @@ func (api *privateAdminAPI) StartHTTP(host *string, port *int, cors *string, api
 		CorsAllowedOrigins: api.node.config.HTTPCors,
 		Vhosts:             api.node.config.HTTPVirtualHosts,
 		Modules:            api.node.config.HTTPModules,
+		MethodAllow:        api.node.config.RPCMethodAllow,
+		MethodDeny:         api.node.config.RPCMethodDeny,
 	}
 	if cors != nil {
 		config.CorsAllowedOrigins = nil
@@ -256,8 +258,10 @@ func (api *privateAdminAPI) StartWS(host *string, port *int, allowedOrigins *str
 
 	// Determine config.
 	config := wsConfig{
-		Modules: api.node.config.WSModules,
-		Origins: api.node.config.WSOrigins,
+		Modules:     api.node.config.WSModules,
+		MethodAllow: api.node.config.RPCMethodAllow,
+		MethodDeny:  api.node.config.RPCMethodDeny,
+		Origins:     api.node.config.WSOrigins,
 		// ExposeAll: api.node.config.WSExposeAll,
 	}
 	if apis != nil {
@@ -25,6 +25,7 @@ import (
 	"runtime"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/scroll-tech/go-ethereum/common"
 	"github.com/scroll-tech/go-ethereum/crypto"
@@ -160,6 +161,16 @@ type Config struct {
 	// exposed.
 	WSModules []string
 
+	// RPCMethodAllow is a list of RPC methods to expose via the HTTP and websocket RPC
+	// interfaces even if their module is not exposed, e.g. "admin_rollupSyncStatus".
+	// Patterns may contain path.Match wildcards. The IPC interface is not filtered.
+	RPCMethodAllow []string `toml:",omitempty"`
+
+	// RPCMethodDeny is a list of RPC methods that are not exposed via the HTTP and
+	// websocket RPC interfaces, even if their module is exposed. It takes precedence
+	// over RPCMethodAllow.
+	RPCMethodDeny []string `toml:",omitempty"`
+
 	// WSExposeAll exposes all API modules via the WebSocket RPC interface rather
 	// than just the public ones.
 	//
@@ -197,6 +208,19 @@ type Config struct {
 	L1Confirmations rpc.BlockNumber `toml:",omitempty"`
 	// L1 bridge deployment block number
 	L1DeploymentBlock uint64 `toml:",omitempty"`
+	// Maximum number of eth_getLogs queries per second sent to L1, 0 for no limit
+	L1GetLogsRateLimit float64 `toml:",omitempty"`
+	// Maximum number of eth_getLogs queries per day sent to L1, 0 for no limit
+	L1GetLogsDailyBudget uint64 `toml:",omitempty"`
+	// Endpoint of an L1 light client serving verified L1 data over HTTP-RPC
+	L1VerifiedEndpoint string `toml:",omitempty"`
+	// Source of L1 data on startup, "trusted" (L1Endpoint) or "verified" (L1VerifiedEndpoint)
+	L1Mode string `toml:",omitempty"`
+	// Latency above which L1 requests are logged as slow, 0 to disable
+	L1SlowQueryThreshold time.Duration `toml:",omitempty"`
+	// Endpoints of L1Endpoint on which the ScrollChain events are collected from block
+	// receipts instead of eth_getLogs
+	L1ReceiptsEndpoints []string `toml:",omitempty"`
 }
 
 // IPCEndpoint resolves an IPC endpoint based on a configured value, taking into
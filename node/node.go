@@ -357,6 +357,8 @@ func (n *Node) startRPC() error {
 			CorsAllowedOrigins: n.config.HTTPCors,
 			Vhosts:             n.config.HTTPVirtualHosts,
 			Modules:            n.config.HTTPModules,
+			MethodAllow:        n.config.RPCMethodAllow,
+			MethodDeny:         n.config.RPCMethodDeny,
 			prefix:             n.config.HTTPPathPrefix,
 		}
 		if err := n.http.setListenAddr(n.config.HTTPHost, n.config.HTTPPort); err != nil {
@@ -371,9 +373,11 @@ func (n *Node) startRPC() error {
 	if n.config.WSHost != "" {
 		server := n.wsServerForPort(n.config.WSPort)
 		config := wsConfig{
-			Modules: n.config.WSModules,
-			Origins: n.config.WSOrigins,
-			prefix:  n.config.WSPathPrefix,
+			Modules:     n.config.WSModules,
+			MethodAllow: n.config.RPCMethodAllow,
+			MethodDeny:  n.config.RPCMethodDeny,
+			Origins:     n.config.WSOrigins,
+			prefix:      n.config.WSPathPrefix,
 		}
 		if err := server.setListenAddr(n.config.WSHost, n.config.WSPort); err != nil {
 			return err
@@ -593,14 +597,7 @@ func (n *Node) OpenDatabaseWithFreezer(name string, cache, handles int, freezer,
 	if n.config.DataDir == "" {
 		db = rawdb.NewMemoryDatabase()
 	} else {
-		root := n.ResolvePath(name)
-		switch {
-		case freezer == "":
-			freezer = filepath.Join(root, "ancient")
-		case !filepath.IsAbs(freezer):
-			freezer = n.ResolvePath(freezer)
-		}
-		db, err = rawdb.NewLevelDBDatabaseWithFreezer(root, cache, handles, freezer, namespace, readonly)
+		db, err = rawdb.NewLevelDBDatabaseWithFreezer(n.ResolvePath(name), cache, handles, n.ResolveAncient(name, freezer), namespace, readonly)
 	}
 
 	if err == nil {
@@ -609,6 +606,18 @@ func (n *Node) OpenDatabaseWithFreezer(name string, cache, handles int, freezer,
 	return db, err
 }
 
+// ResolveAncient returns the absolute path of the freezer directory of the named
+// database, as used by OpenDatabaseWithFreezer.
+func (n *Node) ResolveAncient(name string, freezer string) string {
+	switch {
+	case freezer == "":
+		return filepath.Join(n.ResolvePath(name), "ancient")
+	case !filepath.IsAbs(freezer):
+		return n.ResolvePath(freezer)
+	}
+	return freezer
+}
+
 // ResolvePath returns the absolute path of a resource in the instance directory.
 func (n *Node) ResolvePath(x string) string {
 	return n.config.ResolvePath(x)
@@ -24,6 +24,7 @@ import (
 	"io/ioutil"
 	"net"
 	"net/http"
+	"path"
 	"sort"
 	"strings"
 	"sync"
@@ -38,6 +39,8 @@ import (
 // httpConfig is the JSON-RPC/HTTP configuration.
 type httpConfig struct {
 	Modules            []string
+	MethodAllow        []string // methods exposed beyond the modules, see methodFilter
+	MethodDeny         []string // methods hidden although their module is exposed
 	CorsAllowedOrigins []string
 	Vhosts             []string
 	prefix             string // path prefix on which to mount http handler
@@ -45,9 +48,11 @@ type httpConfig struct {
 
 // wsConfig is the JSON-RPC/Websocket configuration
 type wsConfig struct {
-	Origins []string
-	Modules []string
-	prefix  string // path prefix on which to mount ws handler
+	Origins     []string
+	Modules     []string
+	MethodAllow []string // methods exposed beyond the modules, see methodFilter
+	MethodDeny  []string // methods hidden although their module is exposed
+	prefix      string   // path prefix on which to mount ws handler
 }
 
 type rpcHandler struct {
@@ -280,8 +285,12 @@ func (h *httpServer) enableRPC(apis []rpc.API, config httpConfig) error {
 	}
 
 	// Create RPC server and handler.
+	filter, err := newMethodFilter(config.MethodAllow, config.MethodDeny)
+	if err != nil {
+		return err
+	}
 	srv := rpc.NewServer()
-	if err := RegisterApis(apis, config.Modules, srv, false); err != nil {
+	if err := registerFilteredApis(apis, config.Modules, filter, srv, false); err != nil {
 		return err
 	}
 	h.httpConfig = config
@@ -312,8 +321,12 @@ func (h *httpServer) enableWS(apis []rpc.API, config wsConfig) error {
 	}
 
 	// Create RPC server and handler.
+	filter, err := newMethodFilter(config.MethodAllow, config.MethodDeny)
+	if err != nil {
+		return err
+	}
 	srv := rpc.NewServer()
-	if err := RegisterApis(apis, config.Modules, srv, false); err != nil {
+	if err := registerFilteredApis(apis, config.Modules, filter, srv, false); err != nil {
 		return err
 	}
 	h.wsConfig = config
@@ -519,6 +532,12 @@ func (is *ipcServer) stop() error {
 // RegisterApis checks the given modules' availability, generates an allowlist based on the allowed modules,
 // and then registers all of the APIs exposed by the services.
 func RegisterApis(apis []rpc.API, modules []string, srv *rpc.Server, exposeAll bool) error {
+	return registerFilteredApis(apis, modules, nil, srv, exposeAll)
+}
+
+// registerFilteredApis is like RegisterApis, but decides per method with the filter whether
+// it is exposed. A nil filter exposes all methods of the allowed modules.
+func registerFilteredApis(apis []rpc.API, modules []string, filter *methodFilter, srv *rpc.Server, exposeAll bool) error {
 	if bad, available := checkModuleAvailability(modules, apis); len(bad) > 0 {
 		log.Error("Unavailable modules in HTTP API list", "unavailable", bad, "available", available)
 	}
@@ -529,11 +548,61 @@ func RegisterApis(apis []rpc.API, modules []string, srv *rpc.Server, exposeAll b
 	}
 	// Register all the APIs exposed by the services
 	for _, api := range apis {
-		if exposeAll || allowList[api.Namespace] || (len(allowList) == 0 && api.Public) {
-			if err := srv.RegisterName(api.Namespace, api.Service); err != nil {
-				return err
+		enabled := exposeAll || allowList[api.Namespace] || (len(allowList) == 0 && api.Public)
+		if filter == nil {
+			if enabled {
+				if err := srv.RegisterName(api.Namespace, api.Service); err != nil {
+					return err
+				}
 			}
+			continue
+		}
+		allow := func(method string) bool { return filter.allowed(method, enabled) }
+		if err := srv.RegisterNameFiltered(api.Namespace, api.Service, allow); err != nil {
+			return err
 		}
 	}
 	return nil
 }
+
+// methodFilter controls the exposure of single RPC methods beyond the module granularity,
+// e.g. to serve the status methods of the admin module publicly while keeping the others
+// local-only. Patterns are full method names and may contain path.Match wildcards, e.g.
+// "admin_rollupSync*". Denied methods are never exposed, allowed methods are exposed even
+// if their module is not.
+type methodFilter struct {
+	allow []string
+	deny  []string
+}
+
+// newMethodFilter validates the patterns and creates a filter, or returns nil if both
+// pattern lists are empty.
+func newMethodFilter(allow, deny []string) (*methodFilter, error) {
+	if len(allow) == 0 && len(deny) == 0 {
+		return nil, nil
+	}
+	for _, pattern := range append(append([]string{}, allow...), deny...) {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return nil, fmt.Errorf("invalid RPC method pattern %q: %v", pattern, err)
+		}
+	}
+	return &methodFilter{allow: allow, deny: deny}, nil
+}
+
+// allowed reports whether the method is exposed, given whether its module is exposed.
+func (f *methodFilter) allowed(method string, moduleEnabled bool) bool {
+	if matchMethod(f.deny, method) {
+		return false
+	}
+	return moduleEnabled || matchMethod(f.allow, method)
+}
+
+// matchMethod reports whether the method matches any of the patterns.
+func matchMethod(patterns []string, method string) bool {
+	for _, pattern := range patterns {
+		if ok, _ := path.Match(pattern, method); ok {
+			return true
+		}
+	}
+	return false
+}
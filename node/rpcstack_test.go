@@ -292,3 +292,35 @@ func rpcRequest(t *testing.T, url string, extraHeaders ...string) *http.Response
 	}
 	return resp
 }
+
+type methodFilterTestService struct{}
+
+func (s *methodFilterTestService) Status() string { return "ok" }
+func (s *methodFilterTestService) Reset() bool    { return true }
+
+// TestRegisterFilteredApis makes sure single methods can be exposed or hidden beyond the modules.
+func TestRegisterFilteredApis(t *testing.T) {
+	apis := []rpc.API{
+		{Namespace: "admin", Service: new(methodFilterTestService)},
+		{Namespace: "scroll", Service: new(methodFilterTestService), Public: true},
+	}
+	filter, err := newMethodFilter([]string{"admin_status"}, []string{"scroll_res*"})
+	assert.NoError(t, err)
+
+	srv := rpc.NewServer()
+	assert.NoError(t, registerFilteredApis(apis, []string{"scroll"}, filter, srv, false))
+	client := rpc.DialInProc(srv)
+	defer client.Close()
+
+	var result interface{}
+	assert.NoError(t, client.Call(&result, "admin_status"))
+	assert.Error(t, client.Call(&result, "admin_reset"))
+	assert.NoError(t, client.Call(&result, "scroll_status"))
+	assert.Error(t, client.Call(&result, "scroll_reset"))
+
+	_, err = newMethodFilter([]string{"admin_[status"}, nil)
+	assert.Error(t, err)
+	filter, err = newMethodFilter(nil, nil)
+	assert.NoError(t, err)
+	assert.Nil(t, filter)
+}
@@ -391,7 +391,7 @@ var (
 			EnableEIP1559:             true,
 			MaxTxPerBlock:             nil,
 			MaxTxPayloadBytesPerBlock: nil,
-			L1Config:                  &L1Config{5, common.HexToAddress("0x0000000000000000000000000000000000000000"), 0, common.HexToAddress("0x0000000000000000000000000000000000000000")},
+			L1Config:                  &L1Config{5, common.HexToAddress("0x0000000000000000000000000000000000000000"), 0, common.HexToAddress("0x0000000000000000000000000000000000000000"), common.Address{}, 0},
 		}}
 
 	// AllCliqueProtocolChanges contains every protocol change (EIPs) introduced
@@ -407,7 +407,7 @@ var (
 			EnableEIP1559:             true,
 			MaxTxPerBlock:             nil,
 			MaxTxPayloadBytesPerBlock: nil,
-			L1Config:                  &L1Config{5, common.HexToAddress("0x0000000000000000000000000000000000000000"), 0, common.HexToAddress("0x0000000000000000000000000000000000000000")},
+			L1Config:                  &L1Config{5, common.HexToAddress("0x0000000000000000000000000000000000000000"), 0, common.HexToAddress("0x0000000000000000000000000000000000000000"), common.Address{}, 0},
 		}}
 
 	TestChainConfig = &ChainConfig{big.NewInt(1), big.NewInt(0), nil, false, big.NewInt(0), common.Hash{}, big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), nil, nil, nil, new(EthashConfig), nil,
@@ -418,7 +418,7 @@ var (
 			EnableEIP1559:             true,
 			MaxTxPerBlock:             nil,
 			MaxTxPayloadBytesPerBlock: nil,
-			L1Config:                  &L1Config{5, common.HexToAddress("0x0000000000000000000000000000000000000000"), 0, common.HexToAddress("0x0000000000000000000000000000000000000000")},
+			L1Config:                  &L1Config{5, common.HexToAddress("0x0000000000000000000000000000000000000000"), 0, common.HexToAddress("0x0000000000000000000000000000000000000000"), common.Address{}, 0},
 		}}
 	TestRules = TestChainConfig.Rules(new(big.Int))
 
@@ -430,7 +430,7 @@ var (
 			EnableEIP1559:             true,
 			MaxTxPerBlock:             nil,
 			MaxTxPayloadBytesPerBlock: nil,
-			L1Config:                  &L1Config{5, common.HexToAddress("0x0000000000000000000000000000000000000000"), 0, common.HexToAddress("0x0000000000000000000000000000000000000000")},
+			L1Config:                  &L1Config{5, common.HexToAddress("0x0000000000000000000000000000000000000000"), 0, common.HexToAddress("0x0000000000000000000000000000000000000000"), common.Address{}, 0},
 		}}
 )
 
@@ -547,6 +547,10 @@ type ScrollConfig struct {
 
 	// L1 config
 	L1Config *L1Config `json:"l1Config,omitempty"`
+
+	// Block from which on the state is kept in the zktrie if UseZktrie is not set, and in the
+	// MPT otherwise (nil = no switch) [optional]
+	TrieSwitchBlock *big.Int `json:"trieSwitchBlock,omitempty"`
 }
 
 // L1Config contains the l1 parameters needed to sync l1 contract events (e.g., l1 messages, commit/revert/finalize batches) in the sequencer
@@ -555,6 +559,12 @@ type L1Config struct {
 	L1MessageQueueAddress common.Address `json:"l1MessageQueueAddress,omitempty"`
 	NumL1MessagesPerBlock uint64         `json:"numL1MessagesPerBlock,string,omitempty"`
 	ScrollChainAddress    common.Address `json:"scrollChainAddress,omitempty"`
+
+	// L1MessageQueueV2Address is the upgraded message queue contract. L1 messages are
+	// collected from it from L1MessageQueueV2MigrationBlock on, and from the original
+	// queue contract before that block.
+	L1MessageQueueV2Address        common.Address `json:"l1MessageQueueV2Address,omitempty"`
+	L1MessageQueueV2MigrationBlock uint64         `json:"l1MessageQueueV2MigrationBlock,string,omitempty"`
 }
 
 func (c *L1Config) String() string {
@@ -562,6 +572,10 @@ func (c *L1Config) String() string {
 		return "<nil>"
 	}
 
+	if c.L1MessageQueueV2Address != (common.Address{}) {
+		return fmt.Sprintf("{l1ChainId: %v, l1MessageQueueAddress: %v, numL1MessagesPerBlock: %v, ScrollChainAddress: %v, l1MessageQueueV2Address: %v, l1MessageQueueV2MigrationBlock: %v}",
+			c.L1ChainId, c.L1MessageQueueAddress.Hex(), c.NumL1MessagesPerBlock, c.ScrollChainAddress.Hex(), c.L1MessageQueueV2Address.Hex(), c.L1MessageQueueV2MigrationBlock)
+	}
 	return fmt.Sprintf("{l1ChainId: %v, l1MessageQueueAddress: %v, numL1MessagesPerBlock: %v, ScrollChainAddress: %v}",
 		c.L1ChainId, c.L1MessageQueueAddress.Hex(), c.NumL1MessagesPerBlock, c.ScrollChainAddress.Hex())
 }
@@ -578,6 +592,17 @@ func (s ScrollConfig) ZktrieEnabled() bool {
 	return s.UseZktrie
 }
 
+// ZktrieEnabledAt returns whether the state after the given block is kept in the zktrie.
+func (s ScrollConfig) ZktrieEnabledAt(num *big.Int) bool {
+	return s.UseZktrie != isForked(s.TrieSwitchBlock, num)
+}
+
+// IsTrieSwitchBlock returns whether the given block is the first one whose state is kept
+// in the other trie than the state of its parent.
+func (s ScrollConfig) IsTrieSwitchBlock(num *big.Int) bool {
+	return s.TrieSwitchBlock != nil && s.TrieSwitchBlock.Cmp(num) == 0
+}
+
 func (s ScrollConfig) ShouldIncludeL1Messages() bool {
 	return s.L1Config != nil && s.L1Config.NumL1MessagesPerBlock > 0
 }
@@ -593,8 +618,8 @@ func (s ScrollConfig) String() string {
 		maxTxPayloadBytesPerBlock = fmt.Sprintf("%v", *s.MaxTxPayloadBytesPerBlock)
 	}
 
-	return fmt.Sprintf("{useZktrie: %v, maxTxPerBlock: %v, MaxTxPayloadBytesPerBlock: %v, feeVaultAddress: %v, enableEIP2718: %v, enableEIP1559: %v, l1Config: %v}",
-		s.UseZktrie, maxTxPerBlock, maxTxPayloadBytesPerBlock, s.FeeVaultAddress, s.EnableEIP2718, s.EnableEIP1559, s.L1Config.String())
+	return fmt.Sprintf("{useZktrie: %v, maxTxPerBlock: %v, MaxTxPayloadBytesPerBlock: %v, feeVaultAddress: %v, enableEIP2718: %v, enableEIP1559: %v, l1Config: %v, trieSwitchBlock: %v}",
+		s.UseZktrie, maxTxPerBlock, maxTxPayloadBytesPerBlock, s.FeeVaultAddress, s.EnableEIP2718, s.EnableEIP1559, s.L1Config.String(), s.TrieSwitchBlock)
 }
 
 // IsValidTxCount returns whether the given block's transaction count is below the limit.
@@ -871,6 +896,9 @@ func (c *ChainConfig) checkCompatible(newcfg *ChainConfig, head *big.Int) *Confi
 	if isForkIncompatible(c.ArchimedesBlock, newcfg.ArchimedesBlock, head) {
 		return newCompatError("Archimedes fork block", c.ArchimedesBlock, newcfg.ArchimedesBlock)
 	}
+	if isForkIncompatible(c.Scroll.TrieSwitchBlock, newcfg.Scroll.TrieSwitchBlock, head) {
+		return newCompatError("Trie switch fork block", c.Scroll.TrieSwitchBlock, newcfg.Scroll.TrieSwitchBlock)
+	}
 	if isForkIncompatible(c.ShanghaiBlock, newcfg.ShanghaiBlock, head) {
 		return newCompatError("Shanghai fork block", c.ShanghaiBlock, newcfg.ShanghaiBlock)
 	}
@@ -0,0 +1,71 @@
+package chunkchecker
+
+import (
+	"fmt"
+
+	"github.com/scroll-tech/go-ethereum/core/types"
+	"github.com/scroll-tech/go-ethereum/rollup/rollup_sync_service"
+)
+
+// ChunkChecker checks that the block being built fits in a chunk of its own, in a
+// batch of its own, of the codec and the chunk rules, so that every block the sequencer
+// produces can be committed to L1. It implements miner.ChunkConstraintChecker.
+type ChunkChecker struct {
+	codec rollup_sync_service.Codec
+	rules rollup_sync_service.ChunkRules
+
+	number          uint64                         // number of the block being built
+	firstL1MsgIndex uint64                         // queue index of the first L1 message the block may pop
+	stats           rollup_sync_service.ChunkStats // stats of a chunk of the transactions included so far
+}
+
+// NewChunkChecker creates a checker of the limits of the given codec and chunk rules.
+func NewChunkChecker(codec rollup_sync_service.Codec, rules rollup_sync_service.ChunkRules) *ChunkChecker {
+	return &ChunkChecker{codec: codec, rules: rules}
+}
+
+// Reset starts checking a new block, whose L1 messages start at the given queue index.
+func (c *ChunkChecker) Reset(header *types.Header, nextL1MsgIndex uint64) {
+	c.number = header.Number.Uint64()
+	c.firstL1MsgIndex = nextL1MsgIndex
+
+	// a block without transactions only adds its block context, which always encodes
+	size, _ := c.codec.EncodedBlockSize(&rollup_sync_service.WrappedBlock{Header: header})
+	c.stats = rollup_sync_service.ChunkStats{Blocks: 1, PayloadBytes: c.codec.DALimits().ChunkOverheadBytes + size}
+}
+
+// Check returns an error if including the transaction makes the block violate a limit.
+func (c *ChunkChecker) Check(tx *types.Transaction) error {
+	stats, err := c.extend(tx)
+	if err != nil {
+		return err
+	}
+	if limit := c.codec.DALimits().MaxBatchBytes; stats.PayloadBytes > limit {
+		return fmt.Errorf("batch limit violated: %d payload bytes exceed %d", stats.PayloadBytes, limit)
+	}
+	return c.rules.Check(c.number, stats)
+}
+
+// Commit records that the checked transaction was included in the block.
+func (c *ChunkChecker) Commit(tx *types.Transaction) {
+	if stats, err := c.extend(tx); err == nil {
+		c.stats = stats
+	}
+}
+
+// extend returns the stats of the block with the transaction included.
+func (c *ChunkChecker) extend(tx *types.Transaction) (rollup_sync_service.ChunkStats, error) {
+	stats := c.stats
+	if tx.IsL1MessageTx() {
+		// the chunk also pops the L1 messages skipped before the message
+		if popped := tx.AsL1MessageTx().QueueIndex + 1 - c.firstL1MsgIndex; popped > stats.L1Messages {
+			stats.L1Messages = popped
+		}
+	}
+	size, err := c.codec.EncodedTxSize(tx)
+	if err != nil {
+		return rollup_sync_service.ChunkStats{}, err
+	}
+	stats.PayloadBytes += size
+	return stats, nil
+}
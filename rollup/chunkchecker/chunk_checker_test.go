@@ -0,0 +1,65 @@
+package chunkchecker
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/scroll-tech/go-ethereum/common"
+	"github.com/scroll-tech/go-ethereum/core/types"
+	"github.com/scroll-tech/go-ethereum/rollup/rollup_sync_service"
+)
+
+// limitedCodec overrides the DA limits of a codec.
+type limitedCodec struct {
+	rollup_sync_service.Codec
+	limits rollup_sync_service.DALimits
+}
+
+func (c limitedCodec) DALimits() rollup_sync_service.DALimits { return c.limits }
+
+func TestChunkChecker(t *testing.T) {
+	codec, err := rollup_sync_service.CodecForVersion(0)
+	require.NoError(t, err)
+
+	l1Message := func(queueIndex uint64) *types.Transaction {
+		return types.NewTx(&types.L1MessageTx{QueueIndex: queueIndex, Gas: 21000, To: &common.Address{1}})
+	}
+	l2Tx := types.NewTransaction(0, common.Address{1}, big.NewInt(0), 21000, big.NewInt(1), make([]byte, 100))
+	l2TxSize, err := codec.EncodedTxSize(l2Tx)
+	require.NoError(t, err)
+	blockSize, err := codec.EncodedBlockSize(&rollup_sync_service.WrappedBlock{Header: &types.Header{Number: big.NewInt(10)}})
+	require.NoError(t, err)
+
+	// the block pops the L1 messages 5 to 7, including the skipped message 6
+	checker := NewChunkChecker(codec, rollup_sync_service.NewChunkRules([]rollup_sync_service.ChunkLimits{{MaxL1Messages: 3, MaxPayloadBytes: 1 + blockSize + 2*l2TxSize}}))
+	checker.Reset(&types.Header{Number: big.NewInt(10)}, 5)
+	require.NoError(t, checker.Check(l1Message(5)))
+	checker.Commit(l1Message(5))
+	require.NoError(t, checker.Check(l1Message(7)))
+	checker.Commit(l1Message(7))
+	assert.Error(t, checker.Check(l1Message(8)))
+	assert.Equal(t, rollup_sync_service.ChunkStats{Blocks: 1, L1Messages: 3, PayloadBytes: 1 + blockSize}, checker.stats)
+
+	// transactions which are checked but not committed don't count
+	for i := 0; i < 3; i++ {
+		require.NoError(t, checker.Check(l2Tx))
+	}
+	checker.Commit(l2Tx)
+	checker.Commit(l2Tx)
+	assert.Error(t, checker.Check(l2Tx))
+
+	// a new block starts with empty stats
+	checker.Reset(&types.Header{Number: big.NewInt(11)}, 8)
+	assert.NoError(t, checker.Check(l1Message(8)))
+	assert.NoError(t, checker.Check(l2Tx))
+
+	// the payload of a block must also fit in a batch of the codec
+	limits := rollup_sync_service.DALimits{MaxBlocksPerChunk: 255, MaxChunksPerBatch: 15, MaxBatchBytes: 1 + blockSize + l2TxSize - 1, ChunkOverheadBytes: 1}
+	checker = NewChunkChecker(limitedCodec{Codec: codec, limits: limits}, rollup_sync_service.DefaultChunkRules)
+	checker.Reset(&types.Header{Number: big.NewInt(10)}, 0)
+	assert.NoError(t, checker.Check(l1Message(0)))
+	assert.Error(t, checker.Check(l2Tx))
+}
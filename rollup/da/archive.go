@@ -0,0 +1,52 @@
+package da
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/scroll-tech/go-ethereum/common"
+	"github.com/scroll-tech/go-ethereum/common/hexutil"
+	"github.com/scroll-tech/go-ethereum/crypto/kzg4844"
+)
+
+// ArchiveClient retrieves blobs from a blobscan-style archive API, which serves each blob
+// at /blobs/<versioned hash> and keeps blobs beyond the retention period of beacon nodes.
+type ArchiveClient struct {
+	endpoint string
+	client   *http.Client
+}
+
+// NewArchiveClient creates a client of the archive API at endpoint.
+func NewArchiveClient(endpoint string) *ArchiveClient {
+	return &ArchiveClient{
+		endpoint: strings.TrimSuffix(endpoint, "/"),
+		client:   &http.Client{Timeout: defaultHTTPTimeout},
+	}
+}
+
+type archiveBlobResponse struct {
+	Data hexutil.Bytes `json:"data"`
+}
+
+// Blob implements BlobProvider.
+func (c *ArchiveClient) Blob(ctx context.Context, versionedHash common.Hash, blockTime uint64) (*kzg4844.Blob, error) {
+	body, err := httpGet(ctx, c.client, c.endpoint+"/blobs/"+versionedHash.Hex())
+	if err != nil {
+		return nil, fmt.Errorf("failed to request blob %v: %w", versionedHash.Hex(), err)
+	}
+	var resp archiveBlobResponse
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return nil, fmt.Errorf("failed to decode blob %v: %w", versionedHash.Hex(), err)
+	}
+	blob, err := toBlob(resp.Data)
+	if err != nil {
+		return nil, err
+	}
+	if err := verifyBlob(blob, versionedHash); err != nil {
+		return nil, err
+	}
+	return blob, nil
+}
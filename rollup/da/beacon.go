@@ -0,0 +1,131 @@
+package da
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/scroll-tech/go-ethereum/common"
+	"github.com/scroll-tech/go-ethereum/common/hexutil"
+	"github.com/scroll-tech/go-ethereum/crypto/kzg4844"
+)
+
+// BeaconNodeClient retrieves blobs from the blob sidecars API of a beacon node. Beacon
+// nodes serve the blobs of the recent slots only.
+type BeaconNodeClient struct {
+	endpoint string
+	client   *http.Client
+
+	lock           sync.Mutex // protects the chain parameters, queried on first use
+	genesisTime    uint64
+	secondsPerSlot uint64
+}
+
+// NewBeaconNodeClient creates a client of the beacon node API at endpoint.
+func NewBeaconNodeClient(endpoint string) *BeaconNodeClient {
+	return &BeaconNodeClient{
+		endpoint: strings.TrimSuffix(endpoint, "/"),
+		client:   &http.Client{Timeout: defaultHTTPTimeout},
+	}
+}
+
+type beaconGenesisResponse struct {
+	Data struct {
+		GenesisTime string `json:"genesis_time"`
+	} `json:"data"`
+}
+
+type beaconSpecResponse struct {
+	Data struct {
+		SecondsPerSlot string `json:"SECONDS_PER_SLOT"`
+	} `json:"data"`
+}
+
+type blobSidecarsResponse struct {
+	Data []struct {
+		Blob          hexutil.Bytes `json:"blob"`
+		KZGCommitment hexutil.Bytes `json:"kzg_commitment"`
+	} `json:"data"`
+}
+
+// Blob implements BlobProvider, it requests the blob sidecars of the slot of blockTime.
+func (c *BeaconNodeClient) Blob(ctx context.Context, versionedHash common.Hash, blockTime uint64) (*kzg4844.Blob, error) {
+	slot, err := c.slot(ctx, blockTime)
+	if err != nil {
+		return nil, err
+	}
+	body, err := httpGet(ctx, c.client, fmt.Sprintf("%s/eth/v1/beacon/blob_sidecars/%d", c.endpoint, slot))
+	if err != nil {
+		return nil, fmt.Errorf("failed to request blob sidecars of slot %d: %w", slot, err)
+	}
+	var sidecars blobSidecarsResponse
+	if err := json.Unmarshal(body, &sidecars); err != nil {
+		return nil, fmt.Errorf("failed to decode blob sidecars of slot %d: %w", slot, err)
+	}
+	for _, sidecar := range sidecars.Data {
+		var commitment kzg4844.Commitment
+		if len(sidecar.KZGCommitment) != len(commitment) {
+			return nil, fmt.Errorf("invalid commitment length %d in slot %d", len(sidecar.KZGCommitment), slot)
+		}
+		copy(commitment[:], sidecar.KZGCommitment)
+		if common.Hash(kzg4844.CalcBlobHashV1(sha256.New(), &commitment)) != versionedHash {
+			continue
+		}
+		blob, err := toBlob(sidecar.Blob)
+		if err != nil {
+			return nil, err
+		}
+		if err := verifyBlob(blob, versionedHash); err != nil {
+			return nil, err
+		}
+		return blob, nil
+	}
+	return nil, fmt.Errorf("%w in sidecars of slot %d", ErrBlobNotFound, slot)
+}
+
+// slot returns the beacon slot of the given timestamp.
+func (c *BeaconNodeClient) slot(ctx context.Context, blockTime uint64) (uint64, error) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if c.secondsPerSlot == 0 {
+		var genesis beaconGenesisResponse
+		if err := c.query(ctx, "/eth/v1/beacon/genesis", &genesis); err != nil {
+			return 0, err
+		}
+		genesisTime, err := strconv.ParseUint(genesis.Data.GenesisTime, 10, 64)
+		if err != nil {
+			return 0, fmt.Errorf("invalid beacon genesis time %q: %w", genesis.Data.GenesisTime, err)
+		}
+		var spec beaconSpecResponse
+		if err := c.query(ctx, "/eth/v1/config/spec", &spec); err != nil {
+			return 0, err
+		}
+		secondsPerSlot, err := strconv.ParseUint(spec.Data.SecondsPerSlot, 10, 64)
+		if err != nil || secondsPerSlot == 0 {
+			return 0, fmt.Errorf("invalid beacon seconds per slot %q", spec.Data.SecondsPerSlot)
+		}
+		c.genesisTime, c.secondsPerSlot = genesisTime, secondsPerSlot
+	}
+	if blockTime < c.genesisTime {
+		return 0, errors.New("block time before beacon genesis")
+	}
+	return (blockTime - c.genesisTime) / c.secondsPerSlot, nil
+}
+
+func (c *BeaconNodeClient) query(ctx context.Context, path string, result interface{}) error {
+	body, err := httpGet(ctx, c.client, c.endpoint+path)
+	if err != nil {
+		return fmt.Errorf("failed to query %s: %w", path, err)
+	}
+	if err := json.Unmarshal(body, result); err != nil {
+		return fmt.Errorf("failed to decode %s: %w", path, err)
+	}
+	return nil
+}
@@ -0,0 +1,34 @@
+package da
+
+import (
+	"context"
+
+	lru "github.com/hashicorp/golang-lru"
+
+	"github.com/scroll-tech/go-ethereum/common"
+	"github.com/scroll-tech/go-ethereum/crypto/kzg4844"
+)
+
+// cachedProvider keeps the recently retrieved blobs of a provider in memory.
+type cachedProvider struct {
+	provider BlobProvider
+	cache    *lru.Cache // versioned hash -> *kzg4844.Blob
+}
+
+// NewCachedProvider creates a blob provider that caches up to size blobs of provider.
+func NewCachedProvider(provider BlobProvider, size int) BlobProvider {
+	cache, _ := lru.New(size)
+	return &cachedProvider{provider: provider, cache: cache}
+}
+
+func (p *cachedProvider) Blob(ctx context.Context, versionedHash common.Hash, blockTime uint64) (*kzg4844.Blob, error) {
+	if blob, ok := p.cache.Get(versionedHash); ok {
+		return blob.(*kzg4844.Blob), nil
+	}
+	blob, err := p.provider.Blob(ctx, versionedHash, blockTime)
+	if err != nil {
+		return nil, err
+	}
+	p.cache.Add(versionedHash, blob)
+	return blob, nil
+}
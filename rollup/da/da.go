@@ -0,0 +1,136 @@
+// Package da retrieves the data that batches publish to the L1 data availability layer.
+package da
+
+import (
+	"context"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/scroll-tech/go-ethereum/common"
+	"github.com/scroll-tech/go-ethereum/crypto/kzg4844"
+)
+
+const (
+	// defaultHTTPTimeout is the maximum duration of a single request to a blob source.
+	defaultHTTPTimeout = 30 * time.Second
+
+	// maxResponseSize bounds the body of a response from a blob source, a beacon node response
+	// carries up to a few blobs, hex encoded.
+	maxResponseSize = 16 * 1024 * 1024
+
+	// defaultBlobCacheSize is the number of blobs kept in memory if no size is configured.
+	defaultBlobCacheSize = 64
+)
+
+// ErrBlobNotFound is returned by a BlobProvider that does not store the requested blob.
+var ErrBlobNotFound = errors.New("blob not found")
+
+// BlobProvider retrieves blobs by their versioned hash. Beacon nodes prune blobs after
+// about 18 days, so older blobs have to be served by archives or mirrors.
+type BlobProvider interface {
+	// Blob returns the blob with the given versioned hash. blockTime is the timestamp of the
+	// L1 block that included the blob, it is used by sources indexed by beacon slot.
+	// The returned blob is verified against the versioned hash.
+	Blob(ctx context.Context, versionedHash common.Hash, blockTime uint64) (*kzg4844.Blob, error)
+}
+
+// Config lists the blob sources of a node. The sources are queried in the order beacon
+// nodes, archives, mirrors, the first one serving a blob wins.
+type Config struct {
+	BeaconNodeEndpoints []string `toml:",omitempty"` // beacon node APIs, e.g. http://localhost:5052
+	ArchiveEndpoints    []string `toml:",omitempty"` // blobscan-style archive APIs, e.g. https://api.blobscan.com
+	MirrorURLs          []string `toml:",omitempty"` // blob mirrors: file://, s3:// or http(s):// URLs
+	CacheSize           int      `toml:",omitempty"` // number of blobs cached in memory, defaults to 64
+
+	// DiskCacheDir is the directory of the blobs persisted across restarts, disabled if empty.
+	DiskCacheDir  string `toml:",omitempty"`
+	DiskCacheSize uint64 `toml:",omitempty"` // size limit of the persisted blobs in bytes
+}
+
+// New creates a blob provider querying the sources of the config in order, with the
+// recently retrieved blobs cached in memory and, if configured, on disk.
+func New(config *Config) (BlobProvider, error) {
+	var providers []BlobProvider
+	for _, endpoint := range config.BeaconNodeEndpoints {
+		providers = append(providers, NewBeaconNodeClient(endpoint))
+	}
+	for _, endpoint := range config.ArchiveEndpoints {
+		providers = append(providers, NewArchiveClient(endpoint))
+	}
+	for _, rawurl := range config.MirrorURLs {
+		mirror, err := NewMirrorClient(rawurl)
+		if err != nil {
+			return nil, err
+		}
+		providers = append(providers, mirror)
+	}
+	if len(providers) == 0 {
+		return nil, errors.New("no blob sources configured")
+	}
+	provider := NewFallbackProvider(providers...)
+	if config.DiskCacheDir != "" {
+		cache, err := NewDiskCache(config.DiskCacheDir, config.DiskCacheSize)
+		if err != nil {
+			return nil, err
+		}
+		provider = NewDiskCachedProvider(provider, cache)
+	}
+	cacheSize := config.CacheSize
+	if cacheSize <= 0 {
+		cacheSize = defaultBlobCacheSize
+	}
+	return NewCachedProvider(provider, cacheSize), nil
+}
+
+// verifyBlob checks that the blob matches the versioned hash.
+func verifyBlob(blob *kzg4844.Blob, versionedHash common.Hash) error {
+	commitment, err := kzg4844.BlobToCommitment(*blob)
+	if err != nil {
+		return fmt.Errorf("failed to compute blob commitment: %w", err)
+	}
+	if hash := common.Hash(kzg4844.CalcBlobHashV1(sha256.New(), &commitment)); hash != versionedHash {
+		return fmt.Errorf("blob does not match versioned hash %v, got %v", versionedHash.Hex(), hash.Hex())
+	}
+	return nil
+}
+
+// toBlob converts the raw bytes of a blob.
+func toBlob(data []byte) (*kzg4844.Blob, error) {
+	blob := new(kzg4844.Blob)
+	if len(data) != len(blob) {
+		return nil, fmt.Errorf("invalid blob length %d, expected %d", len(data), len(blob))
+	}
+	copy(blob[:], data)
+	return blob, nil
+}
+
+// httpGet requests the resource at url, a missing resource is reported as ErrBlobNotFound.
+func httpGet(ctx context.Context, client *http.Client, url string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, ErrBlobNotFound
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status %s from %s", resp.Status, url)
+	}
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxResponseSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(body) > maxResponseSize {
+		return nil, fmt.Errorf("response from %s exceeds %d bytes", url, maxResponseSize)
+	}
+	return body, nil
+}
@@ -0,0 +1,203 @@
+package da
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/scroll-tech/go-ethereum/common"
+	"github.com/scroll-tech/go-ethereum/common/hexutil"
+	"github.com/scroll-tech/go-ethereum/crypto/kzg4844"
+)
+
+// testBlob returns a blob with the given seed and its commitment and versioned hash.
+func testBlob(t *testing.T, seed byte) (*kzg4844.Blob, kzg4844.Commitment, common.Hash) {
+	blob := new(kzg4844.Blob)
+	for i := 0; i < len(blob); i += 32 {
+		// the first byte of each field element is left zero to stay below the modulus
+		blob[i+1] = seed
+		blob[i+31] = byte(i / 32)
+	}
+	commitment, err := kzg4844.BlobToCommitment(*blob)
+	require.NoError(t, err)
+	return blob, commitment, kzg4844.CalcBlobHashV1(sha256.New(), &commitment)
+}
+
+// countingProvider serves a fixed set of blobs and counts the requests.
+type countingProvider struct {
+	blobs    map[common.Hash]*kzg4844.Blob
+	err      error
+	requests int
+}
+
+func (p *countingProvider) Blob(ctx context.Context, versionedHash common.Hash, blockTime uint64) (*kzg4844.Blob, error) {
+	p.requests++
+	if p.err != nil {
+		return nil, p.err
+	}
+	if blob, ok := p.blobs[versionedHash]; ok {
+		return blob, nil
+	}
+	return nil, ErrBlobNotFound
+}
+
+func TestBeaconNodeClient(t *testing.T) {
+	blob, commitment, versionedHash := testBlob(t, 1)
+	other, otherCommitment, _ := testBlob(t, 2)
+	var requestedSlots []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/eth/v1/beacon/genesis":
+			fmt.Fprint(w, `{"data":{"genesis_time":"1000"}}`)
+		case "/eth/v1/config/spec":
+			fmt.Fprint(w, `{"data":{"SECONDS_PER_SLOT":"12"}}`)
+		case "/eth/v1/beacon/blob_sidecars/10":
+			requestedSlots = append(requestedSlots, "10")
+			json.NewEncoder(w).Encode(map[string]interface{}{"data": []map[string]interface{}{
+				{"blob": hexutil.Bytes(other[:]), "kzg_commitment": hexutil.Bytes(otherCommitment[:])},
+				{"blob": hexutil.Bytes(blob[:]), "kzg_commitment": hexutil.Bytes(commitment[:])},
+			}})
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	c := NewBeaconNodeClient(srv.URL + "/")
+	got, err := c.Blob(context.Background(), versionedHash, 1000+10*12+5)
+	require.NoError(t, err)
+	assert.Equal(t, blob, got)
+	assert.Equal(t, []string{"10"}, requestedSlots)
+
+	// blobs of other slots and pruned slots are not found
+	_, err = c.Blob(context.Background(), common.Hash{0x01}, 1000+10*12)
+	assert.ErrorIs(t, err, ErrBlobNotFound)
+	_, err = c.Blob(context.Background(), versionedHash, 1000+11*12)
+	assert.ErrorIs(t, err, ErrBlobNotFound)
+	_, err = c.Blob(context.Background(), versionedHash, 999)
+	assert.Error(t, err)
+}
+
+func TestArchiveClient(t *testing.T) {
+	blob, _, versionedHash := testBlob(t, 1)
+	_, _, otherHash := testBlob(t, 2)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/blobs/" + versionedHash.Hex():
+			json.NewEncoder(w).Encode(map[string]interface{}{"versionedHash": versionedHash, "data": hexutil.Bytes(blob[:])})
+		case "/blobs/" + otherHash.Hex():
+			// the archive serves the wrong blob
+			json.NewEncoder(w).Encode(map[string]interface{}{"data": hexutil.Bytes(blob[:])})
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	c := NewArchiveClient(srv.URL)
+	got, err := c.Blob(context.Background(), versionedHash, 0)
+	require.NoError(t, err)
+	assert.Equal(t, blob, got)
+
+	_, err = c.Blob(context.Background(), otherHash, 0)
+	assert.Error(t, err)
+	assert.NotErrorIs(t, err, ErrBlobNotFound)
+	_, err = c.Blob(context.Background(), common.Hash{0x01}, 0)
+	assert.ErrorIs(t, err, ErrBlobNotFound)
+}
+
+func TestMirrorClient(t *testing.T) {
+	blob, _, versionedHash := testBlob(t, 1)
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, versionedHash.Hex()), blob[:], 0644))
+
+	c, err := NewMirrorClient("file://" + filepath.ToSlash(dir))
+	require.NoError(t, err)
+	got, err := c.Blob(context.Background(), versionedHash, 0)
+	require.NoError(t, err)
+	assert.Equal(t, blob, got)
+	_, err = c.Blob(context.Background(), common.Hash{0x01}, 0)
+	assert.ErrorIs(t, err, ErrBlobNotFound)
+
+	srv := httptest.NewServer(http.FileServer(http.Dir(dir)))
+	defer srv.Close()
+	c, err = NewMirrorClient(srv.URL + "/")
+	require.NoError(t, err)
+	got, err = c.Blob(context.Background(), versionedHash, 0)
+	require.NoError(t, err)
+	assert.Equal(t, blob, got)
+
+	c, err = NewMirrorClient("s3://blobs/mainnet/")
+	require.NoError(t, err)
+	assert.Equal(t, "https://blobs.s3.amazonaws.com/mainnet", c.baseURL)
+
+	for _, rawurl := range []string{"ftp://localhost", "s3:///mainnet", "file://"} {
+		_, err = NewMirrorClient(rawurl)
+		assert.Error(t, err, rawurl)
+	}
+}
+
+func TestFallbackProvider(t *testing.T) {
+	blob, _, versionedHash := testBlob(t, 1)
+	failing := &countingProvider{err: errors.New("connection refused")}
+	empty := &countingProvider{}
+	archive := &countingProvider{blobs: map[common.Hash]*kzg4844.Blob{versionedHash: blob}}
+
+	p := NewFallbackProvider(failing, empty, archive)
+	got, err := p.Blob(context.Background(), versionedHash, 0)
+	require.NoError(t, err)
+	assert.Equal(t, blob, got)
+	assert.Equal(t, []int{1, 1, 1}, []int{failing.requests, empty.requests, archive.requests})
+
+	// the blob is only reported as not found if no source failed
+	_, err = p.Blob(context.Background(), common.Hash{0x01}, 0)
+	assert.Error(t, err)
+	assert.NotErrorIs(t, err, ErrBlobNotFound)
+	_, err = NewFallbackProvider(empty, archive).Blob(context.Background(), common.Hash{0x01}, 0)
+	assert.ErrorIs(t, err, ErrBlobNotFound)
+}
+
+func TestCachedProvider(t *testing.T) {
+	blob, _, versionedHash := testBlob(t, 1)
+	source := &countingProvider{blobs: map[common.Hash]*kzg4844.Blob{versionedHash: blob}}
+
+	p := NewCachedProvider(source, 1)
+	for i := 0; i < 3; i++ {
+		got, err := p.Blob(context.Background(), versionedHash, 0)
+		require.NoError(t, err)
+		assert.Equal(t, blob, got)
+	}
+	assert.Equal(t, 1, source.requests)
+
+	// errors are not cached
+	for i := 0; i < 2; i++ {
+		_, err := p.Blob(context.Background(), common.Hash{0x01}, 0)
+		assert.ErrorIs(t, err, ErrBlobNotFound)
+	}
+	assert.Equal(t, 3, source.requests)
+}
+
+func TestNew(t *testing.T) {
+	_, err := New(&Config{})
+	assert.Error(t, err)
+	_, err = New(&Config{MirrorURLs: []string{"ftp://localhost"}})
+	assert.Error(t, err)
+
+	p, err := New(&Config{BeaconNodeEndpoints: []string{"http://localhost:5052"}, ArchiveEndpoints: []string{"https://api.blobscan.com"}, MirrorURLs: []string{"s3://blobs"}})
+	require.NoError(t, err)
+	providers := p.(*cachedProvider).provider.(*fallbackProvider).providers
+	require.Len(t, providers, 3)
+	assert.IsType(t, &BeaconNodeClient{}, providers[0])
+	assert.IsType(t, &ArchiveClient{}, providers[1])
+	assert.IsType(t, &MirrorClient{}, providers[2])
+}
@@ -0,0 +1,218 @@
+package da
+
+import (
+	"container/list"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/scroll-tech/go-ethereum/common"
+	"github.com/scroll-tech/go-ethereum/crypto/kzg4844"
+	"github.com/scroll-tech/go-ethereum/log"
+)
+
+// DiskCache is a content-addressed store of L1 payloads, e.g. blobs keyed by their versioned
+// hash or transactions keyed by their hash, which persists across restarts. The least
+// recently used payloads are evicted once the total size exceeds the limit. Payloads are
+// not verified by the cache, readers verify them against their key.
+type DiskCache struct {
+	dir     string
+	maxSize uint64
+
+	lock    sync.Mutex
+	size    uint64
+	order   *list.List // keys, most recently used first
+	entries map[common.Hash]*list.Element
+	sizes   map[common.Hash]uint64
+}
+
+// NewDiskCache opens the cache in dir, which is created if missing, with the given size
+// limit in bytes. The usage order of the stored payloads is restored from their
+// modification times.
+func NewDiskCache(dir string, maxSize uint64) (*DiskCache, error) {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, fmt.Errorf("failed to create payload cache directory: %w", err)
+	}
+	files, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read payload cache directory: %w", err)
+	}
+	type storedEntry struct {
+		key     common.Hash
+		size    uint64
+		modTime time.Time
+	}
+	var stored []storedEntry
+	for _, file := range files {
+		var key common.Hash
+		if file.IsDir() || key.UnmarshalText([]byte(file.Name())) != nil {
+			// note: leftovers of interrupted writes are removed as well
+			os.Remove(filepath.Join(dir, file.Name()))
+			continue
+		}
+		info, err := file.Info()
+		if err != nil {
+			continue
+		}
+		stored = append(stored, storedEntry{key: key, size: uint64(info.Size()), modTime: info.ModTime()})
+	}
+	sort.Slice(stored, func(i, j int) bool { return stored[i].modTime.After(stored[j].modTime) })
+
+	c := &DiskCache{
+		dir:     dir,
+		maxSize: maxSize,
+		order:   list.New(),
+		entries: make(map[common.Hash]*list.Element),
+		sizes:   make(map[common.Hash]uint64),
+	}
+	for _, entry := range stored {
+		c.entries[entry.key] = c.order.PushBack(entry.key)
+		c.sizes[entry.key] = entry.size
+		c.size += entry.size
+	}
+	c.lock.Lock()
+	c.evict()
+	c.lock.Unlock()
+	log.Info("Opened L1 payload cache", "dir", dir, "payloads", len(c.entries), "size", common.StorageSize(c.size), "limit", common.StorageSize(maxSize))
+	return c, nil
+}
+
+// Get returns the payload stored with the given key.
+func (c *DiskCache) Get(key common.Hash) ([]byte, bool) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	elem, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+	path := c.path(key)
+	data, err := os.ReadFile(path)
+	if err != nil {
+		log.Warn("Failed to read cached L1 payload", "key", key, "err", err)
+		c.remove(key)
+		return nil, false
+	}
+	c.order.MoveToFront(elem)
+	now := time.Now()
+	os.Chtimes(path, now, now)
+	return data, true
+}
+
+// Put stores a payload with the given key, payloads larger than the size limit are not stored.
+func (c *DiskCache) Put(key common.Hash, data []byte) error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if _, ok := c.entries[key]; ok || uint64(len(data)) > c.maxSize {
+		return nil
+	}
+	tmp, err := os.CreateTemp(c.dir, "tmp-*")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := os.Rename(tmp.Name(), c.path(key)); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	c.entries[key] = c.order.PushFront(key)
+	c.sizes[key] = uint64(len(data))
+	c.size += uint64(len(data))
+	c.evict()
+	return nil
+}
+
+// Delete removes the payload stored with the given key, e.g. if it fails verification.
+func (c *DiskCache) Delete(key common.Hash) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if _, ok := c.entries[key]; ok {
+		os.Remove(c.path(key))
+		c.remove(key)
+	}
+}
+
+// Size returns the total size of the stored payloads in bytes.
+func (c *DiskCache) Size() uint64 {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return c.size
+}
+
+// evict removes the least recently used payloads until the size limit is met, the lock
+// must be held.
+func (c *DiskCache) evict() {
+	for c.size > c.maxSize {
+		oldest := c.order.Back()
+		if oldest == nil {
+			return
+		}
+		key := oldest.Value.(common.Hash)
+		if err := os.Remove(c.path(key)); err != nil && !os.IsNotExist(err) {
+			log.Warn("Failed to evict cached L1 payload", "key", key, "err", err)
+		}
+		c.remove(key)
+	}
+}
+
+// remove drops the entry of a payload, the lock must be held.
+func (c *DiskCache) remove(key common.Hash) {
+	if elem, ok := c.entries[key]; ok {
+		c.order.Remove(elem)
+		delete(c.entries, key)
+		c.size -= c.sizes[key]
+		delete(c.sizes, key)
+	}
+}
+
+func (c *DiskCache) path(key common.Hash) string {
+	return filepath.Join(c.dir, key.Hex())
+}
+
+// diskCachedProvider stores the blobs retrieved by a provider in a disk cache.
+type diskCachedProvider struct {
+	provider BlobProvider
+	cache    *DiskCache
+}
+
+// NewDiskCachedProvider creates a blob provider that serves the blobs of provider from the
+// disk cache once they have been retrieved.
+func NewDiskCachedProvider(provider BlobProvider, cache *DiskCache) BlobProvider {
+	return &diskCachedProvider{provider: provider, cache: cache}
+}
+
+func (p *diskCachedProvider) Blob(ctx context.Context, versionedHash common.Hash, blockTime uint64) (*kzg4844.Blob, error) {
+	if data, ok := p.cache.Get(versionedHash); ok {
+		blob, err := toBlob(data)
+		if err == nil {
+			err = verifyBlob(blob, versionedHash)
+		}
+		if err == nil {
+			return blob, nil
+		}
+		log.Warn("Dropping corrupted cached blob", "versioned hash", versionedHash, "err", err)
+		p.cache.Delete(versionedHash)
+	}
+	blob, err := p.provider.Blob(ctx, versionedHash, blockTime)
+	if err != nil {
+		return nil, err
+	}
+	if err := p.cache.Put(versionedHash, blob[:]); err != nil {
+		log.Warn("Failed to cache blob", "versioned hash", versionedHash, "err", err)
+	}
+	return blob, nil
+}
@@ -0,0 +1,90 @@
+package da
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/scroll-tech/go-ethereum/common"
+	"github.com/scroll-tech/go-ethereum/crypto/kzg4844"
+)
+
+func TestDiskCache(t *testing.T) {
+	dir := t.TempDir()
+	cache, err := NewDiskCache(dir, 10)
+	require.NoError(t, err)
+
+	require.NoError(t, cache.Put(common.Hash{1}, []byte("aaaa")))
+	require.NoError(t, cache.Put(common.Hash{2}, []byte("bbbb")))
+	data, ok := cache.Get(common.Hash{1})
+	require.True(t, ok)
+	assert.Equal(t, []byte("aaaa"), data)
+
+	// the least recently used payload is evicted, payloads above the limit are not stored
+	require.NoError(t, cache.Put(common.Hash{3}, []byte("cccc")))
+	_, ok = cache.Get(common.Hash{2})
+	assert.False(t, ok)
+	require.NoError(t, cache.Put(common.Hash{4}, make([]byte, 11)))
+	_, ok = cache.Get(common.Hash{4})
+	assert.False(t, ok)
+	assert.Equal(t, uint64(8), cache.Size())
+
+	// the payloads and their usage order persist, leftovers of interrupted writes are removed
+	past := time.Now().Add(-time.Hour)
+	require.NoError(t, os.Chtimes(filepath.Join(dir, common.Hash{3}.Hex()), past, past))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "tmp-1"), []byte("x"), 0600))
+	cache, err = NewDiskCache(dir, 10)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(8), cache.Size())
+	_, err = os.Stat(filepath.Join(dir, "tmp-1"))
+	assert.True(t, os.IsNotExist(err))
+	require.NoError(t, cache.Put(common.Hash{5}, []byte("eeee")))
+	_, ok = cache.Get(common.Hash{3})
+	assert.False(t, ok)
+	data, ok = cache.Get(common.Hash{1})
+	require.True(t, ok)
+	assert.Equal(t, []byte("aaaa"), data)
+
+	// a smaller limit evicts on open
+	cache, err = NewDiskCache(dir, 4)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(4), cache.Size())
+	_, ok = cache.Get(common.Hash{1})
+	assert.True(t, ok)
+
+	cache.Delete(common.Hash{1})
+	_, ok = cache.Get(common.Hash{1})
+	assert.False(t, ok)
+	assert.Zero(t, cache.Size())
+}
+
+func TestDiskCachedProvider(t *testing.T) {
+	blob, _, versionedHash := testBlob(t, 1)
+	source := &countingProvider{blobs: map[common.Hash]*kzg4844.Blob{versionedHash: blob}}
+	dir := t.TempDir()
+	cache, err := NewDiskCache(dir, 1<<20)
+	require.NoError(t, err)
+
+	p := NewDiskCachedProvider(source, cache)
+	for i := 0; i < 2; i++ {
+		got, err := p.Blob(context.Background(), versionedHash, 0)
+		require.NoError(t, err)
+		assert.Equal(t, blob, got)
+	}
+	assert.Equal(t, 1, source.requests)
+
+	// corrupted blobs are retrieved again
+	require.NoError(t, os.WriteFile(filepath.Join(dir, versionedHash.Hex()), make([]byte, len(blob)), 0600))
+	got, err := p.Blob(context.Background(), versionedHash, 0)
+	require.NoError(t, err)
+	assert.Equal(t, blob, got)
+	assert.Equal(t, 2, source.requests)
+	data, ok := cache.Get(versionedHash)
+	require.True(t, ok)
+	assert.Equal(t, blob[:], data)
+}
@@ -0,0 +1,45 @@
+package da
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/scroll-tech/go-ethereum/common"
+	"github.com/scroll-tech/go-ethereum/crypto/kzg4844"
+	"github.com/scroll-tech/go-ethereum/log"
+)
+
+// fallbackProvider queries its providers in order until one of them returns the blob.
+type fallbackProvider struct {
+	providers []BlobProvider
+}
+
+// NewFallbackProvider creates a blob provider that queries the given providers in order.
+func NewFallbackProvider(providers ...BlobProvider) BlobProvider {
+	return &fallbackProvider{providers: providers}
+}
+
+func (p *fallbackProvider) Blob(ctx context.Context, versionedHash common.Hash, blockTime uint64) (*kzg4844.Blob, error) {
+	var (
+		errs     []string
+		notFound = true
+	)
+	for i, provider := range p.providers {
+		blob, err := provider.Blob(ctx, versionedHash, blockTime)
+		if err == nil {
+			return blob, nil
+		}
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		log.Debug("Blob source failed, trying next", "source", i, "versioned hash", versionedHash, "err", err)
+		errs = append(errs, fmt.Sprintf("source %d: %v", i, err))
+		notFound = notFound && errors.Is(err, ErrBlobNotFound)
+	}
+	if notFound {
+		return nil, fmt.Errorf("%w in any source: %s", ErrBlobNotFound, strings.Join(errs, "; "))
+	}
+	return nil, fmt.Errorf("failed to retrieve blob %v: %s", versionedHash.Hex(), strings.Join(errs, "; "))
+}
@@ -0,0 +1,78 @@
+package da
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/scroll-tech/go-ethereum/common"
+	"github.com/scroll-tech/go-ethereum/crypto/kzg4844"
+)
+
+// MirrorClient retrieves blobs from a mirror that stores each blob as an object named by its
+// versioned hash in hex, holding the raw blob bytes. A mirror is a local directory, an S3
+// bucket or any HTTP server, e.g. an S3-compatible store.
+type MirrorClient struct {
+	dir     string // set for directory mirrors
+	baseURL string // set for HTTP mirrors
+	client  *http.Client
+}
+
+// NewMirrorClient creates a client of the mirror at rawurl. Supported schemes are file://
+// (the path is the directory of the blobs), s3://<bucket>/<prefix> for public buckets and
+// http:// or https://.
+func NewMirrorClient(rawurl string) (*MirrorClient, error) {
+	u, err := url.Parse(rawurl)
+	if err != nil {
+		return nil, fmt.Errorf("invalid blob mirror url %q: %w", rawurl, err)
+	}
+	switch u.Scheme {
+	case "file":
+		if u.Path == "" {
+			return nil, fmt.Errorf("blob mirror url %q has no path", rawurl)
+		}
+		return &MirrorClient{dir: filepath.FromSlash(u.Path)}, nil
+	case "s3":
+		if u.Host == "" {
+			return nil, fmt.Errorf("blob mirror url %q has no bucket", rawurl)
+		}
+		baseURL := "https://" + u.Host + ".s3.amazonaws.com" + strings.TrimSuffix(u.Path, "/")
+		return &MirrorClient{baseURL: baseURL, client: &http.Client{Timeout: defaultHTTPTimeout}}, nil
+	case "http", "https":
+		return &MirrorClient{baseURL: strings.TrimSuffix(u.String(), "/"), client: &http.Client{Timeout: defaultHTTPTimeout}}, nil
+	default:
+		return nil, fmt.Errorf("unsupported blob mirror scheme: %q", u.Scheme)
+	}
+}
+
+// Blob implements BlobProvider.
+func (c *MirrorClient) Blob(ctx context.Context, versionedHash common.Hash, blockTime uint64) (*kzg4844.Blob, error) {
+	var (
+		data []byte
+		err  error
+	)
+	if c.dir != "" {
+		data, err = os.ReadFile(filepath.Join(c.dir, versionedHash.Hex()))
+		if errors.Is(err, os.ErrNotExist) {
+			err = ErrBlobNotFound
+		}
+	} else {
+		data, err = httpGet(ctx, c.client, c.baseURL+"/"+versionedHash.Hex())
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read blob %v: %w", versionedHash.Hex(), err)
+	}
+	blob, err := toBlob(data)
+	if err != nil {
+		return nil, err
+	}
+	if err := verifyBlob(blob, versionedHash); err != nil {
+		return nil, err
+	}
+	return blob, nil
+}
@@ -0,0 +1,67 @@
+package zstd
+
+import (
+	"bytes"
+	"errors"
+	"io"
+)
+
+// ErrTooLarge is returned if a stream decompresses to more bytes than the limit.
+var ErrTooLarge = errors.New("zstd: decompressed size exceeds limit")
+
+// limitedReader stops decompressing a stream once it exceeds the limit.
+type limitedReader struct {
+	r         *Reader
+	remaining uint64
+}
+
+// NewLimitedReader returns a reader streaming the decompressed data of the zstd stream in r,
+// which fails with ErrTooLarge once more than limit bytes are decompressed. A small payload
+// that expands to more data than any valid payload, a decompression bomb, is rejected without
+// decompressing it further. The memory of the decompressor is bounded by its window of 8 MiB.
+func NewLimitedReader(r io.Reader, limit uint64) io.Reader {
+	return &limitedReader{r: NewReader(r), remaining: limit}
+}
+
+func (l *limitedReader) Read(p []byte) (int, error) {
+	if len(p) == 0 {
+		return 0, nil
+	}
+	if l.remaining == 0 {
+		// the stream must end at the limit
+		var probe [1]byte
+		n, err := l.r.Read(probe[:])
+		if n > 0 {
+			return 0, ErrTooLarge
+		}
+		return 0, err
+	}
+	if uint64(len(p)) > l.remaining {
+		p = p[:l.remaining]
+	}
+	n, err := l.r.Read(p)
+	l.remaining -= uint64(n)
+	return n, err
+}
+
+// Decompress returns the decompressed data of a zstd stream, which must not exceed limit bytes.
+func Decompress(data []byte, limit uint64) ([]byte, error) {
+	var out bytes.Buffer
+	if _, err := io.Copy(&out, NewLimitedReader(bytes.NewReader(data), limit)); err != nil {
+		return nil, err
+	}
+	return out.Bytes(), nil
+}
+
+// DecompressFrame returns the decompressed data of the first frame of a zstd stream, which must
+// not exceed limit bytes. The data following the frame is not read, e.g. the zero padding of a
+// payload stored in a blob.
+func DecompressFrame(data []byte, limit uint64) ([]byte, error) {
+	r := NewReader(bytes.NewReader(data))
+	r.singleFrame = true
+	var out bytes.Buffer
+	if _, err := io.Copy(&out, &limitedReader{r: r, remaining: limit}); err != nil {
+		return nil, err
+	}
+	return out.Bytes(), nil
+}
@@ -0,0 +1,144 @@
+package zstd
+
+import (
+	"bytes"
+	"encoding/binary"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// rawFrame returns a zstd frame storing data in uncompressed blocks.
+func rawFrame(data []byte) []byte {
+	frame := []byte{0x28, 0xb5, 0x2f, 0xfd, 0xa0} // magic, single segment with 4 byte content size
+	frame = binary.LittleEndian.AppendUint32(frame, uint32(len(data)))
+	for {
+		size := len(data)
+		if size > 128*1024 {
+			size = 128 * 1024
+		}
+		header := uint32(size) << 3
+		if size == len(data) {
+			header |= 1 // last block
+		}
+		frame = append(frame, byte(header), byte(header>>8), byte(header>>16))
+		frame = append(frame, data[:size]...)
+		if data = data[size:]; len(data) == 0 {
+			return frame
+		}
+	}
+}
+
+func readTestdata(t testing.TB, name string) []byte {
+	data, err := os.ReadFile("testdata/" + name)
+	require.NoError(t, err)
+	return data
+}
+
+func TestDecompress(t *testing.T) {
+	payload := readTestdata(t, "payload.bin")
+	compressed := readTestdata(t, "payload.zst")
+
+	out, err := Decompress(compressed, uint64(len(payload)))
+	require.NoError(t, err)
+	assert.Equal(t, payload, out)
+
+	out, err = Decompress(rawFrame(payload), uint64(len(payload)))
+	require.NoError(t, err)
+	assert.Equal(t, payload, out)
+
+	_, err = Decompress(compressed, uint64(len(payload))-1)
+	assert.ErrorIs(t, err, ErrTooLarge)
+}
+
+func TestDecompressFrame(t *testing.T) {
+	payload := readTestdata(t, "payload.bin")
+	compressed := readTestdata(t, "payload.zst")
+
+	// the padding after the frame is not decoded
+	padded := append(append([]byte{}, compressed...), make([]byte, 1000)...)
+	out, err := DecompressFrame(padded, uint64(len(payload)))
+	require.NoError(t, err)
+	assert.Equal(t, payload, out)
+
+	_, err = Decompress(padded, uint64(len(payload)))
+	assert.Error(t, err)
+
+	_, err = DecompressFrame(padded, uint64(len(payload))-1)
+	assert.ErrorIs(t, err, ErrTooLarge)
+
+	_, err = DecompressFrame(compressed[:len(compressed)-1], uint64(len(payload)))
+	assert.Error(t, err)
+}
+
+func TestDecompressBomb(t *testing.T) {
+	// 53 bytes decompress to 1 MB of zeros
+	bomb := readTestdata(t, "zeros-1000000.zst")
+	_, err := Decompress(bomb, 128*1024)
+	assert.ErrorIs(t, err, ErrTooLarge)
+
+	out, err := Decompress(bomb, 1000000)
+	require.NoError(t, err)
+	assert.Equal(t, make([]byte, 1000000), out)
+}
+
+func TestDecompressMalformed(t *testing.T) {
+	compressed := readTestdata(t, "payload.zst")
+	for name, data := range map[string][]byte{
+		"truncated":    compressed[:len(compressed)/2],
+		"no magic":     compressed[4:],
+		"corrupted":    append(append([]byte{}, compressed[:100]...), bytes.Repeat([]byte{0xff}, 100)...),
+		"garbage tail": append(append([]byte{}, compressed...), 0x01, 0x02),
+	} {
+		_, err := Decompress(data, 1<<20)
+		assert.Error(t, err, name)
+	}
+}
+
+func TestLimitedReader(t *testing.T) {
+	payload := readTestdata(t, "payload.bin")
+
+	// the data up to the limit is streamed before the error
+	r := NewLimitedReader(bytes.NewReader(rawFrame(payload)), 1000)
+	buf := make([]byte, 600)
+	n, err := r.Read(buf)
+	require.NoError(t, err)
+	assert.Equal(t, payload[:n], buf[:n])
+	var total int
+	for total = n; err == nil; total += n {
+		n, err = r.Read(buf)
+	}
+	assert.ErrorIs(t, err, ErrTooLarge)
+	assert.Equal(t, 1000, total)
+}
+
+func FuzzDecompress(f *testing.F) {
+	payload := readTestdata(f, "payload.bin")
+	compressed := readTestdata(f, "payload.zst")
+	f.Add(compressed)
+	f.Add(compressed[:len(compressed)/2])
+	f.Add(readTestdata(f, "zeros-1000000.zst"))
+	f.Add(rawFrame(payload[:1000]))
+	f.Add(rawFrame(nil))
+
+	const limit = 64 * 1024
+	f.Fuzz(func(t *testing.T, data []byte) {
+		out, err := Decompress(data, limit)
+		if err != nil {
+			return
+		}
+		if len(out) > limit {
+			t.Fatalf("decompressed %d bytes, limit %d", len(out), limit)
+		}
+		// a payload within the limit decompresses the same with a higher limit
+		again, err := Decompress(data, 2*limit)
+		if err != nil {
+			t.Fatalf("payload failed with a higher limit: %v", err)
+		}
+		if !bytes.Equal(out, again) {
+			t.Fatal("payload decompressed differently with a higher limit")
+		}
+	})
+}
@@ -0,0 +1,101 @@
+package devnet
+
+import (
+	"context"
+	"time"
+
+	"github.com/scroll-tech/go-ethereum/common"
+	"github.com/scroll-tech/go-ethereum/core/rawdb"
+
+	"github.com/scroll-tech/go-ethereum/rollup/rollup_sync_service"
+)
+
+// apiTimeout bounds a simulation step requested over RPC, including the time the rollup
+// sync service takes to process it.
+const apiTimeout = 5 * time.Minute
+
+// API offers the simulator of a devnet in the devnet RPC namespace. It sends transactions
+// from the owner account of the contract, so it must not be exposed publicly.
+type API struct {
+	simulator *Simulator
+}
+
+// NewAPI creates the devnet RPC API of the simulator.
+func NewAPI(simulator *Simulator) *API {
+	return &API{simulator: simulator}
+}
+
+// rpcSimulatedBatch is a batch submitted by the simulator.
+type rpcSimulatedBatch struct {
+	BatchIndex           uint64      `json:"batchIndex"`
+	BatchHash            common.Hash `json:"batchHash"`
+	TotalL1MessagePopped uint64      `json:"totalL1MessagePopped"`
+	StateRoot            common.Hash `json:"stateRoot"`
+	WithdrawRoot         common.Hash `json:"withdrawRoot"`
+}
+
+func newRPCSimulatedBatch(batch *rollup_sync_service.SimulatedBatch) *rpcSimulatedBatch {
+	return &rpcSimulatedBatch{
+		BatchIndex:           batch.BatchIndex,
+		BatchHash:            batch.Meta.BatchHash,
+		TotalL1MessagePopped: batch.Meta.TotalL1MessagePopped,
+		StateRoot:            batch.Meta.StateRoot,
+		WithdrawRoot:         batch.Meta.WithdrawRoot,
+	}
+}
+
+// ImportGenesisBatch imports the genesis batch of the local chain.
+func (api *API) ImportGenesisBatch(ctx context.Context) (*rpcSimulatedBatch, error) {
+	ctx, cancel := context.WithTimeout(ctx, apiTimeout)
+	defer cancel()
+	batch, err := api.simulator.ImportGenesisBatch(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return newRPCSimulatedBatch(batch), nil
+}
+
+// CommitBatch commits the batch with the given index and chunks.
+func (api *API) CommitBatch(ctx context.Context, batchIndex uint64, chunks []*rawdb.ChunkBlockRange) (*rpcSimulatedBatch, error) {
+	ctx, cancel := context.WithTimeout(ctx, apiTimeout)
+	defer cancel()
+	batch, err := api.simulator.CommitBatch(ctx, batchIndex, chunks)
+	if err != nil {
+		return nil, err
+	}
+	return newRPCSimulatedBatch(batch), nil
+}
+
+// RevertBatches reverts the batch with the given index and all batches above it.
+func (api *API) RevertBatches(ctx context.Context, batchIndex uint64) error {
+	ctx, cancel := context.WithTimeout(ctx, apiTimeout)
+	defer cancel()
+	return api.simulator.RevertBatches(ctx, batchIndex)
+}
+
+// FinalizeBatch finalizes the committed batch with the given index.
+func (api *API) FinalizeBatch(ctx context.Context, batchIndex uint64) (*rpcSimulatedBatch, error) {
+	ctx, cancel := context.WithTimeout(ctx, apiTimeout)
+	defer cancel()
+	batch, err := api.simulator.FinalizeBatch(ctx, batchIndex)
+	if err != nil {
+		return nil, err
+	}
+	return newRPCSimulatedBatch(batch), nil
+}
+
+// SimulateRevert reverts the batch with the given index and all batches above it, re-commits
+// the given batches, each a list of chunks, in their place and finalizes them.
+func (api *API) SimulateRevert(ctx context.Context, batchIndex uint64, batches [][]*rawdb.ChunkBlockRange) ([]*rpcSimulatedBatch, error) {
+	ctx, cancel := context.WithTimeout(ctx, apiTimeout)
+	defer cancel()
+	finalized, err := api.simulator.SimulateRevert(ctx, batchIndex, batches)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]*rpcSimulatedBatch, len(finalized))
+	for i, batch := range finalized {
+		result[i] = newRPCSimulatedBatch(batch)
+	}
+	return result, nil
+}
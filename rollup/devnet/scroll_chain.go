@@ -0,0 +1,344 @@
+// Package devnet provides a minimal ScrollChain-compatible contract for running the
+// rollup sync services against a local L1 chain, e.g. anvil or geth --dev.
+package devnet
+
+import (
+	"encoding/hex"
+	"fmt"
+	"strings"
+
+	"github.com/scroll-tech/go-ethereum/accounts/abi"
+	"github.com/scroll-tech/go-ethereum/accounts/abi/bind"
+	"github.com/scroll-tech/go-ethereum/common"
+	"github.com/scroll-tech/go-ethereum/common/hexutil"
+	"github.com/scroll-tech/go-ethereum/core/asm"
+	"github.com/scroll-tech/go-ethereum/core/types"
+	"github.com/scroll-tech/go-ethereum/core/vm"
+
+	"github.com/scroll-tech/go-ethereum/rollup/rollup_sync_service"
+)
+
+// scrollChainSource is the runtime code of the mock ScrollChain contract. The placeholders in
+// braces are replaced by the function selectors and event IDs of the ScrollChain ABI.
+//
+// The contract keeps the calldata and event layout of ScrollChain, but does not verify batches:
+// the batch index of a header is read from its bytes 1 to 8, the hash of a finalized batch is the
+// hash of its header, and the hash of a committed batch is the hash of the commitBatch arguments.
+// Only the deployer may submit batches.
+//
+// Storage: slot 0 is the owner, slot 1 the last finalized batch index, and committedBatches,
+// finalizedStateRoots and withdrawRoots are mappings at slots 2, 3 and 4.
+const scrollChainSource = `
+PUSH 0
+CALLDATALOAD
+PUSH 224
+SHR
+DUP1
+PUSH {commitBatch}
+EQ
+JUMPI @commit_batch
+DUP1
+PUSH {finalizeBatchWithProof}
+EQ
+JUMPI @finalize_batch
+DUP1
+PUSH {revertBatch}
+EQ
+JUMPI @revert_batch
+DUP1
+PUSH {importGenesisBatch}
+EQ
+JUMPI @import_genesis_batch
+DUP1
+PUSH {lastFinalizedBatchIndex}
+EQ
+JUMPI @last_finalized_batch_index
+DUP1
+PUSH {committedBatches}
+EQ
+JUMPI @committed_batches
+DUP1
+PUSH {finalizedStateRoots}
+EQ
+JUMPI @finalized_state_roots
+DUP1
+PUSH {withdrawRoots}
+EQ
+JUMPI @withdraw_roots
+DUP1
+PUSH {isBatchFinalized}
+EQ
+JUMPI @is_batch_finalized
+DUP1
+PUSH {owner}
+EQ
+JUMPI @owner
+revert:
+PUSH 0
+DUP1
+REVERT
+
+commit_batch:
+{onlyOwner}
+;; the batch index follows the index in the parent batch header
+PUSH 36
+CALLDATALOAD
+PUSH 36
+ADD
+CALLDATALOAD
+{headerBatchIndex}
+PUSH 1
+ADD
+PUSH 4
+CALLDATASIZE
+SUB
+PUSH 4
+PUSH 0
+CALLDATACOPY
+PUSH 4
+CALLDATASIZE
+SUB
+PUSH 0
+SHA3
+DUP2
+{mapSlot 2}
+DUP2
+SWAP1
+SSTORE
+SWAP1
+PUSH {CommitBatch}
+PUSH 0
+PUSH 0
+LOG3
+STOP
+
+finalize_batch:
+{onlyOwner}
+{hashHeader}
+PUSH 0
+MLOAD
+{headerBatchIndex}
+PUSH 68
+CALLDATALOAD
+DUP2
+{mapSlot 3}
+SSTORE
+PUSH 100
+CALLDATALOAD
+DUP2
+{mapSlot 4}
+SSTORE
+PUSH 1
+SLOAD
+DUP2
+GT
+ISZERO
+JUMPI @finalize_batch_emit
+DUP1
+PUSH 1
+SSTORE
+finalize_batch_emit:
+PUSH 68
+CALLDATALOAD
+PUSH 0
+MSTORE
+PUSH 100
+CALLDATALOAD
+PUSH 32
+MSTORE
+PUSH {FinalizeBatch}
+PUSH 64
+PUSH 0
+LOG3
+STOP
+
+revert_batch:
+{onlyOwner}
+PUSH 4
+CALLDATALOAD
+PUSH 36
+ADD
+CALLDATALOAD
+{headerBatchIndex}
+PUSH 36
+CALLDATALOAD
+DUP2
+ADD
+SWAP1
+revert_batch_loop:
+DUP2
+DUP2
+LT
+ISZERO
+JUMPI @revert_batch_done
+DUP1
+{mapSlot 2}
+DUP1
+SLOAD
+PUSH 0
+DUP3
+SSTORE
+SWAP1
+POP
+DUP2
+PUSH {RevertBatch}
+PUSH 0
+PUSH 0
+LOG3
+PUSH 1
+ADD
+JUMP @revert_batch_loop
+revert_batch_done:
+STOP
+
+import_genesis_batch:
+{onlyOwner}
+{hashHeader}
+DUP1
+PUSH 0
+{mapSlot 2}
+SSTORE
+PUSH 36
+CALLDATALOAD
+DUP1
+PUSH 0
+{mapSlot 3}
+SSTORE
+DUP2
+PUSH 0
+PUSH {CommitBatch}
+PUSH 0
+PUSH 0
+LOG3
+PUSH 0
+MSTORE
+PUSH 0
+PUSH 32
+MSTORE
+PUSH 0
+PUSH {FinalizeBatch}
+PUSH 64
+PUSH 0
+LOG3
+STOP
+
+last_finalized_batch_index:
+PUSH 1
+SLOAD
+{returnWord}
+
+committed_batches:
+PUSH 4
+CALLDATALOAD
+{mapSlot 2}
+SLOAD
+{returnWord}
+
+finalized_state_roots:
+PUSH 4
+CALLDATALOAD
+{mapSlot 3}
+SLOAD
+{returnWord}
+
+withdraw_roots:
+PUSH 4
+CALLDATALOAD
+{mapSlot 4}
+SLOAD
+{returnWord}
+
+is_batch_finalized:
+PUSH 4
+CALLDATALOAD
+PUSH 1
+SLOAD
+LT
+ISZERO
+{returnWord}
+
+owner:
+PUSH 0
+SLOAD
+{returnWord}
+`
+
+// scrollChainMacros are the code fragments shared by the functions of scrollChainSource.
+var scrollChainMacros = []string{
+	// reverts unless the caller is the owner
+	"{onlyOwner}", "CALLER\nPUSH 0\nSLOAD\nEQ\nISZERO\nJUMPI @revert",
+	// copies the batch header of the first argument to memory and pushes its hash
+	"{hashHeader}", "PUSH 4\nCALLDATALOAD\nPUSH 4\nADD\nDUP1\nCALLDATALOAD\nSWAP1\nPUSH 32\nADD\nDUP2\nSWAP1\nPUSH 0\nCALLDATACOPY\nPUSH 0\nSHA3",
+	// replaces the first word of a batch header by its batch index
+	"{headerBatchIndex}", "PUSH 184\nSHR\nPUSH 0xffffffffffffffff\nAND",
+	// replaces a key by its storage slot in the mapping at slot 2, 3 or 4
+	"{mapSlot 2}", "PUSH 0\nMSTORE\nPUSH 2\nPUSH 32\nMSTORE\nPUSH 64\nPUSH 0\nSHA3",
+	"{mapSlot 3}", "PUSH 0\nMSTORE\nPUSH 3\nPUSH 32\nMSTORE\nPUSH 64\nPUSH 0\nSHA3",
+	"{mapSlot 4}", "PUSH 0\nMSTORE\nPUSH 4\nPUSH 32\nMSTORE\nPUSH 64\nPUSH 0\nSHA3",
+	// returns the top of the stack as a single word
+	"{returnWord}", "PUSH 0\nMSTORE\nPUSH 32\nPUSH 0\nRETURN",
+}
+
+// ScrollChainCode returns the deployment code of the mock ScrollChain contract. The deployer
+// becomes the owner of the contract, constructor arguments are ignored.
+func ScrollChainCode() ([]byte, error) {
+	scrollChainABI, err := rollup_sync_service.ScrollChainABI()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get scroll chain abi: %w", err)
+	}
+	runtime, err := assemble(scrollChainSource, scrollChainABI)
+	if err != nil {
+		return nil, err
+	}
+	if len(runtime) > 0xffff {
+		return nil, fmt.Errorf("runtime code too large: %d bytes", len(runtime))
+	}
+
+	// store the caller as owner and return the runtime code appended to the init code
+	const initCodeSize = 16
+	code := []byte{
+		byte(vm.CALLER), byte(vm.PUSH1), 0, byte(vm.SSTORE),
+		byte(vm.PUSH2), byte(len(runtime) >> 8), byte(len(runtime)), byte(vm.DUP1),
+		byte(vm.PUSH1), initCodeSize, byte(vm.PUSH1), 0, byte(vm.CODECOPY),
+		byte(vm.PUSH1), 0, byte(vm.RETURN),
+	}
+	return append(code, runtime...), nil
+}
+
+// assemble expands the macros and ABI placeholders of source and compiles it.
+func assemble(source string, scrollChainABI *abi.ABI) ([]byte, error) {
+	replacements := append([]string{}, scrollChainMacros...)
+	for name, method := range scrollChainABI.Methods {
+		replacements = append(replacements, "{"+name+"}", hexutil.Encode(method.ID))
+	}
+	for name, event := range scrollChainABI.Events {
+		replacements = append(replacements, "{"+name+"}", event.ID.Hex())
+	}
+	source = strings.NewReplacer(replacements...).Replace(source)
+	if i := strings.Index(source, "{"); i >= 0 {
+		return nil, fmt.Errorf("unknown placeholder at offset %d", i)
+	}
+
+	compiler := asm.NewCompiler(false)
+	compiler.Feed(asm.Lex([]byte(source), false))
+	bin, errs := compiler.Compile()
+	if len(errs) != 0 {
+		return nil, fmt.Errorf("failed to compile scroll chain contract: %v", errs[0])
+	}
+	return hex.DecodeString(bin)
+}
+
+// DeployScrollChain deploys the mock ScrollChain contract, the L2 chain ID is passed as the
+// constructor argument of ScrollChain.
+func DeployScrollChain(auth *bind.TransactOpts, backend bind.ContractBackend, l2ChainID uint64) (common.Address, *types.Transaction, error) {
+	scrollChainABI, err := rollup_sync_service.ScrollChainABI()
+	if err != nil {
+		return common.Address{}, nil, fmt.Errorf("failed to get scroll chain abi: %w", err)
+	}
+	code, err := ScrollChainCode()
+	if err != nil {
+		return common.Address{}, nil, err
+	}
+	address, tx, _, err := bind.DeployContract(auth, *scrollChainABI, code, backend, l2ChainID)
+	return address, tx, err
+}
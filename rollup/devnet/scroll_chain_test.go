@@ -0,0 +1,117 @@
+package devnet
+
+import (
+	"context"
+	"encoding/binary"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/scroll-tech/go-ethereum"
+	"github.com/scroll-tech/go-ethereum/accounts/abi/bind"
+	"github.com/scroll-tech/go-ethereum/accounts/abi/bind/backends"
+	"github.com/scroll-tech/go-ethereum/common"
+	"github.com/scroll-tech/go-ethereum/core"
+	"github.com/scroll-tech/go-ethereum/core/types"
+	"github.com/scroll-tech/go-ethereum/crypto"
+
+	"github.com/scroll-tech/go-ethereum/rollup/rollup_sync_service"
+)
+
+func batchHeader(batchIndex uint64) []byte {
+	header := make([]byte, 89)
+	binary.BigEndian.PutUint64(header[1:9], batchIndex)
+	return header
+}
+
+func TestScrollChain(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+	auth, err := bind.NewKeyedTransactorWithChainID(key, big.NewInt(1337))
+	require.NoError(t, err)
+	backend := backends.NewSimulatedBackend(core.GenesisAlloc{auth.From: {Balance: big.NewInt(1e18)}}, 10000000)
+	defer backend.Close()
+
+	address, _, err := DeployScrollChain(auth, backend, 534352)
+	require.NoError(t, err)
+	backend.Commit()
+
+	scrollChainABI, err := rollup_sync_service.ScrollChainABI()
+	require.NoError(t, err)
+	contract := bind.NewBoundContract(address, *scrollChainABI, backend, backend, backend)
+
+	transact := func(method string, params ...interface{}) (*types.Transaction, []*types.Log) {
+		tx, err := contract.Transact(auth, method, params...)
+		require.NoError(t, err)
+		backend.Commit()
+		receipt, err := backend.TransactionReceipt(context.Background(), tx.Hash())
+		require.NoError(t, err)
+		require.Equal(t, types.ReceiptStatusSuccessful, receipt.Status)
+		return tx, receipt.Logs
+	}
+	call := func(method string, params ...interface{}) interface{} {
+		var out []interface{}
+		require.NoError(t, contract.Call(nil, &out, method, params...))
+		return out[0]
+	}
+
+	assert.Equal(t, auth.From, call("owner").(common.Address))
+
+	// the genesis batch is committed and finalized at once
+	genesisStateRoot := common.HexToHash("0x01")
+	_, logs := transact("importGenesisBatch", batchHeader(0), genesisStateRoot)
+	require.Len(t, logs, 2)
+	commitEvent := new(rollup_sync_service.L1CommitBatchEvent)
+	require.NoError(t, rollup_sync_service.UnpackLog(scrollChainABI, commitEvent, "CommitBatch", *logs[0]))
+	assert.Equal(t, uint64(0), commitEvent.BatchIndex.Uint64())
+	assert.Equal(t, crypto.Keccak256Hash(batchHeader(0)), commitEvent.BatchHash)
+	finalizeEvent := new(rollup_sync_service.L1FinalizeBatchEvent)
+	require.NoError(t, rollup_sync_service.UnpackLog(scrollChainABI, finalizeEvent, "FinalizeBatch", *logs[1]))
+	assert.Equal(t, genesisStateRoot, finalizeEvent.StateRoot)
+	assert.Equal(t, genesisStateRoot, common.Hash(call("finalizedStateRoots", big.NewInt(0)).([32]byte)))
+
+	// committed batches follow their parent batch
+	for i := uint64(0); i < 3; i++ {
+		tx, logs := transact("commitBatch", uint8(0), batchHeader(i), [][]byte{{0x01}}, []byte{})
+		_, err := rollup_sync_service.DecodeCommitBatchCalldata(tx.Data())
+		require.NoError(t, err)
+		require.Len(t, logs, 1)
+		require.NoError(t, rollup_sync_service.UnpackLog(scrollChainABI, commitEvent, "CommitBatch", *logs[0]))
+		assert.Equal(t, i+1, commitEvent.BatchIndex.Uint64())
+		assert.Equal(t, crypto.Keccak256Hash(tx.Data()[4:]), commitEvent.BatchHash)
+		assert.Equal(t, commitEvent.BatchHash, common.Hash(call("committedBatches", big.NewInt(int64(i+1))).([32]byte)))
+	}
+
+	stateRoot, withdrawRoot := common.HexToHash("0x02"), common.HexToHash("0x03")
+	_, logs = transact("finalizeBatchWithProof", batchHeader(1), genesisStateRoot, stateRoot, withdrawRoot, []byte{})
+	require.Len(t, logs, 1)
+	require.NoError(t, rollup_sync_service.UnpackLog(scrollChainABI, finalizeEvent, "FinalizeBatch", *logs[0]))
+	assert.Equal(t, uint64(1), finalizeEvent.BatchIndex.Uint64())
+	assert.Equal(t, crypto.Keccak256Hash(batchHeader(1)), finalizeEvent.BatchHash)
+	assert.Equal(t, stateRoot, finalizeEvent.StateRoot)
+	assert.Equal(t, withdrawRoot, finalizeEvent.WithdrawRoot)
+	assert.Equal(t, uint64(1), call("lastFinalizedBatchIndex").(*big.Int).Uint64())
+	assert.Equal(t, withdrawRoot, common.Hash(call("withdrawRoots", big.NewInt(1)).([32]byte)))
+	assert.True(t, call("isBatchFinalized", big.NewInt(1)).(bool))
+	assert.False(t, call("isBatchFinalized", big.NewInt(2)).(bool))
+
+	// revert the batches 2 and 3
+	_, logs = transact("revertBatch", batchHeader(2), big.NewInt(2))
+	require.Len(t, logs, 2)
+	for i, vLog := range logs {
+		revertEvent := new(rollup_sync_service.L1RevertBatchEvent)
+		require.NoError(t, rollup_sync_service.UnpackLog(scrollChainABI, revertEvent, "RevertBatch", *vLog))
+		assert.Equal(t, uint64(i+2), revertEvent.BatchIndex.Uint64())
+		assert.NotEqual(t, common.Hash{}, revertEvent.BatchHash)
+		assert.Equal(t, common.Hash{}, common.Hash(call("committedBatches", big.NewInt(int64(i+2))).([32]byte)))
+	}
+
+	// only the owner may submit batches
+	data, err := scrollChainABI.Pack("commitBatch", uint8(0), batchHeader(1), [][]byte{{0x01}}, []byte{})
+	require.NoError(t, err)
+	_, err = backend.CallContract(context.Background(), ethereum.CallMsg{From: common.Address{0x01}, To: &address, Data: data}, nil)
+	assert.Error(t, err)
+	_, err = backend.CallContract(context.Background(), ethereum.CallMsg{From: auth.From, To: &address, Data: data}, nil)
+	assert.NoError(t, err)
+}
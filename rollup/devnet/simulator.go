@@ -0,0 +1,226 @@
+package devnet
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/scroll-tech/go-ethereum/accounts/abi/bind"
+	"github.com/scroll-tech/go-ethereum/common"
+	"github.com/scroll-tech/go-ethereum/core/rawdb"
+	"github.com/scroll-tech/go-ethereum/core/types"
+	"github.com/scroll-tech/go-ethereum/log"
+
+	"github.com/scroll-tech/go-ethereum/rollup/rollup_sync_service"
+)
+
+// syncPollInterval is the interval at which a simulator checks whether the rollup sync
+// service has processed a submitted transaction.
+const syncPollInterval = 100 * time.Millisecond
+
+// Backend is the L1 connection of a simulator, ethclient.Client implements it.
+type Backend interface {
+	bind.ContractBackend
+	bind.DeployBackend
+}
+
+// Simulator submits batches of the local chain to the mock ScrollChain contract and waits
+// until the rollup sync service has processed each transaction. It drives sequences that
+// are rare on public networks, like the revert and re-commit of batches, through the event
+// handling of the service. The batches are built by the service from its local blocks, so
+// that they pass its validation.
+type Simulator struct {
+	service  *rollup_sync_service.RollupSyncService
+	contract *bind.BoundContract
+	backend  Backend
+	auth     *bind.TransactOpts
+
+	lock sync.Mutex // the steps of a simulator are submitted one at a time
+}
+
+// NewSimulator creates a simulator submitting batches to the mock ScrollChain contract at
+// address from the owner account of auth.
+func NewSimulator(service *rollup_sync_service.RollupSyncService, address common.Address, auth *bind.TransactOpts, backend Backend) (*Simulator, error) {
+	if service == nil {
+		return nil, errors.New("rollup sync service is not running")
+	}
+	scrollChainABI, err := rollup_sync_service.ScrollChainABI()
+	if err != nil {
+		return nil, err
+	}
+	return &Simulator{
+		service:  service,
+		contract: bind.NewBoundContract(address, *scrollChainABI, backend, backend, backend),
+		backend:  backend,
+		auth:     auth,
+	}, nil
+}
+
+// ImportGenesisBatch imports the genesis batch of the local chain, which is committed and
+// finalized at once.
+func (s *Simulator) ImportGenesisBatch(ctx context.Context) (*rollup_sync_service.SimulatedBatch, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	batch, err := s.service.SimulateBatch(0, []*rawdb.ChunkBlockRange{{StartBlockNumber: 0, EndBlockNumber: 0}})
+	if err != nil {
+		return nil, err
+	}
+	return batch, s.transact(ctx, "importGenesisBatch", batch.Header, batch.Meta.StateRoot)
+}
+
+// CommitBatch commits the batch with the given index and chunks on top of the committed
+// batch with the previous index.
+func (s *Simulator) CommitBatch(ctx context.Context, batchIndex uint64, chunkBlockRanges []*rawdb.ChunkBlockRange) (*rollup_sync_service.SimulatedBatch, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	return s.commitBatch(ctx, batchIndex, chunkBlockRanges)
+}
+
+// RevertBatches reverts the committed batch with the given index and all batches above it.
+func (s *Simulator) RevertBatches(ctx context.Context, batchIndex uint64) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	return s.revertBatches(ctx, batchIndex)
+}
+
+// FinalizeBatch finalizes the committed batch with the given index.
+func (s *Simulator) FinalizeBatch(ctx context.Context, batchIndex uint64) (*rollup_sync_service.SimulatedBatch, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	return s.finalizeBatch(ctx, batchIndex)
+}
+
+// SimulateRevert reverts the committed batch with the given index and all batches above it,
+// commits the given batches in their place and finalizes them. Each element of batches holds
+// the chunks of a batch, the first one replaces the batch with the given index.
+func (s *Simulator) SimulateRevert(ctx context.Context, batchIndex uint64, batches [][]*rawdb.ChunkBlockRange) ([]*rollup_sync_service.SimulatedBatch, error) {
+	if len(batches) == 0 {
+		return nil, errors.New("no batches to re-commit")
+	}
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if err := s.revertBatches(ctx, batchIndex); err != nil {
+		return nil, err
+	}
+	for i, chunkBlockRanges := range batches {
+		if _, err := s.commitBatch(ctx, batchIndex+uint64(i), chunkBlockRanges); err != nil {
+			return nil, err
+		}
+	}
+	finalized := make([]*rollup_sync_service.SimulatedBatch, len(batches))
+	for i := range batches {
+		batch, err := s.finalizeBatch(ctx, batchIndex+uint64(i))
+		if err != nil {
+			return nil, err
+		}
+		finalized[i] = batch
+	}
+	return finalized, nil
+}
+
+func (s *Simulator) commitBatch(ctx context.Context, batchIndex uint64, chunkBlockRanges []*rawdb.ChunkBlockRange) (*rollup_sync_service.SimulatedBatch, error) {
+	if batchIndex == 0 {
+		return nil, errors.New("the genesis batch is imported, not committed")
+	}
+	if len(chunkBlockRanges) == 0 {
+		return nil, fmt.Errorf("no chunks for batch %d", batchIndex)
+	}
+	batch, err := s.service.SimulateBatch(batchIndex, chunkBlockRanges)
+	if err != nil {
+		return nil, err
+	}
+	commit := batch.Commit
+	return batch, s.transact(ctx, "commitBatch", commit.Version, commit.ParentBatchHeader, commit.Chunks, commit.SkippedL1MessageBitmap)
+}
+
+func (s *Simulator) revertBatches(ctx context.Context, batchIndex uint64) error {
+	// note: the mock contract does not protect finalized batches like ScrollChain does,
+	// the service would reject the event and stop syncing.
+	var out []interface{}
+	if err := s.contract.Call(&bind.CallOpts{Context: ctx}, &out, "isBatchFinalized", new(big.Int).SetUint64(batchIndex)); err != nil {
+		return fmt.Errorf("failed to query finalization of batch %d: %w", batchIndex, err)
+	}
+	if out[0].(bool) {
+		return fmt.Errorf("batch %d is finalized", batchIndex)
+	}
+	var count int64
+	for {
+		committed, err := s.committed(ctx, batchIndex+uint64(count))
+		if err != nil {
+			return err
+		}
+		if !committed {
+			break
+		}
+		count++
+	}
+	if count == 0 {
+		return fmt.Errorf("batch %d is not committed", batchIndex)
+	}
+	batch, err := s.service.SimulateBatch(batchIndex, nil)
+	if err != nil {
+		return err
+	}
+	return s.transact(ctx, "revertBatch", batch.Header, big.NewInt(count))
+}
+
+func (s *Simulator) finalizeBatch(ctx context.Context, batchIndex uint64) (*rollup_sync_service.SimulatedBatch, error) {
+	batch, err := s.service.SimulateBatch(batchIndex, nil)
+	if err != nil {
+		return nil, err
+	}
+	return batch, s.transact(ctx, "finalizeBatchWithProof", batch.Header, batch.Parent.StateRoot, batch.Meta.StateRoot, batch.Meta.WithdrawRoot, []byte{})
+}
+
+// committed reports whether the contract stores a committed batch with the given index.
+func (s *Simulator) committed(ctx context.Context, batchIndex uint64) (bool, error) {
+	var out []interface{}
+	if err := s.contract.Call(&bind.CallOpts{Context: ctx}, &out, "committedBatches", new(big.Int).SetUint64(batchIndex)); err != nil {
+		return false, fmt.Errorf("failed to query committed batch %d: %w", batchIndex, err)
+	}
+	return common.Hash(out[0].([32]byte)) != common.Hash{}, nil
+}
+
+// transact calls the contract method and waits until the rollup sync service has processed
+// the L1 block of the transaction.
+func (s *Simulator) transact(ctx context.Context, method string, params ...interface{}) error {
+	opts := *s.auth
+	opts.Context = ctx
+	tx, err := s.contract.Transact(&opts, method, params...)
+	if err != nil {
+		return fmt.Errorf("failed to send %v transaction: %w", method, err)
+	}
+	receipt, err := bind.WaitMined(ctx, s.backend, tx)
+	if err != nil {
+		return fmt.Errorf("failed to wait for %v transaction %v: %w", method, tx.Hash().Hex(), err)
+	}
+	if receipt.Status != types.ReceiptStatusSuccessful {
+		return fmt.Errorf("%v transaction %v failed", method, tx.Hash().Hex())
+	}
+	log.Info("Submitted devnet rollup transaction", "method", method, "tx", tx.Hash(), "l1 block", receipt.BlockNumber)
+
+	l1BlockNumber := receipt.BlockNumber.Uint64()
+	for {
+		s.service.Sync()
+		status := s.service.Status()
+		if status.SyncedL1BlockNumber >= l1BlockNumber {
+			return nil
+		}
+		if status.Halted {
+			return fmt.Errorf("rollup sync halted before processing L1 block %d, poisoned batches: %v", l1BlockNumber, status.PoisonedBatches)
+		}
+		select {
+		case <-time.After(syncPollInterval):
+		case <-ctx.Done():
+			return fmt.Errorf("rollup sync has not processed L1 block %d of %v transaction: %w", l1BlockNumber, method, ctx.Err())
+		}
+	}
+}
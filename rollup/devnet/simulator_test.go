@@ -0,0 +1,231 @@
+package devnet
+
+import (
+	"context"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/scroll-tech/go-ethereum/accounts/abi/bind"
+	"github.com/scroll-tech/go-ethereum/accounts/abi/bind/backends"
+	"github.com/scroll-tech/go-ethereum/common"
+	"github.com/scroll-tech/go-ethereum/consensus/ethash"
+	"github.com/scroll-tech/go-ethereum/core"
+	"github.com/scroll-tech/go-ethereum/core/rawdb"
+	"github.com/scroll-tech/go-ethereum/core/types"
+	"github.com/scroll-tech/go-ethereum/core/vm"
+	"github.com/scroll-tech/go-ethereum/crypto"
+	"github.com/scroll-tech/go-ethereum/params"
+	"github.com/scroll-tech/go-ethereum/rpc"
+
+	"github.com/scroll-tech/go-ethereum/rollup/rollup_sync_service"
+)
+
+// l1Backend is a simulated L1 chain that mines every transaction right away and treats its
+// latest block as finalized.
+type l1Backend struct {
+	*backends.SimulatedBackend
+}
+
+func (b *l1Backend) SendTransaction(ctx context.Context, tx *types.Transaction) error {
+	if err := b.SimulatedBackend.SendTransaction(ctx, tx); err != nil {
+		return err
+	}
+	b.Commit()
+	return nil
+}
+
+func (b *l1Backend) BlockNumber(ctx context.Context) (uint64, error) {
+	return b.Blockchain().CurrentBlock().NumberU64(), nil
+}
+
+func (b *l1Backend) ChainID(ctx context.Context) (*big.Int, error) {
+	return b.Blockchain().Config().ChainID, nil
+}
+
+func (b *l1Backend) HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error) {
+	if number != nil && number.Sign() < 0 {
+		number = nil
+	}
+	return b.SimulatedBackend.HeaderByNumber(ctx, number)
+}
+
+func TestSimulateRevert(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+	auth, err := bind.NewKeyedTransactorWithChainID(key, big.NewInt(1337))
+	require.NoError(t, err)
+	l1 := &l1Backend{backends.NewSimulatedBackend(core.GenesisAlloc{auth.From: {Balance: big.NewInt(1e18)}}, 10000000)}
+	defer l1.Close()
+	address, _, err := DeployScrollChain(auth, l1, 534352)
+	require.NoError(t, err)
+
+	// the local chain follows the contract from its deployment block
+	config := *params.TestChainConfig
+	config.Scroll.L1Config = &params.L1Config{L1ChainId: 1337, ScrollChainAddress: address}
+	db := rawdb.NewMemoryDatabase()
+	genesis := (&core.Genesis{Config: &config}).MustCommit(db)
+	blocks, _ := core.GenerateChain(&config, genesis, ethash.NewFaker(), rawdb.NewMemoryDatabase(), 7, nil)
+	bc, err := core.NewBlockChain(db, nil, &config, ethash.NewFaker(), vm.Config{}, nil, nil)
+	require.NoError(t, err)
+	defer bc.Stop()
+	_, err = bc.InsertChain(blocks)
+	require.NoError(t, err)
+	service, err := rollup_sync_service.NewRollupSyncService(context.Background(), &config, db, l1, bc, 1, &rollup_sync_service.Config{VerifyMode: rollup_sync_service.VerifyModeHaltSync}, nil)
+	require.NoError(t, err)
+	defer service.Stop()
+
+	simulator, err := NewSimulator(service, address, auth, l1)
+	require.NoError(t, err)
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	_, err = simulator.ImportGenesisBatch(ctx)
+	require.NoError(t, err)
+	for i, chunks := range [][]*rawdb.ChunkBlockRange{
+		{{StartBlockNumber: 1, EndBlockNumber: 2}},
+		{{StartBlockNumber: 3, EndBlockNumber: 4}},
+		{{StartBlockNumber: 5, EndBlockNumber: 6}},
+	} {
+		_, err = simulator.CommitBatch(ctx, uint64(i+1), chunks)
+		require.NoError(t, err)
+	}
+	batch, err := simulator.FinalizeBatch(ctx, 1)
+	require.NoError(t, err)
+	assert.Equal(t, batch.Meta, rawdb.ReadFinalizedBatchMeta(db, 1))
+	assert.Equal(t, uint64(2), *rawdb.ReadFinalizedL2BlockNumber(db))
+
+	// the finalized batch cannot be reverted
+	assert.Error(t, simulator.RevertBatches(ctx, 1))
+
+	// the batches 2 and 3 are reverted and committed again with other chunks
+	recommitted := [][]*rawdb.ChunkBlockRange{
+		{{StartBlockNumber: 3, EndBlockNumber: 3}, {StartBlockNumber: 4, EndBlockNumber: 5}},
+		{{StartBlockNumber: 6, EndBlockNumber: 6}},
+	}
+	finalized, err := simulator.SimulateRevert(ctx, 2, recommitted)
+	require.NoError(t, err)
+	require.Len(t, finalized, 2)
+	for i, batch := range finalized {
+		batchIndex := uint64(i + 2)
+		assert.Equal(t, batchIndex, batch.BatchIndex)
+		assert.Equal(t, "RevertBatch event", rawdb.ReadRevertedBatch(db, batchIndex).Reason)
+		assert.Equal(t, recommitted[i], rawdb.ReadBatchChunkRanges(db, batchIndex))
+		assert.Equal(t, batch.Meta, rawdb.ReadFinalizedBatchMeta(db, batchIndex))
+	}
+	assert.Equal(t, crypto.Keccak256Hash(finalized[1].Header), finalized[1].Meta.BatchHash)
+	assert.Equal(t, blocks[5].Root(), finalized[1].Meta.StateRoot)
+	assert.Equal(t, uint64(6), *rawdb.ReadFinalizedL2BlockNumber(db))
+	assert.Empty(t, rawdb.ReadPoisonedBatchIndices(db))
+
+	// the steps can also be requested over RPC
+	server := rpc.NewServer()
+	defer server.Stop()
+	require.NoError(t, server.RegisterName("devnet", NewAPI(simulator)))
+	client := rpc.DialInProc(server)
+	defer client.Close()
+	chunks := []*rawdb.ChunkBlockRange{{StartBlockNumber: 7, EndBlockNumber: 7}}
+	var committed *rpcSimulatedBatch
+	require.NoError(t, client.CallContext(ctx, &committed, "devnet_commitBatch", 4, chunks))
+	var result []*rpcSimulatedBatch
+	require.NoError(t, client.CallContext(ctx, &result, "devnet_simulateRevert", 4, [][]*rawdb.ChunkBlockRange{chunks}))
+	require.Len(t, result, 1)
+	assert.Equal(t, committed.BatchHash, result[0].BatchHash)
+	assert.Equal(t, result[0].BatchHash, rawdb.ReadFinalizedBatchMeta(db, 4).BatchHash)
+	assert.Equal(t, uint64(7), *rawdb.ReadFinalizedL2BlockNumber(db))
+}
+
+func TestL1Follower(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+	auth, err := bind.NewKeyedTransactorWithChainID(key, big.NewInt(1337))
+	require.NoError(t, err)
+	l1 := &l1Backend{backends.NewSimulatedBackend(core.GenesisAlloc{auth.From: {Balance: big.NewInt(1e18)}}, 10000000)}
+	defer l1.Close()
+	address, _, err := DeployScrollChain(auth, l1, 534352)
+	require.NoError(t, err)
+
+	// every block of the sequencer transfers ether, block 2 includes an L1 message
+	config := *params.TestChainConfig
+	config.Scroll.L1Config = &params.L1Config{L1ChainId: 1337, ScrollChainAddress: address}
+	senderKey, _ := crypto.GenerateKey()
+	genesis := &core.Genesis{Config: &config, Alloc: core.GenesisAlloc{crypto.PubkeyToAddress(senderKey.PublicKey): {Balance: big.NewInt(1e18)}}}
+	l1Message := types.L1MessageTx{QueueIndex: 0, To: &common.Address{0x02}, Gas: 100000, Value: big.NewInt(0)}
+	db, genDB := rawdb.NewMemoryDatabase(), rawdb.NewMemoryDatabase()
+	genesis.MustCommit(db)
+	rawdb.WriteL1Messages(db, []types.L1MessageTx{l1Message})
+	blocks, _ := core.GenerateChain(&config, genesis.MustCommit(genDB), ethash.NewFaker(), genDB, 6, func(i int, gen *core.BlockGen) {
+		if i == 1 {
+			gen.AddTx(types.NewTx(&l1Message))
+		}
+		tx := types.MustSignNewTx(senderKey, types.LatestSigner(&config), &types.LegacyTx{Nonce: gen.TxNonce(crypto.PubkeyToAddress(senderKey.PublicKey)), To: &common.Address{0x01}, Value: big.NewInt(1), Gas: 21000, GasPrice: big.NewInt(1e9)})
+		gen.AddTx(tx)
+	})
+	bc, err := core.NewBlockChain(db, nil, &config, ethash.NewFaker(), vm.Config{}, nil, nil)
+	require.NoError(t, err)
+	defer bc.Stop()
+	_, err = bc.InsertChain(blocks)
+	require.NoError(t, err)
+	service, err := rollup_sync_service.NewRollupSyncService(context.Background(), &config, db, l1, bc, 1, &rollup_sync_service.Config{VerifyMode: rollup_sync_service.VerifyModeHaltSync}, nil)
+	require.NoError(t, err)
+	defer service.Stop()
+
+	// the follower starts from the genesis block and knows the L1 messages
+	followerDB := rawdb.NewMemoryDatabase()
+	genesis.MustCommit(followerDB)
+	rawdb.WriteL1Messages(followerDB, []types.L1MessageTx{l1Message})
+	followerChain, err := core.NewBlockChain(followerDB, nil, &config, ethash.NewFaker(), vm.Config{}, nil, nil)
+	require.NoError(t, err)
+	defer followerChain.Stop()
+	follower, err := rollup_sync_service.NewRollupSyncService(context.Background(), &config, followerDB, l1, followerChain, 1, &rollup_sync_service.Config{L1Follower: true, VerifyMode: rollup_sync_service.VerifyModeHaltSync}, nil)
+	require.NoError(t, err)
+	defer follower.Stop()
+	syncFollower := func() {
+		head := l1.Blockchain().CurrentBlock().NumberU64()
+		require.Eventually(t, func() bool {
+			follower.Sync()
+			status := follower.Status()
+			require.False(t, status.Halted, "poisoned batches: %v", status.PoisonedBatches)
+			return status.SyncedL1BlockNumber >= head
+		}, time.Minute, 10*time.Millisecond)
+	}
+
+	simulator, err := NewSimulator(service, address, auth, l1)
+	require.NoError(t, err)
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	_, err = simulator.ImportGenesisBatch(ctx)
+	require.NoError(t, err)
+	for i, chunks := range [][]*rawdb.ChunkBlockRange{
+		{{StartBlockNumber: 1, EndBlockNumber: 2}},
+		{{StartBlockNumber: 3, EndBlockNumber: 4}, {StartBlockNumber: 5, EndBlockNumber: 5}},
+	} {
+		_, err = simulator.CommitBatch(ctx, uint64(i+1), chunks)
+		require.NoError(t, err)
+	}
+	_, err = simulator.FinalizeBatch(ctx, 1)
+	require.NoError(t, err)
+	syncFollower()
+	assert.Equal(t, uint64(5), followerChain.CurrentBlock().NumberU64())
+	for _, block := range blocks[:5] {
+		derived := followerChain.GetBlockByNumber(block.NumberU64())
+		require.NotNil(t, derived)
+		assert.Equal(t, block.Root(), derived.Root())
+		assert.Equal(t, len(block.Transactions()), len(derived.Transactions()))
+	}
+	assert.Equal(t, uint64(2), *rawdb.ReadFinalizedL2BlockNumber(followerDB))
+
+	// the reverted blocks are derived again from the re-committed batches
+	_, err = simulator.SimulateRevert(ctx, 2, [][]*rawdb.ChunkBlockRange{
+		{{StartBlockNumber: 3, EndBlockNumber: 3}},
+		{{StartBlockNumber: 4, EndBlockNumber: 6}},
+	})
+	require.NoError(t, err)
+	syncFollower()
+	assert.Equal(t, uint64(6), followerChain.CurrentBlock().NumberU64())
+	assert.Equal(t, blocks[5].Root(), followerChain.CurrentBlock().Root())
+	assert.Equal(t, uint64(6), *rawdb.ReadFinalizedL2BlockNumber(followerDB))
+	assert.Equal(t, rawdb.ReadFinalizedBatchMeta(db, 3), rawdb.ReadFinalizedBatchMeta(followerDB, 3))
+	assert.Empty(t, rawdb.ReadPoisonedBatchIndices(followerDB))
+}
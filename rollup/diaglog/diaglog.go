@@ -0,0 +1,162 @@
+// Package diaglog writes large diagnostic payloads of the rollup subsystems to the log,
+// e.g. the chunks of a batch that failed validation. Payloads can be compressed and
+// sampled, and the bytes written per hour are capped, so that repeated failures on a
+// busy network do not flood the disk.
+package diaglog
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/base64"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/scroll-tech/go-ethereum/log"
+	"github.com/scroll-tech/go-ethereum/metrics"
+)
+
+var (
+	writtenBytesCounter = metrics.NewRegisteredCounter("rollup/diaglog/written", nil)
+	droppedCounter      = metrics.NewRegisteredCounter("rollup/diaglog/dropped", nil)
+	sampledCounter      = metrics.NewRegisteredCounter("rollup/diaglog/sampled", nil)
+)
+
+// compressedPrefix marks compressed payloads.
+const compressedPrefix = "gzip+base64:"
+
+// Config configures the handling of diagnostic payloads.
+type Config struct {
+	// MaxBytesPerHour caps the payload bytes written per hour, payloads exceeding
+	// the cap are dropped until the next hour. Zero disables the cap.
+	MaxBytesPerHour uint64
+
+	// CompressThreshold is the size in bytes from which payloads are gzip compressed
+	// and base64 encoded. Zero disables compression.
+	CompressThreshold int
+
+	// SampleRate writes only every SampleRate-th payload of each message, starting
+	// with the first one. Zero and one write all payloads.
+	SampleRate uint64
+}
+
+// DefaultConfig is the configuration of the package level logger.
+var DefaultConfig = Config{
+	MaxBytesPerHour:   16 * 1024 * 1024,
+	CompressThreshold: 64 * 1024,
+	SampleRate:        1,
+}
+
+// Logger writes diagnostic payloads within the limits of its configuration.
+type Logger struct {
+	config Config
+
+	hour     int64             // hours since the epoch of the current budget period
+	written  uint64            // payload bytes written in the current budget period
+	dropped  uint64            // payloads dropped in the current budget period
+	messages map[string]uint64 // number of payloads seen per message
+	lock     sync.Mutex
+
+	now   func() time.Time                     // overridden in tests
+	write func(msg string, ctx ...interface{}) // overridden in tests
+}
+
+// New creates a logger writing to the root logger at error level.
+func New(config Config) *Logger {
+	return &Logger{
+		config:   config,
+		messages: make(map[string]uint64),
+		now:      time.Now,
+		write:    log.Error,
+	}
+}
+
+var root = New(DefaultConfig)
+
+// SetConfig replaces the configuration of the package level logger.
+func SetConfig(config Config) {
+	root.lock.Lock()
+	defer root.lock.Unlock()
+
+	root.config = config
+}
+
+// Error writes a diagnostic payload with the package level logger, see Logger.Error.
+func Error(msg string, key string, payload []byte, ctx ...interface{}) {
+	root.Error(msg, key, payload, ctx...)
+}
+
+// Error writes a log line with the given context and the payload as value of key.
+// Compressed payloads are prefixed by their encoding, see Decompress.
+func (l *Logger) Error(msg string, key string, payload []byte, ctx ...interface{}) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	if hour := l.now().Unix() / 3600; hour != l.hour {
+		if l.dropped > 0 {
+			l.write("Dropped rollup diagnostic payloads", "count", l.dropped, "budget", l.config.MaxBytesPerHour)
+		}
+		l.hour, l.written, l.dropped = hour, 0, 0
+	}
+
+	seen := l.messages[msg]
+	l.messages[msg]++
+	if l.config.SampleRate > 1 && seen%l.config.SampleRate != 0 {
+		sampledCounter.Inc(1)
+		return
+	}
+
+	value := string(payload)
+	if l.config.CompressThreshold > 0 && len(payload) >= l.config.CompressThreshold {
+		if compressed, err := compress(payload); err == nil {
+			value = compressedPrefix + compressed
+			ctx = append(ctx, "size", len(payload))
+		}
+	}
+	if l.config.MaxBytesPerHour > 0 && l.written+uint64(len(value)) > l.config.MaxBytesPerHour {
+		if l.dropped == 0 {
+			log.Warn("Rollup diagnostic log budget exhausted, dropping payloads until the next hour", "budget", l.config.MaxBytesPerHour)
+		}
+		l.dropped++
+		droppedCounter.Inc(1)
+		return
+	}
+	l.written += uint64(len(value))
+	writtenBytesCounter.Inc(int64(len(value)))
+	l.write(msg, append(ctx, key, value)...)
+}
+
+// compress gzips and base64 encodes data.
+func compress(data []byte) (string, error) {
+	var buf bytes.Buffer
+	w := gzip.NewWriter(&buf)
+	if _, err := w.Write(data); err != nil {
+		return "", err
+	}
+	if err := w.Close(); err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(buf.Bytes()), nil
+}
+
+// Decompress decodes a payload written by Error, uncompressed payloads are returned as is.
+func Decompress(value string) ([]byte, error) {
+	if !strings.HasPrefix(value, compressedPrefix) {
+		return []byte(value), nil
+	}
+	data, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(value, compressedPrefix))
+	if err != nil {
+		return nil, err
+	}
+	r, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+
+	var buf bytes.Buffer
+	if _, err := buf.ReadFrom(r); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,80 @@
+package diaglog
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type record struct {
+	msg string
+	ctx []interface{}
+}
+
+func newTestLogger(config Config) (*Logger, *[]record, *time.Time) {
+	l := New(config)
+	now := time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)
+	l.now = func() time.Time { return now }
+	records := new([]record)
+	l.write = func(msg string, ctx ...interface{}) { *records = append(*records, record{msg, ctx}) }
+	return l, records, &now
+}
+
+func TestLoggerCompression(t *testing.T) {
+	l, records, _ := newTestLogger(Config{CompressThreshold: 100})
+
+	l.Error("small", "payload", []byte("abc"), "batch index", 1)
+	large := bytes.Repeat([]byte("chunk"), 100)
+	l.Error("large", "payload", large, "batch index", 2)
+
+	require.Len(t, *records, 2)
+	assert.Equal(t, []interface{}{"batch index", 1, "payload", "abc"}, (*records)[0].ctx)
+
+	ctx := (*records)[1].ctx
+	require.Len(t, ctx, 6)
+	assert.Equal(t, []interface{}{"batch index", 2, "size", len(large), "payload"}, ctx[:5])
+	value := ctx[5].(string)
+	assert.Less(t, len(value), len(large))
+	decompressed, err := Decompress(value)
+	require.NoError(t, err)
+	assert.Equal(t, large, decompressed)
+
+	plain, err := Decompress("abc")
+	require.NoError(t, err)
+	assert.Equal(t, []byte("abc"), plain)
+}
+
+func TestLoggerSampling(t *testing.T) {
+	l, records, _ := newTestLogger(Config{SampleRate: 3})
+
+	for i := 0; i < 7; i++ {
+		l.Error("a", "payload", []byte("x"), "i", i)
+	}
+	l.Error("b", "payload", []byte("x"), "i", 0)
+
+	var written []interface{}
+	for _, r := range *records {
+		written = append(written, r.msg, r.ctx[1])
+	}
+	assert.Equal(t, []interface{}{"a", 0, "a", 3, "a", 6, "b", 0}, written)
+}
+
+func TestLoggerHourlyBudget(t *testing.T) {
+	l, records, now := newTestLogger(Config{MaxBytesPerHour: 10})
+
+	l.Error("a", "payload", []byte("123456"))
+	l.Error("a", "payload", []byte("123456"))
+	l.Error("a", "payload", []byte("1234"))
+	l.Error("a", "payload", []byte("1"))
+	require.Len(t, *records, 2)
+
+	// the budget is reset in the next hour, reporting the dropped payloads
+	*now = now.Add(time.Hour)
+	l.Error("a", "payload", []byte("123456"))
+	require.Len(t, *records, 4)
+	assert.Equal(t, []interface{}{"count", uint64(2), "budget", uint64(10)}, (*records)[2].ctx)
+	assert.Equal(t, []interface{}{"payload", "123456"}, (*records)[3].ctx)
+}
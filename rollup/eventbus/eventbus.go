@@ -0,0 +1,120 @@
+// Package eventbus implements a small in-process event bus that rollup services
+// use to notify each other without depending on each other's packages.
+package eventbus
+
+import (
+	"github.com/scroll-tech/go-ethereum/common"
+	"github.com/scroll-tech/go-ethereum/event"
+)
+
+// L1HeadEvent is posted when a rollup service observes a new confirmed L1 block.
+type L1HeadEvent struct {
+	Number uint64
+}
+
+// BatchCommittedEvent is posted when a CommitBatch event has been processed.
+type BatchCommittedEvent struct {
+	BatchIndex       uint64
+	BatchHash        common.Hash
+	StartBlockNumber uint64 // first L2 block in the batch
+	EndBlockNumber   uint64 // last L2 block in the batch
+	L1BlockNumber    uint64
+	L1TxHash         common.Hash
+}
+
+// BatchRevertedEvent is posted when a RevertBatch event has been processed.
+type BatchRevertedEvent struct {
+	BatchIndex    uint64
+	BatchHash     common.Hash
+	L1BlockNumber uint64
+	L1TxHash      common.Hash
+}
+
+// BatchFinalizedEvent is posted when a FinalizeBatch event has been processed and the batch has been validated.
+type BatchFinalizedEvent struct {
+	BatchIndex       uint64
+	BatchHash        common.Hash
+	StartBlockNumber uint64 // first L2 block in the batch
+	EndBlockNumber   uint64 // last L2 block in the batch
+	StateRoot        common.Hash
+	WithdrawRoot     common.Hash
+	L1BlockNumber    uint64
+	L1TxHash         common.Hash
+}
+
+// L1ReorgEvent is posted when an L1 reorg has been detected and the rollup
+// events above L1 block CommonAncestor have been rolled back.
+type L1ReorgEvent struct {
+	CommonAncestor  uint64 // last processed L1 block that is still canonical
+	RolledBackBlock uint64 // last processed L1 block before the reorg
+}
+
+// Bus delivers typed rollup events to subscribers. The zero value is ready to use.
+type Bus struct {
+	l1HeadFeed         event.Feed
+	batchCommittedFeed event.Feed
+	batchRevertedFeed  event.Feed
+	batchFinalizedFeed event.Feed
+	l1ReorgFeed        event.Feed
+	scope              event.SubscriptionScope
+}
+
+// New creates an event bus.
+func New() *Bus {
+	return &Bus{}
+}
+
+// Close unsubscribes all subscribers.
+func (b *Bus) Close() {
+	b.scope.Close()
+}
+
+// PublishL1Head sends an L1HeadEvent to all subscribers.
+func (b *Bus) PublishL1Head(ev L1HeadEvent) {
+	b.l1HeadFeed.Send(ev)
+}
+
+// SubscribeL1Head registers a subscription of L1HeadEvent.
+func (b *Bus) SubscribeL1Head(ch chan<- L1HeadEvent) event.Subscription {
+	return b.scope.Track(b.l1HeadFeed.Subscribe(ch))
+}
+
+// PublishBatchCommitted sends a BatchCommittedEvent to all subscribers.
+func (b *Bus) PublishBatchCommitted(ev BatchCommittedEvent) {
+	b.batchCommittedFeed.Send(ev)
+}
+
+// SubscribeBatchCommitted registers a subscription of BatchCommittedEvent.
+func (b *Bus) SubscribeBatchCommitted(ch chan<- BatchCommittedEvent) event.Subscription {
+	return b.scope.Track(b.batchCommittedFeed.Subscribe(ch))
+}
+
+// PublishBatchReverted sends a BatchRevertedEvent to all subscribers.
+func (b *Bus) PublishBatchReverted(ev BatchRevertedEvent) {
+	b.batchRevertedFeed.Send(ev)
+}
+
+// SubscribeBatchReverted registers a subscription of BatchRevertedEvent.
+func (b *Bus) SubscribeBatchReverted(ch chan<- BatchRevertedEvent) event.Subscription {
+	return b.scope.Track(b.batchRevertedFeed.Subscribe(ch))
+}
+
+// PublishBatchFinalized sends a BatchFinalizedEvent to all subscribers.
+func (b *Bus) PublishBatchFinalized(ev BatchFinalizedEvent) {
+	b.batchFinalizedFeed.Send(ev)
+}
+
+// SubscribeBatchFinalized registers a subscription of BatchFinalizedEvent.
+func (b *Bus) SubscribeBatchFinalized(ch chan<- BatchFinalizedEvent) event.Subscription {
+	return b.scope.Track(b.batchFinalizedFeed.Subscribe(ch))
+}
+
+// PublishL1Reorg sends an L1ReorgEvent to all subscribers.
+func (b *Bus) PublishL1Reorg(ev L1ReorgEvent) {
+	b.l1ReorgFeed.Send(ev)
+}
+
+// SubscribeL1Reorg registers a subscription of L1ReorgEvent.
+func (b *Bus) SubscribeL1Reorg(ch chan<- L1ReorgEvent) event.Subscription {
+	return b.scope.Track(b.l1ReorgFeed.Subscribe(ch))
+}
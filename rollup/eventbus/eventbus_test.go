@@ -0,0 +1,31 @@
+package eventbus
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBus(t *testing.T) {
+	bus := New()
+
+	committedCh := make(chan BatchCommittedEvent, 1)
+	reorgCh := make(chan L1ReorgEvent, 1)
+	committedSub := bus.SubscribeBatchCommitted(committedCh)
+	reorgSub := bus.SubscribeL1Reorg(reorgCh)
+
+	bus.PublishBatchCommitted(BatchCommittedEvent{BatchIndex: 1})
+	assert.Equal(t, BatchCommittedEvent{BatchIndex: 1}, <-committedCh)
+
+	bus.PublishL1Reorg(L1ReorgEvent{CommonAncestor: 10, RolledBackBlock: 20})
+	assert.Equal(t, L1ReorgEvent{CommonAncestor: 10, RolledBackBlock: 20}, <-reorgCh)
+
+	// events without subscribers are dropped
+	bus.PublishL1Head(L1HeadEvent{Number: 5})
+
+	bus.Close()
+	_, ok := <-committedSub.Err()
+	assert.False(t, ok)
+	_, ok = <-reorgSub.Err()
+	assert.False(t, ok)
+}
@@ -44,8 +44,18 @@ type StateDB interface {
 }
 
 func EstimateL1DataFeeForMessage(msg Message, baseFee, chainID *big.Int, signer types.Signer, state StateDB) (*big.Int, error) {
+	fee, err := EstimateL1FeeForMessage(msg, baseFee, chainID, signer, state)
+	if err != nil {
+		return nil, err
+	}
+	return fee.L1DataFee, nil
+}
+
+// EstimateL1FeeForMessage computes the L1 data fee and its components of the transaction
+// the message would be sent in, signed with a placeholder signature of the same size.
+func EstimateL1FeeForMessage(msg Message, baseFee, chainID *big.Int, signer types.Signer, state StateDB) (*L1Fee, error) {
 	if msg.IsL1MessageTx() {
-		return big.NewInt(0), nil
+		return &L1Fee{L1DataFee: big.NewInt(0)}, nil
 	}
 
 	unsigned := asUnsignedTx(msg, baseFee, chainID)
@@ -54,15 +64,7 @@ func EstimateL1DataFeeForMessage(msg Message, baseFee, chainID *big.Int, signer
 	if err != nil {
 		return nil, err
 	}
-
-	raw, err := rlpEncode(tx)
-	if err != nil {
-		return nil, err
-	}
-
-	l1BaseFee, overhead, scalar := readGPOStorageSlots(rcfg.L1GasPriceOracleAddress, state)
-	l1DataFee := calculateEncodedL1DataFee(raw, overhead, l1BaseFee, scalar)
-	return l1DataFee, nil
+	return CalculateL1Fee(tx, state)
 }
 
 // asUnsignedTx turns a Message into a types.Transaction
@@ -126,6 +128,38 @@ func rlpEncode(tx *types.Transaction) ([]byte, error) {
 	return raw.Bytes(), nil
 }
 
+// L1Fees are the values stored in the L1GasPriceOracle predeploy, which the L1 data fees are
+// computed from. The blob base fee and the commit and blob scalars are zero until they are
+// set after the Curie upgrade of the predeploy.
+type L1Fees struct {
+	L1BaseFee     *big.Int
+	Overhead      *big.Int
+	Scalar        *big.Int
+	L1BlobBaseFee *big.Int
+	CommitScalar  *big.Int
+	BlobScalar    *big.Int
+}
+
+// ReadL1Fees returns the L1 fee components stored in the L1GasPriceOracle predeploy.
+func ReadL1Fees(state StateDB) *L1Fees {
+	l1Fees := new(L1Fees)
+	l1Fees.L1BaseFee, l1Fees.Overhead, l1Fees.Scalar = readGPOStorageSlots(rcfg.L1GasPriceOracleAddress, state)
+	l1Fees.L1BlobBaseFee = state.GetState(rcfg.L1GasPriceOracleAddress, rcfg.L1BlobBaseFeeSlot).Big()
+	l1Fees.CommitScalar = state.GetState(rcfg.L1GasPriceOracleAddress, rcfg.CommitScalarSlot).Big()
+	l1Fees.BlobScalar = state.GetState(rcfg.L1GasPriceOracleAddress, rcfg.BlobScalarSlot).Big()
+	return l1Fees
+}
+
+// Equal reports whether both hold the same L1 fee values.
+func (f *L1Fees) Equal(other *L1Fees) bool {
+	return f.L1BaseFee.Cmp(other.L1BaseFee) == 0 &&
+		f.Overhead.Cmp(other.Overhead) == 0 &&
+		f.Scalar.Cmp(other.Scalar) == 0 &&
+		f.L1BlobBaseFee.Cmp(other.L1BlobBaseFee) == 0 &&
+		f.CommitScalar.Cmp(other.CommitScalar) == 0 &&
+		f.BlobScalar.Cmp(other.BlobScalar) == 0
+}
+
 func readGPOStorageSlots(addr common.Address, state StateDB) (*big.Int, *big.Int, *big.Int) {
 	l1BaseFee := state.GetState(addr, rcfg.L1BaseFeeSlot)
 	overhead := state.GetState(addr, rcfg.OverheadSlot)
@@ -174,8 +208,28 @@ func mulAndScale(x *big.Int, y *big.Int, precision *big.Int) *big.Int {
 }
 
 func CalculateL1DataFee(tx *types.Transaction, state StateDB) (*big.Int, error) {
+	fee, err := CalculateL1Fee(tx, state)
+	if err != nil {
+		return nil, err
+	}
+	return fee.L1DataFee, nil
+}
+
+// L1Fee is the L1 data fee of a transaction, along with the L1 gas used for its data and
+// the values of the L1GasPriceOracle it was computed from.
+type L1Fee struct {
+	L1DataFee *big.Int
+	L1GasUsed *big.Int
+	L1BaseFee *big.Int
+	Overhead  *big.Int
+	Scalar    *big.Int
+}
+
+// CalculateL1Fee computes the L1 data fee of a transaction and its components. L1 messages
+// do not pay an L1 data fee, only their zero L1DataFee is set.
+func CalculateL1Fee(tx *types.Transaction, state StateDB) (*L1Fee, error) {
 	if tx.IsL1MessageTx() {
-		return big.NewInt(0), nil
+		return &L1Fee{L1DataFee: big.NewInt(0)}, nil
 	}
 
 	raw, err := rlpEncode(tx)
@@ -184,6 +238,11 @@ func CalculateL1DataFee(tx *types.Transaction, state StateDB) (*big.Int, error)
 	}
 
 	l1BaseFee, overhead, scalar := readGPOStorageSlots(rcfg.L1GasPriceOracleAddress, state)
-	l1DataFee := calculateEncodedL1DataFee(raw, overhead, l1BaseFee, scalar)
-	return l1DataFee, nil
+	return &L1Fee{
+		L1DataFee: calculateEncodedL1DataFee(raw, overhead, l1BaseFee, scalar),
+		L1GasUsed: CalculateL1GasUsed(raw, overhead),
+		L1BaseFee: l1BaseFee,
+		Overhead:  overhead,
+		Scalar:    scalar,
+	}, nil
 }
@@ -5,6 +5,11 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/scroll-tech/go-ethereum/common"
+	"github.com/scroll-tech/go-ethereum/core/types"
+	"github.com/scroll-tech/go-ethereum/rollup/rcfg"
 )
 
 func TestCalculateEncodedL1DataFee(t *testing.T) {
@@ -18,3 +23,59 @@ func TestCalculateEncodedL1DataFee(t *testing.T) {
 	actual := calculateEncodedL1DataFee(data, overhead, l1BaseFee, scalar)
 	assert.Equal(t, expected, actual)
 }
+
+type testStateDB map[common.Hash]common.Hash
+
+func (s testStateDB) GetState(addr common.Address, slot common.Hash) common.Hash { return s[slot] }
+func (s testStateDB) GetBalance(addr common.Address) *big.Int                    { return big.NewInt(0) }
+
+func TestCalculateL1Fee(t *testing.T) {
+	state := testStateDB{
+		rcfg.L1BaseFeeSlot: common.BigToHash(big.NewInt(15000000)),
+		rcfg.OverheadSlot:  common.BigToHash(big.NewInt(100)),
+		rcfg.ScalarSlot:    common.BigToHash(big.NewInt(2000000000)),
+	}
+	tx := types.NewTransaction(1, common.HexToAddress("0x1"), big.NewInt(1), 21000, big.NewInt(1), []byte{0, 1})
+
+	fee, err := CalculateL1Fee(tx, state)
+	require.NoError(t, err)
+	raw, err := rlpEncode(tx)
+	require.NoError(t, err)
+	assert.Equal(t, CalculateL1GasUsed(raw, big.NewInt(100)), fee.L1GasUsed)
+	assert.Equal(t, big.NewInt(15000000), fee.L1BaseFee)
+	assert.Equal(t, big.NewInt(100), fee.Overhead)
+	assert.Equal(t, big.NewInt(2000000000), fee.Scalar)
+	// the scalar has a precision of 1e9
+	assert.Equal(t, new(big.Int).Mul(new(big.Int).Mul(fee.L1GasUsed, fee.L1BaseFee), big.NewInt(2)), fee.L1DataFee)
+
+	dataFee, err := CalculateL1DataFee(tx, state)
+	require.NoError(t, err)
+	assert.Equal(t, fee.L1DataFee, dataFee)
+
+	// L1 messages do not pay an L1 data fee
+	msg := types.NewTx(&types.L1MessageTx{QueueIndex: 1, Gas: 21000, To: &common.Address{}, Value: big.NewInt(0), Sender: common.HexToAddress("0x2")})
+	fee, err = CalculateL1Fee(msg, state)
+	require.NoError(t, err)
+	assert.Equal(t, big.NewInt(0), fee.L1DataFee)
+	assert.Nil(t, fee.L1GasUsed)
+}
+
+func TestEstimateL1FeeForMessage(t *testing.T) {
+	state := testStateDB{
+		rcfg.L1BaseFeeSlot: common.BigToHash(big.NewInt(15000000)),
+		rcfg.OverheadSlot:  common.BigToHash(big.NewInt(100)),
+		rcfg.ScalarSlot:    common.BigToHash(big.NewInt(2000000000)),
+	}
+	to := common.HexToAddress("0x1")
+	msg := types.NewMessage(common.HexToAddress("0x2"), &to, 1, big.NewInt(1), 21000, big.NewInt(1), nil, nil, []byte{0, 1}, nil, false)
+	signer := types.NewEIP155Signer(big.NewInt(1))
+
+	fee, err := EstimateL1FeeForMessage(msg, nil, big.NewInt(1), signer, state)
+	require.NoError(t, err)
+	assert.Equal(t, big.NewInt(15000000), fee.L1BaseFee)
+	assert.Equal(t, new(big.Int).Mul(new(big.Int).Mul(fee.L1GasUsed, fee.L1BaseFee), big.NewInt(2)), fee.L1DataFee)
+
+	dataFee, err := EstimateL1DataFeeForMessage(msg, nil, big.NewInt(1), signer, state)
+	require.NoError(t, err)
+	assert.Equal(t, fee.L1DataFee, dataFee)
+}
@@ -0,0 +1,122 @@
+// Package redis implements a minimal redis client for the rollup services that publish
+// data to redis. It speaks the RESP protocol directly so that no client library is required.
+package redis
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// defaultTimeout is the maximum duration of a single round trip to the redis server.
+const defaultTimeout = 10 * time.Second
+
+// Client sends commands to a redis server over a single connection. The connection is
+// established on the first command and re-established after a failure. It is safe for
+// concurrent use.
+type Client struct {
+	Addr     string
+	Password string
+
+	mu   sync.Mutex
+	conn net.Conn
+	rd   *bufio.Reader
+}
+
+// NewClient creates a client for the server of the given redis:// url. The path of the
+// url is left to the caller.
+func NewClient(u *url.URL) (*Client, error) {
+	if u.Host == "" {
+		return nil, fmt.Errorf("missing host in redis url")
+	}
+	addr := u.Host
+	if u.Port() == "" {
+		addr = net.JoinHostPort(u.Hostname(), "6379")
+	}
+	password, _ := u.User.Password()
+	return &Client{Addr: addr, Password: password}, nil
+}
+
+// Do sends a single command and returns the first line of the reply.
+func (c *Client) Do(ctx context.Context, args ...string) (string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if err := c.connect(ctx); err != nil {
+		return "", err
+	}
+	reply, err := c.do(ctx, args...)
+	if err != nil {
+		// drop the connection, we will reconnect on the next call
+		c.closeConn()
+	}
+	return reply, err
+}
+
+// Close closes the connection to the server.
+func (c *Client) Close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.closeConn()
+	return nil
+}
+
+func (c *Client) connect(ctx context.Context) error {
+	if c.conn != nil {
+		return nil
+	}
+	var d net.Dialer
+	conn, err := d.DialContext(ctx, "tcp", c.Addr)
+	if err != nil {
+		return fmt.Errorf("failed to connect to redis at %v: %w", c.Addr, err)
+	}
+	c.conn = conn
+	c.rd = bufio.NewReader(conn)
+	if c.Password != "" {
+		if _, err := c.do(ctx, "AUTH", c.Password); err != nil {
+			c.closeConn()
+			return fmt.Errorf("redis authentication failed: %w", err)
+		}
+	}
+	return nil
+}
+
+func (c *Client) closeConn() {
+	if c.conn != nil {
+		c.conn.Close()
+		c.conn = nil
+		c.rd = nil
+	}
+}
+
+func (c *Client) do(ctx context.Context, args ...string) (string, error) {
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		deadline = time.Now().Add(defaultTimeout)
+	}
+	c.conn.SetDeadline(deadline)
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "*%d\r\n", len(args))
+	for _, arg := range args {
+		fmt.Fprintf(&sb, "$%d\r\n%s\r\n", len(arg), arg)
+	}
+	if _, err := c.conn.Write([]byte(sb.String())); err != nil {
+		return "", err
+	}
+
+	line, err := c.rd.ReadString('\n')
+	if err != nil {
+		return "", err
+	}
+	line = strings.TrimSuffix(line, "\r\n")
+	if strings.HasPrefix(line, "-") {
+		return "", fmt.Errorf("redis error: %s", line[1:])
+	}
+	return line, nil
+}
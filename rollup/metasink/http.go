@@ -0,0 +1,62 @@
+package metasink
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// defaultHTTPTimeout is the maximum duration of a single put request.
+const defaultHTTPTimeout = 10 * time.Second
+
+// httpSink puts each record as a JSON document to <endpoint>/batch/<index>/<kind>.
+type httpSink struct {
+	endpoint string
+	client   *http.Client
+}
+
+func newHTTPSink(u *url.URL) *httpSink {
+	return &httpSink{
+		endpoint: strings.TrimSuffix(u.String(), "/"),
+		client:   &http.Client{Timeout: defaultHTTPTimeout},
+	}
+}
+
+func (s *httpSink) url(record *Record) string {
+	return fmt.Sprintf("%s/batch/%d/%s", s.endpoint, record.BatchIndex, record.Kind)
+}
+
+func (s *httpSink) Put(ctx context.Context, record *Record) error {
+	body, err := json.Marshal(record.Value)
+	if err != nil {
+		return fmt.Errorf("failed to marshal %v batch record: %w", record.Kind, err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, s.url(record), bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to put %v batch record: %w", record.Kind, err)
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("unexpected response status from metadata sink: %v", resp.Status)
+	}
+	return nil
+}
+
+func (s *httpSink) Close() error {
+	s.client.CloseIdleConnections()
+	return nil
+}
@@ -0,0 +1,122 @@
+// Package metasink writes the rollup batch metadata to an external key-value store, so that
+// operators can index new batches into their own systems without polling the RPC API.
+package metasink
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+
+	"github.com/scroll-tech/go-ethereum/common"
+
+	"github.com/scroll-tech/go-ethereum/rollup/eventbus"
+)
+
+// Kinds of batch metadata records. Every kind is stored under its own key, a batch that is
+// reverted and committed again keeps its reverted record.
+const (
+	KindCommitted = "committed"
+	KindFinalized = "finalized"
+	KindReverted  = "reverted"
+)
+
+// Record is the metadata of a batch event, it is stored as a JSON document under the
+// key batch/<index>/<kind> (batch:<index>:<kind> in redis) relative to the sink prefix.
+type Record struct {
+	Kind       string
+	BatchIndex uint64
+	Value      interface{}
+}
+
+// CommittedBatch is the value of a KindCommitted record.
+type CommittedBatch struct {
+	BatchIndex       uint64      `json:"batchIndex"`
+	BatchHash        common.Hash `json:"batchHash"`
+	StartBlockNumber uint64      `json:"startBlockNumber"`
+	EndBlockNumber   uint64      `json:"endBlockNumber"`
+	L1BlockNumber    uint64      `json:"l1BlockNumber"`
+	L1TxHash         common.Hash `json:"l1TxHash"`
+}
+
+// FinalizedBatch is the value of a KindFinalized record.
+type FinalizedBatch struct {
+	BatchIndex       uint64      `json:"batchIndex"`
+	BatchHash        common.Hash `json:"batchHash"`
+	StartBlockNumber uint64      `json:"startBlockNumber"`
+	EndBlockNumber   uint64      `json:"endBlockNumber"`
+	StateRoot        common.Hash `json:"stateRoot"`
+	WithdrawRoot     common.Hash `json:"withdrawRoot"`
+	L1BlockNumber    uint64      `json:"l1BlockNumber"`
+	L1TxHash         common.Hash `json:"l1TxHash"`
+}
+
+// RevertedBatch is the value of a KindReverted record.
+type RevertedBatch struct {
+	BatchIndex    uint64      `json:"batchIndex"`
+	BatchHash     common.Hash `json:"batchHash"`
+	L1BlockNumber uint64      `json:"l1BlockNumber"`
+	L1TxHash      common.Hash `json:"l1TxHash"`
+}
+
+// CommittedRecord returns the record of a committed batch.
+func CommittedRecord(ev eventbus.BatchCommittedEvent) *Record {
+	return &Record{Kind: KindCommitted, BatchIndex: ev.BatchIndex, Value: &CommittedBatch{
+		BatchIndex:       ev.BatchIndex,
+		BatchHash:        ev.BatchHash,
+		StartBlockNumber: ev.StartBlockNumber,
+		EndBlockNumber:   ev.EndBlockNumber,
+		L1BlockNumber:    ev.L1BlockNumber,
+		L1TxHash:         ev.L1TxHash,
+	}}
+}
+
+// FinalizedRecord returns the record of a finalized batch.
+func FinalizedRecord(ev eventbus.BatchFinalizedEvent) *Record {
+	return &Record{Kind: KindFinalized, BatchIndex: ev.BatchIndex, Value: &FinalizedBatch{
+		BatchIndex:       ev.BatchIndex,
+		BatchHash:        ev.BatchHash,
+		StartBlockNumber: ev.StartBlockNumber,
+		EndBlockNumber:   ev.EndBlockNumber,
+		StateRoot:        ev.StateRoot,
+		WithdrawRoot:     ev.WithdrawRoot,
+		L1BlockNumber:    ev.L1BlockNumber,
+		L1TxHash:         ev.L1TxHash,
+	}}
+}
+
+// RevertedRecord returns the record of a reverted batch.
+func RevertedRecord(ev eventbus.BatchRevertedEvent) *Record {
+	return &Record{Kind: KindReverted, BatchIndex: ev.BatchIndex, Value: &RevertedBatch{
+		BatchIndex:    ev.BatchIndex,
+		BatchHash:     ev.BatchHash,
+		L1BlockNumber: ev.L1BlockNumber,
+		L1TxHash:      ev.L1TxHash,
+	}}
+}
+
+// Sink is an external key-value store that accepts batch metadata records.
+type Sink interface {
+	// Put stores a record, replacing any previous record under the same key.
+	Put(ctx context.Context, record *Record) error
+
+	// Close releases any resources held by the sink.
+	Close() error
+}
+
+// New creates a metadata sink from the given URL.
+// Supported schemes are redis:// (the path is used as key prefix) and http:// or https://
+// (records are PUT to <url>/batch/<index>/<kind>).
+func New(rawurl string) (Sink, error) {
+	u, err := url.Parse(rawurl)
+	if err != nil {
+		return nil, fmt.Errorf("invalid metadata sink url %q: %w", rawurl, err)
+	}
+	switch u.Scheme {
+	case "redis":
+		return newRedisSink(u)
+	case "http", "https":
+		return newHTTPSink(u), nil
+	default:
+		return nil, fmt.Errorf("unsupported metadata sink scheme: %q", u.Scheme)
+	}
+}
@@ -0,0 +1,129 @@
+package metasink
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/scroll-tech/go-ethereum/common"
+
+	"github.com/scroll-tech/go-ethereum/rollup/eventbus"
+)
+
+func testRecord() *Record {
+	return FinalizedRecord(eventbus.BatchFinalizedEvent{
+		BatchIndex:       7,
+		BatchHash:        common.HexToHash("0x01"),
+		StartBlockNumber: 10,
+		EndBlockNumber:   20,
+		StateRoot:        common.HexToHash("0x02"),
+		WithdrawRoot:     common.HexToHash("0x03"),
+		L1BlockNumber:    100,
+		L1TxHash:         common.HexToHash("0x04"),
+	})
+}
+
+func TestNew(t *testing.T) {
+	_, err := New("ftp://localhost")
+	assert.Error(t, err)
+
+	_, err = New("redis://")
+	assert.Error(t, err)
+
+	s, err := New("redis://:secret@localhost/scroll")
+	require.NoError(t, err)
+	rs := s.(*redisSink)
+	assert.Equal(t, "localhost:6379", rs.client.Addr)
+	assert.Equal(t, "secret", rs.client.Password)
+	assert.Equal(t, "scroll:batch:7:finalized", rs.key(testRecord()))
+
+	s, err = New("redis://localhost:6380")
+	require.NoError(t, err)
+	assert.Equal(t, defaultRedisPrefix, s.(*redisSink).prefix)
+
+	s, err = New("https://example.com/rollup/")
+	require.NoError(t, err)
+	assert.Equal(t, "https://example.com/rollup/batch/7/finalized", s.(*httpSink).url(testRecord()))
+}
+
+func TestHTTPSink(t *testing.T) {
+	var (
+		path     string
+		received FinalizedBatch
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPut, r.Method)
+		path = r.URL.Path
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&received))
+	}))
+	defer srv.Close()
+
+	s, err := New(srv.URL + "/meta")
+	require.NoError(t, err)
+	defer s.Close()
+
+	record := testRecord()
+	require.NoError(t, s.Put(context.Background(), record))
+	assert.Equal(t, "/meta/batch/7/finalized", path)
+	assert.Equal(t, *record.Value.(*FinalizedBatch), received)
+
+	srv.Config.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	})
+	assert.Error(t, s.Put(context.Background(), record))
+}
+
+func TestRedisSink(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer ln.Close()
+
+	commands := make(chan []string, 1)
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		rd := bufio.NewReader(conn)
+		// read a single RESP array of bulk strings
+		header, _ := rd.ReadString('\n')
+		var n int
+		if _, err := fmt.Sscanf(header, "*%d\r\n", &n); err != nil {
+			return
+		}
+		args := make([]string, n)
+		for i := 0; i < n; i++ {
+			rd.ReadString('\n') // length prefix
+			arg, _ := rd.ReadString('\n')
+			args[i] = strings.TrimSuffix(arg, "\r\n")
+		}
+		conn.Write([]byte("+OK\r\n"))
+		commands <- args
+	}()
+
+	s, err := New("redis://" + ln.Addr().String() + "/scroll")
+	require.NoError(t, err)
+	defer s.Close()
+
+	record := CommittedRecord(eventbus.BatchCommittedEvent{BatchIndex: 3, BatchHash: common.HexToHash("0x01"), StartBlockNumber: 1, EndBlockNumber: 2})
+	require.NoError(t, s.Put(context.Background(), record))
+
+	args := <-commands
+	require.Len(t, args, 3)
+	assert.Equal(t, "SET", args[0])
+	assert.Equal(t, "scroll:batch:3:committed", args[1])
+
+	var received CommittedBatch
+	require.NoError(t, json.Unmarshal([]byte(args[2]), &received))
+	assert.Equal(t, *record.Value.(*CommittedBatch), received)
+}
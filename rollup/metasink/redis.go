@@ -0,0 +1,51 @@
+package metasink
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/scroll-tech/go-ethereum/rollup/internal/redis"
+)
+
+// defaultRedisPrefix is the key prefix of the records if the url does not specify one.
+const defaultRedisPrefix = "rollup"
+
+// redisSink stores each record as a JSON document under <prefix>:batch:<index>:<kind> using SET.
+type redisSink struct {
+	client *redis.Client
+	prefix string
+}
+
+func newRedisSink(u *url.URL) (*redisSink, error) {
+	client, err := redis.NewClient(u)
+	if err != nil {
+		return nil, err
+	}
+	prefix := strings.TrimPrefix(u.Path, "/")
+	if prefix == "" {
+		prefix = defaultRedisPrefix
+	}
+	return &redisSink{client: client, prefix: prefix}, nil
+}
+
+func (s *redisSink) key(record *Record) string {
+	return fmt.Sprintf("%s:batch:%d:%s", s.prefix, record.BatchIndex, record.Kind)
+}
+
+func (s *redisSink) Put(ctx context.Context, record *Record) error {
+	payload, err := json.Marshal(record.Value)
+	if err != nil {
+		return fmt.Errorf("failed to marshal %v batch record: %w", record.Kind, err)
+	}
+	if _, err := s.client.Do(ctx, "SET", s.key(record), string(payload)); err != nil {
+		return fmt.Errorf("failed to store %v batch record: %w", record.Kind, err)
+	}
+	return nil
+}
+
+func (s *redisSink) Close() error {
+	return s.client.Close()
+}
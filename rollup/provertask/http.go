@@ -0,0 +1,57 @@
+package provertask
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// defaultHTTPTimeout is the maximum duration of a single enqueue request.
+const defaultHTTPTimeout = 10 * time.Second
+
+// httpBackend posts each task as a JSON document to an HTTP endpoint.
+type httpBackend struct {
+	endpoint string
+	client   *http.Client
+}
+
+func newHTTPBackend(u *url.URL) *httpBackend {
+	return &httpBackend{
+		endpoint: u.String(),
+		client:   &http.Client{Timeout: defaultHTTPTimeout},
+	}
+}
+
+func (b *httpBackend) Enqueue(ctx context.Context, task *Task) error {
+	body, err := json.Marshal(task)
+	if err != nil {
+		return fmt.Errorf("failed to marshal prover task: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, b.endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := b.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to post prover task: %w", err)
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("unexpected response status from prover task queue: %v", resp.Status)
+	}
+	return nil
+}
+
+func (b *httpBackend) Close() error {
+	b.client.CloseIdleConnections()
+	return nil
+}
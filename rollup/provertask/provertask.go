@@ -0,0 +1,49 @@
+package provertask
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+
+	"github.com/scroll-tech/go-ethereum/common"
+	"github.com/scroll-tech/go-ethereum/core/rawdb"
+)
+
+// Task describes a batch that has been committed on L1 and is ready to be proven.
+type Task struct {
+	BatchIndex       uint64                   `json:"batchIndex"`
+	BatchHash        common.Hash              `json:"batchHash"`
+	CommitTxHash     common.Hash              `json:"commitTxHash"`
+	L1BlockNumber    uint64                   `json:"l1BlockNumber"`
+	ChunkBlockRanges []*rawdb.ChunkBlockRange `json:"chunkBlockRanges"`
+	// ChunkHashes is only populated if all blocks of the batch are available locally.
+	ChunkHashes []common.Hash `json:"chunkHashes,omitempty"`
+	// TracesAvailable indicates whether this node can serve the execution traces of all blocks in the batch.
+	TracesAvailable bool `json:"tracesAvailable"`
+}
+
+// Backend is a queue that accepts prover task descriptors.
+type Backend interface {
+	// Enqueue pushes a task to the queue.
+	Enqueue(ctx context.Context, task *Task) error
+
+	// Close releases any resources held by the backend.
+	Close() error
+}
+
+// New creates a prover task queue backend from the given URL.
+// Supported schemes are redis:// (the path is used as list key) and http:// or https://.
+func New(rawurl string) (Backend, error) {
+	u, err := url.Parse(rawurl)
+	if err != nil {
+		return nil, fmt.Errorf("invalid prover task queue url %q: %w", rawurl, err)
+	}
+	switch u.Scheme {
+	case "redis":
+		return newRedisBackend(u)
+	case "http", "https":
+		return newHTTPBackend(u), nil
+	default:
+		return nil, fmt.Errorf("unsupported prover task queue scheme: %q", u.Scheme)
+	}
+}
@@ -0,0 +1,117 @@
+package provertask
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/scroll-tech/go-ethereum/common"
+	"github.com/scroll-tech/go-ethereum/core/rawdb"
+)
+
+func testTask() *Task {
+	return &Task{
+		BatchIndex:       7,
+		BatchHash:        common.HexToHash("0x01"),
+		CommitTxHash:     common.HexToHash("0x02"),
+		L1BlockNumber:    100,
+		ChunkBlockRanges: []*rawdb.ChunkBlockRange{{StartBlockNumber: 1, EndBlockNumber: 3}},
+		ChunkHashes:      []common.Hash{common.HexToHash("0x03")},
+		TracesAvailable:  true,
+	}
+}
+
+func TestNew(t *testing.T) {
+	_, err := New("ftp://localhost")
+	assert.Error(t, err)
+
+	_, err = New("redis://")
+	assert.Error(t, err)
+
+	b, err := New("redis://:secret@localhost/tasks")
+	require.NoError(t, err)
+	rb := b.(*redisBackend)
+	assert.Equal(t, "localhost:6379", rb.client.Addr)
+	assert.Equal(t, "secret", rb.client.Password)
+	assert.Equal(t, "tasks", rb.key)
+
+	b, err = New("redis://localhost:6380")
+	require.NoError(t, err)
+	assert.Equal(t, defaultRedisKey, b.(*redisBackend).key)
+
+	b, err = New("https://example.com/tasks")
+	require.NoError(t, err)
+	assert.IsType(t, &httpBackend{}, b)
+}
+
+func TestHTTPBackend(t *testing.T) {
+	var received Task
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&received))
+	}))
+	defer srv.Close()
+
+	b, err := New(srv.URL)
+	require.NoError(t, err)
+	defer b.Close()
+
+	task := testTask()
+	require.NoError(t, b.Enqueue(context.Background(), task))
+	assert.Equal(t, *task, received)
+}
+
+func TestRedisBackend(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer ln.Close()
+
+	commands := make(chan []string, 1)
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		rd := bufio.NewReader(conn)
+		// read a single RESP array of bulk strings
+		header, _ := rd.ReadString('\n')
+		var n int
+		if _, err := fmt.Sscanf(header, "*%d\r\n", &n); err != nil {
+			return
+		}
+		args := make([]string, n)
+		for i := 0; i < n; i++ {
+			rd.ReadString('\n') // length prefix
+			arg, _ := rd.ReadString('\n')
+			args[i] = strings.TrimSuffix(arg, "\r\n")
+		}
+		conn.Write([]byte(":1\r\n"))
+		commands <- args
+	}()
+
+	b, err := New("redis://" + ln.Addr().String() + "/queue")
+	require.NoError(t, err)
+	defer b.Close()
+
+	task := testTask()
+	require.NoError(t, b.Enqueue(context.Background(), task))
+
+	args := <-commands
+	require.Len(t, args, 3)
+	assert.Equal(t, "RPUSH", args[0])
+	assert.Equal(t, "queue", args[1])
+
+	var received Task
+	require.NoError(t, json.Unmarshal([]byte(args[2]), &received))
+	assert.Equal(t, *task, received)
+}
@@ -0,0 +1,47 @@
+package provertask
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/scroll-tech/go-ethereum/rollup/internal/redis"
+)
+
+// defaultRedisKey is the list that tasks are pushed to if the url does not specify one.
+const defaultRedisKey = "prover_tasks"
+
+// redisBackend pushes each task as a JSON document to a redis list using RPUSH.
+type redisBackend struct {
+	client *redis.Client
+	key    string
+}
+
+func newRedisBackend(u *url.URL) (*redisBackend, error) {
+	client, err := redis.NewClient(u)
+	if err != nil {
+		return nil, err
+	}
+	key := strings.TrimPrefix(u.Path, "/")
+	if key == "" {
+		key = defaultRedisKey
+	}
+	return &redisBackend{client: client, key: key}, nil
+}
+
+func (b *redisBackend) Enqueue(ctx context.Context, task *Task) error {
+	payload, err := json.Marshal(task)
+	if err != nil {
+		return fmt.Errorf("failed to marshal prover task: %w", err)
+	}
+	if _, err := b.client.Do(ctx, "RPUSH", b.key, string(payload)); err != nil {
+		return fmt.Errorf("failed to push prover task: %w", err)
+	}
+	return nil
+}
+
+func (b *redisBackend) Close() error {
+	return b.client.Close()
+}
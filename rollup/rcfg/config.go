@@ -29,4 +29,9 @@ var (
 	L1BaseFeeSlot           = common.BigToHash(big.NewInt(1))
 	OverheadSlot            = common.BigToHash(big.NewInt(2))
 	ScalarSlot              = common.BigToHash(big.NewInt(3))
+
+	// slots of the L1GasPriceOracle that are set from the Curie upgrade of the predeploy on
+	L1BlobBaseFeeSlot = common.BigToHash(big.NewInt(5))
+	CommitScalarSlot  = common.BigToHash(big.NewInt(6))
+	BlobScalarSlot    = common.BigToHash(big.NewInt(7))
 )
@@ -0,0 +1,75 @@
+package rollup_sync_service
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/scroll-tech/go-ethereum/common"
+	"github.com/scroll-tech/go-ethereum/core/rawdb"
+)
+
+// MissingBlockReason describes why a block of a batch is not available locally.
+type MissingBlockReason string
+
+const (
+	// BlockNotSynced means the block is above the local chain head.
+	BlockNotSynced MissingBlockReason = "not-synced"
+
+	// BlockPruned means the block is below the local chain head, but its body is not stored.
+	BlockPruned MissingBlockReason = "pruned"
+)
+
+// MissingBlockRange is a range of consecutive blocks missing for the same reason.
+type MissingBlockRange struct {
+	From   uint64             `json:"from"`
+	To     uint64             `json:"to"`
+	Reason MissingBlockReason `json:"reason"`
+}
+
+// MissingBlocksError is returned if blocks in the chunk ranges of a batch are not available locally.
+type MissingBlocksError struct {
+	BatchIndex uint64              `json:"batchIndex"`
+	Head       uint64              `json:"head"` // local chain head when the blocks were checked
+	Missing    []MissingBlockRange `json:"missing"`
+}
+
+func (e *MissingBlocksError) Error() string {
+	ranges := make([]string, len(e.Missing))
+	for i, r := range e.Missing {
+		ranges[i] = fmt.Sprintf("%d-%d (%s)", r.From, r.To, r.Reason)
+	}
+	return fmt.Sprintf("missing local blocks of batch %d, local head: %d, missing: %s", e.BatchIndex, e.Head, strings.Join(ranges, ", "))
+}
+
+// checkBlockAvailability verifies that all blocks in the chunk ranges of a batch are stored locally.
+// It returns a *MissingBlocksError listing the missing blocks otherwise, which is also reported by Status
+// until a later check succeeds.
+func (s *RollupSyncService) checkBlockAvailability(batchIndex uint64, chunkBlockRanges []*rawdb.ChunkBlockRange) error {
+	head := s.bc.CurrentBlock().NumberU64()
+	var missing []MissingBlockRange
+	for number := chunkBlockRanges[0].StartBlockNumber; number <= chunkBlockRanges[len(chunkBlockRanges)-1].EndBlockNumber; number++ {
+		var reason MissingBlockReason
+		if number > head {
+			reason = BlockNotSynced
+		} else if hash := s.bc.GetCanonicalHash(number); hash == (common.Hash{}) || !s.bc.HasBlock(hash, number) {
+			reason = BlockPruned
+		} else {
+			continue
+		}
+		if n := len(missing); n > 0 && missing[n-1].To+1 == number && missing[n-1].Reason == reason {
+			missing[n-1].To = number
+			continue
+		}
+		missing = append(missing, MissingBlockRange{From: number, To: number, Reason: reason})
+	}
+
+	s.missingBlocksLock.Lock()
+	defer s.missingBlocksLock.Unlock()
+
+	if len(missing) == 0 {
+		s.missingBlocks = nil
+		return nil
+	}
+	s.missingBlocks = &MissingBlocksError{BatchIndex: batchIndex, Head: head, Missing: missing}
+	return s.missingBlocks
+}
@@ -0,0 +1,53 @@
+package rollup_sync_service
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/scroll-tech/go-ethereum/consensus/ethash"
+	"github.com/scroll-tech/go-ethereum/core"
+	"github.com/scroll-tech/go-ethereum/core/rawdb"
+	"github.com/scroll-tech/go-ethereum/core/vm"
+	"github.com/scroll-tech/go-ethereum/params"
+)
+
+func TestCheckBlockAvailability(t *testing.T) {
+	db := rawdb.NewMemoryDatabase()
+	genesis := (&core.Genesis{Config: params.TestChainConfig}).MustCommit(db)
+	blocks, _ := core.GenerateChain(params.TestChainConfig, genesis, ethash.NewFaker(), rawdb.NewMemoryDatabase(), 5, nil)
+	bc, err := core.NewBlockChain(db, nil, params.TestChainConfig, ethash.NewFaker(), vm.Config{}, nil, nil)
+	require.NoError(t, err)
+	_, err = bc.InsertChain(blocks)
+	require.NoError(t, err)
+	bc.Stop()
+
+	// drop the body of block 2 and reopen the chain, so that it is not cached
+	rawdb.DeleteBody(db, blocks[1].Hash(), 2)
+	bc, err = core.NewBlockChain(db, nil, params.TestChainConfig, ethash.NewFaker(), vm.Config{}, nil, nil)
+	require.NoError(t, err)
+	defer bc.Stop()
+
+	service := &RollupSyncService{db: db, bc: bc}
+	require.NoError(t, service.checkBlockAvailability(1, []*rawdb.ChunkBlockRange{{StartBlockNumber: 3, EndBlockNumber: 5}}))
+
+	err = service.checkBlockAvailability(2, []*rawdb.ChunkBlockRange{{StartBlockNumber: 1, EndBlockNumber: 3}, {StartBlockNumber: 4, EndBlockNumber: 8}})
+	var missingErr *MissingBlocksError
+	require.True(t, errors.As(err, &missingErr))
+	assert.Equal(t, &MissingBlocksError{
+		BatchIndex: 2,
+		Head:       5,
+		Missing: []MissingBlockRange{
+			{From: 2, To: 2, Reason: BlockPruned},
+			{From: 6, To: 8, Reason: BlockNotSynced},
+		},
+	}, missingErr)
+	assert.EqualError(t, err, "missing local blocks of batch 2, local head: 5, missing: 2-2 (pruned), 6-8 (not-synced)")
+	assert.Equal(t, missingErr, service.Status().MissingBlocks)
+
+	// a successful check clears the reported missing blocks
+	require.NoError(t, service.checkBlockAvailability(3, []*rawdb.ChunkBlockRange{{StartBlockNumber: 4, EndBlockNumber: 5}}))
+	assert.Nil(t, service.Status().MissingBlocks)
+}
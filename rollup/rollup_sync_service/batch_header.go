@@ -26,9 +26,38 @@ type BatchHeader struct {
 
 // NewBatchHeader creates a new BatchHeader
 func NewBatchHeader(version uint8, batchIndex, totalL1MessagePoppedBefore uint64, parentBatchHash common.Hash, chunks []*Chunk) (*BatchHeader, error) {
+	bitmapBytes, chunkL1MessagePoppedBefore, totalL1MessagePopped, err := skippedL1MessageBitmap(batchIndex, totalL1MessagePoppedBefore, chunks)
+	if err != nil {
+		return nil, err
+	}
+
 	// buffer for storing chunk hashes in order to compute the batch data hash
 	var dataBytes []byte
+	for chunkID, chunk := range chunks {
+		chunkHash, err := chunk.Hash(chunkL1MessagePoppedBefore[chunkID])
+		if err != nil {
+			return nil, err
+		}
+		dataBytes = append(dataBytes, chunkHash.Bytes()...)
+	}
+
+	// compute data hash
+	dataHash := crypto.Keccak256Hash(dataBytes)
 
+	return &BatchHeader{
+		version:                version,
+		batchIndex:             batchIndex,
+		l1MessagePopped:        totalL1MessagePopped - totalL1MessagePoppedBefore,
+		totalL1MessagePopped:   totalL1MessagePopped,
+		dataHash:               dataHash,
+		parentBatchHash:        parentBatchHash,
+		skippedL1MessageBitmap: bitmapBytes,
+	}, nil
+}
+
+// skippedL1MessageBitmap computes the skipped L1 message bitmap of a batch. It also returns the total
+// number of L1 messages popped before each chunk and the total number popped after the batch.
+func skippedL1MessageBitmap(batchIndex, totalL1MessagePoppedBefore uint64, chunks []*Chunk) ([]byte, []uint64, uint64, error) {
 	// skipped L1 message bitmap, an array of 256-bit bitmaps
 	var skippedBitmap []*big.Int
 
@@ -38,14 +67,9 @@ func NewBatchHeader(version uint8, batchIndex, totalL1MessagePoppedBefore uint64
 	// the next queue index that we need to process
 	nextIndex := totalL1MessagePoppedBefore
 
+	chunkL1MessagePoppedBefore := make([]uint64, len(chunks))
 	for chunkID, chunk := range chunks {
-		// build data hash
-		totalL1MessagePoppedBeforeChunk := nextIndex
-		chunkHash, err := chunk.Hash(totalL1MessagePoppedBeforeChunk)
-		if err != nil {
-			return nil, err
-		}
-		dataBytes = append(dataBytes, chunkHash.Bytes()...)
+		chunkL1MessagePoppedBefore[chunkID] = nextIndex
 
 		// build skip bitmap
 		for blockID, block := range chunk.Blocks {
@@ -56,7 +80,7 @@ func NewBatchHeader(version uint8, batchIndex, totalL1MessagePoppedBefore uint64
 				currentIndex := tx.Nonce
 
 				if currentIndex < nextIndex {
-					return nil, fmt.Errorf("unexpected batch payload, expected queue index: %d, got: %d. Batch index: %d, chunk index in batch: %d, block index in chunk: %d, block hash: %v, transaction hash: %v", nextIndex, currentIndex, batchIndex, chunkID, blockID, block.Header.Hash(), tx.TxHash)
+					return nil, nil, 0, fmt.Errorf("unexpected batch payload, expected queue index: %d, got: %d. Batch index: %d, chunk index in batch: %d, block index in chunk: %d, block hash: %v, transaction hash: %v", nextIndex, currentIndex, batchIndex, chunkID, blockID, block.Header.Hash(), tx.TxHash)
 				}
 
 				// mark skipped messages
@@ -82,9 +106,6 @@ func NewBatchHeader(version uint8, batchIndex, totalL1MessagePoppedBefore uint64
 		}
 	}
 
-	// compute data hash
-	dataHash := crypto.Keccak256Hash(dataBytes)
-
 	// compute skipped bitmap
 	bitmapBytes := make([]byte, len(skippedBitmap)*32)
 	for ii, num := range skippedBitmap {
@@ -92,16 +113,7 @@ func NewBatchHeader(version uint8, batchIndex, totalL1MessagePoppedBefore uint64
 		padding := 32 - len(bytes)
 		copy(bitmapBytes[32*ii+padding:], bytes)
 	}
-
-	return &BatchHeader{
-		version:                version,
-		batchIndex:             batchIndex,
-		l1MessagePopped:        nextIndex - totalL1MessagePoppedBefore,
-		totalL1MessagePopped:   nextIndex,
-		dataHash:               dataHash,
-		parentBatchHash:        parentBatchHash,
-		skippedL1MessageBitmap: bitmapBytes,
-	}, nil
+	return bitmapBytes, chunkL1MessagePoppedBefore, nextIndex, nil
 }
 
 // Encode encodes the BatchHeader into RollupV2 BatchHeaderV0Codec Encoding.
@@ -0,0 +1,155 @@
+package rollup_sync_service
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/scroll-tech/go-ethereum/common/hexutil"
+	"github.com/scroll-tech/go-ethereum/core/rawdb"
+)
+
+// BenchReport holds the results of a rollup pipeline benchmark run.
+type BenchReport struct {
+	Iterations int `json:"iterations"`
+
+	// Decoding of commitBatch calldata into chunk block contexts.
+	CalldataFixtures int           `json:"calldataFixtures"`
+	CalldataBytes    int           `json:"calldataBytes"`    // per iteration
+	DecodedChunks    int           `json:"decodedChunks"`    // per iteration
+	DecodedBlocks    int           `json:"decodedBlocks"`    // per iteration
+	DecodeTime       time.Duration `json:"decodeTimeNs"`     // over all iterations
+	DecodeBlockRate  float64       `json:"decodeBlocksPerS"` // decoded block contexts per second
+
+	// Validation, i.e. computing the batch meta data from local block data.
+	BlockFixtures     int           `json:"blockFixtures"`
+	ValidateTime      time.Duration `json:"validateTimeNs"`     // over all iterations
+	ValidateBlockRate float64       `json:"validateBlocksPerS"` // validated blocks per second
+}
+
+// benchFixtures are the recorded inputs of a benchmark run.
+type benchFixtures struct {
+	calldata [][]byte
+	chunks   []*Chunk
+}
+
+// loadBenchFixtures reads all .json files of a directory. Files with a "calldata"
+// field hold the hex encoded calldata of a commitBatch transaction, all other files
+// hold a block trace, which is validated as a chunk of a single block.
+func loadBenchFixtures(dir string) (*benchFixtures, error) {
+	files, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(files)
+
+	fixtures := &benchFixtures{}
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+		var tx struct {
+			Calldata *string `json:"calldata"`
+		}
+		if err := json.Unmarshal(data, &tx); err != nil {
+			return nil, fmt.Errorf("failed to parse fixture %v: %w", file, err)
+		}
+		if tx.Calldata != nil {
+			txData, err := hexutil.Decode(*tx.Calldata)
+			if err != nil {
+				return nil, fmt.Errorf("failed to hex-decode calldata of fixture %v: %w", file, err)
+			}
+			fixtures.calldata = append(fixtures.calldata, txData)
+			continue
+		}
+		block := &WrappedBlock{}
+		if err := json.Unmarshal(data, block); err != nil {
+			return nil, fmt.Errorf("failed to parse block trace fixture %v: %w", file, err)
+		}
+		if block.Header == nil {
+			return nil, fmt.Errorf("fixture %v is neither commitBatch calldata nor a block trace", file)
+		}
+		fixtures.chunks = append(fixtures.chunks, &Chunk{Blocks: []*WrappedBlock{block}})
+	}
+	if len(fixtures.calldata) == 0 && len(fixtures.chunks) == 0 {
+		return nil, fmt.Errorf("no fixtures found in %v", dir)
+	}
+	return fixtures, nil
+}
+
+// RunBench measures the throughput of decoding and validating the recorded
+// fixtures in dir, processing every fixture the given number of times.
+func RunBench(dir string, iterations int) (*BenchReport, error) {
+	if iterations <= 0 {
+		return nil, errors.New("number of iterations must be positive")
+	}
+	fixtures, err := loadBenchFixtures(dir)
+	if err != nil {
+		return nil, err
+	}
+	scrollChainABI, err := scrollChainMetaData.GetAbi()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get scroll chain abi: %w", err)
+	}
+
+	report := &BenchReport{
+		Iterations:       iterations,
+		CalldataFixtures: len(fixtures.calldata),
+		BlockFixtures:    len(fixtures.chunks),
+	}
+	for _, txData := range fixtures.calldata {
+		report.CalldataBytes += len(txData)
+	}
+
+	start := time.Now()
+	for i := 0; i < iterations; i++ {
+		chunks, blocks := 0, 0
+		for _, txData := range fixtures.calldata {
+			calldata, err := decodeCommitBatchCalldata(scrollChainABI, txData)
+			if err != nil {
+				return nil, err
+			}
+			codec, err := CodecForVersion(calldata.Version)
+			if err != nil {
+				return nil, err
+			}
+			chunkBlockContexts, err := codec.DecodeChunkBlockContexts(calldata.Chunks)
+			if err != nil {
+				return nil, err
+			}
+			chunks += len(chunkBlockContexts)
+			for _, blockContexts := range chunkBlockContexts {
+				blocks += len(blockContexts)
+			}
+		}
+		report.DecodedChunks, report.DecodedBlocks = chunks, blocks
+	}
+	report.DecodeTime = time.Since(start)
+
+	if len(fixtures.chunks) > 0 {
+		codec, err := CodecForVersion(batchHeaderVersion)
+		if err != nil {
+			return nil, err
+		}
+		start = time.Now()
+		for i := 0; i < iterations; i++ {
+			if _, _, err := computeFinalizedBatchMeta(codec, 0, &rawdb.FinalizedBatchMeta{}, fixtures.chunks); err != nil {
+				return nil, err
+			}
+		}
+		report.ValidateTime = time.Since(start)
+	}
+
+	if report.DecodeTime > 0 {
+		report.DecodeBlockRate = float64(report.DecodedBlocks*iterations) / report.DecodeTime.Seconds()
+	}
+	if report.ValidateTime > 0 {
+		report.ValidateBlockRate = float64(report.BlockFixtures*iterations) / report.ValidateTime.Seconds()
+	}
+	return report, nil
+}
@@ -0,0 +1,33 @@
+package rollup_sync_service
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunBench(t *testing.T) {
+	report, err := RunBench("./testdata", 2)
+	require.NoError(t, err)
+	assert.Equal(t, 2, report.Iterations)
+	assert.Equal(t, 1, report.CalldataFixtures)
+	assert.Equal(t, 8, report.DecodedChunks)
+	assert.Positive(t, report.DecodedBlocks)
+	assert.Equal(t, 4, report.BlockFixtures)
+	assert.Positive(t, report.DecodeBlockRate)
+	assert.Positive(t, report.ValidateBlockRate)
+
+	_, err = RunBench("./testdata", 0)
+	assert.Error(t, err)
+
+	_, err = RunBench(t.TempDir(), 1)
+	assert.ErrorContains(t, err, "no fixtures")
+
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "invalid.json"), []byte(`{"foo": 1}`), 0644))
+	_, err = RunBench(dir, 1)
+	assert.ErrorContains(t, err, "neither")
+}
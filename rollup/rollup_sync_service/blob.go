@@ -0,0 +1,56 @@
+package rollup_sync_service
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/scroll-tech/go-ethereum/common"
+	"github.com/scroll-tech/go-ethereum/core/rawdb"
+	"github.com/scroll-tech/go-ethereum/crypto/kzg4844"
+	"github.com/scroll-tech/go-ethereum/metrics"
+)
+
+var blobMismatchCounter = metrics.NewRegisteredCounter("rollup/sync/blob/mismatches", nil)
+
+// errNoBlobSources is returned if the blob of a batch is required but no blob sources are configured.
+var errNoBlobSources = errors.New("no blob sources configured")
+
+// fetchBlob retrieves the blob with the given versioned hash, committed in the L1 block l1BlockNumber.
+func (s *RollupSyncService) fetchBlob(versionedHash common.Hash, l1BlockNumber uint64) (*kzg4844.Blob, error) {
+	if s.blobProvider == nil {
+		return nil, errNoBlobSources
+	}
+	blockTime, err := s.client.getBlockTime(s.ctx, l1BlockNumber)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get time of L1 block %d: %w", l1BlockNumber, err)
+	}
+	blob, err := s.blobProvider.Blob(s.ctx, versionedHash, blockTime)
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve blob %v: %w", versionedHash.Hex(), err)
+	}
+	return blob, nil
+}
+
+// checkBatchBlob checks the local blocks of a batch committed with a blob codec against the
+// blob of the batch. It does nothing for other codecs or if no blob sources are configured.
+// A blob that does not match the local blocks is reported as errBatchMismatch, failures to
+// retrieve the blob are returned as is, so that the batch is validated again in the next round.
+func (s *RollupSyncService) checkBatchBlob(codec Codec, batchIndex uint64, chunks []*Chunk) error {
+	blobCodec, ok := codec.(BlobCodec)
+	if !ok || s.blobProvider == nil {
+		return nil
+	}
+	batchL1Meta := rawdb.ReadBatchL1Meta(s.db, batchIndex)
+	if batchL1Meta == nil || batchL1Meta.BlobVersionedHash == (common.Hash{}) {
+		return fmt.Errorf("%w: no versioned hash of batch %d", errMissingBatchBlob, batchIndex)
+	}
+	blob, err := s.fetchBlob(batchL1Meta.BlobVersionedHash, batchL1Meta.CommitL1BlockNumber)
+	if err != nil {
+		return fmt.Errorf("batch %d: %w", batchIndex, err)
+	}
+	if err := blobCodec.CheckBlob(blob, chunks); err != nil {
+		blobMismatchCounter.Inc(1)
+		return fmt.Errorf("%w: blob of batch %d: %v", errBatchMismatch, batchIndex, err)
+	}
+	return nil
+}
@@ -0,0 +1,55 @@
+package rollup_sync_service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/scroll-tech/go-ethereum/common"
+	"github.com/scroll-tech/go-ethereum/core/rawdb"
+	"github.com/scroll-tech/go-ethereum/crypto/kzg4844"
+	"github.com/scroll-tech/go-ethereum/rollup/da"
+)
+
+// mapBlobProvider serves the blobs of a map.
+type mapBlobProvider map[common.Hash]*kzg4844.Blob
+
+func (p mapBlobProvider) Blob(ctx context.Context, versionedHash common.Hash, blockTime uint64) (*kzg4844.Blob, error) {
+	if blob, ok := p[versionedHash]; ok {
+		return blob, nil
+	}
+	return nil, da.ErrBlobNotFound
+}
+
+func TestCheckBatchBlob(t *testing.T) {
+	chunks := loadTraceChunks(t, "blockTrace_02.json", "blockTrace_04.json")
+	payload, err := codecV2.payload(chunks)
+	require.NoError(t, err)
+	blob, err := makeBlob(zstdRawFrame(payload))
+	require.NoError(t, err)
+	versionedHash, err := blobVersionedHash(blob)
+	require.NoError(t, err)
+
+	db := rawdb.NewMemoryDatabase()
+	service := &RollupSyncService{ctx: context.Background(), db: db, client: &L1Client{ctx: context.Background(), client: &mockEthClient{}}}
+	assert.NoError(t, service.checkBatchBlob(codecV2, 1, chunks), "no blob sources")
+
+	service.blobProvider = mapBlobProvider{versionedHash: blob}
+	assert.NoError(t, service.checkBatchBlob(codecV0{}, 1, chunks), "calldata codec")
+	assert.ErrorIs(t, service.checkBatchBlob(codecV2, 1, chunks), errMissingBatchBlob)
+
+	rawdb.WriteBatchL1Meta(db, 1, &rawdb.BatchL1Meta{CodecVersion: 2, BlobVersionedHash: versionedHash, CommitL1BlockNumber: 10})
+	assert.NoError(t, service.checkBatchBlob(codecV2, 1, chunks))
+
+	// the blob does not hold the transactions of the local blocks
+	err = service.checkBatchBlob(codecV2, 1, chunks[:1])
+	assert.ErrorIs(t, err, errBatchMismatch)
+
+	// a blob that cannot be retrieved is not a mismatch, the batch is validated again
+	rawdb.WriteBatchL1Meta(db, 1, &rawdb.BatchL1Meta{CodecVersion: 2, BlobVersionedHash: common.Hash{0x01}, CommitL1BlockNumber: 10})
+	err = service.checkBatchBlob(codecV2, 1, chunks)
+	assert.ErrorIs(t, err, da.ErrBlobNotFound)
+	assert.NotErrorIs(t, err, errBatchMismatch)
+}
@@ -14,12 +14,29 @@ import (
 
 const blockContextByteSize = 60
 
+// blockContextHashByteSize is the number of bytes of a block context included in the chunk hash.
+const blockContextHashByteSize = 58
+
 // WrappedBlock contains the block's Header, Transactions and WithdrawTrieRoot hash.
 type WrappedBlock struct {
 	Header *types.Header `json:"header"`
 	// Transactions is only used for recover types.Transactions, the from of types.TransactionData field is missing.
 	Transactions []*types.TransactionData `json:"transactions"`
 	WithdrawRoot common.Hash              `json:"withdraw_trie_root,omitempty"`
+
+	// txs are the transactions of a block read from the local chain. The binary encoding of
+	// typed transactions cannot be rebuilt from Transactions, which assumes legacy transactions.
+	txs types.Transactions
+}
+
+// NewWrappedBlock wraps a block of the local chain.
+func NewWrappedBlock(block *types.Block, withdrawRoot common.Hash) *WrappedBlock {
+	return &WrappedBlock{
+		Header:       block.Header(),
+		Transactions: txsToTxsData(block.Transactions()),
+		WithdrawRoot: withdrawRoot,
+		txs:          block.Transactions(),
+	}
 }
 
 // BlockContext represents the essential data of a block in the ScrollChain.
@@ -142,6 +159,30 @@ func convertTxDataToRLPEncoding(txData *types.TransactionData) ([]byte, error) {
 	return rlpTxData, nil
 }
 
+// l2TransactionsRLP returns the binary encodings of the L2 transactions of the block in block order.
+func (w *WrappedBlock) l2TransactionsRLP() ([][]byte, error) {
+	var encodings [][]byte
+	for i, txData := range w.Transactions {
+		if txData.Type == types.L1MessageTxType {
+			continue
+		}
+		var (
+			rlpTxData []byte
+			err       error
+		)
+		if i < len(w.txs) {
+			rlpTxData, err = w.txs[i].MarshalBinary()
+		} else {
+			rlpTxData, err = convertTxDataToRLPEncoding(txData)
+		}
+		if err != nil {
+			return nil, err
+		}
+		encodings = append(encodings, rlpTxData)
+	}
+	return encodings, nil
+}
+
 func (w *WrappedBlock) numL2Transactions() uint64 {
 	var count uint64
 	for _, txData := range w.Transactions {
@@ -160,6 +201,7 @@ func decodeBlockContext(encodedBlockContext []byte) (*BlockContext, error) {
 	return &BlockContext{
 		BlockNumber:     binary.BigEndian.Uint64(encodedBlockContext[0:8]),
 		Timestamp:       binary.BigEndian.Uint64(encodedBlockContext[8:16]),
+		BaseFee:         new(big.Int).SetBytes(encodedBlockContext[16:48]),
 		GasLimit:        binary.BigEndian.Uint64(encodedBlockContext[48:56]),
 		NumTransactions: binary.BigEndian.Uint16(encodedBlockContext[56:58]),
 		NumL1Messages:   binary.BigEndian.Uint16(encodedBlockContext[58:60]),
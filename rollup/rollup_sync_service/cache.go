@@ -0,0 +1,109 @@
+package rollup_sync_service
+
+import (
+	lru "github.com/hashicorp/golang-lru"
+
+	"github.com/scroll-tech/go-ethereum/core/rawdb"
+)
+
+// batchCacheSize is the number of finalized batch metas and batch chunk ranges cached in memory.
+const batchCacheSize = 256
+
+// batchCache keeps the recently read and written batch metadata of the sync loop in memory,
+// so that consecutive finalizations do not read the parent batch and chunk ranges from disk.
+// Only stored values are cached; all changes to cached batches must go through the cache.
+type batchCache struct {
+	finalizedBatchMetas *lru.Cache // batch index -> *rawdb.FinalizedBatchMeta
+	chunkRanges         *lru.Cache // batch index -> []*rawdb.ChunkBlockRange
+}
+
+func newBatchCache() *batchCache {
+	finalizedBatchMetas, _ := lru.New(batchCacheSize)
+	chunkRanges, _ := lru.New(batchCacheSize)
+	return &batchCache{finalizedBatchMetas: finalizedBatchMetas, chunkRanges: chunkRanges}
+}
+
+// readFinalizedBatchMeta returns the metadata of a finalized batch from the cache or the database.
+func (s *RollupSyncService) readFinalizedBatchMeta(batchIndex uint64) *rawdb.FinalizedBatchMeta {
+	if s.batchCache != nil {
+		if meta, ok := s.batchCache.finalizedBatchMetas.Get(batchIndex); ok {
+			return meta.(*rawdb.FinalizedBatchMeta)
+		}
+	}
+	meta := rawdb.ReadFinalizedBatchMeta(s.db, batchIndex)
+	if meta != nil && s.batchCache != nil {
+		s.batchCache.finalizedBatchMetas.Add(batchIndex, meta)
+	}
+	return meta
+}
+
+func (s *RollupSyncService) writeFinalizedBatchMeta(batchIndex uint64, meta *rawdb.FinalizedBatchMeta) {
+	rawdb.WriteFinalizedBatchMeta(s.db, batchIndex, meta)
+	if s.batchCache != nil {
+		s.batchCache.finalizedBatchMetas.Add(batchIndex, meta)
+	}
+}
+
+func (s *RollupSyncService) deleteFinalizedBatchMeta(batchIndex uint64) {
+	rawdb.DeleteFinalizedBatchMeta(s.db, batchIndex)
+	if s.batchCache != nil {
+		s.batchCache.finalizedBatchMetas.Remove(batchIndex)
+	}
+}
+
+// readBatchChunkRanges returns the chunk ranges of a committed batch from the cache or the database.
+func (s *RollupSyncService) readBatchChunkRanges(batchIndex uint64) []*rawdb.ChunkBlockRange {
+	if s.batchCache != nil {
+		if chunkBlockRanges, ok := s.batchCache.chunkRanges.Get(batchIndex); ok {
+			return chunkBlockRanges.([]*rawdb.ChunkBlockRange)
+		}
+	}
+	chunkBlockRanges := rawdb.ReadBatchChunkRanges(s.db, batchIndex)
+	if chunkBlockRanges != nil && s.batchCache != nil {
+		s.batchCache.chunkRanges.Add(batchIndex, chunkBlockRanges)
+	}
+	return chunkBlockRanges
+}
+
+func (s *RollupSyncService) writeBatchChunkRanges(batchIndex uint64, chunkBlockRanges []*rawdb.ChunkBlockRange) {
+	rawdb.WriteBatchChunkRanges(s.db, batchIndex, chunkBlockRanges)
+	if s.batchCache != nil {
+		s.batchCache.chunkRanges.Add(batchIndex, chunkBlockRanges)
+	}
+}
+
+func (s *RollupSyncService) deleteBatchChunkRanges(batchIndex uint64) {
+	rawdb.DeleteBatchChunkRanges(s.db, batchIndex)
+	if s.batchCache != nil {
+		s.batchCache.chunkRanges.Remove(batchIndex)
+	}
+}
+
+// deleteBatchRange removes all metadata of the batches in the range [fromBatchIndex, toBatchIndex],
+// see rawdb.DeleteBatchRange.
+func (s *RollupSyncService) deleteBatchRange(fromBatchIndex, toBatchIndex uint64) {
+	rawdb.DeleteBatchRange(s.db, fromBatchIndex, toBatchIndex)
+	if s.batchCache != nil {
+		s.batchCache.removeRange(s.batchCache.finalizedBatchMetas, fromBatchIndex, toBatchIndex)
+		s.batchCache.removeRange(s.batchCache.chunkRanges, fromBatchIndex, toBatchIndex)
+	}
+}
+
+// removeRange evicts the batches in the range [fromBatchIndex, toBatchIndex] from the cache,
+// ranges larger than the cache are evicted by visiting the cached batches instead.
+func (c *batchCache) removeRange(cache *lru.Cache, fromBatchIndex, toBatchIndex uint64) {
+	if fromBatchIndex > toBatchIndex {
+		return
+	}
+	if toBatchIndex-fromBatchIndex < batchCacheSize {
+		for batchIndex := fromBatchIndex; batchIndex <= toBatchIndex; batchIndex++ {
+			cache.Remove(batchIndex)
+		}
+		return
+	}
+	for _, key := range cache.Keys() {
+		if batchIndex := key.(uint64); batchIndex >= fromBatchIndex && batchIndex <= toBatchIndex {
+			cache.Remove(batchIndex)
+		}
+	}
+}
@@ -0,0 +1,79 @@
+package rollup_sync_service
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/scroll-tech/go-ethereum/common"
+	"github.com/scroll-tech/go-ethereum/core/rawdb"
+)
+
+func TestBatchCache(t *testing.T) {
+	db := rawdb.NewMemoryDatabase()
+	service := &RollupSyncService{db: db, batchCache: newBatchCache()}
+
+	meta := &rawdb.FinalizedBatchMeta{BatchHash: common.Hash{0x01}}
+	chunkBlockRanges := []*rawdb.ChunkBlockRange{{StartBlockNumber: 1, EndBlockNumber: 10}}
+	service.writeFinalizedBatchMeta(1, meta)
+	service.writeBatchChunkRanges(1, chunkBlockRanges)
+	assert.Equal(t, meta, rawdb.ReadFinalizedBatchMeta(db, 1))
+	assert.Equal(t, chunkBlockRanges, rawdb.ReadBatchChunkRanges(db, 1))
+
+	// written values are served from memory
+	rawdb.DeleteFinalizedBatchMeta(db, 1)
+	rawdb.DeleteBatchChunkRanges(db, 1)
+	assert.Same(t, meta, service.readFinalizedBatchMeta(1))
+	assert.Equal(t, chunkBlockRanges, service.readBatchChunkRanges(1))
+
+	// missing values are not cached
+	assert.Nil(t, service.readFinalizedBatchMeta(2))
+	rawdb.WriteFinalizedBatchMeta(db, 2, meta)
+	assert.Equal(t, meta, service.readFinalizedBatchMeta(2))
+
+	// deletions evict the cached values
+	service.writeFinalizedBatchMeta(1, meta)
+	service.writeBatchChunkRanges(1, chunkBlockRanges)
+	service.deleteBatchRange(1, 1)
+	assert.Nil(t, service.readFinalizedBatchMeta(1))
+	assert.Nil(t, service.readBatchChunkRanges(1))
+
+	service.writeBatchChunkRanges(3, chunkBlockRanges)
+	service.deleteBatchChunkRanges(3)
+	assert.Nil(t, service.readBatchChunkRanges(3))
+	service.deleteFinalizedBatchMeta(2)
+	assert.Nil(t, service.readFinalizedBatchMeta(2))
+
+	// without a cache all reads go to the database
+	service.batchCache = nil
+	service.writeFinalizedBatchMeta(4, meta)
+	rawdb.DeleteFinalizedBatchMeta(db, 4)
+	assert.Nil(t, service.readFinalizedBatchMeta(4))
+}
+
+func TestBatchCacheDeleteBatchRange(t *testing.T) {
+	db := rawdb.NewMemoryDatabase()
+	service := &RollupSyncService{db: db, batchCache: newBatchCache()}
+
+	meta := &rawdb.FinalizedBatchMeta{BatchHash: common.Hash{0x01}}
+	chunkBlockRanges := []*rawdb.ChunkBlockRange{{StartBlockNumber: 1, EndBlockNumber: 10}}
+	for batchIndex := uint64(1); batchIndex <= 5; batchIndex++ {
+		service.writeFinalizedBatchMeta(batchIndex, meta)
+		service.writeBatchChunkRanges(batchIndex, chunkBlockRanges)
+	}
+
+	// small ranges are evicted batch by batch
+	service.deleteBatchRange(4, 5)
+	assert.Nil(t, service.readFinalizedBatchMeta(4))
+	assert.Nil(t, service.readBatchChunkRanges(5))
+	assert.Equal(t, meta, service.readFinalizedBatchMeta(3))
+
+	// ranges larger than the cache are evicted by visiting the cached batches
+	service.deleteBatchRange(2, 2+batchCacheSize)
+	for batchIndex := uint64(2); batchIndex <= 3; batchIndex++ {
+		assert.Nil(t, service.readFinalizedBatchMeta(batchIndex))
+		assert.Nil(t, service.readBatchChunkRanges(batchIndex))
+	}
+	assert.Equal(t, meta, service.readFinalizedBatchMeta(1))
+	assert.Equal(t, chunkBlockRanges, service.readBatchChunkRanges(1))
+}
@@ -0,0 +1,109 @@
+package rollup_sync_service
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/scroll-tech/go-ethereum/accounts/abi"
+	"github.com/scroll-tech/go-ethereum/common"
+)
+
+// CommitBatchCalldata holds the arguments of a commitBatch call of the ScrollChain contract.
+// The commit arguments of commitAndFinalizeBatch calls in enforced batch mode and of
+// commitBatchWithBlobProof calls are decoded as well.
+type CommitBatchCalldata struct {
+	Version                uint8
+	ParentBatchHeader      []byte
+	Chunks                 [][]byte
+	SkippedL1MessageBitmap []byte
+	BlobDataProof          []byte // only set for commitBatchWithBlobProof calls
+
+	// BlobVersionedHashes are the blob hashes of the commit transaction, they are not part of the calldata.
+	BlobVersionedHashes []common.Hash
+}
+
+// DecodeCommitBatchCalldata decodes the calldata of a commitBatch transaction.
+func DecodeCommitBatchCalldata(txData []byte) (*CommitBatchCalldata, error) {
+	scrollChainABI, err := scrollChainMetaData.GetAbi()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get scroll chain abi: %w", err)
+	}
+	return decodeCommitBatchCalldata(scrollChainABI, txData)
+}
+
+func decodeCommitBatchCalldata(scrollChainABI *abi.ABI, txData []byte) (*CommitBatchCalldata, error) {
+	const methodIDLength = 4
+	if len(txData) < methodIDLength {
+		return nil, fmt.Errorf("transaction data is too short, length of tx data: %v, minimum length required: %v", len(txData), methodIDLength)
+	}
+
+	method, err := scrollChainABI.MethodById(txData[:methodIDLength])
+	if err != nil {
+		return nil, fmt.Errorf("failed to get method by ID, ID: %v, err: %w", txData[:methodIDLength], err)
+	}
+
+	values, err := method.Inputs.Unpack(txData[methodIDLength:])
+	if err != nil {
+		return nil, fmt.Errorf("failed to unpack transaction data using ABI, tx data: %v, err: %w", txData, err)
+	}
+
+	inputs := method.Inputs
+	if method.Name == "commitAndFinalizeBatch" {
+		// the finalize arguments follow the arguments of commitBatch
+		inputs, values = inputs[:4], values[:4]
+	}
+
+	var args CommitBatchCalldata
+	err = inputs.Copy(&args, values)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode calldata into commitBatch args, values: %+v, err: %w", values, err)
+	}
+	return &args, nil
+}
+
+// findNestedCommitBatchCalldata locates the commitBatch call of a batch in the calldata of
+// a wrapper transaction, e.g. a Safe transaction, a multicall or a call through a proxy.
+// The wrapper ABI is unknown, so every occurrence of the commitBatch method ID is decoded,
+// using the preceding word as the length of the call where it is ABI encoded as bytes.
+// Wrappers may commit several batches at once, so the call is identified by the index of
+// its parent batch header.
+func findNestedCommitBatchCalldata(scrollChainABI *abi.ABI, txData []byte, batchIndex uint64) (*CommitBatchCalldata, error) {
+	var methodIDs [][]byte
+	for _, name := range []string{"commitBatch", "commitBatchWithBlobProof"} {
+		method, ok := scrollChainABI.Methods[name]
+		if !ok {
+			return nil, fmt.Errorf("%s method not found in scroll chain abi", name)
+		}
+		methodIDs = append(methodIDs, method.ID)
+	}
+	if batchIndex == 0 {
+		return nil, errors.New("genesis batch has no commitBatch call")
+	}
+
+	for i := 1; i+4 <= len(txData); i++ {
+		if !bytes.Equal(txData[i:i+4], methodIDs[0]) && !bytes.Equal(txData[i:i+4], methodIDs[1]) {
+			continue
+		}
+		candidates := [][]byte{txData[i:]}
+		if i >= common.HashLength {
+			length := new(big.Int).SetBytes(txData[i-common.HashLength : i])
+			if length.IsUint64() && length.Uint64() >= 4 && length.Uint64() <= uint64(len(txData)-i) {
+				candidates = append([][]byte{txData[i : i+int(length.Uint64())]}, candidates...)
+			}
+		}
+		for _, candidate := range candidates {
+			calldata, err := decodeCommitBatchCalldata(scrollChainABI, candidate)
+			if err != nil || len(calldata.ParentBatchHeader) < 9 {
+				continue
+			}
+			// the parent batch header starts with the version byte and the batch index
+			if binary.BigEndian.Uint64(calldata.ParentBatchHeader[1:9]) == batchIndex-1 {
+				return calldata, nil
+			}
+		}
+	}
+	return nil, fmt.Errorf("no nested commitBatch call found for batch index %v", batchIndex)
+}
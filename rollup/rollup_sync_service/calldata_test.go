@@ -0,0 +1,130 @@
+package rollup_sync_service
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"math/big"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/scroll-tech/go-ethereum/accounts/abi"
+	"github.com/scroll-tech/go-ethereum/common"
+	"github.com/scroll-tech/go-ethereum/common/hexutil"
+)
+
+func TestDecodeCommitBatchCalldata(t *testing.T) {
+	data, err := os.ReadFile("./testdata/commit_batch_transaction.json")
+	require.NoError(t, err, "Failed to read json file")
+
+	var txObj struct {
+		CallData string `json:"calldata"`
+	}
+	require.NoError(t, json.Unmarshal(data, &txObj))
+	txData, err := hexutil.Decode(txObj.CallData)
+	require.NoError(t, err)
+
+	calldata, err := DecodeCommitBatchCalldata(txData)
+	require.NoError(t, err)
+	assert.Equal(t, uint8(0), calldata.Version)
+	assert.Len(t, calldata.ParentBatchHeader, 89)
+	assert.Len(t, calldata.Chunks, 8)
+
+	_, err = DecodeCommitBatchCalldata(txData[:3])
+	assert.Error(t, err)
+}
+
+func TestFindNestedCommitBatchCalldata(t *testing.T) {
+	data, err := os.ReadFile("./testdata/commit_batch_transaction.json")
+	require.NoError(t, err)
+	var txObj struct {
+		CallData string `json:"calldata"`
+	}
+	require.NoError(t, json.Unmarshal(data, &txObj))
+	txData, err := hexutil.Decode(txObj.CallData)
+	require.NoError(t, err)
+
+	scrollChainABI, err := scrollChainMetaData.GetAbi()
+	require.NoError(t, err)
+	direct, err := decodeCommitBatchCalldata(scrollChainABI, txData)
+	require.NoError(t, err)
+	batchIndex := binary.BigEndian.Uint64(direct.ParentBatchHeader[1:9]) + 1
+
+	// a commitBatch call of the next batch, e.g. committed in the same multicall
+	nextParentHeader := common.CopyBytes(direct.ParentBatchHeader)
+	binary.BigEndian.PutUint64(nextParentHeader[1:9], batchIndex)
+	nextTxData, err := scrollChainABI.Pack("commitBatch", direct.Version, nextParentHeader, direct.Chunks, direct.SkippedL1MessageBitmap)
+	require.NoError(t, err)
+
+	bytesType, err := abi.NewType("bytes", "", nil)
+	require.NoError(t, err)
+	bytesArrayType, err := abi.NewType("bytes[]", "", nil)
+	require.NoError(t, err)
+	addressType, err := abi.NewType("address", "", nil)
+	require.NoError(t, err)
+
+	// Safe style execTransaction(address,bytes)
+	safeArgs, err := abi.Arguments{{Type: addressType}, {Type: bytesType}}.Pack(common.Address{0x01}, txData)
+	require.NoError(t, err)
+	safeTxData := append([]byte{0x6a, 0x76, 0x12, 0x02}, safeArgs...)
+
+	// multicall(bytes[]) committing two batches
+	multicallArgs, err := abi.Arguments{{Type: bytesArrayType}}.Pack([][]byte{nextTxData, txData})
+	require.NoError(t, err)
+	multicallTxData := append([]byte{0xac, 0x96, 0x50, 0xd8}, multicallArgs...)
+
+	// MultiSend style packed encoding of operation, address, value, length and data
+	packed := []byte{0x00}
+	packed = append(packed, common.Address{0x01}.Bytes()...)
+	packed = append(packed, common.Hash{}.Bytes()...)
+	packed = append(packed, common.BigToHash(big.NewInt(int64(len(txData)))).Bytes()...)
+	packed = append(packed, txData...)
+	multiSendArgs, err := abi.Arguments{{Type: bytesType}}.Pack(packed)
+	require.NoError(t, err)
+	multiSendTxData := append([]byte{0x8d, 0x80, 0xff, 0x0a}, multiSendArgs...)
+
+	for name, wrapped := range map[string][]byte{"safe": safeTxData, "multicall": multicallTxData, "multisend": multiSendTxData} {
+		calldata, err := findNestedCommitBatchCalldata(scrollChainABI, wrapped, batchIndex)
+		require.NoError(t, err, name)
+		assert.Equal(t, direct, calldata, name)
+	}
+
+	calldata, err := findNestedCommitBatchCalldata(scrollChainABI, multicallTxData, batchIndex+1)
+	require.NoError(t, err)
+	assert.Equal(t, nextParentHeader, calldata.ParentBatchHeader)
+
+	_, err = findNestedCommitBatchCalldata(scrollChainABI, safeTxData, batchIndex+1)
+	assert.Error(t, err)
+}
+
+func TestDecodeCommitBatchWithBlobProofCalldata(t *testing.T) {
+	scrollChainABI, err := scrollChainMetaData.GetAbi()
+	require.NoError(t, err)
+
+	parentBatchHeader := make([]byte, 193)
+	parentBatchHeader[0] = 3
+	binary.BigEndian.PutUint64(parentBatchHeader[1:9], 41)
+	chunks := [][]byte{append([]byte{1}, make([]byte, blockContextByteSize)...)}
+	blobDataProof := make([]byte, 160)
+	blobDataProof[0] = 0x01
+	txData, err := scrollChainABI.Pack("commitBatchWithBlobProof", uint8(3), parentBatchHeader, chunks, []byte{}, blobDataProof)
+	require.NoError(t, err)
+
+	calldata, err := decodeCommitBatchCalldata(scrollChainABI, txData)
+	require.NoError(t, err)
+	assert.Equal(t, uint8(3), calldata.Version)
+	assert.Equal(t, parentBatchHeader, calldata.ParentBatchHeader)
+	assert.Equal(t, chunks, calldata.Chunks)
+	assert.Equal(t, blobDataProof, calldata.BlobDataProof)
+
+	// the call is also found within wrapper transactions
+	bytesType, err := abi.NewType("bytes", "", nil)
+	require.NoError(t, err)
+	wrapperArgs, err := abi.Arguments{{Type: bytesType}}.Pack(txData)
+	require.NoError(t, err)
+	nested, err := findNestedCommitBatchCalldata(scrollChainABI, append([]byte{0x01, 0x02, 0x03, 0x04}, wrapperArgs...), 42)
+	require.NoError(t, err)
+	assert.Equal(t, calldata, nested)
+}
@@ -0,0 +1,188 @@
+package rollup_sync_service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"strings"
+
+	"github.com/scroll-tech/go-ethereum"
+	"github.com/scroll-tech/go-ethereum/common"
+	"github.com/scroll-tech/go-ethereum/params"
+	"github.com/scroll-tech/go-ethereum/rpc"
+
+	"github.com/scroll-tech/go-ethereum/rollup/sync_service"
+)
+
+// L1CheckClient is the L1 client used by CheckL1, ethclient.Client implements it.
+type L1CheckClient interface {
+	sync_service.EthClient
+	CodeAt(ctx context.Context, account common.Address, blockNumber *big.Int) ([]byte, error)
+}
+
+// L1CheckResult is the outcome of one check of CheckL1.
+type L1CheckResult struct {
+	Name    string
+	Detail  string // describes what was observed
+	Err     error  // set if the check failed
+	Skipped bool   // set if the check was not configured
+}
+
+// Passed reports whether the check passed or was skipped.
+func (r *L1CheckResult) Passed() bool {
+	return r.Err == nil
+}
+
+// CheckL1 verifies that the L1 endpoint serves what the rollup sync service relies on, so
+// that misconfigurations are found before a long sync: the chain ID, the code of the
+// ScrollChain contract, its events from the deployment block on, the finalized block tag
+// and, if a beacon node endpoint is given, the availability of blobs. It runs all checks
+// and reports every failure in the results.
+func CheckL1(ctx context.Context, client L1CheckClient, l1Config *params.L1Config, deploymentBlock uint64, beaconEndpoint string) []*L1CheckResult {
+	return []*L1CheckResult{
+		checkL1ChainID(ctx, client, l1Config.L1ChainId),
+		checkScrollChainCode(ctx, client, l1Config.ScrollChainAddress),
+		checkRollupEventsAt(ctx, client, l1Config.ScrollChainAddress, deploymentBlock),
+		checkFinalizedTag(ctx, client),
+		checkBlobAvailability(ctx, client, beaconEndpoint),
+	}
+}
+
+func checkL1ChainID(ctx context.Context, client L1CheckClient, expected uint64) *L1CheckResult {
+	result := &L1CheckResult{Name: "chain ID"}
+	got, err := client.ChainID(ctx)
+	switch {
+	case err != nil:
+		result.Err = fmt.Errorf("failed to query L1 chain ID: %w", err)
+	case !got.IsUint64() || got.Uint64() != expected:
+		result.Err = fmt.Errorf("unexpected chain ID, expected: %v, got: %v", expected, got)
+	default:
+		result.Detail = got.String()
+	}
+	return result
+}
+
+func checkScrollChainCode(ctx context.Context, client L1CheckClient, address common.Address) *L1CheckResult {
+	result := &L1CheckResult{Name: "ScrollChain code"}
+	if address == (common.Address{}) {
+		result.Err = errors.New("no ScrollChain address configured")
+		return result
+	}
+	code, err := client.CodeAt(ctx, address, nil)
+	switch {
+	case err != nil:
+		result.Err = fmt.Errorf("failed to query code at %v: %w", address.Hex(), err)
+	case len(code) == 0:
+		result.Err = fmt.Errorf("no contract deployed at %v", address.Hex())
+	default:
+		result.Detail = fmt.Sprintf("%d bytes at %v", len(code), address.Hex())
+	}
+	return result
+}
+
+// checkRollupEventsAt queries the ScrollChain events of the first fetch range starting at
+// the deployment block, which fails on endpoints that do not serve the historical logs.
+func checkRollupEventsAt(ctx context.Context, client L1CheckClient, address common.Address, deploymentBlock uint64) *L1CheckResult {
+	result := &L1CheckResult{Name: "events at deployment block"}
+	if deploymentBlock == 0 {
+		result.Skipped = true
+		result.Detail = "no deployment block configured"
+		return result
+	}
+	latest, err := client.BlockNumber(ctx)
+	if err != nil {
+		result.Err = fmt.Errorf("failed to query latest L1 block number: %w", err)
+		return result
+	}
+	if latest < deploymentBlock {
+		result.Err = fmt.Errorf("deployment block %d is ahead of the latest L1 block %d", deploymentBlock, latest)
+		return result
+	}
+	to := deploymentBlock + defaultFetchBlockRange - 1
+	if to > latest {
+		to = latest
+	}
+	logs, err := client.FilterLogs(ctx, ethereum.FilterQuery{
+		FromBlock: new(big.Int).SetUint64(deploymentBlock),
+		ToBlock:   new(big.Int).SetUint64(to),
+		Addresses: []common.Address{address},
+	})
+	if err != nil {
+		result.Err = fmt.Errorf("failed to filter logs in blocks %d-%d: %w", deploymentBlock, to, err)
+		return result
+	}
+	result.Detail = fmt.Sprintf("%d events in blocks %d-%d", len(logs), deploymentBlock, to)
+	return result
+}
+
+func checkFinalizedTag(ctx context.Context, client L1CheckClient) *L1CheckResult {
+	result := &L1CheckResult{Name: "finalized tag"}
+	header, err := client.HeaderByNumber(ctx, big.NewInt(int64(rpc.FinalizedBlockNumber)))
+	switch {
+	case err != nil:
+		result.Err = fmt.Errorf("failed to query finalized L1 block: %w", err)
+	case header == nil || header.Number == nil:
+		result.Err = errors.New("no finalized L1 block")
+	default:
+		result.Detail = fmt.Sprintf("block %v", header.Number)
+	}
+	return result
+}
+
+// checkBlobAvailability checks that the L1 chain supports blobs and that the beacon node,
+// which serves the blobs, is reachable.
+func checkBlobAvailability(ctx context.Context, client L1CheckClient, beaconEndpoint string) *L1CheckResult {
+	result := &L1CheckResult{Name: "blob availability"}
+	if beaconEndpoint == "" {
+		result.Skipped = true
+		result.Detail = "no beacon node endpoint configured"
+		return result
+	}
+	header, err := client.HeaderByNumber(ctx, nil)
+	if err != nil {
+		result.Err = fmt.Errorf("failed to query latest L1 block: %w", err)
+		return result
+	}
+	if header.ExcessBlobGas == nil {
+		result.Err = fmt.Errorf("L1 block %v has no blob gas fields, blobs are not supported", header.Number)
+		return result
+	}
+	genesisTime, err := fetchBeaconGenesisTime(ctx, beaconEndpoint)
+	if err != nil {
+		result.Err = fmt.Errorf("beacon node unavailable: %w", err)
+		return result
+	}
+	result.Detail = fmt.Sprintf("beacon genesis time %v", genesisTime)
+	return result
+}
+
+// fetchBeaconGenesisTime queries the genesis time from the beacon node API.
+func fetchBeaconGenesisTime(ctx context.Context, endpoint string) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, strings.TrimSuffix(endpoint, "/")+"/eth/v1/beacon/genesis", nil)
+	if err != nil {
+		return "", err
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("unexpected status %s", resp.Status)
+	}
+	var genesis struct {
+		Data struct {
+			GenesisTime string `json:"genesis_time"`
+		} `json:"data"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&genesis); err != nil {
+		return "", fmt.Errorf("failed to decode genesis response: %w", err)
+	}
+	if genesis.Data.GenesisTime == "" {
+		return "", errors.New("missing genesis time")
+	}
+	return genesis.Data.GenesisTime, nil
+}
@@ -0,0 +1,79 @@
+package rollup_sync_service
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/scroll-tech/go-ethereum"
+	"github.com/scroll-tech/go-ethereum/common"
+	"github.com/scroll-tech/go-ethereum/core/types"
+	"github.com/scroll-tech/go-ethereum/params"
+)
+
+// checkL1EthClient serves the code of the ScrollChain contract and a latest block with
+// blob gas fields, the finalized tag is only supported if finalized is set.
+type checkL1EthClient struct {
+	mockEthClient
+	code       []byte
+	finalized  bool
+	logsFailed bool
+}
+
+func (c *checkL1EthClient) CodeAt(ctx context.Context, account common.Address, blockNumber *big.Int) ([]byte, error) {
+	return c.code, nil
+}
+
+func (c *checkL1EthClient) FilterLogs(ctx context.Context, q ethereum.FilterQuery) ([]types.Log, error) {
+	if c.logsFailed {
+		return nil, errors.New("missing trie node")
+	}
+	return []types.Log{{}}, nil
+}
+
+func (c *checkL1EthClient) HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error) {
+	if number != nil && !c.finalized {
+		return nil, errors.New("unknown block tag")
+	}
+	excessBlobGas := uint64(0)
+	return &types.Header{Number: big.NewInt(100), ExcessBlobGas: &excessBlobGas}, nil
+}
+
+func TestCheckL1(t *testing.T) {
+	beacon := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/eth/v1/beacon/genesis", r.URL.Path)
+		w.Write([]byte(`{"data":{"genesis_time":"1655733600"}}`))
+	}))
+	defer beacon.Close()
+
+	l1Config := &params.L1Config{L1ChainId: 11155111, ScrollChainAddress: common.HexToAddress("0x2D567EcE699Eabe5afCd141eDB7A4f2D0D6ce8a0")}
+	client := &checkL1EthClient{code: []byte{0x60}, finalized: true}
+	results := CheckL1(context.Background(), client, l1Config, 100, beacon.URL)
+	require.Len(t, results, 5)
+	for _, result := range results {
+		assert.True(t, result.Passed(), "%s: %v", result.Name, result.Err)
+		assert.False(t, result.Skipped, result.Name)
+	}
+	assert.Equal(t, "1 events in blocks 100-199", results[2].Detail)
+
+	// nothing passes on a misconfigured endpoint
+	*client = checkL1EthClient{logsFailed: true}
+	l1Config.L1ChainId = 1
+	results = CheckL1(context.Background(), client, l1Config, 100, "")
+	require.Len(t, results, 5)
+	for _, result := range results[:4] {
+		assert.False(t, result.Passed(), result.Name)
+	}
+	assert.True(t, results[4].Passed())
+	assert.True(t, results[4].Skipped)
+
+	// the deployment block must not be ahead of the L1 chain
+	results = CheckL1(context.Background(), client, l1Config, 20000000, "")
+	assert.Contains(t, results[2].Err.Error(), "ahead of the latest L1 block")
+}
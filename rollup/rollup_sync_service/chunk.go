@@ -83,10 +83,20 @@ func (c *Chunk) Encode(totalL1MessagePoppedBefore uint64) ([]byte, error) {
 
 // Hash hashes the Chunk into RollupV2 Chunk Hash
 func (c *Chunk) Hash(totalL1MessagePoppedBefore uint64) (common.Hash, error) {
-	chunkBytes, err := c.Encode(totalL1MessagePoppedBefore)
+	dataBytes, err := c.hashPreimage(totalL1MessagePoppedBefore)
 	if err != nil {
 		return common.Hash{}, err
 	}
+	return crypto.Keccak256Hash(dataBytes), nil
+}
+
+// hashPreimage returns the data hashed into the chunk hash: the block contexts
+// followed by the L1 and L2 transaction hashes of each block.
+func (c *Chunk) hashPreimage(totalL1MessagePoppedBefore uint64) ([]byte, error) {
+	chunkBytes, err := c.Encode(totalL1MessagePoppedBefore)
+	if err != nil {
+		return nil, err
+	}
 	numBlocks := chunkBytes[0]
 
 	// concatenate block contexts
@@ -104,7 +114,7 @@ func (c *Chunk) Hash(totalL1MessagePoppedBefore uint64) (common.Hash, error) {
 			txHash := strings.TrimPrefix(txData.TxHash, "0x")
 			hashBytes, err := hex.DecodeString(txHash)
 			if err != nil {
-				return common.Hash{}, err
+				return nil, err
 			}
 			if txData.Type == types.L1MessageTxType {
 				l1TxHashes = append(l1TxHashes, hashBytes...)
@@ -116,21 +126,44 @@ func (c *Chunk) Hash(totalL1MessagePoppedBefore uint64) (common.Hash, error) {
 		dataBytes = append(dataBytes, l2TxHashes...)
 	}
 
-	hash := crypto.Keccak256Hash(dataBytes)
-	return hash, nil
+	return dataBytes, nil
 }
 
 // DecodeChunkBlockRanges decodes the provided chunks into a list of block ranges. Each chunk
 // contains information about multiple blocks, which are decoded and their ranges (from the
 // start block to the end block) are returned.
 func DecodeChunkBlockRanges(chunks [][]byte) ([]*rawdb.ChunkBlockRange, error) {
+	chunkBlockContexts, err := DecodeChunkBlockContexts(chunks)
+	if err != nil {
+		return nil, err
+	}
+	return chunkBlockRangesFromContexts(chunkBlockContexts), nil
+}
+
+// chunkBlockRangesFromContexts returns the block range of each chunk given its decoded block contexts.
+func chunkBlockRangesFromContexts(chunkBlockContexts [][]*BlockContext) []*rawdb.ChunkBlockRange {
 	var chunkBlockRanges []*rawdb.ChunkBlockRange
+	for _, blockContexts := range chunkBlockContexts {
+		chunkBlockRanges = append(chunkBlockRanges, &rawdb.ChunkBlockRange{
+			StartBlockNumber: blockContexts[0].BlockNumber,
+			EndBlockNumber:   blockContexts[len(blockContexts)-1].BlockNumber,
+		})
+	}
+	return chunkBlockRanges
+}
+
+// DecodeChunkBlockContexts decodes the block contexts of each of the provided chunks.
+func DecodeChunkBlockContexts(chunks [][]byte) ([][]*BlockContext, error) {
+	var chunkBlockContexts [][]*BlockContext
 	for _, chunk := range chunks {
 		if len(chunk) < 1 {
 			return nil, fmt.Errorf("invalid chunk, length is less than 1")
 		}
 
 		numBlocks := int(chunk[0])
+		if numBlocks == 0 {
+			return nil, fmt.Errorf("invalid chunk, number of blocks is 0")
+		}
 		if len(chunk) < 1+numBlocks*blockContextByteSize {
 			return nil, fmt.Errorf("chunk size doesn't match with numBlocks, byte length of chunk: %v, expected length: %v", len(chunk), 1+numBlocks*blockContextByteSize)
 		}
@@ -146,10 +179,52 @@ func DecodeChunkBlockRanges(chunks [][]byte) ([]*rawdb.ChunkBlockRange, error) {
 			blockContexts[i] = blockContext
 		}
 
-		chunkBlockRanges = append(chunkBlockRanges, &rawdb.ChunkBlockRange{
-			StartBlockNumber: blockContexts[0].BlockNumber,
-			EndBlockNumber:   blockContexts[len(blockContexts)-1].BlockNumber,
-		})
+		chunkBlockContexts = append(chunkBlockContexts, blockContexts)
+	}
+	return chunkBlockContexts, nil
+}
+
+// DecodeChunkL2Transactions decodes the L2 transactions of each block of each of the provided
+// chunks. The L1 messages of a block are not part of the chunk encoding, only their number.
+func DecodeChunkL2Transactions(chunks [][]byte) ([][]types.Transactions, error) {
+	chunkBlockContexts, err := DecodeChunkBlockContexts(chunks)
+	if err != nil {
+		return nil, err
+	}
+
+	chunkTxs := make([][]types.Transactions, len(chunks))
+	for i, chunk := range chunks {
+		blockContexts := chunkBlockContexts[i]
+		data := chunk[1+len(blockContexts)*blockContextByteSize:]
+
+		chunkTxs[i] = make([]types.Transactions, len(blockContexts))
+		for j, blockContext := range blockContexts {
+			if blockContext.NumTransactions < blockContext.NumL1Messages {
+				return nil, fmt.Errorf("invalid block context of block %d, %d transactions but %d L1 messages", blockContext.BlockNumber, blockContext.NumTransactions, blockContext.NumL1Messages)
+			}
+			// note: the number of transactions includes the skipped L1 messages
+			numL2Transactions := int(blockContext.NumTransactions - blockContext.NumL1Messages)
+			txs := make(types.Transactions, numL2Transactions)
+			for k := range txs {
+				if len(data) < 4 {
+					return nil, fmt.Errorf("chunk %d ends before transaction %d of block %d", i, k, blockContext.BlockNumber)
+				}
+				txLen := binary.BigEndian.Uint32(data[:4])
+				if uint64(len(data)-4) < uint64(txLen) {
+					return nil, fmt.Errorf("chunk %d ends within transaction %d of block %d", i, k, blockContext.BlockNumber)
+				}
+				tx := new(types.Transaction)
+				if err := tx.UnmarshalBinary(data[4 : 4+txLen]); err != nil {
+					return nil, fmt.Errorf("failed to decode transaction %d of block %d: %w", k, blockContext.BlockNumber, err)
+				}
+				txs[k] = tx
+				data = data[4+txLen:]
+			}
+			chunkTxs[i][j] = txs
+		}
+		if len(data) != 0 {
+			return nil, fmt.Errorf("chunk %d has %d trailing bytes", i, len(data))
+		}
 	}
-	return chunkBlockRanges, nil
+	return chunkTxs, nil
 }
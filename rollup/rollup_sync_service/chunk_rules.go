@@ -0,0 +1,164 @@
+package rollup_sync_service
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/scroll-tech/go-ethereum/metrics"
+)
+
+var chunkRuleViolationCounter = metrics.NewRegisteredCounter("rollup/sync/chunk/rule/violations", nil)
+
+// ChunkStats are the quantities of a chunk that the chunk rules constrain.
+type ChunkStats struct {
+	Blocks       uint64 `json:"blocks"`
+	L1Messages   uint64 `json:"l1Messages"`   // included and skipped L1 messages
+	PayloadBytes uint64 `json:"payloadBytes"` // size of the chunk encoding with the codec of its batch
+}
+
+// ChunkRule is an invariant that every chunk committed to L1 must satisfy.
+type ChunkRule interface {
+	// Name identifies the rule in errors and logs.
+	Name() string
+
+	// Check returns an error if a chunk with the given stats violates the rule.
+	Check(stats ChunkStats) error
+}
+
+// maxChunkRule limits one quantity of a chunk.
+type maxChunkRule struct {
+	name    string
+	max     uint64
+	measure func(stats ChunkStats) uint64
+}
+
+func (r maxChunkRule) Name() string { return r.name }
+
+func (r maxChunkRule) Check(stats ChunkStats) error {
+	if value := r.measure(stats); value > r.max {
+		return fmt.Errorf("chunk rule %v violated: %d exceeds %d", r.name, value, r.max)
+	}
+	return nil
+}
+
+// MaxBlocksPerChunk limits the number of blocks of a chunk.
+func MaxBlocksPerChunk(max uint64) ChunkRule {
+	return maxChunkRule{name: "maxBlocks", max: max, measure: func(stats ChunkStats) uint64 { return stats.Blocks }}
+}
+
+// MaxL1MessagesPerChunk limits the number of L1 messages popped by a chunk.
+func MaxL1MessagesPerChunk(max uint64) ChunkRule {
+	return maxChunkRule{name: "maxL1Messages", max: max, measure: func(stats ChunkStats) uint64 { return stats.L1Messages }}
+}
+
+// MaxChunkPayloadBytes limits the encoded size of a chunk.
+func MaxChunkPayloadBytes(max uint64) ChunkRule {
+	return maxChunkRule{name: "maxPayloadBytes", max: max, measure: func(stats ChunkStats) uint64 { return stats.PayloadBytes }}
+}
+
+// ChunkRuleSet are the rules of the chunks starting at or after FromBlock.
+type ChunkRuleSet struct {
+	FromBlock uint64
+	Rules     []ChunkRule
+}
+
+// ChunkRules are the chunk rules of all forks. The rules in effect for a chunk are those of
+// the set with the highest FromBlock at or below the first block of the chunk.
+type ChunkRules []ChunkRuleSet
+
+// DefaultChunkRules are the chunk rules of the protocol.
+var DefaultChunkRules = ChunkRules{
+	{FromBlock: 0, Rules: []ChunkRule{
+		MaxBlocksPerChunk(255), // the number of blocks is encoded in 1 byte
+	}},
+}
+
+// ChunkLimits configures a ChunkRuleSet. Zero disables a limit.
+type ChunkLimits struct {
+	FromBlock       uint64
+	MaxBlocks       uint64 `toml:",omitempty"`
+	MaxL1Messages   uint64 `toml:",omitempty"`
+	MaxPayloadBytes uint64 `toml:",omitempty"`
+}
+
+// NewChunkRules creates the chunk rules of the given limits, which may be in any order.
+func NewChunkRules(limits []ChunkLimits) ChunkRules {
+	rules := make(ChunkRules, 0, len(limits))
+	for _, l := range limits {
+		set := ChunkRuleSet{FromBlock: l.FromBlock}
+		if l.MaxBlocks > 0 {
+			set.Rules = append(set.Rules, MaxBlocksPerChunk(l.MaxBlocks))
+		}
+		if l.MaxL1Messages > 0 {
+			set.Rules = append(set.Rules, MaxL1MessagesPerChunk(l.MaxL1Messages))
+		}
+		if l.MaxPayloadBytes > 0 {
+			set.Rules = append(set.Rules, MaxChunkPayloadBytes(l.MaxPayloadBytes))
+		}
+		rules = append(rules, set)
+	}
+	sort.SliceStable(rules, func(i, j int) bool { return rules[i].FromBlock < rules[j].FromBlock })
+	return rules
+}
+
+// At returns the rules in effect for a chunk starting at the given block.
+func (r ChunkRules) At(blockNumber uint64) []ChunkRule {
+	var rules []ChunkRule
+	for _, set := range r {
+		if set.FromBlock > blockNumber {
+			break
+		}
+		rules = set.Rules
+	}
+	return rules
+}
+
+// Check evaluates the rules in effect for a chunk starting at the given block and returns
+// the first violation.
+func (r ChunkRules) Check(firstBlock uint64, stats ChunkStats) error {
+	for _, rule := range r.At(firstBlock) {
+		if err := rule.Check(stats); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// chunkStats measures a chunk encoded with the given codec.
+func chunkStats(codec Codec, chunk *Chunk, totalL1MessagePoppedBefore uint64) (ChunkStats, error) {
+	stats := ChunkStats{
+		Blocks:       uint64(len(chunk.Blocks)),
+		L1Messages:   chunk.NumL1Messages(totalL1MessagePoppedBefore),
+		PayloadBytes: codec.DALimits().ChunkOverheadBytes,
+	}
+	for _, block := range chunk.Blocks {
+		size, err := codec.EncodedBlockSize(block)
+		if err != nil {
+			return ChunkStats{}, err
+		}
+		stats.PayloadBytes += size
+	}
+	return stats, nil
+}
+
+// checkChunkRules evaluates the chunk rules for every chunk of a batch. A violation is a batch
+// mismatch, which is handled according to the verify mode.
+func (s *RollupSyncService) checkChunkRules(codec Codec, batchIndex, totalL1MessagePoppedBefore uint64, chunks []*Chunk) error {
+	for i, chunk := range chunks {
+		if len(chunk.Blocks) == 0 {
+			continue
+		}
+		stats, err := chunkStats(codec, chunk, totalL1MessagePoppedBefore)
+		if err != nil {
+			return fmt.Errorf("failed to measure chunk %d of batch %d: %w", i, batchIndex, err)
+		}
+		totalL1MessagePoppedBefore += stats.L1Messages
+
+		firstBlock := chunk.Blocks[0].Header.Number.Uint64()
+		if err := s.chunkRules.Check(firstBlock, stats); err != nil {
+			chunkRuleViolationCounter.Inc(1)
+			return fmt.Errorf("%w: chunk %d of batch %d starting at block %d: %v", errBatchMismatch, i, batchIndex, firstBlock, err)
+		}
+	}
+	return nil
+}
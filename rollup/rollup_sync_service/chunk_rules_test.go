@@ -0,0 +1,96 @@
+package rollup_sync_service
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/scroll-tech/go-ethereum/core/types"
+)
+
+// l1MessageBlock returns a block with the given number that pops the L1 messages up to lastQueueIndex.
+func l1MessageBlock(number uint64, lastQueueIndex *uint64) *WrappedBlock {
+	block := &WrappedBlock{Header: &types.Header{Number: new(big.Int).SetUint64(number)}}
+	if lastQueueIndex != nil {
+		block.Transactions = []*types.TransactionData{{Type: types.L1MessageTxType, Nonce: *lastQueueIndex}}
+	}
+	return block
+}
+
+func TestChunkRulesAt(t *testing.T) {
+	rules := NewChunkRules([]ChunkLimits{
+		{FromBlock: 100, MaxBlocks: 10, MaxL1Messages: 5},
+		{FromBlock: 0, MaxBlocks: 20},
+		{FromBlock: 200, MaxPayloadBytes: 1000},
+	})
+	require.Len(t, rules, 3)
+	assert.Equal(t, []uint64{0, 100, 200}, []uint64{rules[0].FromBlock, rules[1].FromBlock, rules[2].FromBlock})
+
+	assert.Len(t, rules.At(99), 1)
+	assert.Len(t, rules.At(100), 2)
+	assert.Equal(t, "maxPayloadBytes", rules.At(1000)[0].Name())
+	assert.Nil(t, NewChunkRules([]ChunkLimits{{FromBlock: 10, MaxBlocks: 1}}).At(9))
+
+	assert.NoError(t, rules.Check(50, ChunkStats{Blocks: 20, L1Messages: 100}))
+	assert.Error(t, rules.Check(50, ChunkStats{Blocks: 21}))
+	assert.NoError(t, rules.Check(150, ChunkStats{Blocks: 10, L1Messages: 5}))
+	assert.Error(t, rules.Check(150, ChunkStats{Blocks: 10, L1Messages: 6}))
+	assert.NoError(t, rules.Check(250, ChunkStats{Blocks: 1000, PayloadBytes: 1000}))
+	assert.Error(t, rules.Check(250, ChunkStats{PayloadBytes: 1001}))
+}
+
+func TestCheckChunkRules(t *testing.T) {
+	q := func(i uint64) *uint64 { return &i }
+	chunks := []*Chunk{
+		{Blocks: []*WrappedBlock{l1MessageBlock(1, q(10)), l1MessageBlock(2, nil)}},
+		{Blocks: []*WrappedBlock{l1MessageBlock(3, q(12)), l1MessageBlock(4, q(14))}},
+	}
+
+	s := &RollupSyncService{chunkRules: DefaultChunkRules}
+	assert.NoError(t, s.checkChunkRules(codecV0{}, 1, 10, chunks))
+
+	// the second chunk pops the L1 messages 11 to 14
+	s.chunkRules = NewChunkRules([]ChunkLimits{{MaxL1Messages: 4}})
+	assert.NoError(t, s.checkChunkRules(codecV0{}, 1, 10, chunks))
+	s.chunkRules = NewChunkRules([]ChunkLimits{{MaxL1Messages: 3}})
+	err := s.checkChunkRules(codecV0{}, 1, 10, chunks)
+	assert.True(t, errors.Is(err, errBatchMismatch))
+	assert.Contains(t, err.Error(), "chunk 1 of batch 1")
+
+	s.chunkRules = NewChunkRules([]ChunkLimits{{MaxPayloadBytes: 1 + 2*blockContextByteSize - 1}})
+	assert.True(t, errors.Is(s.checkChunkRules(codecV0{}, 1, 10, chunks), errBatchMismatch))
+
+	// the rule only applies to chunks starting at or after block 3
+	s.chunkRules = NewChunkRules([]ChunkLimits{{FromBlock: 3, MaxBlocks: 1}})
+	err = s.checkChunkRules(codecV0{}, 1, 10, chunks)
+	assert.True(t, errors.Is(err, errBatchMismatch))
+	assert.Contains(t, err.Error(), "starting at block 3")
+}
+
+func TestCodecDAUsageChunkRules(t *testing.T) {
+	blocks := make([]*WrappedBlock, 6)
+	for i := range blocks {
+		queueIndex := uint64(2*i + 1)
+		blocks[i] = l1MessageBlock(uint64(i+1), &queueIndex)
+	}
+
+	// every block pops 2 L1 messages, so at most 2 blocks fit into a chunk
+	rules := NewChunkRules([]ChunkLimits{{MaxL1Messages: 4}})
+	usage, err := codecDAUsage(codecV0{}, rules, 0, blocks)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(3), usage.Chunks)
+	assert.Equal(t, uint64(2), usage.OpenChunkBlocks)
+
+	// the rule takes effect for chunks starting at block 3
+	rules = NewChunkRules([]ChunkLimits{{FromBlock: 3, MaxBlocks: 1}})
+	usage, err = codecDAUsage(codecV0{}, rules, 0, blocks)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(1), usage.Chunks)
+
+	usage, err = codecDAUsage(codecV0{}, rules, 0, blocks[2:])
+	require.NoError(t, err)
+	assert.Equal(t, uint64(4), usage.Chunks)
+}
@@ -0,0 +1,199 @@
+package rollup_sync_service
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/scroll-tech/go-ethereum/common"
+	"github.com/scroll-tech/go-ethereum/core/rawdb"
+	"github.com/scroll-tech/go-ethereum/core/types"
+)
+
+// Codec implements the data availability encoding of one batch header version,
+// identified by the version byte of the commitBatch calldata.
+type Codec interface {
+	// Version returns the batch header version implemented by the codec.
+	Version() uint8
+
+	// DecodeChunkBlockContexts decodes the block contexts of each committed chunk of a batch.
+	DecodeChunkBlockContexts(chunks [][]byte) ([][]*BlockContext, error)
+
+	// DecodeChunkL2Transactions decodes the L2 transactions of each block of each committed chunk.
+	DecodeChunkL2Transactions(chunks [][]byte) ([][]types.Transactions, error)
+
+	// BatchHash computes the hash of the batch header from local block data.
+	BatchHash(batchIndex, totalL1MessagePoppedBefore uint64, parentBatchHash common.Hash, chunks []*Chunk) (common.Hash, error)
+
+	// DALimits returns the limits that the chunks and batches of the codec must respect.
+	DALimits() DALimits
+
+	// EncodedBlockSize returns the number of bytes a block adds to the encoding of its chunk.
+	EncodedBlockSize(block *WrappedBlock) (uint64, error)
+
+	// EncodedTxSize returns the number of bytes a transaction adds to the encoding of its block.
+	EncodedTxSize(tx *types.Transaction) (uint64, error)
+}
+
+// DALimits are the size limits of the chunks and batches committed to L1.
+type DALimits struct {
+	MaxBlocksPerChunk  uint64 `json:"maxBlocksPerChunk"`
+	MaxChunksPerBatch  uint64 `json:"maxChunksPerBatch"`
+	MaxBatchBytes      uint64 `json:"maxBatchBytes"`      // encoded chunk bytes of a batch
+	ChunkOverheadBytes uint64 `json:"chunkOverheadBytes"` // bytes of a chunk encoding not attributed to its blocks
+}
+
+var (
+	codecsLock sync.RWMutex
+	codecs     = make(map[uint8]Codec)
+)
+
+func init() {
+	RegisterCodec(codecV0{})
+	RegisterCodec(codecV1)
+	RegisterCodec(codecV2)
+	RegisterCodec(codecV3)
+	RegisterCodec(codecV4)
+}
+
+// RegisterCodec makes a codec available for batches of its version.
+// It panics if a codec is already registered for the version.
+func RegisterCodec(codec Codec) {
+	codecsLock.Lock()
+	defer codecsLock.Unlock()
+
+	if _, ok := codecs[codec.Version()]; ok {
+		panic(fmt.Sprintf("codec for batch version %d already registered", codec.Version()))
+	}
+	codecs[codec.Version()] = codec
+}
+
+// CodecForVersion returns the codec registered for the given batch header version.
+func CodecForVersion(version uint8) (Codec, error) {
+	codecsLock.RLock()
+	defer codecsLock.RUnlock()
+
+	codec, ok := codecs[version]
+	if !ok {
+		return nil, fmt.Errorf("unsupported batch version: %v", version)
+	}
+	return codec, nil
+}
+
+// CodecVersions returns the versions of all registered codecs in ascending order.
+func CodecVersions() []uint8 {
+	codecs := registeredCodecs()
+	versions := make([]uint8, len(codecs))
+	for i, codec := range codecs {
+		versions[i] = codec.Version()
+	}
+	return versions
+}
+
+// LatestCodec returns the codec of the highest registered version, which the batches
+// of newly built blocks are committed with.
+func LatestCodec() Codec {
+	codecs := registeredCodecs()
+	return codecs[len(codecs)-1]
+}
+
+// registeredCodecs returns all registered codecs ordered by version.
+func registeredCodecs() []Codec {
+	codecsLock.RLock()
+	defer codecsLock.RUnlock()
+
+	list := make([]Codec, 0, len(codecs))
+	for _, codec := range codecs {
+		list = append(list, codec)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Version() < list[j].Version() })
+	return list
+}
+
+// codecForBatch returns the codec of a committed batch. Batches committed before
+// the codec version was recorded are assumed to use version 0. Blob codecs are
+// bound to the blob recorded for the batch.
+func (s *RollupSyncService) codecForBatch(batchIndex uint64) (Codec, error) {
+	var version uint8
+	batchL1Meta := rawdb.ReadBatchL1Meta(s.db, batchIndex)
+	if batchL1Meta != nil {
+		version = batchL1Meta.CodecVersion
+	}
+	codec, err := CodecForVersion(version)
+	if err != nil {
+		return nil, err
+	}
+	if blobCodec, ok := codec.(BlobCodec); ok && batchL1Meta != nil {
+		codec = blobCodec.WithBlob(&BatchBlob{VersionedHash: batchL1Meta.BlobVersionedHash, DataProof: batchL1Meta.BlobDataProof})
+	}
+	return codec, nil
+}
+
+// codecV0 implements the RollupV2 BatchHeaderV0Codec and chunk encoding.
+type codecV0 struct{}
+
+func (codecV0) Version() uint8 {
+	return batchHeaderVersion
+}
+
+// DecodeChunkBlockContexts decodes the block contexts of each committed chunk. Version 0 does
+// not commit the base fee, so the BaseFee of the decoded contexts is left nil.
+func (codecV0) DecodeChunkBlockContexts(chunks [][]byte) ([][]*BlockContext, error) {
+	chunkBlockContexts, err := DecodeChunkBlockContexts(chunks)
+	if err != nil {
+		return nil, err
+	}
+	for _, blockContexts := range chunkBlockContexts {
+		for _, blockContext := range blockContexts {
+			blockContext.BaseFee = nil
+		}
+	}
+	return chunkBlockContexts, nil
+}
+
+func (codecV0) DecodeChunkL2Transactions(chunks [][]byte) ([][]types.Transactions, error) {
+	return DecodeChunkL2Transactions(chunks)
+}
+
+func (codecV0) BatchHash(batchIndex, totalL1MessagePoppedBefore uint64, parentBatchHash common.Hash, chunks []*Chunk) (common.Hash, error) {
+	batchHeader, err := NewBatchHeader(batchHeaderVersion, batchIndex, totalL1MessagePoppedBefore, parentBatchHash, chunks)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	return batchHeader.Hash(), nil
+}
+
+func (codecV0) DALimits() DALimits {
+	return DALimits{
+		MaxBlocksPerChunk:  255, // the number of blocks is encoded in 1 byte
+		MaxChunksPerBatch:  15,
+		MaxBatchBytes:      128 * 1024, // L1 transaction size limit of the commitBatch transaction
+		ChunkOverheadBytes: 1,
+	}
+}
+
+func (codecV0) EncodedBlockSize(block *WrappedBlock) (uint64, error) {
+	size := uint64(blockContextByteSize)
+	for _, txData := range block.Transactions {
+		if txData.Type == types.L1MessageTxType {
+			continue
+		}
+		rlpTxData, err := convertTxDataToRLPEncoding(txData)
+		if err != nil {
+			return 0, err
+		}
+		size += 4 + uint64(len(rlpTxData))
+	}
+	return size, nil
+}
+
+func (codecV0) EncodedTxSize(tx *types.Transaction) (uint64, error) {
+	if tx.IsL1MessageTx() {
+		return 0, nil
+	}
+	rlpTxData, err := tx.MarshalBinary()
+	if err != nil {
+		return 0, fmt.Errorf("failed to marshal binary of the tx: %v, err: %w", tx.Hash().Hex(), err)
+	}
+	return 4 + uint64(len(rlpTxData)), nil
+}
@@ -0,0 +1,529 @@
+package rollup_sync_service
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/scroll-tech/go-ethereum/common"
+	"github.com/scroll-tech/go-ethereum/core/types"
+	"github.com/scroll-tech/go-ethereum/crypto"
+	"github.com/scroll-tech/go-ethereum/crypto/kzg4844"
+	"github.com/scroll-tech/go-ethereum/rlp"
+)
+
+const (
+	// blobFieldElements is the number of field elements of a blob. The first byte of each
+	// field element is zero to keep it below the BLS modulus, the other 31 bytes store data.
+	blobFieldElements        = 4096
+	blobBytesPerFieldElement = 31
+
+	// maxBlobDataBytes is the number of bytes a blob stores.
+	maxBlobDataBytes = blobFieldElements * blobBytesPerFieldElement
+
+	// maxDecompressedBatchBytes limits the decompressed payload of a blob.
+	maxDecompressedBatchBytes = 16 * maxBlobDataBytes
+
+	// blobDataProofClaimOffset is the offset of the claim y in the blob data proof of the commit
+	// calldata, which starts with the evaluation point z followed by y, the KZG commitment and the proof.
+	blobDataProofClaimOffset = 32
+)
+
+// blsModulus is the order of the BLS12-381 scalar field, the evaluation point of the blob data
+// proof is reduced modulo it.
+var blsModulus, _ = new(big.Int).SetString("73eda753299d7d483339d80809a1d80553bda402fffe5bfeffffffff00000001", 16)
+
+// errMissingBatchBlob is returned when the hash of a batch covers blob data that is not known locally.
+var errMissingBatchBlob = errors.New("blob of the batch is unknown")
+
+// BatchBlob describes the blob that a batch committed to L1 along with its commit transaction.
+type BatchBlob struct {
+	VersionedHash common.Hash // versioned hash of the blob carried by the commit transaction
+	DataProof     []byte      // blob data proof of the commitBatchWithBlobProof calldata, from version 3
+}
+
+// BlobCodec is implemented by the codecs of the batches that commit their L2 transactions to an
+// EIP-4844 blob, from version 1. Their committed chunks only contain the block contexts. The blob
+// payload starts with the number of chunks and the byte length of each chunk, followed by the
+// binary encodings of the L2 transactions of all chunks.
+type BlobCodec interface {
+	Codec
+
+	// WithBlob returns the codec bound to the blob committed by a batch. The batch hash covers the
+	// versioned hash of the blob, which cannot be rebuilt from local blocks if the blob payload is
+	// compressed, so BatchHash fails for such codecs unless they are bound to the committed blob.
+	WithBlob(blob *BatchBlob) BlobCodec
+
+	// DecodeBlobL2Transactions decodes the L2 transactions of each block of each committed chunk
+	// from the blob of the batch.
+	DecodeBlobL2Transactions(blob *kzg4844.Blob, chunkBlockContexts [][]*BlockContext) ([][]types.Transactions, error)
+
+	// CheckBlob checks that the blob of a batch carries the L2 transactions of the given chunks.
+	CheckBlob(blob *kzg4844.Blob, chunks []*Chunk) error
+}
+
+// blobCompression is the compression of the payload of a blob.
+type blobCompression int
+
+const (
+	blobUncompressed         blobCompression = iota // the blob stores the payload
+	blobCompressed                                  // the blob stores the payload compressed with zstd
+	blobOptionallyCompressed                        // the first byte of the blob flags whether the payload is compressed
+)
+
+// blobCodec implements the codecs of the batches that commit to blobs. The versions differ in the
+// maximum number of chunks of a batch, the compression of the blob payload and whether the batch
+// header includes the blob data proof.
+type blobCodec struct {
+	version       uint8
+	maxNumChunks  int
+	compression   blobCompression
+	blobDataProof bool // the batch header covers the last block timestamp and the blob data proof
+
+	blob *BatchBlob // committed blob the codec is bound to, nil if unbound
+}
+
+var (
+	codecV1 = &blobCodec{version: 1, maxNumChunks: 15, compression: blobUncompressed}
+	codecV2 = &blobCodec{version: 2, maxNumChunks: 45, compression: blobCompressed}
+	codecV3 = &blobCodec{version: 3, maxNumChunks: 45, compression: blobCompressed, blobDataProof: true}
+	codecV4 = &blobCodec{version: 4, maxNumChunks: 45, compression: blobOptionallyCompressed, blobDataProof: true}
+)
+
+func (c *blobCodec) Version() uint8 {
+	return c.version
+}
+
+func (c *blobCodec) WithBlob(blob *BatchBlob) BlobCodec {
+	bound := *c
+	bound.blob = blob
+	return &bound
+}
+
+// metadataLength returns the length of the metadata at the start of the blob payload, the number
+// of chunks followed by the byte length of each chunk.
+func (c *blobCodec) metadataLength() int {
+	return 2 + 4*c.maxNumChunks
+}
+
+func (c *blobCodec) DecodeChunkBlockContexts(chunks [][]byte) ([][]*BlockContext, error) {
+	if len(chunks) > c.maxNumChunks {
+		return nil, fmt.Errorf("batch has %d chunks, at most %d allowed in batch version %d", len(chunks), c.maxNumChunks, c.version)
+	}
+	chunkBlockContexts, err := DecodeChunkBlockContexts(chunks)
+	if err != nil {
+		return nil, err
+	}
+	for i, chunk := range chunks {
+		if expected := 1 + len(chunkBlockContexts[i])*blockContextByteSize; len(chunk) != expected {
+			return nil, fmt.Errorf("chunk %d has %d bytes, expected %d bytes of block contexts", i, len(chunk), expected)
+		}
+	}
+	return chunkBlockContexts, nil
+}
+
+func (c *blobCodec) DecodeChunkL2Transactions(chunks [][]byte) ([][]types.Transactions, error) {
+	return nil, fmt.Errorf("the L2 transactions of batch version %d are committed to the blob", c.version)
+}
+
+func (c *blobCodec) BatchHash(batchIndex, totalL1MessagePoppedBefore uint64, parentBatchHash common.Hash, chunks []*Chunk) (common.Hash, error) {
+	header, err := c.encodeBatchHeader(batchIndex, totalL1MessagePoppedBefore, parentBatchHash, chunks)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	return crypto.Keccak256Hash(header), nil
+}
+
+// encodeBatchHeader encodes the header of a batch of local blocks, the hash of the batch is the hash of its header.
+func (c *blobCodec) encodeBatchHeader(batchIndex, totalL1MessagePoppedBefore uint64, parentBatchHash common.Hash, chunks []*Chunk) ([]byte, error) {
+	if len(chunks) == 0 || len(chunks) > c.maxNumChunks {
+		return nil, fmt.Errorf("batch has %d chunks, expected 1 to %d chunks in batch version %d", len(chunks), c.maxNumChunks, c.version)
+	}
+	skippedBitmap, chunkL1MessagePoppedBefore, totalL1MessagePopped, err := skippedL1MessageBitmap(batchIndex, totalL1MessagePoppedBefore, chunks)
+	if err != nil {
+		return nil, err
+	}
+
+	var dataBytes []byte
+	for i, chunk := range chunks {
+		chunkHash, err := c.chunkHash(chunk, chunkL1MessagePoppedBefore[i])
+		if err != nil {
+			return nil, err
+		}
+		dataBytes = append(dataBytes, chunkHash[:]...)
+	}
+	dataHash := crypto.Keccak256Hash(dataBytes)
+
+	payload, err := c.payload(chunks)
+	if err != nil {
+		return nil, err
+	}
+	var versionedHash common.Hash
+	if c.compression == blobUncompressed {
+		blob, err := makeBlob(payload)
+		if err != nil {
+			return nil, err
+		}
+		if versionedHash, err = blobVersionedHash(blob); err != nil {
+			return nil, err
+		}
+	} else {
+		if c.blob == nil || c.blob.VersionedHash == (common.Hash{}) {
+			return nil, fmt.Errorf("%w: no versioned hash of batch %d", errMissingBatchBlob, batchIndex)
+		}
+		versionedHash = c.blob.VersionedHash
+	}
+
+	header := []byte{c.version}
+	header = binary.BigEndian.AppendUint64(header, batchIndex)
+	header = binary.BigEndian.AppendUint64(header, totalL1MessagePopped-totalL1MessagePoppedBefore)
+	header = binary.BigEndian.AppendUint64(header, totalL1MessagePopped)
+	header = append(header, dataHash[:]...)
+	header = append(header, versionedHash[:]...)
+	header = append(header, parentBatchHash[:]...)
+	if !c.blobDataProof {
+		return append(header, skippedBitmap...), nil
+	}
+
+	// note: the claim is the evaluation of the blob at z, it cannot be computed without the blob
+	if c.blob == nil || len(c.blob.DataProof) < blobDataProofClaimOffset+32 {
+		return nil, fmt.Errorf("%w: no blob data proof of batch %d", errMissingBatchBlob, batchIndex)
+	}
+	chunkData, err := c.chunkData(payload)
+	if err != nil {
+		return nil, err
+	}
+	lastChunk := chunks[len(chunks)-1]
+	if len(lastChunk.Blocks) == 0 {
+		return nil, fmt.Errorf("last chunk of batch %d has no blocks", batchIndex)
+	}
+	z := c.challengePoint(payload, chunkData, versionedHash)
+	header = binary.BigEndian.AppendUint64(header, lastChunk.Blocks[len(lastChunk.Blocks)-1].Header.Time)
+	header = append(header, z[:]...)
+	return append(header, c.blob.DataProof[blobDataProofClaimOffset:blobDataProofClaimOffset+32]...), nil
+}
+
+// encodeBlockContext encodes the block context of a block, unlike version 0 including its base fee.
+func (c *blobCodec) encodeBlockContext(block *WrappedBlock, totalL1MessagePoppedBefore uint64) ([]byte, error) {
+	blockContext, err := block.Encode(totalL1MessagePoppedBefore)
+	if err != nil {
+		return nil, err
+	}
+	if block.Header.BaseFee != nil {
+		block.Header.BaseFee.FillBytes(blockContext[16:48])
+	}
+	return blockContext, nil
+}
+
+// encodeChunk returns the committed encoding of a chunk, the number of blocks followed by the block contexts.
+func (c *blobCodec) encodeChunk(chunk *Chunk, totalL1MessagePoppedBefore uint64) ([]byte, error) {
+	if len(chunk.Blocks) == 0 || len(chunk.Blocks) > 255 {
+		return nil, fmt.Errorf("chunk has %d blocks, expected 1 to 255 blocks", len(chunk.Blocks))
+	}
+	encoded := []byte{byte(len(chunk.Blocks))}
+	for _, block := range chunk.Blocks {
+		blockContext, err := c.encodeBlockContext(block, totalL1MessagePoppedBefore)
+		if err != nil {
+			return nil, err
+		}
+		encoded = append(encoded, blockContext...)
+		totalL1MessagePoppedBefore += block.numL1Messages(totalL1MessagePoppedBefore)
+	}
+	return encoded, nil
+}
+
+// chunkHash returns the hash of a chunk, which covers its block contexts and the hashes of its L1 messages.
+// Its L2 transactions are covered by the blob.
+func (c *blobCodec) chunkHash(chunk *Chunk, totalL1MessagePoppedBefore uint64) (common.Hash, error) {
+	preimage, err := c.chunkHashPreimage(chunk, totalL1MessagePoppedBefore)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	return crypto.Keccak256Hash(preimage), nil
+}
+
+// chunkHashPreimage returns the data hashed into the chunk hash: the block contexts followed by the
+// L1 message hashes of each block.
+func (c *blobCodec) chunkHashPreimage(chunk *Chunk, totalL1MessagePoppedBefore uint64) ([]byte, error) {
+	var preimage []byte
+	for _, block := range chunk.Blocks {
+		blockContext, err := c.encodeBlockContext(block, totalL1MessagePoppedBefore)
+		if err != nil {
+			return nil, err
+		}
+		preimage = append(preimage, blockContext[:blockContextHashByteSize]...)
+		totalL1MessagePoppedBefore += block.numL1Messages(totalL1MessagePoppedBefore)
+	}
+	for _, block := range chunk.Blocks {
+		for _, txData := range block.Transactions {
+			if txData.Type != types.L1MessageTxType {
+				continue
+			}
+			hashBytes, err := hex.DecodeString(strings.TrimPrefix(txData.TxHash, "0x"))
+			if err != nil {
+				return nil, err
+			}
+			preimage = append(preimage, hashBytes...)
+		}
+	}
+	return preimage, nil
+}
+
+// payload returns the uncompressed blob payload of the chunks of a batch.
+func (c *blobCodec) payload(chunks []*Chunk) ([]byte, error) {
+	payload := make([]byte, c.metadataLength())
+	binary.BigEndian.PutUint16(payload, uint16(len(chunks)))
+	for i, chunk := range chunks {
+		start := len(payload)
+		for _, block := range chunk.Blocks {
+			encodings, err := block.l2TransactionsRLP()
+			if err != nil {
+				return nil, err
+			}
+			for _, encoding := range encodings {
+				payload = append(payload, encoding...)
+			}
+		}
+		binary.BigEndian.PutUint32(payload[2+4*i:], uint32(len(payload)-start))
+	}
+	return payload, nil
+}
+
+// chunkData splits a blob payload into the data of its chunks.
+func (c *blobCodec) chunkData(payload []byte) ([][]byte, error) {
+	if len(payload) < c.metadataLength() {
+		return nil, fmt.Errorf("blob payload of %d bytes is shorter than its metadata of %d bytes", len(payload), c.metadataLength())
+	}
+	numChunks := int(binary.BigEndian.Uint16(payload))
+	if numChunks == 0 || numChunks > c.maxNumChunks {
+		return nil, fmt.Errorf("blob payload has %d chunks, expected 1 to %d chunks", numChunks, c.maxNumChunks)
+	}
+	chunkData := make([][]byte, numChunks)
+	offset := c.metadataLength()
+	for i := range chunkData {
+		size := int(binary.BigEndian.Uint32(payload[2+4*i:]))
+		if size > len(payload)-offset {
+			return nil, fmt.Errorf("chunk %d of %d bytes exceeds the blob payload", i, size)
+		}
+		chunkData[i] = payload[offset : offset+size]
+		offset += size
+	}
+	// note: uncompressed payloads are followed by the zero padding of the blob
+	return chunkData, nil
+}
+
+// challengePoint returns the evaluation point z of the blob data proof, which commits to the
+// metadata and the data of each chunk of the payload and to the versioned hash of the blob.
+func (c *blobCodec) challengePoint(payload []byte, chunkData [][]byte, versionedHash common.Hash) common.Hash {
+	preimage := c.challengePreimage(payload, chunkData, versionedHash)
+	z := new(big.Int).Mod(new(big.Int).SetBytes(crypto.Keccak256(preimage)), blsModulus)
+	return common.BigToHash(z)
+}
+
+// challengePreimage returns the data hashed into the evaluation point of the blob data proof: the hash
+// of the metadata, the hash of the data of each chunk and the versioned hash of the blob.
+func (c *blobCodec) challengePreimage(payload []byte, chunkData [][]byte, versionedHash common.Hash) []byte {
+	preimage := make([]byte, 0, (2+c.maxNumChunks)*common.HashLength)
+	metadataHash := crypto.Keccak256Hash(payload[:c.metadataLength()])
+	preimage = append(preimage, metadataHash[:]...)
+	var chunkDataHash common.Hash
+	for i := 0; i < c.maxNumChunks; i++ {
+		// the hash of the last chunk pads the slots of the missing chunks
+		if i < len(chunkData) {
+			chunkDataHash = crypto.Keccak256Hash(chunkData[i])
+		}
+		preimage = append(preimage, chunkDataHash[:]...)
+	}
+	return append(preimage, versionedHash[:]...)
+}
+
+// decodePayload returns the uncompressed payload stored in a blob.
+func (c *blobCodec) decodePayload(blob *kzg4844.Blob) ([]byte, error) {
+	data, err := blobData(blob)
+	if err != nil {
+		return nil, err
+	}
+	switch c.compression {
+	case blobCompressed:
+		return decompressBatchPayload(data, maxDecompressedBatchBytes)
+	case blobOptionallyCompressed:
+		switch data[0] {
+		case 0:
+			return data[1:], nil
+		case 1:
+			return decompressBatchPayload(data[1:], maxDecompressedBatchBytes)
+		default:
+			return nil, fmt.Errorf("invalid compression flag %d of blob", data[0])
+		}
+	default:
+		return data, nil
+	}
+}
+
+func (c *blobCodec) DecodeBlobL2Transactions(blob *kzg4844.Blob, chunkBlockContexts [][]*BlockContext) ([][]types.Transactions, error) {
+	payload, err := c.decodePayload(blob)
+	if err != nil {
+		return nil, err
+	}
+	chunkData, err := c.chunkData(payload)
+	if err != nil {
+		return nil, err
+	}
+	if len(chunkData) != len(chunkBlockContexts) {
+		return nil, fmt.Errorf("blob has %d chunks, batch committed %d chunks", len(chunkData), len(chunkBlockContexts))
+	}
+
+	chunkTxs := make([][]types.Transactions, len(chunkBlockContexts))
+	for i, blockContexts := range chunkBlockContexts {
+		data := chunkData[i]
+		chunkTxs[i] = make([]types.Transactions, len(blockContexts))
+		for j, blockContext := range blockContexts {
+			if blockContext.NumTransactions < blockContext.NumL1Messages {
+				return nil, fmt.Errorf("invalid block context of block %d, %d transactions but %d L1 messages", blockContext.BlockNumber, blockContext.NumTransactions, blockContext.NumL1Messages)
+			}
+			// note: the number of transactions includes the skipped L1 messages
+			txs := make(types.Transactions, blockContext.NumTransactions-blockContext.NumL1Messages)
+			for k := range txs {
+				size, err := txEncodingSize(data)
+				if err != nil {
+					return nil, fmt.Errorf("chunk %d ends within transaction %d of block %d: %w", i, k, blockContext.BlockNumber, err)
+				}
+				tx := new(types.Transaction)
+				if err := tx.UnmarshalBinary(data[:size]); err != nil {
+					return nil, fmt.Errorf("failed to decode transaction %d of block %d: %w", k, blockContext.BlockNumber, err)
+				}
+				txs[k] = tx
+				data = data[size:]
+			}
+			chunkTxs[i][j] = txs
+		}
+		if len(data) != 0 {
+			return nil, fmt.Errorf("chunk %d has %d trailing bytes", i, len(data))
+		}
+	}
+	return chunkTxs, nil
+}
+
+func (c *blobCodec) CheckBlob(blob *kzg4844.Blob, chunks []*Chunk) error {
+	payload, err := c.decodePayload(blob)
+	if err != nil {
+		return err
+	}
+	committed, err := c.chunkData(payload)
+	if err != nil {
+		return err
+	}
+	localPayload, err := c.payload(chunks)
+	if err != nil {
+		return err
+	}
+	local, err := c.chunkData(localPayload)
+	if err != nil {
+		return err
+	}
+	if len(committed) != len(local) {
+		return fmt.Errorf("blob has %d chunks, local batch has %d chunks", len(committed), len(local))
+	}
+	for i := range local {
+		if !bytes.Equal(committed[i], local[i]) {
+			return fmt.Errorf("L2 transactions of chunk %d differ from the blob", i)
+		}
+	}
+	return nil
+}
+
+func (c *blobCodec) DALimits() DALimits {
+	maxBatchBytes := maxBlobDataBytes - c.metadataLength()
+	if c.compression == blobOptionallyCompressed {
+		maxBatchBytes-- // compression flag
+	}
+	// note: the limits do not rely on compression, the compression ratio is only known once a batch is complete
+	return DALimits{
+		MaxBlocksPerChunk:  255, // the number of blocks is encoded in 1 byte
+		MaxChunksPerBatch:  uint64(c.maxNumChunks),
+		MaxBatchBytes:      uint64(maxBatchBytes),
+		ChunkOverheadBytes: 0,
+	}
+}
+
+func (c *blobCodec) EncodedBlockSize(block *WrappedBlock) (uint64, error) {
+	encodings, err := block.l2TransactionsRLP()
+	if err != nil {
+		return 0, err
+	}
+	var size uint64
+	for _, encoding := range encodings {
+		size += uint64(len(encoding))
+	}
+	return size, nil
+}
+
+func (c *blobCodec) EncodedTxSize(tx *types.Transaction) (uint64, error) {
+	if tx.IsL1MessageTx() {
+		return 0, nil
+	}
+	rlpTxData, err := tx.MarshalBinary()
+	if err != nil {
+		return 0, fmt.Errorf("failed to marshal binary of the tx: %v, err: %w", tx.Hash().Hex(), err)
+	}
+	return uint64(len(rlpTxData)), nil
+}
+
+// txEncodingSize returns the length of the binary encoding of the transaction at the start of data. Legacy
+// transactions are encoded as an RLP list, typed transactions as their type byte followed by an RLP list.
+func txEncodingSize(data []byte) (int, error) {
+	if len(data) == 0 {
+		return 0, errors.New("no transaction data")
+	}
+	var typeLength int
+	if data[0] < 0xc0 {
+		typeLength = 1
+	}
+	_, _, rest, err := rlp.Split(data[typeLength:])
+	if err != nil {
+		return 0, err
+	}
+	return len(data) - len(rest), nil
+}
+
+// makeBlob stores data in the field elements of a blob.
+func makeBlob(data []byte) (*kzg4844.Blob, error) {
+	if len(data) > maxBlobDataBytes {
+		return nil, fmt.Errorf("blob data of %d bytes exceeds the blob capacity of %d bytes", len(data), maxBlobDataBytes)
+	}
+	blob := new(kzg4844.Blob)
+	for i := 0; i*blobBytesPerFieldElement < len(data); i++ {
+		end := (i + 1) * blobBytesPerFieldElement
+		if end > len(data) {
+			end = len(data)
+		}
+		copy(blob[i*32+1:], data[i*blobBytesPerFieldElement:end])
+	}
+	return blob, nil
+}
+
+// blobData returns the data stored in the field elements of a blob.
+func blobData(blob *kzg4844.Blob) ([]byte, error) {
+	data := make([]byte, 0, maxBlobDataBytes)
+	for i := 0; i < blobFieldElements; i++ {
+		if blob[i*32] != 0 {
+			return nil, fmt.Errorf("invalid field element %d of blob, first byte is %d", i, blob[i*32])
+		}
+		data = append(data, blob[i*32+1:(i+1)*32]...)
+	}
+	return data, nil
+}
+
+// blobVersionedHash returns the versioned hash of the KZG commitment of a blob.
+func blobVersionedHash(blob *kzg4844.Blob) (common.Hash, error) {
+	commitment, err := kzg4844.BlobToCommitment(*blob)
+	if err != nil {
+		return common.Hash{}, fmt.Errorf("failed to compute blob commitment: %w", err)
+	}
+	return kzg4844.CalcBlobHashV1(sha256.New(), &commitment), nil
+}
@@ -0,0 +1,272 @@
+package rollup_sync_service
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/scroll-tech/go-ethereum/common"
+	"github.com/scroll-tech/go-ethereum/core/types"
+	"github.com/scroll-tech/go-ethereum/crypto"
+	"github.com/scroll-tech/go-ethereum/crypto/kzg4844"
+)
+
+var blobCodecs = []*blobCodec{codecV1, codecV2, codecV3, codecV4}
+
+// loadTraceChunks returns one chunk per block trace of testdata.
+func loadTraceChunks(t *testing.T, traces ...string) []*Chunk {
+	raw := make([][]json.RawMessage, len(traces))
+	for i, trace := range traces {
+		raw[i] = []json.RawMessage{json.RawMessage(`"` + trace + `"`)}
+	}
+	chunks, err := loadVectorChunks("./testdata", raw)
+	require.NoError(t, err)
+	return chunks
+}
+
+func TestBlobCodecBatchHeader(t *testing.T) {
+	chunks := loadTraceChunks(t, "blockTrace_02.json", "blockTrace_04.json")
+	parentBatchHash := common.Hash{0xaa}
+
+	// the chunk hashes cover the block contexts including the base fee and the L1 message hashes
+	var dataBytes []byte
+	var totalL1MessagePopped uint64
+	for _, chunk := range chunks {
+		block := chunk.Blocks[0]
+		blockContext, err := block.Encode(totalL1MessagePopped)
+		require.NoError(t, err)
+		if block.Header.BaseFee != nil {
+			block.Header.BaseFee.FillBytes(blockContext[16:48])
+		}
+		preimage := blockContext[:58]
+		for _, txData := range block.Transactions {
+			if txData.Type == types.L1MessageTxType {
+				preimage = append(preimage, common.HexToHash(txData.TxHash).Bytes()...)
+			}
+		}
+		dataBytes = append(dataBytes, crypto.Keccak256(preimage)...)
+		totalL1MessagePopped += chunk.NumL1Messages(totalL1MessagePopped)
+	}
+	dataHash := crypto.Keccak256(dataBytes)
+	headerV0, err := NewBatchHeader(0, 1, 0, parentBatchHash, chunks)
+	require.NoError(t, err)
+	encodedV0 := headerV0.Encode()
+	require.NotEmpty(t, encodedV0[89:], "the chunks skip L1 messages")
+
+	blob := &BatchBlob{VersionedHash: common.Hash{0x01, 0x02}, DataProof: make([]byte, 160)}
+	for i := range blob.DataProof {
+		blob.DataProof[i] = byte(i)
+	}
+	lastBlock := chunks[1].Blocks[0]
+	for _, codec := range blobCodecs {
+		header, err := codec.WithBlob(blob).(*blobCodec).encodeBatchHeader(1, 0, parentBatchHash, chunks)
+		require.NoError(t, err, "version %d", codec.version)
+
+		assert.Equal(t, codec.version, header[0])
+		assert.Equal(t, encodedV0[1:25], header[1:25], "batch index and L1 messages popped")
+		assert.Equal(t, dataHash, header[25:57])
+		if codec.version == 1 {
+			// the blob of uncompressed payloads is rebuilt locally
+			payload, err := codec.payload(chunks)
+			require.NoError(t, err)
+			localBlob, err := makeBlob(payload)
+			require.NoError(t, err)
+			versionedHash, err := blobVersionedHash(localBlob)
+			require.NoError(t, err)
+			assert.Equal(t, versionedHash[:], header[57:89])
+			assert.True(t, kzg4844.IsValidVersionedHash(header[57:89]))
+		} else {
+			assert.Equal(t, blob.VersionedHash[:], header[57:89])
+		}
+		assert.Equal(t, parentBatchHash[:], header[89:121])
+
+		if !codec.blobDataProof {
+			assert.Equal(t, encodedV0[89:], header[121:], "skipped L1 message bitmap")
+		} else {
+			require.Len(t, header, 193)
+			assert.Equal(t, lastBlock.Header.Time, binary.BigEndian.Uint64(header[121:129]))
+			payload, err := codec.payload(chunks)
+			require.NoError(t, err)
+			chunkData, err := codec.chunkData(payload)
+			require.NoError(t, err)
+			z := codec.challengePoint(payload, chunkData, blob.VersionedHash)
+			assert.Equal(t, z[:], header[129:161])
+			assert.Equal(t, blob.DataProof[32:64], header[161:193])
+		}
+
+		batchHash, err := codec.WithBlob(blob).BatchHash(1, 0, parentBatchHash, chunks)
+		require.NoError(t, err)
+		assert.Equal(t, crypto.Keccak256Hash(header), batchHash)
+	}
+
+	// compressed blobs and the blob data proof are only known from the commit transaction
+	_, err = codecV1.BatchHash(1, 0, parentBatchHash, chunks)
+	assert.NoError(t, err)
+	_, err = codecV2.BatchHash(1, 0, parentBatchHash, chunks)
+	assert.ErrorIs(t, err, errMissingBatchBlob)
+	_, err = codecV3.WithBlob(&BatchBlob{VersionedHash: blob.VersionedHash}).BatchHash(1, 0, parentBatchHash, chunks)
+	assert.ErrorIs(t, err, errMissingBatchBlob)
+	_, err = codecV1.BatchHash(1, 0, parentBatchHash, make([]*Chunk, 16))
+	assert.Error(t, err)
+}
+
+func TestBlobCodecChallengePoint(t *testing.T) {
+	chunks := loadTraceChunks(t, "blockTrace_02.json")
+	payload, err := codecV3.payload(chunks)
+	require.NoError(t, err)
+	chunkData, err := codecV3.chunkData(payload)
+	require.NoError(t, err)
+	versionedHash := common.Hash{0x01}
+
+	// the hash of the last chunk pads the preimage to the maximum number of chunks
+	preimage := crypto.Keccak256(payload[:codecV3.metadataLength()])
+	for i := 0; i < codecV3.maxNumChunks; i++ {
+		preimage = append(preimage, crypto.Keccak256(chunkData[0])...)
+	}
+	preimage = append(preimage, versionedHash[:]...)
+	expected := new(big.Int).Mod(new(big.Int).SetBytes(crypto.Keccak256(preimage)), blsModulus)
+	z := codecV3.challengePoint(payload, chunkData, versionedHash)
+	assert.Equal(t, common.BigToHash(expected), z)
+
+	// the point is a valid evaluation point of the blob
+	blob, err := makeBlob(zstdRawFrame(payload))
+	require.NoError(t, err)
+	commitment, err := kzg4844.BlobToCommitment(*blob)
+	require.NoError(t, err)
+	proof, claim, err := kzg4844.ComputeProof(*blob, kzg4844.Point(z))
+	require.NoError(t, err)
+	assert.NoError(t, kzg4844.VerifyProof(commitment, kzg4844.Point(z), claim, proof))
+}
+
+func TestBlobCodecDecodeBlob(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+	signer := types.NewLondonSigner(big.NewInt(534352))
+	to := common.Address{0x01}
+	legacyTx := types.MustSignNewTx(key, signer, &types.LegacyTx{Nonce: 0, To: &to, Gas: 21000, GasPrice: big.NewInt(1)})
+	dynamicFeeTx := types.MustSignNewTx(key, signer, &types.DynamicFeeTx{ChainID: big.NewInt(534352), Nonce: 1, To: &to, Gas: 21000, GasFeeCap: big.NewInt(2), GasTipCap: big.NewInt(1), Data: []byte{1, 2, 3}})
+	accessListTx := types.MustSignNewTx(key, signer, &types.AccessListTx{ChainID: big.NewInt(534352), Nonce: 2, Gas: 53000, GasPrice: big.NewInt(1), AccessList: types.AccessList{{Address: to}}})
+	l1Message := types.NewTx(&types.L1MessageTx{QueueIndex: 0, To: &to, Gas: 100000})
+
+	newBlock := func(number int64, txs ...*types.Transaction) *WrappedBlock {
+		return &WrappedBlock{
+			Header:       &types.Header{Number: big.NewInt(number), Time: uint64(number), GasLimit: 10000000, BaseFee: big.NewInt(number * 1000)},
+			Transactions: txsToTxsData(txs),
+			txs:          txs,
+		}
+	}
+	chunks := []*Chunk{
+		{Blocks: []*WrappedBlock{newBlock(1, l1Message, legacyTx, dynamicFeeTx), newBlock(2)}},
+		{Blocks: []*WrappedBlock{newBlock(3, accessListTx)}},
+	}
+	l2Txs := [][]types.Transactions{{{legacyTx, dynamicFeeTx}, {}}, {{accessListTx}}}
+
+	type variant struct {
+		name  string
+		codec *blobCodec
+		wrap  func(payload []byte) []byte
+	}
+	variants := []variant{
+		{"v1", codecV1, func(payload []byte) []byte { return payload }},
+		{"v2", codecV2, zstdRawFrame},
+		{"v3", codecV3, zstdRawFrame},
+		{"v4 compressed", codecV4, func(payload []byte) []byte { return append([]byte{1}, zstdRawFrame(payload)...) }},
+		{"v4 uncompressed", codecV4, func(payload []byte) []byte { return append([]byte{0}, payload...) }},
+	}
+	for _, v := range variants {
+		payload, err := v.codec.payload(chunks)
+		require.NoError(t, err, v.name)
+		blob, err := makeBlob(v.wrap(payload))
+		require.NoError(t, err, v.name)
+
+		// the committed chunks carry the block contexts including the base fee
+		committed := make([][]byte, len(chunks))
+		var totalL1MessagePopped uint64
+		for i, chunk := range chunks {
+			committed[i], err = v.codec.encodeChunk(chunk, totalL1MessagePopped)
+			require.NoError(t, err, v.name)
+			totalL1MessagePopped += chunk.NumL1Messages(totalL1MessagePopped)
+		}
+		chunkBlockContexts, err := v.codec.DecodeChunkBlockContexts(committed)
+		require.NoError(t, err, v.name)
+		assert.Equal(t, big.NewInt(3000), chunkBlockContexts[1][0].BaseFee, v.name)
+		assert.Equal(t, uint16(3), chunkBlockContexts[0][0].NumTransactions, v.name)
+		assert.Equal(t, uint16(1), chunkBlockContexts[0][0].NumL1Messages, v.name)
+
+		chunkTxs, err := v.codec.DecodeBlobL2Transactions(blob, chunkBlockContexts)
+		require.NoError(t, err, v.name)
+		require.Len(t, chunkTxs, len(l2Txs), v.name)
+		for i := range l2Txs {
+			require.Len(t, chunkTxs[i], len(l2Txs[i]), v.name)
+			for j := range l2Txs[i] {
+				require.Len(t, chunkTxs[i][j], len(l2Txs[i][j]), v.name)
+				for k, tx := range l2Txs[i][j] {
+					assert.Equal(t, tx.Hash(), chunkTxs[i][j][k].Hash(), v.name)
+				}
+			}
+		}
+		assert.NoError(t, v.codec.CheckBlob(blob, chunks), v.name)
+
+		// a blob missing a transaction of the local blocks does not match
+		other := []*Chunk{chunks[0], {Blocks: []*WrappedBlock{newBlock(3)}}}
+		assert.Error(t, v.codec.CheckBlob(blob, other), v.name)
+		_, err = v.codec.DecodeBlobL2Transactions(blob, chunkBlockContexts[:1])
+		assert.Error(t, err, v.name)
+	}
+
+	payload, err := codecV4.payload(chunks)
+	require.NoError(t, err)
+	blob, err := makeBlob(append([]byte{2}, payload...))
+	require.NoError(t, err)
+	assert.Error(t, codecV4.CheckBlob(blob, chunks), "invalid compression flag")
+
+	blob, err = makeBlob(payload)
+	require.NoError(t, err)
+	blob[32] = 1
+	assert.Error(t, codecV1.CheckBlob(blob, chunks), "invalid field element")
+
+	// the chunk sizes must not exceed the payload
+	truncated := common.CopyBytes(payload)
+	binary.BigEndian.PutUint32(truncated[2:], uint32(maxBlobDataBytes))
+	blob, err = makeBlob(append([]byte{0}, truncated...))
+	require.NoError(t, err)
+	assert.Error(t, codecV4.CheckBlob(blob, chunks))
+
+	_, err = makeBlob(make([]byte, maxBlobDataBytes+1))
+	assert.Error(t, err)
+}
+
+func TestBlobCodecChunks(t *testing.T) {
+	chunks := loadTraceChunks(t, "blockTrace_02.json")
+	committed, err := codecV1.encodeChunk(chunks[0], 0)
+	require.NoError(t, err)
+	assert.Len(t, committed, 1+blockContextByteSize)
+
+	// the chunks of blob batches only contain block contexts
+	_, err = codecV1.DecodeChunkBlockContexts([][]byte{append(committed, 0)})
+	assert.Error(t, err)
+	_, err = codecV1.DecodeChunkL2Transactions([][]byte{committed})
+	assert.Error(t, err)
+	tooMany := make([][]byte, codecV1.maxNumChunks+1)
+	for i := range tooMany {
+		tooMany[i] = committed
+	}
+	_, err = codecV1.DecodeChunkBlockContexts(tooMany)
+	assert.Error(t, err)
+	_, err = codecV2.DecodeChunkBlockContexts(tooMany)
+	assert.NoError(t, err)
+
+	assert.Equal(t, uint64(maxBlobDataBytes-62), codecV1.DALimits().MaxBatchBytes)
+	assert.Equal(t, uint64(maxBlobDataBytes-182), codecV3.DALimits().MaxBatchBytes)
+	assert.Equal(t, uint64(maxBlobDataBytes-183), codecV4.DALimits().MaxBatchBytes)
+
+	// the encoded size of a block is the size of its L2 transactions in the blob
+	payload, err := codecV1.payload(chunks)
+	require.NoError(t, err)
+	size, err := codecV1.EncodedBlockSize(chunks[0].Blocks[0])
+	require.NoError(t, err)
+	assert.Equal(t, uint64(binary.BigEndian.Uint32(payload[2:6])), size)
+}
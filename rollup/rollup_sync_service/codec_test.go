@@ -0,0 +1,48 @@
+package rollup_sync_service
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/scroll-tech/go-ethereum/common"
+	"github.com/scroll-tech/go-ethereum/core/rawdb"
+)
+
+func TestCodecRegistry(t *testing.T) {
+	codec, err := CodecForVersion(0)
+	require.NoError(t, err)
+	assert.Equal(t, uint8(0), codec.Version())
+
+	assert.Equal(t, []uint8{0, 1, 2, 3, 4}, CodecVersions())
+	assert.Equal(t, uint8(4), LatestCodec().Version())
+	_, err = CodecForVersion(5)
+	assert.Error(t, err)
+
+	assert.Panics(t, func() { RegisterCodec(codecV0{}) })
+	assert.Panics(t, func() { RegisterCodec(codecV4) })
+}
+
+func TestCodecForBatch(t *testing.T) {
+	db := rawdb.NewMemoryDatabase()
+	service := &RollupSyncService{db: db}
+
+	// batches without L1 meta data default to version 0
+	codec, err := service.codecForBatch(1)
+	require.NoError(t, err)
+	assert.Equal(t, uint8(0), codec.Version())
+
+	// blob codecs are bound to the blob of the batch
+	rawdb.WriteBatchL1Meta(db, 2, &rawdb.BatchL1Meta{CommitTxHash: common.Hash{1}, CodecVersion: 3, BlobVersionedHash: common.Hash{2}, BlobDataProof: []byte{3}})
+	codec, err = service.codecForBatch(2)
+	require.NoError(t, err)
+	assert.Equal(t, uint8(3), codec.Version())
+	require.IsType(t, &blobCodec{}, codec)
+	assert.Equal(t, &BatchBlob{VersionedHash: common.Hash{2}, DataProof: []byte{3}}, codec.(*blobCodec).blob)
+	assert.Nil(t, codecV3.blob)
+
+	rawdb.WriteBatchL1Meta(db, 3, &rawdb.BatchL1Meta{CommitTxHash: common.Hash{1}, CodecVersion: 7})
+	_, err = service.codecForBatch(3)
+	assert.Error(t, err)
+}
@@ -0,0 +1,25 @@
+package rollup_sync_service
+
+import (
+	"fmt"
+
+	"github.com/scroll-tech/go-ethereum/rollup/da/zstd"
+)
+
+// zstdMagicNumber starts every zstd frame. The blobs of compressed batches omit it.
+var zstdMagicNumber = []byte{0x28, 0xb5, 0x2f, 0xfd}
+
+// decompressBatchPayload returns the payload of a batch that its blob stores compressed with
+// zstd. The compression covers the whole batch payload, i.e. the metadata and the data of all
+// chunks: the blob carries one zstd frame without its magic number, followed by the padding of
+// the blob. The decompressed payload must not exceed limit bytes, larger payloads are rejected
+// while they are decompressed.
+func decompressBatchPayload(data []byte, limit uint64) ([]byte, error) {
+	frame := make([]byte, 0, len(zstdMagicNumber)+len(data))
+	frame = append(append(frame, zstdMagicNumber...), data...)
+	payload, err := zstd.DecompressFrame(frame, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decompress batch payload: %w", err)
+	}
+	return payload, nil
+}
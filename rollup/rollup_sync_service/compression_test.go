@@ -0,0 +1,74 @@
+package rollup_sync_service
+
+import (
+	"encoding/binary"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/scroll-tech/go-ethereum/rollup/da/zstd"
+)
+
+// zstdRawFrame returns a zstd frame storing data in uncompressed blocks, without the magic
+// number like the compressed payloads of batch blobs.
+func zstdRawFrame(data []byte) []byte {
+	frame := []byte{0xa0} // single segment with 4 byte content size
+	frame = binary.LittleEndian.AppendUint32(frame, uint32(len(data)))
+	for {
+		size := len(data)
+		if size > 128*1024 {
+			size = 128 * 1024
+		}
+		header := uint32(size) << 3
+		if size == len(data) {
+			header |= 1 // last block
+		}
+		frame = append(frame, byte(header), byte(header>>8), byte(header>>16))
+		frame = append(frame, data[:size]...)
+		if data = data[size:]; len(data) == 0 {
+			return frame
+		}
+	}
+}
+
+func TestDecompressBatchPayload(t *testing.T) {
+	payload := []byte("batch payload")
+
+	// the padding of the blob after the frame is ignored
+	padded := append(zstdRawFrame(payload), make([]byte, 100)...)
+	decompressed, err := decompressBatchPayload(padded, uint64(len(payload)))
+	require.NoError(t, err)
+	assert.Equal(t, payload, decompressed)
+
+	_, err = decompressBatchPayload(padded, uint64(len(payload))-1)
+	assert.ErrorIs(t, err, zstd.ErrTooLarge)
+
+	frame := zstdRawFrame(payload)
+	_, err = decompressBatchPayload(frame[:len(frame)-1], uint64(len(payload)))
+	assert.Error(t, err)
+
+	// the frame must not start with the magic number
+	_, err = decompressBatchPayload(append(append([]byte{}, zstdMagicNumber...), frame...), uint64(len(payload)))
+	assert.Error(t, err)
+}
+
+func FuzzDecompressBatchPayload(f *testing.F) {
+	frame := zstdRawFrame([]byte("batch payload"))
+	f.Add(frame)
+	f.Add(frame[:len(frame)/2])
+	f.Add(append(frame, make([]byte, 31)...))
+	f.Add([]byte{0xa0})
+
+	const limit = 1024
+	f.Fuzz(func(t *testing.T, data []byte) {
+		// malformed payloads are rejected with errors, the decompressed payloads are within the limit
+		payload, err := decompressBatchPayload(data, limit)
+		if err != nil {
+			return
+		}
+		if len(payload) > limit {
+			t.Fatalf("decompressed payload of %d bytes", len(payload))
+		}
+	})
+}
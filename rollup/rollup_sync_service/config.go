@@ -0,0 +1,162 @@
+package rollup_sync_service
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/scroll-tech/go-ethereum/common"
+
+	"github.com/scroll-tech/go-ethereum/rollup/da"
+	"github.com/scroll-tech/go-ethereum/rollup/sync_service"
+)
+
+// UnknownEventPolicy determines how the rollup sync service reacts to
+// ScrollChain events that it neither processes nor explicitly ignores.
+type UnknownEventPolicy string
+
+const (
+	// UnknownEventHalt stops the sync at the unknown event until the node is upgraded.
+	UnknownEventHalt UnknownEventPolicy = "halt"
+
+	// UnknownEventSkip skips the unknown event.
+	UnknownEventSkip UnknownEventPolicy = "skip"
+
+	// UnknownEventSkipWithAlert skips the unknown event, logs an error and increments the unknown event metric.
+	UnknownEventSkipWithAlert UnknownEventPolicy = "skip-with-alert"
+)
+
+// ParseUnknownEventPolicy parses the textual representation of an UnknownEventPolicy.
+func ParseUnknownEventPolicy(s string) (UnknownEventPolicy, error) {
+	switch policy := UnknownEventPolicy(s); policy {
+	case UnknownEventHalt, UnknownEventSkip, UnknownEventSkipWithAlert:
+		return policy, nil
+	default:
+		return "", fmt.Errorf("invalid unknown event policy %q, expected one of: %v, %v, %v", s, UnknownEventHalt, UnknownEventSkip, UnknownEventSkipWithAlert)
+	}
+}
+
+// VerifyMode determines how the rollup sync service reacts to a batch that fails validation
+// against the local chain (state root, withdraw root or batch hash mismatch).
+type VerifyMode string
+
+const (
+	// VerifyModeCrash shuts down the node.
+	VerifyModeCrash VerifyMode = "crash"
+
+	// VerifyModeHaltSync stops the rollup sync service but keeps the node running,
+	// so that it can keep serving RPC while the divergence is investigated.
+	VerifyModeHaltSync VerifyMode = "halt-sync"
+
+	// VerifyModeHaltImport stops the rollup sync service and pauses the import of new
+	// L2 blocks, so that a diverged node keeps serving its existing chain read-only
+	// instead of serving more and more blocks built on the diverged state.
+	VerifyModeHaltImport VerifyMode = "halt-import"
+
+	// VerifyModeLogAndContinue logs the mismatch and continues syncing, trusting the L1 values.
+	VerifyModeLogAndContinue VerifyMode = "log-and-continue"
+)
+
+// ParseVerifyMode parses the textual representation of a VerifyMode.
+func ParseVerifyMode(s string) (VerifyMode, error) {
+	switch mode := VerifyMode(s); mode {
+	case VerifyModeCrash, VerifyModeHaltSync, VerifyModeHaltImport, VerifyModeLogAndContinue:
+		return mode, nil
+	default:
+		return "", fmt.Errorf("invalid verify mode %q, expected one of: %v, %v, %v, %v", s, VerifyModeCrash, VerifyModeHaltSync, VerifyModeHaltImport, VerifyModeLogAndContinue)
+	}
+}
+
+// Config contains the optional settings of the rollup sync service.
+type Config struct {
+	// ProverTaskQueue is the URL of the backend that prover task descriptors are
+	// enqueued to on every commit event, e.g. redis://host:6379/key or https://host/path.
+	// Leave empty to disable.
+	ProverTaskQueue string `toml:",omitempty"`
+
+	// StateReexec is the maximum number of blocks re-executed to regenerate the state
+	// required for batch validation when it is missing from both the snapshot and the
+	// trie database (e.g. on pruned nodes). Zero disables re-execution.
+	StateReexec uint64 `toml:",omitempty"`
+
+	// UnknownEventPolicy is applied to ScrollChain events that are neither processed nor ignored.
+	// Defaults to UnknownEventHalt.
+	UnknownEventPolicy UnknownEventPolicy `toml:",omitempty"`
+
+	// IgnoredEventTopics are additional event signatures that are known to be irrelevant
+	// for the rollup sync service and are skipped silently.
+	IgnoredEventTopics []common.Hash `toml:",omitempty"`
+
+	// VerifyMode is applied when a finalized batch does not match the local chain.
+	// Defaults to VerifyModeCrash.
+	VerifyMode VerifyMode `toml:",omitempty"`
+
+	// Confirmations is the number of L1 confirmations after which rollup events are processed.
+	// Zero means that only finalized L1 blocks are processed. Otherwise L1 reorgs are detected
+	// and the batch updates of reorged blocks are rolled back.
+	Confirmations uint64 `toml:",omitempty"`
+
+	// ValidationWorkers is the number of goroutines that load and encode the local blocks
+	// of a batch for validation. Defaults to 1, so that catching up does not compete with
+	// block processing and RPC for CPU.
+	ValidationWorkers int `toml:",omitempty"`
+
+	// ValidationRateLimit caps the number of blocks loaded for validation per second.
+	// Zero disables the limit.
+	ValidationRateLimit uint64 `toml:",omitempty"`
+
+	// L1RequestTimeout bounds every request to the L1 endpoint, so that a hung
+	// endpoint does not block the service. Defaults to 30 seconds.
+	L1RequestTimeout time.Duration `toml:",omitempty"`
+
+	// StrictFinalizeOrder rejects finalize events whose batch indices do not directly follow
+	// the last finalized batch, or exceed the lastFinalizedBatchIndex of the ScrollChain contract.
+	StrictFinalizeOrder bool `toml:",omitempty"`
+
+	// CrossCheckL1Endpoint is the URL of a secondary L1 endpoint. If set, the rollup events
+	// of every block range are fetched from both endpoints and only processed if they match.
+	CrossCheckL1Endpoint string `toml:",omitempty"`
+
+	// CrossCheckL1Client is the client of CrossCheckL1Endpoint. It is connected by the
+	// caller, this package does not import ethclient, whose tests import eth.
+	CrossCheckL1Client sync_service.EthClient `toml:"-"`
+
+	// MaxL1FinalizedStaleness bounds the time since the latest finalized L1 block was first
+	// observed. While it is exceeded, the finalized L2 blocks are not advertised, so that a
+	// stalled L1 feed does not silently freeze the finality of the node. Zero disables the bound.
+	MaxL1FinalizedStaleness time.Duration `toml:",omitempty"`
+
+	// MetadataSink is the URL of an external key-value store that the metadata of every
+	// committed, finalized and reverted batch is written to after it has been stored locally,
+	// e.g. redis://host:6379/prefix or https://host/path. Records are retried in order until
+	// the store accepts them, also across restarts. Leave empty to disable.
+	MetadataSink string `toml:",omitempty"`
+
+	// L1SlowQueryThreshold is the latency above which requests to the cross-check L1 endpoint
+	// are logged as slow, the requests to the primary endpoints are reported by the L1 client
+	// shared with the L1 message sync. Zero disables the report. It is applied by the caller
+	// connecting CrossCheckL1Client.
+	L1SlowQueryThreshold time.Duration `toml:",omitempty"`
+
+	// ChunkLimits replace the protocol chunk rules, e.g. on test networks with other limits.
+	// Each entry applies to the chunks starting at or after its FromBlock. Defaults to
+	// DefaultChunkRules.
+	ChunkLimits []ChunkLimits `toml:",omitempty"`
+
+	// L1Follower rebuilds the L2 chain from the batch data committed to L1: the blocks of
+	// every committed batch are decoded and executed, and verified by the finalize events.
+	// The L1 messages are taken from the L1 message sync. The derived blocks lack the
+	// signatures of the sequencer, so their hashes differ from the blocks of the sequencer.
+	L1Follower bool `toml:",omitempty"`
+
+	// PayloadCacheDir is the directory of the commit transactions persisted across restarts,
+	// so that batches are not downloaded from L1 again on revalidation or L1 reorgs. The
+	// cache is disabled if the directory or PayloadCacheSize is unset.
+	PayloadCacheDir  string `toml:",omitempty"`
+	PayloadCacheSize uint64 `toml:",omitempty"` // size limit of the cached transactions in bytes
+
+	// BlobSources are the sources of the blobs of the batches committed with blob codecs. If
+	// set, the local blocks of these batches are also checked against their blob on finalization,
+	// and L1-follower mode derives their L2 transactions from the blob. Leave nil to disable,
+	// L1-follower mode then stops at the first batch committed with a blob.
+	BlobSources *da.Config `toml:",omitempty"`
+}
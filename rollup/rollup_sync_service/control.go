@@ -0,0 +1,124 @@
+package rollup_sync_service
+
+import (
+	"fmt"
+	"sync/atomic"
+
+	"github.com/scroll-tech/go-ethereum/core/rawdb"
+	"github.com/scroll-tech/go-ethereum/log"
+)
+
+// Pause stops the ingestion of rollup events until Resume is called.
+// A fetch round that is already in progress is completed.
+func (s *RollupSyncService) Pause() {
+	if atomic.CompareAndSwapInt32(&s.paused, 0, 1) {
+		log.Info("Paused rollup event sync")
+	}
+}
+
+// Resume restarts the ingestion of rollup events after Pause. It also
+// lifts a halt at a poisoned batch, so that syncing continues after the
+// operator investigated the mismatch, and resumes a paused block import.
+func (s *RollupSyncService) Resume() {
+	if atomic.SwapInt32(&s.halted, 0) == 1 {
+		log.Warn("Resumed rollup event sync halted at a poisoned batch", "poisoned batches", rawdb.ReadPoisonedBatchIndices(s.db))
+		if s.verifyMode == VerifyModeHaltImport {
+			s.bc.ResumeImport()
+		}
+	}
+	if atomic.CompareAndSwapInt32(&s.paused, 1, 0) {
+		log.Info("Resumed rollup event sync")
+	}
+}
+
+// ClearPoisonedBatch removes the poisoned marker of a batch after the operator accepted the
+// values finalized on L1, e.g. once the mismatch was explained. The halt at the poisoned
+// batches is lifted once none is left.
+func (s *RollupSyncService) ClearPoisonedBatch(batchIndex uint64) error {
+	if rawdb.ReadPoisonedBatch(s.db, batchIndex) == nil {
+		return fmt.Errorf("batch %d is not poisoned", batchIndex)
+	}
+	rawdb.DeletePoisonedBatch(s.db, batchIndex)
+	poisonedBatchClearedCounter.Inc(1)
+	log.Warn("Cleared poisoned batch", "batch index", batchIndex)
+
+	s.liftPoisonedHalt()
+	return nil
+}
+
+// RetryPoisonedBatch removes the poisoned marker of a batch and rewinds the sync progress to
+// before the L1 block of its finalize event, so that the batch is validated again, e.g. after
+// the local chain was repaired. The halt at the poisoned batches is lifted once none is left.
+func (s *RollupSyncService) RetryPoisonedBatch(batchIndex uint64) error {
+	poisoned := rawdb.ReadPoisonedBatch(s.db, batchIndex)
+	if poisoned == nil {
+		return fmt.Errorf("batch %d is not poisoned", batchIndex)
+	}
+	s.supervisor.supersede()
+	s.syncLock.Lock()
+	// note: a halted sync did not process the L1 block of the finalize event
+	if poisoned.L1BlockNumber > 0 && poisoned.L1BlockNumber <= s.latestProcessedBlock {
+		s.resetTo(poisoned.L1BlockNumber - 1)
+	}
+	rawdb.DeletePoisonedBatch(s.db, batchIndex)
+	s.syncLock.Unlock()
+	poisonedBatchRetriedCounter.Inc(1)
+	log.Warn("Retrying poisoned batch", "batch index", batchIndex, "L1 block", poisoned.L1BlockNumber)
+
+	s.liftPoisonedHalt()
+	return nil
+}
+
+// liftPoisonedHalt lifts a halt at a poisoned batch once all poisoned batches were cleared or
+// retried. In VerifyModeHaltImport the block import is resumed as well.
+func (s *RollupSyncService) liftPoisonedHalt() {
+	if poisoned := rawdb.ReadPoisonedBatchIndices(s.db); len(poisoned) > 0 {
+		log.Warn("Rollup event sync stays halted at poisoned batches", "poisoned batches", poisoned)
+		return
+	}
+	if atomic.SwapInt32(&s.halted, 0) == 1 {
+		log.Info("Lifted the halt of the rollup event sync, no poisoned batches are left")
+	}
+	if s.verifyMode == VerifyModeHaltImport && s.bc.ImportPaused() {
+		s.bc.ResumeImport()
+		log.Info("Resumed block import, no poisoned batches are left")
+	}
+}
+
+// ResetTo rewinds the sync progress to the given L1 block, so that the rollup
+// events after it are fetched and validated again. Stored batch data is kept
+// and overwritten as the events are processed again. A running fetch round is
+// superseded, it stops before processing the next range.
+func (s *RollupSyncService) ResetTo(l1BlockNumber uint64) error {
+	s.supervisor.supersede()
+	s.syncLock.Lock()
+	defer s.syncLock.Unlock()
+
+	if l1BlockNumber > s.latestProcessedBlock {
+		return fmt.Errorf("cannot reset rollup sync forward, latest processed block: %v, requested: %v", s.latestProcessedBlock, l1BlockNumber)
+	}
+	s.resetTo(l1BlockNumber)
+	return nil
+}
+
+// resetTo rewinds the sync progress to the given L1 block. It must be called with s.syncLock held.
+func (s *RollupSyncService) resetTo(l1BlockNumber uint64) {
+	// checkpoints of the ranges that are processed again are written anew
+	for _, checkpoint := range rawdb.ReadRollupSyncCheckpoints(s.db) {
+		if checkpoint.L1BlockNumber > l1BlockNumber {
+			rawdb.DeleteRollupSyncCheckpoint(s.db, checkpoint.L1BlockNumber)
+		}
+	}
+	rawdb.WriteRollupEventSyncedL1BlockNumber(s.db, l1BlockNumber)
+
+	log.Warn("Reset rollup event sync", "from", s.latestProcessedBlock, "to", l1BlockNumber)
+	s.latestProcessedBlock = l1BlockNumber
+	l1ProcessedBlockGauge.Update(int64(l1BlockNumber))
+}
+
+// Sync fetches and processes the rollup events up to the latest confirmed L1 block right
+// away, instead of waiting for the next round of the sync loop. It does nothing while the
+// sync is paused or halted.
+func (s *RollupSyncService) Sync() {
+	s.fetchRollupEvents()
+}
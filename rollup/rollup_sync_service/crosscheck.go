@@ -0,0 +1,61 @@
+package rollup_sync_service
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+
+	"github.com/scroll-tech/go-ethereum/core/types"
+	"github.com/scroll-tech/go-ethereum/log"
+)
+
+// crossCheckRollupEvents fetches the rollup events in [from, to] from the cross-check endpoint
+// and compares them with the logs of the primary endpoint. It fails if the endpoints diverge,
+// so that no batch update is persisted unless both endpoints agree on it.
+func (s *RollupSyncService) crossCheckRollupEvents(ctx context.Context, from, to uint64, logs []types.Log) error {
+	secondary, err := s.crossCheckClient.fetchRollupEventsInRange(ctx, from, to)
+	if err != nil {
+		return err
+	}
+	if err := compareLogs(logs, secondary); err != nil {
+		crossCheckDivergenceCounter.Inc(1)
+		log.Error("L1 endpoints returned diverging rollup events", "from block", from, "to block", to, "primary", len(logs), "secondary", len(secondary), "err", err)
+		return err
+	}
+	return nil
+}
+
+// compareLogs returns an error describing the first difference between the logs a and b.
+func compareLogs(a, b []types.Log) error {
+	if len(a) != len(b) {
+		return fmt.Errorf("log count mismatch: %d != %d", len(a), len(b))
+	}
+	for i := range a {
+		x, y := &a[i], &b[i]
+		switch {
+		case x.BlockNumber != y.BlockNumber || x.BlockHash != y.BlockHash:
+			return fmt.Errorf("log %d: block mismatch: %d (%x) != %d (%x)", i, x.BlockNumber, x.BlockHash, y.BlockNumber, y.BlockHash)
+		case x.TxHash != y.TxHash || x.Index != y.Index:
+			return fmt.Errorf("log %d: position mismatch: %x/%d != %x/%d", i, x.TxHash, x.Index, y.TxHash, y.Index)
+		case x.Address != y.Address:
+			return fmt.Errorf("log %d: address mismatch: %x != %x", i, x.Address, y.Address)
+		case !equalTopics(x, y):
+			return fmt.Errorf("log %d: topics mismatch", i)
+		case !bytes.Equal(x.Data, y.Data):
+			return fmt.Errorf("log %d: data mismatch", i)
+		}
+	}
+	return nil
+}
+
+func equalTopics(a, b *types.Log) bool {
+	if len(a.Topics) != len(b.Topics) {
+		return false
+	}
+	for i := range a.Topics {
+		if a.Topics[i] != b.Topics[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,72 @@
+package rollup_sync_service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/scroll-tech/go-ethereum/common"
+	"github.com/scroll-tech/go-ethereum/core/types"
+)
+
+func TestCrossCheckRollupEvents(t *testing.T) {
+	scrollChainABI, err := scrollChainMetaData.GetAbi()
+	require.NoError(t, err)
+	scrollChainAddress := common.HexToAddress("0x0123456789abcdef")
+	newClient := func(logs []types.Log) *L1Client {
+		client, err := newL1Client(context.Background(), &logsEthClient{logs: logs}, 11155111, scrollChainAddress, scrollChainABI, defaultL1RequestTimeout)
+		require.NoError(t, err)
+		return client
+	}
+	fetch := func(service *RollupSyncService) []*fetchedRange {
+		ranges := make(chan *fetchedRange)
+		go service.fetchRanges(context.Background(), 1, 10, ranges)
+		var fetched []*fetchedRange
+		for r := range ranges {
+			fetched = append(fetched, r)
+		}
+		return fetched
+	}
+
+	logs := []types.Log{{
+		Address:     scrollChainAddress,
+		Topics:      []common.Hash{scrollChainABI.Events["CommitBatch"].ID, common.BigToHash(common.Big1)},
+		BlockNumber: 5,
+		BlockHash:   common.Hash{0x05},
+		TxHash:      common.Hash{0x01},
+	}}
+
+	// matching endpoints
+	fetched := fetch(&RollupSyncService{client: newClient(logs), crossCheckClient: newClient(logs)})
+	require.Len(t, fetched, 1)
+	assert.Equal(t, logs, fetched[0].logs)
+
+	// the range is not processed if the secondary endpoint diverges
+	diverging := []types.Log{logs[0]}
+	diverging[0].BlockHash = common.Hash{0x06}
+	assert.Empty(t, fetch(&RollupSyncService{client: newClient(logs), crossCheckClient: newClient(diverging)}))
+	assert.Empty(t, fetch(&RollupSyncService{client: newClient(logs), crossCheckClient: newClient(nil)}))
+}
+
+func TestCompareLogs(t *testing.T) {
+	base := types.Log{Topics: []common.Hash{{0x01}}, Data: []byte{0x01}, BlockNumber: 1}
+	assert.NoError(t, compareLogs(nil, []types.Log{}))
+	assert.NoError(t, compareLogs([]types.Log{base}, []types.Log{base}))
+
+	for _, modify := range []func(l *types.Log){
+		func(l *types.Log) { l.BlockNumber = 2 },
+		func(l *types.Log) { l.TxHash = common.Hash{0x02} },
+		func(l *types.Log) { l.Index = 1 },
+		func(l *types.Log) { l.Address = common.Address{0x01} },
+		func(l *types.Log) { l.Topics = []common.Hash{{0x02}} },
+		func(l *types.Log) { l.Topics = nil },
+		func(l *types.Log) { l.Data = []byte{0x02} },
+	} {
+		other := base
+		modify(&other)
+		assert.Error(t, compareLogs([]types.Log{base}, []types.Log{other}))
+	}
+	assert.Error(t, compareLogs([]types.Log{base}, nil))
+}
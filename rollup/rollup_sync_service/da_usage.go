@@ -0,0 +1,157 @@
+package rollup_sync_service
+
+import (
+	"fmt"
+
+	"github.com/scroll-tech/go-ethereum/common"
+	"github.com/scroll-tech/go-ethereum/core/rawdb"
+	"github.com/scroll-tech/go-ethereum/ethdb"
+)
+
+// maxDAUsageBlocks bounds the number of pending blocks accounted by DAUsage.
+const maxDAUsageBlocks = 10000
+
+// CodecDAUsage is the space the pending blocks would occupy on L1 if committed with one codec.
+// The blocks are packed greedily into chunks and batches within the limits of the codec.
+type CodecDAUsage struct {
+	Version         uint8    `json:"version"`
+	Limits          DALimits `json:"limits"`
+	Bytes           uint64   `json:"bytes"`           // encoded chunk bytes of all pending blocks
+	Chunks          uint64   `json:"chunks"`          // number of chunks needed for the pending blocks
+	Batches         uint64   `json:"batches"`         // number of batches needed for the pending blocks
+	OpenChunkBlocks uint64   `json:"openChunkBlocks"` // blocks in the last chunk
+	OpenBatchChunks uint64   `json:"openBatchChunks"` // chunks in the last batch
+	OpenBatchBytes  uint64   `json:"openBatchBytes"`  // encoded chunk bytes of the last batch
+	ChunkUsage      float64  `json:"chunkUsage"`      // fraction of the block limit used by the last chunk
+	BatchUsage      float64  `json:"batchUsage"`      // fraction of the chunk or byte limit used by the last batch, whichever is higher
+}
+
+// DAUsage describes the L2 blocks that have not been committed to L1 yet.
+type DAUsage struct {
+	LastCommittedBatchIndex *uint64        `json:"lastCommittedBatchIndex,omitempty"`
+	FromBlockNumber         uint64         `json:"fromBlockNumber"`
+	ToBlockNumber           uint64         `json:"toBlockNumber"`
+	PendingBlocks           uint64         `json:"pendingBlocks"`
+	Truncated               bool           `json:"truncated"` // only the first maxDAUsageBlocks pending blocks were accounted
+	Codecs                  []CodecDAUsage `json:"codecs"`
+}
+
+// lastCommittedBatch returns the index and the last L2 block number of the highest committed batch.
+func (s *RollupSyncService) lastCommittedBatch() (batchIndex uint64, endBlockNumber uint64, ok bool) {
+	return readLastCommittedBatch(s.db)
+}
+
+// readLastCommittedBatch returns the index and the last L2 block number of the highest committed batch in db.
+func readLastCommittedBatch(db ethdb.Database) (batchIndex uint64, endBlockNumber uint64, ok bool) {
+	var next uint64
+	if finalizedL2BlockNumber := rawdb.ReadFinalizedL2BlockNumber(db); finalizedL2BlockNumber != nil {
+		next = *finalizedL2BlockNumber
+	}
+	for {
+		index := rawdb.ReadBatchIndexByL2BlockNumber(db, next)
+		if index == nil {
+			return
+		}
+		chunkBlockRanges := rawdb.ReadBatchChunkRanges(db, *index)
+		if len(chunkBlockRanges) == 0 {
+			return
+		}
+		batchIndex, endBlockNumber, ok = *index, chunkBlockRanges[len(chunkBlockRanges)-1].EndBlockNumber, true
+		next = endBlockNumber + 1
+	}
+}
+
+// DAUsage returns the number of bytes the local blocks after the last committed batch would
+// occupy on L1 for every registered codec, and how close they are to the chunk and batch limits.
+func (s *RollupSyncService) DAUsage() (*DAUsage, error) {
+	usage := &DAUsage{ToBlockNumber: s.bc.CurrentBlock().NumberU64(), Codecs: []CodecDAUsage{}}
+	if batchIndex, endBlockNumber, ok := s.lastCommittedBatch(); ok {
+		usage.LastCommittedBatchIndex = &batchIndex
+		usage.FromBlockNumber = endBlockNumber + 1
+	}
+	if usage.FromBlockNumber > usage.ToBlockNumber {
+		usage.ToBlockNumber = usage.FromBlockNumber - 1
+		return usage, nil
+	}
+	usage.PendingBlocks = usage.ToBlockNumber - usage.FromBlockNumber + 1
+
+	last := usage.ToBlockNumber
+	if usage.PendingBlocks > maxDAUsageBlocks {
+		usage.Truncated = true
+		last = usage.FromBlockNumber + maxDAUsageBlocks - 1
+	}
+	blocks := make([]*WrappedBlock, 0, last-usage.FromBlockNumber+1)
+	for number := usage.FromBlockNumber; number <= last; number++ {
+		block := s.bc.GetBlockByNumber(number)
+		if block == nil {
+			return nil, fmt.Errorf("failed to get block by number: %v", number)
+		}
+		blocks = append(blocks, NewWrappedBlock(block, common.Hash{}))
+	}
+
+	totalL1MessagePoppedBefore := s.getTotalL1MessagePoppedBefore(usage.FromBlockNumber)
+	for _, codec := range registeredCodecs() {
+		codecUsage, err := codecDAUsage(codec, s.chunkRules, totalL1MessagePoppedBefore, blocks)
+		if err != nil {
+			return nil, fmt.Errorf("failed to compute DA usage of codec version %v: %w", codec.Version(), err)
+		}
+		usage.Codecs = append(usage.Codecs, *codecUsage)
+	}
+	return usage, nil
+}
+
+// codecDAUsage packs the blocks greedily into chunks and batches of the codec. A chunk
+// is closed before a block that would make it violate the limits of the codec or the
+// chunk rules, totalL1MessagePoppedBefore is the number of L1 messages before the blocks.
+func codecDAUsage(codec Codec, rules ChunkRules, totalL1MessagePoppedBefore uint64, blocks []*WrappedBlock) (*CodecDAUsage, error) {
+	limits := codec.DALimits()
+	usage := &CodecDAUsage{Version: codec.Version(), Limits: limits}
+	var (
+		openChunk      ChunkStats // stats of the last chunk
+		openChunkFirst uint64     // first block of the last chunk
+	)
+	for _, block := range blocks {
+		size, err := codec.EncodedBlockSize(block)
+		if err != nil {
+			return nil, err
+		}
+		numL1Messages := block.numL1Messages(totalL1MessagePoppedBefore)
+		totalL1MessagePoppedBefore += numL1Messages
+
+		extended := ChunkStats{Blocks: openChunk.Blocks + 1, L1Messages: openChunk.L1Messages + numL1Messages, PayloadBytes: openChunk.PayloadBytes + size}
+		if usage.Chunks == 0 || usage.OpenChunkBlocks == limits.MaxBlocksPerChunk || usage.OpenBatchBytes+size > limits.MaxBatchBytes || rules.Check(openChunkFirst, extended) != nil {
+			if usage.Batches == 0 || usage.OpenBatchChunks == limits.MaxChunksPerBatch || usage.OpenBatchBytes+limits.ChunkOverheadBytes+size > limits.MaxBatchBytes {
+				usage.Batches++
+				usage.OpenBatchChunks, usage.OpenBatchBytes = 0, 0
+			}
+			usage.Chunks++
+			usage.OpenChunkBlocks = 0
+			usage.OpenBatchChunks++
+			usage.OpenBatchBytes += limits.ChunkOverheadBytes
+			usage.Bytes += limits.ChunkOverheadBytes
+			openChunk = ChunkStats{PayloadBytes: limits.ChunkOverheadBytes}
+			if len(rules) > 0 {
+				openChunkFirst = block.Header.Number.Uint64()
+			}
+		}
+		usage.OpenChunkBlocks++
+		usage.OpenBatchBytes += size
+		usage.Bytes += size
+		openChunk.Blocks++
+		openChunk.L1Messages += numL1Messages
+		openChunk.PayloadBytes += size
+	}
+
+	if limits.MaxBlocksPerChunk > 0 {
+		usage.ChunkUsage = float64(usage.OpenChunkBlocks) / float64(limits.MaxBlocksPerChunk)
+	}
+	if limits.MaxChunksPerBatch > 0 {
+		usage.BatchUsage = float64(usage.OpenBatchChunks) / float64(limits.MaxChunksPerBatch)
+	}
+	if limits.MaxBatchBytes > 0 {
+		if byteUsage := float64(usage.OpenBatchBytes) / float64(limits.MaxBatchBytes); byteUsage > usage.BatchUsage {
+			usage.BatchUsage = byteUsage
+		}
+	}
+	return usage, nil
+}
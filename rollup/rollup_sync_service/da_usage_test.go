@@ -0,0 +1,103 @@
+package rollup_sync_service
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/scroll-tech/go-ethereum/consensus/ethash"
+	"github.com/scroll-tech/go-ethereum/core"
+	"github.com/scroll-tech/go-ethereum/core/rawdb"
+	"github.com/scroll-tech/go-ethereum/core/types"
+	"github.com/scroll-tech/go-ethereum/core/vm"
+	"github.com/scroll-tech/go-ethereum/params"
+)
+
+func TestCodecDAUsage(t *testing.T) {
+	blocks := make([]*WrappedBlock, 256)
+	for i := range blocks {
+		blocks[i] = &WrappedBlock{Header: &types.Header{}}
+	}
+	usage, err := codecDAUsage(codecV0{}, nil, 0, blocks)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(2), usage.Chunks)
+	assert.Equal(t, uint64(1), usage.Batches)
+	assert.Equal(t, uint64(2+256*blockContextByteSize), usage.Bytes)
+	assert.Equal(t, uint64(1), usage.OpenChunkBlocks)
+	assert.Equal(t, uint64(2), usage.OpenBatchChunks)
+	assert.Equal(t, usage.Bytes, usage.OpenBatchBytes)
+	assert.InDelta(t, 1.0/255, usage.ChunkUsage, 1e-9)
+	assert.InDelta(t, 2.0/15, usage.BatchUsage, 1e-9)
+
+	// exceeding the chunk limit of a batch starts a new batch
+	usage, err = codecDAUsage(limitedCodec{limits: DALimits{MaxBlocksPerChunk: 2, MaxChunksPerBatch: 2, MaxBatchBytes: 1000, ChunkOverheadBytes: 1}}, nil, 0, blocks[:10])
+	require.NoError(t, err)
+	assert.Equal(t, uint64(5), usage.Chunks)
+	assert.Equal(t, uint64(3), usage.Batches)
+	assert.Equal(t, uint64(1), usage.OpenBatchChunks)
+	assert.Equal(t, uint64(1+2*blockContextByteSize), usage.OpenBatchBytes)
+
+	// exceeding the byte limit of a batch starts a new batch
+	usage, err = codecDAUsage(limitedCodec{limits: DALimits{MaxBlocksPerChunk: 10, MaxChunksPerBatch: 10, MaxBatchBytes: 200, ChunkOverheadBytes: 1}}, nil, 0, blocks[:5])
+	require.NoError(t, err)
+	assert.Equal(t, uint64(2), usage.Chunks)
+	assert.Equal(t, uint64(2), usage.Batches)
+	assert.Equal(t, uint64(2), usage.OpenChunkBlocks)
+	assert.Equal(t, uint64(1+2*blockContextByteSize), usage.OpenBatchBytes)
+	assert.InDelta(t, float64(1+2*blockContextByteSize)/200, usage.BatchUsage, 1e-9)
+}
+
+type limitedCodec struct {
+	codecV0
+	limits DALimits
+}
+
+func (c limitedCodec) DALimits() DALimits { return c.limits }
+
+func TestDAUsage(t *testing.T) {
+	db := rawdb.NewMemoryDatabase()
+	genesis := (&core.Genesis{Config: params.TestChainConfig}).MustCommit(db)
+	blocks, _ := core.GenerateChain(params.TestChainConfig, genesis, ethash.NewFaker(), rawdb.NewMemoryDatabase(), 5, nil)
+	bc, err := core.NewBlockChain(db, nil, params.TestChainConfig, ethash.NewFaker(), vm.Config{}, nil, nil)
+	require.NoError(t, err)
+	defer bc.Stop()
+	_, err = bc.InsertChain(blocks)
+	require.NoError(t, err)
+
+	service := &RollupSyncService{db: db, bc: bc}
+	usage, err := service.DAUsage()
+	require.NoError(t, err)
+	assert.Nil(t, usage.LastCommittedBatchIndex)
+	assert.Equal(t, uint64(6), usage.PendingBlocks)
+
+	// batch 0 is finalized, batch 1 is committed
+	rawdb.WriteBatchChunkRanges(db, 0, []*rawdb.ChunkBlockRange{{StartBlockNumber: 0, EndBlockNumber: 0}})
+	rawdb.WriteBatchEndBlock(db, 0, 0)
+	rawdb.WriteFinalizedL2BlockNumber(db, 0)
+	rawdb.WriteBatchChunkRanges(db, 1, []*rawdb.ChunkBlockRange{{StartBlockNumber: 1, EndBlockNumber: 1}, {StartBlockNumber: 2, EndBlockNumber: 3}})
+	rawdb.WriteBatchEndBlock(db, 3, 1)
+
+	usage, err = service.DAUsage()
+	require.NoError(t, err)
+	require.NotNil(t, usage.LastCommittedBatchIndex)
+	assert.Equal(t, uint64(1), *usage.LastCommittedBatchIndex)
+	assert.Equal(t, uint64(4), usage.FromBlockNumber)
+	assert.Equal(t, uint64(5), usage.ToBlockNumber)
+	assert.Equal(t, uint64(2), usage.PendingBlocks)
+	assert.False(t, usage.Truncated)
+	require.Len(t, usage.Codecs, len(CodecVersions()))
+	assert.Equal(t, uint64(1+2*blockContextByteSize), usage.Codecs[0].Bytes)
+	assert.Equal(t, uint64(1), usage.Codecs[0].Chunks)
+	// blob codecs only count the L2 transactions stored in the blob
+	assert.Equal(t, uint64(0), usage.Codecs[1].Bytes)
+	assert.Equal(t, uint64(1), usage.Codecs[1].Chunks)
+
+	// all blocks committed
+	rawdb.WriteBatchChunkRanges(db, 2, []*rawdb.ChunkBlockRange{{StartBlockNumber: 4, EndBlockNumber: 5}})
+	rawdb.WriteBatchEndBlock(db, 5, 2)
+	usage, err = service.DAUsage()
+	require.NoError(t, err)
+	assert.Equal(t, uint64(0), usage.PendingBlocks)
+	assert.Empty(t, usage.Codecs)
+}
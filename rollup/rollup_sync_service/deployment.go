@@ -0,0 +1,45 @@
+package rollup_sync_service
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/scroll-tech/go-ethereum/common"
+	"github.com/scroll-tech/go-ethereum/core/rawdb"
+	"github.com/scroll-tech/go-ethereum/ethdb"
+	"github.com/scroll-tech/go-ethereum/log"
+)
+
+// ErrScrollChainChanged is returned if the configured ScrollChain address differs from the
+// address the stored batches were synced from. The batches of two deployments do not form one
+// batch chain, the stored data is migrated or deleted with geth rollup migrate-scrollchain.
+var ErrScrollChainChanged = errors.New("configured ScrollChain address differs from the address of the stored batches")
+
+// checkScrollChainAddress records the configured ScrollChain address on the first start and
+// refuses to sync the events of another address into the stored batches afterwards. Databases
+// synced before the address was recorded are assumed to match the configured address.
+func checkScrollChainAddress(db ethdb.Database, address common.Address) error {
+	stored := rawdb.ReadRollupScrollChainAddress(db)
+	if stored == nil {
+		rawdb.WriteRollupScrollChainAddress(db, address)
+		return nil
+	}
+	if *stored != address {
+		return fmt.Errorf("%w: stored %v, configured %v, run geth rollup migrate-scrollchain to adopt the new deployment or to sync it anew", ErrScrollChainChanged, stored.Hex(), address.Hex())
+	}
+	return nil
+}
+
+// MigrateScrollChain records address as the ScrollChain deployment of the stored batches.
+// If resync is set, the rollup event store is deleted first, so that the batches of the new
+// deployment are synced from its deployment block. Otherwise the stored batches are kept, for
+// a deployment that continues the batch chain of the previous one. The node must not be running.
+func MigrateScrollChain(db ethdb.Database, address common.Address, resync bool) {
+	previous := rawdb.ReadRollupScrollChainAddress(db)
+	if resync {
+		deleted := rawdb.DeleteRollupEventStore(db)
+		log.Warn("Deleted rollup event store", "keys", deleted)
+	}
+	rawdb.WriteRollupScrollChainAddress(db, address)
+	log.Info("Migrated rollup data to ScrollChain deployment", "previous", previous, "address", address, "resync", resync)
+}
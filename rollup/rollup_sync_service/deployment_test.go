@@ -0,0 +1,56 @@
+package rollup_sync_service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/scroll-tech/go-ethereum/common"
+	"github.com/scroll-tech/go-ethereum/core/rawdb"
+	"github.com/scroll-tech/go-ethereum/params"
+)
+
+func TestScrollChainAddressGuard(t *testing.T) {
+	genesisConfig := func(address common.Address) *params.ChainConfig {
+		return &params.ChainConfig{
+			Scroll: params.ScrollConfig{
+				L1Config: &params.L1Config{L1ChainId: 11155111, ScrollChainAddress: address},
+			},
+		}
+	}
+	oldAddress := common.HexToAddress("0x2D567EcE699Eabe5afCd141eDB7A4f2D0D6ce8a0")
+	newAddress := common.HexToAddress("0x01")
+	db := rawdb.NewMemoryDatabase()
+	bc := newTestBlockChain(t, db)
+
+	// the address is recorded on the first start
+	_, err := NewRollupSyncService(context.Background(), genesisConfig(oldAddress), db, &mockEthClient{}, bc, 1, &Config{VerifyMode: VerifyModeHaltSync}, nil)
+	require.NoError(t, err)
+	assert.Equal(t, oldAddress, *rawdb.ReadRollupScrollChainAddress(db))
+	rawdb.WriteBatchChunkRanges(db, 1, []*rawdb.ChunkBlockRange{{StartBlockNumber: 1, EndBlockNumber: 5}})
+	rawdb.WriteRollupEventSyncedL1BlockNumber(db, 100)
+
+	_, err = NewRollupSyncService(context.Background(), genesisConfig(oldAddress), db, &mockEthClient{}, bc, 1, &Config{VerifyMode: VerifyModeHaltSync}, nil)
+	require.NoError(t, err)
+
+	// the events of another deployment are not mixed into the stored batches
+	_, err = NewRollupSyncService(context.Background(), genesisConfig(newAddress), db, &mockEthClient{}, bc, 1, &Config{VerifyMode: VerifyModeHaltSync}, nil)
+	assert.ErrorIs(t, err, ErrScrollChainChanged)
+
+	// a migration keeping the stored batches adopts the new deployment
+	MigrateScrollChain(db, newAddress, false)
+	_, err = NewRollupSyncService(context.Background(), genesisConfig(newAddress), db, &mockEthClient{}, bc, 1, &Config{VerifyMode: VerifyModeHaltSync}, nil)
+	require.NoError(t, err)
+	assert.NotNil(t, rawdb.ReadBatchChunkRanges(db, 1))
+
+	// a resync deletes the stored batches
+	MigrateScrollChain(db, oldAddress, true)
+	assert.Equal(t, oldAddress, *rawdb.ReadRollupScrollChainAddress(db))
+	assert.Nil(t, rawdb.ReadBatchChunkRanges(db, 1))
+	assert.Nil(t, rawdb.ReadRollupEventSyncedL1BlockNumber(db))
+	service, err := NewRollupSyncService(context.Background(), genesisConfig(oldAddress), db, &mockEthClient{}, bc, 1, &Config{VerifyMode: VerifyModeHaltSync}, nil)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(0), service.latestProcessedBlock)
+}
@@ -0,0 +1,209 @@
+package rollup_sync_service
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math/big"
+
+	"github.com/scroll-tech/go-ethereum/common"
+	"github.com/scroll-tech/go-ethereum/consensus/misc"
+	"github.com/scroll-tech/go-ethereum/core"
+	"github.com/scroll-tech/go-ethereum/core/rawdb"
+	"github.com/scroll-tech/go-ethereum/core/types"
+	"github.com/scroll-tech/go-ethereum/log"
+	"github.com/scroll-tech/go-ethereum/params"
+)
+
+// deriveBatch builds the L2 blocks of a committed batch from its commit calldata, and its blob
+// for blob codecs, and appends them to the local chain, it is used in L1-follower mode. Blocks that exist locally are
+// checked against their committed block contexts instead. The included L1 messages are read
+// from the L1 message sync, the derivation is retried in the next round if they are missing.
+// The derived blocks are verified when the batch is finalized, like the blocks of any node.
+func (s *RollupSyncService) deriveBatch(batchIndex uint64, vLog *types.Log, batch *committedBatch) error {
+	calldata, codec, chunkBlockContexts := batch.calldata, batch.codec, batch.chunkBlockContexts
+	var (
+		chunkTxs [][]types.Transactions
+		err      error
+	)
+	if blobCodec, ok := codec.(BlobCodec); ok {
+		// the L2 transactions of the batch are stored in its blob
+		if len(calldata.BlobVersionedHashes) != 1 {
+			return fmt.Errorf("commit transaction of batch %d has %d blobs, expected 1", batchIndex, len(calldata.BlobVersionedHashes))
+		}
+		blob, err := s.fetchBlob(calldata.BlobVersionedHashes[0], vLog.BlockNumber)
+		if err != nil {
+			return fmt.Errorf("batch %d: %w", batchIndex, err)
+		}
+		chunkTxs, err = blobCodec.DecodeBlobL2Transactions(blob, chunkBlockContexts)
+		if err != nil {
+			return fmt.Errorf("failed to decode L2 transactions from blob: %w", err)
+		}
+	} else {
+		chunkTxs, err = codec.DecodeChunkL2Transactions(calldata.Chunks)
+		if err != nil {
+			return fmt.Errorf("failed to decode L2 transactions: %w", err)
+		}
+	}
+	skipped, err := skippedL1Messages(calldata, chunkBlockContexts)
+	if err != nil {
+		return fmt.Errorf("failed to decode skipped L1 messages: %w", err)
+	}
+	skippedSet := make(map[uint64]struct{}, len(skipped))
+	for _, queueIndex := range skipped {
+		skippedSet[queueIndex] = struct{}{}
+	}
+
+	// the length of the parent batch header is checked by skippedL1Messages
+	nextQueueIndex := binary.BigEndian.Uint64(calldata.ParentBatchHeader[17:25])
+	var derived int
+	for i, blockContexts := range chunkBlockContexts {
+		for j, blockContext := range blockContexts {
+			queueIndex := nextQueueIndex
+			nextQueueIndex += uint64(blockContext.NumL1Messages)
+
+			head := s.bc.CurrentBlock().NumberU64()
+			if blockContext.BlockNumber <= head {
+				header := s.bc.GetHeaderByNumber(blockContext.BlockNumber)
+				if header == nil {
+					return fmt.Errorf("local block %d not found", blockContext.BlockNumber)
+				}
+				if err := compareBlockContext(blockContext, header); err != nil {
+					return fmt.Errorf("local block %d differs from the committed block: %v", blockContext.BlockNumber, err)
+				}
+				continue
+			}
+			if blockContext.BlockNumber != head+1 {
+				return fmt.Errorf("committed block %d does not extend the local head %d", blockContext.BlockNumber, head)
+			}
+			l1Messages, err := s.readBlockL1Messages(queueIndex, uint64(blockContext.NumL1Messages), skippedSet)
+			if err != nil {
+				return fmt.Errorf("block %d: %w", blockContext.BlockNumber, err)
+			}
+			txs := append(l1Messages, chunkTxs[i][j]...)
+			if err := s.deriveBlock(blockContext, txs, nextQueueIndex); err != nil {
+				return fmt.Errorf("failed to derive block %d: %w", blockContext.BlockNumber, err)
+			}
+			derived++
+		}
+	}
+	if derived > 0 {
+		log.Info("Derived blocks from committed batch", "batch index", batchIndex, "blocks", derived, "head", s.bc.CurrentBlock().NumberU64())
+	}
+	return nil
+}
+
+// readBlockL1Messages reads the L1 messages popped by a block, starting at the given queue
+// index. The skipped messages are popped but not included.
+func (s *RollupSyncService) readBlockL1Messages(queueIndex, count uint64, skipped map[uint64]struct{}) (types.Transactions, error) {
+	var txs types.Transactions
+	for index := queueIndex; index < queueIndex+count; index++ {
+		if _, ok := skipped[index]; ok {
+			continue
+		}
+		msg := rawdb.ReadL1Message(s.db, index)
+		if msg == nil {
+			return nil, fmt.Errorf("L1 message %d not synced yet", index)
+		}
+		txs = append(txs, types.NewTx(msg))
+	}
+	return txs, nil
+}
+
+// deriveBlock executes the transactions of a committed block on top of the local head and
+// writes the resulting block. nextQueueIndex is the first L1 message not popped by the block.
+func (s *RollupSyncService) deriveBlock(blockContext *BlockContext, txs types.Transactions, nextQueueIndex uint64) error {
+	parent := s.bc.CurrentBlock()
+	config := s.bc.Config()
+	header := &types.Header{
+		ParentHash: parent.Hash(),
+		Number:     new(big.Int).SetUint64(blockContext.BlockNumber),
+		GasLimit:   blockContext.GasLimit,
+		Time:       blockContext.Timestamp,
+		Difficulty: common.Big1,
+		Extra:      []byte{},
+	}
+	baseFee, err := committedBaseFee(config, parent.Header(), header.Number, blockContext)
+	if err != nil {
+		return err
+	}
+	header.BaseFee = baseFee
+
+	statedb, err := s.bc.StateForChild(parent.Header())
+	if err != nil {
+		return err
+	}
+	var (
+		gasPool  = new(core.GasPool).AddGas(header.GasLimit)
+		receipts = make([]*types.Receipt, len(txs))
+	)
+	for i, tx := range txs {
+		statedb.Prepare(tx.Hash(), i)
+		receipt, err := core.ApplyTransaction(config, s.bc, &header.Coinbase, gasPool, statedb, header, tx, &header.GasUsed, *s.bc.GetVMConfig())
+		if err != nil {
+			return fmt.Errorf("failed to apply transaction %d (%v): %w", i, tx.Hash().Hex(), err)
+		}
+		receipts[i] = receipt
+	}
+	block, err := s.bc.Engine().FinalizeAndAssemble(s.bc, header, statedb, txs, nil, receipts)
+	if err != nil {
+		return err
+	}
+	if err := compareBlockContext(blockContext, block.Header()); err != nil {
+		return fmt.Errorf("derived block differs from the committed block: %v", err)
+	}
+
+	// the receipts and logs were created before the block hash was known
+	hash := block.Hash()
+	var logs []*types.Log
+	for _, receipt := range receipts {
+		receipt.BlockHash = hash
+		for _, l := range receipt.Logs {
+			l.BlockHash = hash
+		}
+		logs = append(logs, receipt.Logs...)
+	}
+	rawdb.WriteFirstQueueIndexNotInL2Block(s.db, hash, nextQueueIndex)
+	if _, err := s.bc.WriteBlockWithState(block, receipts, logs, statedb, true); err != nil {
+		return err
+	}
+	return nil
+}
+
+// committedBaseFee returns the base fee of a derived block, which is the one of its committed
+// block context. The base fee is set by the sequencer, so it is only checked to be absent when
+// the base fee is disabled at the block. Codecs that do not commit the base fee decode it as
+// nil, it is computed from the parent then.
+func committedBaseFee(config *params.ChainConfig, parent *types.Header, number *big.Int, blockContext *BlockContext) (*big.Int, error) {
+	if !config.IsLondon(number) || !config.Scroll.BaseFeeEnabled() {
+		if blockContext.BaseFee != nil && blockContext.BaseFee.Sign() != 0 {
+			return nil, fmt.Errorf("committed base fee %v, but the base fee is disabled", blockContext.BaseFee)
+		}
+		return nil, nil
+	}
+	if blockContext.BaseFee == nil {
+		return misc.CalcBaseFee(config, parent), nil
+	}
+	if blockContext.BaseFee.Sign() < 0 || blockContext.BaseFee.BitLen() > 256 {
+		return nil, fmt.Errorf("invalid committed base fee %v", blockContext.BaseFee)
+	}
+	return new(big.Int).Set(blockContext.BaseFee), nil
+}
+
+// rewindDerivedBlocks removes the derived blocks of the given batch and all blocks above it
+// from the local chain, it is called in L1-follower mode before the batch is reverted.
+func (s *RollupSyncService) rewindDerivedBlocks(batchIndex uint64) error {
+	chunkBlockRanges := s.readBatchChunkRanges(batchIndex)
+	if len(chunkBlockRanges) == 0 {
+		return nil
+	}
+	start := chunkBlockRanges[0].StartBlockNumber
+	head := s.bc.CurrentBlock().NumberU64()
+	if start == 0 || head < start {
+		return nil
+	}
+	if err := s.bc.SetHead(start - 1); err != nil {
+		return fmt.Errorf("failed to rewind the derived blocks of batch %d: %w", batchIndex, err)
+	}
+	log.Info("Rewound derived blocks of reverted batch", "batch index", batchIndex, "from", head, "to", start-1)
+	return nil
+}
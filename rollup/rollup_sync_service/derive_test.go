@@ -0,0 +1,90 @@
+package rollup_sync_service
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/scroll-tech/go-ethereum/common"
+	"github.com/scroll-tech/go-ethereum/consensus/misc"
+	"github.com/scroll-tech/go-ethereum/core/types"
+	"github.com/scroll-tech/go-ethereum/crypto"
+	"github.com/scroll-tech/go-ethereum/params"
+)
+
+func TestDecodeChunkL2Transactions(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+	signer := types.HomesteadSigner{}
+	to := common.Address{0x01}
+	newTx := func(nonce uint64) *types.Transaction {
+		return types.MustSignNewTx(key, signer, &types.LegacyTx{Nonce: nonce, To: &to, Gas: 21000, GasPrice: big.NewInt(1), Data: []byte{byte(nonce)}})
+	}
+	l1Message := types.NewTx(&types.L1MessageTx{QueueIndex: 5, To: &to, Gas: 100000})
+
+	// the first block includes an L1 message, the second one has no transactions
+	block1 := types.Transactions{l1Message, newTx(0), newTx(1)}
+	block3 := types.Transactions{newTx(2)}
+	chunks := make([][]byte, 2)
+	var err error
+	chunks[0], err = (&Chunk{Blocks: []*WrappedBlock{
+		{Header: &types.Header{Number: big.NewInt(1)}, Transactions: txsToTxsData(block1)},
+		{Header: &types.Header{Number: big.NewInt(2)}, Transactions: txsToTxsData(nil)},
+	}}).Encode(5)
+	require.NoError(t, err)
+	chunks[1], err = (&Chunk{Blocks: []*WrappedBlock{
+		{Header: &types.Header{Number: big.NewInt(3)}, Transactions: txsToTxsData(block3)},
+	}}).Encode(6)
+	require.NoError(t, err)
+
+	chunkTxs, err := DecodeChunkL2Transactions(chunks)
+	require.NoError(t, err)
+	require.Len(t, chunkTxs, 2)
+	require.Len(t, chunkTxs[0], 2)
+	require.Len(t, chunkTxs[1], 1)
+	hashes := func(txs types.Transactions) []common.Hash {
+		result := make([]common.Hash, len(txs))
+		for i, tx := range txs {
+			result[i] = tx.Hash()
+		}
+		return result
+	}
+	assert.Equal(t, hashes(block1[1:]), hashes(chunkTxs[0][0]))
+	assert.Empty(t, chunkTxs[0][1])
+	assert.Equal(t, hashes(block3), hashes(chunkTxs[1][0]))
+
+	// truncated and padded chunks are rejected
+	_, err = DecodeChunkL2Transactions([][]byte{chunks[0][:len(chunks[0])-1]})
+	assert.Error(t, err)
+	_, err = DecodeChunkL2Transactions([][]byte{append(chunks[1], 0)})
+	assert.Error(t, err)
+}
+
+func TestCommittedBaseFee(t *testing.T) {
+	enabled := *params.TestChainConfig
+	enabled.Scroll.EnableEIP2718, enabled.Scroll.EnableEIP1559 = true, true
+	disabled := enabled
+	disabled.Scroll.EnableEIP1559 = false
+	number := big.NewInt(10)
+	parent := &types.Header{Number: big.NewInt(9), GasLimit: 10000000, GasUsed: 5000000, BaseFee: big.NewInt(1000)}
+
+	// the committed base fee is used as is, not recomputed from the parent
+	baseFee, err := committedBaseFee(&enabled, parent, number, &BlockContext{BaseFee: big.NewInt(123)})
+	require.NoError(t, err)
+	assert.Equal(t, big.NewInt(123), baseFee)
+
+	// the base fee is computed from the parent if the codec does not commit it
+	baseFee, err = committedBaseFee(&enabled, parent, number, &BlockContext{})
+	require.NoError(t, err)
+	assert.Equal(t, misc.CalcBaseFee(&enabled, parent), baseFee)
+
+	_, err = committedBaseFee(&enabled, parent, number, &BlockContext{BaseFee: big.NewInt(-1)})
+	assert.Error(t, err)
+
+	baseFee, err = committedBaseFee(&disabled, parent, number, &BlockContext{BaseFee: common.Big0})
+	require.NoError(t, err)
+	assert.Nil(t, baseFee)
+	_, err = committedBaseFee(&disabled, parent, number, &BlockContext{BaseFee: big.NewInt(123)})
+	assert.Error(t, err)
+}
@@ -0,0 +1,68 @@
+package rollup_sync_service
+
+import (
+	"fmt"
+
+	"github.com/scroll-tech/go-ethereum/core/rawdb"
+	"github.com/scroll-tech/go-ethereum/core/types"
+	"github.com/scroll-tech/go-ethereum/log"
+
+	"github.com/scroll-tech/go-ethereum/rollup/eventbus"
+)
+
+// enforcedBatchModeReason is the revert reason of the batches unwound when the enforced batch mode is enabled.
+const enforcedBatchModeReason = "enforced batch mode enabled"
+
+// handleUpdateEnforcedBatchMode handles an UpdateEnforcedBatchMode event. ScrollChain enables the
+// enforced batch mode if the sequencer stops finalizing batches, anyone can then submit batches with
+// commitAndFinalizeBatch. Enabling the mode reverts all unfinalized batches without RevertBatch events,
+// so the batches above the last committed batch index of the event are unwound here. The commit and
+// finalize events of enforced batches are validated like those of regular batches.
+func (s *RollupSyncService) handleUpdateEnforcedBatchMode(vLog *types.Log) error {
+	event := &L1UpdateEnforcedBatchModeEvent{}
+	if err := UnpackLog(s.scrollChainABI, event, "UpdateEnforcedBatchMode", *vLog); err != nil {
+		return fmt.Errorf("failed to unpack enforced batch mode rollup event log, err: %w", err)
+	}
+	lastCommittedBatchIndex := event.LastCommittedBatchIndex.Uint64()
+	log.Trace("found new UpdateEnforcedBatchMode event", "enabled", event.Enabled, "last committed batch index", lastCommittedBatchIndex)
+
+	if event.Enabled {
+		indices := rawdb.ReadCommittedBatchIndices(s.db, lastCommittedBatchIndex+1)
+		for _, index := range indices {
+			if s.readFinalizedBatchMeta(index) != nil {
+				return fmt.Errorf("fatal: enabling the enforced batch mode at batch %d reverts finalized batch %d", lastCommittedBatchIndex, index)
+			}
+		}
+		s.unwindBatches(indices, vLog, func(uint64) *rawdb.RevertedBatch {
+			return &rawdb.RevertedBatch{Reason: enforcedBatchModeReason}
+		})
+		for _, index := range indices {
+			s.bus.PublishBatchReverted(eventbus.BatchRevertedEvent{
+				BatchIndex:    index,
+				L1BlockNumber: vLog.BlockNumber,
+				L1TxHash:      vLog.TxHash,
+			})
+			if err := runBatchHooks("revert", index, func(hook BatchHook) error {
+				return hook.OnBatchReverted(&BatchRevertInfo{BatchIndex: index, L1Log: vLog})
+			}); err != nil {
+				return err
+			}
+		}
+		log.Warn("Enforced batch mode enabled", "last committed batch index", lastCommittedBatchIndex, "reverted", len(indices), "L1 block", vLog.BlockNumber, "tx", vLog.TxHash)
+	} else {
+		log.Info("Enforced batch mode disabled", "last committed batch index", lastCommittedBatchIndex, "L1 block", vLog.BlockNumber, "tx", vLog.TxHash)
+	}
+
+	rawdb.WriteEnforcedBatchMode(s.db, &rawdb.EnforcedBatchMode{
+		Enabled:                 event.Enabled,
+		LastCommittedBatchIndex: lastCommittedBatchIndex,
+		L1BlockNumber:           vLog.BlockNumber,
+	})
+	return nil
+}
+
+// enforcedBatchModeEnabled reports whether the enforced batch mode of ScrollChain is enabled.
+func (s *RollupSyncService) enforcedBatchModeEnabled() bool {
+	mode := rawdb.ReadEnforcedBatchMode(s.db)
+	return mode != nil && mode.Enabled
+}
@@ -0,0 +1,104 @@
+package rollup_sync_service
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/scroll-tech/go-ethereum/common"
+	"github.com/scroll-tech/go-ethereum/core"
+	"github.com/scroll-tech/go-ethereum/core/rawdb"
+	"github.com/scroll-tech/go-ethereum/core/types"
+	"github.com/scroll-tech/go-ethereum/params"
+
+	"github.com/scroll-tech/go-ethereum/rollup/eventbus"
+)
+
+func TestUpdateEnforcedBatchMode(t *testing.T) {
+	genesisConfig := &params.ChainConfig{
+		Scroll: params.ScrollConfig{
+			L1Config: &params.L1Config{
+				L1ChainId:          11155111,
+				ScrollChainAddress: common.HexToAddress("0x2D567EcE699Eabe5afCd141eDB7A4f2D0D6ce8a0"),
+			},
+		},
+	}
+	db := rawdb.NewMemoryDatabase()
+	service, err := NewRollupSyncService(context.Background(), genesisConfig, db, &mockEthClient{}, &core.BlockChain{}, 1, &Config{VerifyMode: VerifyModeHaltSync}, nil)
+	require.NoError(t, err)
+	assert.NotContains(t, service.ignoredEventTopics, service.l1UpdateEnforcedBatchModeEventSignature)
+
+	// batch 3 is finalized, batches 4 to 6 are committed
+	for batchIndex := uint64(3); batchIndex <= 6; batchIndex++ {
+		service.writeBatchChunkRanges(batchIndex, []*rawdb.ChunkBlockRange{{StartBlockNumber: batchIndex*10 + 1, EndBlockNumber: batchIndex*10 + 10}})
+		rawdb.WriteBatchEndBlock(db, batchIndex*10+10, batchIndex)
+		rawdb.WriteBatchL1Meta(db, batchIndex, &rawdb.BatchL1Meta{CommitTxHash: common.BigToHash(big.NewInt(int64(batchIndex)))})
+	}
+	service.writeFinalizedBatchMeta(3, &rawdb.FinalizedBatchMeta{BatchHash: common.HexToHash("0x03")})
+
+	ch := make(chan eventbus.BatchRevertedEvent, 3)
+	sub := service.bus.SubscribeBatchReverted(ch)
+	defer sub.Unsubscribe()
+
+	modeLog := func(enabled bool, lastCommittedBatchIndex uint64) types.Log {
+		data, err := service.scrollChainABI.Events["UpdateEnforcedBatchMode"].Inputs.Pack(enabled, new(big.Int).SetUint64(lastCommittedBatchIndex))
+		require.NoError(t, err)
+		return types.Log{
+			Topics:      []common.Hash{service.l1UpdateEnforcedBatchModeEventSignature},
+			Data:        data,
+			BlockNumber: 100,
+			TxHash:      common.HexToHash("0xabcd"),
+		}
+	}
+	require.NoError(t, service.parseAndUpdateRollupEventLogs([]types.Log{modeLog(true, 3)}, 100, nil))
+	assert.Equal(t, &rawdb.EnforcedBatchMode{Enabled: true, LastCommittedBatchIndex: 3, L1BlockNumber: 100}, rawdb.ReadEnforcedBatchMode(db))
+	assert.True(t, service.Status().EnforcedBatchMode)
+
+	// the unfinalized batches are reverted by the contract
+	for batchIndex := uint64(4); batchIndex <= 6; batchIndex++ {
+		assert.Nil(t, service.readBatchChunkRanges(batchIndex))
+		assert.Nil(t, rawdb.ReadBatchL1Meta(db, batchIndex))
+		assert.Equal(t, &rawdb.RevertedBatch{
+			Reason:        enforcedBatchModeReason,
+			L1BlockNumber: 100,
+			L1TxHash:      common.HexToHash("0xabcd"),
+			CommitTxHash:  common.BigToHash(big.NewInt(int64(batchIndex))),
+		}, rawdb.ReadRevertedBatch(db, batchIndex))
+		select {
+		case ev := <-ch:
+			assert.Equal(t, batchIndex, ev.BatchIndex)
+		default:
+			t.Fatalf("no BatchRevertedEvent received for batch %d", batchIndex)
+		}
+	}
+	assert.NotNil(t, service.readBatchChunkRanges(3))
+
+	require.NoError(t, service.parseAndUpdateRollupEventLogs([]types.Log{modeLog(false, 7)}, 101, nil))
+	assert.False(t, service.Status().EnforcedBatchMode)
+
+	// finalized batches cannot be reverted
+	assert.Error(t, service.parseAndUpdateRollupEventLogs([]types.Log{modeLog(true, 2)}, 102, nil))
+	assert.NotNil(t, service.readFinalizedBatchMeta(3))
+	assert.False(t, service.enforcedBatchModeEnabled())
+}
+
+func TestDecodeCommitAndFinalizeBatchCalldata(t *testing.T) {
+	scrollChainABI, err := ScrollChainABI()
+	require.NoError(t, err)
+
+	parentBatchHeader, chunks, bitmap := []byte{0x00, 0x01}, [][]byte{{0x02}, {0x03}}, []byte{0x04}
+	data, err := scrollChainABI.Pack("commitAndFinalizeBatch", uint8(1), parentBatchHeader, chunks, bitmap, common.HexToHash("0x05"), common.HexToHash("0x06"), []byte{0x07})
+	require.NoError(t, err)
+
+	calldata, err := DecodeCommitBatchCalldata(data)
+	require.NoError(t, err)
+	assert.Equal(t, &CommitBatchCalldata{
+		Version:                1,
+		ParentBatchHeader:      parentBatchHeader,
+		Chunks:                 chunks,
+		SkippedL1MessageBitmap: bitmap,
+	}, calldata)
+}
@@ -0,0 +1,50 @@
+package rollup_sync_service
+
+import (
+	"github.com/scroll-tech/go-ethereum/core"
+	"github.com/scroll-tech/go-ethereum/core/rawdb"
+	"github.com/scroll-tech/go-ethereum/core/types"
+)
+
+// updateFinalityMarkers points the "finalized" block tag of the chain to the last block of the last
+// finalized batch, and the "safe" block tag to the last local block of the last committed batch.
+// The markers follow the stored batches, so that reverted batches and L1 reorgs are reflected,
+// and are fast-forwarded to the L1 batch pointers during a catch-up. The finalized marker is
+// withheld while the L1 finalized block is stale.
+func (s *RollupSyncService) updateFinalityMarkers() {
+	if s.bc == nil {
+		return
+	}
+	var lastCommitted *CommittedBatch
+	if batchIndex, endBlockNumber, ok := s.lastCommittedBatch(); ok {
+		lastCommitted = &CommittedBatch{BatchIndex: batchIndex, EndBlockNumber: endBlockNumber}
+	}
+	finalizedL2BlockNumber := rawdb.ReadFinalizedL2BlockNumber(s.db)
+	finalizedL2BlockNumber, lastCommitted = s.fastForwardFinalityMarkers(finalizedL2BlockNumber, lastCommitted)
+	if s.l1FinalizedStale() {
+		finalizedL2BlockNumber = nil
+	}
+	setFinalityMarkers(s.bc, finalizedL2BlockNumber, lastCommitted)
+}
+
+// setFinalityMarkers sets the "finalized" block tag of bc to the given finalized block and the
+// "safe" block tag to the last local block of the last committed batch.
+func setFinalityMarkers(bc *core.BlockChain, finalizedL2BlockNumber *uint64, lastCommitted *CommittedBatch) {
+	var finalized *types.Header
+	if finalizedL2BlockNumber != nil {
+		finalized = bc.GetHeaderByNumber(*finalizedL2BlockNumber)
+	}
+	safe := finalized
+	if lastCommitted != nil {
+		// note: committed blocks may not be imported yet, the marker then follows the local head.
+		endBlockNumber := lastCommitted.EndBlockNumber
+		if head := bc.CurrentBlock().NumberU64(); endBlockNumber > head {
+			endBlockNumber = head
+		}
+		if header := bc.GetHeaderByNumber(endBlockNumber); header != nil && (safe == nil || header.Number.Cmp(safe.Number) > 0) {
+			safe = header
+		}
+	}
+	bc.SetFinalized(finalized)
+	bc.SetSafe(safe)
+}
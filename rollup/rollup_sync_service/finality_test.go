@@ -0,0 +1,71 @@
+package rollup_sync_service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/scroll-tech/go-ethereum/consensus/ethash"
+	"github.com/scroll-tech/go-ethereum/core"
+	"github.com/scroll-tech/go-ethereum/core/rawdb"
+	"github.com/scroll-tech/go-ethereum/core/vm"
+	"github.com/scroll-tech/go-ethereum/params"
+)
+
+func TestUpdateFinalityMarkers(t *testing.T) {
+	db := rawdb.NewMemoryDatabase()
+	genesis := (&core.Genesis{Config: params.TestChainConfig}).MustCommit(db)
+	blocks, _ := core.GenerateChain(params.TestChainConfig, genesis, ethash.NewFaker(), rawdb.NewMemoryDatabase(), 5, nil)
+	bc, err := core.NewBlockChain(db, nil, params.TestChainConfig, ethash.NewFaker(), vm.Config{}, nil, nil)
+	require.NoError(t, err)
+	defer bc.Stop()
+	_, err = bc.InsertChain(blocks[:4])
+	require.NoError(t, err)
+
+	service := &RollupSyncService{db: db, bc: bc}
+	service.updateFinalityMarkers()
+	assert.Nil(t, bc.CurrentFinalizedBlock())
+	assert.Nil(t, bc.CurrentSafeBlock())
+
+	// batch 0 is finalized, batch 1 is committed
+	rawdb.WriteBatchChunkRanges(db, 0, []*rawdb.ChunkBlockRange{{StartBlockNumber: 0, EndBlockNumber: 0}})
+	rawdb.WriteBatchEndBlock(db, 0, 0)
+	rawdb.WriteFinalizedL2BlockNumber(db, 0)
+	rawdb.WriteBatchChunkRanges(db, 1, []*rawdb.ChunkBlockRange{{StartBlockNumber: 1, EndBlockNumber: 2}})
+	rawdb.WriteBatchEndBlock(db, 2, 1)
+	service.updateFinalityMarkers()
+	assert.Equal(t, genesis.Hash(), bc.CurrentFinalizedBlock().Hash())
+	assert.Equal(t, blocks[1].Hash(), bc.CurrentSafeBlock().Hash())
+
+	// the safe block follows the local head if the committed blocks are not imported yet
+	rawdb.WriteBatchChunkRanges(db, 2, []*rawdb.ChunkBlockRange{{StartBlockNumber: 3, EndBlockNumber: 5}})
+	rawdb.WriteBatchEndBlock(db, 5, 2)
+	service.updateFinalityMarkers()
+	assert.Equal(t, blocks[3].Hash(), bc.CurrentSafeBlock().Hash())
+	_, err = bc.InsertChain(blocks[4:])
+	require.NoError(t, err)
+	service.updateFinalityMarkers()
+	assert.Equal(t, blocks[4].Hash(), bc.CurrentSafeBlock().Hash())
+
+	// reverted batches are no longer safe
+	rawdb.DeleteBatchRange(db, 2, 2)
+	service.updateFinalityMarkers()
+	assert.Equal(t, blocks[1].Hash(), bc.CurrentSafeBlock().Hash())
+
+	rawdb.WriteFinalizedL2BlockNumber(db, 2)
+	service.updateFinalityMarkers()
+	assert.Equal(t, blocks[1].Hash(), bc.CurrentFinalizedBlock().Hash())
+	assert.Equal(t, blocks[1].Hash(), bc.CurrentSafeBlock().Hash())
+
+	// the finalized block is withheld while the L1 finalized block is stale
+	service.maxL1FinalizedStaleness = time.Minute
+	service.observeL1Finalized(100, time.Now().Add(-time.Hour))
+	service.updateFinalityMarkers()
+	assert.Nil(t, bc.CurrentFinalizedBlock())
+	assert.Equal(t, blocks[1].Hash(), bc.CurrentSafeBlock().Hash())
+	service.observeL1Finalized(101, time.Now())
+	service.updateFinalityMarkers()
+	assert.Equal(t, blocks[1].Hash(), bc.CurrentFinalizedBlock().Hash())
+}
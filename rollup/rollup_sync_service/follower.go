@@ -0,0 +1,216 @@
+package rollup_sync_service
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/scroll-tech/go-ethereum/core"
+	"github.com/scroll-tech/go-ethereum/core/rawdb"
+	"github.com/scroll-tech/go-ethereum/log"
+	"github.com/scroll-tech/go-ethereum/rpc"
+)
+
+const (
+	// defaultFollowInterval is the frequency of updating the finality markers from the writer node.
+	defaultFollowInterval = 10 * time.Second
+
+	// defaultFollowRequestTimeout bounds every request of a follower to the writer node.
+	defaultFollowRequestTimeout = 10 * time.Second
+)
+
+// remoteBatchReader reads the rollup metadata from the rollupdb API of another node.
+type remoteBatchReader struct {
+	client *rpc.Client
+}
+
+// NewRemoteBatchReader returns a BatchReader of the rollup metadata served by the
+// rollupdb API of the node at the other end of client.
+func NewRemoteBatchReader(client *rpc.Client) BatchReader {
+	return &remoteBatchReader{client: client}
+}
+
+func (r *remoteBatchReader) FinalizedBatchMeta(ctx context.Context, batchIndex uint64) (*rawdb.FinalizedBatchMeta, error) {
+	var meta *rawdb.FinalizedBatchMeta
+	err := r.client.CallContext(ctx, &meta, "rollupdb_finalizedBatchMeta", batchIndex)
+	return meta, err
+}
+
+func (r *remoteBatchReader) BatchChunkRanges(ctx context.Context, batchIndex uint64) ([]*rawdb.ChunkBlockRange, error) {
+	var chunkBlockRanges []*rawdb.ChunkBlockRange
+	err := r.client.CallContext(ctx, &chunkBlockRanges, "rollupdb_batchChunkRanges", batchIndex)
+	return chunkBlockRanges, err
+}
+
+func (r *remoteBatchReader) BatchL1Meta(ctx context.Context, batchIndex uint64) (*rawdb.BatchL1Meta, error) {
+	var meta *rawdb.BatchL1Meta
+	err := r.client.CallContext(ctx, &meta, "rollupdb_batchL1Meta", batchIndex)
+	return meta, err
+}
+
+func (r *remoteBatchReader) BatchIndexByL2BlockNumber(ctx context.Context, l2BlockNumber uint64) (*uint64, error) {
+	var batchIndex *uint64
+	err := r.client.CallContext(ctx, &batchIndex, "rollupdb_batchIndexByL2BlockNumber", l2BlockNumber)
+	return batchIndex, err
+}
+
+func (r *remoteBatchReader) FinalizedL2BlockNumber(ctx context.Context) (*uint64, error) {
+	var number *uint64
+	err := r.client.CallContext(ctx, &number, "rollupdb_finalizedL2BlockNumber")
+	return number, err
+}
+
+func (r *remoteBatchReader) RollupEventSyncedL1BlockNumber(ctx context.Context) (*uint64, error) {
+	var number *uint64
+	err := r.client.CallContext(ctx, &number, "rollupdb_rollupEventSyncedL1BlockNumber")
+	return number, err
+}
+
+func (r *remoteBatchReader) LastCommittedBatch(ctx context.Context) (*CommittedBatch, error) {
+	var batch *CommittedBatch
+	err := r.client.CallContext(ctx, &batch, "rollupdb_lastCommittedBatch")
+	return batch, err
+}
+
+func (r *remoteBatchReader) BatchesByL1Block(ctx context.Context, l1BlockNumber uint64) (*L1BlockBatches, error) {
+	var batches *L1BlockBatches
+	err := r.client.CallContext(ctx, &batches, "rollupdb_batchesByL1Block", l1BlockNumber)
+	return batches, err
+}
+
+func (r *remoteBatchReader) L1EndpointRanges(ctx context.Context, from, to uint64) ([]*rawdb.L1EndpointRange, error) {
+	var ranges []*rawdb.L1EndpointRange
+	err := r.client.CallContext(ctx, &ranges, "rollupdb_l1EndpointRanges", from, to)
+	return ranges, err
+}
+
+func (r *remoteBatchReader) BatchGaps(ctx context.Context) ([]BatchGap, error) {
+	var gaps []BatchGap
+	err := r.client.CallContext(ctx, &gaps, "rollupdb_batchGaps")
+	return gaps, err
+}
+
+func (r *remoteBatchReader) RecoveryProgress(ctx context.Context) (*RecoveryProgress, error) {
+	var progress *RecoveryProgress
+	err := r.client.CallContext(ctx, &progress, "rollupdb_recoveryProgress")
+	return progress, err
+}
+
+func (r *remoteBatchReader) L1BatchPointers(ctx context.Context) (*L1BatchPointers, error) {
+	var pointers *L1BatchPointers
+	err := r.client.CallContext(ctx, &pointers, "rollupdb_l1BatchPointers")
+	return pointers, err
+}
+
+// Follower serves the rollup metadata of a writer node running the rollup verifier instead of
+// syncing it from L1, so that a cluster of RPC nodes shares one copy of the batch metadata and
+// one set of L1 queries. It keeps the finality markers of the local chain up to date with the
+// writer node.
+type Follower struct {
+	ctx    context.Context
+	cancel context.CancelFunc
+	client *rpc.Client
+	reader BatchReader
+	bc     *core.BlockChain
+	wg     sync.WaitGroup
+}
+
+// NewFollower connects to the RPC endpoint of the writer node, which must serve the rollupdb API.
+func NewFollower(ctx context.Context, url string, bc *core.BlockChain) (*Follower, error) {
+	client, err := rpc.DialContext(ctx, url)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to rollup writer node: %w", err)
+	}
+	return newFollower(ctx, client, bc), nil
+}
+
+func newFollower(ctx context.Context, client *rpc.Client, bc *core.BlockChain) *Follower {
+	ctx, cancel := context.WithCancel(ctx)
+	return &Follower{
+		ctx:    ctx,
+		cancel: cancel,
+		client: client,
+		reader: NewRemoteBatchReader(client),
+		bc:     bc,
+	}
+}
+
+// Reader returns the reader of the rollup metadata of the writer node.
+func (f *Follower) Reader() BatchReader {
+	return f.reader
+}
+
+// Forward calls the RPC method of the writer node, for the scroll RPCs that are computed by
+// the rollup verifier and cannot be served from the rollup metadata alone. The writer node
+// must serve the scroll API next to the rollupdb API.
+func (f *Follower) Forward(ctx context.Context, result interface{}, method string, args ...interface{}) error {
+	return f.client.CallContext(ctx, result, method, args...)
+}
+
+// Subscribe subscribes to the notifications of the scroll API of the writer node. The
+// subscription ends when the follower is stopped.
+func (f *Follower) Subscribe(channel interface{}, args ...interface{}) (*rpc.ClientSubscription, error) {
+	ctx, cancel := context.WithTimeout(f.ctx, defaultFollowRequestTimeout)
+	defer cancel()
+	return f.client.Subscribe(ctx, "scroll", channel, args...)
+}
+
+// Start starts updating the finality markers from the writer node.
+func (f *Follower) Start() {
+	if f == nil {
+		return
+	}
+
+	log.Info("Starting rollup metadata follower")
+
+	f.wg.Add(1)
+	go func() {
+		defer f.wg.Done()
+
+		ticker := time.NewTicker(defaultFollowInterval)
+		defer ticker.Stop()
+
+		for {
+			if err := f.updateFinalityMarkers(); err != nil {
+				log.Warn("Failed to update finality markers from rollup writer node", "err", err)
+			}
+			select {
+			case <-f.ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// Stop stops the follower and closes the connection to the writer node.
+func (f *Follower) Stop() {
+	if f == nil {
+		return
+	}
+
+	log.Info("Stopping rollup metadata follower")
+
+	f.cancel()
+	f.wg.Wait()
+	f.client.Close()
+}
+
+// updateFinalityMarkers points the finality markers of the local chain to the finalized and
+// committed batches of the writer node, see RollupSyncService.updateFinalityMarkers.
+func (f *Follower) updateFinalityMarkers() error {
+	ctx, cancel := context.WithTimeout(f.ctx, defaultFollowRequestTimeout)
+	defer cancel()
+
+	finalizedL2BlockNumber, err := f.reader.FinalizedL2BlockNumber(ctx)
+	if err != nil {
+		return err
+	}
+	lastCommitted, err := f.reader.LastCommittedBatch(ctx)
+	if err != nil {
+		return err
+	}
+	setFinalityMarkers(f.bc, finalizedL2BlockNumber, lastCommitted)
+	return nil
+}
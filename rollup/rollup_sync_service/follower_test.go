@@ -0,0 +1,153 @@
+package rollup_sync_service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/scroll-tech/go-ethereum/common"
+	"github.com/scroll-tech/go-ethereum/consensus/ethash"
+	"github.com/scroll-tech/go-ethereum/core"
+	"github.com/scroll-tech/go-ethereum/core/rawdb"
+	"github.com/scroll-tech/go-ethereum/core/vm"
+	"github.com/scroll-tech/go-ethereum/params"
+	"github.com/scroll-tech/go-ethereum/rpc"
+)
+
+func TestFollower(t *testing.T) {
+	// the writer node stores the rollup metadata
+	writerDb := rawdb.NewMemoryDatabase()
+	rawdb.WriteBatchChunkRanges(writerDb, 0, []*rawdb.ChunkBlockRange{{StartBlockNumber: 0, EndBlockNumber: 0}})
+	rawdb.WriteBatchEndBlock(writerDb, 0, 0)
+	rawdb.WriteFinalizedBatchMeta(writerDb, 0, &rawdb.FinalizedBatchMeta{BatchHash: common.HexToHash("0x01"), TotalL1MessagePopped: 3})
+	rawdb.WriteBatchL1Meta(writerDb, 0, &rawdb.BatchL1Meta{CommitTxHash: common.HexToHash("0x02"), CommitL1BlockNumber: 10})
+	rawdb.WriteBatchL1Block(writerDb, 10, rawdb.BatchCommittedL1Event, 0)
+	rawdb.WriteFinalizedL2BlockNumber(writerDb, 0)
+	rawdb.WriteBatchChunkRanges(writerDb, 1, []*rawdb.ChunkBlockRange{{StartBlockNumber: 1, EndBlockNumber: 1}, {StartBlockNumber: 2, EndBlockNumber: 2}})
+	rawdb.WriteBatchEndBlock(writerDb, 2, 1)
+	rawdb.WriteRollupEventSyncedL1BlockNumber(writerDb, 20)
+	rawdb.WriteL1EndpointRange(writerDb, &rawdb.L1EndpointRange{FromL1BlockNumber: 11, ToL1BlockNumber: 20, Endpoints: []string{"a", "b"}})
+	rawdb.WriteRollupBatchGaps(writerDb, []*rawdb.RollupBatchGap{{FromBatchIndex: 5, ToBatchIndex: 6, FromL1BlockNumber: 1, ToL1BlockNumber: 10, NextL1BlockNumber: 3}})
+	rawdb.WriteRollupSyncRecovery(writerDb, &rawdb.RollupSyncRecovery{StartL1BlockNumber: 10, TargetL1BlockNumber: 30, BatchesDerived: 2})
+
+	server := rpc.NewServer()
+	defer server.Stop()
+	require.NoError(t, server.RegisterName("rollupdb", NewBatchReaderAPI(NewDBBatchReader(writerDb))))
+
+	// the follower node only has the chain
+	db := rawdb.NewMemoryDatabase()
+	genesis := (&core.Genesis{Config: params.TestChainConfig}).MustCommit(db)
+	blocks, _ := core.GenerateChain(params.TestChainConfig, genesis, ethash.NewFaker(), rawdb.NewMemoryDatabase(), 3, nil)
+	bc, err := core.NewBlockChain(db, nil, params.TestChainConfig, ethash.NewFaker(), vm.Config{}, nil, nil)
+	require.NoError(t, err)
+	defer bc.Stop()
+	_, err = bc.InsertChain(blocks)
+	require.NoError(t, err)
+
+	follower := newFollower(context.Background(), rpc.DialInProc(server), bc)
+	defer follower.Stop()
+
+	// the follower serves the metadata of the writer node
+	ctx := context.Background()
+	local, remote := NewDBBatchReader(writerDb), follower.Reader()
+	for batchIndex := uint64(0); batchIndex <= 2; batchIndex++ {
+		want, err := local.FinalizedBatchMeta(ctx, batchIndex)
+		require.NoError(t, err)
+		have, err := remote.FinalizedBatchMeta(ctx, batchIndex)
+		require.NoError(t, err)
+		assert.Equal(t, want, have)
+
+		wantRanges, err := local.BatchChunkRanges(ctx, batchIndex)
+		require.NoError(t, err)
+		haveRanges, err := remote.BatchChunkRanges(ctx, batchIndex)
+		require.NoError(t, err)
+		assert.Equal(t, wantRanges, haveRanges)
+
+		wantL1Meta, err := local.BatchL1Meta(ctx, batchIndex)
+		require.NoError(t, err)
+		haveL1Meta, err := remote.BatchL1Meta(ctx, batchIndex)
+		require.NoError(t, err)
+		assert.Equal(t, wantL1Meta, haveL1Meta)
+	}
+	batchIndex, err := remote.BatchIndexByL2BlockNumber(ctx, 1)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(1), *batchIndex)
+	batchIndex, err = remote.BatchIndexByL2BlockNumber(ctx, 3)
+	require.NoError(t, err)
+	assert.Nil(t, batchIndex)
+	number, err := remote.RollupEventSyncedL1BlockNumber(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(20), *number)
+	lastCommitted, err := remote.LastCommittedBatch(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, &CommittedBatch{BatchIndex: 1, EndBlockNumber: 2}, lastCommitted)
+	l1BlockBatches, err := remote.BatchesByL1Block(ctx, 5)
+	require.NoError(t, err)
+	assert.Equal(t, &L1BlockBatches{L1BlockNumber: 10, Committed: []uint64{0}}, l1BlockBatches)
+	endpointRanges, err := remote.L1EndpointRanges(ctx, 15, 15)
+	require.NoError(t, err)
+	assert.Equal(t, []*rawdb.L1EndpointRange{{FromL1BlockNumber: 11, ToL1BlockNumber: 20, Endpoints: []string{"a", "b"}}}, endpointRanges)
+	gaps, err := remote.BatchGaps(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []BatchGap{{FromBatchIndex: 5, ToBatchIndex: 6, FromL1Block: 1, ToL1Block: 10, NextL1Block: 3}}, gaps)
+	recovery, err := remote.RecoveryProgress(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, &RecoveryProgress{StartL1Block: 10, L1Height: 20, TargetL1Block: 30, BatchesDerived: 2}, recovery)
+	pointers, err := remote.L1BatchPointers(ctx)
+	require.NoError(t, err)
+	assert.Nil(t, pointers)
+
+	// and its sync progress
+	progress, err := ReadProgress(ctx, remote, 3)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(20), progress.L1ProcessedBlock)
+	assert.Equal(t, uint64(1), *progress.LatestCommittedBatch)
+	assert.Equal(t, uint64(0), *progress.LatestFinalizedBatch)
+	assert.Equal(t, uint64(0), *progress.FinalizedL2Block)
+	assert.Equal(t, uint64(3), progress.FinalizedL2BlockLag)
+
+	// and follows its finality markers
+	require.NoError(t, follower.updateFinalityMarkers())
+	assert.Equal(t, genesis.Hash(), bc.CurrentFinalizedBlock().Hash())
+	assert.Equal(t, blocks[1].Hash(), bc.CurrentSafeBlock().Hash())
+
+	rawdb.WriteFinalizedL2BlockNumber(writerDb, 2)
+	require.NoError(t, follower.updateFinalityMarkers())
+	assert.Equal(t, blocks[1].Hash(), bc.CurrentFinalizedBlock().Hash())
+
+	// the local database is not written
+	assert.Nil(t, rawdb.ReadFinalizedL2BlockNumber(db))
+	assert.Nil(t, rawdb.ReadBatchChunkRanges(db, 0))
+
+	// failures of the writer node are reported
+	server.Stop()
+	assert.Error(t, follower.updateFinalityMarkers())
+}
+
+func TestBatchesByL1Block(t *testing.T) {
+	db := rawdb.NewMemoryDatabase()
+	reader := NewDBBatchReader(db)
+	ctx := context.Background()
+
+	// batch 1 was committed at L1 block 10, reverted and committed again at L1 block 20
+	rawdb.WriteBatchL1Block(db, 10, rawdb.BatchCommittedL1Event, 1)
+	rawdb.WriteBatchL1Block(db, 20, rawdb.BatchCommittedL1Event, 1)
+	rawdb.WriteBatchL1Block(db, 20, rawdb.BatchCommittedL1Event, 2)
+	rawdb.WriteBatchL1Block(db, 30, rawdb.BatchFinalizedL1Event, 1)
+	rawdb.WriteBatchL1Meta(db, 1, &rawdb.BatchL1Meta{CommitTxHash: common.HexToHash("0x01"), CommitL1BlockNumber: 20, FinalizeTxHash: common.HexToHash("0x02"), FinalizeL1BlockNumber: 30})
+	rawdb.WriteBatchL1Meta(db, 2, &rawdb.BatchL1Meta{CommitTxHash: common.HexToHash("0x03"), CommitL1BlockNumber: 20})
+
+	batches, err := reader.BatchesByL1Block(ctx, 0)
+	require.NoError(t, err)
+	assert.Equal(t, &L1BlockBatches{L1BlockNumber: 20, Committed: []uint64{1, 2}}, batches)
+
+	batches, err = reader.BatchesByL1Block(ctx, 21)
+	require.NoError(t, err)
+	assert.Equal(t, &L1BlockBatches{L1BlockNumber: 30, Finalized: []uint64{1}}, batches)
+
+	batches, err = reader.BatchesByL1Block(ctx, 31)
+	require.NoError(t, err)
+	assert.Nil(t, batches)
+}
@@ -0,0 +1,264 @@
+package rollup_sync_service
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/scroll-tech/go-ethereum/core/rawdb"
+	"github.com/scroll-tech/go-ethereum/core/types"
+	"github.com/scroll-tech/go-ethereum/ethdb"
+	"github.com/scroll-tech/go-ethereum/log"
+)
+
+const (
+	// maxBatchGapScan bounds the number of batches searched backwards for the last
+	// locally committed batch when a gap is detected.
+	maxBatchGapScan = 10000
+
+	// maxBatchGapScanBlocks bounds the number of L1 blocks re-queried for the missing
+	// batches of a gap at a time. Larger gaps are re-queried over several fetch rounds.
+	maxBatchGapScanBlocks = 10 * defaultFetchBlockRange
+)
+
+// BatchGap is a range of batch indices whose CommitBatch events were not returned
+// by L1, along with the L1 blocks in which the missing events must have been emitted.
+// The L1 blocks are re-queried in windows, NextL1Block is the first block of the next one.
+type BatchGap struct {
+	FromBatchIndex uint64 `json:"fromBatchIndex"`
+	ToBatchIndex   uint64 `json:"toBatchIndex"`
+	FromL1Block    uint64 `json:"fromL1Block"`
+	ToL1Block      uint64 `json:"toL1Block"`
+	NextL1Block    uint64 `json:"nextL1Block"`
+}
+
+// SyncGaps returns the batch index gaps that could not be recovered from L1 so far.
+func (s *RollupSyncService) SyncGaps() []BatchGap {
+	s.gapsLock.Lock()
+	defer s.gapsLock.Unlock()
+
+	gaps := make([]BatchGap, 0, len(s.gaps))
+	for _, gap := range s.gaps {
+		gaps = append(gaps, *gap)
+	}
+	return gaps
+}
+
+// readBatchGaps loads the batch gaps persisted by a previous run.
+func readBatchGaps(db ethdb.Reader) []*BatchGap {
+	var gaps []*BatchGap
+	for _, gap := range rawdb.ReadRollupBatchGaps(db) {
+		gaps = append(gaps, &BatchGap{
+			FromBatchIndex: gap.FromBatchIndex,
+			ToBatchIndex:   gap.ToBatchIndex,
+			FromL1Block:    gap.FromL1BlockNumber,
+			ToL1Block:      gap.ToL1BlockNumber,
+			NextL1Block:    gap.NextL1BlockNumber,
+		})
+	}
+	return gaps
+}
+
+// setBatchGaps replaces the tracked batch gaps and persists them. It must be called with
+// s.gapsLock held.
+func (s *RollupSyncService) setBatchGaps(gaps []*BatchGap) {
+	s.gaps = gaps
+	stored := make([]*rawdb.RollupBatchGap, 0, len(gaps))
+	for _, gap := range gaps {
+		stored = append(stored, &rawdb.RollupBatchGap{
+			FromBatchIndex:    gap.FromBatchIndex,
+			ToBatchIndex:      gap.ToBatchIndex,
+			FromL1BlockNumber: gap.FromL1Block,
+			ToL1BlockNumber:   gap.ToL1Block,
+			NextL1BlockNumber: gap.NextL1Block,
+		})
+	}
+	rawdb.WriteRollupBatchGaps(s.db, stored)
+}
+
+func (s *RollupSyncService) isBatchCommitted(batchIndex uint64) bool {
+	return s.readBatchChunkRanges(batchIndex) != nil
+}
+
+// isGapTracked reports whether a batch is part of a tracked gap.
+func (s *RollupSyncService) isGapTracked(batchIndex uint64) bool {
+	s.gapsLock.Lock()
+	defer s.gapsLock.Unlock()
+
+	for _, gap := range s.gaps {
+		if gap.FromBatchIndex <= batchIndex && batchIndex <= gap.ToBatchIndex {
+			return true
+		}
+	}
+	return false
+}
+
+// detectBatchGap returns the range of batches missing before a committed batch,
+// or nil if the previous batch has been committed locally or is already tracked.
+func (s *RollupSyncService) detectBatchGap(batchIndex, l1BlockNumber uint64) *BatchGap {
+	if batchIndex == 0 || s.isBatchCommitted(batchIndex-1) || s.isGapTracked(batchIndex-1) {
+		return nil
+	}
+	gap := &BatchGap{FromBatchIndex: 0, ToBatchIndex: batchIndex - 1, FromL1Block: s.l1DeploymentBlock, ToL1Block: l1BlockNumber}
+	for i := uint64(1); i <= maxBatchGapScan && i < batchIndex; i++ {
+		if s.isBatchCommitted(batchIndex - 1 - i) {
+			gap.FromBatchIndex = batchIndex - i
+			if batchL1Meta := rawdb.ReadBatchL1Meta(s.db, batchIndex-1-i); batchL1Meta != nil {
+				gap.FromL1Block = batchL1Meta.CommitL1BlockNumber
+			}
+			break
+		}
+	}
+	gap.NextL1Block = gap.FromL1Block
+	batchGapCounter.Inc(1)
+	log.Warn("Detected missing CommitBatch events", "from batch index", gap.FromBatchIndex, "to batch index", gap.ToBatchIndex, "from L1 block", gap.FromL1Block, "to L1 block", gap.ToL1Block)
+	return gap
+}
+
+// trackBatchGap re-queries the first window of L1 blocks of a new gap, and tracks the
+// rest of the gap until it is recovered.
+func (s *RollupSyncService) trackBatchGap(gap *BatchGap) error {
+	remaining, err := s.recoverBatchGap(gap)
+	if err != nil {
+		return err
+	}
+	s.gapsLock.Lock()
+	defer s.gapsLock.Unlock()
+	s.setBatchGaps(append(s.gaps, remaining...))
+	return nil
+}
+
+// recoverBatchGap re-queries the next window of L1 blocks of a gap and stores the missing
+// batches found in it. It returns the batches of the gap that are still missing afterwards:
+// the gap itself to continue with the next window, or, once all L1 blocks of the gap were
+// re-queried, the unresolved gaps to be re-queried from the start again.
+func (s *RollupSyncService) recoverBatchGap(gap *BatchGap) ([]*BatchGap, error) {
+	end := gap.NextL1Block + maxBatchGapScanBlocks - 1
+	if end > gap.ToL1Block {
+		end = gap.ToL1Block
+	}
+	// note: only the last commit of a batch index counts, earlier ones have been reverted.
+	commits := make(map[uint64]types.Log)
+	for from := gap.NextL1Block; from <= end; from += defaultFetchBlockRange {
+		to := from + defaultFetchBlockRange - 1
+		if to > end {
+			to = end
+		}
+		logs, err := s.client.fetchRollupEventsInRange(s.ctx, from, to)
+		if err != nil {
+			return nil, fmt.Errorf("failed to re-query missing batches, from batch index: %v, to batch index: %v, err: %w", gap.FromBatchIndex, gap.ToBatchIndex, err)
+		}
+		for _, vLog := range logs {
+			if len(vLog.Topics) < 2 {
+				continue
+			}
+			batchIndex := vLog.Topics[1].Big().Uint64()
+			if batchIndex < gap.FromBatchIndex || batchIndex > gap.ToBatchIndex {
+				continue
+			}
+			switch vLog.Topics[0] {
+			case s.l1CommitBatchEventSignature:
+				commits[batchIndex] = vLog
+			case s.l1RevertBatchEventSignature:
+				delete(commits, batchIndex)
+				// the batch might have been recovered from an earlier window
+				if err := s.revertRecoveredBatch(batchIndex, vLog); err != nil {
+					return nil, err
+				}
+			}
+		}
+	}
+
+	indices := make([]uint64, 0, len(commits))
+	for batchIndex := range commits {
+		indices = append(indices, batchIndex)
+	}
+	sort.Slice(indices, func(i, j int) bool { return indices[i] < indices[j] })
+	for _, batchIndex := range indices {
+		if s.isBatchCommitted(batchIndex) {
+			continue
+		}
+		vLog := commits[batchIndex]
+		if err := s.handleCommitBatch(&vLog, false); err != nil {
+			return nil, err
+		}
+		log.Info("Recovered missing CommitBatch event", "batch index", batchIndex, "L1 block", vLog.BlockNumber)
+	}
+
+	if end < gap.ToL1Block {
+		next := *gap
+		next.NextL1Block = end + 1
+		if len(s.unresolvedBatches(&next)) == 0 {
+			return nil, nil
+		}
+		log.Debug("Re-queried missing CommitBatch events", "from batch index", gap.FromBatchIndex, "to batch index", gap.ToBatchIndex, "L1 block", end, "to L1 block", gap.ToL1Block)
+		return []*BatchGap{&next}, nil
+	}
+	unresolved := s.unresolvedBatches(gap)
+	for _, g := range unresolved {
+		log.Error("Missing CommitBatch events could not be recovered", "from batch index", g.FromBatchIndex, "to batch index", g.ToBatchIndex, "from L1 block", g.FromL1Block, "to L1 block", g.ToL1Block)
+	}
+	return unresolved, nil
+}
+
+// revertRecoveredBatch removes a batch of a gap that was recovered before its RevertBatch
+// event was found. Only the batch itself is removed, the batches committed after the revert
+// are not affected.
+func (s *RollupSyncService) revertRecoveredBatch(batchIndex uint64, vLog types.Log) error {
+	if !s.isBatchCommitted(batchIndex) || s.readFinalizedBatchMeta(batchIndex) != nil {
+		return nil
+	}
+	if s.l1Follower {
+		if err := s.rewindDerivedBlocks(batchIndex); err != nil {
+			return err
+		}
+	}
+	s.unwindBatches([]uint64{batchIndex}, &vLog, func(uint64) *rawdb.RevertedBatch {
+		return &rawdb.RevertedBatch{Reason: "RevertBatch event of a recovered batch"}
+	})
+	log.Info("Reverted recovered batch", "batch index", batchIndex, "L1 block", vLog.BlockNumber)
+	return nil
+}
+
+// unresolvedBatches returns the batches of a gap that are still not committed locally, to be
+// re-queried from the first L1 block of the gap.
+func (s *RollupSyncService) unresolvedBatches(gap *BatchGap) []*BatchGap {
+	var unresolved []*BatchGap
+	for batchIndex := gap.FromBatchIndex; batchIndex <= gap.ToBatchIndex; batchIndex++ {
+		if s.isBatchCommitted(batchIndex) {
+			continue
+		}
+		if n := len(unresolved); n > 0 && unresolved[n-1].ToBatchIndex+1 == batchIndex {
+			unresolved[n-1].ToBatchIndex = batchIndex
+			continue
+		}
+		unresolved = append(unresolved, &BatchGap{FromBatchIndex: batchIndex, ToBatchIndex: batchIndex, FromL1Block: gap.FromL1Block, ToL1Block: gap.ToL1Block, NextL1Block: gap.FromL1Block})
+	}
+	return unresolved
+}
+
+// retryBatchGaps re-queries the next window of L1 blocks of the tracked gaps. Gaps whose
+// batches have been committed in the meantime are dropped.
+func (s *RollupSyncService) retryBatchGaps() {
+	s.gapsLock.Lock()
+	gaps := s.gaps
+	s.gapsLock.Unlock()
+	if len(gaps) == 0 {
+		return
+	}
+
+	var remaining []*BatchGap
+	for i, gap := range gaps {
+		recovered, err := s.recoverBatchGap(gap)
+		if err != nil {
+			log.Warn("Failed to recover missing batches", "from batch index", gap.FromBatchIndex, "to batch index", gap.ToBatchIndex, "err", err)
+			remaining = append(remaining, gaps[i:]...)
+			break
+		}
+		remaining = append(remaining, recovered...)
+	}
+
+	s.gapsLock.Lock()
+	defer s.gapsLock.Unlock()
+	// note: gaps detected while retrying are appended to the tracked ones
+	s.setBatchGaps(append(remaining, s.gaps[len(gaps):]...))
+}
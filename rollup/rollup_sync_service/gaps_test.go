@@ -0,0 +1,147 @@
+package rollup_sync_service
+
+import (
+	"context"
+	"math/big"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/scroll-tech/go-ethereum"
+	"github.com/scroll-tech/go-ethereum/common"
+	"github.com/scroll-tech/go-ethereum/core/rawdb"
+	"github.com/scroll-tech/go-ethereum/core/types"
+	"github.com/scroll-tech/go-ethereum/params"
+)
+
+// logsEthClient serves a fixed set of rollup event logs.
+type logsEthClient struct {
+	mockEthClient
+	logs []types.Log
+}
+
+func (m *logsEthClient) FilterLogs(ctx context.Context, q ethereum.FilterQuery) ([]types.Log, error) {
+	var logs []types.Log
+	for _, vLog := range m.logs {
+		if vLog.BlockNumber >= q.FromBlock.Uint64() && vLog.BlockNumber <= q.ToBlock.Uint64() {
+			logs = append(logs, vLog)
+		}
+	}
+	return logs, nil
+}
+
+func TestBatchGaps(t *testing.T) {
+	genesisConfig := &params.ChainConfig{
+		Scroll: params.ScrollConfig{
+			L1Config: &params.L1Config{
+				L1ChainId:          11155111,
+				ScrollChainAddress: common.HexToAddress("0x2D567EcE699Eabe5afCd141eDB7A4f2D0D6ce8a0"),
+			},
+		},
+	}
+	rlpData, err := os.ReadFile("./testdata/commit_batch_tx.rlp")
+	require.NoError(t, err)
+	client := &logsEthClient{mockEthClient: mockEthClient{commitBatchRLP: rlpData}}
+	db := rawdb.NewMemoryDatabase()
+	service, err := NewRollupSyncService(context.Background(), genesisConfig, db, client, newTestBlockChain(t, db), 1, &Config{VerifyMode: VerifyModeHaltSync}, nil)
+	require.NoError(t, err)
+
+	eventLog := func(signature common.Hash, batchIndex, l1BlockNumber uint64) types.Log {
+		return types.Log{
+			Topics:      []common.Hash{signature, common.BigToHash(new(big.Int).SetUint64(batchIndex)), {}},
+			BlockNumber: l1BlockNumber,
+		}
+	}
+	commitLog := func(batchIndex, l1BlockNumber uint64) types.Log {
+		return eventLog(service.l1CommitBatchEventSignature, batchIndex, l1BlockNumber)
+	}
+
+	// batches 0 and 1 are committed, batch 1 in L1 block 10
+	rawdb.WriteBatchChunkRanges(db, 0, []*rawdb.ChunkBlockRange{{StartBlockNumber: 0, EndBlockNumber: 0}})
+	rawdb.WriteBatchChunkRanges(db, 1, []*rawdb.ChunkBlockRange{{StartBlockNumber: 1, EndBlockNumber: 5}})
+	rawdb.WriteBatchL1Meta(db, 1, &rawdb.BatchL1Meta{CommitL1BlockNumber: 10})
+
+	// the commit of batch 2 is recovered, batch 3 was reverted and batch 4 is not returned
+	client.logs = []types.Log{
+		commitLog(2, 15),
+		commitLog(3, 16),
+		eventLog(service.l1RevertBatchEventSignature, 3, 17),
+	}
+	batch5 := commitLog(5, 300)
+	require.NoError(t, service.handleCommitBatch(&batch5, true))
+	assert.True(t, service.isBatchCommitted(2))
+	assert.False(t, service.isBatchCommitted(3))
+	assert.True(t, service.isBatchCommitted(5))
+	assert.Equal(t, []BatchGap{{FromBatchIndex: 3, ToBatchIndex: 4, FromL1Block: 10, ToL1Block: 300, NextL1Block: 10}}, service.SyncGaps())
+
+	// consecutive commits do not trigger a re-query
+	client.logs = nil
+	batch6 := commitLog(6, 301)
+	require.NoError(t, service.handleCommitBatch(&batch6, true))
+	assert.Len(t, service.SyncGaps(), 1)
+
+	// the gap is resolved once L1 returns the missing commits
+	client.logs = []types.Log{commitLog(3, 250), commitLog(4, 251)}
+	service.retryBatchGaps()
+	assert.True(t, service.isBatchCommitted(3))
+	assert.True(t, service.isBatchCommitted(4))
+	assert.Empty(t, service.SyncGaps())
+}
+
+func TestBatchGapScanWindows(t *testing.T) {
+	genesisConfig := &params.ChainConfig{
+		Scroll: params.ScrollConfig{
+			L1Config: &params.L1Config{
+				L1ChainId:          11155111,
+				ScrollChainAddress: common.HexToAddress("0x2D567EcE699Eabe5afCd141eDB7A4f2D0D6ce8a0"),
+			},
+		},
+	}
+	rlpData, err := os.ReadFile("./testdata/commit_batch_tx.rlp")
+	require.NoError(t, err)
+	client := &logsEthClient{mockEthClient: mockEthClient{commitBatchRLP: rlpData}}
+	db := rawdb.NewMemoryDatabase()
+	bc := newTestBlockChain(t, db)
+	service, err := NewRollupSyncService(context.Background(), genesisConfig, db, client, bc, 1, &Config{VerifyMode: VerifyModeHaltSync}, nil)
+	require.NoError(t, err)
+
+	commitLog := func(batchIndex, l1BlockNumber uint64) types.Log {
+		return types.Log{
+			Topics:      []common.Hash{service.l1CommitBatchEventSignature, common.BigToHash(new(big.Int).SetUint64(batchIndex)), {}},
+			BlockNumber: l1BlockNumber,
+		}
+	}
+
+	// only batch 0 is committed locally, the gap spans all L1 blocks since the deployment
+	rawdb.WriteBatchChunkRanges(db, 0, []*rawdb.ChunkBlockRange{{StartBlockNumber: 0, EndBlockNumber: 0}})
+	client.logs = []types.Log{commitLog(1, 500), commitLog(2, 2500)}
+	batch3 := commitLog(3, 2600)
+	require.NoError(t, service.handleCommitBatch(&batch3, true))
+
+	// the first window is re-queried right away, the rest of the gap in the next rounds
+	assert.True(t, service.isBatchCommitted(1))
+	assert.False(t, service.isBatchCommitted(2))
+	gap := BatchGap{FromBatchIndex: 1, ToBatchIndex: 2, FromL1Block: 1, ToL1Block: 2600, NextL1Block: 1 + maxBatchGapScanBlocks}
+	assert.Equal(t, []BatchGap{gap}, service.SyncGaps())
+
+	// the scan progress is persisted
+	service, err = NewRollupSyncService(context.Background(), genesisConfig, db, client, bc, 1, &Config{VerifyMode: VerifyModeHaltSync}, nil)
+	require.NoError(t, err)
+	assert.Equal(t, []BatchGap{gap}, service.SyncGaps())
+
+	// a commit that is fetched again, e.g. after a failed round, does not start another scan
+	require.NoError(t, service.handleCommitBatch(&batch3, true))
+	assert.Equal(t, []BatchGap{gap}, service.SyncGaps())
+
+	service.retryBatchGaps()
+	assert.False(t, service.isBatchCommitted(2))
+	gap.NextL1Block += maxBatchGapScanBlocks
+	assert.Equal(t, []BatchGap{gap}, service.SyncGaps())
+
+	service.retryBatchGaps()
+	assert.True(t, service.isBatchCommitted(2))
+	assert.Empty(t, service.SyncGaps())
+	assert.Empty(t, rawdb.ReadRollupBatchGaps(db))
+}
@@ -0,0 +1,93 @@
+package rollup_sync_service
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/scroll-tech/go-ethereum/common"
+	"github.com/scroll-tech/go-ethereum/core/rawdb"
+	"github.com/scroll-tech/go-ethereum/core/types"
+)
+
+// BatchCommitInfo describes a batch whose CommitBatch event has been processed.
+type BatchCommitInfo struct {
+	BatchIndex       uint64
+	BatchHash        common.Hash
+	CodecVersion     uint8
+	ChunkBlockRanges []*rawdb.ChunkBlockRange
+	L1Log            *types.Log // the CommitBatch event
+}
+
+// BatchFinalizeInfo describes a batch that has been validated and stored as finalized.
+type BatchFinalizeInfo struct {
+	BatchIndex       uint64
+	Meta             *rawdb.FinalizedBatchMeta
+	StartBlockNumber uint64
+	EndBlockNumber   uint64
+	Chunks           []*Chunk   // local blocks of the batch
+	L1Log            *types.Log // the FinalizeBatch or FinalizeBundle event
+}
+
+// BatchRevertInfo describes a batch whose RevertBatch event has been processed.
+type BatchRevertInfo struct {
+	BatchIndex uint64
+	BatchHash  common.Hash
+	L1Log      *types.Log // the RevertBatch event
+}
+
+// BatchHook is invoked synchronously by the rollup sync service after the batch
+// metadata of an event has been stored. If a hook fails, processing of the fetched
+// L1 range stops and is retried in the next sync round, so hooks are called at least
+// once for every event and must be idempotent.
+type BatchHook interface {
+	OnBatchCommitted(info *BatchCommitInfo) error
+	OnBatchFinalized(info *BatchFinalizeInfo) error
+	OnBatchReverted(info *BatchRevertInfo) error
+}
+
+// NoopBatchHook implements BatchHook without any behavior. It can be embedded
+// by hooks that only handle some of the events.
+type NoopBatchHook struct{}
+
+func (NoopBatchHook) OnBatchCommitted(*BatchCommitInfo) error   { return nil }
+func (NoopBatchHook) OnBatchFinalized(*BatchFinalizeInfo) error { return nil }
+func (NoopBatchHook) OnBatchReverted(*BatchRevertInfo) error    { return nil }
+
+type namedBatchHook struct {
+	name string
+	hook BatchHook
+}
+
+var (
+	batchHooksLock sync.RWMutex
+	batchHooks     []namedBatchHook
+)
+
+// RegisterBatchHook adds a hook that is invoked on batch events, typically from
+// an init function of a package compiled into the node. Hooks are invoked in the
+// order they are registered. It panics if a hook is already registered under the name.
+func RegisterBatchHook(name string, hook BatchHook) {
+	batchHooksLock.Lock()
+	defer batchHooksLock.Unlock()
+
+	for _, h := range batchHooks {
+		if h.name == name {
+			panic(fmt.Sprintf("batch hook %q already registered", name))
+		}
+	}
+	batchHooks = append(batchHooks, namedBatchHook{name: name, hook: hook})
+}
+
+// runBatchHooks calls fn for every registered hook and stops at the first error.
+func runBatchHooks(event string, batchIndex uint64, fn func(hook BatchHook) error) error {
+	batchHooksLock.RLock()
+	hooks := batchHooks
+	batchHooksLock.RUnlock()
+
+	for _, h := range hooks {
+		if err := fn(h.hook); err != nil {
+			return fmt.Errorf("batch hook %q failed on %v event, batch index: %v, err: %w", h.name, event, batchIndex, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,86 @@
+package rollup_sync_service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/scroll-tech/go-ethereum/common"
+	"github.com/scroll-tech/go-ethereum/core/rawdb"
+	"github.com/scroll-tech/go-ethereum/core/types"
+
+	"github.com/scroll-tech/go-ethereum/rollup/eventbus"
+)
+
+// recordingBatchHook records the batch indices of the events it is called on.
+type recordingBatchHook struct {
+	NoopBatchHook
+	committed []uint64
+	finalized []uint64
+	err       error
+}
+
+func (h *recordingBatchHook) OnBatchCommitted(info *BatchCommitInfo) error {
+	h.committed = append(h.committed, info.BatchIndex)
+	return h.err
+}
+
+func (h *recordingBatchHook) OnBatchFinalized(info *BatchFinalizeInfo) error {
+	h.finalized = append(h.finalized, info.BatchIndex)
+	return h.err
+}
+
+func TestBatchHooks(t *testing.T) {
+	saved := batchHooks
+	t.Cleanup(func() { batchHooks = saved })
+	batchHooks = nil
+
+	hook := &recordingBatchHook{}
+	RegisterBatchHook("recorder", hook)
+	assert.Panics(t, func() { RegisterBatchHook("recorder", hook) })
+
+	scrollChainABI, err := scrollChainMetaData.GetAbi()
+	require.NoError(t, err)
+	db := rawdb.NewMemoryDatabase()
+	service := &RollupSyncService{
+		ctx:                         context.Background(),
+		db:                          db,
+		bus:                         eventbus.New(),
+		scrollChainABI:              scrollChainABI,
+		l1CommitBatchEventSignature: scrollChainABI.Events["CommitBatch"].ID,
+	}
+
+	// the genesis batch is committed without fetching its L1 transaction
+	commitLog := &types.Log{Topics: []common.Hash{service.l1CommitBatchEventSignature, {}, {0x01}}}
+	require.NoError(t, service.handleCommitBatch(commitLog, false))
+	assert.Equal(t, []uint64{0}, hook.committed)
+
+	templateBlockTrace, err := os.ReadFile("./testdata/blockTrace_02.json")
+	require.NoError(t, err)
+	wrappedBlock := &WrappedBlock{}
+	require.NoError(t, json.Unmarshal(templateBlockTrace, wrappedBlock))
+	chunks := []*Chunk{{Blocks: []*WrappedBlock{wrappedBlock}}}
+	endBlock := wrappedBlock.Header.Number.Uint64()
+	require.NoError(t, service.writeFinalizedBatch(0, endBlock, &rawdb.FinalizedBatchMeta{}, chunks, &types.Log{BlockNumber: 10}, nil))
+	assert.Equal(t, []uint64{0}, hook.finalized)
+
+	// a failing hook fails the event, the metadata is stored regardless
+	hook.err = errors.New("archive unavailable")
+	err = service.writeFinalizedBatch(1, endBlock, &rawdb.FinalizedBatchMeta{}, chunks, &types.Log{BlockNumber: 11}, nil)
+	assert.ErrorContains(t, err, `batch hook "recorder" failed on finalize event, batch index: 1`)
+	assert.NotNil(t, rawdb.ReadFinalizedBatchMeta(db, 1))
+	assert.Equal(t, uint64(11), rawdb.ReadBatchL1Meta(db, 1).FinalizeL1BlockNumber)
+	assert.Equal(t, common.Hash{}, rawdb.ReadBatchL1Meta(db, 1).FinalizedStateRoot)
+
+	// the roots of the finalize event are recorded
+	hook.err = nil
+	event := &L1FinalizeBatchEvent{StateRoot: common.Hash{0x02}, WithdrawRoot: common.Hash{0x03}}
+	require.NoError(t, service.writeFinalizedBatch(1, endBlock, &rawdb.FinalizedBatchMeta{}, chunks, &types.Log{BlockNumber: 12}, event))
+	assert.Equal(t, common.Hash{0x02}, rawdb.ReadBatchL1Meta(db, 1).FinalizedStateRoot)
+	assert.Equal(t, common.Hash{0x03}, rawdb.ReadBatchL1Meta(db, 1).FinalizedWithdrawRoot)
+}
@@ -0,0 +1,199 @@
+package rollup_sync_service
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/scroll-tech/go-ethereum/common"
+	"github.com/scroll-tech/go-ethereum/common/hexutil"
+	"github.com/scroll-tech/go-ethereum/core/rawdb"
+	"github.com/scroll-tech/go-ethereum/crypto"
+)
+
+// TxInclusionProof shows that a transaction is part of a batch finalized on L1. It is
+// checked without a full node as follows:
+//   - BatchHeader hashes to BatchHash, the batch hash of the FinalizeBatch event,
+//   - the concatenation of ChunkHashes hashes to the data hash of BatchHeader (bytes 25 to 57),
+//   - ChunkData hashes to ChunkHashes[ChunkIndex], and
+//   - TxHash is found in ChunkData at TxHashOffset, after the block contexts of the chunk.
+//
+// ChunkData starts with the 58-byte contexts of the blocks of the chunk, the context of
+// the transaction's block is at BlockIndex and starts with its block number.
+//
+// Batches committed with a blob only hash the L1 messages into their chunk hashes. The L2
+// transactions of batch versions 3 and above are proven through the blob data proof instead:
+//   - BlobChallengePreimage hashes, modulo the BLS modulus, to the evaluation point z of
+//     BatchHeader (bytes 129 to 161),
+//   - BlobChunkData hashes to the chunk data hash at slot ChunkIndex+1 of BlobChallengePreimage, and
+//   - the transaction encoding at TxOffset in BlobChunkData, TxLength bytes long, hashes to TxHash.
+type TxInclusionProof struct {
+	TxHash       common.Hash    `json:"txHash"`
+	BlockNumber  uint64         `json:"blockNumber"`
+	BlockHash    common.Hash    `json:"blockHash"`
+	BatchIndex   uint64         `json:"batchIndex"`
+	BatchHash    common.Hash    `json:"batchHash"`
+	BatchHeader  hexutil.Bytes  `json:"batchHeader"`
+	ChunkHashes  []common.Hash  `json:"chunkHashes"`
+	ChunkIndex   uint64         `json:"chunkIndex"` // index of the chunk containing the block in the batch
+	BlockIndex   uint64         `json:"blockIndex"` // index of the block in the chunk
+	ChunkData    hexutil.Bytes  `json:"chunkData"`  // preimage of the chunk hash
+	TxHashOffset hexutil.Uint64 `json:"txHashOffset"`
+
+	BlobChallengePreimage hexutil.Bytes  `json:"blobChallengePreimage,omitempty"`
+	BlobChunkData         hexutil.Bytes  `json:"blobChunkData,omitempty"`
+	TxOffset              hexutil.Uint64 `json:"txOffset,omitempty"`
+	TxLength              hexutil.Uint64 `json:"txLength,omitempty"`
+}
+
+// TxInclusionProof builds the inclusion proof of a transaction in its finalized batch from
+// the local chain. It fails if the transaction is unknown, its batch is not finalized yet,
+// or the batch recomputed from the local blocks does not match the batch finalized on L1.
+func (s *RollupSyncService) TxInclusionProof(txHash common.Hash) (*TxInclusionProof, error) {
+	tx, blockHash, blockNumber, _ := rawdb.ReadTransaction(s.db, txHash)
+	if tx == nil {
+		return nil, fmt.Errorf("transaction %v not found", txHash.Hex())
+	}
+	batchIndex := rawdb.ReadBatchIndexByL2BlockNumber(s.db, blockNumber)
+	if batchIndex == nil {
+		return nil, fmt.Errorf("block %v is not part of a committed batch yet", blockNumber)
+	}
+	meta := rawdb.ReadFinalizedBatchMeta(s.db, *batchIndex)
+	if meta == nil {
+		return nil, fmt.Errorf("batch %v is not finalized", *batchIndex)
+	}
+	chunkBlockRanges := rawdb.ReadBatchChunkRanges(s.db, *batchIndex)
+	if len(chunkBlockRanges) == 0 || chunkBlockRanges[0].StartBlockNumber > blockNumber {
+		return nil, fmt.Errorf("block %v is not part of a committed batch yet", blockNumber)
+	}
+	parentBatchMeta := &rawdb.FinalizedBatchMeta{}
+	if *batchIndex > 0 {
+		if parentBatchMeta = rawdb.ReadFinalizedBatchMeta(s.db, *batchIndex-1); parentBatchMeta == nil {
+			return nil, fmt.Errorf("parent batch %v is not finalized", *batchIndex-1)
+		}
+	}
+	codec, err := s.codecForBatch(*batchIndex)
+	if err != nil {
+		return nil, err
+	}
+	blobCodec, isBlobCodec := codec.(*blobCodec)
+	if codec.Version() != batchHeaderVersion && !isBlobCodec {
+		return nil, fmt.Errorf("inclusion proofs are not supported for batch version %v", codec.Version())
+	}
+
+	if err := s.checkBlockAvailability(*batchIndex, chunkBlockRanges); err != nil {
+		return nil, err
+	}
+	chunks, err := s.loadChunks(chunkBlockRanges)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load local blocks, batch index: %v, err: %w", *batchIndex, err)
+	}
+	batchHeader, err := encodeBatchHeader(codec, *batchIndex, parentBatchMeta, chunks)
+	if err != nil {
+		return nil, err
+	}
+	if crypto.Keccak256Hash(batchHeader) != meta.BatchHash {
+		return nil, fmt.Errorf("%w: local blocks of batch %v do not match its finalized batch hash", errBatchMismatch, *batchIndex)
+	}
+
+	proof := &TxInclusionProof{
+		TxHash:      txHash,
+		BlockNumber: blockNumber,
+		BlockHash:   blockHash,
+		BatchIndex:  *batchIndex,
+		BatchHash:   meta.BatchHash,
+		BatchHeader: batchHeader,
+	}
+	totalL1MessagePopped := parentBatchMeta.TotalL1MessagePopped
+	found := false
+	for chunkIndex, chunk := range chunks {
+		var preimage []byte
+		if isBlobCodec {
+			preimage, err = blobCodec.chunkHashPreimage(chunk, totalL1MessagePopped)
+		} else {
+			preimage, err = chunk.hashPreimage(totalL1MessagePopped)
+		}
+		if err != nil {
+			return nil, err
+		}
+		proof.ChunkHashes = append(proof.ChunkHashes, crypto.Keccak256Hash(preimage))
+
+		for blockIndex, block := range chunk.Blocks {
+			if block.Header.Number.Uint64() != blockNumber {
+				continue
+			}
+			proof.ChunkIndex = uint64(chunkIndex)
+			proof.BlockIndex = uint64(blockIndex)
+			proof.ChunkData = preimage
+			found = true
+			if offset := findTxHash(preimage, len(chunk.Blocks), txHash); offset >= 0 {
+				proof.TxHashOffset = hexutil.Uint64(offset)
+			} else if !isBlobCodec || tx.IsL1MessageTx() {
+				return nil, fmt.Errorf("transaction %v not found in chunk %v of batch %v", txHash.Hex(), chunkIndex, *batchIndex)
+			} else if err := blobCodec.proveBlobInclusion(proof, chunks); err != nil {
+				return nil, err
+			}
+		}
+		totalL1MessagePopped += chunk.NumL1Messages(totalL1MessagePopped)
+	}
+	if !found {
+		return nil, fmt.Errorf("block %v not found in batch %v", blockNumber, *batchIndex)
+	}
+	return proof, nil
+}
+
+// encodeBatchHeader encodes the header of a batch of local blocks with the codec of its batch version.
+func encodeBatchHeader(codec Codec, batchIndex uint64, parentBatchMeta *rawdb.FinalizedBatchMeta, chunks []*Chunk) ([]byte, error) {
+	if blobCodec, ok := codec.(*blobCodec); ok {
+		return blobCodec.encodeBatchHeader(batchIndex, parentBatchMeta.TotalL1MessagePopped, parentBatchMeta.BatchHash, chunks)
+	}
+	header, err := NewBatchHeader(batchHeaderVersion, batchIndex, parentBatchMeta.TotalL1MessagePopped, parentBatchMeta.BatchHash, chunks)
+	if err != nil {
+		return nil, err
+	}
+	return header.Encode(), nil
+}
+
+// proveBlobInclusion adds to proof the blob data of its chunk and the preimage of the evaluation
+// point of the blob data proof, which together show that the L2 transaction is stored in the blob.
+// Batch versions without a blob data proof only bind the blob through its KZG commitment, so their
+// L2 transactions cannot be proven this way.
+func (c *blobCodec) proveBlobInclusion(proof *TxInclusionProof, chunks []*Chunk) error {
+	if !c.blobDataProof {
+		return fmt.Errorf("inclusion proofs of L2 transactions are not supported for batch version %v", c.version)
+	}
+	payload, err := c.payload(chunks)
+	if err != nil {
+		return err
+	}
+	chunkData, err := c.chunkData(payload)
+	if err != nil {
+		return err
+	}
+	data := chunkData[proof.ChunkIndex]
+	for offset := 0; offset < len(data); {
+		size, err := txEncodingSize(data[offset:])
+		if err != nil {
+			return err
+		}
+		if crypto.Keccak256Hash(data[offset:offset+size]) == proof.TxHash {
+			proof.BlobChallengePreimage = c.challengePreimage(payload, chunkData, c.blob.VersionedHash)
+			proof.BlobChunkData = data
+			proof.TxOffset = hexutil.Uint64(offset)
+			proof.TxLength = hexutil.Uint64(size)
+			return nil
+		}
+		offset += size
+	}
+	return fmt.Errorf("transaction %v not found in the blob data of chunk %v of batch %v", proof.TxHash.Hex(), proof.ChunkIndex, proof.BatchIndex)
+}
+
+// findTxHash returns the offset of txHash in the transaction hashes of a chunk hash
+// preimage with numBlocks block contexts, or -1 if it is not found.
+func findTxHash(preimage []byte, numBlocks int, txHash common.Hash) int {
+	for offset := numBlocks * blockContextHashByteSize; offset+common.HashLength <= len(preimage); offset += common.HashLength {
+		if bytes.Equal(preimage[offset:offset+common.HashLength], txHash[:]) {
+			return offset
+		}
+	}
+	return -1
+}
@@ -0,0 +1,152 @@
+package rollup_sync_service
+
+import (
+	"context"
+	"encoding/binary"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/scroll-tech/go-ethereum/common"
+	"github.com/scroll-tech/go-ethereum/consensus/ethash"
+	"github.com/scroll-tech/go-ethereum/core"
+	"github.com/scroll-tech/go-ethereum/core/rawdb"
+	"github.com/scroll-tech/go-ethereum/core/types"
+	"github.com/scroll-tech/go-ethereum/core/vm"
+	"github.com/scroll-tech/go-ethereum/crypto"
+	"github.com/scroll-tech/go-ethereum/params"
+)
+
+// newInclusionProofChain returns a service over a chain of 5 blocks, the fourth block holds
+// the 2 returned transactions.
+func newInclusionProofChain(t *testing.T) (*RollupSyncService, []*types.Block, []*types.Transaction) {
+	key, _ := crypto.GenerateKey()
+	sender := crypto.PubkeyToAddress(key.PublicKey)
+	signer := types.LatestSigner(params.TestChainConfig)
+
+	db := rawdb.NewMemoryDatabase()
+	gspec := &core.Genesis{Config: params.TestChainConfig, Alloc: core.GenesisAlloc{sender: {Balance: big.NewInt(1e18)}}}
+	genesis := gspec.MustCommit(db)
+	gendb := rawdb.NewMemoryDatabase()
+	gspec.MustCommit(gendb)
+	var txs []*types.Transaction
+	blocks, _ := core.GenerateChain(params.TestChainConfig, genesis, ethash.NewFaker(), gendb, 5, func(i int, b *core.BlockGen) {
+		b.SetCoinbase(common.Address{byte(i + 1)})
+		if i == 3 {
+			for nonce := uint64(0); nonce < 2; nonce++ {
+				tx, err := types.SignTx(types.NewTransaction(nonce, common.Address{0x01}, big.NewInt(1), params.TxGas, b.BaseFee(), nil), signer, key)
+				require.NoError(t, err)
+				b.AddTx(tx)
+				txs = append(txs, tx)
+			}
+		}
+	})
+	bc, err := core.NewBlockChain(db, nil, params.TestChainConfig, ethash.NewFaker(), vm.Config{}, nil, nil)
+	require.NoError(t, err)
+	t.Cleanup(bc.Stop)
+	_, err = bc.InsertChain(blocks)
+	require.NoError(t, err)
+	return &RollupSyncService{ctx: context.Background(), db: db, bc: bc}, blocks, txs
+}
+
+func TestTxInclusionProof(t *testing.T) {
+	service, blocks, txs := newInclusionProofChain(t)
+	db := service.db
+	_, err := service.TxInclusionProof(txs[1].Hash())
+	assert.ErrorContains(t, err, "not part of a committed batch")
+
+	chunkBlockRanges := []*rawdb.ChunkBlockRange{{StartBlockNumber: 1, EndBlockNumber: 2}, {StartBlockNumber: 3, EndBlockNumber: 5}}
+	rawdb.WriteBatchChunkRanges(db, 1, chunkBlockRanges)
+	rawdb.WriteBatchEndBlock(db, 5, 1)
+	rawdb.WriteFinalizedBatchMeta(db, 0, &rawdb.FinalizedBatchMeta{})
+	_, err = service.TxInclusionProof(txs[1].Hash())
+	assert.ErrorContains(t, err, "not finalized")
+
+	chunks, err := service.loadChunks(chunkBlockRanges)
+	require.NoError(t, err)
+	_, meta, err := computeFinalizedBatchMeta(codecV0{}, 1, &rawdb.FinalizedBatchMeta{}, chunks)
+	require.NoError(t, err)
+	rawdb.WriteFinalizedBatchMeta(db, 1, meta)
+
+	proof, err := service.TxInclusionProof(txs[1].Hash())
+	require.NoError(t, err)
+	assert.Equal(t, uint64(4), proof.BlockNumber)
+	assert.Equal(t, blocks[3].Hash(), proof.BlockHash)
+	assert.Equal(t, uint64(1), proof.BatchIndex)
+	assert.Equal(t, uint64(1), proof.ChunkIndex)
+	assert.Equal(t, uint64(1), proof.BlockIndex)
+
+	// check the proof the way an external verifier does
+	assert.Equal(t, meta.BatchHash, crypto.Keccak256Hash(proof.BatchHeader))
+	var chunkHashes []byte
+	for _, hash := range proof.ChunkHashes {
+		chunkHashes = append(chunkHashes, hash.Bytes()...)
+	}
+	assert.Equal(t, crypto.Keccak256(chunkHashes), []byte(proof.BatchHeader[25:57]))
+	assert.Equal(t, proof.ChunkHashes[proof.ChunkIndex], crypto.Keccak256Hash(proof.ChunkData))
+	blockContext := proof.ChunkData[proof.BlockIndex*blockContextHashByteSize:]
+	assert.Equal(t, proof.BlockNumber, binary.BigEndian.Uint64(blockContext[:8]))
+	offset := int(proof.TxHashOffset)
+	assert.Equal(t, txs[1].Hash().Bytes(), []byte(proof.ChunkData[offset:offset+common.HashLength]))
+
+	// the proof is refused if the local blocks do not match the finalized batch
+	corrupted := *meta
+	corrupted.BatchHash = common.Hash{0x01}
+	rawdb.WriteFinalizedBatchMeta(db, 1, &corrupted)
+	_, err = service.TxInclusionProof(txs[1].Hash())
+	assert.ErrorIs(t, err, errBatchMismatch)
+
+	_, err = service.TxInclusionProof(common.Hash{0x01})
+	assert.ErrorContains(t, err, "not found")
+}
+
+func TestTxInclusionProofBlob(t *testing.T) {
+	service, _, txs := newInclusionProofChain(t)
+	db := service.db
+	chunkBlockRanges := []*rawdb.ChunkBlockRange{{StartBlockNumber: 1, EndBlockNumber: 2}, {StartBlockNumber: 3, EndBlockNumber: 5}}
+	rawdb.WriteBatchChunkRanges(db, 1, chunkBlockRanges)
+	rawdb.WriteBatchEndBlock(db, 5, 1)
+	rawdb.WriteFinalizedBatchMeta(db, 0, &rawdb.FinalizedBatchMeta{})
+	chunks, err := service.loadChunks(chunkBlockRanges)
+	require.NoError(t, err)
+
+	// the claim of the blob data proof is not checked, only hashed into the batch header
+	blob := &BatchBlob{VersionedHash: common.Hash{0x01, 0x02}, DataProof: make([]byte, 64)}
+	blob.DataProof[63] = 0x03
+	l1Meta := &rawdb.BatchL1Meta{CodecVersion: codecV3.Version(), BlobVersionedHash: blob.VersionedHash, BlobDataProof: blob.DataProof}
+	rawdb.WriteBatchL1Meta(db, 1, l1Meta)
+	_, meta, err := computeFinalizedBatchMeta(codecV3.WithBlob(blob), 1, &rawdb.FinalizedBatchMeta{}, chunks)
+	require.NoError(t, err)
+	rawdb.WriteFinalizedBatchMeta(db, 1, meta)
+
+	proof, err := service.TxInclusionProof(txs[1].Hash())
+	require.NoError(t, err)
+	assert.Equal(t, uint64(1), proof.ChunkIndex)
+	assert.Equal(t, uint64(1), proof.BlockIndex)
+
+	// check the proof the way an external verifier does
+	assert.Equal(t, meta.BatchHash, crypto.Keccak256Hash(proof.BatchHeader))
+	var chunkHashes []byte
+	for _, hash := range proof.ChunkHashes {
+		chunkHashes = append(chunkHashes, hash.Bytes()...)
+	}
+	assert.Equal(t, crypto.Keccak256(chunkHashes), []byte(proof.BatchHeader[25:57]))
+	assert.Equal(t, proof.ChunkHashes[proof.ChunkIndex], crypto.Keccak256Hash(proof.ChunkData))
+	z := new(big.Int).Mod(new(big.Int).SetBytes(crypto.Keccak256(proof.BlobChallengePreimage)), blsModulus)
+	assert.Equal(t, common.BigToHash(z).Bytes(), []byte(proof.BatchHeader[129:161]))
+	slot := proof.BlobChallengePreimage[32*(1+proof.ChunkIndex):]
+	assert.Equal(t, crypto.Keccak256(proof.BlobChunkData), []byte(slot[:32]))
+	txData := proof.BlobChunkData[proof.TxOffset : proof.TxOffset+proof.TxLength]
+	assert.Equal(t, txs[1].Hash(), crypto.Keccak256Hash(txData))
+
+	// batch versions without a blob data proof do not bind the L2 transactions to the batch hash
+	l1Meta = &rawdb.BatchL1Meta{CodecVersion: codecV2.Version(), BlobVersionedHash: blob.VersionedHash}
+	rawdb.WriteBatchL1Meta(db, 1, l1Meta)
+	_, meta, err = computeFinalizedBatchMeta(codecV2.WithBlob(blob), 1, &rawdb.FinalizedBatchMeta{}, chunks)
+	require.NoError(t, err)
+	rawdb.WriteFinalizedBatchMeta(db, 1, meta)
+	_, err = service.TxInclusionProof(txs[1].Hash())
+	assert.ErrorContains(t, err, "not supported for batch version 2")
+}
@@ -0,0 +1,102 @@
+package rollup_sync_service
+
+import (
+	"sync/atomic"
+	"time"
+
+	"github.com/scroll-tech/go-ethereum/common"
+	"github.com/scroll-tech/go-ethereum/core/rawdb"
+	"github.com/scroll-tech/go-ethereum/log"
+	"github.com/scroll-tech/go-ethereum/metrics"
+)
+
+var l1FinalizedStalenessGauge = metrics.NewRegisteredGauge("rollup/sync/l1/finalized/staleness", nil)
+
+// L1FinalizedStatus reports the latest finalized L1 block observed by the rollup sync service
+// and how long ago it was first observed. The L1 chain finalizes a block every few minutes,
+// a growing staleness means that the L1 endpoint or the L1 chain itself stalled.
+type L1FinalizedStatus struct {
+	Number           uint64 `json:"number"`
+	ObservedAt       uint64 `json:"observedAt"` // unix time in seconds
+	StalenessSeconds uint64 `json:"stalenessSeconds"`
+	// Stale is set if the staleness exceeds the configured bound, in which case the finalized
+	// L2 blocks are not advertised.
+	Stale bool `json:"stale"`
+}
+
+// observeL1Finalized records the latest finalized L1 block reported by the L1 endpoint. The
+// observation time only changes when the finalized block advances, so that an endpoint that
+// keeps reporting the same block is detected as stale.
+func (s *RollupSyncService) observeL1Finalized(number uint64, now time.Time) {
+	if finalized := rawdb.ReadL1FinalizedBlock(s.db); finalized != nil && number <= finalized.Number {
+		return
+	}
+	rawdb.WriteL1FinalizedBlock(s.db, &rawdb.L1FinalizedBlock{Number: number, ObservedAt: uint64(now.Unix())})
+}
+
+// refreshL1Finalized fetches the latest finalized L1 block, latestConfirmed is the latest
+// confirmed block of the fetch round, which is the finalized block if no confirmations are configured.
+func (s *RollupSyncService) refreshL1Finalized(latestConfirmed uint64) {
+	number := latestConfirmed
+	if s.confirmations > 0 {
+		var err error
+		if number, err = s.client.getLatestFinalizedBlockNumber(s.ctx); err != nil {
+			log.Debug("Failed to get latest finalized L1 block number", "err", err)
+			return
+		}
+	}
+	now := time.Now()
+	s.observeL1Finalized(number, now)
+	if status := s.l1FinalizedStatus(now); status != nil {
+		l1FinalizedStalenessGauge.Update(int64(status.StalenessSeconds))
+	}
+}
+
+// l1FinalizedStatus returns the status of the latest observed finalized L1 block at the given time,
+// or nil if no finalized L1 block was observed yet.
+func (s *RollupSyncService) l1FinalizedStatus(now time.Time) *L1FinalizedStatus {
+	finalized := rawdb.ReadL1FinalizedBlock(s.db)
+	if finalized == nil {
+		return nil
+	}
+	status := &L1FinalizedStatus{Number: finalized.Number, ObservedAt: finalized.ObservedAt}
+	if observedAt := time.Unix(int64(finalized.ObservedAt), 0); now.After(observedAt) {
+		status.StalenessSeconds = uint64(now.Sub(observedAt) / time.Second)
+	}
+	status.Stale = s.maxL1FinalizedStaleness > 0 && time.Duration(status.StalenessSeconds)*time.Second > s.maxL1FinalizedStaleness
+	return status
+}
+
+// L1Finalized returns the status of the latest observed finalized L1 block, or nil if no
+// finalized L1 block was observed yet.
+func (s *RollupSyncService) L1Finalized() *L1FinalizedStatus {
+	return s.l1FinalizedStatus(time.Now())
+}
+
+// l1FinalizedStale reports whether the finalized L2 blocks must not be advertised, because
+// the L1 view they were derived from is older than the configured bound. It is never stale
+// if no bound is configured.
+func (s *RollupSyncService) l1FinalizedStale() bool {
+	if s.maxL1FinalizedStaleness == 0 {
+		return false
+	}
+	status := s.L1Finalized()
+	stale := status == nil || status.Stale
+
+	var flag int32
+	if stale {
+		flag = 1
+	}
+	if atomic.SwapInt32(&s.l1FinalizedStaleFlag, flag) != flag {
+		if stale {
+			ctx := []interface{}{"bound", common.PrettyDuration(s.maxL1FinalizedStaleness)}
+			if status != nil {
+				ctx = append(ctx, "l1Finalized", status.Number, "staleness", common.PrettyDuration(time.Duration(status.StalenessSeconds)*time.Second))
+			}
+			log.Warn("L1 finalized block is stale, not advertising finalized L2 blocks", ctx...)
+		} else {
+			log.Info("L1 finalized block advanced, advertising finalized L2 blocks again", "l1Finalized", status.Number)
+		}
+	}
+	return stale
+}
@@ -0,0 +1,48 @@
+package rollup_sync_service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/scroll-tech/go-ethereum/core/rawdb"
+)
+
+func TestL1FinalizedStaleness(t *testing.T) {
+	db := rawdb.NewMemoryDatabase()
+	service := &RollupSyncService{db: db}
+	now := time.Now()
+
+	// without a bound the finalized block is never stale
+	assert.Nil(t, service.l1FinalizedStatus(now))
+	assert.False(t, service.l1FinalizedStale())
+	service.observeL1Finalized(100, now.Add(-time.Hour))
+	status := service.l1FinalizedStatus(now)
+	require.NotNil(t, status)
+	assert.Equal(t, uint64(100), status.Number)
+	assert.Equal(t, uint64(3600), status.StalenessSeconds)
+	assert.False(t, status.Stale)
+	assert.False(t, service.l1FinalizedStale())
+
+	service.maxL1FinalizedStaleness = 10 * time.Minute
+	assert.True(t, service.l1FinalizedStatus(now).Stale)
+	assert.True(t, service.l1FinalizedStale())
+
+	// reporting the same or an older block does not refresh the observation
+	service.observeL1Finalized(100, now)
+	service.observeL1Finalized(90, now)
+	assert.True(t, service.l1FinalizedStale())
+
+	service.observeL1Finalized(132, now.Add(-time.Minute))
+	status = service.l1FinalizedStatus(now)
+	assert.Equal(t, uint64(132), status.Number)
+	assert.Equal(t, uint64(60), status.StalenessSeconds)
+	assert.False(t, status.Stale)
+	assert.False(t, service.l1FinalizedStale())
+
+	// no observation at all is stale if a bound is configured
+	service.db = rawdb.NewMemoryDatabase()
+	assert.True(t, service.l1FinalizedStale())
+}
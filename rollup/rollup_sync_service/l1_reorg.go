@@ -0,0 +1,144 @@
+package rollup_sync_service
+
+import (
+	"errors"
+	"fmt"
+	"math"
+
+	"github.com/scroll-tech/go-ethereum"
+	"github.com/scroll-tech/go-ethereum/common"
+	"github.com/scroll-tech/go-ethereum/core/rawdb"
+	"github.com/scroll-tech/go-ethereum/core/types"
+	"github.com/scroll-tech/go-ethereum/log"
+
+	"github.com/scroll-tech/go-ethereum/rollup/eventbus"
+)
+
+// newCheckpoint records the L1 block hash of the end of the range [.., to] along with
+// the batches committed and finalized in the range, so that they can be rolled back on reorg.
+// It must be created before the range is processed, as it captures the finalized L2 block
+// number the range started from.
+func (s *RollupSyncService) newCheckpoint(logs []types.Log, to uint64) (*rawdb.RollupSyncCheckpoint, error) {
+	hash, err := s.client.getBlockHash(s.ctx, to)
+	if err != nil {
+		return nil, err
+	}
+	checkpoint := &rawdb.RollupSyncCheckpoint{
+		L1BlockNumber:    to,
+		L1BlockHash:      hash,
+		CommittedBatches: []uint64{},
+		FinalizedBatches: []uint64{},
+	}
+	if finalized := rawdb.ReadFinalizedL2BlockNumber(s.db); finalized != nil {
+		checkpoint.FinalizedL2BlockNumber = *finalized
+	}
+	for _, vLog := range logs {
+		// batch index is the first indexed argument of CommitBatch, FinalizeBatch and FinalizeBundle
+		if len(vLog.Topics) < 2 {
+			continue
+		}
+		batchIndex := vLog.Topics[1].Big().Uint64()
+		switch vLog.Topics[0] {
+		case s.l1CommitBatchEventSignature:
+			checkpoint.CommittedBatches = append(checkpoint.CommittedBatches, batchIndex)
+		case s.l1FinalizeBatchEventSignature:
+			checkpoint.FinalizedBatches = append(checkpoint.FinalizedBatches, batchIndex)
+		case s.l1FinalizeBundleEventSignature:
+			if len(vLog.Topics) < 3 {
+				continue
+			}
+			for i, end := batchIndex, vLog.Topics[2].Big().Uint64(); i <= end; i++ {
+				checkpoint.FinalizedBatches = append(checkpoint.FinalizedBatches, i)
+			}
+		}
+	}
+	return checkpoint, nil
+}
+
+// handleL1Reorg compares the stored checkpoints against the canonical L1 chain. On mismatch it rolls
+// back the batch updates of all reorged ranges and resets the sync progress to the newest checkpoint
+// that is still canonical. Checkpoints at or below the L1 finalized block are pruned, except for the newest one.
+func (s *RollupSyncService) handleL1Reorg() error {
+	checkpoints := rawdb.ReadRollupSyncCheckpoints(s.db)
+	if len(checkpoints) == 0 {
+		return nil
+	}
+
+	for i := len(checkpoints) - 1; i >= 0; i-- {
+		hash, err := s.client.getBlockHash(s.ctx, checkpoints[i].L1BlockNumber)
+		if err != nil && !errors.Is(err, ethereum.NotFound) {
+			return fmt.Errorf("failed to get L1 block hash, number: %v, err: %w", checkpoints[i].L1BlockNumber, err)
+		}
+		if err == nil && hash == checkpoints[i].L1BlockHash {
+			if i < len(checkpoints)-1 {
+				s.rollbackToCheckpoint(checkpoints[i], checkpoints[i+1:])
+			}
+			return s.pruneCheckpoints(checkpoints[:i+1])
+		}
+	}
+	return fmt.Errorf("L1 reorg deeper than the oldest checkpoint at L1 block %v", checkpoints[0].L1BlockNumber)
+}
+
+// rollbackToCheckpoint undoes the batch updates of the given reorged checkpoints.
+// Note: batches reverted in the reorged ranges cannot be restored, and an enforced batch mode
+// update in the reorged ranges is dropped until the event is processed again.
+func (s *RollupSyncService) rollbackToCheckpoint(checkpoint *rawdb.RollupSyncCheckpoint, reorged []*rawdb.RollupSyncCheckpoint) {
+	l1ReorgCounter.Inc(1)
+	log.Warn("L1 reorg detected, rolling back rollup events", "checkpoint", checkpoint.L1BlockNumber, "reorged ranges", len(reorged), "latest processed block", s.latestProcessedBlock)
+
+	// undo the newest changes first
+	var committedFrom, committedTo uint64 = math.MaxUint64, 0
+	for i := len(reorged) - 1; i >= 0; i-- {
+		cp := reorged[i]
+		for _, batchIndex := range cp.FinalizedBatches {
+			s.deleteFinalizedBatchMeta(batchIndex)
+			rawdb.DeletePoisonedBatch(s.db, batchIndex)
+			if batchL1Meta := rawdb.ReadBatchL1Meta(s.db, batchIndex); batchL1Meta != nil {
+				rawdb.DeleteBatchL1Block(s.db, batchL1Meta.FinalizeL1BlockNumber, rawdb.BatchFinalizedL1Event, batchIndex)
+				batchL1Meta.FinalizeTxHash = common.Hash{}
+				batchL1Meta.FinalizeL1BlockNumber = 0
+				batchL1Meta.FinalizeL1BlockHash = common.Hash{}
+				batchL1Meta.FinalizedStateRoot = common.Hash{}
+				batchL1Meta.FinalizedWithdrawRoot = common.Hash{}
+				rawdb.WriteBatchL1Meta(s.db, batchIndex, batchL1Meta)
+			}
+		}
+		for _, batchIndex := range cp.CommittedBatches {
+			if batchIndex < committedFrom {
+				committedFrom = batchIndex
+			}
+			if batchIndex > committedTo {
+				committedTo = batchIndex
+			}
+		}
+		rawdb.WriteFinalizedL2BlockNumber(s.db, cp.FinalizedL2BlockNumber)
+		rawdb.DeleteRollupSyncCheckpoint(s.db, cp.L1BlockNumber)
+	}
+	// batches are committed in order, so the ones committed in the reorged ranges are
+	// all batches from the lowest of them on and they are removed as one range
+	s.deleteBatchRange(committedFrom, committedTo)
+
+	if mode := rawdb.ReadEnforcedBatchMode(s.db); mode != nil && mode.L1BlockNumber > checkpoint.L1BlockNumber {
+		rawdb.DeleteEnforcedBatchMode(s.db)
+	}
+
+	rawdb.WriteRollupEventSyncedL1BlockNumber(s.db, checkpoint.L1BlockNumber)
+	s.bus.PublishL1Reorg(eventbus.L1ReorgEvent{
+		CommonAncestor:  checkpoint.L1BlockNumber,
+		RolledBackBlock: s.latestProcessedBlock,
+	})
+	s.latestProcessedBlock = checkpoint.L1BlockNumber
+	l1ProcessedBlockGauge.Update(int64(checkpoint.L1BlockNumber))
+}
+
+// pruneCheckpoints removes checkpoints that can no longer be reorged.
+func (s *RollupSyncService) pruneCheckpoints(checkpoints []*rawdb.RollupSyncCheckpoint) error {
+	finalized, err := s.client.getLatestFinalizedBlockNumber(s.ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get latest finalized L1 block number, err: %w", err)
+	}
+	for i := 0; i+1 < len(checkpoints) && checkpoints[i+1].L1BlockNumber <= finalized; i++ {
+		rawdb.DeleteRollupSyncCheckpoint(s.db, checkpoints[i].L1BlockNumber)
+	}
+	return nil
+}
@@ -0,0 +1,258 @@
+package rollup_sync_service
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/scroll-tech/go-ethereum"
+	"github.com/scroll-tech/go-ethereum/common"
+	"github.com/scroll-tech/go-ethereum/core/rawdb"
+	"github.com/scroll-tech/go-ethereum/core/types"
+	"github.com/scroll-tech/go-ethereum/event"
+
+	"github.com/scroll-tech/go-ethereum/rollup/eventbus"
+)
+
+// forkEthClient serves L1 headers of a chain whose blocks above forkBlock belong to fork.
+type forkEthClient struct {
+	mockEthClient
+	head      uint64
+	finalized uint64
+	forkBlock uint64
+	fork      byte
+}
+
+func (m *forkEthClient) BlockNumber(ctx context.Context) (uint64, error) {
+	return m.head, nil
+}
+
+func (m *forkEthClient) HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error) {
+	if number.Sign() < 0 {
+		number = new(big.Int).SetUint64(m.finalized)
+	}
+	header := &types.Header{Number: number}
+	if number.Uint64() > m.forkBlock {
+		header.Extra = []byte{m.fork}
+	}
+	return header, nil
+}
+
+func TestHandleL1Reorg(t *testing.T) {
+	scrollChainABI, err := scrollChainMetaData.GetAbi()
+	require.NoError(t, err)
+
+	db := rawdb.NewMemoryDatabase()
+	client := &forkEthClient{head: 400, finalized: 150, forkBlock: 1000}
+	service := &RollupSyncService{
+		ctx:                           context.Background(),
+		bus:                           eventbus.New(),
+		db:                            db,
+		client:                        &L1Client{ctx: context.Background(), client: client},
+		l1CommitBatchEventSignature:   scrollChainABI.Events["CommitBatch"].ID,
+		l1FinalizeBatchEventSignature: scrollChainABI.Events["FinalizeBatch"].ID,
+	}
+
+	commitLog := func(batchIndex int64) types.Log {
+		return types.Log{Topics: []common.Hash{service.l1CommitBatchEventSignature, common.BigToHash(big.NewInt(batchIndex))}}
+	}
+	finalizeLog := func(batchIndex int64) types.Log {
+		return types.Log{Topics: []common.Hash{service.l1FinalizeBatchEventSignature, common.BigToHash(big.NewInt(batchIndex))}}
+	}
+
+	writeCheckpoint := func(logs []types.Log, to uint64) {
+		checkpoint, err := service.newCheckpoint(logs, to)
+		require.NoError(t, err)
+		rawdb.WriteRollupSyncCheckpoint(db, checkpoint)
+	}
+
+	// range [101, 200]: batch 1 committed
+	writeCheckpoint([]types.Log{commitLog(1)}, 200)
+	rawdb.WriteBatchChunkRanges(db, 1, []*rawdb.ChunkBlockRange{{StartBlockNumber: 1, EndBlockNumber: 10}})
+
+	// range [201, 300]: batch 1 finalized, batch 2 committed
+	writeCheckpoint([]types.Log{finalizeLog(1), commitLog(2)}, 300)
+	rawdb.WriteFinalizedBatchMeta(db, 1, &rawdb.FinalizedBatchMeta{})
+	rawdb.WriteFinalizedL2BlockNumber(db, 10)
+	rawdb.WriteBatchChunkRanges(db, 2, []*rawdb.ChunkBlockRange{{StartBlockNumber: 11, EndBlockNumber: 20}})
+	service.latestProcessedBlock = 300
+
+	// no reorg
+	require.NoError(t, service.handleL1Reorg())
+	assert.Len(t, rawdb.ReadRollupSyncCheckpoints(db), 2)
+	assert.Equal(t, uint64(300), service.latestProcessedBlock)
+
+	// reorg of all blocks above 250
+	reorgCh := make(chan eventbus.L1ReorgEvent, 1)
+	reorgSub := service.bus.SubscribeL1Reorg(reorgCh)
+	defer reorgSub.Unsubscribe()
+	client.forkBlock, client.fork = 250, 1
+	require.NoError(t, service.handleL1Reorg())
+	assert.Equal(t, eventbus.L1ReorgEvent{CommonAncestor: 200, RolledBackBlock: 300}, <-reorgCh)
+	assert.Equal(t, uint64(200), service.latestProcessedBlock)
+	assert.Equal(t, uint64(200), *rawdb.ReadRollupEventSyncedL1BlockNumber(db))
+	assert.Nil(t, rawdb.ReadFinalizedBatchMeta(db, 1))
+	assert.Equal(t, uint64(0), *rawdb.ReadFinalizedL2BlockNumber(db))
+	assert.NotNil(t, rawdb.ReadBatchChunkRanges(db, 1))
+	assert.Nil(t, rawdb.ReadBatchChunkRanges(db, 2))
+	checkpoints := rawdb.ReadRollupSyncCheckpoints(db)
+	require.Len(t, checkpoints, 1)
+	assert.Equal(t, uint64(200), checkpoints[0].L1BlockNumber)
+
+	// reorg below all checkpoints
+	client.forkBlock, client.fork = 100, 2
+	assert.Error(t, service.handleL1Reorg())
+}
+
+func TestPruneCheckpoints(t *testing.T) {
+	db := rawdb.NewMemoryDatabase()
+	client := &forkEthClient{head: 400, finalized: 250, forkBlock: 1000}
+	service := &RollupSyncService{
+		ctx:    context.Background(),
+		db:     db,
+		client: &L1Client{ctx: context.Background(), client: client},
+	}
+	for _, number := range []uint64{100, 200, 300} {
+		checkpoint, err := service.newCheckpoint(nil, number)
+		require.NoError(t, err)
+		rawdb.WriteRollupSyncCheckpoint(db, checkpoint)
+	}
+	require.NoError(t, service.handleL1Reorg())
+
+	// the newest checkpoint below the finalized block is kept
+	checkpoints := rawdb.ReadRollupSyncCheckpoints(db)
+	require.Len(t, checkpoints, 2)
+	assert.Equal(t, uint64(200), checkpoints[0].L1BlockNumber)
+	assert.Equal(t, uint64(300), checkpoints[1].L1BlockNumber)
+}
+
+func TestCheckpointWrittenAfterParsing(t *testing.T) {
+	db := rawdb.NewMemoryDatabase()
+	client := &forkEthClient{head: 400, finalized: 50, forkBlock: 1000}
+	service := &RollupSyncService{
+		ctx:    context.Background(),
+		db:     db,
+		client: &L1Client{ctx: context.Background(), client: client},
+	}
+	rawdb.WriteFinalizedL2BlockNumber(db, 10)
+
+	// the range fails on an unknown event, no checkpoint is stored
+	checkpoint, err := service.newCheckpoint(nil, 100)
+	require.NoError(t, err)
+	assert.Error(t, service.parseAndUpdateRollupEventLogs([]types.Log{{}}, 100, checkpoint))
+	assert.Empty(t, rawdb.ReadRollupSyncCheckpoints(db))
+	assert.Nil(t, rawdb.ReadRollupEventSyncedL1BlockNumber(db))
+
+	// the checkpoint is stored along with the synced L1 block
+	require.NoError(t, service.parseAndUpdateRollupEventLogs(nil, 100, checkpoint))
+	checkpoints := rawdb.ReadRollupSyncCheckpoints(db)
+	require.Len(t, checkpoints, 1)
+	assert.Equal(t, uint64(100), checkpoints[0].L1BlockNumber)
+	assert.Equal(t, uint64(10), checkpoints[0].FinalizedL2BlockNumber)
+	assert.Equal(t, uint64(100), *rawdb.ReadRollupEventSyncedL1BlockNumber(db))
+}
+
+// rangeEthClient records the queried log ranges and fails queries starting at failFrom.
+type rangeEthClient struct {
+	forkEthClient
+	failFrom uint64
+	queried  []uint64
+}
+
+func (m *rangeEthClient) FilterLogs(ctx context.Context, q ethereum.FilterQuery) ([]types.Log, error) {
+	from := q.FromBlock.Uint64()
+	if m.failFrom != 0 && from >= m.failFrom {
+		return nil, errors.New("connection refused")
+	}
+	m.queried = append(m.queried, from)
+	return []types.Log{}, nil
+}
+
+func TestFetchRollupEventsPipeline(t *testing.T) {
+	db := rawdb.NewMemoryDatabase()
+	client := &rangeEthClient{forkEthClient: forkEthClient{head: 400, finalized: 350, forkBlock: 1000}}
+	service := &RollupSyncService{
+		ctx:    context.Background(),
+		bus:    eventbus.New(),
+		db:     db,
+		client: &L1Client{ctx: context.Background(), client: client},
+	}
+
+	service.fetchRollupEvents()
+	assert.Equal(t, []uint64{1, 101, 201, 301}, client.queried)
+	assert.Equal(t, uint64(350), service.latestProcessedBlock)
+	assert.Equal(t, uint64(350), *rawdb.ReadRollupEventSyncedL1BlockNumber(db))
+
+	// a failed fetch stops the round after the ranges that were fetched before
+	client.finalized = 700
+	client.failFrom = 551
+	client.queried = nil
+	service.fetchRollupEvents()
+	assert.Equal(t, []uint64{351, 451}, client.queried)
+	assert.Equal(t, uint64(550), service.latestProcessedBlock)
+	assert.Equal(t, uint64(550), *rawdb.ReadRollupEventSyncedL1BlockNumber(db))
+}
+
+// subEthClient supports log subscriptions and reports the fetched log ranges.
+type subEthClient struct {
+	forkEthClient
+	subscribed chan chan<- types.Log
+	fetched    chan uint64
+}
+
+func (m *subEthClient) SubscribeFilterLogs(ctx context.Context, q ethereum.FilterQuery, ch chan<- types.Log) (ethereum.Subscription, error) {
+	m.subscribed <- ch
+	return event.NewSubscription(func(quit <-chan struct{}) error {
+		<-quit
+		return nil
+	}), nil
+}
+
+func (m *subEthClient) FilterLogs(ctx context.Context, q ethereum.FilterQuery) ([]types.Log, error) {
+	m.fetched <- q.FromBlock.Uint64()
+	return []types.Log{}, nil
+}
+
+func TestSubscribeRollupEvents(t *testing.T) {
+	// clients without subscription support fall back to polling
+	l1Client := &L1Client{ctx: context.Background(), client: &mockEthClient{}}
+	_, err := l1Client.subscribeRollupEvents(context.Background(), make(chan types.Log))
+	assert.Error(t, err)
+
+	client := &subEthClient{
+		forkEthClient: forkEthClient{head: 400, finalized: 150, forkBlock: 1000},
+		subscribed:    make(chan chan<- types.Log, 1),
+		fetched:       make(chan uint64, 10),
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	service := &RollupSyncService{
+		ctx:    ctx,
+		cancel: cancel,
+		bus:    eventbus.New(),
+		db:     rawdb.NewMemoryDatabase(),
+		client: &L1Client{ctx: ctx, client: client},
+	}
+	service.Start()
+	defer service.Stop()
+
+	var logCh chan<- types.Log
+	select {
+	case logCh = <-client.subscribed:
+	case <-time.After(5 * time.Second):
+		t.Fatal("rollup events not subscribed")
+	}
+
+	// a new event triggers a fetch without waiting for the sync interval
+	logCh <- types.Log{BlockNumber: 150}
+	select {
+	case from := <-client.fetched:
+		assert.Equal(t, uint64(1), from)
+	case <-time.After(5 * time.Second):
+		t.Fatal("rollup events not fetched on new event")
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"math/big"
+	"time"
 
 	"github.com/scroll-tech/go-ethereum"
 	"github.com/scroll-tech/go-ethereum/accounts/abi"
@@ -19,23 +20,23 @@ import (
 // L1Client is a wrapper around EthClient that adds
 // methods for conveniently collecting rollup events of ScrollChain contract.
 type L1Client struct {
-	ctx                           context.Context
-	client                        sync_service.EthClient
-	scrollChainAddress            common.Address
-	l1CommitBatchEventSignature   common.Hash
-	l1RevertBatchEventSignature   common.Hash
-	l1FinalizeBatchEventSignature common.Hash
+	ctx                context.Context
+	client             sync_service.EthClient
+	scrollChainAddress common.Address
+	scrollChainABI     *abi.ABI
 }
 
 // newL1Client initializes a new L1Client instance with the provided configuration.
 // It checks for a valid scrollChainAddress and verifies the chain ID.
-func newL1Client(ctx context.Context, l1Client sync_service.EthClient, l1ChainId uint64, scrollChainAddress common.Address, scrollChainABI *abi.ABI) (*L1Client, error) {
+// Every request is bounded by the given timeout, zero disables the timeout.
+func newL1Client(ctx context.Context, l1Client sync_service.EthClient, l1ChainId uint64, scrollChainAddress common.Address, scrollChainABI *abi.ABI, timeout time.Duration) (*L1Client, error) {
 	if scrollChainAddress == (common.Address{}) {
 		return nil, errors.New("must pass non-zero scrollChainAddress to L1Client")
 	}
+	metered := &meteredEthClient{EthClient: l1Client, timeout: timeout}
 
 	// sanity check: compare chain IDs
-	got, err := l1Client.ChainID(ctx)
+	got, err := metered.ChainID(ctx)
 	if err != nil {
 		return nil, fmt.Errorf("failed to query L1 chain ID, err: %w", err)
 	}
@@ -44,18 +45,17 @@ func newL1Client(ctx context.Context, l1Client sync_service.EthClient, l1ChainId
 	}
 
 	client := L1Client{
-		ctx:                           ctx,
-		client:                        l1Client,
-		scrollChainAddress:            scrollChainAddress,
-		l1CommitBatchEventSignature:   scrollChainABI.Events["CommitBatch"].ID,
-		l1RevertBatchEventSignature:   scrollChainABI.Events["RevertBatch"].ID,
-		l1FinalizeBatchEventSignature: scrollChainABI.Events["FinalizeBatch"].ID,
+		ctx:                ctx,
+		client:             metered,
+		scrollChainAddress: scrollChainAddress,
+		scrollChainABI:     scrollChainABI,
 	}
 
 	return &client, nil
 }
 
-// fetcRollupEventsInRange retrieves and parses commit/revert/finalize rollup events between block numbers: [from, to].
+// fetcRollupEventsInRange retrieves all events emitted by the ScrollChain contract between block numbers: [from, to].
+// Events other than commit/revert/finalize are handled according to the configured unknown event policy.
 func (c *L1Client) fetchRollupEventsInRange(ctx context.Context, from, to uint64) ([]types.Log, error) {
 	log.Trace("L1Client fetchRollupEventsInRange", "fromBlock", from, "toBlock", to)
 
@@ -65,20 +65,33 @@ func (c *L1Client) fetchRollupEventsInRange(ctx context.Context, from, to uint64
 		Addresses: []common.Address{
 			c.scrollChainAddress,
 		},
-		Topics: make([][]common.Hash, 1),
 	}
-	query.Topics[0] = make([]common.Hash, 3)
-	query.Topics[0][0] = c.l1CommitBatchEventSignature
-	query.Topics[0][1] = c.l1RevertBatchEventSignature
-	query.Topics[0][2] = c.l1FinalizeBatchEventSignature
 
-	logs, err := c.client.FilterLogs(c.ctx, query)
+	logs, err := c.client.FilterLogs(ctx, query)
 	if err != nil {
 		return nil, fmt.Errorf("failed to filter logs, err: %w", err)
 	}
 	return logs, nil
 }
 
+// subscribeRollupEvents subscribes to the events emitted by the ScrollChain contract.
+// It fails if the underlying client does not support log subscriptions, e.g. over HTTP.
+func (c *L1Client) subscribeRollupEvents(ctx context.Context, ch chan<- types.Log) (ethereum.Subscription, error) {
+	query := ethereum.FilterQuery{
+		Addresses: []common.Address{
+			c.scrollChainAddress,
+		},
+	}
+	sub, err := c.client.SubscribeFilterLogs(ctx, query, ch)
+	if err != nil {
+		return nil, err
+	}
+	if sub == nil {
+		return nil, errors.New("log subscriptions are not supported")
+	}
+	return sub, nil
+}
+
 // getLatestFinalizedBlockNumber fetches the block number of the latest finalized block from the L1 chain.
 func (c *L1Client) getLatestFinalizedBlockNumber(ctx context.Context) (uint64, error) {
 	header, err := c.client.HeaderByNumber(ctx, big.NewInt(int64(rpc.FinalizedBlockNumber)))
@@ -90,3 +103,135 @@ func (c *L1Client) getLatestFinalizedBlockNumber(ctx context.Context) (uint64, e
 	}
 	return header.Number.Uint64(), nil
 }
+
+// getLatestConfirmedBlockNumber fetches the number of the latest L1 block with at least the given number of
+// confirmations. If confirmations is zero, it returns the latest finalized block instead.
+func (c *L1Client) getLatestConfirmedBlockNumber(ctx context.Context, confirmations uint64) (uint64, error) {
+	if confirmations == 0 {
+		return c.getLatestFinalizedBlockNumber(ctx)
+	}
+	number, err := c.client.BlockNumber(ctx)
+	if err != nil {
+		return 0, err
+	}
+	if number < confirmations {
+		return 0, nil
+	}
+	return number - confirmations, nil
+}
+
+// getBlockHash fetches the hash of the canonical L1 block with the given number.
+func (c *L1Client) getBlockHash(ctx context.Context, number uint64) (common.Hash, error) {
+	header, err := c.client.HeaderByNumber(ctx, new(big.Int).SetUint64(number))
+	if err != nil {
+		return common.Hash{}, err
+	}
+	return header.Hash(), nil
+}
+
+// getBlockTime fetches the timestamp of the canonical L1 block with the given number.
+func (c *L1Client) getBlockTime(ctx context.Context, number uint64) (uint64, error) {
+	header, err := c.client.HeaderByNumber(ctx, new(big.Int).SetUint64(number))
+	if err != nil {
+		return 0, err
+	}
+	return header.Time, nil
+}
+
+// getLastFinalizedBatchIndex calls lastFinalizedBatchIndex of the ScrollChain contract at the latest L1 block.
+func (c *L1Client) getLastFinalizedBatchIndex(ctx context.Context) (uint64, error) {
+	data, err := c.scrollChainABI.Pack("lastFinalizedBatchIndex")
+	if err != nil {
+		return 0, fmt.Errorf("failed to pack lastFinalizedBatchIndex call, err: %w", err)
+	}
+	result, err := c.client.CallContract(ctx, ethereum.CallMsg{To: &c.scrollChainAddress, Data: data}, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to call lastFinalizedBatchIndex, err: %w", err)
+	}
+	values, err := c.scrollChainABI.Unpack("lastFinalizedBatchIndex", result)
+	if err != nil {
+		return 0, fmt.Errorf("failed to unpack lastFinalizedBatchIndex result, err: %w", err)
+	}
+	index, ok := values[0].(*big.Int)
+	if !ok || !index.IsUint64() {
+		return 0, fmt.Errorf("unexpected lastFinalizedBatchIndex result: %v", values[0])
+	}
+	return index.Uint64(), nil
+}
+
+// meteredEthClient counts the failed requests of the wrapped L1 client and bounds
+// every request by a timeout, so that a hung L1 endpoint cannot block the service.
+// Subscriptions are not bounded by the timeout.
+type meteredEthClient struct {
+	sync_service.EthClient
+	timeout time.Duration // zero disables the timeout
+}
+
+func (c *meteredEthClient) withTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	if c.timeout == 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, c.timeout)
+}
+
+func countL1RPCError(err error) {
+	if err != nil && !errors.Is(err, ethereum.NotFound) {
+		l1RPCErrorCounter.Inc(1)
+	}
+}
+
+func (c *meteredEthClient) BlockNumber(ctx context.Context) (uint64, error) {
+	ctx, cancel := c.withTimeout(ctx)
+	defer cancel()
+	number, err := c.EthClient.BlockNumber(ctx)
+	countL1RPCError(err)
+	return number, err
+}
+
+func (c *meteredEthClient) ChainID(ctx context.Context) (*big.Int, error) {
+	ctx, cancel := c.withTimeout(ctx)
+	defer cancel()
+	chainID, err := c.EthClient.ChainID(ctx)
+	countL1RPCError(err)
+	return chainID, err
+}
+
+func (c *meteredEthClient) FilterLogs(ctx context.Context, q ethereum.FilterQuery) ([]types.Log, error) {
+	ctx, cancel := c.withTimeout(ctx)
+	defer cancel()
+	logs, err := c.EthClient.FilterLogs(ctx, q)
+	countL1RPCError(err)
+	return logs, err
+}
+
+func (c *meteredEthClient) HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error) {
+	ctx, cancel := c.withTimeout(ctx)
+	defer cancel()
+	header, err := c.EthClient.HeaderByNumber(ctx, number)
+	countL1RPCError(err)
+	return header, err
+}
+
+func (c *meteredEthClient) TransactionByHash(ctx context.Context, txHash common.Hash) (*types.Transaction, bool, error) {
+	ctx, cancel := c.withTimeout(ctx)
+	defer cancel()
+	tx, isPending, err := c.EthClient.TransactionByHash(ctx, txHash)
+	countL1RPCError(err)
+	return tx, isPending, err
+}
+
+func (c *meteredEthClient) BlockByHash(ctx context.Context, hash common.Hash) (*types.Block, error) {
+	ctx, cancel := c.withTimeout(ctx)
+	defer cancel()
+	block, err := c.EthClient.BlockByHash(ctx, hash)
+	countL1RPCError(err)
+	return block, err
+}
+
+func (c *meteredEthClient) CallContract(ctx context.Context, call ethereum.CallMsg, blockNumber *big.Int) ([]byte, error) {
+	ctx, cancel := c.withTimeout(ctx)
+	defer cancel()
+	result, err := c.EthClient.CallContract(ctx, call, blockNumber)
+	countL1RPCError(err)
+	return result, err
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"math/big"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -23,7 +24,7 @@ func TestL1Client(t *testing.T) {
 		t.Fatal("failed to get scroll chain abi", "err", err)
 	}
 	scrollChainAddress := common.HexToAddress("0x0123456789abcdef")
-	l1Client, err := newL1Client(ctx, mockClient, 11155111, scrollChainAddress, scrollChainABI)
+	l1Client, err := newL1Client(ctx, mockClient, 11155111, scrollChainAddress, scrollChainABI, defaultL1RequestTimeout)
 	require.NoError(t, err, "Failed to initialize L1Client")
 
 	blockNumber, err := l1Client.getLatestFinalizedBlockNumber(ctx)
@@ -35,8 +36,37 @@ func TestL1Client(t *testing.T) {
 	assert.Empty(t, logs, "Expected no logs from fetchRollupEventsInRange")
 }
 
+// hangingEthClient is an L1 client whose requests only return once their context is done.
+type hangingEthClient struct {
+	mockEthClient
+}
+
+func (m *hangingEthClient) BlockNumber(ctx context.Context) (uint64, error) {
+	<-ctx.Done()
+	return 0, ctx.Err()
+}
+
+func TestL1ClientTimeout(t *testing.T) {
+	scrollChainABI, err := scrollChainMetaData.GetAbi()
+	require.NoError(t, err)
+	l1Client, err := newL1Client(context.Background(), &hangingEthClient{}, 11155111, common.HexToAddress("0x0123456789abcdef"), scrollChainABI, 10*time.Millisecond)
+	require.NoError(t, err)
+
+	_, err = l1Client.getLatestConfirmedBlockNumber(context.Background(), 1)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+
+	// canceling the context aborts the request before the timeout
+	l1Client.client.(*meteredEthClient).timeout = time.Hour
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = l1Client.getLatestConfirmedBlockNumber(ctx, 1)
+	assert.ErrorIs(t, err, context.Canceled)
+}
+
 type mockEthClient struct {
-	commitBatchRLP []byte
+	commitBatchRLP          []byte
+	commitBatchTx           *types.Transaction // returned instead of commitBatchRLP if set
+	lastFinalizedBatchIndex uint64
 }
 
 func (m *mockEthClient) BlockNumber(ctx context.Context) (uint64, error) {
@@ -62,6 +92,9 @@ func (m *mockEthClient) SubscribeFilterLogs(ctx context.Context, query ethereum.
 }
 
 func (m *mockEthClient) TransactionByHash(ctx context.Context, txHash common.Hash) (*types.Transaction, bool, error) {
+	if m.commitBatchTx != nil {
+		return m.commitBatchTx, false, nil
+	}
 	var tx types.Transaction
 	if err := rlp.DecodeBytes(m.commitBatchRLP, &tx); err != nil {
 		return nil, false, err
@@ -72,3 +105,7 @@ func (m *mockEthClient) TransactionByHash(ctx context.Context, txHash common.Has
 func (m *mockEthClient) BlockByHash(ctx context.Context, hash common.Hash) (*types.Block, error) {
 	return nil, nil
 }
+
+func (m *mockEthClient) CallContract(ctx context.Context, call ethereum.CallMsg, blockNumber *big.Int) ([]byte, error) {
+	return common.BigToHash(new(big.Int).SetUint64(m.lastFinalizedBatchIndex)).Bytes(), nil
+}
@@ -0,0 +1,163 @@
+package rollup_sync_service
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/scroll-tech/go-ethereum/core/rawdb"
+	"github.com/scroll-tech/go-ethereum/ethdb"
+	"github.com/scroll-tech/go-ethereum/log"
+	"github.com/scroll-tech/go-ethereum/metrics"
+
+	"github.com/scroll-tech/go-ethereum/rollup/eventbus"
+	"github.com/scroll-tech/go-ethereum/rollup/metasink"
+)
+
+const (
+	// defaultMetadataSinkTimeout is the maximum time we wait for the metadata sink to accept a record.
+	defaultMetadataSinkTimeout = 10 * time.Second
+
+	// defaultMetadataSinkRetryInterval is the time we wait before delivering a record again
+	// after the metadata sink failed to accept it.
+	defaultMetadataSinkRetryInterval = 30 * time.Second
+
+	// metadataSinkBuffer is the number of batch events buffered for the metadata sink. The sync
+	// loop only blocks on a slow sink once the buffer is full.
+	metadataSinkBuffer = 256
+)
+
+var (
+	metadataSinkWrittenCounter = metrics.NewRegisteredCounter("rollup/sync/metasink/written", nil)
+	metadataSinkErrorCounter   = metrics.NewRegisteredCounter("rollup/sync/metasink/errors", nil)
+	metadataSinkPendingGauge   = metrics.NewRegisteredGauge("rollup/sync/metasink/pending", nil)
+)
+
+// metadataSinkQueue delivers the batch metadata records to the metadata sink in order. The
+// records are stored before they are delivered, and the cursor of the next record to deliver
+// is only advanced once the sink accepted the record, so that the sink catches up after it
+// was unavailable or the node was restarted.
+type metadataSinkQueue struct {
+	db     ethdb.Database
+	sink   metasink.Sink
+	cursor uint64 // sequence number of the next record to deliver
+	next   uint64 // sequence number of the next record to store
+}
+
+func newMetadataSinkQueue(db ethdb.Database, sink metasink.Sink) *metadataSinkQueue {
+	q := &metadataSinkQueue{db: db, sink: sink, cursor: rawdb.ReadMetadataSinkCursor(db)}
+	q.next = q.cursor
+	for rawdb.ReadMetadataSinkRecord(db, q.next) != nil {
+		q.next++
+	}
+	metadataSinkPendingGauge.Update(int64(q.next - q.cursor))
+	return q
+}
+
+// push stores a record to be delivered.
+func (q *metadataSinkQueue) push(record *metasink.Record) {
+	value, err := json.Marshal(record.Value)
+	if err != nil {
+		metadataSinkErrorCounter.Inc(1)
+		log.Error("failed to encode batch metadata record", "kind", record.Kind, "batch index", record.BatchIndex, "err", err)
+		return
+	}
+	rawdb.WriteMetadataSinkRecord(q.db, q.next, &rawdb.MetadataSinkRecord{Kind: record.Kind, BatchIndex: record.BatchIndex, Value: value})
+	q.next++
+	metadataSinkPendingGauge.Update(int64(q.next - q.cursor))
+}
+
+// deliver puts the stored records to the metadata sink in order. It stops at the first
+// record the sink fails to accept and reports whether all records were delivered.
+func (q *metadataSinkQueue) deliver(ctx context.Context) bool {
+	for ; q.cursor < q.next; q.cursor++ {
+		stored := rawdb.ReadMetadataSinkRecord(q.db, q.cursor)
+		if stored == nil {
+			log.Error("missing batch metadata record", "sequence", q.cursor)
+			continue
+		}
+		record := &metasink.Record{Kind: stored.Kind, BatchIndex: stored.BatchIndex, Value: json.RawMessage(stored.Value)}
+		if err := q.put(ctx, record); err != nil {
+			metadataSinkErrorCounter.Inc(1)
+			log.Warn("failed to write batch metadata to sink, retrying later", "kind", record.Kind, "batch index", record.BatchIndex, "pending", q.next-q.cursor, "err", err)
+			return false
+		}
+		metadataSinkWrittenCounter.Inc(1)
+		log.Trace("wrote batch metadata to sink", "kind", record.Kind, "batch index", record.BatchIndex)
+
+		batch := q.db.NewBatch()
+		rawdb.DeleteMetadataSinkRecord(batch, q.cursor)
+		rawdb.WriteMetadataSinkCursor(batch, q.cursor+1)
+		if err := batch.Write(); err != nil {
+			log.Crit("failed to advance metadata sink cursor", "err", err)
+		}
+		metadataSinkPendingGauge.Update(int64(q.next - q.cursor - 1))
+	}
+	return true
+}
+
+// put puts a single record to the metadata sink.
+func (q *metadataSinkQueue) put(ctx context.Context, record *metasink.Record) error {
+	ctx, cancel := context.WithTimeout(ctx, defaultMetadataSinkTimeout)
+	defer cancel()
+	return q.sink.Put(ctx, record)
+}
+
+// startMetadataSink writes the metadata of the batch events published on the event bus to the
+// configured metadata sink. The events are published after the batch metadata has been stored,
+// so the sink only sees batches that are persisted locally. Records the sink fails to accept
+// are retried, and the records that were not delivered before a restart are delivered first.
+// The sync service does not wait for the sink.
+func (s *RollupSyncService) startMetadataSink() {
+	if s.metadataSink == nil {
+		return
+	}
+
+	committedCh := make(chan eventbus.BatchCommittedEvent, metadataSinkBuffer)
+	finalizedCh := make(chan eventbus.BatchFinalizedEvent, metadataSinkBuffer)
+	revertedCh := make(chan eventbus.BatchRevertedEvent, metadataSinkBuffer)
+	committedSub := s.bus.SubscribeBatchCommitted(committedCh)
+	finalizedSub := s.bus.SubscribeBatchFinalized(finalizedCh)
+	revertedSub := s.bus.SubscribeBatchReverted(revertedCh)
+
+	queue := newMetadataSinkQueue(s.db, s.metadataSink)
+
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		defer committedSub.Unsubscribe()
+		defer finalizedSub.Unsubscribe()
+		defer revertedSub.Unsubscribe()
+
+		// note: the records stored before a restart are delivered first. While a retry is
+		// scheduled, new records wait for it, so that the records are delivered in order.
+		retry := time.NewTimer(0)
+		defer retry.Stop()
+		scheduled := true
+
+		deliver := func() {
+			if !queue.deliver(s.ctx) {
+				retry.Reset(defaultMetadataSinkRetryInterval)
+				scheduled = true
+			}
+		}
+
+		for {
+			select {
+			case <-s.ctx.Done():
+				return
+			case ev := <-committedCh:
+				queue.push(metasink.CommittedRecord(ev))
+			case ev := <-finalizedCh:
+				queue.push(metasink.FinalizedRecord(ev))
+			case ev := <-revertedCh:
+				queue.push(metasink.RevertedRecord(ev))
+			case <-retry.C:
+				scheduled = false
+			}
+			if !scheduled {
+				deliver()
+			}
+		}
+	}()
+}
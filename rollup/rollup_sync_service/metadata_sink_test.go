@@ -0,0 +1,112 @@
+package rollup_sync_service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/scroll-tech/go-ethereum/common"
+	"github.com/scroll-tech/go-ethereum/core/rawdb"
+
+	"github.com/scroll-tech/go-ethereum/rollup/eventbus"
+	"github.com/scroll-tech/go-ethereum/rollup/metasink"
+)
+
+type mockMetadataSink struct {
+	records chan *metasink.Record
+	fail    int32 // accessed atomically
+}
+
+func (m *mockMetadataSink) Put(ctx context.Context, record *metasink.Record) error {
+	fail := atomic.LoadInt32(&m.fail) == 1
+	m.records <- record
+	if fail {
+		return errors.New("sink unavailable")
+	}
+	return nil
+}
+
+func (m *mockMetadataSink) Close() error { return nil }
+
+// assertRecordValue checks the value of a record delivered from the stored records, which is
+// the JSON encoding of the value.
+func assertRecordValue(t *testing.T, want interface{}, record *metasink.Record) {
+	wantValue, err := json.Marshal(want)
+	require.NoError(t, err)
+	value, err := json.Marshal(record.Value)
+	require.NoError(t, err)
+	assert.JSONEq(t, string(wantValue), string(value))
+}
+
+func TestMetadataSink(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	sink := &mockMetadataSink{records: make(chan *metasink.Record, 3)}
+	db := rawdb.NewMemoryDatabase()
+	service := &RollupSyncService{ctx: ctx, cancel: cancel, db: db, bus: eventbus.New(), metadataSink: sink}
+	service.startMetadataSink()
+
+	next := func() *metasink.Record {
+		select {
+		case record := <-sink.records:
+			return record
+		case <-time.After(5 * time.Second):
+			t.Fatal("timeout waiting for metadata record")
+			return nil
+		}
+	}
+
+	service.bus.PublishBatchCommitted(eventbus.BatchCommittedEvent{BatchIndex: 5, BatchHash: common.HexToHash("0x05"), StartBlockNumber: 1, EndBlockNumber: 10})
+	record := next()
+	assert.Equal(t, metasink.KindCommitted, record.Kind)
+	assert.Equal(t, uint64(5), record.BatchIndex)
+	assertRecordValue(t, &metasink.CommittedBatch{BatchIndex: 5, BatchHash: common.HexToHash("0x05"), StartBlockNumber: 1, EndBlockNumber: 10}, record)
+
+	service.bus.PublishBatchReverted(eventbus.BatchRevertedEvent{BatchIndex: 5, BatchHash: common.HexToHash("0x05")})
+	assert.Equal(t, metasink.KindReverted, next().Kind)
+
+	service.bus.PublishBatchFinalized(eventbus.BatchFinalizedEvent{BatchIndex: 4, WithdrawRoot: common.HexToHash("0x44")})
+	record = next()
+	assert.Equal(t, metasink.KindFinalized, record.Kind)
+	assertRecordValue(t, &metasink.FinalizedBatch{BatchIndex: 4, WithdrawRoot: common.HexToHash("0x44")}, record)
+
+	service.Stop()
+}
+
+func TestMetadataSinkQueue(t *testing.T) {
+	db := rawdb.NewMemoryDatabase()
+	sink := &mockMetadataSink{records: make(chan *metasink.Record, 10)}
+	queue := newMetadataSinkQueue(db, sink)
+
+	// failed records are kept
+	atomic.StoreInt32(&sink.fail, 1)
+	queue.push(metasink.CommittedRecord(eventbus.BatchCommittedEvent{BatchIndex: 1}))
+	queue.push(metasink.CommittedRecord(eventbus.BatchCommittedEvent{BatchIndex: 2}))
+	assert.False(t, queue.deliver(context.Background()))
+	assert.Equal(t, uint64(1), (<-sink.records).BatchIndex)
+	assert.Len(t, sink.records, 0)
+	assert.Equal(t, uint64(0), rawdb.ReadMetadataSinkCursor(db))
+
+	// and delivered in order after a restart
+	atomic.StoreInt32(&sink.fail, 0)
+	queue = newMetadataSinkQueue(db, sink)
+	queue.push(metasink.RevertedRecord(eventbus.BatchRevertedEvent{BatchIndex: 2}))
+	assert.True(t, queue.deliver(context.Background()))
+	for _, want := range []*metasink.Record{
+		metasink.CommittedRecord(eventbus.BatchCommittedEvent{BatchIndex: 1}),
+		metasink.CommittedRecord(eventbus.BatchCommittedEvent{BatchIndex: 2}),
+		metasink.RevertedRecord(eventbus.BatchRevertedEvent{BatchIndex: 2}),
+	} {
+		record := <-sink.records
+		assert.Equal(t, want.Kind, record.Kind)
+		assert.Equal(t, want.BatchIndex, record.BatchIndex)
+		assertRecordValue(t, want.Value, record)
+	}
+	assert.Equal(t, uint64(3), rawdb.ReadMetadataSinkCursor(db))
+	assert.Nil(t, rawdb.ReadMetadataSinkRecord(db, 0))
+}
@@ -0,0 +1,279 @@
+package rollup_sync_service
+
+import (
+	"context"
+
+	"github.com/scroll-tech/go-ethereum/common"
+	"github.com/scroll-tech/go-ethereum/core/rawdb"
+	"github.com/scroll-tech/go-ethereum/core/types"
+	"github.com/scroll-tech/go-ethereum/ethdb"
+	"github.com/scroll-tech/go-ethereum/log"
+	"github.com/scroll-tech/go-ethereum/metrics"
+)
+
+// maxPointerScanRanges bounds the number of ranges scanned back from the latest confirmed L1
+// block for the newest batches, about 1.4 days of L1 blocks. Batches are finalized every
+// few hours, the scan usually stops after a few ranges.
+const maxPointerScanRanges = 100
+
+var (
+	pointerCommittedBatchGauge = metrics.NewRegisteredGauge("rollup/sync/pointers/committed", nil)
+	pointerFinalizedBatchGauge = metrics.NewRegisteredGauge("rollup/sync/pointers/finalized", nil)
+)
+
+// L1BatchPointers reports the newest committed and finalized batches emitted by ScrollChain
+// while the rollup sync service catches up with L1. They are found by a quick scan of the
+// newest L1 blocks before the events are validated in order, so that the finality reported
+// by L1 is known long before the catch-up completes. The "safe" and "finalized" block tags
+// are fast-forwarded to the pointers, the finalized pointer once the state root of its last
+// L2 block matches the finalized state root. The pointers are validated lazily, once the
+// events are validated up to the finalized batch.
+type L1BatchPointers struct {
+	ScannedL1Block            uint64       `json:"scannedL1Block"` // L1 block up to which the events were scanned
+	LatestCommittedBatchIndex *uint64      `json:"latestCommittedBatchIndex,omitempty"`
+	LatestFinalizedBatchIndex *uint64      `json:"latestFinalizedBatchIndex,omitempty"`
+	FinalizedBatchHash        *common.Hash `json:"finalizedBatchHash,omitempty"`
+	FinalizedStateRoot        *common.Hash `json:"finalizedStateRoot,omitempty"`
+	FinalizedL2BlockNumber    *uint64      `json:"finalizedL2BlockNumber,omitempty"` // nil if the commit of the finalized batch was not found
+	CommittedL2BlockNumber    *uint64      `json:"committedL2BlockNumber,omitempty"` // nil if the last L2 block of the committed batch is not known
+}
+
+// L1BatchPointers returns the newest batches reported by L1 that are not validated yet, or
+// nil if the service is not catching up with L1.
+func (s *RollupSyncService) L1BatchPointers() *L1BatchPointers {
+	return readL1BatchPointers(s.db)
+}
+
+// readL1BatchPointers returns the L1 batch pointers stored in db, if any.
+func readL1BatchPointers(db ethdb.Reader) *L1BatchPointers {
+	stored := rawdb.ReadRollupSyncBatchPointers(db)
+	if stored == nil {
+		return nil
+	}
+	pointers := &L1BatchPointers{ScannedL1Block: stored.ScannedL1BlockNumber}
+	if stored.CommittedL1BlockNumber != 0 {
+		pointers.LatestCommittedBatchIndex = &stored.CommittedBatchIndex
+		if stored.CommittedL2BlockNumber != 0 {
+			pointers.CommittedL2BlockNumber = &stored.CommittedL2BlockNumber
+		}
+	}
+	if stored.FinalizedL1BlockNumber != 0 {
+		pointers.LatestFinalizedBatchIndex = &stored.FinalizedBatchIndex
+		pointers.FinalizedBatchHash = &stored.FinalizedBatchHash
+		pointers.FinalizedStateRoot = &stored.FinalizedStateRoot
+		if stored.FinalizedL2BlockNumber != 0 {
+			pointers.FinalizedL2BlockNumber = &stored.FinalizedL2BlockNumber
+		}
+	}
+	return pointers
+}
+
+// batchPointerScan holds the state of a scan from the newest to the oldest rollup events.
+type batchPointerScan struct {
+	pointers    rawdb.RollupSyncBatchPointers
+	committed   bool
+	finalized   bool
+	l2Block     bool   // the last L2 block of the finalized batch is known
+	reverted    bool   // batches were reverted after the newest commit
+	minReverted uint64 // lowest batch index reverted after the newest commit
+}
+
+func (scan *batchPointerScan) done() bool {
+	return scan.committed && scan.finalized && scan.l2Block
+}
+
+// scanBatchPointers is the first phase of a fetch round during a catch-up: the L1 blocks that
+// were not scanned yet are scanned back from latestConfirmed for the newest commit and finalize
+// events, which are stored as the L1 batch pointers. The second phase validates the events in
+// order, as in any fetch round, until it reaches the scanned L1 block and the pointers are
+// dropped. Scan failures are logged only, the pointers are an early estimate.
+func (s *RollupSyncService) scanBatchPointers(ctx context.Context, latestConfirmed uint64) {
+	if rawdb.ReadRollupSyncRecovery(s.db) == nil {
+		return
+	}
+	stored := rawdb.ReadRollupSyncBatchPointers(s.db)
+	if stored != nil && stored.ScannedL1BlockNumber > latestConfirmed {
+		// the scanned L1 blocks were reorged
+		stored = nil
+	}
+	from := s.latestProcessedBlock + 1
+	if stored != nil && stored.ScannedL1BlockNumber >= from {
+		from = stored.ScannedL1BlockNumber + 1
+	}
+	if from > latestConfirmed {
+		return
+	}
+
+	scan := new(batchPointerScan)
+	if stored != nil {
+		scan.pointers = *stored
+	}
+	to := latestConfirmed
+	for i := 0; i < maxPointerScanRanges && !scan.done(); i++ {
+		start := from
+		if to-from >= defaultFetchBlockRange {
+			start = to - defaultFetchBlockRange + 1
+		}
+		logs, err := s.client.fetchRollupEventsInRange(ctx, start, to)
+		if err != nil {
+			log.Debug("Failed to scan newest rollup events", "from block", start, "to block", to, "err", err)
+			return
+		}
+		for j := len(logs) - 1; j >= 0 && !scan.done(); j-- {
+			if err := s.scanPointerLog(scan, &logs[j]); err != nil {
+				log.Debug("Failed to scan newest rollup event", "L1 block", logs[j].BlockNumber, "tx", logs[j].TxHash, "err", err)
+				return
+			}
+		}
+		if start == from {
+			break
+		}
+		to = start - 1
+	}
+
+	if scan.reverted && !scan.committed && scan.pointers.CommittedL1BlockNumber != 0 && scan.pointers.CommittedBatchIndex >= scan.minReverted {
+		// the committed batch of a previous scan was reverted
+		scan.pointers.CommittedBatchIndex = scan.minReverted - 1
+		scan.pointers.CommittedL2BlockNumber = 0
+	}
+	scan.pointers.ScannedL1BlockNumber = latestConfirmed
+	rawdb.WriteRollupSyncBatchPointers(s.db, &scan.pointers)
+	if scan.pointers.CommittedL1BlockNumber != 0 {
+		pointerCommittedBatchGauge.Update(int64(scan.pointers.CommittedBatchIndex))
+	}
+	if scan.pointers.FinalizedL1BlockNumber != 0 {
+		pointerFinalizedBatchGauge.Update(int64(scan.pointers.FinalizedBatchIndex))
+	}
+	log.Info("Scanned newest batches ahead of validation", "L1 block", latestConfirmed, "committed", scan.pointers.CommittedBatchIndex, "committed L2 block", scan.pointers.CommittedL2BlockNumber, "finalized", scan.pointers.FinalizedBatchIndex, "finalized L2 block", scan.pointers.FinalizedL2BlockNumber)
+}
+
+// scanPointerLog updates a scan with an event, the events are passed from the newest to the oldest.
+func (s *RollupSyncService) scanPointerLog(scan *batchPointerScan, vLog *types.Log) error {
+	if len(vLog.Topics) == 0 {
+		return nil
+	}
+	switch vLog.Topics[0] {
+	case s.l1CommitBatchEventSignature:
+		event := &L1CommitBatchEvent{}
+		if err := UnpackLog(s.scrollChainABI, event, "CommitBatch", *vLog); err != nil {
+			return err
+		}
+		batchIndex := event.BatchIndex.Uint64()
+		// note: the commits of reverted batches are skipped, the newest batch that was not
+		// reverted is committed by an older event.
+		if !scan.committed && (!scan.reverted || batchIndex < scan.minReverted) {
+			chunkBlockRanges := s.readBatchChunkRanges(batchIndex)
+			if len(chunkBlockRanges) == 0 {
+				batch, err := s.getChunkRanges(batchIndex, vLog)
+				if err != nil {
+					return err
+				}
+				chunkBlockRanges = batch.chunkBlockRanges
+			}
+			scan.committed = true
+			scan.pointers.CommittedBatchIndex = batchIndex
+			scan.pointers.CommittedL1BlockNumber = vLog.BlockNumber
+			scan.pointers.CommittedL2BlockNumber = chunkBlockRanges[len(chunkBlockRanges)-1].EndBlockNumber
+		}
+		if scan.finalized && !scan.l2Block && batchIndex == scan.pointers.FinalizedBatchIndex {
+			batch, err := s.getChunkRanges(batchIndex, vLog)
+			if err != nil {
+				return err
+			}
+			scan.pointers.FinalizedL2BlockNumber = batch.chunkBlockRanges[len(batch.chunkBlockRanges)-1].EndBlockNumber
+			scan.l2Block = true
+		}
+
+	case s.l1RevertBatchEventSignature:
+		event := &L1RevertBatchEvent{}
+		if err := UnpackLog(s.scrollChainABI, event, "RevertBatch", *vLog); err != nil {
+			return err
+		}
+		// note: the genesis batch is never reverted
+		if batchIndex := event.BatchIndex.Uint64(); !scan.committed && batchIndex > 0 && (!scan.reverted || batchIndex < scan.minReverted) {
+			scan.reverted = true
+			scan.minReverted = batchIndex
+		}
+
+	case s.l1FinalizeBatchEventSignature:
+		event := &L1FinalizeBatchEvent{}
+		if err := UnpackLog(s.scrollChainABI, event, "FinalizeBatch", *vLog); err != nil {
+			return err
+		}
+		s.scanFinalizedPointer(scan, event.BatchIndex.Uint64(), event.BatchHash, event.StateRoot, vLog.BlockNumber)
+
+	case s.l1FinalizeBundleEventSignature:
+		event := &L1FinalizeBundleEvent{}
+		if err := UnpackLog(s.scrollChainABI, event, "FinalizeBundle", *vLog); err != nil {
+			return err
+		}
+		s.scanFinalizedPointer(scan, event.EndBatchIndex.Uint64(), event.EndBatchHash, event.StateRoot, vLog.BlockNumber)
+	}
+	return nil
+}
+
+// scanFinalizedPointer records the newest finalized batch of a scan. The last L2 block of the
+// batch is known if the batch was committed locally, else it is read from its commit event.
+func (s *RollupSyncService) scanFinalizedPointer(scan *batchPointerScan, batchIndex uint64, batchHash, stateRoot common.Hash, l1BlockNumber uint64) {
+	if scan.finalized {
+		return
+	}
+	scan.finalized = true
+	scan.pointers.FinalizedBatchIndex = batchIndex
+	scan.pointers.FinalizedBatchHash = batchHash
+	scan.pointers.FinalizedStateRoot = stateRoot
+	scan.pointers.FinalizedL1BlockNumber = l1BlockNumber
+	scan.pointers.FinalizedL2BlockNumber = 0
+	if chunkBlockRanges := s.readBatchChunkRanges(batchIndex); len(chunkBlockRanges) > 0 {
+		scan.pointers.FinalizedL2BlockNumber = chunkBlockRanges[len(chunkBlockRanges)-1].EndBlockNumber
+		scan.l2Block = true
+	}
+}
+
+// dropValidatedBatchPointers removes the L1 batch pointers once the events have been validated
+// up to the scanned L1 block, from then on the validated batches are as recent as the pointers.
+func (s *RollupSyncService) dropValidatedBatchPointers() {
+	if pointers := rawdb.ReadRollupSyncBatchPointers(s.db); pointers != nil && s.latestProcessedBlock >= pointers.ScannedL1BlockNumber {
+		rawdb.DeleteRollupSyncBatchPointers(s.db)
+	}
+}
+
+// fastForwardFinalityMarkers returns the finalized L2 block and the last committed batch that
+// the block tags of the chain point to while catching up, i.e. the validated ones, or the L1
+// batch pointers if they are more recent. The finalized pointer is only used once its last L2
+// block is imported with the finalized state root.
+func (s *RollupSyncService) fastForwardFinalityMarkers(finalizedL2BlockNumber *uint64, lastCommitted *CommittedBatch) (*uint64, *CommittedBatch) {
+	pointers := rawdb.ReadRollupSyncBatchPointers(s.db)
+	if pointers == nil {
+		return finalizedL2BlockNumber, lastCommitted
+	}
+	if number := pointers.FinalizedL2BlockNumber; pointers.FinalizedL1BlockNumber != 0 && number != 0 && (finalizedL2BlockNumber == nil || number > *finalizedL2BlockNumber) {
+		if header := s.bc.GetHeaderByNumber(number); header == nil {
+			log.Debug("Finalized L2 block of the L1 batch pointers not imported yet", "number", number)
+		} else if header.Root != pointers.FinalizedStateRoot {
+			log.Warn("State root mismatch of the finalized L1 batch pointer", "batch index", pointers.FinalizedBatchIndex, "L2 block", number, "l1 finalized state root", pointers.FinalizedStateRoot.Hex(), "l2 state root", header.Root.Hex())
+		} else {
+			finalizedL2BlockNumber = &number
+		}
+	}
+	if number := pointers.CommittedL2BlockNumber; pointers.CommittedL1BlockNumber != 0 && number != 0 && (lastCommitted == nil || number > lastCommitted.EndBlockNumber) {
+		lastCommitted = &CommittedBatch{BatchIndex: pointers.CommittedBatchIndex, EndBlockNumber: number}
+	}
+	return finalizedL2BlockNumber, lastCommitted
+}
+
+// validateBatchPointers checks the finalized L1 batch pointer once the batch it points to is
+// validated. The pointers are dropped if the validated batch differs, e.g. after an L1 reorg,
+// so that the block tags fall back to the validated batches.
+func (s *RollupSyncService) validateBatchPointers(batchIndex uint64, finalizedBatchMeta *rawdb.FinalizedBatchMeta) {
+	pointers := rawdb.ReadRollupSyncBatchPointers(s.db)
+	if pointers == nil || pointers.FinalizedL1BlockNumber == 0 || pointers.FinalizedBatchIndex != batchIndex {
+		return
+	}
+	if finalizedBatchMeta.BatchHash != pointers.FinalizedBatchHash || finalizedBatchMeta.StateRoot != pointers.FinalizedStateRoot {
+		log.Error("Validated batch differs from the finalized L1 batch pointer, dropping the pointers", "batch index", batchIndex, "batch hash", finalizedBatchMeta.BatchHash.Hex(), "pointer batch hash", pointers.FinalizedBatchHash.Hex(), "state root", finalizedBatchMeta.StateRoot.Hex(), "pointer state root", pointers.FinalizedStateRoot.Hex())
+		rawdb.DeleteRollupSyncBatchPointers(s.db)
+		return
+	}
+	log.Info("Validated the finalized L1 batch pointer", "batch index", batchIndex)
+}
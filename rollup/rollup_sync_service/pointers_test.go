@@ -0,0 +1,162 @@
+package rollup_sync_service
+
+import (
+	"context"
+	"math/big"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/scroll-tech/go-ethereum/common"
+	"github.com/scroll-tech/go-ethereum/consensus/ethash"
+	"github.com/scroll-tech/go-ethereum/core"
+	"github.com/scroll-tech/go-ethereum/core/rawdb"
+	"github.com/scroll-tech/go-ethereum/core/types"
+	"github.com/scroll-tech/go-ethereum/core/vm"
+	"github.com/scroll-tech/go-ethereum/params"
+)
+
+func TestBatchPointers(t *testing.T) {
+	genesisConfig := &params.ChainConfig{
+		Scroll: params.ScrollConfig{
+			L1Config: &params.L1Config{
+				L1ChainId:          11155111,
+				ScrollChainAddress: common.HexToAddress("0x2D567EcE699Eabe5afCd141eDB7A4f2D0D6ce8a0"),
+			},
+		},
+	}
+	rlpData, err := os.ReadFile("./testdata/commit_batch_tx.rlp")
+	require.NoError(t, err)
+	client := &logsEthClient{mockEthClient: mockEthClient{commitBatchRLP: rlpData}}
+	db := rawdb.NewMemoryDatabase()
+	service, err := NewRollupSyncService(context.Background(), genesisConfig, db, client, newTestBlockChain(t, db), 1, &Config{VerifyMode: VerifyModeHaltSync}, nil)
+	require.NoError(t, err)
+
+	eventLog := func(signature common.Hash, batchIndex, l1BlockNumber uint64) types.Log {
+		return types.Log{
+			Topics:      []common.Hash{signature, common.BigToHash(new(big.Int).SetUint64(batchIndex)), {}},
+			BlockNumber: l1BlockNumber,
+		}
+	}
+	commitLog := func(batchIndex, l1BlockNumber uint64) types.Log {
+		return eventLog(service.l1CommitBatchEventSignature, batchIndex, l1BlockNumber)
+	}
+	stateRoot := common.HexToHash("0x5e")
+	finalizeLog := eventLog(service.l1FinalizeBatchEventSignature, 6, 5000)
+	finalizeLog.Topics[2] = common.HexToHash("0xba")
+	finalizeLog.Data, err = service.scrollChainABI.Events["FinalizeBatch"].Inputs.NonIndexed().Pack(stateRoot, common.Hash{})
+	require.NoError(t, err)
+
+	// batch 6 is finalized, batch 8 was reverted after its commit
+	client.logs = []types.Log{
+		commitLog(5, 1000),
+		commitLog(6, 1100),
+		finalizeLog,
+		commitLog(7, 5100),
+		commitLog(8, 5200),
+		eventLog(service.l1RevertBatchEventSignature, 8, 5300),
+	}
+
+	// no pointers are scanned close to the L1 chain
+	service.latestProcessedBlock = 100
+	service.scanBatchPointers(context.Background(), 1000)
+	assert.Nil(t, service.L1BatchPointers())
+
+	service.startRecoveryRound(6000)
+	service.scanBatchPointers(context.Background(), 6000)
+	pointers := service.L1BatchPointers()
+	require.NotNil(t, pointers)
+	assert.Equal(t, uint64(6000), pointers.ScannedL1Block)
+	require.NotNil(t, pointers.LatestCommittedBatchIndex)
+	assert.Equal(t, uint64(7), *pointers.LatestCommittedBatchIndex)
+	require.NotNil(t, pointers.LatestFinalizedBatchIndex)
+	assert.Equal(t, uint64(6), *pointers.LatestFinalizedBatchIndex)
+	assert.Equal(t, common.HexToHash("0xba"), *pointers.FinalizedBatchHash)
+	assert.Equal(t, stateRoot, *pointers.FinalizedStateRoot)
+	// the last L2 block of the finalized batch is read from its commit transaction
+	require.NotNil(t, pointers.FinalizedL2BlockNumber)
+	assert.Equal(t, uint64(911159), *pointers.FinalizedL2BlockNumber)
+	require.NotNil(t, pointers.CommittedL2BlockNumber)
+	assert.Equal(t, uint64(911159), *pointers.CommittedL2BlockNumber)
+	assert.Equal(t, pointers, service.Status().L1BatchPointers)
+
+	// the next round scans the new blocks only and keeps the finalized batch
+	client.logs = append(client.logs, eventLog(service.l1RevertBatchEventSignature, 7, 6100))
+	service.scanBatchPointers(context.Background(), 6200)
+	pointers = service.L1BatchPointers()
+	require.NotNil(t, pointers)
+	assert.Equal(t, uint64(6200), pointers.ScannedL1Block)
+	assert.Equal(t, uint64(6), *pointers.LatestCommittedBatchIndex)
+	assert.Nil(t, pointers.CommittedL2BlockNumber)
+	assert.Equal(t, uint64(6), *pointers.LatestFinalizedBatchIndex)
+	assert.Equal(t, uint64(911159), *pointers.FinalizedL2BlockNumber)
+
+	// the pointers are dropped once the events are validated up to the scanned block
+	service.latestProcessedBlock = 6100
+	service.dropValidatedBatchPointers()
+	assert.NotNil(t, service.L1BatchPointers())
+	service.latestProcessedBlock = 6200
+	service.dropValidatedBatchPointers()
+	assert.Nil(t, service.L1BatchPointers())
+}
+
+func TestFastForwardFinalityMarkers(t *testing.T) {
+	db := rawdb.NewMemoryDatabase()
+	genesis := (&core.Genesis{Config: params.TestChainConfig}).MustCommit(db)
+	blocks, _ := core.GenerateChain(params.TestChainConfig, genesis, ethash.NewFaker(), rawdb.NewMemoryDatabase(), 5, nil)
+	bc, err := core.NewBlockChain(db, nil, params.TestChainConfig, ethash.NewFaker(), vm.Config{}, nil, nil)
+	require.NoError(t, err)
+	defer bc.Stop()
+	_, err = bc.InsertChain(blocks[:3])
+	require.NoError(t, err)
+
+	// batch 0 is validated, L1 reports batch 1 as finalized and batch 2 as committed
+	service := &RollupSyncService{db: db, bc: bc}
+	rawdb.WriteBatchChunkRanges(db, 0, []*rawdb.ChunkBlockRange{{StartBlockNumber: 0, EndBlockNumber: 0}})
+	rawdb.WriteBatchEndBlock(db, 0, 0)
+	rawdb.WriteFinalizedL2BlockNumber(db, 0)
+	pointers := &rawdb.RollupSyncBatchPointers{
+		ScannedL1BlockNumber:   1000,
+		CommittedBatchIndex:    2,
+		CommittedL1BlockNumber: 900,
+		CommittedL2BlockNumber: 5,
+		FinalizedBatchIndex:    1,
+		FinalizedBatchHash:     common.HexToHash("0xba"),
+		FinalizedStateRoot:     blocks[3].Root(),
+		FinalizedL1BlockNumber: 800,
+		FinalizedL2BlockNumber: 4,
+	}
+	rawdb.WriteRollupSyncBatchPointers(db, pointers)
+
+	// the finalized pointer waits for its last L2 block, the safe block follows the local head
+	service.updateFinalityMarkers()
+	assert.Equal(t, genesis.Hash(), bc.CurrentFinalizedBlock().Hash())
+	assert.Equal(t, blocks[2].Hash(), bc.CurrentSafeBlock().Hash())
+
+	_, err = bc.InsertChain(blocks[3:])
+	require.NoError(t, err)
+	service.updateFinalityMarkers()
+	assert.Equal(t, blocks[3].Hash(), bc.CurrentFinalizedBlock().Hash())
+	assert.Equal(t, blocks[4].Hash(), bc.CurrentSafeBlock().Hash())
+
+	// the finalized pointer is not used if the state root of its last L2 block differs
+	pointers.FinalizedStateRoot = common.HexToHash("0x5e")
+	rawdb.WriteRollupSyncBatchPointers(db, pointers)
+	service.updateFinalityMarkers()
+	assert.Equal(t, genesis.Hash(), bc.CurrentFinalizedBlock().Hash())
+
+	// the pointers are kept once the finalized batch is validated as reported by L1
+	pointers.FinalizedStateRoot = blocks[3].Root()
+	rawdb.WriteRollupSyncBatchPointers(db, pointers)
+	service.validateBatchPointers(1, &rawdb.FinalizedBatchMeta{BatchHash: common.HexToHash("0xba"), StateRoot: blocks[3].Root()})
+	assert.NotNil(t, rawdb.ReadRollupSyncBatchPointers(db))
+
+	// and dropped if the validated batch differs
+	service.validateBatchPointers(1, &rawdb.FinalizedBatchMeta{BatchHash: common.HexToHash("0xbb"), StateRoot: blocks[3].Root()})
+	assert.Nil(t, rawdb.ReadRollupSyncBatchPointers(db))
+	service.updateFinalityMarkers()
+	assert.Equal(t, genesis.Hash(), bc.CurrentFinalizedBlock().Hash())
+	assert.Equal(t, genesis.Hash(), bc.CurrentSafeBlock().Hash())
+}
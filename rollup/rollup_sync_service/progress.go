@@ -0,0 +1,37 @@
+package rollup_sync_service
+
+import (
+	"sync/atomic"
+
+	"github.com/scroll-tech/go-ethereum"
+	"github.com/scroll-tech/go-ethereum/core/rawdb"
+)
+
+// Progress returns how far the service has synced the committed and finalized batches from L1,
+// and how far it lags behind the L1 and local L2 chains. The lags are estimates, the L1 lag is
+// based on the latest confirmed L1 block of the last fetch round.
+func (s *RollupSyncService) Progress() *ethereum.RollupSyncProgress {
+	progress := new(ethereum.RollupSyncProgress)
+	if number := rawdb.ReadRollupEventSyncedL1BlockNumber(s.db); number != nil {
+		progress.L1ProcessedBlock = *number
+	}
+	if latest := atomic.LoadUint64(&s.latestConfirmedBlock); latest > progress.L1ProcessedBlock {
+		progress.L1BlockLag = latest - progress.L1ProcessedBlock
+	}
+	if batchIndex, _, ok := s.lastCommittedBatch(); ok {
+		progress.LatestCommittedBatch = &batchIndex
+	}
+	if batchIndex, ok := s.lastFinalizedBatchIndex(); ok {
+		progress.LatestFinalizedBatch = &batchIndex
+	}
+	progress.FinalizedL2Block = rawdb.ReadFinalizedL2BlockNumber(s.db)
+
+	head := s.bc.CurrentHeader().Number.Uint64()
+	switch {
+	case progress.FinalizedL2Block == nil:
+		progress.FinalizedL2BlockLag = head
+	case head > *progress.FinalizedL2Block:
+		progress.FinalizedL2BlockLag = head - *progress.FinalizedL2Block
+	}
+	return progress
+}
@@ -0,0 +1,58 @@
+package rollup_sync_service
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/scroll-tech/go-ethereum/consensus/ethash"
+	"github.com/scroll-tech/go-ethereum/core"
+	"github.com/scroll-tech/go-ethereum/core/rawdb"
+	"github.com/scroll-tech/go-ethereum/core/vm"
+	"github.com/scroll-tech/go-ethereum/params"
+)
+
+func TestProgress(t *testing.T) {
+	db := rawdb.NewMemoryDatabase()
+	genesis := (&core.Genesis{Config: params.TestChainConfig}).MustCommit(db)
+	blocks, _ := core.GenerateChain(params.TestChainConfig, genesis, ethash.NewFaker(), rawdb.NewMemoryDatabase(), 5, nil)
+	bc, err := core.NewBlockChain(db, nil, params.TestChainConfig, ethash.NewFaker(), vm.Config{}, nil, nil)
+	require.NoError(t, err)
+	defer bc.Stop()
+	_, err = bc.InsertChain(blocks)
+	require.NoError(t, err)
+
+	service := &RollupSyncService{db: db, bc: bc}
+	progress := service.Progress()
+	assert.Equal(t, uint64(0), progress.L1ProcessedBlock)
+	assert.Nil(t, progress.LatestCommittedBatch)
+	assert.Nil(t, progress.LatestFinalizedBatch)
+	assert.Nil(t, progress.FinalizedL2Block)
+	assert.Equal(t, uint64(5), progress.FinalizedL2BlockLag)
+
+	// batch 0 is finalized, batch 1 is committed
+	rawdb.WriteBatchChunkRanges(db, 0, []*rawdb.ChunkBlockRange{{StartBlockNumber: 0, EndBlockNumber: 0}})
+	rawdb.WriteBatchEndBlock(db, 0, 0)
+	rawdb.WriteFinalizedBatchMeta(db, 0, &rawdb.FinalizedBatchMeta{})
+	rawdb.WriteFinalizedL2BlockNumber(db, 0)
+	rawdb.WriteBatchChunkRanges(db, 1, []*rawdb.ChunkBlockRange{{StartBlockNumber: 1, EndBlockNumber: 3}})
+	rawdb.WriteBatchEndBlock(db, 3, 1)
+	rawdb.WriteRollupEventSyncedL1BlockNumber(db, 100)
+	service.latestConfirmedBlock = 120
+
+	progress = service.Progress()
+	assert.Equal(t, uint64(100), progress.L1ProcessedBlock)
+	assert.Equal(t, uint64(20), progress.L1BlockLag)
+	require.NotNil(t, progress.LatestCommittedBatch)
+	assert.Equal(t, uint64(1), *progress.LatestCommittedBatch)
+	require.NotNil(t, progress.LatestFinalizedBatch)
+	assert.Equal(t, uint64(0), *progress.LatestFinalizedBatch)
+	require.NotNil(t, progress.FinalizedL2Block)
+	assert.Equal(t, uint64(0), *progress.FinalizedL2Block)
+	assert.Equal(t, uint64(5), progress.FinalizedL2BlockLag)
+
+	// the confirmed block of the last fetch round is behind the processed block
+	service.latestConfirmedBlock = 90
+	assert.Equal(t, uint64(0), service.Progress().L1BlockLag)
+}
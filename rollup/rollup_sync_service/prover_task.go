@@ -0,0 +1,138 @@
+package rollup_sync_service
+
+import (
+	"context"
+	"time"
+
+	"github.com/scroll-tech/go-ethereum/common"
+	"github.com/scroll-tech/go-ethereum/core/rawdb"
+	"github.com/scroll-tech/go-ethereum/core/types"
+	"github.com/scroll-tech/go-ethereum/log"
+	"github.com/scroll-tech/go-ethereum/metrics"
+
+	"github.com/scroll-tech/go-ethereum/rollup/provertask"
+)
+
+const (
+	// defaultProverTaskEnqueueTimeout is the maximum time we wait for the prover task queue to accept a task.
+	defaultProverTaskEnqueueTimeout = 10 * time.Second
+
+	// proverTaskBuffer is the number of prover tasks buffered for the prover task queue. Tasks
+	// are dropped once the buffer is full, the sync loop never waits for the queue.
+	proverTaskBuffer = 256
+)
+
+var (
+	proverTaskEnqueuedCounter = metrics.NewRegisteredCounter("rollup/sync/provertask/enqueued", nil)
+	proverTaskErrorCounter    = metrics.NewRegisteredCounter("rollup/sync/provertask/errors", nil)
+	proverTaskDroppedCounter  = metrics.NewRegisteredCounter("rollup/sync/provertask/dropped", nil)
+)
+
+// enqueueProverTask hands a task descriptor for a newly committed batch to the prover task loop,
+// which pushes it to the configured prover task queue in the background. Failures are logged and
+// otherwise ignored, the sync service must not depend on the availability of the queue.
+func (s *RollupSyncService) enqueueProverTask(event *L1CommitBatchEvent, vLog *types.Log, chunkBlockRanges []*rawdb.ChunkBlockRange) {
+	if s.proverTaskQueue == nil {
+		return
+	}
+
+	task := &provertask.Task{
+		BatchIndex:       event.BatchIndex.Uint64(),
+		BatchHash:        event.BatchHash,
+		CommitTxHash:     vLog.TxHash,
+		L1BlockNumber:    vLog.BlockNumber,
+		ChunkBlockRanges: chunkBlockRanges,
+	}
+	select {
+	case s.proverTasks <- task:
+	default:
+		proverTaskDroppedCounter.Inc(1)
+		log.Warn("prover task queue is lagging behind, dropping task", "batch index", task.BatchIndex)
+	}
+}
+
+// startProverTaskQueue pushes the tasks handed over by enqueueProverTask to the prover task queue.
+func (s *RollupSyncService) startProverTaskQueue() {
+	if s.proverTaskQueue == nil {
+		return
+	}
+
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+
+		for {
+			select {
+			case <-s.ctx.Done():
+				return
+			case task := <-s.proverTasks:
+				s.pushProverTask(task)
+			}
+		}
+	}()
+}
+
+// pushProverTask adds the chunk hashes to a task if the blocks of the batch are available
+// locally, and puts it to the prover task queue.
+func (s *RollupSyncService) pushProverTask(task *provertask.Task) {
+	if chunks := s.getLocalChunksForProverTask(task.ChunkBlockRanges); chunks != nil {
+		task.TracesAvailable = true
+		totalL1MessagePoppedBefore := s.getTotalL1MessagePoppedBefore(task.ChunkBlockRanges[0].StartBlockNumber)
+		for _, chunk := range chunks {
+			chunkHash, err := chunk.Hash(totalL1MessagePoppedBefore)
+			if err != nil {
+				log.Warn("failed to compute chunk hash for prover task", "batch index", task.BatchIndex, "err", err)
+				task.ChunkHashes = nil
+				break
+			}
+			task.ChunkHashes = append(task.ChunkHashes, chunkHash)
+			totalL1MessagePoppedBefore += chunk.NumL1Messages(totalL1MessagePoppedBefore)
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(s.ctx, defaultProverTaskEnqueueTimeout)
+	defer cancel()
+	if err := s.proverTaskQueue.Enqueue(ctx, task); err != nil {
+		proverTaskErrorCounter.Inc(1)
+		log.Warn("failed to enqueue prover task", "batch index", task.BatchIndex, "err", err)
+		return
+	}
+	proverTaskEnqueuedCounter.Inc(1)
+	log.Debug("enqueued prover task", "batch index", task.BatchIndex, "traces available", task.TracesAvailable)
+}
+
+// getLocalChunksForProverTask loads the blocks of the given chunks from the local chain without waiting for them.
+// It returns nil if any block is not available yet.
+func (s *RollupSyncService) getLocalChunksForProverTask(chunkBlockRanges []*rawdb.ChunkBlockRange) []*Chunk {
+	if len(chunkBlockRanges) == 0 || s.bc.CurrentBlock().Number().Uint64() < chunkBlockRanges[len(chunkBlockRanges)-1].EndBlockNumber {
+		return nil
+	}
+
+	chunks := make([]*Chunk, len(chunkBlockRanges))
+	for i, cr := range chunkBlockRanges {
+		chunks[i] = &Chunk{Blocks: make([]*WrappedBlock, cr.EndBlockNumber-cr.StartBlockNumber+1)}
+		for j := cr.StartBlockNumber; j <= cr.EndBlockNumber; j++ {
+			block := s.bc.GetBlockByNumber(j)
+			if block == nil {
+				return nil
+			}
+			chunks[i].Blocks[j-cr.StartBlockNumber] = NewWrappedBlock(block, common.Hash{})
+		}
+	}
+	return chunks
+}
+
+// getTotalL1MessagePoppedBefore returns the number of L1 messages (included or skipped) before the given L2 block.
+func (s *RollupSyncService) getTotalL1MessagePoppedBefore(blockNumber uint64) uint64 {
+	if blockNumber == 0 {
+		return 0
+	}
+	parent := s.bc.GetHeaderByNumber(blockNumber - 1)
+	if parent == nil {
+		return 0
+	}
+	if queueIndex := rawdb.ReadFirstQueueIndexNotInL2Block(s.db, parent.Hash()); queueIndex != nil {
+		return *queueIndex
+	}
+	return 0
+}
@@ -0,0 +1,68 @@
+package rollup_sync_service
+
+import (
+	"context"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/scroll-tech/go-ethereum/core/types"
+
+	"github.com/scroll-tech/go-ethereum/rollup/provertask"
+)
+
+type mockProverTaskQueue struct {
+	tasks chan *provertask.Task
+}
+
+func (m *mockProverTaskQueue) Enqueue(ctx context.Context, task *provertask.Task) error {
+	select {
+	case m.tasks <- task:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (m *mockProverTaskQueue) Close() error { return nil }
+
+func TestEnqueueProverTaskAsync(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	queue := &mockProverTaskQueue{tasks: make(chan *provertask.Task)}
+	service := &RollupSyncService{ctx: ctx, cancel: cancel, proverTaskQueue: queue, proverTasks: make(chan *provertask.Task, 2)}
+
+	// the sync loop does not wait for the queue, tasks beyond the buffer are dropped
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := int64(0); i < 3; i++ {
+			service.enqueueProverTask(&L1CommitBatchEvent{BatchIndex: big.NewInt(i)}, &types.Log{BlockNumber: 100}, nil)
+		}
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("enqueueProverTask blocked on the prover task queue")
+	}
+
+	service.startProverTaskQueue()
+	for i := uint64(0); i < 2; i++ {
+		select {
+		case task := <-queue.tasks:
+			assert.Equal(t, i, task.BatchIndex)
+			assert.Equal(t, uint64(100), task.L1BlockNumber)
+			assert.False(t, task.TracesAvailable)
+		case <-time.After(5 * time.Second):
+			t.Fatal("timeout waiting for prover task")
+		}
+	}
+	select {
+	case task := <-queue.tasks:
+		t.Fatalf("unexpected prover task for batch %d", task.BatchIndex)
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	service.Stop()
+}
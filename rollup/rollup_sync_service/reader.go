@@ -0,0 +1,236 @@
+package rollup_sync_service
+
+import (
+	"context"
+
+	"github.com/scroll-tech/go-ethereum"
+	"github.com/scroll-tech/go-ethereum/common"
+	"github.com/scroll-tech/go-ethereum/core/rawdb"
+	"github.com/scroll-tech/go-ethereum/ethdb"
+)
+
+// CommittedBatch identifies the highest committed batch and its last L2 block.
+type CommittedBatch struct {
+	BatchIndex     uint64 `json:"batchIndex"`
+	EndBlockNumber uint64 `json:"endBlockNumber"`
+}
+
+// L1BlockBatches lists the batches committed and finalized in an L1 block.
+type L1BlockBatches struct {
+	L1BlockNumber uint64   `json:"l1BlockNumber"`
+	Committed     []uint64 `json:"committed"`
+	Finalized     []uint64 `json:"finalized"`
+}
+
+// BatchReader provides read access to the rollup metadata stored by the rollup sync service.
+// Values that are not stored are returned as nil without an error.
+type BatchReader interface {
+	FinalizedBatchMeta(ctx context.Context, batchIndex uint64) (*rawdb.FinalizedBatchMeta, error)
+	BatchChunkRanges(ctx context.Context, batchIndex uint64) ([]*rawdb.ChunkBlockRange, error)
+	BatchL1Meta(ctx context.Context, batchIndex uint64) (*rawdb.BatchL1Meta, error)
+	BatchIndexByL2BlockNumber(ctx context.Context, l2BlockNumber uint64) (*uint64, error)
+	FinalizedL2BlockNumber(ctx context.Context) (*uint64, error)
+	RollupEventSyncedL1BlockNumber(ctx context.Context) (*uint64, error)
+	LastCommittedBatch(ctx context.Context) (*CommittedBatch, error)
+	// BatchesByL1Block returns the batches of the first L1 block at or after l1BlockNumber
+	// that committed or finalized a batch.
+	BatchesByL1Block(ctx context.Context, l1BlockNumber uint64) (*L1BlockBatches, error)
+	L1EndpointRanges(ctx context.Context, from, to uint64) ([]*rawdb.L1EndpointRange, error)
+	BatchGaps(ctx context.Context) ([]BatchGap, error)
+	RecoveryProgress(ctx context.Context) (*RecoveryProgress, error)
+	L1BatchPointers(ctx context.Context) (*L1BatchPointers, error)
+}
+
+// dbBatchReader reads the rollup metadata from the local database.
+type dbBatchReader struct {
+	db ethdb.Database
+}
+
+// NewDBBatchReader returns a BatchReader of the rollup metadata in db.
+func NewDBBatchReader(db ethdb.Database) BatchReader {
+	return &dbBatchReader{db: db}
+}
+
+func (r *dbBatchReader) FinalizedBatchMeta(_ context.Context, batchIndex uint64) (*rawdb.FinalizedBatchMeta, error) {
+	return rawdb.ReadFinalizedBatchMeta(r.db, batchIndex), nil
+}
+
+func (r *dbBatchReader) BatchChunkRanges(_ context.Context, batchIndex uint64) ([]*rawdb.ChunkBlockRange, error) {
+	return rawdb.ReadBatchChunkRanges(r.db, batchIndex), nil
+}
+
+func (r *dbBatchReader) BatchL1Meta(_ context.Context, batchIndex uint64) (*rawdb.BatchL1Meta, error) {
+	return rawdb.ReadBatchL1Meta(r.db, batchIndex), nil
+}
+
+func (r *dbBatchReader) BatchIndexByL2BlockNumber(_ context.Context, l2BlockNumber uint64) (*uint64, error) {
+	return rawdb.ReadBatchIndexByL2BlockNumber(r.db, l2BlockNumber), nil
+}
+
+func (r *dbBatchReader) FinalizedL2BlockNumber(_ context.Context) (*uint64, error) {
+	return rawdb.ReadFinalizedL2BlockNumber(r.db), nil
+}
+
+func (r *dbBatchReader) RollupEventSyncedL1BlockNumber(_ context.Context) (*uint64, error) {
+	return rawdb.ReadRollupEventSyncedL1BlockNumber(r.db), nil
+}
+
+func (r *dbBatchReader) LastCommittedBatch(_ context.Context) (*CommittedBatch, error) {
+	if batchIndex, endBlockNumber, ok := readLastCommittedBatch(r.db); ok {
+		return &CommittedBatch{BatchIndex: batchIndex, EndBlockNumber: endBlockNumber}, nil
+	}
+	return nil, nil
+}
+
+func (r *dbBatchReader) BatchesByL1Block(_ context.Context, l1BlockNumber uint64) (*L1BlockBatches, error) {
+	for {
+		number, committed, finalized, ok := rawdb.ReadBatchesByL1Block(r.db, l1BlockNumber)
+		if !ok {
+			return nil, nil
+		}
+		// skip index entries of events that were replaced, e.g. by a revert and a new commit
+		batches := &L1BlockBatches{L1BlockNumber: number}
+		for _, batchIndex := range committed {
+			if meta := rawdb.ReadBatchL1Meta(r.db, batchIndex); meta != nil && meta.CommitTxHash != (common.Hash{}) && meta.CommitL1BlockNumber == number {
+				batches.Committed = append(batches.Committed, batchIndex)
+			}
+		}
+		for _, batchIndex := range finalized {
+			if meta := rawdb.ReadBatchL1Meta(r.db, batchIndex); meta != nil && meta.FinalizeTxHash != (common.Hash{}) && meta.FinalizeL1BlockNumber == number {
+				batches.Finalized = append(batches.Finalized, batchIndex)
+			}
+		}
+		if len(batches.Committed) > 0 || len(batches.Finalized) > 0 {
+			return batches, nil
+		}
+		l1BlockNumber = number + 1
+	}
+}
+
+func (r *dbBatchReader) L1EndpointRanges(_ context.Context, from, to uint64) ([]*rawdb.L1EndpointRange, error) {
+	return rawdb.ReadL1EndpointRanges(r.db, from, to), nil
+}
+
+func (r *dbBatchReader) BatchGaps(_ context.Context) ([]BatchGap, error) {
+	gaps := []BatchGap{}
+	for _, gap := range readBatchGaps(r.db) {
+		gaps = append(gaps, *gap)
+	}
+	return gaps, nil
+}
+
+func (r *dbBatchReader) RecoveryProgress(_ context.Context) (*RecoveryProgress, error) {
+	return readRecoveryProgress(r.db), nil
+}
+
+func (r *dbBatchReader) L1BatchPointers(_ context.Context) (*L1BatchPointers, error) {
+	return readL1BatchPointers(r.db), nil
+}
+
+// ReadProgress returns the rollup sync progress stored in reader, with the lag of the finalized
+// L2 block behind head. The L1 block lag is only known to the node running the rollup verifier.
+func ReadProgress(ctx context.Context, reader BatchReader, head uint64) (*ethereum.RollupSyncProgress, error) {
+	progress := new(ethereum.RollupSyncProgress)
+	number, err := reader.RollupEventSyncedL1BlockNumber(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if number != nil {
+		progress.L1ProcessedBlock = *number
+	}
+	lastCommitted, err := reader.LastCommittedBatch(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if lastCommitted != nil {
+		progress.LatestCommittedBatch = &lastCommitted.BatchIndex
+	}
+	if progress.FinalizedL2Block, err = reader.FinalizedL2BlockNumber(ctx); err != nil {
+		return nil, err
+	}
+
+	switch {
+	case progress.FinalizedL2Block == nil:
+		progress.FinalizedL2BlockLag = head
+		return progress, nil
+	case head > *progress.FinalizedL2Block:
+		progress.FinalizedL2BlockLag = head - *progress.FinalizedL2Block
+	}
+
+	// note: the finalized batch is only reported if it ends at the finalized L2 block, see lastFinalizedBatchIndex.
+	batchIndex, err := reader.BatchIndexByL2BlockNumber(ctx, *progress.FinalizedL2Block)
+	if err != nil || batchIndex == nil {
+		return progress, err
+	}
+	meta, err := reader.FinalizedBatchMeta(ctx, *batchIndex)
+	if err != nil || meta == nil {
+		return progress, err
+	}
+	chunkBlockRanges, err := reader.BatchChunkRanges(ctx, *batchIndex)
+	if err != nil {
+		return nil, err
+	}
+	if len(chunkBlockRanges) > 0 && chunkBlockRanges[len(chunkBlockRanges)-1].EndBlockNumber == *progress.FinalizedL2Block {
+		progress.LatestFinalizedBatch = batchIndex
+	}
+	return progress, nil
+}
+
+// BatchReaderAPI serves a BatchReader over RPC, so that follower nodes can share the
+// rollup metadata of one node running the rollup verifier, see NewRemoteBatchReader.
+type BatchReaderAPI struct {
+	reader BatchReader
+}
+
+// NewBatchReaderAPI creates the RPC service of reader.
+func NewBatchReaderAPI(reader BatchReader) *BatchReaderAPI {
+	return &BatchReaderAPI{reader: reader}
+}
+
+func (api *BatchReaderAPI) FinalizedBatchMeta(ctx context.Context, batchIndex uint64) (*rawdb.FinalizedBatchMeta, error) {
+	return api.reader.FinalizedBatchMeta(ctx, batchIndex)
+}
+
+func (api *BatchReaderAPI) BatchChunkRanges(ctx context.Context, batchIndex uint64) ([]*rawdb.ChunkBlockRange, error) {
+	return api.reader.BatchChunkRanges(ctx, batchIndex)
+}
+
+func (api *BatchReaderAPI) BatchL1Meta(ctx context.Context, batchIndex uint64) (*rawdb.BatchL1Meta, error) {
+	return api.reader.BatchL1Meta(ctx, batchIndex)
+}
+
+func (api *BatchReaderAPI) BatchIndexByL2BlockNumber(ctx context.Context, l2BlockNumber uint64) (*uint64, error) {
+	return api.reader.BatchIndexByL2BlockNumber(ctx, l2BlockNumber)
+}
+
+func (api *BatchReaderAPI) FinalizedL2BlockNumber(ctx context.Context) (*uint64, error) {
+	return api.reader.FinalizedL2BlockNumber(ctx)
+}
+
+func (api *BatchReaderAPI) RollupEventSyncedL1BlockNumber(ctx context.Context) (*uint64, error) {
+	return api.reader.RollupEventSyncedL1BlockNumber(ctx)
+}
+
+func (api *BatchReaderAPI) LastCommittedBatch(ctx context.Context) (*CommittedBatch, error) {
+	return api.reader.LastCommittedBatch(ctx)
+}
+
+func (api *BatchReaderAPI) BatchesByL1Block(ctx context.Context, l1BlockNumber uint64) (*L1BlockBatches, error) {
+	return api.reader.BatchesByL1Block(ctx, l1BlockNumber)
+}
+
+func (api *BatchReaderAPI) L1EndpointRanges(ctx context.Context, from, to uint64) ([]*rawdb.L1EndpointRange, error) {
+	return api.reader.L1EndpointRanges(ctx, from, to)
+}
+
+func (api *BatchReaderAPI) BatchGaps(ctx context.Context) ([]BatchGap, error) {
+	return api.reader.BatchGaps(ctx)
+}
+
+func (api *BatchReaderAPI) RecoveryProgress(ctx context.Context) (*RecoveryProgress, error) {
+	return api.reader.RecoveryProgress(ctx)
+}
+
+func (api *BatchReaderAPI) L1BatchPointers(ctx context.Context) (*L1BatchPointers, error) {
+	return api.reader.L1BatchPointers(ctx)
+}
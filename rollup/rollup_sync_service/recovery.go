@@ -0,0 +1,145 @@
+package rollup_sync_service
+
+import (
+	"time"
+
+	"github.com/scroll-tech/go-ethereum/common"
+	"github.com/scroll-tech/go-ethereum/core/rawdb"
+	"github.com/scroll-tech/go-ethereum/ethdb"
+	"github.com/scroll-tech/go-ethereum/log"
+	"github.com/scroll-tech/go-ethereum/metrics"
+)
+
+// recoveryLagThreshold is the number of unprocessed L1 blocks from which a fetch round is
+// tracked as a catch-up, about 4 hours of L1 blocks. Below it rounds finish within minutes.
+const recoveryLagThreshold = 1200
+
+var (
+	recoveryBatchRateGauge = metrics.NewRegisteredGaugeFloat64("rollup/sync/recovery/batchrate", nil)
+	recoveryETAGauge       = metrics.NewRegisteredGauge("rollup/sync/recovery/eta", nil)
+	recoveryL1HeightGauge  = metrics.NewRegisteredGauge("rollup/sync/recovery/l1height", nil)
+)
+
+// RecoveryProgress reports the progress of a catch-up of the rollup sync service over a long
+// range of L1 blocks, e.g. when recovering all batches from L1. The rates are averages over the
+// whole catch-up, the time the node was down is not accounted for.
+type RecoveryProgress struct {
+	StartL1Block      uint64  `json:"startL1Block"`
+	L1Height          uint64  `json:"l1Height"`      // last processed L1 block
+	TargetL1Block     uint64  `json:"targetL1Block"` // latest confirmed L1 block of the current fetch round
+	BatchesDerived    uint64  `json:"batchesDerived"`
+	BatchesPerSecond  float64 `json:"batchesPerSecond"`
+	L1BlocksPerSecond float64 `json:"l1BlocksPerSecond"`
+	ElapsedSeconds    uint64  `json:"elapsedSeconds"`
+	ETASeconds        *uint64 `json:"etaSeconds,omitempty"` // nil until a L1 block rate is known
+}
+
+// newRecoveryProgress computes the rates and the remaining time of a catch-up that has
+// processed the L1 blocks up to l1Height.
+func newRecoveryProgress(recovery *rawdb.RollupSyncRecovery, l1Height uint64) *RecoveryProgress {
+	progress := &RecoveryProgress{
+		StartL1Block:   recovery.StartL1BlockNumber,
+		L1Height:       l1Height,
+		TargetL1Block:  recovery.TargetL1BlockNumber,
+		BatchesDerived: recovery.BatchesDerived,
+		ElapsedSeconds: uint64(time.Duration(recovery.Elapsed) / time.Second),
+	}
+	elapsed := time.Duration(recovery.Elapsed).Seconds()
+	if elapsed == 0 || l1Height <= recovery.StartL1BlockNumber {
+		return progress
+	}
+	progress.BatchesPerSecond = float64(recovery.BatchesDerived) / elapsed
+	progress.L1BlocksPerSecond = float64(l1Height-recovery.StartL1BlockNumber) / elapsed
+
+	var eta uint64
+	if recovery.TargetL1BlockNumber > l1Height {
+		eta = uint64(float64(recovery.TargetL1BlockNumber-l1Height) / progress.L1BlocksPerSecond)
+	}
+	progress.ETASeconds = &eta
+	return progress
+}
+
+// RecoveryProgress returns the progress of the running catch-up, or nil if the service is
+// within recoveryLagThreshold blocks of the L1 chain.
+func (s *RollupSyncService) RecoveryProgress() *RecoveryProgress {
+	return readRecoveryProgress(s.db)
+}
+
+// readRecoveryProgress returns the progress of the catch-up stored in db, if any.
+func readRecoveryProgress(db ethdb.Reader) *RecoveryProgress {
+	recovery := rawdb.ReadRollupSyncRecovery(db)
+	if recovery == nil {
+		return nil
+	}
+	l1Height := recovery.StartL1BlockNumber
+	if number := rawdb.ReadRollupEventSyncedL1BlockNumber(db); number != nil {
+		l1Height = *number
+	}
+	return newRecoveryProgress(recovery, l1Height)
+}
+
+// startRecoveryRound starts tracking a catch-up if the fetch round from the latest processed
+// block to latestConfirmed spans at least recoveryLagThreshold blocks. A catch-up interrupted
+// by a restart or a failed round is continued.
+func (s *RollupSyncService) startRecoveryRound(latestConfirmed uint64) {
+	s.recoveryUpdated = time.Now()
+
+	recovery := rawdb.ReadRollupSyncRecovery(s.db)
+	switch {
+	case recovery != nil && s.latestProcessedBlock < recovery.StartL1BlockNumber:
+		// the sync progress was reset behind the catch-up, start it anew
+		recovery = nil
+	case recovery == nil && latestConfirmed < s.latestProcessedBlock+recoveryLagThreshold:
+		return
+	}
+	if recovery == nil {
+		recovery = &rawdb.RollupSyncRecovery{StartL1BlockNumber: s.latestProcessedBlock}
+		log.Info("Rollup sync catching up with L1", "from", s.latestProcessedBlock, "to", latestConfirmed)
+	}
+	recovery.TargetL1BlockNumber = latestConfirmed
+	rawdb.WriteRollupSyncRecovery(s.db, recovery)
+}
+
+// updateRecovery accounts a processed range that ends at l1Height and finalized the given
+// number of batches to the running catch-up, if any.
+func (s *RollupSyncService) updateRecovery(l1Height, batches uint64) {
+	recovery := rawdb.ReadRollupSyncRecovery(s.db)
+	if recovery == nil {
+		return
+	}
+	now := time.Now()
+	recovery.Elapsed += uint64(now.Sub(s.recoveryUpdated))
+	recovery.BatchesDerived += batches
+	s.recoveryUpdated = now
+
+	if l1Height >= recovery.TargetL1BlockNumber {
+		progress := newRecoveryProgress(recovery, l1Height)
+		log.Info("Rollup sync caught up with L1", "from", recovery.StartL1BlockNumber, "to", l1Height, "batches", recovery.BatchesDerived, "elapsed", common.PrettyDuration(recovery.Elapsed), "batches/s", progress.BatchesPerSecond)
+		rawdb.DeleteRollupSyncRecovery(s.db)
+		recoveryBatchRateGauge.Update(0)
+		recoveryETAGauge.Update(0)
+		return
+	}
+	rawdb.WriteRollupSyncRecovery(s.db, recovery)
+
+	progress := newRecoveryProgress(recovery, l1Height)
+	recoveryBatchRateGauge.Update(progress.BatchesPerSecond)
+	recoveryL1HeightGauge.Update(int64(l1Height))
+	if progress.ETASeconds != nil {
+		recoveryETAGauge.Update(int64(*progress.ETASeconds))
+	}
+}
+
+// logRecoveryProgress logs the progress of the running catch-up, if any.
+func (s *RollupSyncService) logRecoveryProgress() {
+	progress := s.RecoveryProgress()
+	if progress == nil {
+		return
+	}
+	ctx := []interface{}{"l1Height", progress.L1Height, "target", progress.TargetL1Block, "batches", progress.BatchesDerived,
+		"batches/s", progress.BatchesPerSecond, "l1Blocks/s", progress.L1BlocksPerSecond, "elapsed", common.PrettyDuration(time.Duration(progress.ElapsedSeconds) * time.Second)}
+	if progress.ETASeconds != nil {
+		ctx = append(ctx, "eta", common.PrettyDuration(time.Duration(*progress.ETASeconds)*time.Second))
+	}
+	log.Info("Rollup sync catch-up progress", ctx...)
+}
@@ -0,0 +1,75 @@
+package rollup_sync_service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/scroll-tech/go-ethereum/core/rawdb"
+)
+
+func TestNewRecoveryProgress(t *testing.T) {
+	recovery := &rawdb.RollupSyncRecovery{StartL1BlockNumber: 1000, TargetL1BlockNumber: 5000, BatchesDerived: 50, Elapsed: uint64(100 * time.Second)}
+
+	progress := newRecoveryProgress(recovery, 2000)
+	assert.Equal(t, uint64(2000), progress.L1Height)
+	assert.Equal(t, uint64(100), progress.ElapsedSeconds)
+	assert.Equal(t, 0.5, progress.BatchesPerSecond)
+	assert.Equal(t, 10.0, progress.L1BlocksPerSecond)
+	require.NotNil(t, progress.ETASeconds)
+	assert.Equal(t, uint64(300), *progress.ETASeconds)
+
+	// no rate is known before the first range is processed
+	progress = newRecoveryProgress(&rawdb.RollupSyncRecovery{StartL1BlockNumber: 1000, TargetL1BlockNumber: 5000}, 1000)
+	assert.Zero(t, progress.BatchesPerSecond)
+	assert.Nil(t, progress.ETASeconds)
+}
+
+func TestRecoveryRounds(t *testing.T) {
+	db := rawdb.NewMemoryDatabase()
+	service := &RollupSyncService{db: db, latestProcessedBlock: 100}
+
+	// rounds close to the L1 chain are not tracked
+	service.startRecoveryRound(100 + recoveryLagThreshold - 1)
+	assert.Nil(t, service.RecoveryProgress())
+
+	service.startRecoveryRound(10000)
+	progress := service.RecoveryProgress()
+	require.NotNil(t, progress)
+	assert.Equal(t, uint64(100), progress.StartL1Block)
+	assert.Equal(t, uint64(10000), progress.TargetL1Block)
+
+	service.recoveryUpdated = time.Now().Add(-time.Minute)
+	rawdb.WriteRollupEventSyncedL1BlockNumber(db, 5000)
+	service.latestProcessedBlock = 5000
+	service.updateRecovery(5000, 30)
+	progress = service.RecoveryProgress()
+	require.NotNil(t, progress)
+	assert.Equal(t, uint64(5000), progress.L1Height)
+	assert.Equal(t, uint64(30), progress.BatchesDerived)
+	assert.GreaterOrEqual(t, progress.ElapsedSeconds, uint64(60))
+	require.NotNil(t, progress.ETASeconds)
+
+	// a restarted round continues the catch-up with the new target
+	service.startRecoveryRound(12000)
+	progress = service.RecoveryProgress()
+	require.NotNil(t, progress)
+	assert.Equal(t, uint64(100), progress.StartL1Block)
+	assert.Equal(t, uint64(12000), progress.TargetL1Block)
+	assert.Equal(t, uint64(30), progress.BatchesDerived)
+
+	// reaching the target finishes the catch-up
+	service.updateRecovery(12000, 10)
+	assert.Nil(t, service.RecoveryProgress())
+
+	// a reset behind the start of a catch-up starts it anew
+	service.latestProcessedBlock = 5000
+	service.startRecoveryRound(12000)
+	service.latestProcessedBlock = 50
+	service.startRecoveryRound(12000)
+	progress = service.RecoveryProgress()
+	require.NotNil(t, progress)
+	assert.Equal(t, uint64(50), progress.StartL1Block)
+}
@@ -0,0 +1,123 @@
+package rollup_sync_service
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/scroll-tech/go-ethereum/core"
+	"github.com/scroll-tech/go-ethereum/core/rawdb"
+	"github.com/scroll-tech/go-ethereum/ethdb"
+	"github.com/scroll-tech/go-ethereum/log"
+)
+
+// BatchFieldMismatch is a field of a finalized batch whose stored value differs
+// from the value recomputed from the local chain.
+type BatchFieldMismatch struct {
+	Field    string `json:"field"`
+	Stored   string `json:"stored"`
+	Computed string `json:"computed"`
+}
+
+// RevalidationResult is the outcome of re-validating a finalized batch against the local chain.
+type RevalidationResult struct {
+	BatchIndex       uint64               `json:"batchIndex"`
+	StartBlockNumber uint64               `json:"startBlockNumber"`
+	EndBlockNumber   uint64               `json:"endBlockNumber"`
+	Valid            bool                 `json:"valid"`
+	Poisoned         bool                 `json:"poisoned"` // the batch failed validation when it was finalized
+	Mismatches       []BatchFieldMismatch `json:"mismatches"`
+}
+
+// RevalidateBatch re-reads the local blocks of a finalized batch and recomputes its
+// metadata, comparing it against the metadata stored when the batch was finalized.
+// It does not modify the database.
+func (s *RollupSyncService) RevalidateBatch(batchIndex uint64) (*RevalidationResult, error) {
+	stored := rawdb.ReadFinalizedBatchMeta(s.db, batchIndex)
+	if stored == nil {
+		return nil, fmt.Errorf("batch %v is not finalized", batchIndex)
+	}
+	chunkBlockRanges := rawdb.ReadBatchChunkRanges(s.db, batchIndex)
+	if len(chunkBlockRanges) == 0 {
+		return nil, fmt.Errorf("failed to get batch chunk ranges, batch index: %v", batchIndex)
+	}
+	parentBatchMeta := &rawdb.FinalizedBatchMeta{}
+	if batchIndex > 0 {
+		if parentBatchMeta = rawdb.ReadFinalizedBatchMeta(s.db, batchIndex-1); parentBatchMeta == nil {
+			return nil, fmt.Errorf("parent batch %v is not finalized", batchIndex-1)
+		}
+	}
+	codec, err := s.codecForBatch(batchIndex)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.checkBlockAvailability(batchIndex, chunkBlockRanges); err != nil {
+		return nil, err
+	}
+	chunks, err := s.loadChunks(chunkBlockRanges)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load local blocks, batch index: %v, err: %w", batchIndex, err)
+	}
+	endBlock, computed, err := computeFinalizedBatchMeta(codec, batchIndex, parentBatchMeta, chunks)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &RevalidationResult{
+		BatchIndex:       batchIndex,
+		StartBlockNumber: chunkBlockRanges[0].StartBlockNumber,
+		EndBlockNumber:   endBlock,
+		Poisoned:         rawdb.ReadPoisonedBatch(s.db, batchIndex) != nil,
+		Mismatches:       []BatchFieldMismatch{},
+	}
+	if stored.BatchHash != computed.BatchHash {
+		result.Mismatches = append(result.Mismatches, BatchFieldMismatch{Field: "batchHash", Stored: stored.BatchHash.Hex(), Computed: computed.BatchHash.Hex()})
+	}
+	if stored.StateRoot != computed.StateRoot {
+		result.Mismatches = append(result.Mismatches, BatchFieldMismatch{Field: "stateRoot", Stored: stored.StateRoot.Hex(), Computed: computed.StateRoot.Hex()})
+	}
+	if stored.WithdrawRoot != computed.WithdrawRoot {
+		result.Mismatches = append(result.Mismatches, BatchFieldMismatch{Field: "withdrawRoot", Stored: stored.WithdrawRoot.Hex(), Computed: computed.WithdrawRoot.Hex()})
+	}
+	if stored.TotalL1MessagePopped != computed.TotalL1MessagePopped {
+		result.Mismatches = append(result.Mismatches, BatchFieldMismatch{Field: "totalL1MessagePopped", Stored: fmt.Sprint(stored.TotalL1MessagePopped), Computed: fmt.Sprint(computed.TotalL1MessagePopped)})
+	}
+	result.Valid = len(result.Mismatches) == 0
+
+	if result.Valid {
+		log.Info("Re-validated finalized batch", "batch index", batchIndex, "start block", result.StartBlockNumber, "end block", endBlock)
+	} else {
+		log.Error("Finalized batch failed re-validation", "batch index", batchIndex, "start block", result.StartBlockNumber, "end block", endBlock, "mismatches", result.Mismatches)
+	}
+	return result, nil
+}
+
+// RevalidateBatches re-validates the finalized batches [from, to] against the local chain without
+// an L1 connection, e.g. after restoring a database. fn is called with the result of every batch,
+// and re-validation stops at the first error of fn or if ctx is cancelled.
+func RevalidateBatches(ctx context.Context, db ethdb.Database, bc *core.BlockChain, config *Config, from, to uint64, fn func(*RevalidationResult) error) error {
+	if from > to {
+		return fmt.Errorf("invalid batch range, from: %v, to: %v", from, to)
+	}
+	if config == nil {
+		config = &Config{}
+	}
+	s := &RollupSyncService{ctx: ctx, db: db, bc: bc, stateReexec: config.StateReexec, validationWorkers: 1}
+	if config.ValidationWorkers > 0 {
+		s.validationWorkers = config.ValidationWorkers
+	}
+
+	for batchIndex := from; batchIndex <= to; batchIndex++ {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		result, err := s.RevalidateBatch(batchIndex)
+		if err != nil {
+			return fmt.Errorf("failed to re-validate batch %v: %w", batchIndex, err)
+		}
+		if err := fn(result); err != nil {
+			return err
+		}
+	}
+	return nil
+}
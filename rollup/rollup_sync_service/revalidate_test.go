@@ -0,0 +1,69 @@
+package rollup_sync_service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/scroll-tech/go-ethereum/common"
+	"github.com/scroll-tech/go-ethereum/consensus/ethash"
+	"github.com/scroll-tech/go-ethereum/core"
+	"github.com/scroll-tech/go-ethereum/core/rawdb"
+	"github.com/scroll-tech/go-ethereum/core/vm"
+	"github.com/scroll-tech/go-ethereum/params"
+)
+
+func TestRevalidateBatch(t *testing.T) {
+	db := rawdb.NewMemoryDatabase()
+	genesis := (&core.Genesis{Config: params.TestChainConfig}).MustCommit(db)
+	blocks, _ := core.GenerateChain(params.TestChainConfig, genesis, ethash.NewFaker(), rawdb.NewMemoryDatabase(), 5, func(i int, b *core.BlockGen) {
+		b.SetCoinbase(common.Address{byte(i + 1)})
+	})
+	bc, err := core.NewBlockChain(db, nil, params.TestChainConfig, ethash.NewFaker(), vm.Config{}, nil, nil)
+	require.NoError(t, err)
+	defer bc.Stop()
+	_, err = bc.InsertChain(blocks)
+	require.NoError(t, err)
+
+	service := &RollupSyncService{ctx: context.Background(), db: db, bc: bc}
+	_, err = service.RevalidateBatch(1)
+	assert.ErrorContains(t, err, "not finalized")
+
+	chunkBlockRanges := []*rawdb.ChunkBlockRange{{StartBlockNumber: 1, EndBlockNumber: 2}, {StartBlockNumber: 3, EndBlockNumber: 5}}
+	rawdb.WriteBatchChunkRanges(db, 1, chunkBlockRanges)
+	rawdb.WriteFinalizedBatchMeta(db, 0, &rawdb.FinalizedBatchMeta{})
+	chunks, err := service.loadChunks(chunkBlockRanges)
+	require.NoError(t, err)
+	_, meta, err := computeFinalizedBatchMeta(codecV0{}, 1, &rawdb.FinalizedBatchMeta{}, chunks)
+	require.NoError(t, err)
+	rawdb.WriteFinalizedBatchMeta(db, 1, meta)
+
+	result, err := service.RevalidateBatch(1)
+	require.NoError(t, err)
+	assert.True(t, result.Valid)
+	assert.Equal(t, uint64(1), result.StartBlockNumber)
+	assert.Equal(t, uint64(5), result.EndBlockNumber)
+	assert.Empty(t, result.Mismatches)
+
+	// a corrupted state root is reported without touching the stored data
+	corrupted := *meta
+	corrupted.StateRoot = common.Hash{0x01}
+	rawdb.WriteFinalizedBatchMeta(db, 1, &corrupted)
+	result, err = service.RevalidateBatch(1)
+	require.NoError(t, err)
+	assert.False(t, result.Valid)
+	assert.Equal(t, []BatchFieldMismatch{{Field: "stateRoot", Stored: corrupted.StateRoot.Hex(), Computed: meta.StateRoot.Hex()}}, result.Mismatches)
+	assert.Equal(t, &corrupted, rawdb.ReadFinalizedBatchMeta(db, 1))
+
+	// the range variant reports every batch and stops at the first batch that cannot be re-validated
+	var results []*RevalidationResult
+	err = RevalidateBatches(context.Background(), db, bc, nil, 1, 2, func(result *RevalidationResult) error {
+		results = append(results, result)
+		return nil
+	})
+	assert.ErrorContains(t, err, "failed to re-validate batch 2")
+	require.Len(t, results, 1)
+	assert.False(t, results[0].Valid)
+}
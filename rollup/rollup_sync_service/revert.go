@@ -0,0 +1,82 @@
+package rollup_sync_service
+
+import (
+	"fmt"
+
+	"github.com/scroll-tech/go-ethereum/core/rawdb"
+	"github.com/scroll-tech/go-ethereum/core/types"
+	"github.com/scroll-tech/go-ethereum/log"
+
+	"github.com/scroll-tech/go-ethereum/rollup/eventbus"
+)
+
+// revertBatches handles a RevertBatch event. ScrollChain only reverts unfinalized batches up to the
+// last committed batch, so all records of the batches at and above the reverted index are removed:
+// chunk ranges, the block to batch index, L1 metadata and poisoned markers. A revert record is kept
+// for each removed batch. Subscribers and hooks are notified of the batch of the event only, the
+// contract emits one event for every reverted batch.
+func (s *RollupSyncService) revertBatches(event *L1RevertBatchEvent, vLog *types.Log) error {
+	batchIndex := event.BatchIndex.Uint64()
+
+	indices := rawdb.ReadCommittedBatchIndices(s.db, batchIndex)
+	if len(indices) == 0 || indices[0] != batchIndex {
+		indices = append([]uint64{batchIndex}, indices...)
+	}
+	for _, index := range indices {
+		if s.readFinalizedBatchMeta(index) != nil {
+			return fmt.Errorf("fatal: RevertBatch event of batch %d reverts finalized batch %d", batchIndex, index)
+		}
+	}
+
+	if s.l1Follower {
+		if err := s.rewindDerivedBlocks(indices[0]); err != nil {
+			return err
+		}
+	}
+
+	s.unwindBatches(indices, vLog, func(index uint64) *rawdb.RevertedBatch {
+		if index == batchIndex {
+			return &rawdb.RevertedBatch{BatchHash: event.BatchHash, Reason: "RevertBatch event"}
+		}
+		return &rawdb.RevertedBatch{Reason: fmt.Sprintf("parent batch %d reverted", batchIndex)}
+	})
+	log.Info("Reverted batches", "batch index", batchIndex, "reverted", len(indices), "L1 block", vLog.BlockNumber, "tx", vLog.TxHash)
+
+	s.bus.PublishBatchReverted(eventbus.BatchRevertedEvent{
+		BatchIndex:    batchIndex,
+		BatchHash:     event.BatchHash,
+		L1BlockNumber: vLog.BlockNumber,
+		L1TxHash:      vLog.TxHash,
+	})
+	return runBatchHooks("revert", batchIndex, func(hook BatchHook) error {
+		return hook.OnBatchReverted(&BatchRevertInfo{BatchIndex: batchIndex, BatchHash: event.BatchHash, L1Log: vLog})
+	})
+}
+
+// unwindBatches removes all records of the given batches and stores a revert record for each
+// of them, record returns the batch hash and reason of the revert of a batch. The indices are
+// in ascending order, the lowest one becomes the next batch to be committed.
+func (s *RollupSyncService) unwindBatches(indices []uint64, vLog *types.Log, record func(index uint64) *rawdb.RevertedBatch) {
+	for _, index := range indices {
+		revertedBatch := record(index)
+		revertedBatch.L1BlockNumber = vLog.BlockNumber
+		revertedBatch.L1TxHash = vLog.TxHash
+		if batchL1Meta := rawdb.ReadBatchL1Meta(s.db, index); batchL1Meta != nil {
+			revertedBatch.CommitTxHash = batchL1Meta.CommitTxHash
+		} else if prev := rawdb.ReadRevertedBatch(s.db, index); prev != nil && prev.L1TxHash == vLog.TxHash {
+			// the batch has already been removed by this transaction, with an earlier event or before a restart
+			revertedBatch.CommitTxHash = prev.CommitTxHash
+		}
+		rawdb.WriteRevertedBatch(s.db, index, revertedBatch)
+	}
+	if len(indices) == 0 {
+		return
+	}
+	// all batches above the lowest one are reverted, so they are removed as one range
+	s.deleteBatchRange(indices[0], indices[len(indices)-1])
+	if indices[0] > 0 {
+		committedBatchGauge.Update(int64(indices[0] - 1))
+	} else {
+		committedBatchGauge.Update(0)
+	}
+}
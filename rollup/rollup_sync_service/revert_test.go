@@ -0,0 +1,104 @@
+package rollup_sync_service
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/scroll-tech/go-ethereum/common"
+	"github.com/scroll-tech/go-ethereum/core"
+	"github.com/scroll-tech/go-ethereum/core/rawdb"
+	"github.com/scroll-tech/go-ethereum/core/types"
+	"github.com/scroll-tech/go-ethereum/params"
+
+	"github.com/scroll-tech/go-ethereum/rollup/eventbus"
+)
+
+func TestRevertBatches(t *testing.T) {
+	genesisConfig := &params.ChainConfig{
+		Scroll: params.ScrollConfig{
+			L1Config: &params.L1Config{
+				L1ChainId:          11155111,
+				ScrollChainAddress: common.HexToAddress("0x2D567EcE699Eabe5afCd141eDB7A4f2D0D6ce8a0"),
+			},
+		},
+	}
+	db := rawdb.NewMemoryDatabase()
+	service, err := NewRollupSyncService(context.Background(), genesisConfig, db, &mockEthClient{}, &core.BlockChain{}, 1, &Config{VerifyMode: VerifyModeHaltSync}, nil)
+	require.NoError(t, err)
+
+	// batch 3 is finalized, batches 4 to 6 are committed
+	for batchIndex := uint64(3); batchIndex <= 6; batchIndex++ {
+		service.writeBatchChunkRanges(batchIndex, []*rawdb.ChunkBlockRange{{StartBlockNumber: batchIndex*10 + 1, EndBlockNumber: batchIndex*10 + 10}})
+		rawdb.WriteBatchEndBlock(db, batchIndex*10+10, batchIndex)
+		rawdb.WriteBatchL1Meta(db, batchIndex, &rawdb.BatchL1Meta{CommitTxHash: common.BigToHash(big.NewInt(int64(batchIndex)))})
+	}
+	service.writeFinalizedBatchMeta(3, &rawdb.FinalizedBatchMeta{BatchHash: common.HexToHash("0x03")})
+	rawdb.WritePoisonedBatch(db, 6, &rawdb.PoisonedBatch{Reason: "test"})
+
+	ch := make(chan eventbus.BatchRevertedEvent, 2)
+	sub := service.bus.SubscribeBatchReverted(ch)
+	defer sub.Unsubscribe()
+
+	revertLog := func(batchIndex uint64, batchHash common.Hash) types.Log {
+		return types.Log{
+			Topics:      []common.Hash{service.l1RevertBatchEventSignature, common.BigToHash(new(big.Int).SetUint64(batchIndex)), batchHash},
+			BlockNumber: 100,
+			TxHash:      common.HexToHash("0xabcd"),
+		}
+	}
+	// the contract emits one event for each reverted batch
+	logs := []types.Log{revertLog(5, common.HexToHash("0x05")), revertLog(6, common.HexToHash("0x06"))}
+	require.NoError(t, service.parseAndUpdateRollupEventLogs(logs[:1], 100, nil))
+
+	// batch 5 and all batches above are removed
+	for batchIndex := uint64(5); batchIndex <= 6; batchIndex++ {
+		assert.Nil(t, service.readBatchChunkRanges(batchIndex))
+		assert.Nil(t, rawdb.ReadBatchL1Meta(db, batchIndex))
+		assert.Nil(t, rawdb.ReadBatchIndexByL2BlockNumber(db, batchIndex*10+5))
+	}
+	assert.Nil(t, rawdb.ReadPoisonedBatch(db, 6))
+	assert.Equal(t, &rawdb.RevertedBatch{
+		BatchHash:     common.HexToHash("0x05"),
+		Reason:        "RevertBatch event",
+		L1BlockNumber: 100,
+		L1TxHash:      common.HexToHash("0xabcd"),
+		CommitTxHash:  common.BigToHash(big.NewInt(5)),
+	}, rawdb.ReadRevertedBatch(db, 5))
+	assert.Equal(t, "parent batch 5 reverted", rawdb.ReadRevertedBatch(db, 6).Reason)
+	assert.Equal(t, common.Hash{}, rawdb.ReadRevertedBatch(db, 6).BatchHash)
+
+	// the batches below are kept
+	assert.NotNil(t, service.readBatchChunkRanges(4))
+	assert.NotNil(t, rawdb.ReadBatchL1Meta(db, 4))
+	assert.Equal(t, uint64(4), *rawdb.ReadBatchIndexByL2BlockNumber(db, 45))
+	assert.Nil(t, rawdb.ReadRevertedBatch(db, 4))
+
+	// the event of the next batch completes its revert record
+	require.NoError(t, service.parseAndUpdateRollupEventLogs(logs[1:], 100, nil))
+	assert.Equal(t, &rawdb.RevertedBatch{
+		BatchHash:     common.HexToHash("0x06"),
+		Reason:        "RevertBatch event",
+		L1BlockNumber: 100,
+		L1TxHash:      common.HexToHash("0xabcd"),
+		CommitTxHash:  common.BigToHash(big.NewInt(6)),
+	}, rawdb.ReadRevertedBatch(db, 6))
+
+	// subscribers are notified of the batch of each event
+	for _, want := range []uint64{5, 6} {
+		select {
+		case ev := <-ch:
+			assert.Equal(t, want, ev.BatchIndex)
+		default:
+			t.Fatalf("no BatchRevertedEvent received for batch %d", want)
+		}
+	}
+
+	// finalized batches cannot be reverted
+	assert.Error(t, service.parseAndUpdateRollupEventLogs([]types.Log{revertLog(3, common.HexToHash("0x03"))}, 100, nil))
+	assert.NotNil(t, service.readBatchChunkRanges(3))
+	assert.NotNil(t, service.readBatchChunkRanges(4))
+}
@@ -3,12 +3,20 @@ package rollup_sync_service
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
 	"reflect"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"syscall"
 	"time"
 
+	"golang.org/x/sync/errgroup"
+	"golang.org/x/time/rate"
+
+	"github.com/scroll-tech/go-ethereum"
 	"github.com/scroll-tech/go-ethereum/accounts/abi"
 	"github.com/scroll-tech/go-ethereum/common"
 	"github.com/scroll-tech/go-ethereum/core"
@@ -16,17 +24,25 @@ import (
 	"github.com/scroll-tech/go-ethereum/core/types"
 	"github.com/scroll-tech/go-ethereum/ethdb"
 	"github.com/scroll-tech/go-ethereum/log"
+	"github.com/scroll-tech/go-ethereum/metrics"
 	"github.com/scroll-tech/go-ethereum/params"
 
-	"github.com/scroll-tech/go-ethereum/rollup/rcfg"
+	"github.com/scroll-tech/go-ethereum/rollup/da"
+	"github.com/scroll-tech/go-ethereum/rollup/diaglog"
+	"github.com/scroll-tech/go-ethereum/rollup/eventbus"
+	"github.com/scroll-tech/go-ethereum/rollup/metasink"
+	"github.com/scroll-tech/go-ethereum/rollup/provertask"
 	"github.com/scroll-tech/go-ethereum/rollup/sync_service"
-	"github.com/scroll-tech/go-ethereum/rollup/withdrawtrie"
 )
 
 const (
 	// defaultFetchBlockRange is the number of blocks that we collect in a single eth_getLogs query.
 	defaultFetchBlockRange = uint64(100)
 
+	// defaultFetchQueueSize is the number of fetched block ranges that are buffered while
+	// the previous ranges are validated.
+	defaultFetchQueueSize = 4
+
 	// defaultSyncInterval is the frequency at which we query for new rollup event.
 	defaultSyncInterval = 60 * time.Second
 
@@ -40,23 +56,93 @@ const (
 
 	// defaultLogInterval is the frequency at which we print the latestProcessedBlock.
 	defaultLogInterval = 5 * time.Minute
+
+	// defaultL1RequestTimeout bounds every request to the L1 client if no timeout is configured.
+	defaultL1RequestTimeout = 30 * time.Second
+)
+
+var (
+	blockContextMismatchCounter = metrics.NewRegisteredCounter("rollup/sync/blockcontext/mismatch", nil)
+	unknownEventCounter         = metrics.NewRegisteredCounter("rollup/sync/events/unknown", nil)
+	batchMismatchCounter        = metrics.NewRegisteredCounter("rollup/sync/batch/mismatch", nil)
+	l1ReorgCounter              = metrics.NewRegisteredCounter("rollup/sync/l1/reorg", nil)
+	l1RPCErrorCounter           = metrics.NewRegisteredCounter("rollup/sync/l1/rpc/errors", nil)
+	batchGapCounter             = metrics.NewRegisteredCounter("rollup/sync/batch/gaps", nil)
+	nonMonotonicBatchCounter    = metrics.NewRegisteredCounter("rollup/sync/batch/nonmonotonic", nil)
+	crossCheckDivergenceCounter = metrics.NewRegisteredCounter("rollup/sync/l1/crosscheck/divergence", nil)
+	poisonedBatchClearedCounter = metrics.NewRegisteredCounter("rollup/sync/batch/poisoned/cleared", nil)
+	poisonedBatchRetriedCounter = metrics.NewRegisteredCounter("rollup/sync/batch/poisoned/retried", nil)
+
+	l1ProcessedBlockGauge  = metrics.NewRegisteredGauge("rollup/sync/l1/processed", nil)
+	committedBatchGauge    = metrics.NewRegisteredGauge("rollup/sync/batch/committed", nil)
+	finalizedBatchGauge    = metrics.NewRegisteredGauge("rollup/sync/batch/finalized", nil)
+	finalizedBlockLagGauge = metrics.NewRegisteredGauge("rollup/sync/l2/finalized/lag", nil)
+	batchValidationTimer   = metrics.NewRegisteredTimer("rollup/sync/batch/validation", nil)
 )
 
+// errBatchMismatch is returned by validateBatch if the batch finalized on L1 does not match the local chain.
+var errBatchMismatch = errors.New("batch mismatch")
+
 // RollupSyncService collects ScrollChain batch commit/revert/finalize events and stores metadata into db.
 type RollupSyncService struct {
-	ctx                           context.Context
-	cancel                        context.CancelFunc
-	client                        *L1Client
-	db                            ethdb.Database
-	latestProcessedBlock          uint64
-	scrollChainABI                *abi.ABI
-	l1CommitBatchEventSignature   common.Hash
-	l1RevertBatchEventSignature   common.Hash
-	l1FinalizeBatchEventSignature common.Hash
-	bc                            *core.BlockChain
+	ctx                                     context.Context
+	cancel                                  context.CancelFunc
+	client                                  *L1Client
+	crossCheckClient                        *L1Client // nil if rollup events are not cross-checked
+	db                                      ethdb.Database
+	l1DeploymentBlock                       uint64
+	latestProcessedBlock                    uint64
+	latestConfirmedBlock                    uint64 // latest confirmed L1 block of the last fetch round, accessed atomically
+	scrollChainABI                          *abi.ABI
+	l1CommitBatchEventSignature             common.Hash
+	l1RevertBatchEventSignature             common.Hash
+	l1FinalizeBatchEventSignature           common.Hash
+	l1FinalizeBundleEventSignature          common.Hash
+	l1UpdateEnforcedBatchModeEventSignature common.Hash
+	bc                                      *core.BlockChain
+	proverTaskQueue                         provertask.Backend
+	proverTasks                             chan *provertask.Task // tasks waiting for the prover task queue
+	metadataSink                            metasink.Sink         // nil if batch metadata is not written to an external store
+	stateReexec                             uint64
+	validationWorkers                       int
+	validationLimiter                       *rate.Limiter // nil if validation is not rate limited
+	unknownEventPolicy                      UnknownEventPolicy
+	ignoredEventTopics                      map[common.Hash]struct{}
+	verifyMode                              VerifyMode
+	halted                                  int32      // set to 1 if syncing is halted at a poisoned batch, accessed atomically
+	paused                                  int32      // set to 1 if syncing is paused by the operator, accessed atomically
+	syncLock                                sync.Mutex // serializes fetch rounds and resets of the sync progress
+	supervisor                              fetchSupervisor
+	confirmations                           uint64
+	bus                                     *eventbus.Bus
+	strictFinalizeOrder                     bool
+	contractFinalizedBatchIndex             uint64 // last known lastFinalizedBatchIndex of the ScrollChain contract, used in strict mode
+
+	gaps     []*BatchGap // batch index gaps that could not be recovered from L1 yet, persisted in the database
+	gapsLock sync.Mutex
+
+	missingBlocks     *MissingBlocksError // result of the last failed block availability check
+	missingBlocksLock sync.Mutex
+
+	batchCache *batchCache // nil disables caching
+
+	chunkRules ChunkRules // invariants of the committed chunks, evaluated on validation and in DAUsage
+
+	l1Follower bool // the local chain is derived from the committed batches
+
+	payloadCache *da.DiskCache // commit transactions by hash, nil if not cached on disk
+
+	blobProvider da.BlobProvider // blobs of the batches committed with blob codecs, nil if not retrieved
+
+	recoveryUpdated time.Time // last time the running catch-up was accounted, only accessed in fetch rounds
+
+	maxL1FinalizedStaleness time.Duration // zero disables the staleness bound of the L1 finalized block
+	l1FinalizedStaleFlag    int32         // set to 1 while the L1 finalized block is stale, accessed atomically
+
+	wg sync.WaitGroup // tracks the sync, metadata sink and prover task loops, so that Stop can wait for them
 }
 
-func NewRollupSyncService(ctx context.Context, genesisConfig *params.ChainConfig, db ethdb.Database, l1Client sync_service.EthClient, bc *core.BlockChain, l1DeploymentBlock uint64) (*RollupSyncService, error) {
+func NewRollupSyncService(ctx context.Context, genesisConfig *params.ChainConfig, db ethdb.Database, l1Client sync_service.EthClient, bc *core.BlockChain, l1DeploymentBlock uint64, config *Config, bus *eventbus.Bus) (*RollupSyncService, error) {
 	// terminate if the caller does not provide an L1 client (e.g. in tests)
 	if l1Client == nil || (reflect.ValueOf(l1Client).Kind() == reflect.Ptr && reflect.ValueOf(l1Client).IsNil()) {
 		log.Warn("No L1 client provided, L1 rollup sync service will not run")
@@ -67,15 +153,37 @@ func NewRollupSyncService(ctx context.Context, genesisConfig *params.ChainConfig
 		return nil, fmt.Errorf("missing L1 config in genesis")
 	}
 
+	if err := checkScrollChainAddress(db, genesisConfig.Scroll.L1Config.ScrollChainAddress); err != nil {
+		return nil, err
+	}
+
 	scrollChainABI, err := scrollChainMetaData.GetAbi()
 	if err != nil {
 		return nil, fmt.Errorf("failed to get scroll chain abi: %w", err)
 	}
 
-	client, err := newL1Client(ctx, l1Client, genesisConfig.Scroll.L1Config.L1ChainId, genesisConfig.Scroll.L1Config.ScrollChainAddress, scrollChainABI)
+	if config == nil {
+		config = &Config{}
+	}
+	l1RequestTimeout := defaultL1RequestTimeout
+	if config.L1RequestTimeout != 0 {
+		l1RequestTimeout = config.L1RequestTimeout
+	}
+
+	client, err := newL1Client(ctx, l1Client, genesisConfig.Scroll.L1Config.L1ChainId, genesisConfig.Scroll.L1Config.ScrollChainAddress, scrollChainABI, l1RequestTimeout)
 	if err != nil {
 		return nil, fmt.Errorf("failed to initialize l1 client: %w", err)
 	}
+	var crossCheckClient *L1Client
+	if config.CrossCheckL1Endpoint != "" {
+		if config.CrossCheckL1Client == nil {
+			return nil, fmt.Errorf("cross-check L1 endpoint %v is not connected", config.CrossCheckL1Endpoint)
+		}
+		crossCheckClient, err = newL1Client(ctx, config.CrossCheckL1Client, genesisConfig.Scroll.L1Config.L1ChainId, genesisConfig.Scroll.L1Config.ScrollChainAddress, scrollChainABI, l1RequestTimeout)
+		if err != nil {
+			return nil, fmt.Errorf("failed to initialize cross-check l1 client: %w", err)
+		}
+	}
 
 	// Initialize the latestProcessedBlock with the block just before the L1 deployment block.
 	// This serves as a default value when there's no L1 rollup events synced in the database.
@@ -90,19 +198,121 @@ func NewRollupSyncService(ctx context.Context, genesisConfig *params.ChainConfig
 		latestProcessedBlock = *block
 	}
 
+	if bus == nil {
+		bus = eventbus.New()
+	}
+
+	unknownEventPolicy := UnknownEventHalt
+	if config.UnknownEventPolicy != "" {
+		if unknownEventPolicy, err = ParseUnknownEventPolicy(string(config.UnknownEventPolicy)); err != nil {
+			return nil, err
+		}
+	}
+	ignoredEventTopics := defaultIgnoredEventTopics(scrollChainABI)
+	for _, topic := range config.IgnoredEventTopics {
+		ignoredEventTopics[topic] = struct{}{}
+	}
+
+	verifyMode := VerifyModeCrash
+	if config.VerifyMode != "" {
+		if verifyMode, err = ParseVerifyMode(string(config.VerifyMode)); err != nil {
+			return nil, err
+		}
+	}
+
+	var proverTaskQueue provertask.Backend
+	if config.ProverTaskQueue != "" {
+		proverTaskQueue, err = provertask.New(config.ProverTaskQueue)
+		if err != nil {
+			return nil, fmt.Errorf("failed to initialize prover task queue: %w", err)
+		}
+	}
+
+	var metadataSink metasink.Sink
+	if config.MetadataSink != "" {
+		metadataSink, err = metasink.New(config.MetadataSink)
+		if err != nil {
+			return nil, fmt.Errorf("failed to initialize metadata sink: %w", err)
+		}
+	}
+
+	var payloadCache *da.DiskCache
+	if config.PayloadCacheDir != "" && config.PayloadCacheSize > 0 {
+		payloadCache, err = da.NewDiskCache(config.PayloadCacheDir, config.PayloadCacheSize)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open payload cache: %w", err)
+		}
+	}
+
+	var blobProvider da.BlobProvider
+	if config.BlobSources != nil {
+		blobProvider, err = da.New(config.BlobSources)
+		if err != nil {
+			return nil, fmt.Errorf("failed to initialize blob sources: %w", err)
+		}
+	}
+
+	chunkRules := DefaultChunkRules
+	if len(config.ChunkLimits) > 0 {
+		chunkRules = NewChunkRules(config.ChunkLimits)
+	}
+
+	validationWorkers := 1
+	if config.ValidationWorkers > 0 {
+		validationWorkers = config.ValidationWorkers
+	}
+	var validationLimiter *rate.Limiter
+	if config.ValidationRateLimit > 0 {
+		validationLimiter = rate.NewLimiter(rate.Limit(config.ValidationRateLimit), int(config.ValidationRateLimit))
+	}
+
 	ctx, cancel := context.WithCancel(ctx)
 
 	service := RollupSyncService{
-		ctx:                           ctx,
-		cancel:                        cancel,
-		client:                        client,
-		db:                            db,
-		latestProcessedBlock:          latestProcessedBlock,
-		scrollChainABI:                scrollChainABI,
-		l1CommitBatchEventSignature:   scrollChainABI.Events["CommitBatch"].ID,
-		l1RevertBatchEventSignature:   scrollChainABI.Events["RevertBatch"].ID,
-		l1FinalizeBatchEventSignature: scrollChainABI.Events["FinalizeBatch"].ID,
-		bc:                            bc,
+		ctx:                                     ctx,
+		cancel:                                  cancel,
+		client:                                  client,
+		crossCheckClient:                        crossCheckClient,
+		db:                                      db,
+		l1DeploymentBlock:                       l1DeploymentBlock,
+		latestProcessedBlock:                    latestProcessedBlock,
+		scrollChainABI:                          scrollChainABI,
+		l1CommitBatchEventSignature:             scrollChainABI.Events["CommitBatch"].ID,
+		l1RevertBatchEventSignature:             scrollChainABI.Events["RevertBatch"].ID,
+		l1FinalizeBatchEventSignature:           scrollChainABI.Events["FinalizeBatch"].ID,
+		l1FinalizeBundleEventSignature:          scrollChainABI.Events["FinalizeBundle"].ID,
+		l1UpdateEnforcedBatchModeEventSignature: scrollChainABI.Events["UpdateEnforcedBatchMode"].ID,
+		bc:                                      bc,
+		proverTaskQueue:                         proverTaskQueue,
+		proverTasks:                             make(chan *provertask.Task, proverTaskBuffer),
+		metadataSink:                            metadataSink,
+		stateReexec:                             config.StateReexec,
+		validationWorkers:                       validationWorkers,
+		validationLimiter:                       validationLimiter,
+		unknownEventPolicy:                      unknownEventPolicy,
+		ignoredEventTopics:                      ignoredEventTopics,
+		verifyMode:                              verifyMode,
+		confirmations:                           config.Confirmations,
+		bus:                                     bus,
+		strictFinalizeOrder:                     config.StrictFinalizeOrder,
+		maxL1FinalizedStaleness:                 config.MaxL1FinalizedStaleness,
+		batchCache:                              newBatchCache(),
+		chunkRules:                              chunkRules,
+		l1Follower:                              config.L1Follower,
+		payloadCache:                            payloadCache,
+		blobProvider:                            blobProvider,
+		gaps:                                    readBatchGaps(db),
+	}
+
+	if poisoned := rawdb.ReadPoisonedBatchIndices(db); len(poisoned) > 0 {
+		log.Error("Found batches that failed validation", "batch indices", poisoned, "verify mode", verifyMode)
+		switch verifyMode {
+		case VerifyModeHaltSync:
+			service.halted = 1
+		case VerifyModeHaltImport:
+			service.halted = 1
+			bc.PauseImport()
+		}
 	}
 
 	return &service, nil
@@ -115,21 +325,71 @@ func (s *RollupSyncService) Start() {
 
 	log.Info("Starting rollup event sync background service", "latest processed block", s.latestProcessedBlock)
 
+	if !s.supervisor.startLoop() {
+		log.Warn("Rollup event sync background service is already running")
+		return
+	}
+	s.startMetadataSink()
+	s.startProverTaskQueue()
+
+	s.wg.Add(1)
 	go func() {
+		defer s.wg.Done()
+		defer s.supervisor.loopStopped()
+
 		syncTicker := time.NewTicker(defaultSyncInterval)
 		defer syncTicker.Stop()
 
 		logTicker := time.NewTicker(defaultLogInterval)
 		defer logTicker.Stop()
 
+		// note: if the L1 endpoint supports log subscriptions, new rollup events trigger
+		// a fetch right away. Polling remains as fallback, e.g. for events that are not
+		// confirmed yet when they are observed.
+		logCh := make(chan types.Log, 16)
+		var (
+			sub    ethereum.Subscription
+			subErr <-chan error
+		)
+		subscribe := func() {
+			var err error
+			if sub, err = s.client.subscribeRollupEvents(s.ctx, logCh); err != nil {
+				log.Debug("Failed to subscribe to rollup events, polling instead", "err", err)
+				sub, subErr = nil, nil
+				return
+			}
+			subErr = sub.Err()
+			log.Info("Subscribed to rollup events")
+		}
+		defer func() {
+			if sub != nil {
+				sub.Unsubscribe()
+			}
+		}()
+		subscribe()
+
 		for {
 			select {
 			case <-s.ctx.Done():
 				return
+			case <-logCh:
+				// drain the events observed together, they are fetched in a single round
+				for len(logCh) > 0 {
+					<-logCh
+				}
+				s.fetchRollupEvents()
+			case err := <-subErr:
+				log.Warn("Rollup event subscription failed, falling back to polling", "err", err)
+				sub.Unsubscribe()
+				sub, subErr = nil, nil
 			case <-syncTicker.C:
+				if sub == nil {
+					subscribe()
+				}
 				s.fetchRollupEvents()
 			case <-logTicker.C:
 				log.Info("Sync rollup events progress update", "latestProcessedBlock", s.latestProcessedBlock)
+				s.logRecoveryProgress()
 			}
 		}
 	}()
@@ -145,60 +405,187 @@ func (s *RollupSyncService) Stop() {
 	if s.cancel != nil {
 		s.cancel()
 	}
+	// note: all L1 requests are bound by the service context and the request timeout,
+	// so the sync loop exits within a bounded time.
+	s.wg.Wait()
+
+	if s.proverTaskQueue != nil {
+		s.proverTaskQueue.Close()
+	}
+	if s.metadataSink != nil {
+		s.metadataSink.Close()
+	}
 }
 
 func (s *RollupSyncService) fetchRollupEvents() {
-	latestConfirmed, err := s.client.getLatestFinalizedBlockNumber(s.ctx)
+	if atomic.LoadInt32(&s.paused) == 1 {
+		log.Trace("Rollup event sync is paused")
+		return
+	}
+
+	s.syncLock.Lock()
+	defer s.syncLock.Unlock()
+
+	if atomic.LoadInt32(&s.halted) == 1 {
+		log.Trace("Rollup event sync is halted at a poisoned batch")
+		return
+	}
+	// note: also update the markers if no events are processed, as the safe block follows the local head.
+	defer s.updateFinalityMarkers()
+
+	s.retryBatchGaps()
+
+	latestConfirmed, err := s.client.getLatestConfirmedBlockNumber(s.ctx, s.confirmations)
 	if err != nil {
 		log.Warn("failed to get latest confirmed block number", "err", err)
 		return
 	}
+	atomic.StoreUint64(&s.latestConfirmedBlock, latestConfirmed)
+	s.refreshL1Finalized(latestConfirmed)
+
+	if s.confirmations > 0 {
+		if err := s.handleL1Reorg(); err != nil {
+			log.Error("failed to handle L1 reorg", "err", err)
+			return
+		}
+	}
 
 	log.Trace("Sync service fetch rollup events", "latest processed block", s.latestProcessedBlock, "latest confirmed", latestConfirmed)
 
+	// note: ranges are fetched ahead while the previous ranges are validated,
+	// so that L1 requests and local block and state reads overlap. Fetching stops
+	// once the round is superseded by a reset of the sync progress.
+	ctx, generation := s.supervisor.beginRound(s.ctx)
+	defer s.supervisor.endRound(generation)
+
+	s.startRecoveryRound(latestConfirmed)
+	s.scanBatchPointers(ctx, latestConfirmed)
+
+	ranges := make(chan *fetchedRange, defaultFetchQueueSize)
+	go s.fetchRanges(ctx, s.latestProcessedBlock+1, latestConfirmed, ranges)
+
+	for r := range ranges {
+		if !s.supervisor.current(generation) {
+			supersededRoundCounter.Inc(1)
+			log.Info("Fetch round superseded, dropping fetched rollup events", "from", r.from)
+			return
+		}
+		finalizedBefore, _ := s.lastFinalizedBatchIndex()
+
+		var checkpoint *rawdb.RollupSyncCheckpoint
+		if s.confirmations > 0 {
+			var err error
+			if checkpoint, err = s.newCheckpoint(r.logs, r.to); err != nil {
+				log.Error("failed to create rollup sync checkpoint", "L1 block number", r.to, "err", err)
+				return
+			}
+		}
+
+		// note: the synced L1 block and the checkpoint are persisted only after the range has been validated.
+		if err := s.parseAndUpdateRollupEventLogs(r.logs, r.to, checkpoint); err != nil {
+			log.Error("failed to parse and update rollup event logs", "err", err)
+			return
+		}
+
+		if len(r.endpoints) > 0 {
+			rawdb.WriteL1EndpointRange(s.db, &rawdb.L1EndpointRange{FromL1BlockNumber: r.from, ToL1BlockNumber: r.to, Endpoints: r.endpoints})
+		}
+
+		s.latestProcessedBlock = r.to
+		l1ProcessedBlockGauge.Update(int64(r.to))
+
+		var finalized uint64
+		if finalizedAfter, _ := s.lastFinalizedBatchIndex(); finalizedAfter > finalizedBefore {
+			finalized = finalizedAfter - finalizedBefore
+		}
+		s.updateRecovery(r.to, finalized)
+		s.dropValidatedBatchPointers()
+	}
+	s.updateFinalizedBlockLag()
+}
+
+// fetchedRange holds the rollup events emitted in the L1 blocks [from, to].
+type fetchedRange struct {
+	from, to  uint64
+	logs      []types.Log
+	endpoints []string // L1 endpoints that served the events, if multiple endpoints are configured
+}
+
+// fetchRanges fetches the rollup events of the L1 blocks [from, latest] in ranges of defaultFetchBlockRange
+// blocks and sends them in order to the ranges channel. The channel is closed once all ranges are sent,
+// the context is canceled or fetching fails.
+func (s *RollupSyncService) fetchRanges(ctx context.Context, from, latest uint64, ranges chan<- *fetchedRange) {
+	defer close(ranges)
+
 	// query in batches
-	for from := s.latestProcessedBlock + 1; from <= latestConfirmed; from += defaultFetchBlockRange {
-		if s.ctx.Err() != nil {
-			log.Info("Context canceled", "reason", s.ctx.Err())
+	for ; from <= latest; from += defaultFetchBlockRange {
+		if ctx.Err() != nil {
+			if s.ctx.Err() != nil {
+				log.Info("Context canceled", "reason", s.ctx.Err())
+			}
 			return
 		}
 
 		to := from + defaultFetchBlockRange - 1
-		if to > latestConfirmed {
-			to = latestConfirmed
+		if to > latest {
+			to = latest
 		}
 
-		logs, err := s.client.fetchRollupEventsInRange(s.ctx, from, to)
+		recorderCtx, recorder := sync_service.WithEndpointRecorder(ctx)
+		logs, err := s.client.fetchRollupEventsInRange(recorderCtx, from, to)
+		if err != nil && ctx.Err() != nil {
+			return
+		}
 		if err != nil {
 			log.Error("failed to fetch rollup events in range", "from block", from, "to block", to, "err", err)
 			return
 		}
+		if s.crossCheckClient != nil {
+			// the range is retried in the next round, until both endpoints agree
+			if err := s.crossCheckRollupEvents(ctx, from, to, logs); err != nil {
+				log.Error("failed to cross-check rollup events in range", "from block", from, "to block", to, "err", err)
+				return
+			}
+		}
 
-		if err := s.parseAndUpdateRollupEventLogs(logs, to); err != nil {
-			log.Error("failed to parse and update rollup event logs", "err", err)
+		select {
+		case ranges <- &fetchedRange{from: from, to: to, logs: logs, endpoints: recorder.Endpoints()}:
+		case <-ctx.Done():
 			return
 		}
+	}
+}
 
-		s.latestProcessedBlock = to
+// updateFinalizedBlockLag reports the number of local L2 blocks that are not yet finalized on L1.
+func (s *RollupSyncService) updateFinalizedBlockLag() {
+	finalized := rawdb.ReadFinalizedL2BlockNumber(s.db)
+	if finalized == nil {
+		return
+	}
+	if head := s.bc.CurrentHeader().Number.Uint64(); head > *finalized {
+		finalizedBlockLagGauge.Update(int64(head - *finalized))
+	} else {
+		finalizedBlockLagGauge.Update(0)
 	}
 }
 
-func (s *RollupSyncService) parseAndUpdateRollupEventLogs(logs []types.Log, endBlockNumber uint64) error {
+// parseAndUpdateRollupEventLogs processes the rollup events of the L1 blocks up to endBlockNumber.
+// Once all events are processed, the synced L1 block is stored along with the checkpoint of the
+// range, if any.
+func (s *RollupSyncService) parseAndUpdateRollupEventLogs(logs []types.Log, endBlockNumber uint64, checkpoint *rawdb.RollupSyncCheckpoint) error {
 	for _, vLog := range logs {
-		switch vLog.Topics[0] {
-		case s.l1CommitBatchEventSignature:
-			event := &L1CommitBatchEvent{}
-			if err := UnpackLog(s.scrollChainABI, event, "CommitBatch", vLog); err != nil {
-				return fmt.Errorf("failed to unpack commit rollup event log, err: %w", err)
+		if len(vLog.Topics) == 0 {
+			if err := s.handleUnknownEvent(&vLog); err != nil {
+				return err
 			}
-			batchIndex := event.BatchIndex.Uint64()
-			log.Trace("found new CommitBatch event", "batch index", batchIndex)
+			continue
+		}
 
-			chunkBlockRanges, err := s.getChunkRanges(batchIndex, &vLog)
-			if err != nil {
-				return fmt.Errorf("failed to get chunk ranges, batch index: %v, err: %w", batchIndex, err)
+		switch vLog.Topics[0] {
+		case s.l1CommitBatchEventSignature:
+			if err := s.handleCommitBatch(&vLog, true); err != nil {
+				return err
 			}
-			rawdb.WriteBatchChunkRanges(s.db, batchIndex, chunkBlockRanges)
 
 		case s.l1RevertBatchEventSignature:
 			event := &L1RevertBatchEvent{}
@@ -208,7 +595,9 @@ func (s *RollupSyncService) parseAndUpdateRollupEventLogs(logs []types.Log, endB
 			batchIndex := event.BatchIndex.Uint64()
 			log.Trace("found new RevertBatch event", "batch index", batchIndex)
 
-			rawdb.DeleteBatchChunkRanges(s.db, batchIndex)
+			if err := s.revertBatches(event, &vLog); err != nil {
+				return err
+			}
 
 		case s.l1FinalizeBatchEventSignature:
 			event := &L1FinalizeBatchEvent{}
@@ -218,37 +607,362 @@ func (s *RollupSyncService) parseAndUpdateRollupEventLogs(logs []types.Log, endB
 			batchIndex := event.BatchIndex.Uint64()
 			log.Trace("found new FinalizeBatch event", "batch index", batchIndex)
 
+			if err := s.checkFinalizeOrder(batchIndex, batchIndex, event.BatchHash); err != nil {
+				return err
+			}
 			parentBatchMeta, chunks, err := s.getLocalInfoForBatch(batchIndex)
 			if err != nil {
 				return fmt.Errorf("failed to get local node info, batch index: %v, err: %w", batchIndex, err)
 			}
+			codec, err := s.codecForBatch(batchIndex)
+			if err != nil {
+				return fmt.Errorf("failed to get batch codec, batch index: %v, err: %w", batchIndex, err)
+			}
 
-			endBlock, finalizedBatchMeta, err := validateBatch(event, parentBatchMeta, chunks)
+			endBlock, finalizedBatchMeta, err := validateBatch(codec, event, parentBatchMeta, chunks)
+			if err == nil {
+				err = s.checkChunkRules(codec, batchIndex, parentBatchMeta.TotalL1MessagePopped, chunks)
+			}
+			if err == nil {
+				err = s.checkBatchBlob(codec, batchIndex, chunks)
+			}
+			if errors.Is(err, errBatchMismatch) {
+				endBlock, finalizedBatchMeta, err = s.handleBatchMismatch(codec, event, vLog.BlockNumber, parentBatchMeta, chunks, err)
+			} else if err == nil {
+				rawdb.DeletePoisonedBatch(s.db, batchIndex)
+			}
 			if err != nil {
 				return fmt.Errorf("fatal: validateBatch failed: finalize event: %v, err: %w", event, err)
 			}
 
-			rawdb.WriteFinalizedL2BlockNumber(s.db, endBlock)
-			rawdb.WriteFinalizedBatchMeta(s.db, batchIndex, finalizedBatchMeta)
+			if err := s.writeFinalizedBatch(batchIndex, endBlock, finalizedBatchMeta, chunks, &vLog, event); err != nil {
+				return err
+			}
 
-			if batchIndex%100 == 0 {
-				log.Info("finalized batch progress", "batch index", batchIndex, "finalized l2 block height", endBlock)
+		case s.l1FinalizeBundleEventSignature:
+			event := &L1FinalizeBundleEvent{}
+			if err := UnpackLog(s.scrollChainABI, event, "FinalizeBundle", vLog); err != nil {
+				return fmt.Errorf("failed to unpack finalized bundle rollup event log, err: %w", err)
+			}
+			log.Trace("found new FinalizeBundle event", "start batch index", event.StartBatchIndex.Uint64(), "end batch index", event.EndBatchIndex.Uint64())
+
+			if err := s.finalizeBundle(event, &vLog); err != nil {
+				return err
+			}
+
+		case s.l1UpdateEnforcedBatchModeEventSignature:
+			if err := s.handleUpdateEnforcedBatchMode(&vLog); err != nil {
+				return err
 			}
 
 		default:
-			return fmt.Errorf("unknown event, topic: %v, tx hash: %v", vLog.Topics[0].Hex(), vLog.TxHash.Hex())
+			if err := s.handleUnknownEvent(&vLog); err != nil {
+				return err
+			}
 		}
 	}
 
 	// note: the batch updates above are idempotent, if we crash
 	// before this line and reexecute the previous steps, we will
 	// get the same result.
-	rawdb.WriteRollupEventSyncedL1BlockNumber(s.db, endBlockNumber)
+	batch := s.db.NewBatch()
+	if checkpoint != nil {
+		rawdb.WriteRollupSyncCheckpoint(batch, checkpoint)
+	}
+	rawdb.WriteRollupEventSyncedL1BlockNumber(batch, endBlockNumber)
+	if err := batch.Write(); err != nil {
+		return fmt.Errorf("failed to write rollup event synced L1 block number, err: %w", err)
+	}
 	return nil
 }
 
+// handleCommitBatch stores the chunk ranges and L1 meta data of a committed batch.
+// If detectGaps is set, batches that are missing before the committed batch are
+// re-queried from L1 first.
+func (s *RollupSyncService) handleCommitBatch(vLog *types.Log, detectGaps bool) error {
+	event := &L1CommitBatchEvent{}
+	if err := UnpackLog(s.scrollChainABI, event, "CommitBatch", *vLog); err != nil {
+		return fmt.Errorf("failed to unpack commit rollup event log, err: %w", err)
+	}
+	batchIndex := event.BatchIndex.Uint64()
+	log.Trace("found new CommitBatch event", "batch index", batchIndex)
+
+	if detectGaps {
+		if gap := s.detectBatchGap(batchIndex, vLog.BlockNumber); gap != nil {
+			if err := s.trackBatchGap(gap); err != nil {
+				return err
+			}
+		}
+	}
+	batch, err := s.getChunkRanges(batchIndex, vLog)
+	if err != nil {
+		return fmt.Errorf("failed to get chunk ranges, batch index: %v, err: %w", batchIndex, err)
+	}
+	if s.l1Follower && batchIndex > 0 {
+		if err := s.deriveBatch(batchIndex, vLog, batch); err != nil {
+			return fmt.Errorf("failed to derive blocks, batch index: %v, err: %w", batchIndex, err)
+		}
+	}
+	chunkBlockRanges, codecVersion, skipped := batch.chunkBlockRanges, batch.codecVersion, batch.skipped
+	s.writeBatchChunkRanges(batchIndex, chunkBlockRanges)
+	if len(skipped) > 0 {
+		rawdb.WriteBatchSkippedL1Messages(s.db, batchIndex, skipped)
+		skippedL1MessageCounter.Inc(int64(len(skipped)))
+		log.Info("Batch skipped L1 messages", "batch index", batchIndex, "count", len(skipped), "first", skipped[0], "last", skipped[len(skipped)-1])
+	}
+	rawdb.WriteBatchEndBlock(s.db, chunkBlockRanges[len(chunkBlockRanges)-1].EndBlockNumber, batchIndex)
+	batchL1Meta := &rawdb.BatchL1Meta{
+		CommitTxHash:        vLog.TxHash,
+		CommitL1BlockNumber: vLog.BlockNumber,
+		CommitL1BlockHash:   vLog.BlockHash,
+		CodecVersion:        codecVersion,
+		Enforced:            s.enforcedBatchModeEnabled(),
+	}
+	if batch.blob != nil {
+		batchL1Meta.BlobVersionedHash, batchL1Meta.BlobDataProof = batch.blob.VersionedHash, batch.blob.DataProof
+	}
+	rawdb.WriteBatchL1Meta(s.db, batchIndex, batchL1Meta)
+	rawdb.WriteBatchL1Block(s.db, vLog.BlockNumber, rawdb.BatchCommittedL1Event, batchIndex)
+	committedBatchGauge.Update(int64(batchIndex))
+	s.bus.PublishBatchCommitted(eventbus.BatchCommittedEvent{
+		BatchIndex:       batchIndex,
+		BatchHash:        event.BatchHash,
+		StartBlockNumber: chunkBlockRanges[0].StartBlockNumber,
+		EndBlockNumber:   chunkBlockRanges[len(chunkBlockRanges)-1].EndBlockNumber,
+		L1BlockNumber:    vLog.BlockNumber,
+		L1TxHash:         vLog.TxHash,
+	})
+	s.enqueueProverTask(event, vLog, chunkBlockRanges)
+
+	return runBatchHooks("commit", batchIndex, func(hook BatchHook) error {
+		return hook.OnBatchCommitted(&BatchCommitInfo{
+			BatchIndex:       batchIndex,
+			BatchHash:        event.BatchHash,
+			CodecVersion:     codecVersion,
+			ChunkBlockRanges: chunkBlockRanges,
+			L1Log:            vLog,
+		})
+	})
+}
+
+// finalizeBundle validates and stores all batches of a bundle. Only the roots and the hash of the
+// last batch are published on L1, the metadata of the other batches is computed from local data.
+// Nothing is stored if the validation of the last batch fails.
+func (s *RollupSyncService) finalizeBundle(event *L1FinalizeBundleEvent, vLog *types.Log) error {
+	startBatchIndex, endBatchIndex := event.StartBatchIndex.Uint64(), event.EndBatchIndex.Uint64()
+	if startBatchIndex > endBatchIndex {
+		return fmt.Errorf("invalid FinalizeBundle event, start batch index: %v, end batch index: %v", startBatchIndex, endBatchIndex)
+	}
+	if err := s.checkFinalizeOrder(startBatchIndex, endBatchIndex, event.EndBatchHash); err != nil {
+		return err
+	}
+
+	type finalizedBatch struct {
+		endBlock uint64
+		meta     *rawdb.FinalizedBatchMeta
+		chunks   []*Chunk
+		event    *L1FinalizeBatchEvent // the roots recorded on L1, only set for the last batch
+	}
+	batches := make([]finalizedBatch, 0, endBatchIndex-startBatchIndex+1)
+
+	var (
+		parentBatchMeta *rawdb.FinalizedBatchMeta
+		ruleViolation   error // first chunk rule or blob violation of the bundle, a mismatch of the last batch
+	)
+	for batchIndex := startBatchIndex; batchIndex <= endBatchIndex; batchIndex++ {
+		storedParentBatchMeta, chunks, err := s.getLocalInfoForBatch(batchIndex)
+		if err != nil {
+			return fmt.Errorf("failed to get local node info, batch index: %v, err: %w", batchIndex, err)
+		}
+		// the parents of all but the first batch are part of the bundle
+		if parentBatchMeta == nil {
+			parentBatchMeta = storedParentBatchMeta
+		}
+		codec, err := s.codecForBatch(batchIndex)
+		if err != nil {
+			return fmt.Errorf("failed to get batch codec, batch index: %v, err: %w", batchIndex, err)
+		}
+
+		if ruleViolation == nil {
+			ruleViolation = s.checkChunkRules(codec, batchIndex, parentBatchMeta.TotalL1MessagePopped, chunks)
+		}
+		if ruleViolation == nil {
+			if err := s.checkBatchBlob(codec, batchIndex, chunks); errors.Is(err, errBatchMismatch) {
+				ruleViolation = err
+			} else if err != nil {
+				return err
+			}
+		}
+
+		var endBlock uint64
+		var finalizedBatchMeta *rawdb.FinalizedBatchMeta
+		var batchEvent *L1FinalizeBatchEvent
+		if batchIndex < endBatchIndex {
+			endBlock, finalizedBatchMeta, err = computeFinalizedBatchMeta(codec, batchIndex, parentBatchMeta, chunks)
+		} else {
+			batchEvent = &L1FinalizeBatchEvent{
+				BatchIndex:   event.EndBatchIndex,
+				BatchHash:    event.EndBatchHash,
+				StateRoot:    event.StateRoot,
+				WithdrawRoot: event.WithdrawRoot,
+			}
+			endBlock, finalizedBatchMeta, err = validateBatch(codec, batchEvent, parentBatchMeta, chunks)
+			if err == nil && ruleViolation != nil {
+				err = ruleViolation
+			}
+			if errors.Is(err, errBatchMismatch) {
+				endBlock, finalizedBatchMeta, err = s.handleBatchMismatch(codec, batchEvent, vLog.BlockNumber, parentBatchMeta, chunks, err)
+			} else if err == nil {
+				rawdb.DeletePoisonedBatch(s.db, batchIndex)
+			}
+		}
+		if err != nil {
+			return fmt.Errorf("fatal: validateBatch failed: finalize bundle event: %v, batch index: %v, err: %w", event, batchIndex, err)
+		}
+
+		batches = append(batches, finalizedBatch{endBlock: endBlock, meta: finalizedBatchMeta, chunks: chunks, event: batchEvent})
+		parentBatchMeta = finalizedBatchMeta
+	}
+
+	for i, batch := range batches {
+		if err := s.writeFinalizedBatch(startBatchIndex+uint64(i), batch.endBlock, batch.meta, batch.chunks, vLog, batch.event); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeFinalizedBatch stores the metadata of a validated batch and notifies subscribers and batch hooks.
+// The roots of the finalize event are recorded if event is set, i.e. unless the batch is finalized
+// within a bundle without being its last batch.
+func (s *RollupSyncService) writeFinalizedBatch(batchIndex uint64, endBlock uint64, finalizedBatchMeta *rawdb.FinalizedBatchMeta, chunks []*Chunk, vLog *types.Log, event *L1FinalizeBatchEvent) error {
+	rawdb.WriteFinalizedL2BlockNumber(s.db, endBlock)
+	s.writeFinalizedBatchMeta(batchIndex, finalizedBatchMeta)
+	s.validateBatchPointers(batchIndex, finalizedBatchMeta)
+	finalizedBatchGauge.Update(int64(batchIndex))
+	// note: also index the batch here to cover batches committed before the index was introduced.
+	rawdb.WriteBatchEndBlock(s.db, endBlock, batchIndex)
+
+	batchL1Meta := rawdb.ReadBatchL1Meta(s.db, batchIndex)
+	if batchL1Meta == nil {
+		batchL1Meta = &rawdb.BatchL1Meta{}
+	} else if batchL1Meta.FinalizeTxHash != (common.Hash{}) {
+		rawdb.DeleteBatchL1Block(s.db, batchL1Meta.FinalizeL1BlockNumber, rawdb.BatchFinalizedL1Event, batchIndex)
+	}
+	batchL1Meta.FinalizeTxHash = vLog.TxHash
+	batchL1Meta.FinalizeL1BlockNumber = vLog.BlockNumber
+	batchL1Meta.FinalizeL1BlockHash = vLog.BlockHash
+	batchL1Meta.FinalizedStateRoot, batchL1Meta.FinalizedWithdrawRoot = common.Hash{}, common.Hash{}
+	if event != nil {
+		batchL1Meta.FinalizedStateRoot, batchL1Meta.FinalizedWithdrawRoot = event.StateRoot, event.WithdrawRoot
+	}
+	rawdb.WriteBatchL1Meta(s.db, batchIndex, batchL1Meta)
+	rawdb.WriteBatchL1Block(s.db, vLog.BlockNumber, rawdb.BatchFinalizedL1Event, batchIndex)
+	s.bus.PublishBatchFinalized(eventbus.BatchFinalizedEvent{
+		BatchIndex:       batchIndex,
+		BatchHash:        finalizedBatchMeta.BatchHash,
+		StartBlockNumber: chunks[0].Blocks[0].Header.Number.Uint64(),
+		EndBlockNumber:   endBlock,
+		StateRoot:        finalizedBatchMeta.StateRoot,
+		WithdrawRoot:     finalizedBatchMeta.WithdrawRoot,
+		L1BlockNumber:    vLog.BlockNumber,
+		L1TxHash:         vLog.TxHash,
+	})
+
+	if batchIndex%100 == 0 {
+		log.Info("finalized batch progress", "batch index", batchIndex, "finalized l2 block height", endBlock)
+	}
+
+	return runBatchHooks("finalize", batchIndex, func(hook BatchHook) error {
+		return hook.OnBatchFinalized(&BatchFinalizeInfo{
+			BatchIndex:       batchIndex,
+			Meta:             finalizedBatchMeta,
+			StartBlockNumber: chunks[0].Blocks[0].Header.Number.Uint64(),
+			EndBlockNumber:   endBlock,
+			Chunks:           chunks,
+			L1Log:            vLog,
+		})
+	})
+}
+
+// handleBatchMismatch persists a poisoned batch marker for a batch that failed validation and applies the configured verify mode.
+// In VerifyModeLogAndContinue it returns the batch metadata as finalized on L1 so that syncing can continue.
+func (s *RollupSyncService) handleBatchMismatch(codec Codec, event *L1FinalizeBatchEvent, l1BlockNumber uint64, parentBatchMeta *rawdb.FinalizedBatchMeta, chunks []*Chunk, mismatch error) (uint64, *rawdb.FinalizedBatchMeta, error) {
+	batchIndex := event.BatchIndex.Uint64()
+	batchMismatchCounter.Inc(1)
+	rawdb.WritePoisonedBatch(s.db, batchIndex, &rawdb.PoisonedBatch{
+		Reason:        mismatch.Error(),
+		L1BlockNumber: l1BlockNumber,
+	})
+
+	switch s.verifyMode {
+	case VerifyModeLogAndContinue:
+		log.Error("Batch validation failed, continuing with L1 values", "batch index", batchIndex, "err", mismatch)
+		endBlock, finalizedBatchMeta, err := computeFinalizedBatchMeta(codec, batchIndex, parentBatchMeta, chunks)
+		if err != nil {
+			return 0, nil, err
+		}
+		finalizedBatchMeta.BatchHash = event.BatchHash
+		finalizedBatchMeta.StateRoot = event.StateRoot
+		finalizedBatchMeta.WithdrawRoot = event.WithdrawRoot
+		return endBlock, finalizedBatchMeta, nil
+	case VerifyModeHaltSync:
+		log.Error("Batch validation failed, halting rollup event sync", "batch index", batchIndex, "err", mismatch)
+		atomic.StoreInt32(&s.halted, 1)
+		return 0, nil, mismatch
+	case VerifyModeHaltImport:
+		log.Error("Batch validation failed, halting rollup event sync and block import", "batch index", batchIndex, "err", mismatch)
+		atomic.StoreInt32(&s.halted, 1)
+		s.bc.PauseImport()
+		return 0, nil, mismatch
+	default:
+		log.Error("Batch validation failed, shutting down", "batch index", batchIndex, "err", mismatch)
+		syscall.Kill(os.Getpid(), syscall.SIGTERM)
+		return 0, nil, mismatch
+	}
+}
+
+// handleUnknownEvent applies the configured policy to an event that is not processed by the service.
+// Events in the ignore list are always skipped.
+func (s *RollupSyncService) handleUnknownEvent(vLog *types.Log) error {
+	var topic common.Hash
+	if len(vLog.Topics) > 0 {
+		topic = vLog.Topics[0]
+	}
+	if _, ok := s.ignoredEventTopics[topic]; ok {
+		log.Trace("ignoring known rollup event", "topic", topic.Hex(), "tx hash", vLog.TxHash.Hex())
+		return nil
+	}
+
+	switch s.unknownEventPolicy {
+	case UnknownEventSkip:
+		log.Debug("skipping unknown rollup event", "topic", topic.Hex(), "tx hash", vLog.TxHash.Hex(), "block number", vLog.BlockNumber)
+		return nil
+	case UnknownEventSkipWithAlert:
+		unknownEventCounter.Inc(1)
+		log.Error("Skipping unknown rollup event", "topic", topic.Hex(), "tx hash", vLog.TxHash.Hex(), "block number", vLog.BlockNumber)
+		return nil
+	default:
+		return fmt.Errorf("unknown event, topic: %v, tx hash: %v", topic.Hex(), vLog.TxHash.Hex())
+	}
+}
+
+// defaultIgnoredEventTopics returns the signatures of all ScrollChain events
+// that do not affect batch metadata and can be skipped safely.
+func defaultIgnoredEventTopics(scrollChainABI *abi.ABI) map[common.Hash]struct{} {
+	topics := make(map[common.Hash]struct{})
+	for name, event := range scrollChainABI.Events {
+		switch name {
+		case "CommitBatch", "RevertBatch", "FinalizeBatch", "FinalizeBundle", "UpdateEnforcedBatchMode":
+			continue
+		}
+		topics[event.ID] = struct{}{}
+	}
+	return topics
+}
+
 func (s *RollupSyncService) getLocalInfoForBatch(batchIndex uint64) (*rawdb.FinalizedBatchMeta, []*Chunk, error) {
-	chunkBlockRanges := rawdb.ReadBatchChunkRanges(s.db, batchIndex)
+	chunkBlockRanges := s.readBatchChunkRanges(batchIndex)
 	if len(chunkBlockRanges) == 0 {
 		return nil, nil, fmt.Errorf("failed to get batch chunk ranges, empty chunk block ranges")
 	}
@@ -267,163 +981,351 @@ func (s *RollupSyncService) getLocalInfoForBatch(batchIndex uint64) (*rawdb.Fina
 
 		log.Debug("local node is not synced up to the required block height, waiting for next retry",
 			"retries", i+1, "local synced block height", localSyncedBlockHeight, "required end block number", endBlockNumber)
-		time.Sleep(defaultGetBlockInRangeRetryDelay)
+		select {
+		case <-time.After(defaultGetBlockInRangeRetryDelay):
+		case <-s.ctx.Done():
+			log.Info("Context canceled", "reason", s.ctx.Err())
+			return nil, nil, s.ctx.Err()
+		}
 	}
 
-	localSyncedBlockHeight := s.bc.CurrentBlock().Number().Uint64()
-	if localSyncedBlockHeight < endBlockNumber {
-		return nil, nil, fmt.Errorf("local node is not synced up to the required block height: %v, local synced block height: %v", endBlockNumber, localSyncedBlockHeight)
+	if err := s.checkBlockAvailability(batchIndex, chunkBlockRanges); err != nil {
+		return nil, nil, err
 	}
 
-	chunks := make([]*Chunk, len(chunkBlockRanges))
-	for i, cr := range chunkBlockRanges {
-		chunks[i] = &Chunk{Blocks: make([]*WrappedBlock, cr.EndBlockNumber-cr.StartBlockNumber+1)}
-		for j := cr.StartBlockNumber; j <= cr.EndBlockNumber; j++ {
-			block := s.bc.GetBlockByNumber(j)
-			if block == nil {
-				return nil, nil, fmt.Errorf("failed to get block by number: %v", i)
-			}
-			txData := txsToTxsData(block.Transactions())
-			state, err := s.bc.StateAt(block.Root())
-			if err != nil {
-				return nil, nil, fmt.Errorf("failed to get block state, block: %v, err: %w", block.Hash().Hex(), err)
-			}
-			withdrawRoot := withdrawtrie.ReadWTRSlot(rcfg.L2MessageQueueAddress, state)
-			chunks[i].Blocks[j-cr.StartBlockNumber] = &WrappedBlock{
-				Header:       block.Header(),
-				Transactions: txData,
-				WithdrawRoot: withdrawRoot,
-			}
-		}
+	chunks, err := s.loadChunks(chunkBlockRanges)
+	if err != nil {
+		return nil, nil, err
 	}
 
 	// get metadata of parent batch: default to genesis batch metadata.
 	parentBatchMeta := &rawdb.FinalizedBatchMeta{}
 	if batchIndex > 0 {
-		parentBatchMeta = rawdb.ReadFinalizedBatchMeta(s.db, batchIndex-1)
+		parentBatchMeta = s.readFinalizedBatchMeta(batchIndex - 1)
 	}
 
 	return parentBatchMeta, chunks, nil
 }
 
-func (s *RollupSyncService) getChunkRanges(batchIndex uint64, vLog *types.Log) ([]*rawdb.ChunkBlockRange, error) {
+// loadChunks loads the local blocks of the given chunks. The blocks are split into consecutive
+// segments that are loaded by up to validationWorkers goroutines, at most at the configured rate.
+func (s *RollupSyncService) loadChunks(chunkBlockRanges []*rawdb.ChunkBlockRange) ([]*Chunk, error) {
+	startBlockNumber := chunkBlockRanges[0].StartBlockNumber
+	blocks := make([]*WrappedBlock, chunkBlockRanges[len(chunkBlockRanges)-1].EndBlockNumber-startBlockNumber+1)
+
+	workers := s.validationWorkers
+	if workers < 1 {
+		workers = 1
+	}
+	if workers > len(blocks) {
+		workers = len(blocks)
+	}
+	segment := (len(blocks) + workers - 1) / workers
+
+	g, ctx := errgroup.WithContext(s.ctx)
+	for from := 0; from < len(blocks); from += segment {
+		from, to := from, from+segment
+		if to > len(blocks) {
+			to = len(blocks)
+		}
+		g.Go(func() error {
+			// note: each worker reads consecutive blocks, so that regenerated state can be reused.
+			withdrawRootReader := s.newWithdrawRootReader()
+			for k := from; k < to; k++ {
+				if s.validationLimiter != nil {
+					if err := s.validationLimiter.Wait(ctx); err != nil {
+						return err
+					}
+				}
+				number := startBlockNumber + uint64(k)
+				block := s.bc.GetBlockByNumber(number)
+				if block == nil {
+					return fmt.Errorf("failed to get block by number: %v", number)
+				}
+				withdrawRoot, err := withdrawRootReader.read(block)
+				if err != nil {
+					return err
+				}
+				blocks[k] = NewWrappedBlock(block, withdrawRoot)
+			}
+			return nil
+		})
+	}
+	if err := g.Wait(); err != nil {
+		return nil, err
+	}
+
+	chunks := make([]*Chunk, len(chunkBlockRanges))
+	for i, cr := range chunkBlockRanges {
+		chunks[i] = &Chunk{Blocks: blocks[cr.StartBlockNumber-startBlockNumber : cr.EndBlockNumber-startBlockNumber+1]}
+	}
+	return chunks, nil
+}
+
+// committedBatch is the commit data of a batch decoded from its commit transaction.
+type committedBatch struct {
+	chunkBlockRanges []*rawdb.ChunkBlockRange
+	codecVersion     uint8
+	skipped          []uint64   // queue indices of the L1 messages skipped by the batch
+	blob             *BatchBlob // blob committed along with the batch, nil for version 0
+
+	// the decoded commit data, nil for the genesis batch
+	calldata           *CommitBatchCalldata
+	codec              Codec
+	chunkBlockContexts [][]*BlockContext
+}
+
+// getChunkRanges returns the block ranges of the chunks of a committed batch along with the batch version,
+// the queue indices of the L1 messages the batch skipped and the blob the batch committed to.
+func (s *RollupSyncService) getChunkRanges(batchIndex uint64, vLog *types.Log) (*committedBatch, error) {
 	if batchIndex == 0 {
-		return []*rawdb.ChunkBlockRange{{StartBlockNumber: 0, EndBlockNumber: 0}}, nil
+		return &committedBatch{
+			chunkBlockRanges: []*rawdb.ChunkBlockRange{{StartBlockNumber: 0, EndBlockNumber: 0}},
+			codecVersion:     batchHeaderVersion,
+		}, nil
 	}
 
-	tx, _, err := s.client.client.TransactionByHash(s.ctx, vLog.TxHash)
+	calldata, err := s.getCommitBatchCalldata(batchIndex, vLog)
 	if err != nil {
-		log.Debug("failed to get transaction by hash, probably an unindexed transaction, fetching the whole block to get the transaction",
-			"tx hash", vLog.TxHash.Hex(), "block number", vLog.BlockNumber, "block hash", vLog.BlockHash.Hex(), "err", err)
-		block, err := s.client.client.BlockByHash(s.ctx, vLog.BlockHash)
-		if err != nil {
-			return nil, fmt.Errorf("failed to get block by hash, block number: %v, block hash: %v, err: %w", vLog.BlockNumber, vLog.BlockHash.Hex(), err)
-		}
+		return nil, err
+	}
+	codec, err := CodecForVersion(calldata.Version)
+	if err != nil {
+		return nil, err
+	}
 
-		found := false
-		for _, txInBlock := range block.Transactions() {
-			if txInBlock.Hash() == vLog.TxHash {
-				tx = txInBlock
-				found = true
-				break
-			}
-		}
-		if !found {
-			return nil, fmt.Errorf("transaction not found in the block, tx hash: %v, block number: %v, block hash: %v", vLog.TxHash.Hex(), vLog.BlockNumber, vLog.BlockHash.Hex())
+	chunkBlockContexts, err := codec.DecodeChunkBlockContexts(calldata.Chunks)
+	if err != nil {
+		return nil, err
+	}
+	s.checkBlockContexts(batchIndex, chunkBlockContexts)
+
+	batch := &committedBatch{
+		chunkBlockRanges:   chunkBlockRangesFromContexts(chunkBlockContexts),
+		codecVersion:       codec.Version(),
+		calldata:           calldata,
+		codec:              codec,
+		chunkBlockContexts: chunkBlockContexts,
+	}
+	if _, ok := codec.(BlobCodec); ok {
+		if len(calldata.BlobVersionedHashes) != 1 {
+			return nil, fmt.Errorf("commit transaction of batch version %v carries %v blobs, expected 1", codec.Version(), len(calldata.BlobVersionedHashes))
 		}
+		batch.blob = &BatchBlob{VersionedHash: calldata.BlobVersionedHashes[0], DataProof: calldata.BlobDataProof}
 	}
 
-	return s.decodeChunkBlockRanges(tx.Data())
+	// note: the skipped messages are informational, a malformed bitmap is rejected by ScrollChain anyway
+	batch.skipped, err = skippedL1Messages(calldata, chunkBlockContexts)
+	if err != nil {
+		log.Warn("Failed to decode skipped L1 messages", "batch index", batchIndex, "err", err)
+	}
+	return batch, nil
 }
 
-// decodeChunkBlockRanges decodes chunks in a batch based on the commit batch transaction's calldata.
-func (s *RollupSyncService) decodeChunkBlockRanges(txData []byte) ([]*rawdb.ChunkBlockRange, error) {
-	const methodIDLength = 4
-	if len(txData) < methodIDLength {
-		return nil, fmt.Errorf("transaction data is too short, length of tx data: %v, minimum length required: %v", len(txData), methodIDLength)
+// getCommitBatchCalldata fetches and decodes the commitBatch arguments of the L1 transaction
+// that emitted the given commit event.
+func (s *RollupSyncService) getCommitBatchCalldata(batchIndex uint64, vLog *types.Log) (*CommitBatchCalldata, error) {
+	tx, err := s.getCommitBatchTransaction(vLog)
+	if err != nil {
+		return nil, err
 	}
 
-	method, err := s.scrollChainABI.MethodById(txData[:methodIDLength])
+	calldata, err := decodeCommitBatchCalldata(s.scrollChainABI, tx.Data())
 	if err != nil {
-		return nil, fmt.Errorf("failed to get method by ID, ID: %v, err: %w", txData[:methodIDLength], err)
+		// the operator may submit commitBatch through a wrapper contract
+		var nestedErr error
+		if calldata, nestedErr = findNestedCommitBatchCalldata(s.scrollChainABI, tx.Data(), batchIndex); nestedErr != nil {
+			return nil, fmt.Errorf("%w, nested call recovery failed: %v", err, nestedErr)
+		}
+		log.Debug("Recovered nested commitBatch call", "batch index", batchIndex, "tx hash", vLog.TxHash.Hex(), "to", tx.To())
 	}
+	calldata.BlobVersionedHashes = tx.BlobHashes()
+	return calldata, nil
+}
 
-	values, err := method.Inputs.Unpack(txData[methodIDLength:])
+// getCommitBatchTransaction fetches the L1 transaction that emitted the given commit event,
+// from the payload cache if it has been fetched before.
+func (s *RollupSyncService) getCommitBatchTransaction(vLog *types.Log) (*types.Transaction, error) {
+	if s.payloadCache == nil {
+		return s.fetchCommitBatchTransaction(vLog)
+	}
+	if data, ok := s.payloadCache.Get(vLog.TxHash); ok {
+		tx := new(types.Transaction)
+		if err := tx.UnmarshalBinary(data); err == nil && tx.Hash() == vLog.TxHash {
+			return tx, nil
+		}
+		log.Warn("Dropping corrupted cached commit transaction", "tx hash", vLog.TxHash.Hex())
+		s.payloadCache.Delete(vLog.TxHash)
+	}
+	tx, err := s.fetchCommitBatchTransaction(vLog)
 	if err != nil {
-		return nil, fmt.Errorf("failed to unpack transaction data using ABI, tx data: %v, err: %w", txData, err)
+		return nil, err
 	}
+	// note: blob transactions are not cached, the rlp package cannot decode their uint256 fields
+	if tx.Type() == types.BlobTxType {
+		return tx, nil
+	}
+	if data, err := tx.MarshalBinary(); err == nil {
+		if err := s.payloadCache.Put(vLog.TxHash, data); err != nil {
+			log.Warn("Failed to cache commit transaction", "tx hash", vLog.TxHash.Hex(), "err", err)
+		}
+	}
+	return tx, nil
+}
 
-	type commitBatchArgs struct {
-		Version                uint8
-		ParentBatchHeader      []byte
-		Chunks                 [][]byte
-		SkippedL1MessageBitmap []byte
+// fetchCommitBatchTransaction fetches the L1 transaction that emitted the given commit event
+// from the L1 endpoint.
+func (s *RollupSyncService) fetchCommitBatchTransaction(vLog *types.Log) (*types.Transaction, error) {
+	tx, _, err := s.client.client.TransactionByHash(s.ctx, vLog.TxHash)
+	if err == nil {
+		return tx, nil
 	}
-	var args commitBatchArgs
-	err = method.Inputs.Copy(&args, values)
+
+	log.Debug("failed to get transaction by hash, probably an unindexed transaction, fetching the whole block to get the transaction",
+		"tx hash", vLog.TxHash.Hex(), "block number", vLog.BlockNumber, "block hash", vLog.BlockHash.Hex(), "err", err)
+	block, err := s.client.client.BlockByHash(s.ctx, vLog.BlockHash)
 	if err != nil {
-		return nil, fmt.Errorf("failed to decode calldata into commitBatch args, values: %+v, err: %w", values, err)
+		return nil, fmt.Errorf("failed to get block by hash, block number: %v, block hash: %v, err: %w", vLog.BlockNumber, vLog.BlockHash.Hex(), err)
 	}
 
-	if args.Version != batchHeaderVersion {
-		return nil, fmt.Errorf("unexpected batch version, expected: %v, got: %v", batchHeaderVersion, args.Version)
+	for _, txInBlock := range block.Transactions() {
+		if txInBlock.Hash() == vLog.TxHash {
+			return txInBlock, nil
+		}
 	}
-
-	return DecodeChunkBlockRanges(args.Chunks)
+	return nil, fmt.Errorf("transaction not found in the block, tx hash: %v, block number: %v, block hash: %v", vLog.TxHash.Hex(), vLog.BlockNumber, vLog.BlockHash.Hex())
 }
 
-// validateBatch verifies the consistency between the L1 contract and L2 node data.
-// The function will terminate the node and exit if any consistency check fails.
-// It returns the number of the end block, a finalized batch meta data, and an error if any.
-func validateBatch(event *L1FinalizeBatchEvent, parentBatchMeta *rawdb.FinalizedBatchMeta, chunks []*Chunk) (uint64, *rawdb.FinalizedBatchMeta, error) {
-	if len(chunks) == 0 {
-		return 0, nil, fmt.Errorf("invalid argument: length of chunks is 0, batch index: %v", event.BatchIndex.Uint64())
+// decodeChunkBlockRanges decodes chunks in a batch based on the commit batch transaction's calldata.
+func (s *RollupSyncService) decodeChunkBlockRanges(txData []byte) ([]*rawdb.ChunkBlockRange, error) {
+	codec, chunks, err := s.decodeCommitBatchChunks(txData)
+	if err != nil {
+		return nil, err
+	}
+	chunkBlockContexts, err := codec.DecodeChunkBlockContexts(chunks)
+	if err != nil {
+		return nil, err
 	}
+	return chunkBlockRangesFromContexts(chunkBlockContexts), nil
+}
 
-	startChunk := chunks[0]
-	if len(startChunk.Blocks) == 0 {
-		return 0, nil, fmt.Errorf("invalid argument: block count of start chunk is 0, batch index: %v", event.BatchIndex.Uint64())
+// decodeCommitBatchChunks extracts the encoded chunks from the commit batch transaction's calldata
+// and returns them along with the codec of the batch version.
+func (s *RollupSyncService) decodeCommitBatchChunks(txData []byte) (Codec, [][]byte, error) {
+	calldata, err := decodeCommitBatchCalldata(s.scrollChainABI, txData)
+	if err != nil {
+		return nil, nil, err
 	}
-	startBlock := startChunk.Blocks[0]
 
-	endChunk := chunks[len(chunks)-1]
-	if len(endChunk.Blocks) == 0 {
-		return 0, nil, fmt.Errorf("invalid argument: block count of end chunk is 0, batch index: %v", event.BatchIndex.Uint64())
+	codec, err := CodecForVersion(calldata.Version)
+	if err != nil {
+		return nil, nil, err
 	}
-	endBlock := endChunk.Blocks[len(endChunk.Blocks)-1]
 
-	localStateRoot := endBlock.Header.Root
-	if localStateRoot != event.StateRoot {
-		log.Error("State root mismatch", "batch index", event.BatchIndex.Uint64(), "start block", startBlock.Header.Number.Uint64(), "end block", endBlock.Header.Number.Uint64(), "parent batch hash", parentBatchMeta.BatchHash.Hex(), "l1 finalized state root", event.StateRoot.Hex(), "l2 state root", localStateRoot.Hex())
-		syscall.Kill(os.Getpid(), syscall.SIGTERM)
-		return 0, nil, fmt.Errorf("state root mismatch")
+	return codec, calldata.Chunks, nil
+}
+
+// checkBlockContexts compares the gas limit and base fee of each committed block context against the
+// local header of the same block. A mismatch does not stop the sync, but it indicates a drift between
+// the sequencer and the commit encoder that will eventually lead to a batch hash mismatch at finalization.
+// Blocks that are not yet available locally are skipped.
+func (s *RollupSyncService) checkBlockContexts(batchIndex uint64, chunkBlockContexts [][]*BlockContext) {
+	for _, blockContexts := range chunkBlockContexts {
+		for _, blockContext := range blockContexts {
+			header := s.bc.GetHeaderByNumber(blockContext.BlockNumber)
+			if header == nil {
+				log.Debug("skipping block context check, block not available locally", "batch index", batchIndex, "block number", blockContext.BlockNumber)
+				continue
+			}
+			if err := compareBlockContext(blockContext, header); err != nil {
+				blockContextMismatchCounter.Inc(1)
+				log.Error("Committed block context mismatch", "batch index", batchIndex, "block number", blockContext.BlockNumber, "block hash", header.Hash().Hex(), "err", err)
+			}
+		}
 	}
+}
 
-	localWithdrawRoot := endBlock.WithdrawRoot
-	if localWithdrawRoot != event.WithdrawRoot {
-		log.Error("Withdraw root mismatch", "batch index", event.BatchIndex.Uint64(), "start block", startBlock.Header.Number.Uint64(), "end block", endBlock.Header.Number.Uint64(), "parent batch hash", parentBatchMeta.BatchHash.Hex(), "l1 finalized withdraw root", event.WithdrawRoot.Hex(), "l2 withdraw root", localWithdrawRoot.Hex())
-		syscall.Kill(os.Getpid(), syscall.SIGTERM)
-		return 0, nil, fmt.Errorf("withdraw root mismatch")
+// compareBlockContext returns an error describing the fields of the block context that do not match the header.
+func compareBlockContext(blockContext *BlockContext, header *types.Header) error {
+	var mismatches []string
+	if blockContext.GasLimit != header.GasLimit {
+		mismatches = append(mismatches, fmt.Sprintf("gas limit: committed %v, local %v", blockContext.GasLimit, header.GasLimit))
+	}
+	// the base fee is not committed by all codecs, it can't be compared then
+	if committedBaseFee := blockContext.BaseFee; committedBaseFee != nil {
+		localBaseFee := header.BaseFee
+		if localBaseFee == nil {
+			localBaseFee = common.Big0
+		}
+		if committedBaseFee.Cmp(localBaseFee) != 0 {
+			mismatches = append(mismatches, fmt.Sprintf("base fee: committed %v, local %v", committedBaseFee, localBaseFee))
+		}
 	}
+	if len(mismatches) > 0 {
+		return errors.New(strings.Join(mismatches, "; "))
+	}
+	return nil
+}
 
-	// Note: All params for NewBatchHeader are calculated locally based on the block data.
-	batchHeader, err := NewBatchHeader(batchHeaderVersion, event.BatchIndex.Uint64(), parentBatchMeta.TotalL1MessagePopped, parentBatchMeta.BatchHash, chunks)
+// validateBatch verifies the consistency between the L1 contract and L2 node data.
+// Inconsistencies between L1 and L2 are reported as errBatchMismatch.
+// It returns the number of the end block, a finalized batch meta data, and an error if any.
+func validateBatch(codec Codec, event *L1FinalizeBatchEvent, parentBatchMeta *rawdb.FinalizedBatchMeta, chunks []*Chunk) (uint64, *rawdb.FinalizedBatchMeta, error) {
+	defer batchValidationTimer.UpdateSince(time.Now())
+
+	endBlockNumber, finalizedBatchMeta, err := computeFinalizedBatchMeta(codec, event.BatchIndex.Uint64(), parentBatchMeta, chunks)
 	if err != nil {
-		return 0, nil, fmt.Errorf("failed to construct batch header, batch index: %v, err: %w", event.BatchIndex.Uint64(), err)
+		return 0, nil, err
+	}
+	startBlockNumber := chunks[0].Blocks[0].Header.Number.Uint64()
+
+	if finalizedBatchMeta.StateRoot != event.StateRoot {
+		log.Error("State root mismatch", "batch index", event.BatchIndex.Uint64(), "start block", startBlockNumber, "end block", endBlockNumber, "parent batch hash", parentBatchMeta.BatchHash.Hex(), "l1 finalized state root", event.StateRoot.Hex(), "l2 state root", finalizedBatchMeta.StateRoot.Hex())
+		return 0, nil, fmt.Errorf("%w: state root mismatch", errBatchMismatch)
+	}
+
+	if finalizedBatchMeta.WithdrawRoot != event.WithdrawRoot {
+		log.Error("Withdraw root mismatch", "batch index", event.BatchIndex.Uint64(), "start block", startBlockNumber, "end block", endBlockNumber, "parent batch hash", parentBatchMeta.BatchHash.Hex(), "l1 finalized withdraw root", event.WithdrawRoot.Hex(), "l2 withdraw root", finalizedBatchMeta.WithdrawRoot.Hex())
+		return 0, nil, fmt.Errorf("%w: withdraw root mismatch", errBatchMismatch)
 	}
 
 	// Note: If the batch headers match, this ensures the consistency of blocks and transactions
 	// (including skipped transactions) between L1 and L2.
-	localBatchHash := batchHeader.Hash()
-	if localBatchHash != event.BatchHash {
-		log.Error("Batch hash mismatch", "batch index", event.BatchIndex.Uint64(), "start block", startBlock.Header.Number.Uint64(), "end block", endBlock.Header.Number.Uint64(), "parent batch hash", parentBatchMeta.BatchHash.Hex(), "parent TotalL1MessagePopped", parentBatchMeta.TotalL1MessagePopped, "l1 finalized batch hash", event.BatchHash.Hex(), "l2 batch hash", localBatchHash.Hex())
+	if finalizedBatchMeta.BatchHash != event.BatchHash {
+		log.Error("Batch hash mismatch", "batch index", event.BatchIndex.Uint64(), "start block", startBlockNumber, "end block", endBlockNumber, "parent batch hash", parentBatchMeta.BatchHash.Hex(), "parent TotalL1MessagePopped", parentBatchMeta.TotalL1MessagePopped, "l1 finalized batch hash", event.BatchHash.Hex(), "l2 batch hash", finalizedBatchMeta.BatchHash.Hex())
 		chunksJson, err := json.Marshal(chunks)
 		if err != nil {
 			log.Error("marshal chunks failed", "err", err)
 		}
-		log.Error("Chunks", "chunks", string(chunksJson))
-		syscall.Kill(os.Getpid(), syscall.SIGTERM)
-		return 0, nil, fmt.Errorf("batch hash mismatch")
+		diaglog.Error("Chunks", "chunks", chunksJson, "batch index", event.BatchIndex.Uint64())
+		return 0, nil, fmt.Errorf("%w: batch hash mismatch", errBatchMismatch)
+	}
+
+	return endBlockNumber, finalizedBatchMeta, nil
+}
+
+// computeFinalizedBatchMeta computes the metadata of a batch from local block data using the codec of the batch.
+// It returns the number of the end block, the batch meta data, and an error if any.
+func computeFinalizedBatchMeta(codec Codec, batchIndex uint64, parentBatchMeta *rawdb.FinalizedBatchMeta, chunks []*Chunk) (uint64, *rawdb.FinalizedBatchMeta, error) {
+	if len(chunks) == 0 {
+		return 0, nil, fmt.Errorf("invalid argument: length of chunks is 0, batch index: %v", batchIndex)
+	}
+
+	startChunk := chunks[0]
+	if len(startChunk.Blocks) == 0 {
+		return 0, nil, fmt.Errorf("invalid argument: block count of start chunk is 0, batch index: %v", batchIndex)
+	}
+
+	endChunk := chunks[len(chunks)-1]
+	if len(endChunk.Blocks) == 0 {
+		return 0, nil, fmt.Errorf("invalid argument: block count of end chunk is 0, batch index: %v", batchIndex)
+	}
+	endBlock := endChunk.Blocks[len(endChunk.Blocks)-1]
+
+	// Note: All params of the batch header are calculated locally based on the block data.
+	batchHash, err := codec.BatchHash(batchIndex, parentBatchMeta.TotalL1MessagePopped, parentBatchMeta.BatchHash, chunks)
+	if err != nil {
+		return 0, nil, fmt.Errorf("failed to construct batch header, batch index: %v, err: %w", batchIndex, err)
 	}
 
 	totalL1MessagePopped := parentBatchMeta.TotalL1MessagePopped
@@ -431,10 +1333,10 @@ func validateBatch(event *L1FinalizeBatchEvent, parentBatchMeta *rawdb.Finalized
 		totalL1MessagePopped += chunk.NumL1Messages(totalL1MessagePopped)
 	}
 	finalizedBatchMeta := &rawdb.FinalizedBatchMeta{
-		BatchHash:            localBatchHash,
+		BatchHash:            batchHash,
 		TotalL1MessagePopped: totalL1MessagePopped,
-		StateRoot:            localStateRoot,
-		WithdrawRoot:         localWithdrawRoot,
+		StateRoot:            endBlock.Header.Root,
+		WithdrawRoot:         endBlock.WithdrawRoot,
 	}
 	return endBlock.Header.Number.Uint64(), finalizedBatchMeta, nil
 }
@@ -9,15 +9,22 @@ import (
 	"testing"
 	"time"
 
+	"github.com/holiman/uint256"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
 	"github.com/scroll-tech/go-ethereum/common"
+	"github.com/scroll-tech/go-ethereum/consensus/ethash"
 	"github.com/scroll-tech/go-ethereum/core"
 	"github.com/scroll-tech/go-ethereum/core/rawdb"
 	"github.com/scroll-tech/go-ethereum/core/types"
+	"github.com/scroll-tech/go-ethereum/core/vm"
+	"github.com/scroll-tech/go-ethereum/ethdb"
 	"github.com/scroll-tech/go-ethereum/ethdb/memorydb"
 	"github.com/scroll-tech/go-ethereum/params"
+	"github.com/scroll-tech/go-ethereum/rlp"
+
+	"github.com/scroll-tech/go-ethereum/rollup/eventbus"
 )
 
 func TestRollupSyncServiceStartAndStop(t *testing.T) {
@@ -32,7 +39,7 @@ func TestRollupSyncServiceStartAndStop(t *testing.T) {
 	db := rawdb.NewDatabase(memorydb.New())
 	l1Client := &mockEthClient{}
 	bc := &core.BlockChain{}
-	service, err := NewRollupSyncService(context.Background(), genesisConfig, db, l1Client, bc, 1)
+	service, err := NewRollupSyncService(context.Background(), genesisConfig, db, l1Client, bc, 1, &Config{VerifyMode: VerifyModeHaltSync}, nil)
 	if err != nil {
 		t.Fatalf("Failed to new rollup sync service: %v", err)
 	}
@@ -111,8 +118,8 @@ func TestGetChunkRanges(t *testing.T) {
 	l1Client := &mockEthClient{
 		commitBatchRLP: rlpData,
 	}
-	bc := &core.BlockChain{}
-	service, err := NewRollupSyncService(context.Background(), genesisConfig, db, l1Client, bc, 1)
+	bc := newTestBlockChain(t, db)
+	service, err := NewRollupSyncService(context.Background(), genesisConfig, db, l1Client, bc, 1, &Config{VerifyMode: VerifyModeHaltSync}, nil)
 	if err != nil {
 		t.Fatalf("Failed to new rollup sync service: %v", err)
 	}
@@ -120,8 +127,12 @@ func TestGetChunkRanges(t *testing.T) {
 	vLog := &types.Log{
 		TxHash: common.HexToHash("0x0"),
 	}
-	ranges, err := service.getChunkRanges(1, vLog)
+	batch, err := service.getChunkRanges(1, vLog)
 	require.NoError(t, err)
+	assert.Equal(t, uint8(0), batch.codecVersion)
+	assert.Empty(t, batch.skipped)
+	assert.Nil(t, batch.blob)
+	ranges := batch.chunkBlockRanges
 
 	expectedRanges := []*rawdb.ChunkBlockRange{
 		{StartBlockNumber: 911145, EndBlockNumber: 911151},
@@ -140,6 +151,111 @@ func TestGetChunkRanges(t *testing.T) {
 	}
 }
 
+func TestGetChunkRangesBlob(t *testing.T) {
+	genesisConfig := &params.ChainConfig{
+		Scroll: params.ScrollConfig{
+			L1Config: &params.L1Config{
+				L1ChainId:          11155111,
+				ScrollChainAddress: common.HexToAddress("0x2D567EcE699Eabe5afCd141eDB7A4f2D0D6ce8a0"),
+			},
+		},
+	}
+	scrollChainABI, err := scrollChainMetaData.GetAbi()
+	require.NoError(t, err)
+
+	committed, err := codecV3.encodeChunk(loadTraceChunks(t, "blockTrace_02.json")[0], 0)
+	require.NoError(t, err)
+	parentBatchHeader := make([]byte, 193)
+	parentBatchHeader[0] = 3
+	blobDataProof := make([]byte, 160)
+	blobDataProof[32] = 0x01
+	txData, err := scrollChainABI.Pack("commitBatchWithBlobProof", uint8(3), parentBatchHeader, [][]byte{committed}, []byte{}, blobDataProof)
+	require.NoError(t, err)
+	versionedHash := common.Hash{0x01, 0x02}
+	tx := types.NewTx(&types.BlobTx{
+		ChainID:    uint256.NewInt(11155111),
+		GasTipCap:  uint256.NewInt(1),
+		GasFeeCap:  uint256.NewInt(1),
+		Value:      uint256.NewInt(0),
+		BlobFeeCap: uint256.NewInt(1),
+		Data:       txData,
+		BlobHashes: []common.Hash{versionedHash},
+		V:          uint256.NewInt(0),
+		R:          uint256.NewInt(0),
+		S:          uint256.NewInt(0),
+	})
+
+	db := rawdb.NewDatabase(memorydb.New())
+	service, err := NewRollupSyncService(context.Background(), genesisConfig, db, &mockEthClient{commitBatchTx: tx}, newTestBlockChain(t, db), 1, &Config{VerifyMode: VerifyModeHaltSync}, nil)
+	require.NoError(t, err)
+	batch, err := service.getChunkRanges(1, &types.Log{TxHash: tx.Hash()})
+	require.NoError(t, err)
+	assert.Equal(t, uint8(3), batch.codecVersion)
+	assert.Equal(t, []*rawdb.ChunkBlockRange{{StartBlockNumber: 2, EndBlockNumber: 2}}, batch.chunkBlockRanges)
+	assert.Equal(t, &BatchBlob{VersionedHash: versionedHash, DataProof: blobDataProof}, batch.blob)
+}
+
+// countingEthClient counts the transactions requested from L1.
+type countingEthClient struct {
+	mockEthClient
+	txRequests int
+}
+
+func (c *countingEthClient) TransactionByHash(ctx context.Context, txHash common.Hash) (*types.Transaction, bool, error) {
+	c.txRequests++
+	return c.mockEthClient.TransactionByHash(ctx, txHash)
+}
+
+func TestPayloadCache(t *testing.T) {
+	genesisConfig := &params.ChainConfig{
+		Scroll: params.ScrollConfig{
+			L1Config: &params.L1Config{
+				L1ChainId:          11155111,
+				ScrollChainAddress: common.HexToAddress("0x2D567EcE699Eabe5afCd141eDB7A4f2D0D6ce8a0"),
+			},
+		},
+	}
+	rlpData, err := os.ReadFile("./testdata/commit_batch_tx.rlp")
+	require.NoError(t, err)
+	var tx types.Transaction
+	require.NoError(t, rlp.DecodeBytes(rlpData, &tx))
+	vLog := &types.Log{TxHash: tx.Hash()}
+
+	// the commit transaction is fetched once, also after a restart
+	config := &Config{PayloadCacheDir: t.TempDir(), PayloadCacheSize: 1024 * 1024, VerifyMode: VerifyModeHaltSync}
+	for i := 0; i < 2; i++ {
+		db := rawdb.NewDatabase(memorydb.New())
+		l1Client := &countingEthClient{mockEthClient: mockEthClient{commitBatchRLP: rlpData}}
+		service, err := NewRollupSyncService(context.Background(), genesisConfig, db, l1Client, newTestBlockChain(t, db), 1, config, nil)
+		require.NoError(t, err)
+		for j := 0; j < 2; j++ {
+			batch, err := service.getChunkRanges(1, vLog)
+			require.NoError(t, err)
+			assert.Len(t, batch.chunkBlockRanges, 3)
+		}
+		if i == 0 {
+			assert.Equal(t, 1, l1Client.txRequests)
+		} else {
+			assert.Zero(t, l1Client.txRequests)
+		}
+	}
+}
+
+func TestCompareBlockContext(t *testing.T) {
+	header := &types.Header{Number: big.NewInt(1), GasLimit: 10000000}
+	assert.NoError(t, compareBlockContext(&BlockContext{BlockNumber: 1, GasLimit: 10000000, BaseFee: big.NewInt(0)}, header))
+
+	err := compareBlockContext(&BlockContext{BlockNumber: 1, GasLimit: 20000000, BaseFee: big.NewInt(0)}, header)
+	assert.ErrorContains(t, err, "gas limit")
+
+	header.BaseFee = big.NewInt(7)
+	err = compareBlockContext(&BlockContext{BlockNumber: 1, GasLimit: 10000000, BaseFee: big.NewInt(8)}, header)
+	assert.ErrorContains(t, err, "base fee")
+
+	// the base fee is skipped if the codec does not commit it
+	assert.NoError(t, compareBlockContext(&BlockContext{BlockNumber: 1, GasLimit: 10000000}, header))
+}
+
 func TestValidateBatch(t *testing.T) {
 	templateBlockTrace1, err := os.ReadFile("./testdata/blockTrace_02.json")
 	require.NoError(t, err)
@@ -169,7 +285,7 @@ func TestValidateBatch(t *testing.T) {
 		StateRoot:    chunk3.Blocks[len(chunk3.Blocks)-1].Header.Root,
 		WithdrawRoot: chunk3.Blocks[len(chunk3.Blocks)-1].WithdrawRoot,
 	}
-	endBlock1, finalizedBatchMeta1, err := validateBatch(event1, parentBatchMeta1, []*Chunk{chunk1, chunk2, chunk3})
+	endBlock1, finalizedBatchMeta1, err := validateBatch(codecV0{}, event1, parentBatchMeta1, []*Chunk{chunk1, chunk2, chunk3})
 	assert.NoError(t, err)
 	assert.Equal(t, uint64(13), endBlock1)
 
@@ -193,7 +309,7 @@ func TestValidateBatch(t *testing.T) {
 		StateRoot:    chunk4.Blocks[len(chunk4.Blocks)-1].Header.Root,
 		WithdrawRoot: chunk4.Blocks[len(chunk4.Blocks)-1].WithdrawRoot,
 	}
-	endBlock2, finalizedBatchMeta2, err := validateBatch(event2, parentBatchMeta2, []*Chunk{chunk4})
+	endBlock2, finalizedBatchMeta2, err := validateBatch(codecV0{}, event2, parentBatchMeta2, []*Chunk{chunk4})
 	assert.NoError(t, err)
 	assert.Equal(t, uint64(17), endBlock2)
 
@@ -205,3 +321,292 @@ func TestValidateBatch(t *testing.T) {
 	}
 	assert.Equal(t, parentBatchMeta3, finalizedBatchMeta2)
 }
+
+func newTestBlockChain(t *testing.T, db ethdb.Database) *core.BlockChain {
+	(&core.Genesis{Config: params.TestChainConfig}).MustCommit(db)
+	bc, err := core.NewBlockChain(db, nil, params.TestChainConfig, ethash.NewFaker(), vm.Config{}, nil, nil)
+	require.NoError(t, err)
+	t.Cleanup(bc.Stop)
+	return bc
+}
+
+func TestHandleUnknownEvent(t *testing.T) {
+	scrollChainABI, err := scrollChainMetaData.GetAbi()
+	require.NoError(t, err)
+
+	unknownLog := &types.Log{Topics: []common.Hash{common.HexToHash("0x1234")}}
+	ignoredLog := &types.Log{Topics: []common.Hash{scrollChainABI.Events["UpdateProver"].ID}}
+
+	service := &RollupSyncService{
+		unknownEventPolicy: UnknownEventHalt,
+		ignoredEventTopics: defaultIgnoredEventTopics(scrollChainABI),
+	}
+	assert.Error(t, service.handleUnknownEvent(unknownLog))
+	assert.NoError(t, service.handleUnknownEvent(ignoredLog))
+	assert.Error(t, service.handleUnknownEvent(&types.Log{}))
+
+	service.unknownEventPolicy = UnknownEventSkip
+	assert.NoError(t, service.handleUnknownEvent(unknownLog))
+
+	service.unknownEventPolicy = UnknownEventSkipWithAlert
+	assert.NoError(t, service.handleUnknownEvent(unknownLog))
+
+	_, err = ParseUnknownEventPolicy("ignore")
+	assert.Error(t, err)
+	policy, err := ParseUnknownEventPolicy("skip-with-alert")
+	require.NoError(t, err)
+	assert.Equal(t, UnknownEventSkipWithAlert, policy)
+}
+
+func TestBatchRevertedEventFeed(t *testing.T) {
+	genesisConfig := &params.ChainConfig{
+		Scroll: params.ScrollConfig{
+			L1Config: &params.L1Config{
+				L1ChainId:          11155111,
+				ScrollChainAddress: common.HexToAddress("0x2D567EcE699Eabe5afCd141eDB7A4f2D0D6ce8a0"),
+			},
+		},
+	}
+	db := rawdb.NewDatabase(memorydb.New())
+	service, err := NewRollupSyncService(context.Background(), genesisConfig, db, &mockEthClient{}, &core.BlockChain{}, 1, &Config{VerifyMode: VerifyModeHaltSync}, nil)
+	require.NoError(t, err)
+
+	rawdb.WriteBatchChunkRanges(db, 5, []*rawdb.ChunkBlockRange{{StartBlockNumber: 10, EndBlockNumber: 20}})
+	rawdb.WriteBatchEndBlock(db, 20, 5)
+
+	ch := make(chan eventbus.BatchRevertedEvent, 1)
+	sub := service.bus.SubscribeBatchReverted(ch)
+	defer sub.Unsubscribe()
+
+	batchHash := common.HexToHash("0x1234")
+	revertLog := types.Log{
+		Topics:      []common.Hash{service.l1RevertBatchEventSignature, common.BigToHash(big.NewInt(5)), batchHash},
+		BlockNumber: 100,
+		TxHash:      common.HexToHash("0xabcd"),
+	}
+	require.NoError(t, service.parseAndUpdateRollupEventLogs([]types.Log{revertLog}, 100, nil))
+
+	select {
+	case ev := <-ch:
+		assert.Equal(t, eventbus.BatchRevertedEvent{BatchIndex: 5, BatchHash: batchHash, L1BlockNumber: 100, L1TxHash: revertLog.TxHash}, ev)
+	default:
+		t.Fatal("no BatchRevertedEvent received")
+	}
+	assert.Nil(t, rawdb.ReadBatchChunkRanges(db, 5))
+	assert.Nil(t, rawdb.ReadBatchIndexByL2BlockNumber(db, 15))
+}
+
+func TestHandleBatchMismatch(t *testing.T) {
+	templateBlockTrace, err := os.ReadFile("./testdata/blockTrace_02.json")
+	require.NoError(t, err)
+	wrappedBlock := &WrappedBlock{}
+	require.NoError(t, json.Unmarshal(templateBlockTrace, wrappedBlock))
+	chunks := []*Chunk{{Blocks: []*WrappedBlock{wrappedBlock}}}
+
+	parentBatchMeta := &rawdb.FinalizedBatchMeta{}
+	event := &L1FinalizeBatchEvent{
+		BatchIndex:   big.NewInt(0),
+		BatchHash:    common.HexToHash("0x01"),
+		StateRoot:    common.HexToHash("0x02"),
+		WithdrawRoot: wrappedBlock.WithdrawRoot,
+	}
+	_, _, mismatch := validateBatch(codecV0{}, event, parentBatchMeta, chunks)
+	require.ErrorIs(t, mismatch, errBatchMismatch)
+
+	db := rawdb.NewMemoryDatabase()
+	service := &RollupSyncService{db: db, verifyMode: VerifyModeLogAndContinue}
+	endBlock, finalizedBatchMeta, err := service.handleBatchMismatch(codecV0{}, event, 100, parentBatchMeta, chunks, mismatch)
+	require.NoError(t, err)
+	assert.Equal(t, wrappedBlock.Header.Number.Uint64(), endBlock)
+	assert.Equal(t, event.BatchHash, finalizedBatchMeta.BatchHash)
+	assert.Equal(t, event.StateRoot, finalizedBatchMeta.StateRoot)
+	assert.Equal(t, &rawdb.PoisonedBatch{Reason: mismatch.Error(), L1BlockNumber: 100}, rawdb.ReadPoisonedBatch(db, 0))
+
+	service = &RollupSyncService{db: db, verifyMode: VerifyModeHaltSync}
+	_, _, err = service.handleBatchMismatch(codecV0{}, event, 100, parentBatchMeta, chunks, mismatch)
+	assert.ErrorIs(t, err, errBatchMismatch)
+	assert.Equal(t, int32(1), service.halted)
+
+	bc := &core.BlockChain{}
+	service = &RollupSyncService{db: db, bc: bc, verifyMode: VerifyModeHaltImport}
+	_, _, err = service.handleBatchMismatch(codecV0{}, event, 100, parentBatchMeta, chunks, mismatch)
+	assert.ErrorIs(t, err, errBatchMismatch)
+	assert.Equal(t, int32(1), service.halted)
+	assert.True(t, bc.ImportPaused())
+	assert.True(t, service.Status().ImportPaused)
+
+	// import is paused again after a restart and resumed together with the sync
+	service, err = NewRollupSyncService(context.Background(), &params.ChainConfig{Scroll: params.ScrollConfig{L1Config: &params.L1Config{L1ChainId: 11155111, ScrollChainAddress: common.HexToAddress("0x01")}}}, db, &mockEthClient{}, &core.BlockChain{}, 1, &Config{VerifyMode: VerifyModeHaltImport}, nil)
+	require.NoError(t, err)
+	assert.True(t, service.Status().Halted)
+	assert.True(t, service.Status().ImportPaused)
+	service.Resume()
+	assert.False(t, service.Status().Halted)
+	assert.False(t, service.Status().ImportPaused)
+
+	_, err = ParseVerifyMode("ignore")
+	assert.Error(t, err)
+}
+
+func TestFinalizeBundleBatchMeta(t *testing.T) {
+	readChunk := func(file string) *Chunk {
+		templateBlockTrace, err := os.ReadFile(file)
+		require.NoError(t, err)
+		wrappedBlock := &WrappedBlock{}
+		require.NoError(t, json.Unmarshal(templateBlockTrace, wrappedBlock))
+		return &Chunk{Blocks: []*WrappedBlock{wrappedBlock}}
+	}
+	chunk1 := readChunk("./testdata/blockTrace_02.json")
+	chunk2 := readChunk("./testdata/blockTrace_03.json")
+	chunk3 := readChunk("./testdata/blockTrace_04.json")
+	chunk4 := readChunk("./testdata/blockTrace_05.json")
+
+	// bundle of batches 0 and 1: only the hash and roots of batch 1 are known from L1
+	endBlock, batch0Meta, err := computeFinalizedBatchMeta(codecV0{}, 0, &rawdb.FinalizedBatchMeta{}, []*Chunk{chunk1, chunk2, chunk3})
+	require.NoError(t, err)
+	assert.Equal(t, uint64(13), endBlock)
+	assert.Equal(t, common.HexToHash("0xd0f52bc254646e639bf24cc34606319a111975b2fdc431b1381eb6199bc09790"), batch0Meta.BatchHash)
+
+	event := &L1FinalizeBatchEvent{
+		BatchIndex:   big.NewInt(1),
+		BatchHash:    common.HexToHash("0xfb77bf8f3bf449126ebbf403fdccfcf78636e34d72d62eed8da0e8c9fd38fa63"),
+		StateRoot:    chunk4.Blocks[0].Header.Root,
+		WithdrawRoot: chunk4.Blocks[0].WithdrawRoot,
+	}
+	endBlock, _, err = validateBatch(codecV0{}, event, batch0Meta, []*Chunk{chunk4})
+	require.NoError(t, err)
+	assert.Equal(t, uint64(17), endBlock)
+
+	// unpack a FinalizeBundle log
+	scrollChainABI, err := scrollChainMetaData.GetAbi()
+	require.NoError(t, err)
+	bundleEvent := scrollChainABI.Events["FinalizeBundle"]
+	data, err := bundleEvent.Inputs.NonIndexed().Pack(event.StateRoot, event.WithdrawRoot)
+	require.NoError(t, err)
+	bundleLog := types.Log{
+		Topics: []common.Hash{bundleEvent.ID, common.BigToHash(big.NewInt(0)), common.BigToHash(big.NewInt(1)), event.BatchHash},
+		Data:   data,
+	}
+	unpacked := &L1FinalizeBundleEvent{}
+	require.NoError(t, UnpackLog(scrollChainABI, unpacked, "FinalizeBundle", bundleLog))
+	assert.Equal(t, uint64(0), unpacked.StartBatchIndex.Uint64())
+	assert.Equal(t, uint64(1), unpacked.EndBatchIndex.Uint64())
+	assert.Equal(t, event.BatchHash, unpacked.EndBatchHash)
+	assert.Equal(t, event.StateRoot, unpacked.StateRoot)
+	assert.Equal(t, event.WithdrawRoot, unpacked.WithdrawRoot)
+}
+
+func TestStatus(t *testing.T) {
+	db := rawdb.NewMemoryDatabase()
+	service := &RollupSyncService{db: db, verifyMode: VerifyModeHaltSync, unknownEventPolicy: UnknownEventSkip, halted: 1}
+
+	status := service.Status()
+	assert.True(t, status.Halted)
+	assert.Equal(t, uint64(0), status.SyncedL1BlockNumber)
+	assert.Empty(t, status.PoisonedBatches)
+
+	rawdb.WriteRollupEventSyncedL1BlockNumber(db, 100)
+	rawdb.WriteFinalizedL2BlockNumber(db, 10)
+	rawdb.WritePoisonedBatch(db, 3, &rawdb.PoisonedBatch{})
+	rawdb.WriteRollupSyncCheckpoint(db, &rawdb.RollupSyncCheckpoint{L1BlockNumber: 100})
+
+	status = service.Status()
+	assert.Equal(t, uint64(100), status.SyncedL1BlockNumber)
+	assert.Equal(t, uint64(10), status.FinalizedL2BlockNumber)
+	assert.Equal(t, []uint64{3}, status.PoisonedBatches)
+	assert.Equal(t, []uint64{100}, status.Checkpoints)
+	assert.Equal(t, VerifyModeHaltSync, status.VerifyMode)
+	assert.Contains(t, status.Counters, "batchMismatch")
+}
+
+func TestStopWaitsForSyncLoop(t *testing.T) {
+	genesisConfig := &params.ChainConfig{Scroll: params.ScrollConfig{L1Config: &params.L1Config{L1ChainId: 11155111, ScrollChainAddress: common.HexToAddress("0x01")}}}
+	service, err := NewRollupSyncService(context.Background(), genesisConfig, rawdb.NewMemoryDatabase(), &mockEthClient{}, &core.BlockChain{}, 1, &Config{VerifyMode: VerifyModeHaltSync}, nil)
+	require.NoError(t, err)
+	assert.Equal(t, defaultL1RequestTimeout, service.client.client.(*meteredEthClient).timeout)
+
+	service.Start()
+	done := make(chan struct{})
+	go func() {
+		service.Stop()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Stop did not return")
+	}
+}
+
+func TestPauseResumeResetTo(t *testing.T) {
+	db := rawdb.NewMemoryDatabase()
+	service := &RollupSyncService{db: db, latestProcessedBlock: 300, halted: 1}
+	rawdb.WriteRollupEventSyncedL1BlockNumber(db, 300)
+	rawdb.WriteRollupSyncCheckpoint(db, &rawdb.RollupSyncCheckpoint{L1BlockNumber: 100})
+	rawdb.WriteRollupSyncCheckpoint(db, &rawdb.RollupSyncCheckpoint{L1BlockNumber: 200})
+	rawdb.WriteRollupSyncCheckpoint(db, &rawdb.RollupSyncCheckpoint{L1BlockNumber: 300})
+
+	service.Pause()
+	assert.True(t, service.Status().Paused)
+
+	// resuming lifts both the pause and the halt
+	service.Resume()
+	assert.False(t, service.Status().Paused)
+	assert.False(t, service.Status().Halted)
+
+	assert.Error(t, service.ResetTo(301))
+
+	require.NoError(t, service.ResetTo(200))
+	assert.Equal(t, uint64(200), service.latestProcessedBlock)
+	assert.Equal(t, uint64(200), *rawdb.ReadRollupEventSyncedL1BlockNumber(db))
+	assert.Equal(t, []uint64{100, 200}, service.Status().Checkpoints)
+}
+
+func TestClearRetryPoisonedBatches(t *testing.T) {
+	db := rawdb.NewMemoryDatabase()
+	service := &RollupSyncService{db: db, latestProcessedBlock: 300, halted: 1}
+	rawdb.WriteRollupEventSyncedL1BlockNumber(db, 300)
+	rawdb.WritePoisonedBatch(db, 5, &rawdb.PoisonedBatch{Reason: "state root mismatch", L1BlockNumber: 250})
+	rawdb.WritePoisonedBatch(db, 6, &rawdb.PoisonedBatch{Reason: "withdraw root mismatch", L1BlockNumber: 260})
+
+	status := service.Status()
+	assert.Equal(t, []uint64{5, 6}, status.PoisonedBatches)
+	assert.Equal(t, []*PoisonedBatch{
+		{BatchIndex: 5, Reason: "state root mismatch", L1BlockNumber: 250},
+		{BatchIndex: 6, Reason: "withdraw root mismatch", L1BlockNumber: 260},
+	}, status.Poisoned)
+
+	assert.Error(t, service.ClearPoisonedBatch(7))
+	assert.Error(t, service.RetryPoisonedBatch(7))
+
+	// the sync stays halted while poisoned batches are left
+	require.NoError(t, service.ClearPoisonedBatch(6))
+	assert.Equal(t, []uint64{5}, service.Status().PoisonedBatches)
+	assert.Equal(t, uint64(300), service.latestProcessedBlock)
+	assert.True(t, service.Status().Halted)
+
+	// retrying validates the batch again from the L1 block of its finalize event
+	require.NoError(t, service.RetryPoisonedBatch(5))
+	assert.Empty(t, service.Status().PoisonedBatches)
+	assert.Equal(t, uint64(249), service.latestProcessedBlock)
+	assert.Equal(t, uint64(249), *rawdb.ReadRollupEventSyncedL1BlockNumber(db))
+	assert.False(t, service.Status().Halted)
+}
+
+func TestClearPoisonedBatchesResumesImport(t *testing.T) {
+	db := rawdb.NewMemoryDatabase()
+	rawdb.WritePoisonedBatch(db, 5, &rawdb.PoisonedBatch{Reason: "state root mismatch", L1BlockNumber: 250})
+	rawdb.WritePoisonedBatch(db, 6, &rawdb.PoisonedBatch{Reason: "withdraw root mismatch", L1BlockNumber: 260})
+	service, err := NewRollupSyncService(context.Background(), &params.ChainConfig{Scroll: params.ScrollConfig{L1Config: &params.L1Config{L1ChainId: 11155111, ScrollChainAddress: common.HexToAddress("0x01")}}}, db, &mockEthClient{}, &core.BlockChain{}, 1, &Config{VerifyMode: VerifyModeHaltImport}, nil)
+	require.NoError(t, err)
+	assert.True(t, service.Status().ImportPaused)
+
+	// block import stays paused while poisoned batches are left
+	require.NoError(t, service.ClearPoisonedBatch(5))
+	assert.True(t, service.Status().Halted)
+	assert.True(t, service.Status().ImportPaused)
+
+	require.NoError(t, service.RetryPoisonedBatch(6))
+	assert.False(t, service.Status().Halted)
+	assert.False(t, service.Status().ImportPaused)
+}
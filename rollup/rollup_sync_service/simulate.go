@@ -0,0 +1,95 @@
+package rollup_sync_service
+
+import (
+	"fmt"
+
+	"github.com/scroll-tech/go-ethereum/core/rawdb"
+)
+
+// SimulatedBatch is a batch built from local blocks, encoded as it is submitted to the
+// ScrollChain contract. Batches submitted this way pass the validation of the service.
+type SimulatedBatch struct {
+	BatchIndex uint64
+	Header     []byte                    // encoded batch header, its hash is the batch hash
+	Commit     *CommitBatchCalldata      // arguments of the commitBatch call of the batch
+	Parent     *rawdb.FinalizedBatchMeta // metadata of the parent batch
+	Meta       *rawdb.FinalizedBatchMeta // metadata stored once the batch is finalized
+}
+
+// SimulateBatch builds the batch with the given index from the local blocks of the given
+// chunks, on top of the stored batch with the previous index. If chunkBlockRanges is nil,
+// the stored chunks of the batch are used. The committed batches between the last
+// finalized batch and the batch are rebuilt from their stored chunks, batches are built
+// with the V0 codec. It is meant for devnets and tests, e.g. the simulator of package devnet.
+func (s *RollupSyncService) SimulateBatch(batchIndex uint64, chunkBlockRanges []*rawdb.ChunkBlockRange) (*SimulatedBatch, error) {
+	// note: the header of the parent batch is part of the commit arguments, so it is rebuilt as well.
+	start := batchIndex
+	if start > 0 {
+		start--
+	}
+	for start > 0 && s.readFinalizedBatchMeta(start-1) == nil {
+		start--
+	}
+	parent := &rawdb.FinalizedBatchMeta{}
+	if start > 0 {
+		parent = s.readFinalizedBatchMeta(start - 1)
+	}
+
+	var (
+		parentHeader []byte
+		batch        *SimulatedBatch
+	)
+	for index := start; index <= batchIndex; index++ {
+		ranges := chunkBlockRanges
+		if index < batchIndex || ranges == nil {
+			ranges = s.readBatchChunkRanges(index)
+		}
+		if len(ranges) == 0 {
+			return nil, fmt.Errorf("batch %d is not committed", index)
+		}
+		var err error
+		if batch, err = s.buildBatch(index, parent, parentHeader, ranges); err != nil {
+			return nil, err
+		}
+		parent, parentHeader = batch.Meta, batch.Header
+	}
+	return batch, nil
+}
+
+// buildBatch builds a batch of local blocks on top of the parent batch.
+func (s *RollupSyncService) buildBatch(batchIndex uint64, parent *rawdb.FinalizedBatchMeta, parentHeader []byte, chunkBlockRanges []*rawdb.ChunkBlockRange) (*SimulatedBatch, error) {
+	chunks, err := s.loadChunks(chunkBlockRanges)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load chunks of batch %d: %w", batchIndex, err)
+	}
+	header, err := NewBatchHeader(batchHeaderVersion, batchIndex, parent.TotalL1MessagePopped, parent.BatchHash, chunks)
+	if err != nil {
+		return nil, fmt.Errorf("failed to construct batch header, batch index: %v, err: %w", batchIndex, err)
+	}
+
+	encodedChunks := make([][]byte, len(chunks))
+	totalL1MessagePopped := parent.TotalL1MessagePopped
+	for i, chunk := range chunks {
+		if encodedChunks[i], err = chunk.Encode(totalL1MessagePopped); err != nil {
+			return nil, fmt.Errorf("failed to encode chunk %d of batch %d: %w", i, batchIndex, err)
+		}
+		totalL1MessagePopped += chunk.NumL1Messages(totalL1MessagePopped)
+	}
+
+	_, meta, err := computeFinalizedBatchMeta(codecV0{}, batchIndex, parent, chunks)
+	if err != nil {
+		return nil, err
+	}
+	return &SimulatedBatch{
+		BatchIndex: batchIndex,
+		Header:     header.Encode(),
+		Commit: &CommitBatchCalldata{
+			Version:                batchHeaderVersion,
+			ParentBatchHeader:      parentHeader,
+			Chunks:                 encodedChunks,
+			SkippedL1MessageBitmap: header.skippedL1MessageBitmap,
+		},
+		Parent: parent,
+		Meta:   meta,
+	}, nil
+}
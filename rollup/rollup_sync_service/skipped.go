@@ -0,0 +1,50 @@
+package rollup_sync_service
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math/big"
+
+	"github.com/scroll-tech/go-ethereum/metrics"
+)
+
+var skippedL1MessageCounter = metrics.NewRegisteredCounter("rollup/sync/l1messages/skipped", nil)
+
+// skippedL1Messages returns the queue indices of the L1 messages that a committed batch popped
+// from the message queue without including them, e.g. because they exceeded the circuit capacity.
+// The batch pops the messages after the total popped by its parent batch, chunkBlockContexts give
+// the number of popped messages and the bitmap of the calldata marks the skipped ones.
+func skippedL1Messages(calldata *CommitBatchCalldata, chunkBlockContexts [][]*BlockContext) ([]uint64, error) {
+	// the parent batch header starts with the version byte, the batch index, the number
+	// of L1 messages popped in the batch and the total number of L1 messages popped
+	if len(calldata.ParentBatchHeader) < 25 {
+		return nil, fmt.Errorf("parent batch header too short: %d bytes", len(calldata.ParentBatchHeader))
+	}
+	totalL1MessagePoppedBefore := binary.BigEndian.Uint64(calldata.ParentBatchHeader[17:25])
+
+	var l1MessagePopped uint64
+	for _, blockContexts := range chunkBlockContexts {
+		for _, blockContext := range blockContexts {
+			l1MessagePopped += uint64(blockContext.NumL1Messages)
+		}
+	}
+	bitmap := calldata.SkippedL1MessageBitmap
+	if expected := (l1MessagePopped + 255) / 256 * 32; uint64(len(bitmap)) != expected {
+		return nil, fmt.Errorf("unexpected skipped L1 message bitmap length, expected: %d, got: %d", expected, len(bitmap))
+	}
+
+	// the bitmap is an array of 256-bit words, bit i of word j marks message 256*j+i of the batch
+	var skipped []uint64
+	for i := 0; i*32 < len(bitmap); i++ {
+		word := new(big.Int).SetBytes(bitmap[i*32 : (i+1)*32])
+		for bit := 0; bit < word.BitLen(); bit++ {
+			if word.Bit(bit) == 0 {
+				continue
+			}
+			if index := uint64(i*256 + bit); index < l1MessagePopped {
+				skipped = append(skipped, totalL1MessagePoppedBefore+index)
+			}
+		}
+	}
+	return skipped, nil
+}
@@ -0,0 +1,38 @@
+package rollup_sync_service
+
+import (
+	"encoding/binary"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSkippedL1Messages(t *testing.T) {
+	parentBatchHeader := make([]byte, 89)
+	binary.BigEndian.PutUint64(parentBatchHeader[17:25], 10)
+	chunkBlockContexts := [][]*BlockContext{{{NumL1Messages: 3}}, {{NumL1Messages: 0}, {NumL1Messages: 2}}}
+
+	// messages 1 and 3 of the batch are skipped
+	bitmap := make([]byte, 32)
+	bitmap[31] = 0x0a
+	skipped, err := skippedL1Messages(&CommitBatchCalldata{ParentBatchHeader: parentBatchHeader, SkippedL1MessageBitmap: bitmap}, chunkBlockContexts)
+	require.NoError(t, err)
+	assert.Equal(t, []uint64{11, 13}, skipped)
+
+	// bits beyond the popped messages are ignored
+	bitmap[0] = 0x80
+	skipped, err = skippedL1Messages(&CommitBatchCalldata{ParentBatchHeader: parentBatchHeader, SkippedL1MessageBitmap: bitmap}, chunkBlockContexts)
+	require.NoError(t, err)
+	assert.Equal(t, []uint64{11, 13}, skipped)
+
+	// batches without L1 messages have an empty bitmap
+	skipped, err = skippedL1Messages(&CommitBatchCalldata{ParentBatchHeader: parentBatchHeader}, [][]*BlockContext{{{}}})
+	require.NoError(t, err)
+	assert.Empty(t, skipped)
+
+	_, err = skippedL1Messages(&CommitBatchCalldata{ParentBatchHeader: parentBatchHeader, SkippedL1MessageBitmap: make([]byte, 64)}, chunkBlockContexts)
+	assert.Error(t, err)
+	_, err = skippedL1Messages(&CommitBatchCalldata{ParentBatchHeader: parentBatchHeader[:20], SkippedL1MessageBitmap: bitmap}, chunkBlockContexts)
+	assert.Error(t, err)
+}
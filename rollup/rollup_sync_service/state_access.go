@@ -0,0 +1,161 @@
+package rollup_sync_service
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/scroll-tech/go-ethereum/common"
+	"github.com/scroll-tech/go-ethereum/core/rawdb"
+	"github.com/scroll-tech/go-ethereum/core/state"
+	"github.com/scroll-tech/go-ethereum/core/types"
+	"github.com/scroll-tech/go-ethereum/crypto"
+	"github.com/scroll-tech/go-ethereum/log"
+	"github.com/scroll-tech/go-ethereum/rlp"
+	"github.com/scroll-tech/go-ethereum/trie"
+
+	"github.com/scroll-tech/go-ethereum/rollup/rcfg"
+	"github.com/scroll-tech/go-ethereum/rollup/withdrawtrie"
+)
+
+// withdrawRootReader reads the withdraw trie root of consecutive blocks. For every block it
+// uses the withdraw root persisted at import time if available. Otherwise it falls back to
+// the snapshot layer, then the trie database, and finally (if enabled) regenerates
+// the state by re-executing blocks from the nearest ancestor whose state is available.
+// The regenerated state is kept so that consecutive blocks only require a single re-execution.
+type withdrawRootReader struct {
+	s        *RollupSyncService
+	reexec   uint64
+	database state.Database // ephemeral state database used for re-execution
+
+	regenerated       *state.StateDB // regenerated state after executing block regeneratedNumber
+	regeneratedNumber uint64
+}
+
+func (s *RollupSyncService) newWithdrawRootReader() *withdrawRootReader {
+	return &withdrawRootReader{s: s, reexec: s.stateReexec}
+}
+
+// read returns the withdraw trie root after executing the given block.
+func (r *withdrawRootReader) read(block *types.Block) (common.Hash, error) {
+	if root := rawdb.ReadBlockWithdrawRoot(r.s.db, block.Hash()); root != nil {
+		return *root, nil
+	}
+	if root, ok := r.readFromSnapshot(block); ok {
+		return root, nil
+	}
+
+	statedb, err := r.s.bc.StateAt(block.Root())
+	if err == nil {
+		root := withdrawtrie.ReadWTRSlot(rcfg.L2MessageQueueAddress, statedb)
+		if err = statedb.Error(); err == nil {
+			return root, nil
+		}
+	}
+	if r.reexec == 0 {
+		return common.Hash{}, fmt.Errorf("failed to get block state, block: %v, err: %w", block.Hash().Hex(), err)
+	}
+
+	log.Debug("block state not available, regenerating", "block number", block.NumberU64(), "block hash", block.Hash().Hex(), "err", err)
+	statedb, err = r.regenerate(block)
+	if err != nil {
+		return common.Hash{}, fmt.Errorf("failed to regenerate block state, block: %v, err: %w", block.Hash().Hex(), err)
+	}
+	return withdrawtrie.ReadWTRSlot(rcfg.L2MessageQueueAddress, statedb), nil
+}
+
+// readFromSnapshot reads the withdraw trie root directly from the flat state snapshot, if available.
+func (r *withdrawRootReader) readFromSnapshot(block *types.Block) (common.Hash, bool) {
+	snaps := r.s.bc.Snapshots()
+	if snaps == nil {
+		return common.Hash{}, false
+	}
+	snap := snaps.Snapshot(block.Root())
+	if snap == nil {
+		return common.Hash{}, false
+	}
+	// the snapshot of a zktrie state is keyed by the leaf keys of the trie, and holds the
+	// raw storage values
+	zktrie := r.s.bc.Config().Scroll.ZktrieEnabledAt(block.Number())
+	accountHash, slotHash := crypto.Keccak256Hash(rcfg.L2MessageQueueAddress.Bytes()), crypto.Keccak256Hash(rcfg.WithdrawTrieRootSlot.Bytes())
+	if zktrie {
+		var err error
+		if accountHash, err = trie.ZkLeafKey(rcfg.L2MessageQueueAddress.Bytes()); err != nil {
+			return common.Hash{}, false
+		}
+		if slotHash, err = trie.ZkLeafKey(rcfg.WithdrawTrieRootSlot.Bytes()); err != nil {
+			return common.Hash{}, false
+		}
+	}
+	enc, err := snap.Storage(accountHash, slotHash)
+	if err != nil {
+		return common.Hash{}, false
+	}
+	var root common.Hash
+	if zktrie {
+		root.SetBytes(enc)
+	} else if len(enc) > 0 {
+		_, content, _, err := rlp.Split(enc)
+		if err != nil {
+			return common.Hash{}, false
+		}
+		root.SetBytes(content)
+	}
+	return root, true
+}
+
+// regenerate re-executes blocks up to and including the given block, starting either from the
+// previously regenerated state or from the nearest ancestor (at most r.reexec blocks back) whose state is available.
+func (r *withdrawRootReader) regenerate(block *types.Block) (*state.StateDB, error) {
+	bc := r.s.bc
+	target := block.NumberU64()
+
+	if r.regenerated == nil || r.regeneratedNumber >= target {
+		r.database = state.NewDatabaseWithConfig(r.s.db, &trie.Config{Cache: 16, Zktrie: bc.Config().Scroll.ZktrieEnabledAt(block.Number())})
+		r.regenerated = nil
+
+		current := block
+		for i := uint64(0); i < r.reexec; i++ {
+			if current.NumberU64() == 0 {
+				return nil, errors.New("genesis state is missing")
+			}
+			parent := bc.GetBlock(current.ParentHash(), current.NumberU64()-1)
+			if parent == nil {
+				return nil, fmt.Errorf("missing block %v %d", current.ParentHash().Hex(), current.NumberU64()-1)
+			}
+			current = parent
+
+			if statedb, err := state.New(current.Root(), r.database, nil); err == nil {
+				r.regenerated, r.regeneratedNumber = statedb, current.NumberU64()
+				break
+			}
+		}
+		if r.regenerated == nil {
+			return nil, fmt.Errorf("required historical state unavailable (reexec=%d)", r.reexec)
+		}
+	}
+
+	for r.regeneratedNumber < target {
+		next := bc.GetBlockByNumber(r.regeneratedNumber + 1)
+		if next == nil {
+			return nil, fmt.Errorf("block #%d not found", r.regeneratedNumber+1)
+		}
+		if _, _, _, err := bc.Processor().Process(next, r.regenerated, *bc.GetVMConfig()); err != nil {
+			r.regenerated = nil
+			return nil, fmt.Errorf("processing block %d failed: %w", next.NumberU64(), err)
+		}
+		root, err := r.regenerated.Commit(bc.Config().IsEIP158(next.Number()))
+		if err != nil {
+			r.regenerated = nil
+			return nil, fmt.Errorf("commit failed, number %d root %v: %w", next.NumberU64(), next.Root().Hex(), err)
+		}
+		if root != next.Root() {
+			r.regenerated = nil
+			return nil, fmt.Errorf("regenerated state root mismatch, number %d, expected %v, got %v", next.NumberU64(), next.Root().Hex(), root.Hex())
+		}
+		if r.regenerated, err = state.New(root, r.database, nil); err != nil {
+			return nil, fmt.Errorf("state reset after block %d failed: %w", next.NumberU64(), err)
+		}
+		r.regeneratedNumber = next.NumberU64()
+	}
+	return r.regenerated, nil
+}
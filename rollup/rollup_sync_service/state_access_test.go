@@ -0,0 +1,121 @@
+package rollup_sync_service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/time/rate"
+
+	"github.com/scroll-tech/go-ethereum/common"
+	"github.com/scroll-tech/go-ethereum/consensus/ethash"
+	"github.com/scroll-tech/go-ethereum/core"
+	"github.com/scroll-tech/go-ethereum/core/rawdb"
+	"github.com/scroll-tech/go-ethereum/core/state"
+	"github.com/scroll-tech/go-ethereum/core/vm"
+	"github.com/scroll-tech/go-ethereum/params"
+
+	"github.com/scroll-tech/go-ethereum/rollup/withdrawtrie"
+)
+
+func TestWithdrawRootReaderRegenerate(t *testing.T) {
+	db := rawdb.NewMemoryDatabase()
+	genesis := (&core.Genesis{Config: params.TestChainConfig}).MustCommit(db)
+	blocks, _ := core.GenerateChain(params.TestChainConfig, genesis, ethash.NewFaker(), rawdb.NewMemoryDatabase(), 4, func(i int, b *core.BlockGen) {
+		b.SetCoinbase(common.Address{byte(i + 1)})
+	})
+
+	bc, err := core.NewBlockChain(db, nil, params.TestChainConfig, ethash.NewFaker(), vm.Config{}, nil, nil)
+	require.NoError(t, err)
+	defer bc.Stop()
+	_, err = bc.InsertChain(blocks)
+	require.NoError(t, err)
+
+	service := &RollupSyncService{db: db, bc: bc}
+
+	// the state of recent blocks is only held in memory, so the ephemeral
+	// re-execution database has to start from the genesis state.
+	reader := &withdrawRootReader{s: service, reexec: 1}
+	_, err = reader.regenerate(blocks[2])
+	assert.Error(t, err)
+
+	reader = &withdrawRootReader{s: service, reexec: 10}
+	statedb, err := reader.regenerate(blocks[2])
+	require.NoError(t, err)
+	assert.Equal(t, blocks[2].Root(), statedb.IntermediateRoot(true))
+	assert.Equal(t, uint64(3), reader.regeneratedNumber)
+
+	// consecutive blocks continue from the regenerated state
+	statedb, err = reader.regenerate(blocks[3])
+	require.NoError(t, err)
+	assert.Equal(t, blocks[3].Root(), statedb.IntermediateRoot(true))
+
+	// the live state is available, no re-execution required
+	root, err := service.newWithdrawRootReader().read(blocks[3])
+	require.NoError(t, err)
+	assert.Equal(t, common.Hash{}, root)
+}
+
+func TestWithdrawRootReaderPersisted(t *testing.T) {
+	db := rawdb.NewMemoryDatabase()
+	genesis := (&core.Genesis{Config: params.TestChainConfig}).MustCommit(db)
+	blocks, _ := core.GenerateChain(params.TestChainConfig, genesis, ethash.NewFaker(), rawdb.NewMemoryDatabase(), 2, nil)
+
+	bc, err := core.NewBlockChain(db, nil, params.TestChainConfig, ethash.NewFaker(), vm.Config{}, nil, nil)
+	require.NoError(t, err)
+	defer bc.Stop()
+	bc.SetWithdrawRootFn(func(statedb *state.StateDB) common.Hash { return withdrawtrie.WithdrawRoot(statedb) })
+	_, err = bc.InsertChain(blocks)
+	require.NoError(t, err)
+
+	// the withdraw root is persisted at import time
+	for _, block := range blocks {
+		assert.Equal(t, &common.Hash{}, rawdb.ReadBlockWithdrawRoot(db, block.Hash()))
+	}
+
+	// the persisted withdraw root is used without loading the state
+	service := &RollupSyncService{db: db, bc: bc}
+	withdrawRoot := common.HexToHash("0x01")
+	rawdb.WriteBlockWithdrawRoot(db, blocks[1].Hash(), withdrawRoot)
+	root, err := service.newWithdrawRootReader().read(blocks[1])
+	require.NoError(t, err)
+	assert.Equal(t, withdrawRoot, root)
+}
+
+func TestLoadChunks(t *testing.T) {
+	db := rawdb.NewMemoryDatabase()
+	genesis := (&core.Genesis{Config: params.TestChainConfig}).MustCommit(db)
+	blocks, _ := core.GenerateChain(params.TestChainConfig, genesis, ethash.NewFaker(), rawdb.NewMemoryDatabase(), 10, func(i int, b *core.BlockGen) {
+		b.SetCoinbase(common.Address{byte(i + 1)})
+	})
+
+	bc, err := core.NewBlockChain(db, nil, params.TestChainConfig, ethash.NewFaker(), vm.Config{}, nil, nil)
+	require.NoError(t, err)
+	defer bc.Stop()
+	_, err = bc.InsertChain(blocks)
+	require.NoError(t, err)
+
+	chunkBlockRanges := []*rawdb.ChunkBlockRange{
+		{StartBlockNumber: 2, EndBlockNumber: 4},
+		{StartBlockNumber: 5, EndBlockNumber: 9},
+	}
+	for _, workers := range []int{1, 3, 16} {
+		service := &RollupSyncService{ctx: context.Background(), db: db, bc: bc, validationWorkers: workers, validationLimiter: rate.NewLimiter(1000, 1)}
+		chunks, err := service.loadChunks(chunkBlockRanges)
+		require.NoError(t, err)
+		require.Len(t, chunks, 2)
+		require.Len(t, chunks[0].Blocks, 3)
+		require.Len(t, chunks[1].Blocks, 5)
+		for i, chunk := range chunks {
+			for j, block := range chunk.Blocks {
+				assert.Equal(t, chunkBlockRanges[i].StartBlockNumber+uint64(j), block.Header.Number.Uint64(), "workers: %d", workers)
+			}
+		}
+	}
+
+	// blocks that are not available locally fail the batch
+	service := &RollupSyncService{ctx: context.Background(), db: db, bc: bc, validationWorkers: 2}
+	_, err = service.loadChunks([]*rawdb.ChunkBlockRange{{StartBlockNumber: 8, EndBlockNumber: 11}})
+	assert.Error(t, err)
+}
@@ -0,0 +1,94 @@
+package rollup_sync_service
+
+import (
+	"sync/atomic"
+
+	"github.com/scroll-tech/go-ethereum/core/rawdb"
+
+	"github.com/scroll-tech/go-ethereum/rollup/sync_service"
+)
+
+// Status is a snapshot of the state of the rollup sync service.
+type Status struct {
+	SyncedL1BlockNumber    uint64              `json:"syncedL1BlockNumber"`
+	FinalizedL2BlockNumber uint64              `json:"finalizedL2BlockNumber"`
+	Halted                 bool                `json:"halted"`
+	Paused                 bool                `json:"paused"`
+	ImportPaused           bool                `json:"importPaused"` // block import paused after a mismatch in VerifyModeHaltImport
+	VerifyMode             VerifyMode          `json:"verifyMode"`
+	UnknownEventPolicy     UnknownEventPolicy  `json:"unknownEventPolicy"`
+	Confirmations          uint64              `json:"confirmations"`
+	StrictFinalizeOrder    bool                `json:"strictFinalizeOrder"`
+	EnforcedBatchMode      bool                `json:"enforcedBatchMode"` // enforced batch mode of ScrollChain enabled
+	PoisonedBatches        []uint64            `json:"poisonedBatches"`
+	Poisoned               []*PoisonedBatch    `json:"poisoned"`                  // reasons of the poisoned batches, to be cleared or retried by the operator
+	Checkpoints            []uint64            `json:"checkpoints"`               // L1 block numbers of the stored reorg checkpoints
+	MissingBlocks          *MissingBlocksError `json:"missingBlocks,omitempty"`   // local blocks missing for the last validated batch
+	Recovery               *RecoveryProgress   `json:"recovery,omitempty"`        // progress of a running catch-up with L1
+	L1BatchPointers        *L1BatchPointers    `json:"l1BatchPointers,omitempty"` // newest batches reported by L1 during a catch-up, not validated yet
+	Counters               map[string]int64    `json:"counters"`
+}
+
+// PoisonedBatch is a batch that failed validation, see ClearPoisonedBatch and RetryPoisonedBatch.
+type PoisonedBatch struct {
+	BatchIndex    uint64 `json:"batchIndex"`
+	Reason        string `json:"reason"`
+	L1BlockNumber uint64 `json:"l1BlockNumber"` // L1 block of the finalize event
+}
+
+// Status returns a snapshot of the state of the service. It is safe to call concurrently with the sync loop.
+func (s *RollupSyncService) Status() *Status {
+	status := &Status{
+		Halted:              atomic.LoadInt32(&s.halted) == 1,
+		Paused:              atomic.LoadInt32(&s.paused) == 1,
+		VerifyMode:          s.verifyMode,
+		UnknownEventPolicy:  s.unknownEventPolicy,
+		Confirmations:       s.confirmations,
+		StrictFinalizeOrder: s.strictFinalizeOrder,
+		EnforcedBatchMode:   s.enforcedBatchModeEnabled(),
+		PoisonedBatches:     rawdb.ReadPoisonedBatchIndices(s.db),
+		Poisoned:            []*PoisonedBatch{},
+		Checkpoints:         []uint64{},
+		Counters: map[string]int64{
+			"blockContextMismatch": blockContextMismatchCounter.Count(),
+			"unknownEvents":        unknownEventCounter.Count(),
+			"batchMismatch":        batchMismatchCounter.Count(),
+			"l1Reorgs":             l1ReorgCounter.Count(),
+			"l1RPCErrors":          l1RPCErrorCounter.Count(),
+			"nonMonotonicBatches":  nonMonotonicBatchCounter.Count(),
+			"crossCheckDivergence": crossCheckDivergenceCounter.Count(),
+			"skippedL1Messages":    skippedL1MessageCounter.Count(),
+			"l1SlowQueries":        sync_service.SlowQueries(),
+			"poisonedCleared":      poisonedBatchClearedCounter.Count(),
+			"poisonedRetried":      poisonedBatchRetriedCounter.Count(),
+		},
+	}
+	if s.bc != nil {
+		status.ImportPaused = s.bc.ImportPaused()
+	}
+	if status.PoisonedBatches == nil {
+		status.PoisonedBatches = []uint64{}
+	}
+	for _, batchIndex := range status.PoisonedBatches {
+		if poisoned := rawdb.ReadPoisonedBatch(s.db, batchIndex); poisoned != nil {
+			status.Poisoned = append(status.Poisoned, &PoisonedBatch{BatchIndex: batchIndex, Reason: poisoned.Reason, L1BlockNumber: poisoned.L1BlockNumber})
+		}
+	}
+	if number := rawdb.ReadRollupEventSyncedL1BlockNumber(s.db); number != nil {
+		status.SyncedL1BlockNumber = *number
+	}
+	if number := rawdb.ReadFinalizedL2BlockNumber(s.db); number != nil {
+		status.FinalizedL2BlockNumber = *number
+	}
+	status.Recovery = s.RecoveryProgress()
+	status.L1BatchPointers = s.L1BatchPointers()
+
+	s.missingBlocksLock.Lock()
+	status.MissingBlocks = s.missingBlocks
+	s.missingBlocksLock.Unlock()
+
+	for _, checkpoint := range rawdb.ReadRollupSyncCheckpoints(s.db) {
+		status.Checkpoints = append(status.Checkpoints, checkpoint.L1BlockNumber)
+	}
+	return status
+}
@@ -0,0 +1,76 @@
+package rollup_sync_service
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/scroll-tech/go-ethereum/common"
+	"github.com/scroll-tech/go-ethereum/core/rawdb"
+	"github.com/scroll-tech/go-ethereum/log"
+)
+
+// errNonMonotonicBatch is returned in strict mode if a finalize event does not directly follow the last finalized batch.
+var errNonMonotonicBatch = errors.New("non-monotonic batch index")
+
+// lastFinalizedBatchIndex returns the index of the batch containing the finalized L2 block.
+func (s *RollupSyncService) lastFinalizedBatchIndex() (uint64, bool) {
+	finalizedL2BlockNumber := rawdb.ReadFinalizedL2BlockNumber(s.db)
+	if finalizedL2BlockNumber == nil {
+		return 0, false
+	}
+	batchIndex := rawdb.ReadBatchIndexByL2BlockNumber(s.db, *finalizedL2BlockNumber)
+	if batchIndex == nil || s.readFinalizedBatchMeta(*batchIndex) == nil {
+		return 0, false
+	}
+	// note: batches finalized before the end block index was introduced are not indexed.
+	chunkBlockRanges := s.readBatchChunkRanges(*batchIndex)
+	if len(chunkBlockRanges) == 0 || chunkBlockRanges[len(chunkBlockRanges)-1].EndBlockNumber != *finalizedL2BlockNumber {
+		return 0, false
+	}
+	return *batchIndex, true
+}
+
+// checkFinalizeOrder verifies in strict mode that the batches [startBatchIndex, endBatchIndex] of
+// a finalize event directly follow the last finalized batch, and that the ScrollChain contract has
+// finalized them. A repeated event for the last finalized batch is accepted if its batch hash matches,
+// since events are reprocessed after a restart.
+func (s *RollupSyncService) checkFinalizeOrder(startBatchIndex, endBatchIndex uint64, endBatchHash common.Hash) error {
+	if !s.strictFinalizeOrder {
+		return nil
+	}
+
+	if last, ok := s.lastFinalizedBatchIndex(); ok {
+		var err error
+		switch {
+		case endBatchIndex == last:
+			if stored := s.readFinalizedBatchMeta(last); stored.BatchHash != endBatchHash {
+				err = fmt.Errorf("%w: batch %v is already finalized with batch hash %v, got %v", errNonMonotonicBatch, last, stored.BatchHash.Hex(), endBatchHash.Hex())
+			}
+		case endBatchIndex < last:
+			err = fmt.Errorf("%w: batches %v to %v are below the last finalized batch %v", errNonMonotonicBatch, startBatchIndex, endBatchIndex, last)
+		case startBatchIndex != last+1:
+			err = fmt.Errorf("%w: batches %v to %v do not follow the last finalized batch %v", errNonMonotonicBatch, startBatchIndex, endBatchIndex, last)
+		}
+		if err != nil {
+			nonMonotonicBatchCounter.Inc(1)
+			log.Error("Rejected finalize event in strict mode", "start batch index", startBatchIndex, "end batch index", endBatchIndex, "err", err)
+			return err
+		}
+	}
+
+	// note: only query the contract once the cached index has been passed.
+	if endBatchIndex > s.contractFinalizedBatchIndex {
+		index, err := s.client.getLastFinalizedBatchIndex(s.ctx)
+		if err != nil {
+			return fmt.Errorf("failed to query last finalized batch index of ScrollChain contract, err: %w", err)
+		}
+		s.contractFinalizedBatchIndex = index
+	}
+	if endBatchIndex > s.contractFinalizedBatchIndex {
+		nonMonotonicBatchCounter.Inc(1)
+		err := fmt.Errorf("%w: batch %v is beyond the last finalized batch %v of the ScrollChain contract", errNonMonotonicBatch, endBatchIndex, s.contractFinalizedBatchIndex)
+		log.Error("Rejected finalize event in strict mode", "start batch index", startBatchIndex, "end batch index", endBatchIndex, "err", err)
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,61 @@
+package rollup_sync_service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/scroll-tech/go-ethereum/common"
+	"github.com/scroll-tech/go-ethereum/core"
+	"github.com/scroll-tech/go-ethereum/core/rawdb"
+	"github.com/scroll-tech/go-ethereum/params"
+)
+
+func TestCheckFinalizeOrder(t *testing.T) {
+	genesisConfig := &params.ChainConfig{
+		Scroll: params.ScrollConfig{
+			L1Config: &params.L1Config{
+				L1ChainId:          11155111,
+				ScrollChainAddress: common.HexToAddress("0x2D567EcE699Eabe5afCd141eDB7A4f2D0D6ce8a0"),
+			},
+		},
+	}
+	client := &mockEthClient{lastFinalizedBatchIndex: 5}
+	db := rawdb.NewMemoryDatabase()
+	service, err := NewRollupSyncService(context.Background(), genesisConfig, db, client, &core.BlockChain{}, 1, &Config{StrictFinalizeOrder: true, VerifyMode: VerifyModeHaltSync}, nil)
+	require.NoError(t, err)
+
+	// nothing finalized locally yet
+	require.NoError(t, service.checkFinalizeOrder(0, 0, common.Hash{}))
+
+	batchHash := common.HexToHash("0x01")
+	rawdb.WriteBatchChunkRanges(db, 2, []*rawdb.ChunkBlockRange{{StartBlockNumber: 11, EndBlockNumber: 20}})
+	rawdb.WriteBatchEndBlock(db, 20, 2)
+	rawdb.WriteFinalizedBatchMeta(db, 2, &rawdb.FinalizedBatchMeta{BatchHash: batchHash})
+	rawdb.WriteFinalizedL2BlockNumber(db, 20)
+
+	assert.NoError(t, service.checkFinalizeOrder(3, 3, common.Hash{}))
+	assert.NoError(t, service.checkFinalizeOrder(3, 5, common.Hash{}))
+	assert.NoError(t, service.checkFinalizeOrder(2, 2, batchHash), "repeated event of the last finalized batch")
+	assert.NoError(t, service.checkFinalizeOrder(1, 2, batchHash), "repeated bundle ending at the last finalized batch")
+
+	for _, r := range []struct{ start, end uint64 }{{2, 2}, {1, 1}, {4, 4}, {2, 3}} {
+		err := service.checkFinalizeOrder(r.start, r.end, common.HexToHash("0x02"))
+		assert.True(t, errors.Is(err, errNonMonotonicBatch), "batches %d to %d, err: %v", r.start, r.end, err)
+	}
+
+	// the contract has not finalized batch 6 yet
+	err = service.checkFinalizeOrder(3, 6, common.Hash{})
+	assert.True(t, errors.Is(err, errNonMonotonicBatch))
+	assert.Contains(t, err.Error(), "last finalized batch 5 of the ScrollChain contract")
+
+	client.lastFinalizedBatchIndex = 6
+	assert.NoError(t, service.checkFinalizeOrder(3, 6, common.Hash{}))
+
+	// strict mode disabled
+	service.strictFinalizeOrder = false
+	assert.NoError(t, service.checkFinalizeOrder(1, 1, common.Hash{}))
+}
@@ -0,0 +1,88 @@
+package rollup_sync_service
+
+import (
+	"context"
+	"sync"
+
+	"github.com/scroll-tech/go-ethereum/metrics"
+)
+
+var supersededRoundCounter = metrics.NewRegisteredCounter("rollup/sync/round/superseded", nil)
+
+// fetchSupervisor makes sure that a single sync loop runs, and that a fetch round which was
+// superseded, e.g. by a reset of the sync progress after a switch of the L1 source, stops
+// before it processes more of the ranges it fetched. Every round runs with the generation
+// that was current when it began, superseding the rounds bumps the generation and cancels
+// the running round. The zero value is ready to use.
+type fetchSupervisor struct {
+	mu          sync.Mutex
+	loopRunning bool
+	generation  uint64
+	cancel      context.CancelFunc // cancels the running round, nil if no round runs
+}
+
+// startLoop reports whether the caller may start the sync loop, which is the case if no
+// other loop is running.
+func (f *fetchSupervisor) startLoop() bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.loopRunning {
+		return false
+	}
+	f.loopRunning = true
+	return true
+}
+
+// loopStopped records that the sync loop exited.
+func (f *fetchSupervisor) loopStopped() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.loopRunning = false
+}
+
+// beginRound starts a fetch round with the current generation. The returned context is
+// canceled once the round is superseded or ended.
+func (f *fetchSupervisor) beginRound(parent context.Context) (context.Context, uint64) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	ctx, cancel := context.WithCancel(parent)
+	if f.cancel != nil {
+		f.cancel()
+	}
+	f.cancel = cancel
+	return ctx, f.generation
+}
+
+// endRound releases the context of the round with the given generation.
+func (f *fetchSupervisor) endRound(generation uint64) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.cancel != nil && f.generation == generation {
+		f.cancel()
+		f.cancel = nil
+	}
+}
+
+// current reports whether the round with the given generation was not superseded.
+func (f *fetchSupervisor) current(generation uint64) bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return f.generation == generation
+}
+
+// supersede invalidates the running round, if any, so that it stops at the next range.
+func (f *fetchSupervisor) supersede() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.generation++
+	if f.cancel != nil {
+		f.cancel()
+		f.cancel = nil
+	}
+}
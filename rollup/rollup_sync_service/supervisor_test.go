@@ -0,0 +1,74 @@
+package rollup_sync_service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/scroll-tech/go-ethereum"
+	"github.com/scroll-tech/go-ethereum/core/rawdb"
+	"github.com/scroll-tech/go-ethereum/core/types"
+
+	"github.com/scroll-tech/go-ethereum/rollup/eventbus"
+)
+
+func TestFetchSupervisor(t *testing.T) {
+	var f fetchSupervisor
+	assert.True(t, f.startLoop())
+	assert.False(t, f.startLoop())
+	f.loopStopped()
+	assert.True(t, f.startLoop())
+
+	ctx, generation := f.beginRound(context.Background())
+	assert.True(t, f.current(generation))
+	f.supersede()
+	assert.False(t, f.current(generation))
+	assert.Error(t, ctx.Err())
+	f.endRound(generation)
+
+	// ending a round does not affect the next one
+	ctx, generation = f.beginRound(context.Background())
+	f.endRound(generation - 1)
+	assert.NoError(t, ctx.Err())
+	assert.True(t, f.current(generation))
+	f.endRound(generation)
+	assert.Error(t, ctx.Err())
+}
+
+// supersedingEthClient supersedes the running fetch round when the range starting at supersedeFrom is fetched.
+type supersedingEthClient struct {
+	rangeEthClient
+	service       *RollupSyncService
+	supersedeFrom uint64
+}
+
+func (m *supersedingEthClient) FilterLogs(ctx context.Context, q ethereum.FilterQuery) ([]types.Log, error) {
+	if q.FromBlock.Uint64() == m.supersedeFrom {
+		m.service.supervisor.supersede()
+	}
+	return m.rangeEthClient.FilterLogs(ctx, q)
+}
+
+func TestFetchRollupEventsSuperseded(t *testing.T) {
+	db := rawdb.NewMemoryDatabase()
+	client := &supersedingEthClient{rangeEthClient: rangeEthClient{forkEthClient: forkEthClient{head: 400, finalized: 350, forkBlock: 1000}}, supersedeFrom: 1}
+	service := &RollupSyncService{
+		ctx:    context.Background(),
+		bus:    eventbus.New(),
+		db:     db,
+		client: &L1Client{ctx: context.Background(), client: client},
+	}
+	client.service = service
+
+	// the ranges fetched after the round was superseded are not processed
+	service.fetchRollupEvents()
+	assert.Equal(t, uint64(0), service.latestProcessedBlock)
+	assert.Nil(t, rawdb.ReadRollupEventSyncedL1BlockNumber(db))
+
+	// the next round is not affected
+	client.supersedeFrom = 0
+	service.fetchRollupEvents()
+	assert.Equal(t, uint64(350), service.latestProcessedBlock)
+	assert.Equal(t, uint64(350), *rawdb.ReadRollupEventSyncedL1BlockNumber(db))
+}
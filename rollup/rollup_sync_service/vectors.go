@@ -0,0 +1,179 @@
+package rollup_sync_service
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/scroll-tech/go-ethereum/common"
+	"github.com/scroll-tech/go-ethereum/common/hexutil"
+)
+
+// BatchVector is a test vector of the batch hash computation, as published with the
+// protocol specification of each codec version.
+type BatchVector struct {
+	Name                       string      `json:"name"`
+	CodecVersion               uint8       `json:"codecVersion"`
+	BatchIndex                 uint64      `json:"batchIndex"`
+	TotalL1MessagePoppedBefore uint64      `json:"totalL1MessagePoppedBefore"`
+	ParentBatchHash            common.Hash `json:"parentBatchHash"`
+	// Chunks lists the blocks of each chunk, either as wrapped block objects or as
+	// paths of block trace files relative to the vector file.
+	Chunks [][]json.RawMessage `json:"chunks"`
+	// CommittedChunks are the chunks of the commit calldata, they are checked against the
+	// block contexts of the local blocks if set.
+	CommittedChunks []hexutil.Bytes `json:"committedChunks,omitempty"`
+	// BlobData is the data stored in the blob of batches from version 1, without the padding
+	// of the blob. The versioned hash of the blob is computed from it.
+	BlobData hexutil.Bytes `json:"blobData,omitempty"`
+	// BlobDataProof is the blob data proof of the commit calldata, from version 3.
+	BlobDataProof hexutil.Bytes `json:"blobDataProof,omitempty"`
+	BatchHash     common.Hash   `json:"batchHash"`
+}
+
+// BatchVectorResult is the outcome of checking one test vector.
+type BatchVectorResult struct {
+	Name         string
+	CodecVersion uint8
+	Expected     common.Hash
+	Computed     common.Hash
+	Err          error // set if the batch hash could not be computed
+}
+
+// Passed reports whether the computed batch hash matches the test vector.
+func (r *BatchVectorResult) Passed() bool {
+	return r.Err == nil && r.Computed == r.Expected
+}
+
+// CheckBatchVectors computes the batch hash of every test vector in the file at path
+// with the codec of its version and compares it with the expected hash. It only fails
+// if the file cannot be loaded, failing vectors are reported in the results.
+func CheckBatchVectors(path string) ([]*BatchVectorResult, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var vectors []*BatchVector
+	if err := json.Unmarshal(data, &vectors); err != nil {
+		return nil, fmt.Errorf("failed to decode test vectors: %w", err)
+	}
+	dir := filepath.Dir(path)
+
+	results := make([]*BatchVectorResult, 0, len(vectors))
+	for i, vector := range vectors {
+		if vector.BatchHash == (common.Hash{}) {
+			return nil, fmt.Errorf("test vector %d (%s) has no batch hash", i, vector.Name)
+		}
+		chunks, err := loadVectorChunks(dir, vector.Chunks)
+		if err != nil {
+			return nil, fmt.Errorf("test vector %d (%s): %w", i, vector.Name, err)
+		}
+		result := &BatchVectorResult{
+			Name:         vector.Name,
+			CodecVersion: vector.CodecVersion,
+			Expected:     vector.BatchHash,
+		}
+		result.Computed, result.Err = checkBatchVector(vector, chunks)
+		results = append(results, result)
+	}
+	return results, nil
+}
+
+// checkBatchVector checks the committed chunks and the blob of a test vector against its
+// blocks and computes the batch hash.
+func checkBatchVector(vector *BatchVector, chunks []*Chunk) (common.Hash, error) {
+	codec, err := CodecForVersion(vector.CodecVersion)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	if len(vector.CommittedChunks) > 0 {
+		committed := make([][]byte, len(vector.CommittedChunks))
+		for i, chunk := range vector.CommittedChunks {
+			committed[i] = chunk
+		}
+		chunkBlockContexts, err := codec.DecodeChunkBlockContexts(committed)
+		if err != nil {
+			return common.Hash{}, fmt.Errorf("failed to decode committed chunks: %w", err)
+		}
+		if len(chunkBlockContexts) != len(chunks) {
+			return common.Hash{}, fmt.Errorf("%d committed chunks, %d chunks of blocks", len(chunkBlockContexts), len(chunks))
+		}
+		for i, blockContexts := range chunkBlockContexts {
+			if len(blockContexts) != len(chunks[i].Blocks) {
+				return common.Hash{}, fmt.Errorf("committed chunk %d has %d blocks, expected %d", i, len(blockContexts), len(chunks[i].Blocks))
+			}
+			for j, blockContext := range blockContexts {
+				header := chunks[i].Blocks[j].Header
+				if blockContext.BlockNumber != header.Number.Uint64() || blockContext.Timestamp != header.Time {
+					return common.Hash{}, fmt.Errorf("committed chunk %d: block %d at %d, expected block %d at %d", i, blockContext.BlockNumber, blockContext.Timestamp, header.Number, header.Time)
+				}
+				if err := compareBlockContext(blockContext, header); err != nil {
+					return common.Hash{}, fmt.Errorf("committed chunk %d: %w", i, err)
+				}
+			}
+		}
+	}
+	if blobCodec, ok := codec.(BlobCodec); ok {
+		blob, err := makeBlob(vector.BlobData)
+		if err != nil {
+			return common.Hash{}, err
+		}
+		if err := blobCodec.CheckBlob(blob, chunks); err != nil {
+			return common.Hash{}, err
+		}
+		versionedHash, err := blobVersionedHash(blob)
+		if err != nil {
+			return common.Hash{}, err
+		}
+		codec = blobCodec.WithBlob(&BatchBlob{VersionedHash: versionedHash, DataProof: vector.BlobDataProof})
+	}
+	return codec.BatchHash(vector.BatchIndex, vector.TotalL1MessagePoppedBefore, vector.ParentBatchHash, chunks)
+}
+
+// UncoveredCodecVersions returns the versions of the registered codecs that no test
+// vector of results was checked with.
+func UncoveredCodecVersions(results []*BatchVectorResult) []uint8 {
+	covered := make(map[uint8]bool)
+	for _, result := range results {
+		covered[result.CodecVersion] = true
+	}
+	var versions []uint8
+	for _, codec := range registeredCodecs() {
+		if !covered[codec.Version()] {
+			versions = append(versions, codec.Version())
+		}
+	}
+	return versions
+}
+
+// loadVectorChunks decodes the chunks of a test vector, reading the blocks given as
+// file paths from dir.
+func loadVectorChunks(dir string, rawChunks [][]json.RawMessage) ([]*Chunk, error) {
+	chunks := make([]*Chunk, 0, len(rawChunks))
+	for _, rawBlocks := range rawChunks {
+		chunk := &Chunk{Blocks: make([]*WrappedBlock, 0, len(rawBlocks))}
+		for _, rawBlock := range rawBlocks {
+			var file string
+			if err := json.Unmarshal(rawBlock, &file); err == nil {
+				if !filepath.IsAbs(file) {
+					file = filepath.Join(dir, file)
+				}
+				if rawBlock, err = os.ReadFile(file); err != nil {
+					return nil, err
+				}
+			}
+			block := new(WrappedBlock)
+			if err := json.Unmarshal(rawBlock, block); err != nil {
+				return nil, fmt.Errorf("failed to decode block: %w", err)
+			}
+			if block.Header == nil {
+				return nil, errors.New("block without header")
+			}
+			chunk.Blocks = append(chunk.Blocks, block)
+		}
+		chunks = append(chunks, chunk)
+	}
+	return chunks, nil
+}
@@ -0,0 +1,66 @@
+package rollup_sync_service
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/scroll-tech/go-ethereum/common"
+)
+
+func TestCheckBatchVectors(t *testing.T) {
+	results, err := CheckBatchVectors("./testdata/vectors/batch_vectors.json")
+	require.NoError(t, err)
+	require.Len(t, results, 7)
+	for _, result := range results {
+		assert.NoError(t, result.Err, result.Name)
+		assert.True(t, result.Passed(), result.Name)
+	}
+	assert.Empty(t, UncoveredCodecVersions(results))
+
+	// a wrong hash or an unknown codec version fails the vector, not the check
+	trace, err := filepath.Abs("./testdata/blockTrace_02.json")
+	require.NoError(t, err)
+	wrongHash := common.HexToHash("0x01")
+	path := filepath.Join(t.TempDir(), "vectors.json")
+	require.NoError(t, os.WriteFile(path, []byte(`[
+		{"name": "wrong hash", "chunks": [["`+trace+`"]], "batchHash": "`+wrongHash.Hex()+`"},
+		{"name": "unknown version", "codecVersion": 255, "chunks": [["`+trace+`"]], "batchHash": "`+wrongHash.Hex()+`"}
+	]`), 0644))
+	results, err = CheckBatchVectors(path)
+	require.NoError(t, err)
+	require.Len(t, results, 2)
+	assert.NoError(t, results[0].Err)
+	assert.False(t, results[0].Passed())
+	assert.Equal(t, wrongHash, results[0].Expected)
+	assert.NotEqual(t, common.Hash{}, results[0].Computed)
+	assert.Error(t, results[1].Err)
+	assert.False(t, results[1].Passed())
+	assert.Equal(t, []uint8{1, 2, 3, 4}, UncoveredCodecVersions(results))
+	assert.Equal(t, []uint8{0, 1, 2, 3, 4}, UncoveredCodecVersions(results[1:]))
+
+	// blob data or committed chunks that do not match the blocks fail the vector
+	trace5, err := filepath.Abs("./testdata/blockTrace_05.json")
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(path, []byte(`[
+		{"name": "wrong blob", "codecVersion": 1, "chunks": [["`+trace+`"]], "blobData": "0x0001", "batchHash": "`+wrongHash.Hex()+`"},
+		{"name": "wrong chunk", "codecVersion": 1, "chunks": [["`+trace5+`"]], "committedChunks": ["0x01`+strings.Repeat("00", blockContextByteSize)+`"], "batchHash": "`+wrongHash.Hex()+`"}
+	]`), 0644))
+	results, err = CheckBatchVectors(path)
+	require.NoError(t, err)
+	require.Len(t, results, 2)
+	assert.Error(t, results[0].Err)
+	assert.Error(t, results[1].Err)
+
+	// malformed files fail the check
+	require.NoError(t, os.WriteFile(path, []byte(`[{"name": "no hash", "chunks": []}]`), 0644))
+	_, err = CheckBatchVectors(path)
+	assert.Error(t, err)
+	require.NoError(t, os.WriteFile(path, []byte(`[{"name": "missing file", "chunks": [["missing.json"]], "batchHash": "`+wrongHash.Hex()+`"}]`), 0644))
+	_, err = CheckBatchVectors(path)
+	assert.Error(t, err)
+}
@@ -8,6 +8,7 @@ import (
 
 	"github.com/scroll-tech/go-ethereum/accounts/abi/bind"
 	"github.com/scroll-tech/go-ethereum/common"
+	"github.com/scroll-tech/go-ethereum/core/rawdb"
 	"github.com/scroll-tech/go-ethereum/core/types"
 	"github.com/scroll-tech/go-ethereum/log"
 	"github.com/scroll-tech/go-ethereum/rpc"
@@ -20,9 +21,21 @@ type BridgeClient struct {
 	confirmations         rpc.BlockNumber
 	l1MessageQueueAddress common.Address
 	filterer              *L1MessageQueueFilterer
+
+	// messages are collected from the L1MessageQueueV2 contract from v2MigrationBlock on,
+	// v2Filterer is nil if no such contract is configured
+	v2Filterer       *L1MessageQueueV2Filterer
+	v2MigrationBlock uint64
+}
+
+// l1Message is an L1 message collected from a message queue contract.
+type l1Message struct {
+	tx     types.L1MessageTx
+	origin rawdb.L1MessageOrigin
+	fee    *rawdb.L1MessageFee // nil for messages of the original queue contract
 }
 
-func newBridgeClient(ctx context.Context, l1Client EthClient, l1ChainId uint64, confirmations rpc.BlockNumber, l1MessageQueueAddress common.Address) (*BridgeClient, error) {
+func newBridgeClient(ctx context.Context, l1Client EthClient, l1ChainId uint64, confirmations rpc.BlockNumber, l1MessageQueueAddress common.Address, l1MessageQueueV2Address common.Address, v2MigrationBlock uint64) (*BridgeClient, error) {
 	if l1MessageQueueAddress == (common.Address{}) {
 		return nil, errors.New("must pass non-zero l1MessageQueueAddress to BridgeClient")
 	}
@@ -48,14 +61,42 @@ func newBridgeClient(ctx context.Context, l1Client EthClient, l1ChainId uint64,
 		filterer:              filterer,
 	}
 
+	if l1MessageQueueV2Address != (common.Address{}) {
+		client.v2Filterer, err = NewL1MessageQueueV2Filterer(l1MessageQueueV2Address, l1Client)
+		if err != nil {
+			return nil, fmt.Errorf("failed to initialize L1MessageQueueV2Filterer, err = %w", err)
+		}
+		client.v2MigrationBlock = v2MigrationBlock
+	}
+
 	return &client, nil
 }
 
 // fetchMessagesInRange retrieves and parses all L1 messages between the
-// provided from and to L1 block numbers (inclusive).
-func (c *BridgeClient) fetchMessagesInRange(ctx context.Context, from, to uint64) ([]types.L1MessageTx, error) {
+// provided from and to L1 block numbers (inclusive). Ranges across the migration
+// block are collected from both message queue contracts.
+func (c *BridgeClient) fetchMessagesInRange(ctx context.Context, from, to uint64) ([]l1Message, error) {
 	log.Trace("BridgeClient fetchMessagesInRange", "fromBlock", from, "toBlock", to)
 
+	if c.v2Filterer == nil || to < c.v2MigrationBlock {
+		return c.fetchV1MessagesInRange(ctx, from, to)
+	}
+	if from >= c.v2MigrationBlock {
+		return c.fetchV2MessagesInRange(ctx, from, to)
+	}
+	msgs, err := c.fetchV1MessagesInRange(ctx, from, c.v2MigrationBlock-1)
+	if err != nil {
+		return nil, err
+	}
+	v2Msgs, err := c.fetchV2MessagesInRange(ctx, c.v2MigrationBlock, to)
+	if err != nil {
+		return nil, err
+	}
+	return append(msgs, v2Msgs...), nil
+}
+
+// fetchV1MessagesInRange retrieves the L1 messages of the original queue contract.
+func (c *BridgeClient) fetchV1MessagesInRange(ctx context.Context, from, to uint64) ([]l1Message, error) {
 	opts := bind.FilterOpts{
 		Start:   from,
 		End:     &to,
@@ -66,7 +107,7 @@ func (c *BridgeClient) fetchMessagesInRange(ctx context.Context, from, to uint64
 		return nil, err
 	}
 
-	var msgs []types.L1MessageTx
+	var msgs []l1Message
 
 	for it.Next() {
 		event := it.Event
@@ -76,17 +117,59 @@ func (c *BridgeClient) fetchMessagesInRange(ctx context.Context, from, to uint64
 			return nil, fmt.Errorf("invalid QueueTransaction event: QueueIndex = %v, GasLimit = %v", event.QueueIndex, event.GasLimit)
 		}
 
-		msgs = append(msgs, types.L1MessageTx{
-			QueueIndex: event.QueueIndex,
-			Gas:        event.GasLimit.Uint64(),
-			To:         &event.Target,
-			Value:      event.Value,
-			Data:       event.Data,
-			Sender:     event.Sender,
+		msgs = append(msgs, l1Message{
+			tx: types.L1MessageTx{
+				QueueIndex: event.QueueIndex,
+				Gas:        event.GasLimit.Uint64(),
+				To:         &event.Target,
+				Value:      event.Value,
+				Data:       event.Data,
+				Sender:     event.Sender,
+			},
+			origin: rawdb.L1MessageOrigin{L1BlockNumber: event.Raw.BlockNumber, L1TxHash: event.Raw.TxHash},
+		})
+	}
+
+	return msgs, it.Error()
+}
+
+// fetchV2MessagesInRange retrieves the L1 messages of the L1MessageQueueV2 contract.
+func (c *BridgeClient) fetchV2MessagesInRange(ctx context.Context, from, to uint64) ([]l1Message, error) {
+	opts := bind.FilterOpts{
+		Start:   from,
+		End:     &to,
+		Context: ctx,
+	}
+	it, err := c.v2Filterer.FilterQueueTransaction(&opts, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var msgs []l1Message
+
+	for it.Next() {
+		event := it.Event
+		log.Trace("Received new L1 QueueTransaction V2 event", "event", event)
+
+		if !event.GasLimit.IsUint64() {
+			return nil, fmt.Errorf("invalid QueueTransaction V2 event: QueueIndex = %v, GasLimit = %v", event.QueueIndex, event.GasLimit)
+		}
+
+		msgs = append(msgs, l1Message{
+			tx: types.L1MessageTx{
+				QueueIndex: event.QueueIndex,
+				Gas:        event.GasLimit.Uint64(),
+				To:         &event.Target,
+				Value:      event.Value,
+				Data:       event.Data,
+				Sender:     event.Sender,
+			},
+			origin: rawdb.L1MessageOrigin{L1BlockNumber: event.Raw.BlockNumber, L1TxHash: event.Raw.TxHash},
+			fee:    &rawdb.L1MessageFee{L2BaseFee: event.L2BaseFee, Fee: event.Fee},
 		})
 	}
 
-	return msgs, nil
+	return msgs, it.Error()
 }
 
 func (c *BridgeClient) getLatestConfirmedBlockNumber(ctx context.Context) (uint64, error) {
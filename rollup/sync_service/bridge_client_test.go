@@ -0,0 +1,101 @@
+package sync_service
+
+import (
+	"context"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/scroll-tech/go-ethereum"
+	"github.com/scroll-tech/go-ethereum/accounts/abi"
+	"github.com/scroll-tech/go-ethereum/common"
+	"github.com/scroll-tech/go-ethereum/core/types"
+	"github.com/scroll-tech/go-ethereum/rpc"
+)
+
+// queueEthClient serves the QueueTransaction logs of both message queue contracts.
+type queueEthClient struct {
+	flakyEthClient
+	logs []types.Log
+}
+
+func (m *queueEthClient) FilterLogs(ctx context.Context, q ethereum.FilterQuery) ([]types.Log, error) {
+	var logs []types.Log
+	for _, vLog := range m.logs {
+		if vLog.Address == q.Addresses[0] && vLog.Topics[0] == q.Topics[0][0] &&
+			vLog.BlockNumber >= q.FromBlock.Uint64() && vLog.BlockNumber <= q.ToBlock.Uint64() {
+			logs = append(logs, vLog)
+		}
+	}
+	return logs, nil
+}
+
+func queueTransactionLog(t *testing.T, abiJSON string, address common.Address, blockNumber, queueIndex uint64, fees ...interface{}) types.Log {
+	parsed, err := abi.JSON(strings.NewReader(abiJSON))
+	require.NoError(t, err)
+	event := parsed.Events["QueueTransaction"]
+	data, err := event.Inputs.NonIndexed().Pack(append([]interface{}{big.NewInt(1), queueIndex, big.NewInt(21000), []byte{0x01}}, fees...)...)
+	require.NoError(t, err)
+	return types.Log{
+		Address:     address,
+		Topics:      []common.Hash{event.ID, common.BytesToHash(common.Address{0x01}.Bytes()), common.BytesToHash(common.Address{0x02}.Bytes())},
+		Data:        data,
+		BlockNumber: blockNumber,
+		TxHash:      common.BigToHash(new(big.Int).SetUint64(queueIndex + 1)),
+	}
+}
+
+func TestBridgeClientMessageQueueMigration(t *testing.T) {
+	v1Address, v2Address := common.Address{0x10}, common.Address{0x20}
+	client := &queueEthClient{logs: []types.Log{
+		queueTransactionLog(t, L1MessageQueueABI, v1Address, 5, 0),
+		queueTransactionLog(t, L1MessageQueueABI, v1Address, 9, 1),
+		queueTransactionLog(t, L1MessageQueueV2ABI, v2Address, 10, 2, big.NewInt(7), big.NewInt(1000)),
+		queueTransactionLog(t, L1MessageQueueV2ABI, v2Address, 15, 3, big.NewInt(8), big.NewInt(2000)),
+	}}
+	bridgeClient, err := newBridgeClient(context.Background(), client, 1, rpc.LatestBlockNumber, v1Address, v2Address, 10)
+	require.NoError(t, err)
+
+	queueIndices := func(msgs []l1Message) []uint64 {
+		var indices []uint64
+		for _, msg := range msgs {
+			indices = append(indices, msg.tx.QueueIndex)
+		}
+		return indices
+	}
+
+	// before the migration block only the original contract is queried
+	msgs, err := bridgeClient.fetchMessagesInRange(context.Background(), 0, 9)
+	require.NoError(t, err)
+	assert.Equal(t, []uint64{0, 1}, queueIndices(msgs))
+	assert.Nil(t, msgs[0].fee)
+	assert.Equal(t, common.Address{0x01}, msgs[0].tx.Sender)
+	assert.Equal(t, common.Address{0x02}, *msgs[0].tx.To)
+	assert.Equal(t, uint64(21000), msgs[0].tx.Gas)
+	assert.Equal(t, uint64(9), msgs[1].origin.L1BlockNumber)
+	assert.Equal(t, common.BigToHash(big.NewInt(2)), msgs[1].origin.L1TxHash)
+
+	// from the migration block on only the L1MessageQueueV2 contract is queried
+	msgs, err = bridgeClient.fetchMessagesInRange(context.Background(), 10, 20)
+	require.NoError(t, err)
+	assert.Equal(t, []uint64{2, 3}, queueIndices(msgs))
+	require.NotNil(t, msgs[1].fee)
+	assert.Equal(t, big.NewInt(8), msgs[1].fee.L2BaseFee)
+	assert.Equal(t, big.NewInt(2000), msgs[1].fee.Fee)
+	assert.Equal(t, uint64(15), msgs[1].origin.L1BlockNumber)
+
+	// ranges across the migration block are split
+	msgs, err = bridgeClient.fetchMessagesInRange(context.Background(), 0, 20)
+	require.NoError(t, err)
+	assert.Equal(t, []uint64{0, 1, 2, 3}, queueIndices(msgs))
+
+	// without the L1MessageQueueV2 contract, its messages are ignored
+	bridgeClient, err = newBridgeClient(context.Background(), client, 1, rpc.LatestBlockNumber, v1Address, common.Address{}, 0)
+	require.NoError(t, err)
+	msgs, err = bridgeClient.fetchMessagesInRange(context.Background(), 0, 20)
+	require.NoError(t, err)
+	assert.Equal(t, []uint64{0, 1}, queueIndices(msgs))
+}
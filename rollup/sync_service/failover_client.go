@@ -0,0 +1,266 @@
+package sync_service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"math/rand"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/scroll-tech/go-ethereum"
+	"github.com/scroll-tech/go-ethereum/common"
+	"github.com/scroll-tech/go-ethereum/core/types"
+	"github.com/scroll-tech/go-ethereum/log"
+	"github.com/scroll-tech/go-ethereum/rpc"
+)
+
+const (
+	// defaultFailoverRetries is the number of attempts of a request across all endpoints.
+	defaultFailoverRetries = 5
+
+	// defaultFailoverBaseDelay is the backoff before the first retry, doubled on each further retry.
+	defaultFailoverBaseDelay = 250 * time.Millisecond
+
+	// defaultFailoverMaxDelay caps the backoff between retries.
+	defaultFailoverMaxDelay = 8 * time.Second
+)
+
+// failoverEndpoint is an L1 endpoint along with its health score.
+type failoverEndpoint struct {
+	name   string
+	client EthClient
+	score  float64 // recent failures, decays by half on each success
+}
+
+// FailoverClient is an EthClient that spreads requests over multiple L1 endpoints.
+// Each request is sent to the healthiest endpoint, and retried with exponential
+// backoff and jitter on the then healthiest endpoint if it fails. Endpoints are
+// preferred in the order they are given if they are equally healthy.
+type FailoverClient struct {
+	endpoints []*failoverEndpoint
+	lock      sync.Mutex
+
+	retries   int
+	baseDelay time.Duration
+	maxDelay  time.Duration
+}
+
+type endpointRecorderKey struct{}
+
+// EndpointRecorder collects the names of the L1 endpoints of a FailoverClient
+// that served the requests made with the context returned by WithEndpointRecorder.
+type EndpointRecorder struct {
+	lock  sync.Mutex
+	names []string
+}
+
+// WithEndpointRecorder returns a context that records the endpoints serving the
+// requests made with it. Requests to other clients than FailoverClient are not recorded.
+func WithEndpointRecorder(ctx context.Context) (context.Context, *EndpointRecorder) {
+	r := new(EndpointRecorder)
+	return context.WithValue(ctx, endpointRecorderKey{}, r), r
+}
+
+func (r *EndpointRecorder) record(name string) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	for _, n := range r.names {
+		if n == name {
+			return
+		}
+	}
+	r.names = append(r.names, name)
+}
+
+// Endpoints returns the names of the recorded endpoints in the order they first served a request.
+func (r *EndpointRecorder) Endpoints() []string {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	return append([]string(nil), r.names...)
+}
+
+// NewFailoverClient creates a client failing over between the given endpoint clients.
+// The names are used to identify the endpoints in logs.
+func NewFailoverClient(clients []EthClient, names []string) (*FailoverClient, error) {
+	if len(clients) == 0 {
+		return nil, errors.New("no L1 endpoints")
+	}
+	if len(clients) != len(names) {
+		return nil, fmt.Errorf("mismatched number of L1 endpoint names, clients: %d, names: %d", len(clients), len(names))
+	}
+	c := &FailoverClient{
+		retries:   defaultFailoverRetries,
+		baseDelay: defaultFailoverBaseDelay,
+		maxDelay:  defaultFailoverMaxDelay,
+	}
+	for i, client := range clients {
+		c.endpoints = append(c.endpoints, &failoverEndpoint{name: names[i], client: client})
+	}
+	return c, nil
+}
+
+// healthiest returns the endpoint with the lowest failure score.
+func (c *FailoverClient) healthiest() *failoverEndpoint {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	best := c.endpoints[0]
+	for _, ep := range c.endpoints[1:] {
+		if ep.score < best.score {
+			best = ep
+		}
+	}
+	return best
+}
+
+func (c *FailoverClient) report(ep *failoverEndpoint, err error) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if err == nil {
+		ep.score /= 2
+	} else {
+		ep.score++
+	}
+}
+
+// retryable reports whether a request that failed with err may succeed on retry.
+// Error responses of the endpoint, e.g. for invalid requests, are not retried.
+func retryable(err error) bool {
+	if errors.Is(err, ethereum.NotFound) || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	var rpcErr rpc.Error
+	return !errors.As(err, &rpcErr)
+}
+
+// do runs the request fn against the healthiest endpoint until it succeeds,
+// fails with a non-retryable error or runs out of attempts.
+func (c *FailoverClient) do(ctx context.Context, method string, fn func(EthClient) error) error {
+	var err error
+	for attempt := 0; attempt < c.retries; attempt++ {
+		if attempt > 0 {
+			delay := c.baseDelay << (attempt - 1)
+			if delay > c.maxDelay || delay <= 0 {
+				delay = c.maxDelay
+			}
+			// add up to 50% jitter, so that clients do not retry in lockstep
+			delay += time.Duration(rand.Int63n(int64(delay)/2 + 1))
+
+			select {
+			case <-time.After(delay):
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+
+		ep := c.healthiest()
+		if err = fn(ep.client); err == nil || !retryable(err) {
+			// note: non-retryable errors are answers of the endpoint or caused by the caller,
+			// they do not tell about the health of the endpoint.
+			if err == nil {
+				c.report(ep, nil)
+			}
+			if r, ok := ctx.Value(endpointRecorderKey{}).(*EndpointRecorder); ok {
+				r.record(ep.name)
+			}
+			return err
+		}
+		c.report(ep, err)
+		log.Debug("L1 request failed", "method", method, "endpoint", ep.name, "attempt", attempt+1, "err", err)
+	}
+	return err
+}
+
+func (c *FailoverClient) BlockNumber(ctx context.Context) (uint64, error) {
+	var number uint64
+	err := c.do(ctx, "BlockNumber", func(client EthClient) (err error) {
+		number, err = client.BlockNumber(ctx)
+		return err
+	})
+	return number, err
+}
+
+func (c *FailoverClient) ChainID(ctx context.Context) (*big.Int, error) {
+	var chainID *big.Int
+	err := c.do(ctx, "ChainID", func(client EthClient) (err error) {
+		chainID, err = client.ChainID(ctx)
+		return err
+	})
+	return chainID, err
+}
+
+func (c *FailoverClient) FilterLogs(ctx context.Context, q ethereum.FilterQuery) ([]types.Log, error) {
+	var logs []types.Log
+	err := c.do(ctx, "FilterLogs", func(client EthClient) (err error) {
+		logs, err = client.FilterLogs(ctx, q)
+		return err
+	})
+	return logs, err
+}
+
+func (c *FailoverClient) HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error) {
+	var header *types.Header
+	err := c.do(ctx, "HeaderByNumber", func(client EthClient) (err error) {
+		header, err = client.HeaderByNumber(ctx, number)
+		return err
+	})
+	return header, err
+}
+
+// SubscribeFilterLogs subscribes on the healthiest endpoint that supports subscriptions.
+// The subscription is not moved to another endpoint if it fails.
+func (c *FailoverClient) SubscribeFilterLogs(ctx context.Context, query ethereum.FilterQuery, ch chan<- types.Log) (ethereum.Subscription, error) {
+	c.lock.Lock()
+	endpoints := make([]*failoverEndpoint, len(c.endpoints))
+	copy(endpoints, c.endpoints)
+	sort.SliceStable(endpoints, func(i, j int) bool { return endpoints[i].score < endpoints[j].score })
+	c.lock.Unlock()
+
+	err := errors.New("log subscriptions are not supported")
+	for _, ep := range endpoints {
+		sub, subErr := ep.client.SubscribeFilterLogs(ctx, query, ch)
+		if subErr == nil && sub != nil {
+			return sub, nil
+		}
+		if subErr != nil {
+			err = subErr
+		}
+	}
+	return nil, err
+}
+
+func (c *FailoverClient) TransactionByHash(ctx context.Context, txHash common.Hash) (*types.Transaction, bool, error) {
+	var (
+		tx        *types.Transaction
+		isPending bool
+	)
+	err := c.do(ctx, "TransactionByHash", func(client EthClient) (err error) {
+		tx, isPending, err = client.TransactionByHash(ctx, txHash)
+		return err
+	})
+	return tx, isPending, err
+}
+
+func (c *FailoverClient) BlockByHash(ctx context.Context, hash common.Hash) (*types.Block, error) {
+	var block *types.Block
+	err := c.do(ctx, "BlockByHash", func(client EthClient) (err error) {
+		block, err = client.BlockByHash(ctx, hash)
+		return err
+	})
+	return block, err
+}
+
+func (c *FailoverClient) CallContract(ctx context.Context, call ethereum.CallMsg, blockNumber *big.Int) ([]byte, error) {
+	var result []byte
+	err := c.do(ctx, "CallContract", func(client EthClient) (err error) {
+		result, err = client.CallContract(ctx, call, blockNumber)
+		return err
+	})
+	return result, err
+}
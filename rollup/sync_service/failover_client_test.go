@@ -0,0 +1,146 @@
+package sync_service
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/scroll-tech/go-ethereum"
+	"github.com/scroll-tech/go-ethereum/common"
+	"github.com/scroll-tech/go-ethereum/core/types"
+)
+
+// flakyEthClient fails the first failures requests and counts all requests.
+type flakyEthClient struct {
+	number   uint64
+	failures int
+	requests int
+	err      error
+}
+
+func (m *flakyEthClient) BlockNumber(ctx context.Context) (uint64, error) {
+	m.requests++
+	if m.requests <= m.failures {
+		return 0, m.err
+	}
+	return m.number, nil
+}
+
+func (m *flakyEthClient) ChainID(ctx context.Context) (*big.Int, error) { return big.NewInt(1), nil }
+
+func (m *flakyEthClient) FilterLogs(ctx context.Context, q ethereum.FilterQuery) ([]types.Log, error) {
+	return nil, nil
+}
+
+func (m *flakyEthClient) HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error) {
+	return nil, nil
+}
+
+func (m *flakyEthClient) SubscribeFilterLogs(ctx context.Context, query ethereum.FilterQuery, ch chan<- types.Log) (ethereum.Subscription, error) {
+	return nil, errors.New("notifications not supported")
+}
+
+func (m *flakyEthClient) TransactionByHash(ctx context.Context, txHash common.Hash) (*types.Transaction, bool, error) {
+	return nil, false, ethereum.NotFound
+}
+
+func (m *flakyEthClient) BlockByHash(ctx context.Context, hash common.Hash) (*types.Block, error) {
+	return nil, nil
+}
+
+func (m *flakyEthClient) CallContract(ctx context.Context, call ethereum.CallMsg, blockNumber *big.Int) ([]byte, error) {
+	return nil, nil
+}
+
+func newTestFailoverClient(t *testing.T, clients ...*flakyEthClient) *FailoverClient {
+	var (
+		ethClients []EthClient
+		names      []string
+	)
+	for i, client := range clients {
+		ethClients = append(ethClients, client)
+		names = append(names, string(rune('a'+i)))
+	}
+	c, err := NewFailoverClient(ethClients, names)
+	require.NoError(t, err)
+	c.baseDelay = time.Millisecond
+	c.maxDelay = 4 * time.Millisecond
+	return c
+}
+
+func TestFailoverClient(t *testing.T) {
+	_, err := NewFailoverClient(nil, nil)
+	assert.Error(t, err)
+
+	// requests fail over to the second endpoint while the first one is down
+	primary := &flakyEthClient{number: 1, failures: 2, err: errors.New("connection refused")}
+	secondary := &flakyEthClient{number: 2}
+	c := newTestFailoverClient(t, primary, secondary)
+
+	number, err := c.BlockNumber(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, uint64(2), number)
+	assert.Equal(t, 1, primary.requests)
+
+	// the primary endpoint is preferred again once the secondary is less healthy
+	secondary.failures, secondary.requests, secondary.err = 4, 0, errors.New("timeout")
+	number, err = c.BlockNumber(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, uint64(1), number)
+
+	// not found responses are not retried and do not count as successful requests
+	ep := c.healthiest()
+	score := ep.score
+	require.NotZero(t, score)
+	_, _, err = c.TransactionByHash(context.Background(), common.Hash{})
+	assert.ErrorIs(t, err, ethereum.NotFound)
+	assert.Equal(t, score, ep.score)
+
+	_, err = c.SubscribeFilterLogs(context.Background(), ethereum.FilterQuery{}, make(chan types.Log))
+	assert.Error(t, err)
+}
+
+func TestFailoverClientRetries(t *testing.T) {
+	client := &flakyEthClient{failures: 100, err: errors.New("connection refused")}
+	c := newTestFailoverClient(t, client)
+
+	_, err := c.BlockNumber(context.Background())
+	assert.Error(t, err)
+	assert.Equal(t, defaultFailoverRetries, client.requests)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	client.requests = 0
+	_, err = c.BlockNumber(ctx)
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Equal(t, 1, client.requests)
+}
+
+func TestFailoverClientEndpointRecorder(t *testing.T) {
+	primary := &flakyEthClient{number: 1, failures: 1, err: errors.New("connection refused")}
+	secondary := &flakyEthClient{number: 2}
+	c := newTestFailoverClient(t, primary, secondary)
+
+	ctx, recorder := WithEndpointRecorder(context.Background())
+	_, err := c.BlockNumber(ctx)
+	require.NoError(t, err)
+	_, err = c.BlockNumber(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"b"}, recorder.Endpoints())
+
+	// the primary endpoint is healthier again after the secondary failed
+	secondary.failures, secondary.requests, secondary.err = 2, 0, errors.New("timeout")
+	_, err = c.BlockNumber(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"b", "a"}, recorder.Endpoints())
+
+	// requests without a recorder are not recorded
+	_, err = c.BlockNumber(context.Background())
+	require.NoError(t, err)
+	assert.Len(t, recorder.Endpoints(), 2)
+}
@@ -0,0 +1,112 @@
+package sync_service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"sync/atomic"
+
+	"github.com/scroll-tech/go-ethereum"
+	"github.com/scroll-tech/go-ethereum/common"
+	"github.com/scroll-tech/go-ethereum/core/types"
+	"github.com/scroll-tech/go-ethereum/log"
+)
+
+// L1Mode selects the source of the L1 data that the sync services consume.
+type L1Mode string
+
+const (
+	// L1ModeTrusted reads L1 data from the trusted L1 RPC endpoints.
+	L1ModeTrusted L1Mode = "trusted"
+	// L1ModeVerified reads L1 data from an L1 light client, which verifies it
+	// against the L1 consensus instead of trusting the RPC provider.
+	L1ModeVerified L1Mode = "verified"
+)
+
+// ParseL1Mode parses an L1 mode from a string.
+func ParseL1Mode(s string) (L1Mode, error) {
+	switch mode := L1Mode(s); mode {
+	case L1ModeTrusted, L1ModeVerified:
+		return mode, nil
+	default:
+		return "", fmt.Errorf("invalid L1 mode %q, expected one of: %v, %v", s, L1ModeTrusted, L1ModeVerified)
+	}
+}
+
+// ModeSwitchClient is an EthClient that sends requests either to the trusted or the
+// verified L1 client, depending on the current L1 mode. The mode can be switched at
+// runtime, requests that are in flight complete with the previous client.
+type ModeSwitchClient struct {
+	trusted  EthClient
+	verified EthClient
+	mode     atomic.Value // L1Mode
+}
+
+// NewModeSwitchClient creates a ModeSwitchClient starting in the given mode.
+func NewModeSwitchClient(trusted, verified EthClient, mode L1Mode) (*ModeSwitchClient, error) {
+	if trusted == nil || verified == nil {
+		return nil, errors.New("both a trusted and a verified L1 client are required")
+	}
+	c := &ModeSwitchClient{trusted: trusted, verified: verified}
+	if err := c.SetMode(mode); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// Mode returns the current L1 mode.
+func (c *ModeSwitchClient) Mode() L1Mode {
+	return c.mode.Load().(L1Mode)
+}
+
+// SetMode switches the L1 mode.
+func (c *ModeSwitchClient) SetMode(mode L1Mode) error {
+	if _, err := ParseL1Mode(string(mode)); err != nil {
+		return err
+	}
+	if prev, _ := c.mode.Load().(L1Mode); prev != mode {
+		log.Info("Switched L1 mode", "mode", mode, "previous", prev)
+	}
+	c.mode.Store(mode)
+	return nil
+}
+
+func (c *ModeSwitchClient) client() EthClient {
+	if c.Mode() == L1ModeVerified {
+		return c.verified
+	}
+	return c.trusted
+}
+
+func (c *ModeSwitchClient) BlockNumber(ctx context.Context) (uint64, error) {
+	return c.client().BlockNumber(ctx)
+}
+
+func (c *ModeSwitchClient) ChainID(ctx context.Context) (*big.Int, error) {
+	return c.client().ChainID(ctx)
+}
+
+func (c *ModeSwitchClient) FilterLogs(ctx context.Context, q ethereum.FilterQuery) ([]types.Log, error) {
+	return c.client().FilterLogs(ctx, q)
+}
+
+func (c *ModeSwitchClient) HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error) {
+	return c.client().HeaderByNumber(ctx, number)
+}
+
+func (c *ModeSwitchClient) SubscribeFilterLogs(ctx context.Context, query ethereum.FilterQuery, ch chan<- types.Log) (ethereum.Subscription, error) {
+	return c.client().SubscribeFilterLogs(ctx, query, ch)
+}
+
+func (c *ModeSwitchClient) TransactionByHash(ctx context.Context, txHash common.Hash) (*types.Transaction, bool, error) {
+	return c.client().TransactionByHash(ctx, txHash)
+}
+
+func (c *ModeSwitchClient) BlockByHash(ctx context.Context, hash common.Hash) (*types.Block, error) {
+	return c.client().BlockByHash(ctx, hash)
+}
+
+func (c *ModeSwitchClient) CallContract(ctx context.Context, call ethereum.CallMsg, blockNumber *big.Int) ([]byte, error) {
+	return c.client().CallContract(ctx, call, blockNumber)
+}
@@ -0,0 +1,46 @@
+package sync_service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestModeSwitchClient(t *testing.T) {
+	trusted, verified := &flakyEthClient{number: 100}, &flakyEthClient{number: 90}
+	client, err := NewModeSwitchClient(trusted, verified, L1ModeTrusted)
+	require.NoError(t, err)
+	assert.Equal(t, L1ModeTrusted, client.Mode())
+
+	number, err := client.BlockNumber(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, uint64(100), number)
+
+	require.NoError(t, client.SetMode(L1ModeVerified))
+	assert.Equal(t, L1ModeVerified, client.Mode())
+	number, err = client.BlockNumber(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, uint64(90), number)
+	assert.Equal(t, 1, trusted.requests)
+	assert.Equal(t, 1, verified.requests)
+
+	assert.Error(t, client.SetMode("light"))
+	assert.Equal(t, L1ModeVerified, client.Mode())
+
+	_, err = NewModeSwitchClient(trusted, nil, L1ModeTrusted)
+	assert.Error(t, err)
+	_, err = NewModeSwitchClient(trusted, verified, "")
+	assert.Error(t, err)
+}
+
+func TestParseL1Mode(t *testing.T) {
+	for _, s := range []string{"trusted", "verified"} {
+		mode, err := ParseL1Mode(s)
+		require.NoError(t, err)
+		assert.Equal(t, L1Mode(s), mode)
+	}
+	_, err := ParseL1Mode("Trusted")
+	assert.Error(t, err)
+}
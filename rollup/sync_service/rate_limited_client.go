@@ -0,0 +1,94 @@
+package sync_service
+
+import (
+	"context"
+	"errors"
+	"math"
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+
+	"github.com/scroll-tech/go-ethereum"
+	"github.com/scroll-tech/go-ethereum/core/types"
+	"github.com/scroll-tech/go-ethereum/metrics"
+)
+
+var (
+	getLogsThrottledCounter = metrics.NewRegisteredCounter("rollup/l1/getlogs/throttled", nil)
+	getLogsRejectedCounter  = metrics.NewRegisteredCounter("rollup/l1/getlogs/rejected", nil)
+	getLogsBudgetUsedGauge  = metrics.NewRegisteredGauge("rollup/l1/getlogs/budget/used", nil)
+)
+
+// ErrGetLogsBudgetExhausted is returned by RateLimitedClient.FilterLogs once the daily
+// budget of eth_getLogs queries is used up. The budget is reset at midnight UTC.
+var ErrGetLogsBudgetExhausted = errors.New("daily budget of L1 eth_getLogs queries exhausted")
+
+// RateLimitedClient is an EthClient that limits the eth_getLogs queries sent to L1, which
+// public providers throttle the most. Queries wait for the rate limiter and fail once the
+// daily budget is exhausted, all other requests are passed through. A single client is
+// shared between the L1 message sync and the rollup sync service, so that the limits
+// apply to the queries of both.
+type RateLimitedClient struct {
+	EthClient
+
+	limiter     *rate.Limiter // nil if the request rate is not limited
+	dailyBudget uint64        // 0 if the number of requests per day is not limited
+
+	day  int64  // days since the epoch of the current budget period
+	used uint64 // queries sent in the current budget period
+	lock sync.Mutex
+
+	now func() time.Time // overridden in tests
+}
+
+// NewRateLimitedClient wraps client, limiting its eth_getLogs queries to requestsPerSecond
+// and to dailyBudget queries per day. Zero disables the respective limit.
+func NewRateLimitedClient(client EthClient, requestsPerSecond float64, dailyBudget uint64) *RateLimitedClient {
+	c := &RateLimitedClient{
+		EthClient:   client,
+		dailyBudget: dailyBudget,
+		now:         time.Now,
+	}
+	if requestsPerSecond > 0 {
+		c.limiter = rate.NewLimiter(rate.Limit(requestsPerSecond), int(math.Max(1, math.Ceil(requestsPerSecond))))
+	}
+	return c
+}
+
+// FilterLogs waits for the rate limiter and sends the query if the daily budget allows it.
+func (c *RateLimitedClient) FilterLogs(ctx context.Context, q ethereum.FilterQuery) ([]types.Log, error) {
+	if c.limiter != nil {
+		if !c.limiter.Allow() {
+			getLogsThrottledCounter.Inc(1)
+			if err := c.limiter.Wait(ctx); err != nil {
+				return nil, err
+			}
+		}
+	}
+	// charge the budget only once the query is actually sent, a canceled wait is free
+	if err := c.spend(); err != nil {
+		return nil, err
+	}
+	return c.EthClient.FilterLogs(ctx, q)
+}
+
+// spend accounts one query against the daily budget.
+func (c *RateLimitedClient) spend() error {
+	if c.dailyBudget == 0 {
+		return nil
+	}
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if day := c.now().UTC().Unix() / 86400; day != c.day {
+		c.day, c.used = day, 0
+	}
+	if c.used >= c.dailyBudget {
+		getLogsRejectedCounter.Inc(1)
+		return ErrGetLogsBudgetExhausted
+	}
+	c.used++
+	getLogsBudgetUsedGauge.Update(int64(c.used))
+	return nil
+}
@@ -0,0 +1,67 @@
+package sync_service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/scroll-tech/go-ethereum"
+)
+
+func TestRateLimitedClientDailyBudget(t *testing.T) {
+	now := time.Date(2024, 1, 1, 23, 59, 0, 0, time.UTC)
+	client := NewRateLimitedClient(&flakyEthClient{}, 0, 2)
+	client.now = func() time.Time { return now }
+
+	for i := 0; i < 2; i++ {
+		_, err := client.FilterLogs(context.Background(), ethereum.FilterQuery{})
+		require.NoError(t, err)
+	}
+	_, err := client.FilterLogs(context.Background(), ethereum.FilterQuery{})
+	assert.ErrorIs(t, err, ErrGetLogsBudgetExhausted)
+
+	// other requests are not limited
+	_, err = client.BlockNumber(context.Background())
+	assert.NoError(t, err)
+
+	// the budget is reset at midnight UTC
+	now = now.Add(time.Minute)
+	_, err = client.FilterLogs(context.Background(), ethereum.FilterQuery{})
+	assert.NoError(t, err)
+}
+
+func TestRateLimitedClientRate(t *testing.T) {
+	client := NewRateLimitedClient(&flakyEthClient{}, 20, 0)
+
+	// the burst is the rate per second, further queries wait
+	start := time.Now()
+	for i := 0; i < 22; i++ {
+		_, err := client.FilterLogs(context.Background(), ethereum.FilterQuery{})
+		require.NoError(t, err)
+	}
+	assert.GreaterOrEqual(t, time.Since(start), 80*time.Millisecond)
+
+	// waiting queries are canceled with the context
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err := client.FilterLogs(ctx, ethereum.FilterQuery{})
+	assert.Error(t, err)
+}
+
+func TestRateLimitedClientCanceledWaitIsFree(t *testing.T) {
+	client := NewRateLimitedClient(&flakyEthClient{}, 1, 2)
+
+	// use up the burst, so that the next query has to wait
+	_, err := client.FilterLogs(context.Background(), ethereum.FilterQuery{})
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = client.FilterLogs(ctx, ethereum.FilterQuery{})
+	assert.Error(t, err)
+	assert.NotErrorIs(t, err, ErrGetLogsBudgetExhausted)
+	assert.Equal(t, uint64(1), client.used)
+}
@@ -0,0 +1,155 @@
+package sync_service
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+
+	"github.com/scroll-tech/go-ethereum"
+	"github.com/scroll-tech/go-ethereum/common"
+	"github.com/scroll-tech/go-ethereum/core/types"
+	"github.com/scroll-tech/go-ethereum/metrics"
+	"github.com/scroll-tech/go-ethereum/rpc"
+)
+
+var (
+	receiptsQueryCounter   = metrics.NewRegisteredCounter("rollup/l1/receipts/queries", nil)
+	receiptsFetchedCounter = metrics.NewRegisteredCounter("rollup/l1/receipts/fetched", nil)
+)
+
+// ReceiptsClient is an EthClient that also serves the receipts of a block,
+// ethclient.Client implements it.
+type ReceiptsClient interface {
+	EthClient
+	BlockReceipts(ctx context.Context, blockNrOrHash rpc.BlockNumberOrHash) ([]*types.Receipt, error)
+}
+
+// ReceiptsLogClient is an EthClient for L1 endpoints that price eth_getLogs heavily but
+// serve block receipts cheaply. The logs of the given contracts are collected from the
+// receipts of the blocks whose bloom filter includes the contracts, fetched with a single
+// eth_getBlockReceipts request per block. The receipts carry the logs of all calls, so
+// the events emitted in calls from other contracts, e.g. a multisig, are included.
+// Queries of other contracts, e.g. the L1 message queue whose events are emitted in most
+// blocks, and queries of block tags are sent as eth_getLogs.
+type ReceiptsLogClient struct {
+	ReceiptsClient
+
+	contracts map[common.Address]bool
+}
+
+// NewReceiptsLogClient wraps client, collecting the logs of contracts from block receipts.
+func NewReceiptsLogClient(client ReceiptsClient, contracts []common.Address) *ReceiptsLogClient {
+	c := &ReceiptsLogClient{
+		ReceiptsClient: client,
+		contracts:      make(map[common.Address]bool),
+	}
+	for _, contract := range contracts {
+		c.contracts[contract] = true
+	}
+	return c
+}
+
+// FilterLogs collects the logs from receipts if the query covers a range of block numbers
+// or a block hash and only the contracts of the client.
+func (c *ReceiptsLogClient) FilterLogs(ctx context.Context, q ethereum.FilterQuery) ([]types.Log, error) {
+	if !c.covers(q) {
+		return c.ReceiptsClient.FilterLogs(ctx, q)
+	}
+	receiptsQueryCounter.Inc(1)
+
+	if q.BlockHash != nil {
+		return c.blockLogs(ctx, *q.BlockHash, q)
+	}
+	var logs []types.Log
+	for number := q.FromBlock.Uint64(); number <= q.ToBlock.Uint64(); number++ {
+		header, err := c.HeaderByNumber(ctx, new(big.Int).SetUint64(number))
+		if err != nil {
+			return nil, fmt.Errorf("failed to get header %d: %w", number, err)
+		}
+		if !c.bloomMatches(header.Bloom, q.Addresses) {
+			continue
+		}
+		// the receipts are requested by hash, so that they match the header despite reorgs
+		blockLogs, err := c.blockLogs(ctx, header.Hash(), q)
+		if err != nil {
+			return nil, err
+		}
+		logs = append(logs, blockLogs...)
+	}
+	return logs, nil
+}
+
+// covers reports whether the logs of the query can be collected from receipts.
+func (c *ReceiptsLogClient) covers(q ethereum.FilterQuery) bool {
+	if len(q.Addresses) == 0 {
+		return false
+	}
+	for _, address := range q.Addresses {
+		if !c.contracts[address] {
+			return false
+		}
+	}
+	if q.BlockHash != nil {
+		return true
+	}
+	// block tags are resolved by eth_getLogs
+	return q.FromBlock != nil && q.FromBlock.Sign() >= 0 && q.ToBlock != nil && q.ToBlock.Sign() >= 0
+}
+
+func (c *ReceiptsLogClient) bloomMatches(bloom types.Bloom, addresses []common.Address) bool {
+	for _, address := range addresses {
+		if types.BloomLookup(bloom, address) {
+			return true
+		}
+	}
+	return false
+}
+
+// blockLogs returns the logs of the block with the given hash matching the query.
+func (c *ReceiptsLogClient) blockLogs(ctx context.Context, hash common.Hash, q ethereum.FilterQuery) ([]types.Log, error) {
+	receipts, err := c.BlockReceipts(ctx, rpc.BlockNumberOrHashWithHash(hash, false))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get receipts of block %v: %w", hash.Hex(), err)
+	}
+	receiptsFetchedCounter.Inc(int64(len(receipts)))
+	var logs []types.Log
+	for _, receipt := range receipts {
+		if receipt.BlockHash != hash {
+			return nil, fmt.Errorf("receipt of transaction %v belongs to block %v, expected block %v", receipt.TxHash.Hex(), receipt.BlockHash.Hex(), hash.Hex())
+		}
+		for _, log := range receipt.Logs {
+			if !logMatches(log, q) {
+				continue
+			}
+			logs = append(logs, *log)
+		}
+	}
+	return logs, nil
+}
+
+// logMatches reports whether the log matches the addresses and topics of the query.
+func logMatches(log *types.Log, q ethereum.FilterQuery) bool {
+	var included bool
+	for _, address := range q.Addresses {
+		if log.Address == address {
+			included = true
+			break
+		}
+	}
+	if !included || len(q.Topics) > len(log.Topics) {
+		return false
+	}
+	for i, sub := range q.Topics {
+		match := len(sub) == 0 // empty rule set == wildcard
+		for _, topic := range sub {
+			if log.Topics[i] == topic {
+				match = true
+				break
+			}
+		}
+		if !match {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,112 @@
+package sync_service
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/scroll-tech/go-ethereum"
+	"github.com/scroll-tech/go-ethereum/common"
+	"github.com/scroll-tech/go-ethereum/core/types"
+	"github.com/scroll-tech/go-ethereum/rpc"
+)
+
+// receiptsEthClient serves headers and block receipts and counts the requests.
+type receiptsEthClient struct {
+	flakyEthClient
+	headers  []*types.Header
+	receipts map[common.Hash][]*types.Receipt
+
+	getLogs, receiptRequests int
+}
+
+func (c *receiptsEthClient) FilterLogs(ctx context.Context, q ethereum.FilterQuery) ([]types.Log, error) {
+	c.getLogs++
+	return nil, nil
+}
+
+func (c *receiptsEthClient) HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error) {
+	return c.headers[number.Uint64()], nil
+}
+
+func (c *receiptsEthClient) BlockReceipts(ctx context.Context, blockNrOrHash rpc.BlockNumberOrHash) ([]*types.Receipt, error) {
+	c.receiptRequests++
+	hash, ok := blockNrOrHash.Hash()
+	if !ok {
+		return nil, errors.New("receipts requested by number")
+	}
+	receipts, ok := c.receipts[hash]
+	if !ok {
+		return nil, ethereum.NotFound
+	}
+	return receipts, nil
+}
+
+func TestReceiptsLogClient(t *testing.T) {
+	var (
+		scrollChain = common.Address{0x5c}
+		other       = common.Address{0x01}
+		commitTopic = common.Hash{0xc0}
+		otherTopic  = common.Hash{0x01}
+		multisig    = common.Address{0x02}
+	)
+
+	// block 1 calls the contract directly, block 2 through a multisig,
+	// block 3 emits no events of the contract
+	client := &receiptsEthClient{receipts: make(map[common.Hash][]*types.Receipt)}
+	newBlock := func(number uint64, logs ...*types.Log) *types.Header {
+		receipt := &types.Receipt{Status: types.ReceiptStatusSuccessful, Logs: logs, TxHash: common.Hash{byte(number)}}
+		receipt.Bloom = types.CreateBloom(types.Receipts{receipt})
+		header := &types.Header{Number: new(big.Int).SetUint64(number), Bloom: receipt.Bloom}
+		receipt.BlockHash = header.Hash()
+		for _, log := range logs {
+			log.BlockNumber, log.BlockHash, log.TxHash = number, header.Hash(), receipt.TxHash
+		}
+		client.receipts[header.Hash()] = []*types.Receipt{receipt}
+		return header
+	}
+	client.headers = []*types.Header{
+		newBlock(0),
+		newBlock(1, &types.Log{Address: scrollChain, Topics: []common.Hash{commitTopic}}, &types.Log{Address: scrollChain, Topics: []common.Hash{otherTopic}}),
+		newBlock(2, &types.Log{Address: multisig}, &types.Log{Address: scrollChain, Topics: []common.Hash{commitTopic}}),
+		newBlock(3, &types.Log{Address: other, Topics: []common.Hash{commitTopic}}),
+	}
+
+	query := ethereum.FilterQuery{FromBlock: big.NewInt(0), ToBlock: big.NewInt(3), Addresses: []common.Address{scrollChain}, Topics: [][]common.Hash{{commitTopic}}}
+	c := NewReceiptsLogClient(client, []common.Address{scrollChain})
+	logs, err := c.FilterLogs(context.Background(), query)
+	require.NoError(t, err)
+	require.Len(t, logs, 2)
+	assert.Equal(t, uint64(1), logs[0].BlockNumber)
+	assert.Equal(t, uint64(2), logs[1].BlockNumber)
+	assert.Zero(t, client.getLogs)
+	assert.Equal(t, 2, client.receiptRequests, "blocks without events of the contract are skipped")
+
+	hash := client.headers[2].Hash()
+	logs, err = c.FilterLogs(context.Background(), ethereum.FilterQuery{BlockHash: &hash, Addresses: []common.Address{scrollChain}})
+	require.NoError(t, err)
+	require.Len(t, logs, 1)
+	assert.Equal(t, uint64(2), logs[0].BlockNumber)
+
+	// receipts of another block, e.g. after a reorg, are refused
+	client.receipts[hash] = client.receipts[client.headers[1].Hash()]
+	_, err = c.FilterLogs(context.Background(), ethereum.FilterQuery{BlockHash: &hash, Addresses: []common.Address{scrollChain}})
+	assert.ErrorContains(t, err, "belongs to block")
+
+	// queries of other contracts and of block tags are sent as eth_getLogs
+	for _, q := range []ethereum.FilterQuery{
+		{FromBlock: big.NewInt(0), ToBlock: big.NewInt(3), Addresses: []common.Address{other}},
+		{FromBlock: big.NewInt(0), ToBlock: big.NewInt(3), Addresses: []common.Address{scrollChain, other}},
+		{FromBlock: big.NewInt(0), ToBlock: big.NewInt(-3), Addresses: []common.Address{scrollChain}},
+		{FromBlock: big.NewInt(0), Addresses: []common.Address{scrollChain}},
+		{FromBlock: big.NewInt(0), ToBlock: big.NewInt(3)},
+	} {
+		_, err = c.FilterLogs(context.Background(), q)
+		require.NoError(t, err)
+	}
+	assert.Equal(t, 5, client.getLogs)
+}
@@ -0,0 +1,121 @@
+package sync_service
+
+import (
+	"context"
+	"math/big"
+	"time"
+
+	"github.com/scroll-tech/go-ethereum"
+	"github.com/scroll-tech/go-ethereum/common"
+	"github.com/scroll-tech/go-ethereum/core/types"
+	"github.com/scroll-tech/go-ethereum/log"
+	"github.com/scroll-tech/go-ethereum/metrics"
+)
+
+var slowQueryCounter = metrics.NewRegisteredCounter("rollup/l1/rpc/slow", nil)
+
+// SlowQueryClient is an EthClient that logs and counts the requests to a single L1 endpoint
+// that exceed a latency threshold, along with the method, a summary of the parameters and
+// the duration, so that a degrading provider or method can be identified from the logs.
+// Subscriptions are passed through.
+type SlowQueryClient struct {
+	EthClient
+
+	endpoint  string
+	threshold time.Duration
+
+	now func() time.Time // overridden in tests
+}
+
+// NewSlowQueryClient wraps the client of the given endpoint, reporting the requests that
+// take longer than threshold. The endpoint is only used to identify the client in logs.
+func NewSlowQueryClient(client EthClient, endpoint string, threshold time.Duration) *SlowQueryClient {
+	return &SlowQueryClient{
+		EthClient: client,
+		endpoint:  endpoint,
+		threshold: threshold,
+		now:       time.Now,
+	}
+}
+
+// SlowQueries returns the number of slow L1 requests reported by all clients.
+func SlowQueries() int64 {
+	return slowQueryCounter.Count()
+}
+
+// observe reports the request of method started at start if it exceeded the threshold,
+// and returns whether it was reported. The params are logged as key-value pairs.
+func (c *SlowQueryClient) observe(method string, start time.Time, err error, params ...interface{}) bool {
+	elapsed := c.now().Sub(start)
+	if elapsed < c.threshold {
+		return false
+	}
+	slowQueryCounter.Inc(1)
+	metrics.GetOrRegisterCounter("rollup/l1/rpc/slow/"+method, nil).Inc(1)
+
+	ctx := []interface{}{"method", method, "endpoint", c.endpoint, "duration", common.PrettyDuration(elapsed)}
+	ctx = append(ctx, params...)
+	if err != nil {
+		ctx = append(ctx, "err", err)
+	}
+	log.Warn("Slow L1 RPC call", ctx...)
+	return true
+}
+
+func (c *SlowQueryClient) BlockNumber(ctx context.Context) (uint64, error) {
+	start := c.now()
+	number, err := c.EthClient.BlockNumber(ctx)
+	c.observe("eth_blockNumber", start, err)
+	return number, err
+}
+
+func (c *SlowQueryClient) ChainID(ctx context.Context) (*big.Int, error) {
+	start := c.now()
+	chainID, err := c.EthClient.ChainID(ctx)
+	c.observe("eth_chainId", start, err)
+	return chainID, err
+}
+
+func (c *SlowQueryClient) FilterLogs(ctx context.Context, q ethereum.FilterQuery) ([]types.Log, error) {
+	start := c.now()
+	logs, err := c.EthClient.FilterLogs(ctx, q)
+	params := []interface{}{"from", q.FromBlock, "to", q.ToBlock}
+	if q.BlockHash != nil {
+		params = []interface{}{"blockHash", q.BlockHash.Hex()}
+	}
+	params = append(params, "addresses", len(q.Addresses), "topics", len(q.Topics), "logs", len(logs))
+	c.observe("eth_getLogs", start, err, params...)
+	return logs, err
+}
+
+func (c *SlowQueryClient) HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error) {
+	start := c.now()
+	header, err := c.EthClient.HeaderByNumber(ctx, number)
+	c.observe("eth_getBlockByNumber", start, err, "number", number)
+	return header, err
+}
+
+func (c *SlowQueryClient) TransactionByHash(ctx context.Context, txHash common.Hash) (*types.Transaction, bool, error) {
+	start := c.now()
+	tx, isPending, err := c.EthClient.TransactionByHash(ctx, txHash)
+	c.observe("eth_getTransactionByHash", start, err, "hash", txHash.Hex())
+	return tx, isPending, err
+}
+
+func (c *SlowQueryClient) BlockByHash(ctx context.Context, hash common.Hash) (*types.Block, error) {
+	start := c.now()
+	block, err := c.EthClient.BlockByHash(ctx, hash)
+	c.observe("eth_getBlockByHash", start, err, "hash", hash.Hex())
+	return block, err
+}
+
+func (c *SlowQueryClient) CallContract(ctx context.Context, call ethereum.CallMsg, blockNumber *big.Int) ([]byte, error) {
+	start := c.now()
+	result, err := c.EthClient.CallContract(ctx, call, blockNumber)
+	var to string
+	if call.To != nil {
+		to = call.To.Hex()
+	}
+	c.observe("eth_call", start, err, "to", to, "data", len(call.Data), "number", blockNumber)
+	return result, err
+}
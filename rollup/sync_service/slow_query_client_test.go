@@ -0,0 +1,43 @@
+package sync_service
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/scroll-tech/go-ethereum"
+)
+
+func TestSlowQueryClient(t *testing.T) {
+	client := NewSlowQueryClient(&flakyEthClient{number: 7}, "http://l1.example", time.Second)
+
+	now := time.Unix(1000, 0)
+	client.now = func() time.Time { return now }
+
+	assert.False(t, client.observe("eth_blockNumber", now.Add(-999*time.Millisecond), nil))
+	assert.True(t, client.observe("eth_blockNumber", now.Add(-time.Second), nil))
+	assert.True(t, client.observe("eth_getLogs", now.Add(-time.Minute), errors.New("timeout"), "from", big.NewInt(1), "to", nil))
+
+	// the requests are passed through, also if they are slow
+	var calls int
+	client.now = func() time.Time {
+		calls++
+		return now.Add(time.Duration(calls) * time.Second)
+	}
+	number, err := client.BlockNumber(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, uint64(7), number)
+
+	_, err = client.FilterLogs(context.Background(), ethereum.FilterQuery{FromBlock: big.NewInt(1), ToBlock: big.NewInt(100)})
+	assert.NoError(t, err)
+	_, err = client.CallContract(context.Background(), ethereum.CallMsg{}, nil)
+	assert.NoError(t, err)
+	_, _, err = client.TransactionByHash(context.Background(), [32]byte{1})
+	assert.ErrorIs(t, err, ethereum.NotFound)
+	assert.Equal(t, 8, calls)
+}
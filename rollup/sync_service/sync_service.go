@@ -4,15 +4,21 @@ import (
 	"context"
 	"fmt"
 	"reflect"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/scroll-tech/go-ethereum/core"
 	"github.com/scroll-tech/go-ethereum/core/rawdb"
+	"github.com/scroll-tech/go-ethereum/core/types"
 	"github.com/scroll-tech/go-ethereum/ethdb"
 	"github.com/scroll-tech/go-ethereum/event"
 	"github.com/scroll-tech/go-ethereum/log"
+	"github.com/scroll-tech/go-ethereum/metrics"
 	"github.com/scroll-tech/go-ethereum/node"
 	"github.com/scroll-tech/go-ethereum/params"
+
+	"github.com/scroll-tech/go-ethereum/rollup/eventbus"
 )
 
 const (
@@ -35,6 +41,8 @@ const (
 	DbWriteThresholdBlocks = 1000
 )
 
+var l1MessageMismatchCounter = metrics.NewRegisteredCounter("rollup/l1/messages/mismatch", nil)
+
 // SyncService collects all L1 messages and stores them in a local database.
 type SyncService struct {
 	ctx                  context.Context
@@ -43,11 +51,15 @@ type SyncService struct {
 	db                   ethdb.Database
 	msgCountFeed         event.Feed
 	pollInterval         time.Duration
-	latestProcessedBlock uint64
+	latestProcessedBlock uint64     // written atomically by fetch rounds
+	fetchLock            sync.Mutex // serializes fetch rounds
+	resetLock            sync.Mutex // protects pendingReset
+	pendingReset         *uint64    // L1 block to rewind the sync progress to, applied by the next fetch round
 	scope                event.SubscriptionScope
+	bus                  *eventbus.Bus
 }
 
-func NewSyncService(ctx context.Context, genesisConfig *params.ChainConfig, nodeConfig *node.Config, db ethdb.Database, l1Client EthClient) (*SyncService, error) {
+func NewSyncService(ctx context.Context, genesisConfig *params.ChainConfig, nodeConfig *node.Config, db ethdb.Database, l1Client EthClient, bus *eventbus.Bus) (*SyncService, error) {
 	// terminate if the caller does not provide an L1 client (e.g. in tests)
 	if l1Client == nil || (reflect.ValueOf(l1Client).Kind() == reflect.Ptr && reflect.ValueOf(l1Client).IsNil()) {
 		log.Warn("No L1 client provided, L1 sync service will not run")
@@ -58,7 +70,8 @@ func NewSyncService(ctx context.Context, genesisConfig *params.ChainConfig, node
 		return nil, fmt.Errorf("missing L1 config in genesis")
 	}
 
-	client, err := newBridgeClient(ctx, l1Client, genesisConfig.Scroll.L1Config.L1ChainId, nodeConfig.L1Confirmations, genesisConfig.Scroll.L1Config.L1MessageQueueAddress)
+	l1Config := genesisConfig.Scroll.L1Config
+	client, err := newBridgeClient(ctx, l1Client, l1Config.L1ChainId, nodeConfig.L1Confirmations, l1Config.L1MessageQueueAddress, l1Config.L1MessageQueueV2Address, l1Config.L1MessageQueueV2MigrationBlock)
 	if err != nil {
 		return nil, fmt.Errorf("failed to initialize bridge client: %w", err)
 	}
@@ -71,6 +84,10 @@ func NewSyncService(ctx context.Context, genesisConfig *params.ChainConfig, node
 		latestProcessedBlock = *block
 	}
 
+	if bus == nil {
+		bus = eventbus.New()
+	}
+
 	ctx, cancel := context.WithCancel(ctx)
 
 	service := SyncService{
@@ -80,6 +97,7 @@ func NewSyncService(ctx context.Context, genesisConfig *params.ChainConfig, node
 		db:                   db,
 		pollInterval:         DefaultPollInterval,
 		latestProcessedBlock: latestProcessedBlock,
+		bus:                  bus,
 	}
 
 	return &service, nil
@@ -140,14 +158,79 @@ func (s *SyncService) SubscribeNewL1MsgsEvent(ch chan<- core.NewL1MsgsEvent) eve
 	return s.scope.Track(s.msgCountFeed.Subscribe(ch))
 }
 
+// ResetTo rewinds the sync progress to the given L1 block, so that the L1 messages
+// after it are fetched again, e.g. to verify them against another L1 source. Messages
+// that are already stored are kept, fetched messages that differ are reported. The
+// reset does not wait for a fetch round in progress, the round stops at its next range
+// and the messages are fetched again from the given block right away.
+func (s *SyncService) ResetTo(l1BlockNumber uint64) error {
+	if latest := s.LatestProcessedBlock(); l1BlockNumber > latest {
+		return fmt.Errorf("cannot reset L1 message sync forward, latest processed block: %v, requested: %v", latest, l1BlockNumber)
+	}
+	s.resetLock.Lock()
+	defer s.resetLock.Unlock()
+
+	if s.pendingReset == nil || l1BlockNumber < *s.pendingReset {
+		s.pendingReset = &l1BlockNumber
+	}
+	return nil
+}
+
+// LatestProcessedBlock returns the last L1 block whose messages have been collected.
+func (s *SyncService) LatestProcessedBlock() uint64 {
+	return atomic.LoadUint64(&s.latestProcessedBlock)
+}
+
+// resetPending reports whether a reset of the sync progress was requested.
+func (s *SyncService) resetPending() bool {
+	s.resetLock.Lock()
+	defer s.resetLock.Unlock()
+
+	return s.pendingReset != nil
+}
+
+// applyPendingReset rewinds the sync progress if a reset was requested. It must be
+// called with s.fetchLock held.
+func (s *SyncService) applyPendingReset() {
+	s.resetLock.Lock()
+	defer s.resetLock.Unlock()
+
+	if s.pendingReset == nil {
+		return
+	}
+	l1BlockNumber := *s.pendingReset
+	s.pendingReset = nil
+	if l1BlockNumber >= s.latestProcessedBlock {
+		return
+	}
+	rawdb.WriteSyncedL1BlockNumber(s.db, l1BlockNumber)
+
+	log.Warn("Reset L1 message sync", "from", s.latestProcessedBlock, "to", l1BlockNumber)
+	atomic.StoreUint64(&s.latestProcessedBlock, l1BlockNumber)
+}
+
 func (s *SyncService) fetchMessages() {
+	s.fetchLock.Lock()
+	defer s.fetchLock.Unlock()
+
+	// note: a round stopped by a reset is started again from the reset block
+	for s.fetchMessagesRound() {
+	}
+}
+
+// fetchMessagesRound collects the L1 messages up to the latest confirmed L1 block. It
+// reports whether it stopped early because a reset of the sync progress was requested.
+func (s *SyncService) fetchMessagesRound() bool {
+	s.applyPendingReset()
+
 	latestConfirmed, err := s.client.getLatestConfirmedBlockNumber(s.ctx)
 	if err != nil {
 		log.Warn("Failed to get latest confirmed block number", "err", err)
-		return
+		return false
 	}
 
 	log.Trace("Sync service fetchMessages", "latestProcessedBlock", s.latestProcessedBlock, "latestConfirmed", latestConfirmed)
+	s.bus.PublishL1Head(eventbus.L1HeadEvent{Number: latestConfirmed})
 
 	batchWriter := s.db.NewBatch()
 	numBlocksPendingDbWrite := uint64(0)
@@ -173,7 +256,7 @@ func (s *SyncService) fetchMessages() {
 			numMessagesPendingDbWrite = 0
 		}
 
-		s.latestProcessedBlock = lastBlock
+		atomic.StoreUint64(&s.latestProcessedBlock, lastBlock)
 	}
 
 	// ticker for logging progress
@@ -188,12 +271,18 @@ func (s *SyncService) fetchMessages() {
 			if from > 0 {
 				flush(from - 1)
 			}
-			return
+			return false
 		case <-t.C:
 			progress := 100 * float64(s.latestProcessedBlock) / float64(latestConfirmed)
 			log.Info("Syncing L1 messages", "processed", s.latestProcessedBlock, "confirmed", latestConfirmed, "collected", numMsgsCollected, "progress(%)", progress)
 		default:
 		}
+		if s.resetPending() {
+			if from > 0 {
+				flush(from - 1)
+			}
+			return true
+		}
 
 		to := from + DefaultFetchBlockRange - 1
 		if to > latestConfirmed {
@@ -207,21 +296,39 @@ func (s *SyncService) fetchMessages() {
 				flush(from - 1)
 			}
 			log.Warn("Failed to fetch L1 messages in range", "fromBlock", from, "toBlock", to, "err", err)
-			return
+			return false
 		}
 
+		numNewMsgs := 0
 		if len(msgs) > 0 {
 			log.Debug("Received new L1 events", "fromBlock", from, "toBlock", to, "count", len(msgs))
-			rawdb.WriteL1Messages(batchWriter, msgs) // collect messages in memory
-			numMsgsCollected += len(msgs)
+			// collect messages in memory
+			for _, msg := range msgs {
+				if stored := rawdb.ReadL1Message(s.db, msg.tx.QueueIndex); stored != nil {
+					// the message is fetched again after a reset, it may be included in L2 blocks already
+					if storedHash, fetchedHash := types.NewTx(stored).Hash(), types.NewTx(&msg.tx).Hash(); storedHash != fetchedHash {
+						l1MessageMismatchCounter.Inc(1)
+						log.Error("Fetched L1 message differs from the stored message", "queueIndex", msg.tx.QueueIndex, "stored", storedHash, "fetched", fetchedHash)
+					}
+					continue
+				}
+				rawdb.WriteL1Message(batchWriter, msg.tx)
+				rawdb.WriteL1MessageOrigin(batchWriter, msg.tx.QueueIndex, &msg.origin)
+				if msg.fee != nil {
+					rawdb.WriteL1MessageFee(batchWriter, msg.tx.QueueIndex, msg.fee)
+				}
+				numNewMsgs++
+			}
+			numMsgsCollected += numNewMsgs
 		}
 
 		numBlocksPendingDbWrite += to - from
-		numMessagesPendingDbWrite += len(msgs)
+		numMessagesPendingDbWrite += numNewMsgs
 
 		// flush new messages to database periodically
 		if to == latestConfirmed || batchWriter.ValueSize() >= DbWriteThresholdBytes || numBlocksPendingDbWrite >= DbWriteThresholdBlocks {
 			flush(to)
 		}
 	}
+	return false
 }
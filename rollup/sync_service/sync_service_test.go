@@ -0,0 +1,46 @@
+package sync_service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/scroll-tech/go-ethereum/common"
+	"github.com/scroll-tech/go-ethereum/core/rawdb"
+	"github.com/scroll-tech/go-ethereum/core/types"
+	"github.com/scroll-tech/go-ethereum/rollup/eventbus"
+	"github.com/scroll-tech/go-ethereum/rpc"
+)
+
+func TestSyncServiceResetTo(t *testing.T) {
+	address := common.Address{0x10}
+	client := &queueEthClient{flakyEthClient: flakyEthClient{number: 20}, logs: []types.Log{
+		queueTransactionLog(t, L1MessageQueueABI, address, 5, 0),
+		queueTransactionLog(t, L1MessageQueueABI, address, 9, 1),
+	}}
+	bridgeClient, err := newBridgeClient(context.Background(), client, 1, rpc.LatestBlockNumber, address, common.Address{}, 0)
+	require.NoError(t, err)
+	db := rawdb.NewMemoryDatabase()
+	service := &SyncService{ctx: context.Background(), client: bridgeClient, db: db, bus: eventbus.New()}
+
+	service.fetchMessages()
+	assert.Equal(t, uint64(20), service.LatestProcessedBlock())
+	assert.NotNil(t, rawdb.ReadL1Message(db, 1))
+
+	// a message that was missed in an L1 block that was already processed
+	client.logs = append(client.logs, queueTransactionLog(t, L1MessageQueueABI, address, 8, 2))
+	assert.Error(t, service.ResetTo(21))
+
+	// the reset is applied by the next fetch round, which fetches the message
+	require.NoError(t, service.ResetTo(10))
+	require.NoError(t, service.ResetTo(7))
+	assert.Equal(t, uint64(20), service.LatestProcessedBlock())
+	assert.Nil(t, rawdb.ReadL1Message(db, 2))
+	service.fetchMessages()
+	assert.Equal(t, uint64(20), service.LatestProcessedBlock())
+	assert.Equal(t, uint64(20), *rawdb.ReadSyncedL1BlockNumber(db))
+	assert.NotNil(t, rawdb.ReadL1Message(db, 2))
+	assert.False(t, service.resetPending())
+}
@@ -0,0 +1,222 @@
+package tracing
+
+import (
+	"sync"
+
+	"github.com/scroll-tech/go-ethereum/common"
+	"github.com/scroll-tech/go-ethereum/common/hexutil"
+	"github.com/scroll-tech/go-ethereum/core/state"
+	"github.com/scroll-tech/go-ethereum/core/types"
+	"github.com/scroll-tech/go-ethereum/core/vm"
+	"github.com/scroll-tech/go-ethereum/log"
+)
+
+// txProofKeys are the accounts and storage slots touched by a transaction.
+type txProofKeys struct {
+	accounts map[common.Address]struct{}
+	storages map[common.Address]vm.Storage
+}
+
+// storageProofTask extracts the proofs of the storage slots of an account. The slots of an
+// account are proven by one worker, the deletion proofs are traced on a single trie.
+type storageProofTask struct {
+	addr    common.Address
+	keys    []common.Hash
+	deleted []common.Hash
+
+	proofs map[common.Hash][]hexutil.Bytes
+	tracer state.ZktrieProofTracer
+	failed bool
+}
+
+// fillProofs extracts the proofs of the keys touched by the transactions of a block, which
+// are not known to the trace environment yet, from proofState. The accounts and the storages
+// of the accounts are proven concurrently by a pool of at most threads workers, each on its
+// own copy of proofState. The proofs are then merged into the storage traces of the block
+// and its txs.
+func (env *TraceEnv) fillProofs(proofState *state.StateDB, keys []*txProofKeys, threads int) {
+	// collect the keys without a proof, each once
+	var (
+		accounts     []common.Address
+		accountSeen  = make(map[common.Address]struct{})
+		storageTasks []*storageProofTask
+		taskByAddr   = make(map[common.Address]*storageProofTask)
+		keySeen      = make(map[common.Address]map[common.Hash]bool) // whether the key is deleted
+	)
+	for _, txKeys := range keys {
+		if txKeys == nil {
+			continue
+		}
+		for addr := range txKeys.accounts {
+			if _, existed := env.Proofs[addr.String()]; existed {
+				continue
+			}
+			if _, seen := accountSeen[addr]; !seen {
+				accountSeen[addr] = struct{}{}
+				accounts = append(accounts, addr)
+			}
+		}
+		for addr, slots := range txKeys.storages {
+			seen, ok := keySeen[addr]
+			if !ok {
+				seen = make(map[common.Hash]bool)
+				keySeen[addr] = seen
+			}
+			task, ok := taskByAddr[addr]
+			if !ok {
+				task = &storageProofTask{addr: addr, proofs: make(map[common.Hash][]hexutil.Bytes)}
+				taskByAddr[addr] = task
+				storageTasks = append(storageTasks, task)
+			}
+			for key, value := range slots {
+				isDelete := value == (common.Hash{})
+				if deleted, existed := seen[key]; existed {
+					if isDelete && !deleted {
+						seen[key] = true
+						task.deleted = append(task.deleted, key)
+					}
+					continue
+				}
+				seen[key] = isDelete
+				if isDelete {
+					task.deleted = append(task.deleted, key)
+				}
+				if _, existed := env.StorageProofs[addr.String()][key.String()]; !existed {
+					task.keys = append(task.keys, key)
+				}
+			}
+		}
+	}
+
+	accountProofs := make([][]hexutil.Bytes, len(accounts))
+	runProofWorkers(proofState, threads, len(accounts)+len(storageTasks), func(statedb *state.StateDB, i int) {
+		if i < len(accounts) {
+			proof, err := statedb.GetProof(accounts[i])
+			if err != nil {
+				log.Error("Proof not available", "address", accounts[i], "error", err)
+				// but we still mark the proofs map with nil array
+			}
+			accountProofs[i] = types.WrapProof(proof)
+			return
+		}
+		proveStorages(statedb, storageTasks[i-len(accounts)])
+	})
+
+	// merge the proofs into the trace environment
+	for i, addr := range accounts {
+		env.Proofs[addr.String()] = accountProofs[i]
+	}
+	for _, task := range storageTasks {
+		if task.failed {
+			continue
+		}
+		addrStr := task.addr.String()
+		m, existed := env.StorageProofs[addrStr]
+		if !existed {
+			m = make(map[string][]hexutil.Bytes)
+			env.StorageProofs[addrStr] = m
+		}
+		for key, proof := range task.proofs {
+			m[key.String()] = proof
+		}
+		if task.tracer.Available() {
+			if tracer := env.ZkTrieTracer[addrStr]; tracer.Available() {
+				tracer.Merge(task.tracer)
+			} else {
+				env.ZkTrieTracer[addrStr] = task.tracer
+			}
+			// the tracer holds the paths of the keys proven by earlier calls as well
+			for _, key := range task.deleted {
+				env.ZkTrieTracer[addrStr].MarkDeletion(key)
+			}
+		}
+	}
+
+	// assign the proofs to the storage traces of the txs
+	for index, txKeys := range keys {
+		txStorageTrace := env.TxStorageTraces[index]
+		if txKeys == nil || txStorageTrace == nil {
+			continue
+		}
+		for addr := range txKeys.accounts {
+			txStorageTrace.Proofs[addr.String()] = env.Proofs[addr.String()]
+		}
+		for addr, slots := range txKeys.storages {
+			addrStr := addr.String()
+			txm, existed := txStorageTrace.StorageProofs[addrStr]
+			if !existed {
+				txm = make(map[string][]hexutil.Bytes)
+				txStorageTrace.StorageProofs[addrStr] = txm
+			}
+			m, existed := env.StorageProofs[addrStr]
+			if !existed {
+				continue
+			}
+			for key := range slots {
+				if proof, existed := m[key.String()]; existed {
+					txm[key.String()] = proof
+				}
+			}
+		}
+	}
+}
+
+// proveStorages proves the storage slots of a task through a proof tracer on the storage trie
+// of the account, so that the deletion proofs of the slots can be collected later.
+func proveStorages(statedb *state.StateDB, task *storageProofTask) {
+	trie, err := statedb.GetStorageTrieForProof(task.addr)
+	if err != nil {
+		// but we still continue to next address
+		log.Error("Storage trie not available", "error", err, "address", task.addr)
+		task.failed = true
+		return
+	}
+	task.tracer = statedb.NewProofTracer(trie)
+	for _, key := range task.keys {
+		var proof [][]byte
+		if task.tracer.Available() {
+			proof, err = statedb.GetSecureTrieProof(task.tracer, key)
+		} else {
+			proof, err = statedb.GetSecureTrieProof(trie, key)
+		}
+		if err != nil {
+			log.Error("Storage proof not available", "error", err, "address", task.addr, "key", key)
+			// but we still mark the proofs map with nil array
+		}
+		task.proofs[key] = types.WrapProof(proof)
+	}
+}
+
+// runProofWorkers runs work for the indices [0, n) on a pool of workers, each working on its
+// own copy of proofState, since the tries of a state are not safe for concurrent use.
+func runProofWorkers(proofState *state.StateDB, threads, n int, work func(statedb *state.StateDB, i int)) {
+	if threads > n {
+		threads = n
+	}
+	jobs := make(chan int, n)
+	for i := 0; i < n; i++ {
+		jobs <- i
+	}
+	close(jobs)
+
+	// the copies are made before any worker uses proofState
+	states := make([]*state.StateDB, threads)
+	for th := range states {
+		states[th] = proofState
+		if th > 0 {
+			states[th] = proofState.Copy()
+		}
+	}
+	var pend sync.WaitGroup
+	for _, statedb := range states {
+		pend.Add(1)
+		statedb := statedb
+		go func() {
+			defer pend.Done()
+			for i := range jobs {
+				work(statedb, i)
+			}
+		}()
+	}
+	pend.Wait()
+}
@@ -0,0 +1,129 @@
+package tracing
+
+import (
+	"fmt"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/scroll-tech/go-ethereum/common"
+	"github.com/scroll-tech/go-ethereum/common/hexutil"
+	"github.com/scroll-tech/go-ethereum/core/rawdb"
+	"github.com/scroll-tech/go-ethereum/core/state"
+	"github.com/scroll-tech/go-ethereum/core/types"
+	"github.com/scroll-tech/go-ethereum/core/vm"
+	"github.com/scroll-tech/go-ethereum/trie"
+)
+
+// newProofTestState creates a zktrie state of accounts with slots storage slots each, and
+// the keys of one tx per account, which touches the account and its slots and deletes every
+// other slot. The last tx touches the storage of the first account as well.
+func newProofTestState(tb testing.TB, accounts, slots int) (*state.StateDB, []*txProofKeys) {
+	db := state.NewDatabaseWithConfig(rawdb.NewMemoryDatabase(), &trie.Config{Zktrie: true})
+	statedb, err := state.New(common.Hash{}, db, nil)
+	require.NoError(tb, err)
+	addrs := make([]common.Address, accounts)
+	for i := range addrs {
+		addrs[i] = common.BigToAddress(big.NewInt(int64(i + 1)))
+		statedb.SetBalance(addrs[i], big.NewInt(1))
+		for j := 0; j < slots; j++ {
+			slot := common.BigToHash(big.NewInt(int64(j + 1)))
+			statedb.SetState(addrs[i], slot, slot)
+		}
+	}
+	root, err := statedb.Commit(true)
+	require.NoError(tb, err)
+	require.NoError(tb, db.TrieDB().Commit(root, false, nil))
+	statedb, err = state.New(root, db, nil)
+	require.NoError(tb, err)
+
+	keys := make([]*txProofKeys, accounts)
+	for i, addr := range addrs {
+		storage := make(vm.Storage)
+		for j := 0; j < slots; j++ {
+			var value common.Hash
+			if j%2 == 1 {
+				value = common.BigToHash(big.NewInt(int64(j)))
+			}
+			storage[common.BigToHash(big.NewInt(int64(j+1)))] = value
+		}
+		keys[i] = &txProofKeys{
+			accounts: map[common.Address]struct{}{addr: {}},
+			storages: map[common.Address]vm.Storage{addr: storage},
+		}
+	}
+	keys[accounts-1].storages[addrs[0]] = vm.Storage{common.BigToHash(big.NewInt(2)): {}}
+	return statedb, keys
+}
+
+func newProofTestEnv(txs int) *TraceEnv {
+	env := &TraceEnv{
+		StorageTrace: &types.StorageTrace{
+			Proofs:        make(map[string][]hexutil.Bytes),
+			StorageProofs: make(map[string]map[string][]hexutil.Bytes),
+		},
+		ZkTrieTracer:    make(map[string]state.ZktrieProofTracer),
+		TxStorageTraces: make([]*types.StorageTrace, txs),
+	}
+	for i := range env.TxStorageTraces {
+		env.TxStorageTraces[i] = &types.StorageTrace{
+			Proofs:        make(map[string][]hexutil.Bytes),
+			StorageProofs: make(map[string]map[string][]hexutil.Bytes),
+		}
+	}
+	return env
+}
+
+func TestFillProofs(t *testing.T) {
+	const accounts, slots = 8, 6
+	serialState, keys := newProofTestState(t, accounts, slots)
+	serial := newProofTestEnv(accounts)
+	serial.fillProofs(serialState, keys, 1)
+	parallelState, _ := newProofTestState(t, accounts, slots)
+	parallel := newProofTestEnv(accounts)
+	parallel.fillProofs(parallelState, keys, 4)
+
+	// the workers extract the same proofs as a serial extraction
+	assert.Equal(t, serial.Proofs, parallel.Proofs)
+	assert.Equal(t, serial.StorageProofs, parallel.StorageProofs)
+	assert.Equal(t, serial.TxStorageTraces, parallel.TxStorageTraces)
+	serialDeletions, parallelDeletions := 0, 0
+	for addr, tracer := range serial.ZkTrieTracer {
+		proofs, err := tracer.GetDeletionProofs()
+		require.NoError(t, err)
+		serialDeletions += len(proofs)
+		proofs, err = parallel.ZkTrieTracer[addr].GetDeletionProofs()
+		require.NoError(t, err)
+		parallelDeletions += len(proofs)
+	}
+	assert.NotZero(t, serialDeletions)
+	assert.Equal(t, serialDeletions, parallelDeletions)
+
+	// each tx gets the proofs of the keys it touches
+	addr := common.BigToAddress(big.NewInt(1))
+	proof, err := serialState.GetProof(addr)
+	require.NoError(t, err)
+	assert.Equal(t, types.WrapProof(proof), parallel.TxStorageTraces[0].Proofs[addr.String()])
+	assert.Len(t, parallel.TxStorageTraces[0].StorageProofs[addr.String()], slots)
+	assert.Len(t, parallel.TxStorageTraces[accounts-1].StorageProofs[addr.String()], 1)
+	assert.Len(t, parallel.StorageProofs[addr.String()], slots)
+
+	// known proofs are not extracted again
+	parallel.Proofs[addr.String()] = nil
+	parallel.fillProofs(parallelState, keys, 4)
+	assert.Nil(t, parallel.Proofs[addr.String()])
+}
+
+func BenchmarkFillProofs(b *testing.B) {
+	for _, threads := range []int{1, 2, 4, 8} {
+		b.Run(fmt.Sprintf("threads=%d", threads), func(b *testing.B) {
+			statedb, keys := newProofTestState(b, 64, 32)
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				newProofTestEnv(len(keys)).fillProofs(statedb, keys, threads)
+			}
+		})
+	}
+}
@@ -0,0 +1,60 @@
+package tracing
+
+import (
+	"fmt"
+
+	"github.com/scroll-tech/go-ethereum/consensus"
+	"github.com/scroll-tech/go-ethereum/core"
+	"github.com/scroll-tech/go-ethereum/core/rawdb"
+	"github.com/scroll-tech/go-ethereum/core/state"
+	"github.com/scroll-tech/go-ethereum/core/types"
+	"github.com/scroll-tech/go-ethereum/ethdb"
+	"github.com/scroll-tech/go-ethereum/params"
+
+	"github.com/scroll-tech/go-ethereum/rollup/rollup_sync_service"
+)
+
+// CreateTraceEnvAndGetProverBlockTrace traces a block like CreateTraceEnvAndGetBlockTrace and
+// assembles the input of its proof from the trace.
+func (tw *TracerWrapper) CreateTraceEnvAndGetProverBlockTrace(chainConfig *params.ChainConfig, chainContext core.ChainContext, engine consensus.Engine, chaindb ethdb.Database, statedb *state.StateDB, parent *types.Block, block *types.Block, commitAfterApply bool) (*types.ProverBlockTrace, error) {
+	trace, err := tw.CreateTraceEnvAndGetBlockTrace(chainConfig, chainContext, engine, chaindb, statedb, parent, block, commitAfterApply)
+	if err != nil {
+		return nil, err
+	}
+	return NewProverBlockTrace(chaindb, block, trace)
+}
+
+// NewProverBlockTrace adds the L1 message queue bounds, the row consumption recorded by the
+// circuit capacity checker and the codec hints of a block to its execution trace.
+func NewProverBlockTrace(chaindb ethdb.Reader, block *types.Block, trace *types.BlockTrace) (*types.ProverBlockTrace, error) {
+	endL1QueueIndex := rawdb.ReadFirstQueueIndexNotInL2Block(chaindb, block.Hash())
+	if endL1QueueIndex == nil {
+		return nil, fmt.Errorf("missing FirstQueueIndexNotInL2Block for block: number=%v, hash=%v", block.NumberU64(), block.Hash())
+	}
+	proverTrace := &types.ProverBlockTrace{
+		BlockTrace:      trace,
+		EndL1QueueIndex: *endL1QueueIndex,
+	}
+
+	if rc := rawdb.ReadBlockRowConsumption(chaindb, block.Hash()); rc != nil {
+		var rows uint64
+		for _, subCircuit := range *rc {
+			if subCircuit.RowNumber > rows {
+				rows = subCircuit.RowNumber
+			}
+		}
+		proverTrace.RowConsumption = rc
+		proverTrace.EstimatedRows = &rows
+	}
+
+	codec := rollup_sync_service.LatestCodec()
+	encodedSize, err := codec.EncodedBlockSize(rollup_sync_service.NewWrappedBlock(block, trace.WithdrawTrieRoot))
+	if err != nil {
+		return nil, fmt.Errorf("failed to compute encoded size of block %v: %w", block.NumberU64(), err)
+	}
+	proverTrace.CodecHints = &types.CodecHints{
+		CodecVersion: codec.Version(),
+		EncodedSize:  encodedSize,
+	}
+	return proverTrace, nil
+}
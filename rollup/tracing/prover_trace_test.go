@@ -0,0 +1,61 @@
+package tracing
+
+import (
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/scroll-tech/go-ethereum/common"
+	"github.com/scroll-tech/go-ethereum/core/rawdb"
+	"github.com/scroll-tech/go-ethereum/core/types"
+	"github.com/scroll-tech/go-ethereum/params"
+)
+
+func TestNewProverBlockTrace(t *testing.T) {
+	db := rawdb.NewMemoryDatabase()
+	header := &types.Header{Number: big.NewInt(10), Difficulty: common.Big0, BaseFee: big.NewInt(1)}
+	tx := types.NewTransaction(0, common.HexToAddress("0x01"), common.Big1, 21000, common.Big1, nil)
+	block := types.NewBlockWithHeader(header).WithBody([]*types.Transaction{tx}, nil)
+	trace := &types.BlockTrace{
+		Header:            header,
+		Transactions:      []*types.TransactionData{types.NewTransactionData(tx, 10, params.TestChainConfig)},
+		WithdrawTrieRoot:  common.HexToHash("0xaa"),
+		StartL1QueueIndex: 3,
+	}
+
+	// the end queue index is recorded for every processed block
+	_, err := NewProverBlockTrace(db, block, trace)
+	assert.Error(t, err)
+
+	rawdb.WriteFirstQueueIndexNotInL2Block(db, block.Hash(), 5)
+	proverTrace, err := NewProverBlockTrace(db, block, trace)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(5), proverTrace.EndL1QueueIndex)
+	assert.Nil(t, proverTrace.RowConsumption)
+	assert.Nil(t, proverTrace.EstimatedRows)
+	require.NotNil(t, proverTrace.CodecHints)
+	txData, err := tx.MarshalBinary()
+	require.NoError(t, err)
+	// the hints describe the latest codec, which stores the L2 transactions in the blob
+	assert.Equal(t, uint8(4), proverTrace.CodecHints.CodecVersion)
+	assert.Equal(t, uint64(len(txData)), proverTrace.CodecHints.EncodedSize)
+
+	rawdb.WriteBlockRowConsumption(db, block.Hash(), &types.RowConsumption{{Name: "evm", RowNumber: 100}, {Name: "keccak", RowNumber: 300}})
+	proverTrace, err = NewProverBlockTrace(db, block, trace)
+	require.NoError(t, err)
+	require.NotNil(t, proverTrace.EstimatedRows)
+	assert.Equal(t, uint64(300), *proverTrace.EstimatedRows)
+	assert.Len(t, *proverTrace.RowConsumption, 2)
+
+	// the fields of the block trace are kept at the top level of the result
+	encoded, err := json.Marshal(proverTrace)
+	require.NoError(t, err)
+	var fields map[string]json.RawMessage
+	require.NoError(t, json.Unmarshal(encoded, &fields))
+	for _, field := range []string{"header", "transactions", "withdraw_trie_root", "startL1QueueIndex", "endL1QueueIndex", "rowConsumption", "estimatedRows", "codecHints"} {
+		assert.Contains(t, fields, field)
+	}
+}
@@ -1,7 +1,6 @@
 package tracing
 
 import (
-	"bytes"
 	"errors"
 	"fmt"
 	"runtime"
@@ -56,12 +55,7 @@ type TraceEnv struct {
 	state    *state.StateDB
 	blockCtx vm.BlockContext
 
-	// pMu lock is used to protect Proofs' read and write mutual exclusion,
-	// since txs are executed in parallel, so this lock is required.
-	pMu sync.Mutex
-	// sMu is required because of txs are executed in parallel,
-	// this lock is used to protect StorageTrace's read and write mutual exclusion.
-	sMu sync.Mutex
+	// the proofs are filled after the txs have been executed in parallel, they need no lock.
 	*types.StorageTrace
 	TxStorageTraces []*types.StorageTrace
 	// zktrie tracer is used for zktrie storage to build additional deletion proof
@@ -166,6 +160,10 @@ func CreateTraceEnv(chainConfig *params.ChainConfig, chainContext core.ChainCont
 	return env, nil
 }
 
+// GetBlockTrace traces a block in two passes. The first pass executes the transactions,
+// each traced concurrently on the state left by its predecessors, and collects the accounts
+// and storages they touch. The second pass extracts the proofs of the touched keys, each key
+// once, by a pool of workers.
 func (env *TraceEnv) GetBlockTrace(block *types.Block) (*types.BlockTrace, error) {
 	// Execute all the transaction contained within the block concurrently
 	var (
@@ -173,6 +171,9 @@ func (env *TraceEnv) GetBlockTrace(block *types.Block) (*types.BlockTrace, error
 		pend  = new(sync.WaitGroup)
 		jobs  = make(chan *txTraceTask, len(txs))
 		errCh = make(chan error, 1)
+		keys  = make([]*txProofKeys, len(txs))
+		// the proofs are taken from the state before the block, like the ones of the coinbase
+		proofState = env.state.Copy()
 	)
 	threads := runtime.NumCPU()
 	if threads > len(txs) {
@@ -184,7 +185,9 @@ func (env *TraceEnv) GetBlockTrace(block *types.Block) (*types.BlockTrace, error
 			defer pend.Done()
 			// Fetch and execute the next transaction trace tasks
 			for task := range jobs {
-				if err := env.getTxResult(task.statedb, task.index, block); err != nil {
+				txKeys, err := env.getTxResult(task.statedb, task.index, block)
+				keys[task.index] = txKeys
+				if err != nil {
 					select {
 					case errCh <- err:
 					default:
@@ -227,17 +230,10 @@ func (env *TraceEnv) GetBlockTrace(block *types.Block) (*types.BlockTrace, error
 	close(jobs)
 	pend.Wait()
 
+	env.fillProofs(proofState, keys, runtime.NumCPU())
+
 	// after all tx has been traced, collect "deletion proof" for zktrie
-	for _, tracer := range env.ZkTrieTracer {
-		delProofs, err := tracer.GetDeletionProofs()
-		if err != nil {
-			log.Error("deletion proof failure", "error", err)
-		} else {
-			for _, proof := range delProofs {
-				env.DeletionProofs = append(env.DeletionProofs, proof)
-			}
-		}
-	}
+	env.collectDeletionProofs()
 
 	// build dummy per-tx deletion proof
 	for _, txStorageTrace := range env.TxStorageTraces {
@@ -259,7 +255,8 @@ func (env *TraceEnv) GetBlockTrace(block *types.Block) (*types.BlockTrace, error
 	return env.fillBlockTrace(block)
 }
 
-func (env *TraceEnv) getTxResult(state *state.StateDB, index int, block *types.Block) error {
+// getTxResult traces a transaction and returns the accounts and storages it touches.
+func (env *TraceEnv) getTxResult(state *state.StateDB, index int, block *types.Block) (*txProofKeys, error) {
 	tx := block.Transactions()[index]
 	msg, _ := tx.AsMessage(env.signer, block.BaseFee())
 	from, _ := types.Sender(env.signer, tx)
@@ -299,11 +296,11 @@ func (env *TraceEnv) getTxResult(state *state.StateDB, index int, block *types.B
 	}
 	callTracer, err := tracers.New("callTracer", &tracerContext)
 	if err != nil {
-		return fmt.Errorf("failed to create callTracer: %w", err)
+		return nil, fmt.Errorf("failed to create callTracer: %w", err)
 	}
 	prestateTracer, err := tracers.New("prestateTracer", &tracerContext)
 	if err != nil {
-		return fmt.Errorf("failed to create prestateTracer: %w", err)
+		return nil, fmt.Errorf("failed to create prestateTracer: %w", err)
 	}
 	structLogger := vm.NewStructLogger(env.logConfig)
 	tracer := NewMuxTracer(structLogger, callTracer, prestateTracer)
@@ -316,11 +313,11 @@ func (env *TraceEnv) getTxResult(state *state.StateDB, index int, block *types.B
 	// Computes the new state by applying the given message.
 	l1DataFee, err := fees.CalculateL1DataFee(tx, state)
 	if err != nil {
-		return err
+		return nil, err
 	}
 	result, err := core.ApplyMessage(vmenv, msg, new(core.GasPool).AddGas(msg.Gas()), l1DataFee)
 	if err != nil {
-		return err
+		return nil, err
 	}
 	// If the result contains a revert reason, return it.
 	returnVal := result.Return()
@@ -332,7 +329,7 @@ func (env *TraceEnv) getTxResult(state *state.StateDB, index int, block *types.B
 	var after []*types.AccountWrapper
 	if to == nil {
 		if createdAcc == nil {
-			return errors.New("unexpected tx: address for created contract unavailable")
+			return nil, errors.New("unexpected tx: address for created contract unavailable")
 		}
 		to = &createdAcc.Address
 	}
@@ -360,109 +357,21 @@ func (env *TraceEnv) getTxResult(state *state.StateDB, index int, block *types.B
 		txStorageTrace.RootAfter = block.Root()
 	}
 
-	// merge required proof data
+	// the proofs of the touched accounts and storages are extracted once all txs are executed
 	proofAccounts := structLogger.UpdatedAccounts()
 	proofAccounts[vmenv.FeeRecipient()] = struct{}{}
-	for addr := range proofAccounts {
-		addrStr := addr.String()
-
-		env.pMu.Lock()
-		checkedProof, existed := env.Proofs[addrStr]
-		if existed {
-			txStorageTrace.Proofs[addrStr] = checkedProof
-		}
-		env.pMu.Unlock()
-		if existed {
-			continue
-		}
-		proof, err := state.GetProof(addr)
-		if err != nil {
-			log.Error("Proof not available", "address", addrStr, "error", err)
-			// but we still mark the proofs map with nil array
-		}
-		wrappedProof := types.WrapProof(proof)
-		env.pMu.Lock()
-		env.Proofs[addrStr] = wrappedProof
-		txStorageTrace.Proofs[addrStr] = wrappedProof
-		env.pMu.Unlock()
-	}
-
-	proofStorages := structLogger.UpdatedStorages()
-	for addr, keys := range proofStorages {
-		if _, existed := txStorageTrace.StorageProofs[addr.String()]; !existed {
-			txStorageTrace.StorageProofs[addr.String()] = make(map[string][]hexutil.Bytes)
-		}
-
-		env.sMu.Lock()
-		trie, err := state.GetStorageTrieForProof(addr)
-		if err != nil {
-			// but we still continue to next address
-			log.Error("Storage trie not available", "error", err, "address", addr)
-			env.sMu.Unlock()
-			continue
-		}
-		zktrieTracer := state.NewProofTracer(trie)
-		env.sMu.Unlock()
-
-		for key, values := range keys {
-			addrStr := addr.String()
-			keyStr := key.String()
-			isDelete := bytes.Equal(values.Bytes(), common.Hash{}.Bytes())
-
-			txm := txStorageTrace.StorageProofs[addrStr]
-			env.sMu.Lock()
-			m, existed := env.StorageProofs[addrStr]
-			if !existed {
-				m = make(map[string][]hexutil.Bytes)
-				env.StorageProofs[addrStr] = m
-			}
-			if zktrieTracer.Available() && !env.ZkTrieTracer[addrStr].Available() {
-				env.ZkTrieTracer[addrStr] = state.NewProofTracer(trie)
-			}
-
-			if proof, existed := m[keyStr]; existed {
-				txm[keyStr] = proof
-				// still need to touch tracer for deletion
-				if isDelete && zktrieTracer.Available() {
-					env.ZkTrieTracer[addrStr].MarkDeletion(key)
-				}
-				env.sMu.Unlock()
-				continue
-			}
-			env.sMu.Unlock()
-
-			var proof [][]byte
-			var err error
-			if zktrieTracer.Available() {
-				proof, err = state.GetSecureTrieProof(zktrieTracer, key)
-			} else {
-				proof, err = state.GetSecureTrieProof(trie, key)
-			}
-			if err != nil {
-				log.Error("Storage proof not available", "error", err, "address", addrStr, "key", keyStr)
-				// but we still mark the proofs map with nil array
-			}
-			wrappedProof := types.WrapProof(proof)
-			env.sMu.Lock()
-			txm[keyStr] = wrappedProof
-			m[keyStr] = wrappedProof
-			if zktrieTracer.Available() {
-				if isDelete {
-					zktrieTracer.MarkDeletion(key)
-				}
-				env.ZkTrieTracer[addrStr].Merge(zktrieTracer)
-			}
-			env.sMu.Unlock()
-		}
+	keys := &txProofKeys{
+		accounts: proofAccounts,
+		storages: structLogger.UpdatedStorages(),
 	}
 
 	callTrace, err := callTracer.GetResult()
 	if err != nil {
-		return fmt.Errorf("failed to get callTracer result: %w", err)
+		return nil, fmt.Errorf("failed to get callTracer result: %w", err)
 	}
 	prestate, err := prestateTracer.GetResult()
 	if err != nil {
-		return fmt.Errorf("failed to get prestateTracer result: %w", err)
+		return nil, fmt.Errorf("failed to get prestateTracer result: %w", err)
 	}
 
 	env.ExecutionResults[index] = &types.ExecutionResult{
@@ -480,7 +389,21 @@ func (env *TraceEnv) getTxResult(state *state.StateDB, index int, block *types.B
 	}
 	env.TxStorageTraces[index] = txStorageTrace
 
-	return nil
+	return keys, nil
+}
+
+// collectDeletionProofs collects the deletion proofs of the zktrie tracers into the storage trace.
+func (env *TraceEnv) collectDeletionProofs() {
+	for _, tracer := range env.ZkTrieTracer {
+		delProofs, err := tracer.GetDeletionProofs()
+		if err != nil {
+			log.Error("deletion proof failure", "error", err)
+		} else {
+			for _, proof := range delProofs {
+				env.DeletionProofs = append(env.DeletionProofs, proof)
+			}
+		}
+	}
 }
 
 // fillBlockTrace content after all the txs are finished running.
@@ -564,7 +487,7 @@ func (env *TraceEnv) fillBlockTrace(block *types.Block) (*types.BlockTrace, erro
 	}
 
 	// only zktrie model has the ability to get `mptwitness`.
-	if env.chainConfig.Scroll.ZktrieEnabled() {
+	if env.chainConfig.Scroll.ZktrieEnabledAt(block.Number()) {
 		// we use MPTWitnessNothing by default and do not allow switch among MPTWitnessType atm.
 		// MPTWitness will be removed from traces in the future.
 		if err := zkproof.FillBlockTraceForMPTWitness(zkproof.MPTWitnessNothing, blockTrace); err != nil {
@@ -0,0 +1,271 @@
+package tracing
+
+import (
+	"bytes"
+	"fmt"
+	"math/big"
+	"runtime"
+	"sort"
+	"time"
+
+	"github.com/scroll-tech/go-ethereum/common"
+	"github.com/scroll-tech/go-ethereum/common/hexutil"
+	"github.com/scroll-tech/go-ethereum/core"
+	"github.com/scroll-tech/go-ethereum/core/state"
+	"github.com/scroll-tech/go-ethereum/core/types"
+	"github.com/scroll-tech/go-ethereum/core/vm"
+	"github.com/scroll-tech/go-ethereum/crypto"
+	"github.com/scroll-tech/go-ethereum/params"
+
+	"github.com/scroll-tech/go-ethereum/rollup/fees"
+)
+
+// CreateBlockWitness executes a block on the state of its parent and returns its stateless witness.
+func (tw *TracerWrapper) CreateBlockWitness(chainConfig *params.ChainConfig, chainContext core.ChainContext, statedb *state.StateDB, parent *types.Block, block *types.Block) (*types.BlockWitness, error) {
+	return NewBlockWitness(chainConfig, chainContext, statedb, parent, block)
+}
+
+// NewBlockWitness executes a block on statedb, the state after its parent, and collects the
+// accounts and storage slots the block accesses, including the ones accessed outside of the
+// EVM like the L1 fee slots and the coinbase. Their proofs are extracted from the state of the
+// parent, together with the code the block loads from it and the block hashes it looks up.
+func NewBlockWitness(chainConfig *params.ChainConfig, chainContext core.ChainContext, statedb *state.StateDB, parent *types.Block, block *types.Block) (*types.BlockWitness, error) {
+	var (
+		proofState  = statedb.Copy()
+		blockCtx    = core.NewEVMBlockContext(block.Header(), chainContext, chainConfig, nil)
+		signer      = types.MakeSigner(chainConfig, block.Number())
+		txs         = block.Transactions()
+		keys        = make([]*txProofKeys, len(txs))
+		recorder    = &witnessStateDB{StateDB: statedb, codes: make(map[common.Address]struct{})}
+		blockHashes = &blockHashTracer{hashes: make(map[uint64]common.Hash)}
+	)
+	for i, tx := range txs {
+		msg, err := tx.AsMessage(signer, block.BaseFee())
+		if err != nil {
+			return nil, fmt.Errorf("could not apply tx %d [%v]: %w", i, tx.Hash().Hex(), err)
+		}
+		keys[i] = &txProofKeys{
+			accounts: make(map[common.Address]struct{}),
+			storages: make(map[common.Address]vm.Storage),
+		}
+		recorder.keys = keys[i]
+		statedb.Prepare(tx.Hash(), i)
+		vmenv := vm.NewEVM(blockCtx, core.NewEVMTxContext(msg), recorder, chainConfig, vm.Config{Debug: true, Tracer: blockHashes})
+		l1DataFee, err := fees.CalculateL1DataFee(tx, recorder)
+		if err != nil {
+			return nil, fmt.Errorf("could not apply tx %d [%v]: %w", i, tx.Hash().Hex(), err)
+		}
+		if _, err := core.ApplyMessage(vmenv, msg, new(core.GasPool).AddGas(msg.Gas()), l1DataFee); err != nil {
+			return nil, fmt.Errorf("could not apply tx %d [%v]: %w", i, tx.Hash().Hex(), err)
+		}
+		statedb.Finalise(vmenv.ChainConfig().IsEIP158(block.Number()))
+	}
+
+	env := &TraceEnv{
+		StorageTrace: &types.StorageTrace{
+			RootBefore:    parent.Root(),
+			RootAfter:     block.Root(),
+			Proofs:        make(map[string][]hexutil.Bytes),
+			StorageProofs: make(map[string]map[string][]hexutil.Bytes),
+		},
+		ZkTrieTracer:    make(map[string]state.ZktrieProofTracer),
+		TxStorageTraces: make([]*types.StorageTrace, len(txs)),
+	}
+	env.fillProofs(proofState, keys, runtime.NumCPU())
+	env.collectDeletionProofs()
+
+	// the code deployed within the block is not part of the witness, it is deployed again
+	codeByHash := make(map[common.Hash][]byte)
+	for addr := range recorder.codes {
+		if code := proofState.GetCode(addr); len(code) != 0 {
+			codeByHash[crypto.Keccak256Hash(code)] = code
+		}
+	}
+	codeHashes := make([]common.Hash, 0, len(codeByHash))
+	for hash := range codeByHash {
+		codeHashes = append(codeHashes, hash)
+	}
+	sort.Slice(codeHashes, func(i, j int) bool { return bytes.Compare(codeHashes[i][:], codeHashes[j][:]) < 0 })
+	codes := make([]hexutil.Bytes, len(codeHashes))
+	for i, hash := range codeHashes {
+		codes[i] = codeByHash[hash]
+	}
+
+	hashes := make([]*types.BlockHashWitness, 0, len(blockHashes.hashes))
+	for number, hash := range blockHashes.hashes {
+		hashes = append(hashes, &types.BlockHashWitness{Number: hexutil.Uint64(number), Hash: hash})
+	}
+	sort.Slice(hashes, func(i, j int) bool { return hashes[i].Number < hashes[j].Number })
+
+	txData := make([]*types.TransactionData, len(txs))
+	for i, tx := range txs {
+		txData[i] = types.NewTransactionData(tx, block.NumberU64(), chainConfig)
+	}
+	var chainID uint64
+	if chainConfig.ChainID != nil {
+		chainID = chainConfig.ChainID.Uint64()
+	}
+	return &types.BlockWitness{
+		ChainID:      chainID,
+		Header:       block.Header(),
+		Transactions: txData,
+		StorageTrace: env.StorageTrace,
+		Codes:        codes,
+		BlockHashes:  hashes,
+	}, nil
+}
+
+// witnessStateDB records the accounts and the storage slots read and written through it
+// into the keys of the current transaction, and the accounts whose code is loaded.
+type witnessStateDB struct {
+	*state.StateDB
+	keys  *txProofKeys
+	codes map[common.Address]struct{}
+}
+
+func (s *witnessStateDB) touch(addr common.Address) {
+	s.keys.accounts[addr] = struct{}{}
+}
+
+// touchSlot records a slot with the value it is read or written with, the last written value
+// of a slot decides whether the deletion of the slot is proven.
+func (s *witnessStateDB) touchSlot(addr common.Address, key, value common.Hash, written bool) {
+	s.touch(addr)
+	storage, ok := s.keys.storages[addr]
+	if !ok {
+		storage = make(vm.Storage)
+		s.keys.storages[addr] = storage
+	}
+	if _, existed := storage[key]; !existed || written {
+		storage[key] = value
+	}
+}
+
+func (s *witnessStateDB) CreateAccount(addr common.Address) {
+	s.touch(addr)
+	s.StateDB.CreateAccount(addr)
+}
+
+func (s *witnessStateDB) SubBalance(addr common.Address, amount *big.Int) {
+	s.touch(addr)
+	s.StateDB.SubBalance(addr, amount)
+}
+
+func (s *witnessStateDB) AddBalance(addr common.Address, amount *big.Int) {
+	s.touch(addr)
+	s.StateDB.AddBalance(addr, amount)
+}
+
+func (s *witnessStateDB) GetBalance(addr common.Address) *big.Int {
+	s.touch(addr)
+	return s.StateDB.GetBalance(addr)
+}
+
+func (s *witnessStateDB) GetNonce(addr common.Address) uint64 {
+	s.touch(addr)
+	return s.StateDB.GetNonce(addr)
+}
+
+func (s *witnessStateDB) SetNonce(addr common.Address, nonce uint64) {
+	s.touch(addr)
+	s.StateDB.SetNonce(addr, nonce)
+}
+
+func (s *witnessStateDB) GetKeccakCodeHash(addr common.Address) common.Hash {
+	s.touch(addr)
+	return s.StateDB.GetKeccakCodeHash(addr)
+}
+
+func (s *witnessStateDB) GetCode(addr common.Address) []byte {
+	s.touch(addr)
+	s.codes[addr] = struct{}{}
+	return s.StateDB.GetCode(addr)
+}
+
+func (s *witnessStateDB) SetCode(addr common.Address, code []byte) {
+	s.touch(addr)
+	s.StateDB.SetCode(addr, code)
+}
+
+func (s *witnessStateDB) GetPoseidonCodeHash(addr common.Address) common.Hash {
+	s.touch(addr)
+	return s.StateDB.GetPoseidonCodeHash(addr)
+}
+
+func (s *witnessStateDB) GetCodeSize(addr common.Address) uint64 {
+	s.touch(addr)
+	return s.StateDB.GetCodeSize(addr)
+}
+
+func (s *witnessStateDB) GetCommittedState(addr common.Address, key common.Hash) common.Hash {
+	value := s.StateDB.GetCommittedState(addr, key)
+	s.touchSlot(addr, key, value, false)
+	return value
+}
+
+func (s *witnessStateDB) GetState(addr common.Address, key common.Hash) common.Hash {
+	value := s.StateDB.GetState(addr, key)
+	s.touchSlot(addr, key, value, false)
+	return value
+}
+
+func (s *witnessStateDB) SetState(addr common.Address, key, value common.Hash) {
+	s.touchSlot(addr, key, value, true)
+	s.StateDB.SetState(addr, key, value)
+}
+
+func (s *witnessStateDB) Suicide(addr common.Address) bool {
+	s.touch(addr)
+	return s.StateDB.Suicide(addr)
+}
+
+func (s *witnessStateDB) Exist(addr common.Address) bool {
+	s.touch(addr)
+	return s.StateDB.Exist(addr)
+}
+
+func (s *witnessStateDB) Empty(addr common.Address) bool {
+	s.touch(addr)
+	return s.StateDB.Empty(addr)
+}
+
+// blockHashTracer records the block hashes returned by BLOCKHASH, the hashes of blocks out
+// of the range of BLOCKHASH are zero and not recorded.
+type blockHashTracer struct {
+	hashes map[uint64]common.Hash
+
+	number  uint64
+	pending bool
+}
+
+func (t *blockHashTracer) CaptureStart(env *vm.EVM, from common.Address, to common.Address, create bool, input []byte, gas uint64, value *big.Int) {
+}
+
+func (t *blockHashTracer) CaptureState(pc uint64, op vm.OpCode, gas, cost uint64, scope *vm.ScopeContext, rData []byte, depth int, err error) {
+	if op != vm.BLOCKHASH || err != nil {
+		return
+	}
+	var overflow bool
+	t.number, overflow = scope.Stack.Back(0).Uint64WithOverflow()
+	t.pending = !overflow
+}
+
+func (t *blockHashTracer) CaptureStateAfter(pc uint64, op vm.OpCode, gas, cost uint64, scope *vm.ScopeContext, rData []byte, depth int, err error) {
+	if op != vm.BLOCKHASH || !t.pending {
+		return
+	}
+	t.pending = false
+	if hash := common.Hash(scope.Stack.Back(0).Bytes32()); err == nil && hash != (common.Hash{}) {
+		t.hashes[t.number] = hash
+	}
+}
+
+func (t *blockHashTracer) CaptureEnter(typ vm.OpCode, from common.Address, to common.Address, input []byte, gas uint64, value *big.Int) {
+}
+
+func (t *blockHashTracer) CaptureExit(output []byte, gasUsed uint64, err error) {}
+
+func (t *blockHashTracer) CaptureFault(pc uint64, op vm.OpCode, gas, cost uint64, scope *vm.ScopeContext, depth int, err error) {
+}
+
+func (t *blockHashTracer) CaptureEnd(output []byte, gasUsed uint64, _ time.Duration, err error) {}
@@ -0,0 +1,82 @@
+package tracing
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/scroll-tech/go-ethereum/common"
+	"github.com/scroll-tech/go-ethereum/common/hexutil"
+	"github.com/scroll-tech/go-ethereum/consensus/ethash"
+	"github.com/scroll-tech/go-ethereum/core"
+	"github.com/scroll-tech/go-ethereum/core/rawdb"
+	"github.com/scroll-tech/go-ethereum/core/types"
+	"github.com/scroll-tech/go-ethereum/core/vm"
+	"github.com/scroll-tech/go-ethereum/crypto"
+	"github.com/scroll-tech/go-ethereum/params"
+)
+
+func TestNewBlockWitness(t *testing.T) {
+	var (
+		key, _   = crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+		sender   = crypto.PubkeyToAddress(key.PublicKey)
+		contract = common.HexToAddress("0xc0de")
+		// SLOAD(1), SSTORE(2, BLOCKHASH(NUMBER-1))
+		code   = common.FromHex("0x6001545060014303406002550000")
+		config = params.TestChainConfig
+		db     = rawdb.NewMemoryDatabase()
+	)
+	genesis := (&core.Genesis{
+		Config: config,
+		Alloc: core.GenesisAlloc{
+			sender:   {Balance: big.NewInt(params.Ether)},
+			contract: {Balance: common.Big0, Code: code, Storage: map[common.Hash]common.Hash{common.BigToHash(common.Big1): common.BigToHash(common.Big1)}},
+		},
+	}).MustCommit(db)
+	blocks, _ := core.GenerateChain(config, genesis, ethash.NewFaker(), db, 2, func(i int, b *core.BlockGen) {
+		if i == 1 {
+			tx, err := types.SignTx(types.NewTransaction(b.TxNonce(sender), contract, common.Big0, 100000, b.BaseFee(), nil), types.HomesteadSigner{}, key)
+			require.NoError(t, err)
+			b.AddTx(tx)
+		}
+	})
+	bc, err := core.NewBlockChain(db, nil, config, ethash.NewFaker(), vm.Config{}, nil, nil)
+	require.NoError(t, err)
+	defer bc.Stop()
+	_, err = bc.InsertChain(blocks)
+	require.NoError(t, err)
+
+	parent, block := blocks[0], blocks[1]
+	statedb, err := bc.StateAt(parent.Root())
+	require.NoError(t, err)
+	witness, err := NewBlockWitness(config, bc, statedb, parent, block)
+	require.NoError(t, err)
+
+	assert.Equal(t, block.Hash(), witness.Header.Hash())
+	assert.Len(t, witness.Transactions, 1)
+	assert.Equal(t, parent.Root(), witness.StorageTrace.RootBefore)
+	assert.Equal(t, block.Root(), witness.StorageTrace.RootAfter)
+
+	// the accounts accessed by the block are proven against the parent state, the fee vault
+	// is credited outside of the EVM
+	parentState, err := bc.StateAt(parent.Root())
+	require.NoError(t, err)
+	for _, addr := range []common.Address{sender, contract, *config.Scroll.FeeVaultAddress} {
+		proof, err := parentState.GetProof(addr)
+		require.NoError(t, err)
+		assert.Equal(t, types.WrapProof(proof), witness.StorageTrace.Proofs[addr.String()], addr)
+	}
+	storageProofs := witness.StorageTrace.StorageProofs[contract.String()]
+	assert.Contains(t, storageProofs, common.BigToHash(common.Big1).String())
+	assert.Contains(t, storageProofs, common.BigToHash(common.Big2).String())
+
+	assert.Equal(t, []hexutil.Bytes{code}, witness.Codes)
+	// the block hash looked up by the contract is the one it stored
+	blockState, err := bc.StateAt(block.Root())
+	require.NoError(t, err)
+	hash := blockState.GetState(contract, common.BigToHash(common.Big2))
+	require.NotEqual(t, common.Hash{}, hash)
+	assert.Equal(t, []*types.BlockHashWitness{{Number: 1, Hash: hash}}, witness.BlockHashes)
+}
@@ -0,0 +1,140 @@
+package withdrawtrie
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+
+	"github.com/scroll-tech/go-ethereum/common"
+	"github.com/scroll-tech/go-ethereum/core/rawdb"
+	"github.com/scroll-tech/go-ethereum/core/state"
+	"github.com/scroll-tech/go-ethereum/core/types"
+	"github.com/scroll-tech/go-ethereum/ethdb"
+	"github.com/scroll-tech/go-ethereum/log"
+	"github.com/scroll-tech/go-ethereum/rollup/rcfg"
+)
+
+const (
+	// backfillProgressInterval is the number of blocks after which the backfill progress is persisted.
+	backfillProgressInterval = 1024
+
+	// backfillLogInterval is the frequency of logging the backfill progress.
+	backfillLogInterval = 30 * time.Second
+)
+
+// BackfillChain is the part of the blockchain read by the Backfiller.
+type BackfillChain interface {
+	CurrentHeader() *types.Header
+	GetHeaderByNumber(number uint64) *types.Header
+	StateAt(root common.Hash) (*state.StateDB, error)
+}
+
+// Backfiller is a one-shot job that stores the withdraw roots of the blocks imported before
+// the withdraw root of every block was stored at import time, so that old databases gain the
+// fast read path without a resync. It walks the canonical blocks up to the head at its start
+// and reads the roots from their state, which requires an archive node: blocks whose state is
+// not available are skipped. The progress is persisted, so the job resumes after a restart
+// and does not run again once it completed.
+type Backfiller struct {
+	db      ethdb.Database
+	chain   BackfillChain
+	limiter *rate.Limiter // nil if the backfill is not rate limited
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// NewBackfiller creates a backfill job that reads the state of at most rateLimit blocks
+// per second, zero means no limit.
+func NewBackfiller(db ethdb.Database, chain BackfillChain, rateLimit uint64) *Backfiller {
+	ctx, cancel := context.WithCancel(context.Background())
+	b := &Backfiller{db: db, chain: chain, ctx: ctx, cancel: cancel}
+	if rateLimit > 0 {
+		b.limiter = rate.NewLimiter(rate.Limit(rateLimit), int(rateLimit))
+	}
+	return b
+}
+
+// Start runs the backfill in the background, unless it already completed.
+func (b *Backfiller) Start() {
+	if b == nil {
+		return
+	}
+	if progress := rawdb.ReadWithdrawRootBackfill(b.db); progress != nil && progress.Done {
+		return
+	}
+	log.Info("Starting withdraw root backfill")
+
+	b.wg.Add(1)
+	go func() {
+		defer b.wg.Done()
+		b.run()
+	}()
+}
+
+// Stop interrupts the backfill and waits for it to persist its progress.
+func (b *Backfiller) Stop() {
+	if b == nil {
+		return
+	}
+	b.cancel()
+	b.wg.Wait()
+}
+
+// run backfills the withdraw roots of the blocks from the persisted progress up to the
+// current head.
+func (b *Backfiller) run() {
+	progress := rawdb.ReadWithdrawRootBackfill(b.db)
+	if progress == nil {
+		progress = &rawdb.WithdrawRootBackfill{}
+	}
+	head := b.chain.CurrentHeader().Number.Uint64()
+
+	var (
+		written, unavailable int
+		start                = time.Now()
+		logged               = time.Now()
+	)
+	defer func() {
+		rawdb.WriteWithdrawRootBackfill(b.db, progress)
+	}()
+	for ; progress.NextBlock <= head; progress.NextBlock++ {
+		if progress.NextBlock%backfillProgressInterval == 0 {
+			rawdb.WriteWithdrawRootBackfill(b.db, progress)
+		}
+		if time.Since(logged) > backfillLogInterval {
+			log.Info("Backfilling withdraw roots", "block", progress.NextBlock, "head", head, "written", written, "unavailable", unavailable)
+			logged = time.Now()
+		}
+		header := b.chain.GetHeaderByNumber(progress.NextBlock)
+		if header == nil {
+			log.Warn("Withdraw root backfill interrupted, missing block", "number", progress.NextBlock)
+			return
+		}
+		if rawdb.ReadBlockWithdrawRoot(b.db, header.Hash()) != nil {
+			continue
+		}
+		if b.limiter != nil {
+			if err := b.limiter.Wait(b.ctx); err != nil {
+				return
+			}
+		} else if b.ctx.Err() != nil {
+			return
+		}
+		statedb, err := b.chain.StateAt(header.Root)
+		if err != nil {
+			unavailable++
+			continue
+		}
+		rawdb.WriteBlockWithdrawRoot(b.db, header.Hash(), ReadWTRSlot(rcfg.L2MessageQueueAddress, statedb))
+		written++
+	}
+	progress.Done = true
+	log.Info("Withdraw root backfill completed", "head", head, "written", written, "unavailable", unavailable, "elapsed", common.PrettyDuration(time.Since(start)))
+	if unavailable > 0 {
+		log.Warn("Withdraw roots of some blocks not backfilled, their state is not available", "count", unavailable)
+	}
+}
@@ -0,0 +1,117 @@
+package withdrawtrie
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/scroll-tech/go-ethereum/common"
+	"github.com/scroll-tech/go-ethereum/core/rawdb"
+	"github.com/scroll-tech/go-ethereum/core/state"
+	"github.com/scroll-tech/go-ethereum/core/types"
+	"github.com/scroll-tech/go-ethereum/ethdb"
+	"github.com/scroll-tech/go-ethereum/rollup/rcfg"
+)
+
+// backfillChain serves headers whose state stores the block number as withdraw root,
+// the state of the blocks in pruned is not available.
+type backfillChain struct {
+	headers []*types.Header
+	pruned  map[uint64]bool
+	sdb     state.Database
+	reads   int
+}
+
+func newBackfillChain(t *testing.T, db ethdb.Database, head uint64) *backfillChain {
+	chain := &backfillChain{pruned: make(map[uint64]bool), sdb: state.NewDatabase(db)}
+	for number := uint64(0); number <= head; number++ {
+		statedb, err := state.New(common.Hash{}, chain.sdb, nil)
+		require.NoError(t, err)
+		statedb.SetState(rcfg.L2MessageQueueAddress, rcfg.WithdrawTrieRootSlot, common.BigToHash(new(big.Int).SetUint64(number+1)))
+		root, err := statedb.Commit(false)
+		require.NoError(t, err)
+		require.NoError(t, chain.sdb.TrieDB().Commit(root, false, nil))
+		chain.headers = append(chain.headers, &types.Header{Number: new(big.Int).SetUint64(number), Root: root})
+	}
+	return chain
+}
+
+func (c *backfillChain) CurrentHeader() *types.Header {
+	return c.headers[len(c.headers)-1]
+}
+
+func (c *backfillChain) GetHeaderByNumber(number uint64) *types.Header {
+	if number >= uint64(len(c.headers)) {
+		return nil
+	}
+	return c.headers[number]
+}
+
+func (c *backfillChain) StateAt(root common.Hash) (*state.StateDB, error) {
+	c.reads++
+	for _, header := range c.headers {
+		if header.Root == root && c.pruned[header.Number.Uint64()] {
+			return nil, errors.New("missing trie node")
+		}
+	}
+	return state.New(root, c.sdb, nil)
+}
+
+func TestBackfiller(t *testing.T) {
+	db := rawdb.NewMemoryDatabase()
+	chain := newBackfillChain(t, db, 10)
+	chain.pruned[3] = true
+
+	// blocks before the interruption point and blocks with a stored root are not read again
+	rawdb.WriteWithdrawRootBackfill(db, &rawdb.WithdrawRootBackfill{NextBlock: 2})
+	rawdb.WriteBlockWithdrawRoot(db, chain.headers[5].Hash(), common.Hash{0x55})
+
+	backfiller := NewBackfiller(db, chain, 0)
+	backfiller.Start()
+	backfiller.wg.Wait()
+
+	assert.Equal(t, 8, chain.reads)
+	for number, header := range chain.headers {
+		root := rawdb.ReadBlockWithdrawRoot(db, header.Hash())
+		switch {
+		case number < 2 || number == 3:
+			assert.Nil(t, root, "block: %d", number)
+		case number == 5:
+			assert.Equal(t, &common.Hash{0x55}, root)
+		default:
+			require.NotNil(t, root, "block: %d", number)
+			assert.Equal(t, common.BigToHash(big.NewInt(int64(number+1))), *root, "block: %d", number)
+		}
+	}
+	assert.Equal(t, &rawdb.WithdrawRootBackfill{NextBlock: 11, Done: true}, rawdb.ReadWithdrawRootBackfill(db))
+
+	// a completed backfill does not run again
+	chain.reads = 0
+	backfiller = NewBackfiller(db, chain, 0)
+	backfiller.Start()
+	backfiller.wg.Wait()
+	assert.Zero(t, chain.reads)
+}
+
+func TestBackfillerStop(t *testing.T) {
+	db := rawdb.NewMemoryDatabase()
+	chain := newBackfillChain(t, db, 10)
+
+	// the first read is served by the burst of the limiter, stopping interrupts the wait
+	// for the second one and persists the progress
+	backfiller := NewBackfiller(db, chain, 1)
+	backfiller.Start()
+	require.Eventually(t, func() bool {
+		return rawdb.ReadBlockWithdrawRoot(db, chain.headers[0].Hash()) != nil
+	}, time.Second, time.Millisecond)
+	backfiller.Stop()
+
+	progress := rawdb.ReadWithdrawRootBackfill(db)
+	require.NotNil(t, progress)
+	assert.False(t, progress.Done)
+	assert.Equal(t, uint64(1), progress.NextBlock)
+}
@@ -0,0 +1,92 @@
+package withdrawtrie
+
+import (
+	"github.com/scroll-tech/go-ethereum/common"
+	"github.com/scroll-tech/go-ethereum/core/rawdb"
+	"github.com/scroll-tech/go-ethereum/core/types"
+	"github.com/scroll-tech/go-ethereum/ethdb"
+)
+
+// The withdraw message index mirrors the append-only withdraw trie: it stores the leaves
+// appended by the canonical blocks by message nonce, together with the block that appended
+// them. The trie after any block consists of the leaves appended up to that block, so the
+// proofs against the withdraw root of every finalized batch can be generated from the index.
+
+// MessageIndexer maintains the withdraw message index alongside the chain, see
+// core.WithdrawMessageIndexer.
+type MessageIndexer struct{}
+
+// IndexBlockMessages implements core.WithdrawMessageIndexer.
+func (MessageIndexer) IndexBlockMessages(db ethdb.KeyValueWriter, blockHash common.Hash, blockNumber uint64, receipts types.Receipts) {
+	IndexBlockMessages(db, blockHash, blockNumber, receipts)
+}
+
+// UnindexBlockMessages implements core.WithdrawMessageIndexer.
+func (MessageIndexer) UnindexBlockMessages(reader ethdb.Reader, db ethdb.KeyValueWriter, logs []*types.Log) {
+	UnindexBlockMessages(reader, db, logs)
+}
+
+// IndexBlockMessages stores the messages appended to the withdraw trie by the canonical
+// block with the given hash, number and receipts.
+func IndexBlockMessages(db ethdb.KeyValueWriter, blockHash common.Hash, blockNumber uint64, receipts types.Receipts) {
+	for _, receipt := range receipts {
+		for _, l := range receipt.Logs {
+			if nonce, messageHash, ok := ParseAppendMessageLog(l); ok {
+				rawdb.WriteWithdrawMessage(db, nonce, &rawdb.WithdrawMessage{MessageHash: messageHash, BlockNumber: blockNumber, BlockHash: blockHash})
+			}
+		}
+	}
+}
+
+// UnindexBlockMessages removes the messages appended by the given logs of a reorged block
+// from the index, unless the new canonical chain appended them again. The logs must have
+// their block hash set.
+func UnindexBlockMessages(reader ethdb.Reader, db ethdb.KeyValueWriter, logs []*types.Log) {
+	for _, l := range logs {
+		nonce, messageHash, ok := ParseAppendMessageLog(l)
+		if !ok {
+			continue
+		}
+		message := rawdb.ReadWithdrawMessage(reader, nonce)
+		if message != nil && message.BlockHash == l.BlockHash {
+			rawdb.DeleteWithdrawMessage(db, nonce)
+			message = nil
+		}
+		if message == nil || message.MessageHash != messageHash {
+			rawdb.DeleteWithdrawMessageNonce(db, messageHash)
+		}
+	}
+}
+
+// MessageLeaves returns the leaves of the withdraw trie after the given block from the
+// index, ok is false if the index does not cover the messages up to the block, e.g. on
+// databases that were synced before the index was introduced.
+func MessageLeaves(db ethdb.Database, blockNumber uint64) (leaves []common.Hash, ok bool) {
+	// appendedBefore reports whether the message with the given nonce was appended up to the block
+	appendedBefore := func(nonce uint64) bool {
+		message := rawdb.ReadWithdrawMessage(db, nonce)
+		return message != nil && message.BlockNumber <= blockNumber
+	}
+	if rawdb.ReadWithdrawMessage(db, 0) == nil {
+		return nil, false
+	}
+	// find the number of messages appended up to the block: first an upper bound, then
+	// a binary search between the bound and the last power of two below it
+	lo, hi := uint64(0), uint64(1)
+	for appendedBefore(hi - 1) {
+		lo, hi = hi, 2*hi
+	}
+	for lo < hi {
+		mid := lo + (hi-lo)/2
+		if appendedBefore(mid) {
+			lo = mid + 1
+		} else {
+			hi = mid
+		}
+	}
+	leaves = rawdb.ReadWithdrawMessageHashes(db, lo)
+	if uint64(len(leaves)) != lo {
+		return nil, false
+	}
+	return leaves, true
+}
@@ -0,0 +1,65 @@
+package withdrawtrie
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/scroll-tech/go-ethereum/common"
+	"github.com/scroll-tech/go-ethereum/core/rawdb"
+	"github.com/scroll-tech/go-ethereum/core/types"
+	"github.com/scroll-tech/go-ethereum/rollup/rcfg"
+)
+
+func newAppendMessageLog(blockHash common.Hash, nonce uint64, messageHash common.Hash) *types.Log {
+	data := append(common.BigToHash(new(big.Int).SetUint64(nonce)).Bytes(), messageHash.Bytes()...)
+	return &types.Log{Address: rcfg.L2MessageQueueAddress, Topics: []common.Hash{AppendMessageEventTopic}, Data: data, BlockHash: blockHash}
+}
+
+func TestMessageLeaves(t *testing.T) {
+	db := rawdb.NewMemoryDatabase()
+	_, ok := MessageLeaves(db, 10)
+	assert.False(t, ok)
+
+	// block n appends n messages
+	var hashes []common.Hash
+	for number := uint64(1); number <= 6; number++ {
+		blockHash := common.Hash{byte(number)}
+		var logs []*types.Log
+		for i := uint64(0); i < number; i++ {
+			hash := common.BigToHash(new(big.Int).SetUint64(uint64(len(hashes)) + 1))
+			logs = append(logs, newAppendMessageLog(blockHash, uint64(len(hashes)), hash))
+			hashes = append(hashes, hash)
+		}
+		IndexBlockMessages(db, blockHash, number, types.Receipts{{Logs: logs}})
+	}
+	for number := uint64(0); number <= 7; number++ {
+		count := number * (number + 1) / 2
+		if number == 7 {
+			count = uint64(len(hashes))
+		}
+		leaves, ok := MessageLeaves(db, number)
+		assert.True(t, ok, "block: %d", number)
+		assert.Len(t, leaves, int(count), "block: %d", number)
+		assert.Equal(t, hashes[:count], append([]common.Hash{}, leaves...), "block: %d", number)
+	}
+
+	// unindexing block 6 removes its messages, unless they were indexed again by another block
+	var logs []*types.Log
+	for nonce := uint64(15); nonce < 21; nonce++ {
+		logs = append(logs, newAppendMessageLog(common.Hash{6}, nonce, hashes[nonce]))
+	}
+	IndexBlockMessages(db, common.Hash{0x66}, 6, types.Receipts{{Logs: []*types.Log{newAppendMessageLog(common.Hash{0x66}, 15, hashes[15])}}})
+	UnindexBlockMessages(db, db, logs)
+	leaves, ok := MessageLeaves(db, 6)
+	assert.True(t, ok)
+	assert.Equal(t, hashes[:16], leaves)
+	assert.NotNil(t, rawdb.ReadWithdrawMessageNonce(db, hashes[15]))
+	assert.Nil(t, rawdb.ReadWithdrawMessageNonce(db, hashes[16]))
+
+	// a gap in the index is reported
+	rawdb.DeleteWithdrawMessage(db, 5)
+	_, ok = MessageLeaves(db, 6)
+	assert.False(t, ok)
+}
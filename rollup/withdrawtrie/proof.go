@@ -0,0 +1,100 @@
+package withdrawtrie
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/scroll-tech/go-ethereum/common"
+	"github.com/scroll-tech/go-ethereum/core/types"
+	"github.com/scroll-tech/go-ethereum/crypto"
+	"github.com/scroll-tech/go-ethereum/rollup/rcfg"
+)
+
+// MaxHeight is the maximum height of the withdraw trie, as in AppendOnlyMerkleTree.sol.
+const MaxHeight = 40
+
+// AppendMessageEventTopic is the topic of the AppendMessage(uint256 index, bytes32 messageHash)
+// event, emitted by the L2MessageQueue predeploy for every message appended to the withdraw trie.
+var AppendMessageEventTopic = crypto.Keccak256Hash([]byte("AppendMessage(uint256,bytes32)"))
+
+// zeroHashes[i] is the root of an empty subtree of height i.
+var zeroHashes [MaxHeight]common.Hash
+
+func init() {
+	for i := 1; i < MaxHeight; i++ {
+		zeroHashes[i] = crypto.Keccak256Hash(zeroHashes[i-1].Bytes(), zeroHashes[i-1].Bytes())
+	}
+}
+
+// ParseAppendMessageLog returns the index and the hash of the message appended to the withdraw
+// trie by the given log, ok is false if the log is not an AppendMessage event of the L2MessageQueue.
+func ParseAppendMessageLog(log *types.Log) (index uint64, messageHash common.Hash, ok bool) {
+	if log.Address != rcfg.L2MessageQueueAddress || len(log.Topics) != 1 || log.Topics[0] != AppendMessageEventTopic || len(log.Data) != 64 {
+		return 0, common.Hash{}, false
+	}
+	nonce := new(big.Int).SetBytes(log.Data[:32])
+	if !nonce.IsUint64() {
+		return 0, common.Hash{}, false
+	}
+	return nonce.Uint64(), common.BytesToHash(log.Data[32:]), true
+}
+
+// GenerateProof returns the root of the withdraw trie with the given leaves, i.e. the message
+// hashes ordered by message nonce, and the Merkle proof of the leaf at index against it. The proof
+// lists the sibling hashes from the leaf upwards, as expected by WithdrawTrieVerifier.sol.
+func GenerateProof(leaves []common.Hash, index uint64) (common.Hash, []common.Hash, error) {
+	if index >= uint64(len(leaves)) {
+		return common.Hash{}, nil, fmt.Errorf("message nonce %d out of range, withdraw trie has %d messages", index, len(leaves))
+	}
+	var proof []common.Hash
+	level := leaves
+	for height := 0; len(level) > 1; height++ {
+		if height >= MaxHeight {
+			return common.Hash{}, nil, errors.New("withdraw trie exceeds maximum height")
+		}
+		sibling := zeroHashes[height]
+		if index^1 < uint64(len(level)) {
+			sibling = level[index^1]
+		}
+		proof = append(proof, sibling)
+
+		// the last node of an odd level is a left child, its right sibling is an empty subtree
+		next := make([]common.Hash, (len(level)+1)/2)
+		for i := range next {
+			right := zeroHashes[height]
+			if 2*i+1 < len(level) {
+				right = level[2*i+1]
+			}
+			next[i] = crypto.Keccak256Hash(level[2*i].Bytes(), right.Bytes())
+		}
+		level = next
+		index >>= 1
+	}
+	return level[0], proof, nil
+}
+
+// MessageRoot returns the root of the withdraw trie with the given leaves, the zero hash if
+// no message was appended yet.
+func MessageRoot(leaves []common.Hash) common.Hash {
+	if len(leaves) == 0 {
+		return common.Hash{}
+	}
+	root, _, _ := GenerateProof(leaves, 0)
+	return root
+}
+
+// VerifyProof reports whether proof is a valid Merkle proof of the message with the given hash
+// and nonce against the withdraw trie root.
+func VerifyProof(root, messageHash common.Hash, nonce uint64, proof []common.Hash) bool {
+	hash := messageHash
+	for _, item := range proof {
+		if nonce%2 == 0 {
+			hash = crypto.Keccak256Hash(hash.Bytes(), item.Bytes())
+		} else {
+			hash = crypto.Keccak256Hash(item.Bytes(), hash.Bytes())
+		}
+		nonce /= 2
+	}
+	return hash == root
+}
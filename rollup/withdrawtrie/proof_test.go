@@ -0,0 +1,75 @@
+package withdrawtrie
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/scroll-tech/go-ethereum/common"
+	"github.com/scroll-tech/go-ethereum/core/types"
+	"github.com/scroll-tech/go-ethereum/crypto"
+	"github.com/scroll-tech/go-ethereum/rollup/rcfg"
+)
+
+// appendMessageHash mirrors _appendMessageHash of AppendOnlyMerkleTree.sol and returns the new root.
+func appendMessageHash(branches []common.Hash, nextMessageIndex uint64, messageHash common.Hash) common.Hash {
+	hash, height := messageHash, 0
+	for index := nextMessageIndex; index != 0; index >>= 1 {
+		if index%2 == 0 {
+			branches[height] = hash
+			hash = crypto.Keccak256Hash(hash.Bytes(), zeroHashes[height].Bytes())
+		} else {
+			hash = crypto.Keccak256Hash(branches[height].Bytes(), hash.Bytes())
+		}
+		height++
+	}
+	branches[height] = hash
+	return hash
+}
+
+func TestGenerateProof(t *testing.T) {
+	branches := make([]common.Hash, MaxHeight)
+	var leaves []common.Hash
+	for n := uint64(0); n < 70; n++ {
+		leaves = append(leaves, crypto.Keccak256Hash(new(big.Int).SetUint64(n).Bytes()))
+		root := appendMessageHash(branches, n, leaves[n])
+
+		for index := uint64(0); index <= n; index++ {
+			proofRoot, proof, err := GenerateProof(leaves, index)
+			require.NoError(t, err)
+			assert.Equal(t, root, proofRoot, "messages: %d", n+1)
+			assert.Equal(t, root, MessageRoot(leaves), "messages: %d", n+1)
+			assert.True(t, VerifyProof(root, leaves[index], index, proof), "messages: %d, index: %d", n+1, index)
+			assert.False(t, VerifyProof(root, common.Hash{0x01}, index, proof), "messages: %d, index: %d", n+1, index)
+			if n > 0 {
+				assert.False(t, VerifyProof(root, leaves[index], index^1, proof), "messages: %d, index: %d", n+1, index)
+			}
+		}
+	}
+
+	assert.Equal(t, common.Hash{}, MessageRoot(nil))
+	_, _, err := GenerateProof(leaves, uint64(len(leaves)))
+	assert.Error(t, err)
+	_, _, err = GenerateProof(nil, 0)
+	assert.Error(t, err)
+}
+
+func TestParseAppendMessageLog(t *testing.T) {
+	messageHash := common.HexToHash("0x1234")
+	data := append(common.BigToHash(big.NewInt(7)).Bytes(), messageHash.Bytes()...)
+	log := &types.Log{Address: rcfg.L2MessageQueueAddress, Topics: []common.Hash{AppendMessageEventTopic}, Data: data}
+
+	index, hash, ok := ParseAppendMessageLog(log)
+	require.True(t, ok)
+	assert.Equal(t, uint64(7), index)
+	assert.Equal(t, messageHash, hash)
+
+	_, _, ok = ParseAppendMessageLog(&types.Log{Address: common.Address{0x01}, Topics: log.Topics, Data: data})
+	assert.False(t, ok)
+	_, _, ok = ParseAppendMessageLog(&types.Log{Address: rcfg.L2MessageQueueAddress, Topics: []common.Hash{{0x01}}, Data: data})
+	assert.False(t, ok)
+	_, _, ok = ParseAppendMessageLog(&types.Log{Address: rcfg.L2MessageQueueAddress, Topics: log.Topics, Data: data[:32]})
+	assert.False(t, ok)
+}
@@ -16,3 +16,8 @@ type StateDB interface {
 func ReadWTRSlot(addr common.Address, state StateDB) common.Hash {
 	return state.GetState(addr, rcfg.WithdrawTrieRootSlot)
 }
+
+// WithdrawRoot returns the withdraw trie root in the given state.
+func WithdrawRoot(state StateDB) common.Hash {
+	return ReadWTRSlot(rcfg.L2MessageQueueAddress, state)
+}
@@ -247,7 +247,7 @@ func initClient(conn ServerCodec, idgen func() ID, services *serviceRegistry) *C
 // subscription an error is returned. Otherwise a new service is created and added to the
 // service collection this client provides to the server.
 func (c *Client) RegisterName(name string, receiver interface{}) error {
-	return c.services.registerName(name, receiver)
+	return c.services.registerName(name, receiver, nil)
 }
 
 func (c *Client) nextID() json.RawMessage {
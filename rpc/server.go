@@ -68,7 +68,15 @@ func NewServer() *Server {
 // subscription an error is returned. Otherwise a new service is created and added to the
 // service collection this server provides to clients.
 func (s *Server) RegisterName(name string, receiver interface{}) error {
-	return s.services.registerName(name, receiver)
+	return s.services.registerName(name, receiver, nil)
+}
+
+// RegisterNameFiltered is like RegisterName, but only exposes the methods and subscriptions
+// of the receiver for which allow returns true. The filter is called with the full method
+// name, e.g. "admin_peers", subscriptions are named after the service and the subscription,
+// e.g. "eth_newHeads". No service is created if the filter rejects all methods.
+func (s *Server) RegisterNameFiltered(name string, receiver interface{}, allow func(method string) bool) error {
+	return s.services.registerName(name, receiver, allow)
 }
 
 // ServeCodec reads incoming requests from codec, calls the appropriate callback and writes
@@ -51,6 +51,33 @@ func TestServerRegisterName(t *testing.T) {
 	}
 }
 
+func TestServerRegisterNameFiltered(t *testing.T) {
+	server := NewServer()
+	allow := func(method string) bool { return method == "test_echo" || method == "test_subscription" }
+	if err := server.RegisterNameFiltered("test", new(testService), allow); err != nil {
+		t.Fatalf("%v", err)
+	}
+
+	svc, ok := server.services.services["test"]
+	if !ok {
+		t.Fatalf("Expected service test to be registered")
+	}
+	if len(svc.callbacks) != 1 || svc.callbacks["echo"] == nil {
+		t.Errorf("Expected only callback echo, got %v", svc.callbacks)
+	}
+	if len(svc.subscriptions) != 1 || svc.subscriptions["subscription"] == nil {
+		t.Errorf("Expected only subscription subscription, got %v", svc.subscriptions)
+	}
+
+	// services without allowed methods are not registered
+	if err := server.RegisterNameFiltered("none", new(testService), func(string) bool { return false }); err != nil {
+		t.Fatalf("%v", err)
+	}
+	if _, ok := server.services.services["none"]; ok {
+		t.Errorf("Expected service none not to be registered")
+	}
+}
+
 func TestServer(t *testing.T) {
 	files, err := ioutil.ReadDir("testdata")
 	if err != nil {
@@ -58,7 +58,7 @@ type callback struct {
 	isSubscribe bool           // true if this is a subscription callback
 }
 
-func (r *serviceRegistry) registerName(name string, rcvr interface{}) error {
+func (r *serviceRegistry) registerName(name string, rcvr interface{}, allow func(method string) bool) error {
 	rcvrVal := reflect.ValueOf(rcvr)
 	if name == "" {
 		return fmt.Errorf("no service name for type %s", rcvrVal.Type().String())
@@ -67,6 +67,16 @@ func (r *serviceRegistry) registerName(name string, rcvr interface{}) error {
 	if len(callbacks) == 0 {
 		return fmt.Errorf("service %T doesn't have any suitable methods/subscriptions to expose", rcvr)
 	}
+	if allow != nil {
+		for method := range callbacks {
+			if !allow(name + serviceMethodSeparator + method) {
+				delete(callbacks, method)
+			}
+		}
+		if len(callbacks) == 0 {
+			return nil
+		}
+	}
 
 	r.mu.Lock()
 	defer r.mu.Unlock()
@@ -118,6 +118,36 @@ func (t *ZkTrie) GetKey(kHashBytes []byte) []byte {
 	return nil
 }
 
+// IterateLeaves calls fn with the preimage of the key and the value of every leaf of the trie,
+// until fn returns an error. The values of accounts are their zktrie encoding, the values of
+// storage slots are 32 bytes long. The preimages of the keys are only known if the database
+// records preimages.
+func (t *ZkTrie) IterateLeaves(fn func(key, value []byte) error) error {
+	return t.iterateLeaves(t.Tree().Root(), fn)
+}
+
+func (t *ZkTrie) iterateLeaves(nodeHash *zkt.Hash, fn func(key, value []byte) error) error {
+	n, err := t.Tree().GetNode(nodeHash)
+	if err != nil {
+		return err
+	}
+	switch n.Type {
+	case zktrie.NodeTypeEmpty_New:
+		return nil
+	case zktrie.NodeTypeLeaf_New:
+		key := t.GetKey(n.NodeKey.Bytes())
+		if key == nil {
+			return fmt.Errorf("missing preimage of key %x", n.NodeKey.Bytes())
+		}
+		return fn(key, common.CopyBytes(n.Data()))
+	default:
+		if err := t.iterateLeaves(n.ChildL, fn); err != nil {
+			return err
+		}
+		return t.iterateLeaves(n.ChildR, fn)
+	}
+}
+
 // Commit writes all nodes and the secure hash pre-images to the trie's database.
 // Nodes are stored with their sha3 hash as the key.
 //
@@ -142,13 +172,6 @@ func (t *ZkTrie) Copy() *ZkTrie {
 	return &ZkTrie{t.ZkTrie.Copy(), t.db}
 }
 
-// NodeIterator returns an iterator that returns nodes of the underlying trie. Iteration
-// starts at the key after the given start key.
-func (t *ZkTrie) NodeIterator(start []byte) NodeIterator {
-	/// FIXME
-	panic("not implemented")
-}
-
 // hashKey returns the hash of key as an ephemeral buffer.
 // The caller must not hold onto the return value because it will become
 // invalid on the next call to hashKey or secKey.
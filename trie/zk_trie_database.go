@@ -29,7 +29,10 @@ func NewZktrieDatabase(diskdb ethdb.KeyValueStore) *ZktrieDatabase {
 
 // adhoc wrapper...
 func NewZktrieDatabaseFromTriedb(db *Database) *ZktrieDatabase {
-	db.Zktrie = true
+	// note: the flag is only written once, tries are opened concurrently by the tracers
+	if !db.Zktrie {
+		db.Zktrie = true
+	}
 	return &ZktrieDatabase{db: db, prefix: []byte{}}
 }
 
@@ -0,0 +1,186 @@
+// Copyright 2022 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package trie
+
+import (
+	"bytes"
+
+	zktrie "github.com/scroll-tech/zktrie/trie"
+	zkt "github.com/scroll-tech/zktrie/types"
+
+	"github.com/scroll-tech/go-ethereum/common"
+	"github.com/scroll-tech/go-ethereum/ethdb"
+)
+
+// ZkLeafKey returns the key of the leaf of the given key in the zktrie, as reported by the
+// LeafKey of its node iterator. The path of a leaf is made of the bits of the poseidon hash
+// of its key starting from the least significant one, so the bit-reversed hash orders the
+// leaf keys like the iteration of the trie.
+func ZkLeafKey(key []byte) (common.Hash, error) {
+	k, err := zkt.ToSecureKey(key)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	return common.BytesToHash(bitReverse(common.BigToHash(k).Bytes())), nil
+}
+
+// zkNodeIteratorState represents the iteration state at one particular node of the
+// zktrie.
+type zkNodeIteratorState struct {
+	hash   *zkt.Hash    // Hash of the node being iterated
+	node   *zktrie.Node // Trie node being iterated, resolved when it is visited
+	parent common.Hash  // Hash of the first full ancestor node (nil if current is the root)
+	path   []byte       // Path of the node, one byte per bit of the node keys
+}
+
+// zkNodeIterator is a NodeIterator over the nodes of a zktrie, visiting the nodes in the
+// order of their paths. The empty nodes are not reported.
+type zkNodeIterator struct {
+	trie  *ZkTrie
+	start []byte                 // Leaf key the iteration starts at, nodes before it are skipped
+	stack []*zkNodeIteratorState // Current node and the pending siblings of its ancestors
+	err   error                  // Failure set in case of an internal error in the iterator
+}
+
+// NodeIterator returns an iterator that returns nodes of the underlying trie. Iteration
+// starts at the key after the given start key. The leaf keys are the ones returned by
+// ZkLeafKey.
+func (t *ZkTrie) NodeIterator(start []byte) NodeIterator {
+	it := &zkNodeIterator{trie: t, start: start}
+	if root := t.Tree().Root(); !bytes.Equal(root[:], zkt.HashZero[:]) {
+		it.stack = []*zkNodeIteratorState{{hash: root}}
+	}
+	return it
+}
+
+func (it *zkNodeIterator) Hash() common.Hash {
+	if len(it.stack) == 0 {
+		return common.Hash{}
+	}
+	return common.BytesToHash(it.stack[len(it.stack)-1].hash.Bytes())
+}
+
+func (it *zkNodeIterator) Parent() common.Hash {
+	if len(it.stack) == 0 {
+		return common.Hash{}
+	}
+	return it.stack[len(it.stack)-1].parent
+}
+
+func (it *zkNodeIterator) Leaf() bool {
+	if len(it.stack) == 0 || it.stack[len(it.stack)-1].node == nil {
+		return false
+	}
+	return it.stack[len(it.stack)-1].node.Type == zktrie.NodeTypeLeaf_New
+}
+
+func (it *zkNodeIterator) LeafKey() []byte {
+	if it.Leaf() {
+		return bitReverse(it.stack[len(it.stack)-1].node.NodeKey.Bytes())
+	}
+	panic("not at leaf")
+}
+
+func (it *zkNodeIterator) LeafBlob() []byte {
+	if it.Leaf() {
+		return common.CopyBytes(it.stack[len(it.stack)-1].node.Data())
+	}
+	panic("not at leaf")
+}
+
+func (it *zkNodeIterator) LeafProof() [][]byte {
+	panic("leaf proofs are not supported by the zktrie iterator")
+}
+
+func (it *zkNodeIterator) Path() []byte {
+	if len(it.stack) == 0 {
+		return nil
+	}
+	return it.stack[len(it.stack)-1].path
+}
+
+func (it *zkNodeIterator) Error() error {
+	return it.err
+}
+
+// AddResolver is a no-op, the nodes of the zktrie are always resolved from its database.
+func (it *zkNodeIterator) AddResolver(ethdb.KeyValueStore) {}
+
+// Next moves the iterator to the next node, returning whether there are any further nodes.
+// In case of an internal error this method returns false and sets the Error field to the
+// encountered failure. If `descend` is false, skips iterating over any subnodes of the
+// current node.
+func (it *zkNodeIterator) Next(descend bool) bool {
+	if it.err != nil || len(it.stack) == 0 {
+		return false
+	}
+	// the children of the current node are pushed in place of it, the siblings of its
+	// ancestors stay pending below
+	if top := it.stack[len(it.stack)-1]; top.node != nil {
+		it.stack = it.stack[:len(it.stack)-1]
+		if descend && !top.node.IsTerminal() {
+			it.stack = append(it.stack, it.child(top, top.node.ChildR, 1), it.child(top, top.node.ChildL, 0))
+		}
+	}
+	for len(it.stack) > 0 {
+		top := it.stack[len(it.stack)-1]
+		if bytes.Equal(top.hash[:], zkt.HashZero[:]) {
+			it.stack = it.stack[:len(it.stack)-1]
+			continue
+		}
+		node, err := it.trie.Tree().GetNode(top.hash)
+		if err != nil {
+			it.err = err
+			return false
+		}
+		top.node = node
+		if node.Type == zktrie.NodeTypeEmpty_New || it.beforeStart(top) {
+			it.stack = it.stack[:len(it.stack)-1]
+			continue
+		}
+		return true
+	}
+	return false
+}
+
+func (it *zkNodeIterator) child(parent *zkNodeIteratorState, hash *zkt.Hash, bit byte) *zkNodeIteratorState {
+	path := make([]byte, len(parent.path)+1)
+	copy(path, parent.path)
+	path[len(parent.path)] = bit
+	return &zkNodeIteratorState{hash: hash, parent: common.BytesToHash(parent.hash.Bytes()), path: path}
+}
+
+// beforeStart reports whether all the leaves below the given node come before the start
+// key of the iteration.
+func (it *zkNodeIterator) beforeStart(state *zkNodeIteratorState) bool {
+	if len(it.start) == 0 {
+		return false
+	}
+	if state.node.Type == zktrie.NodeTypeLeaf_New {
+		return bytes.Compare(bitReverse(state.node.NodeKey.Bytes()), it.start) < 0
+	}
+	for i, bit := range state.path {
+		var startBit byte
+		if i/8 < len(it.start) {
+			startBit = it.start[i/8] >> (7 - i%8) & 1
+		}
+		if bit != startBit {
+			return bit < startBit
+		}
+	}
+	return false
+}
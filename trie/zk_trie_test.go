@@ -265,3 +265,41 @@ func TestZkTrieDelete(t *testing.T) {
 		assert.Equal(t, hashes[i].Hex(), hash.Hex())
 	}
 }
+
+func TestZkTrieNodeIterator(t *testing.T) {
+	_, trie, content := makeTestZkTrie()
+
+	// the leaves are iterated in the order of their leaf keys
+	leaves := make(map[common.Hash][]byte)
+	for key, val := range content {
+		leafKey, err := ZkLeafKey([]byte(key))
+		assert.NoError(t, err)
+		leaves[leafKey] = val
+	}
+	var keys [][]byte
+	it := NewIterator(trie.NodeIterator(nil))
+	for it.Next() {
+		assert.Equal(t, leaves[common.BytesToHash(it.Key)], it.Value)
+		if len(keys) > 0 {
+			assert.Equal(t, -1, bytes.Compare(keys[len(keys)-1], it.Key))
+		}
+		keys = append(keys, it.Key)
+	}
+	assert.NoError(t, it.Err)
+	assert.Equal(t, len(content), len(keys))
+
+	// the iteration starts at the given key
+	for _, i := range []int{0, 1, len(keys) / 2, len(keys) - 1} {
+		it = NewIterator(trie.NodeIterator(keys[i]))
+		assert.True(t, it.Next())
+		assert.Equal(t, keys[i], it.Key)
+	}
+	start := common.CopyBytes(keys[len(keys)/2])
+	start[len(start)-1]++
+	it = NewIterator(trie.NodeIterator(start))
+	assert.True(t, it.Next())
+	assert.Equal(t, keys[len(keys)/2+1], it.Key)
+
+	// an empty trie has no nodes
+	assert.False(t, newEmptyZkTrie().NodeIterator(nil).Next(true))
+}